@@ -14,11 +14,13 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/richinex/ariadne/internal/text"
 	"github.com/richinex/ariadne/model"
 	jsonutil "github.com/richinex/ariadne/internal/json"
 	"github.com/richinex/ariadne/llm"
@@ -36,6 +38,7 @@ type Agent struct {
 	storage      storage.MemoryStorage
 	sessionID    string
 	verbose      bool
+	wrapUp       tools.WrapUpPolicy
 }
 
 // New creates a new agent with the given configuration and provider.
@@ -54,12 +57,45 @@ func New(config Config, provider llm.Provider) *Agent {
 	}
 }
 
-// WithToolConfig overrides the tool execution configuration.
+// WithToolConfig overrides the tool execution configuration. Tools its
+// Filter excludes are removed from the agent, its Egress policy is applied
+// to the agent's HTTP tools, and its WrapUp policy to the loop.
 func (a *Agent) WithToolConfig(config tools.ToolConfig) *Agent {
 	a.toolExecutor = tools.NewExecutor(config)
+	a.wrapUp = config.WrapUp
+	for _, name := range a.toolRegistry.Names() {
+		if !config.Filter.Allows(name) {
+			a.toolRegistry.Unregister(name)
+			continue
+		}
+		tool, _ := a.toolRegistry.Get(name)
+		if httpTool, ok := tool.(*tools.HTTPTool); ok && config.Egress != nil {
+			httpTool.WithEgressPolicy(config.Egress)
+		}
+	}
+	return a
+}
+
+// WithExamples adds few-shot exchanges to the system prompt, shown within
+// budget tokens (0 = DefaultExampleBudget, negative = no cap).
+func (a *Agent) WithExamples(examples []Example, budget int) *Agent {
+	a.config.Examples = append(a.config.Examples, examples...)
+	a.config.ExampleBudget = budget
 	return a
 }
 
+// AddTool registers an additional tool after construction.
+// Returns error if a tool with the same name is already registered.
+func (a *Agent) AddTool(tool tools.Tool) error {
+	return a.toolRegistry.Register(tool)
+}
+
+// RemoveTool unregisters a tool by name.
+// Returns false if the agent had no such tool.
+func (a *Agent) RemoveTool(name string) bool {
+	return a.toolRegistry.Unregister(name)
+}
+
 // Name returns the agent's name.
 func (a *Agent) Name() string {
 	return a.config.Name
@@ -95,70 +131,52 @@ func (a *Agent) Execute(ctx context.Context, task string, maxIterations int) Res
 }
 
 // ExecuteWithHistory runs a task with conversation history.
+// The current system prompt (tools, memories, iteration limit) is always
+// applied; system messages already in history are replaced by it.
 func (a *Agent) ExecuteWithHistory(ctx context.Context, task string, history []llm.ChatMessage, maxIterations int) Response {
-	return a.executeFull(ctx, task, history, nil, maxIterations)
+	return a.executeFull(ctx, task, history, nil, maxIterations, nil)
 }
 
 // ExecuteWithContext runs a task with additional context data.
 func (a *Agent) ExecuteWithContext(ctx context.Context, task string, contextData json.RawMessage, maxIterations int) Response {
-	return a.executeFull(ctx, task, nil, contextData, maxIterations)
+	return a.executeFull(ctx, task, nil, contextData, maxIterations, nil)
 }
 
-// executeFull is the main execution method with all options.
-func (a *Agent) executeFull(ctx context.Context, task string, history []llm.ChatMessage, contextData json.RawMessage, maxIterations int) Response {
-	startTime := time.Now()
-	var steps []model.Step
-	var toolCalls []model.ToolCall
-	var totalUsage llm.TokenUsage // Track cumulative token usage
-	var llmCalls int              // Track number of LLM calls
-	conversation := history
-	var lastToolOutput string
-
-	// Load relevant memories
-	memoryContext := a.loadRelevantMemories(ctx, 3)
-
-	// Build memory section
-	memorySection := ""
-	if memoryContext != "" {
-		memorySection = "\n\n" + memoryContext + "\n"
-	}
+// ExecuteWithBudget runs a task like ExecuteWithContext, cancelling it once
+// it goes over budget. The overrun is a failure whose Err matches
+// ErrBudgetExceeded; steps, token usage and the last tool output are kept.
+func (a *Agent) ExecuteWithBudget(ctx context.Context, task string, contextData json.RawMessage, maxIterations int, budget Budget) Response {
+	ctx, guard, stop := budget.start(ctx)
+	defer stop()
+	return a.executeFull(ctx, task, nil, contextData, maxIterations, guard)
+}
 
-	// Build context section
-	contextSection := ""
-	if len(contextData) > 0 {
-		contextSection = fmt.Sprintf("\n\nCONTEXT DATA:\n```json\n%s\n```", string(contextData))
+// executeFull is the main execution method with all options. With storage,
+// a run that fails or times out leaves its progress in the session for the
+// next run; a successful run clears it.
+func (a *Agent) executeFull(ctx context.Context, task string, history []llm.ChatMessage, contextData json.RawMessage, maxIterations int, guard *budgetGuard) Response {
+	resp := a.run(ctx, task, history, contextData, maxIterations, guard)
+	if resp.Type == ResponseSuccess {
+		a.clearPartialProgress(ctx)
+	} else {
+		a.storePartialProgress(ctx, task, resp)
 	}
-
-	// Add system prompt if starting fresh
-	if len(conversation) == 0 {
-		systemPrompt := fmt.Sprintf(
-			`%s
-
-Available Tools:
-%s%s%s
-
-You have a maximum of %d iterations.
-Respond in this JSON format:
-{
-  "thought": "your reasoning",
-  "action": {"tool": "name", "input": {...}},
-  "is_final": false,
-  "final_answer": null
+	return resp
 }
 
-When complete: is_final=true, action=null, provide final_answer.`,
-			a.config.SystemPrompt,
-			a.toolRegistry.Description(),
-			contextSection,
-			memorySection,
-			maxIterations,
-		)
+// run is the ReAct loop.
+func (a *Agent) run(ctx context.Context, task string, history []llm.ChatMessage, contextData json.RawMessage, maxIterations int, guard *budgetGuard) Response {
+	startTime := time.Now()
+	var steps []model.Step
+	var toolCalls []model.ToolCall
+	episode := storage.Episode{Task: task} // Stored as an episodic memory when the run ends
+	var stats thinkStats // Token usage, LLM calls and decision repairs
+	var lastToolOutput string
+	var lastToolErr error // Denied calls are reported if the loop stalls on them
+	stall := tools.NewStallDetector()
 
-		conversation = append(conversation, llm.ChatMessage{
-			Role:    "system",
-			Content: systemPrompt,
-		})
-	}
+	// A fresh system prompt leads every run, resumed or not
+	conversation := withSystemPrompt(history, a.systemPrompt(ctx, contextData, maxIterations))
 
 	conversation = append(conversation, llm.ChatMessage{
 		Role:    "user",
@@ -169,31 +187,39 @@ When complete: is_final=true, action=null, provide final_answer.`,
 	for iteration := 0; iteration < maxIterations; iteration++ {
 		// Check context cancellation at top of loop
 		if ctx.Err() != nil {
-			return NewFailureResponse(
-				fmt.Sprintf("execution cancelled: %v", ctx.Err()),
-				steps,
-				uint64(time.Since(startTime).Milliseconds()),
-			)
+			return stats.annotate(cancelledResponse(ctx, steps, lastToolOutput, startTime))
 		}
 
 		remaining := maxIterations - iteration
 
+		// At the wrap-up threshold, ask for the answer instead of an action
+		forced := a.wrapUp.ForcesAnswer(remaining)
+		if forced && conversation[len(conversation)-1].Role == "user" {
+			conversation[len(conversation)-1].Content += "\n\n" + a.wrapUp.SynthesisPrompt()
+		}
+
 		// Think: get next action from LLM
-		decision, usage, err := a.think(ctx, conversation)
+		decision, err := a.think(ctx, conversation, &stats)
+		if err != nil && ctx.Err() != nil {
+			return stats.annotate(cancelledResponse(ctx, steps, lastToolOutput, startTime))
+		}
 		if err != nil {
-			return NewFailureResponse(
-				fmt.Sprintf("Failed to reason: %v", err),
+			return NewErrorResponse(
+				LLMError(err),
 				steps,
 				uint64(time.Since(startTime).Milliseconds()),
 			)
 		}
 
-		// Track LLM call and accumulate token usage
-		llmCalls++
-		if usage != nil {
-			totalUsage.PromptTokens += usage.PromptTokens
-			totalUsage.CompletionTokens += usage.CompletionTokens
-			totalUsage.TotalTokens += usage.TotalTokens
+		// Over the token budget: stop before acting, but keep a final answer
+		if guard.spend(stats.usage.TotalTokens) && !decision.IsFinal {
+			return stats.annotate(cancelledResponse(ctx, steps, lastToolOutput, startTime))
+		}
+
+		// A synthesis step's decision is the answer, whatever its form
+		if forced && !decision.IsFinal {
+			answer := a.getImplicitResult(decision, lastToolOutput, steps)
+			decision.IsFinal, decision.Action, decision.FinalAnswer = true, nil, &answer
 		}
 
 		// Check if complete
@@ -201,7 +227,8 @@ When complete: is_final=true, action=null, provide final_answer.`,
 			result := a.getFinalResult(decision, lastToolOutput)
 
 			// Store episodic memory
-			a.storeEpisodicMemory(ctx, task, result)
+			episode.Outcome, episode.Result = storage.EpisodeSuccess, result
+			a.storeEpisodicMemory(ctx, episode)
 
 			steps = append(steps, model.Step{
 				Iteration:   iteration,
@@ -210,15 +237,15 @@ When complete: is_final=true, action=null, provide final_answer.`,
 				Observation: &result,
 			})
 
-			return NewSuccessResponse(
+			return stats.annotate(NewSuccessResponse(
 				result,
 				steps,
 				toolCalls,
 				uint64(time.Since(startTime).Milliseconds()),
 				a.config.Name,
-				&totalUsage,
-				llmCalls,
-			)
+				&stats.usage,
+				stats.calls,
+			))
 		}
 
 		// Act: execute tool
@@ -228,10 +255,12 @@ When complete: is_final=true, action=null, provide final_answer.`,
 			if toolCall != nil {
 				toolCalls = append(toolCalls, *toolCall)
 			}
+			episode.AddToolCall(decision.Action.Tool, decision.Action.Input)
 
 			if err == nil {
 				lastToolOutput = observation
 			}
+			lastToolErr = err
 
 			// Add to conversation
 			assistantMsg := map[string]interface{}{
@@ -253,8 +282,8 @@ When complete: is_final=true, action=null, provide final_answer.`,
 			})
 
 			urgency := ""
-			if remaining <= 2 {
-				urgency = fmt.Sprintf("\n\nWARNING: Only %d iterations remaining!", remaining-1)
+			if warning := a.wrapUp.Warning(remaining); warning != "" {
+				urgency = "\n\n" + warning
 			}
 
 			observationMsg := observation
@@ -262,6 +291,12 @@ When complete: is_final=true, action=null, provide final_answer.`,
 				observationMsg = fmt.Sprintf("Tool failed: %v", err)
 			}
 
+			// Nudge the model when it keeps repeating itself
+			verdict := stall.Observe(decision.Action.Tool, decision.Action.Input, observationMsg)
+			if verdict == tools.StallWarn {
+				urgency += "\n\n" + stall.CorrectiveMessage()
+			}
+
 			conversation = append(conversation, llm.ChatMessage{
 				Role: "user",
 				Content: fmt.Sprintf(
@@ -277,22 +312,36 @@ When complete: is_final=true, action=null, provide final_answer.`,
 				Action:      &actionName,
 				Observation: &observationMsg,
 			})
+
+			if verdict == tools.StallAbort {
+				var stallErr error = stall.Err()
+				if errors.Is(lastToolErr, ErrToolDenied) {
+					// Repeating a denied call: report the denial too
+					stallErr = fmt.Errorf("%w: %w", stallErr, lastToolErr)
+				}
+				return NewErrorResponse(
+					stallErr,
+					steps,
+					uint64(time.Since(startTime).Milliseconds()),
+				)
+			}
 		} else {
 			// No action - might be implicit completion
 			if a.hasPriorProgress(steps) {
 				result := a.getImplicitResult(decision, lastToolOutput, steps)
 
-				a.storeEpisodicMemory(ctx, task, result)
+				episode.Outcome, episode.Result = storage.EpisodeSuccess, result
+				a.storeEpisodicMemory(ctx, episode)
 
-				return NewSuccessResponse(
+				return stats.annotate(NewSuccessResponse(
 					result,
 					steps,
 					toolCalls,
 					uint64(time.Since(startTime).Milliseconds()),
 					a.config.Name,
-					&totalUsage,
-					llmCalls,
-				)
+					&stats.usage,
+					stats.calls,
+				))
 			}
 
 			observation := "No action specified"
@@ -306,56 +355,184 @@ When complete: is_final=true, action=null, provide final_answer.`,
 	}
 
 	// Max iterations reached
-	a.storeEpisodicMemory(ctx, task, fmt.Sprintf("Timeout after %d iterations", maxIterations))
+	episode.Outcome, episode.Result = storage.EpisodeTimeout, fmt.Sprintf("Timeout after %d iterations", maxIterations)
+	a.storeEpisodicMemory(ctx, episode)
 
-	return NewTimeoutResponse(
+	return stats.annotate(NewTimeoutResponse(
 		steps,
 		toolCalls,
 		uint64(time.Since(startTime).Milliseconds()),
-		&totalUsage,
-		llmCalls,
-	)
+		&stats.usage,
+		stats.calls,
+	))
 }
 
-// think asks the LLM for the next action.
-// Uses streaming when verbose mode is enabled to show tokens in real-time.
-// Returns the decision and token usage (usage may be nil for streaming).
-func (a *Agent) think(ctx context.Context, conversation []llm.ChatMessage) (Decision, *llm.TokenUsage, error) {
-	var response string
-	var err error
-	var usage *llm.TokenUsage
-
-	if a.verbose {
-		// Use streaming to show tokens in real-time
-		response, usage, err = a.thinkWithStreaming(ctx, conversation)
-	} else {
-		// Use regular completion with token tracking
-		response, usage, err = a.llmClient.ChatWithUsage(ctx, conversation)
+// maxDecisionRepairs bounds the correction requests sent for one decision.
+const maxDecisionRepairs = 2
+
+// thinkStats accumulates the LLM cost of a run's decisions.
+type thinkStats struct {
+	usage     llm.TokenUsage
+	calls     int
+	repairs   int // Correction requests sent
+	fallbacks int // Decisions that stayed invalid after repairs
+}
+
+func (s *thinkStats) add(usage *llm.TokenUsage) {
+	s.calls++
+	s.usage.Add(usage)
+}
+
+// annotate records the run's LLM usage and repair counts on a response.
+func (s *thinkStats) annotate(resp Response) Response {
+	resp.Metadata.TokenUsage = &s.usage
+	resp.Metadata.LLMCalls = s.calls
+	resp.Metadata.DecisionRepairs = s.repairs
+	resp.Metadata.DecisionFallbacks = s.fallbacks
+	return resp
+}
+
+// systemPrompt builds the run's system prompt: the agent's instructions,
+// tool descriptions, context data, relevant memories, iteration limit,
+// decision format and few-shot examples.
+func (a *Agent) systemPrompt(ctx context.Context, contextData json.RawMessage, maxIterations int) string {
+	// Build memory section
+	memorySection := ""
+	if memoryContext := a.loadRelevantMemories(ctx, 3); memoryContext != "" {
+		memorySection = "\n\n" + memoryContext + "\n"
+	}
+	if partial := a.loadPartialProgress(ctx); partial != "" {
+		memorySection += "\n\n" + partial + "\n"
 	}
 
-	if err != nil {
-		return Decision{}, nil, fmt.Errorf("LLM chat failed: %w", err)
+	// Build context section
+	contextSection := ""
+	if len(contextData) > 0 {
+		contextSection = fmt.Sprintf("\n\nCONTEXT DATA:\n```json\n%s\n```", string(contextData))
 	}
 
-	// Extract JSON from response
-	var decision Decision
-	extracted, err := jsonutil.ExtractJSON(response)
-	if err != nil {
-		// Could not extract JSON - treat as a thought without action
-		return Decision{
-			Thought: response,
-			IsFinal: false,
-		}, usage, nil
+	return fmt.Sprintf(
+		`%s
+
+Available Tools:
+%s%s%s%s
+
+You have a maximum of %d iterations.
+Respond in this JSON format:
+{
+  "thought": "your reasoning",
+  "action": {"tool": "name", "input": {...}},
+  "is_final": false,
+  "final_answer": null
+}
+
+When complete: is_final=true, action=null, provide final_answer.%s`,
+		a.config.SystemPrompt,
+		a.toolRegistry.Description(),
+		tools.IngestedContentRule,
+		contextSection,
+		memorySection,
+		maxIterations,
+		examplesSection(a.config.Examples, a.config.ExampleBudget),
+	)
+}
+
+// withSystemPrompt returns a new conversation starting with systemPrompt
+// followed by history. System messages in history are dropped: they are
+// earlier runs' prompts, superseded by the current one.
+func withSystemPrompt(history []llm.ChatMessage, systemPrompt string) []llm.ChatMessage {
+	conversation := make([]llm.ChatMessage, 0, len(history)+2)
+	conversation = append(conversation, llm.ChatMessage{
+		Role:    "system",
+		Content: systemPrompt,
+	})
+	for _, msg := range history {
+		if msg.Role != "system" {
+			conversation = append(conversation, msg)
+		}
 	}
+	return conversation
+}
+
+// think asks the LLM for the next action.
+// Models that enforce JSON schemas get the Decision schema; otherwise
+// streaming is used when verbose mode is enabled and the model supports it,
+// to show tokens in real-time.
+// A reply that doesn't parse as a valid Decision is sent back with a
+// targeted correction request, up to maxDecisionRepairs times, before
+// falling back to treating it as a thought without action.
+func (a *Agent) think(ctx context.Context, conversation []llm.ChatMessage, stats *thinkStats) (Decision, error) {
+	attempt := conversation
+	var lastResponse string
+
+	for repair := 0; repair <= maxDecisionRepairs; repair++ {
+		var response string
+		var err error
+		var usage *llm.TokenUsage
+
+		switch caps := a.llmClient.Capabilities(); {
+		case caps.JSONSchema:
+			// Schema-constrained output can't stream, so print it whole
+			response, usage, err = a.llmClient.ChatWithFormatAndUsage(ctx, attempt, decisionFormat)
+			if err == nil && a.verbose {
+				fmt.Printf("\n[%s] %s\n\n", a.config.Name, response)
+			}
+		case a.verbose && caps.Streaming:
+			// Use streaming to show tokens in real-time
+			response, usage, err = a.thinkWithStreaming(ctx, attempt)
+		default:
+			// Use regular completion with token tracking
+			response, usage, err = a.llmClient.ChatWithUsage(ctx, attempt)
+			if err == nil && a.verbose {
+				fmt.Printf("\n[%s] %s\n\n", a.config.Name, response)
+			}
+		}
+
+		if err != nil {
+			return Decision{}, fmt.Errorf("LLM chat failed: %w", err)
+		}
+		stats.add(usage)
+		lastResponse = response
+
+		decision, err := jsonutil.DecodeValidated(response, validateDecision)
+		if err == nil {
+			return decision, nil
+		}
+		if repair == maxDecisionRepairs {
+			break
+		}
 
-	if err := json.Unmarshal([]byte(extracted), &decision); err != nil {
-		return Decision{
-			Thought: response,
-			IsFinal: false,
-		}, usage, nil
+		stats.repairs++
+		if a.verbose {
+			fmt.Printf("[%s] Invalid decision (%v), asking for a corrected reply\n", a.config.Name, err)
+		}
+		// Repairs go on a copy so the main conversation stays clean
+		attempt = append(attempt[:len(attempt):len(attempt)],
+			llm.ChatMessage{Role: "assistant", Content: response},
+			llm.ChatMessage{Role: "user", Content: jsonutil.RepairPrompt(err)},
+		)
 	}
 
-	return decision, usage, nil
+	// Could not get a usable decision - treat as a thought without action
+	stats.fallbacks++
+	return Decision{
+		Thought: lastResponse,
+		IsFinal: false,
+	}, nil
+}
+
+// validateDecision checks the parts of a Decision the ReAct loop relies on.
+func validateDecision(d Decision) error {
+	if d.IsFinal {
+		return nil
+	}
+	if d.Action == nil {
+		return fmt.Errorf("either an action or is_final=true is required")
+	}
+	if d.Action.Tool == "" {
+		return fmt.Errorf("action.tool is required")
+	}
+	return nil
 }
 
 // streamResult holds the result of a streaming call.
@@ -417,7 +594,7 @@ func (a *Agent) executeTool(ctx context.Context, action *Action) (string, *model
 	}
 	inputSize := len(inputJSON)
 
-	result, err := a.toolExecutor.Execute(ctx, tool, action.Input)
+	result, err := a.toolExecutor.Execute(tools.WithAgent(ctx, a.config.Name), tool, action.Input)
 	if err != nil {
 		return "", nil, fmt.Errorf("tool %q failed: %w", action.Tool, err)
 	}
@@ -437,20 +614,31 @@ func (a *Agent) executeTool(ctx context.Context, action *Action) (string, *model
 	return "", toolCall, result.Error
 }
 
+// cancelledResponse reports a cancelled, timed-out or over-budget run,
+// keeping the last tool output as the partial result.
+func cancelledResponse(ctx context.Context, steps []model.Step, lastToolOutput string, startTime time.Time) Response {
+	err := CancelledError(ctx.Err())
+	if cause := context.Cause(ctx); errors.Is(cause, ErrBudgetExceeded) {
+		err = cause
+	}
+	resp := NewErrorResponse(
+		err,
+		steps,
+		uint64(time.Since(startTime).Milliseconds()),
+	)
+	resp.PartialResult = lastToolOutput
+	return resp
+}
+
 // Memory helpers
 
-func (a *Agent) storeEpisodicMemory(ctx context.Context, task, result string) {
+// storeEpisodicMemory saves a finished run as an episodic memory.
+func (a *Agent) storeEpisodicMemory(ctx context.Context, episode storage.Episode) {
 	if a.storage == nil || a.sessionID == "" {
 		return
 	}
 
-	resultPreview := result
-	if len(result) > 150 {
-		resultPreview = result[:150] + "..."
-	}
-
-	entry := storage.NewMemoryEntry(a.sessionID, storage.MemoryEpisodic, fmt.Sprintf("Task: %s | Result: %s", task, resultPreview)).
-		WithAgent(a.config.Name)
+	entry := episode.Memory(a.sessionID).WithAgent(a.config.Name)
 
 	_ = a.storage.StoreMemory(ctx, entry) // Best-effort memory storage
 }
@@ -461,19 +649,144 @@ func (a *Agent) loadRelevantMemories(ctx context.Context, limit int) string {
 	}
 
 	memType := storage.MemoryEpisodic
-	memories, err := a.storage.QueryMemories(ctx, a.sessionID, &memType, limit)
+	memories, err := a.storage.QueryMemories(ctx, a.sessionID, &memType, storage.MemoryRankWindow)
 	if err != nil || len(memories) == 0 {
 		return ""
 	}
+	storage.RankMemories(memories, time.Now(), storage.DefaultMemoryHalfLife)
+	if len(memories) > limit {
+		memories = memories[:limit]
+	}
 
 	var lines []string
 	for _, m := range memories {
-		lines = append(lines, fmt.Sprintf("- %s", m.Content))
+		lines = append(lines, storage.DescribeMemory(m))
 	}
 
 	return fmt.Sprintf("Relevant past experiences:\n%s", strings.Join(lines, "\n"))
 }
 
+// Partial progress limits: steps kept, and bytes kept per observation in
+// the prompt summary and in the stored metadata.
+const (
+	partialStepLimit      = 5
+	partialPreviewLen     = 300
+	partialObservationLen = 2000
+	partialResultLen      = 4000
+)
+
+// partialStep is a step as kept in a partial progress memory's metadata.
+type partialStep struct {
+	Iteration   int    `json:"iteration"`
+	Thought     string `json:"thought,omitempty"`
+	Action      string `json:"action,omitempty"`
+	Observation string `json:"observation,omitempty"`
+}
+
+// partialProgress is the metadata of a partial progress memory.
+type partialProgress struct {
+	Task          string        `json:"task"`
+	Error         string        `json:"error"`
+	Steps         []partialStep `json:"steps"`
+	PartialResult string        `json:"partial_result,omitempty"`
+}
+
+// storePartialProgress saves the last steps of a failed or timed-out run.
+// The content is a summary for the next run's prompt; the metadata keeps
+// the steps and outputs for a human or tool to inspect.
+func (a *Agent) storePartialProgress(ctx context.Context, task string, resp Response) {
+	if a.storage == nil || a.sessionID == "" || len(resp.Steps) == 0 {
+		return
+	}
+
+	steps := resp.Steps
+	if len(steps) > partialStepLimit {
+		steps = steps[len(steps)-partialStepLimit:]
+	}
+	progress := partialProgress{
+		Task:          task,
+		Error:         resp.ResultText(),
+		PartialResult: text.Truncate(resp.PartialResult, partialResultLen),
+	}
+	if resp.Err != nil {
+		progress.Error = resp.Err.Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task: %s\nStopped after %d steps: %s\nLast steps:", task, len(resp.Steps), progress.Error)
+	for _, step := range steps {
+		ps := partialStep{Iteration: step.Iteration, Thought: step.Thought}
+		if step.Action != nil {
+			ps.Action = *step.Action
+		}
+		if step.Observation != nil {
+			ps.Observation = text.Truncate(*step.Observation, partialObservationLen)
+		}
+		progress.Steps = append(progress.Steps, ps)
+
+		action := ps.Action
+		if action == "" {
+			action = "no action"
+		}
+		fmt.Fprintf(&b, "\n- [%d] %s: %s", step.Iteration, action, text.Truncate(ps.Observation, partialPreviewLen))
+	}
+
+	metadata, err := json.Marshal(progress)
+	if err != nil {
+		metadata = nil
+	}
+	entry := storage.NewMemoryEntry(a.sessionID, storage.MemoryPartial, b.String()).
+		WithAgent(a.config.Name).
+		WithMetadata(string(metadata))
+
+	// Save even when the run stopped because it was cancelled
+	_ = a.storage.StoreMemory(context.WithoutCancel(ctx), entry) // Best-effort memory storage
+}
+
+// clearPartialProgress deletes this agent's partial progress once a run
+// succeeds, so it isn't offered to later runs.
+func (a *Agent) clearPartialProgress(ctx context.Context) {
+	for _, m := range a.partialProgress(ctx) {
+		_ = a.storage.DeleteMemory(ctx, m.ID)
+	}
+}
+
+// partialProgress returns this agent's partial progress memories, newest
+// first.
+func (a *Agent) partialProgress(ctx context.Context) []storage.MemoryEntry {
+	if a.storage == nil || a.sessionID == "" {
+		return nil
+	}
+	memType := storage.MemoryPartial
+	memories, err := a.storage.QueryMemories(ctx, a.sessionID, &memType, storage.MemoryRankWindow)
+	if err != nil {
+		return nil
+	}
+	var own []storage.MemoryEntry
+	for _, m := range memories {
+		if m.AgentID == a.config.Name {
+			own = append(own, m)
+		}
+	}
+	return own
+}
+
+// loadPartialProgress describes the agent's last unfinished run for the
+// system prompt, or returns "" if there is none.
+func (a *Agent) loadPartialProgress(ctx context.Context) string {
+	memories := a.partialProgress(ctx)
+	if len(memories) == 0 {
+		return ""
+	}
+	latest := memories[0]
+	for _, m := range memories[1:] {
+		if m.CreatedAt > latest.CreatedAt {
+			latest = m
+		}
+	}
+	return "An earlier run in this session stopped before finishing. Continue from its progress where it helps instead of repeating it:\n" + latest.Content
+}
+
 // Result helpers
 
 func (a *Agent) getFinalResult(decision Decision, lastToolOutput string) string {
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/tools"
+)
+
+// recordingProvider answers every request with a final decision and keeps
+// the conversations it was sent.
+type recordingProvider struct {
+	mu       sync.Mutex
+	requests [][]llm.ChatMessage
+}
+
+func (p *recordingProvider) Name() string  { return "recording" }
+func (p *recordingProvider) Model() string { return "recording" }
+
+func (p *recordingProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, append([]llm.ChatMessage(nil), messages...))
+	return llm.LLMResponse{Content: `{"thought": "done", "is_final": true, "final_answer": "resumed"}`}, nil
+}
+
+func (p *recordingProvider) ChatWithFormat(ctx context.Context, messages []llm.ChatMessage, format *llm.ResponseFormat) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *recordingProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *recordingProvider) StreamChat(ctx context.Context, messages []llm.ChatMessage, chunks chan<- string) (*llm.TokenUsage, error) {
+	resp, err := p.Chat(ctx, messages)
+	if err == nil {
+		chunks <- resp.Content
+	}
+	return resp.Usage, err
+}
+
+func TestExecuteWithHistoryLeadsWithFreshSystemPrompt(t *testing.T) {
+	provider := &recordingProvider{}
+	config := NewBuilder("resumer").
+		SystemPrompt("You continue earlier work.").
+		Tool(tools.NewStatFileTool()).
+		Build()
+	a := New(config, provider)
+
+	history := []llm.ChatMessage{
+		{Role: "system", Content: "stale prompt from the previous run"},
+		{Role: "user", Content: "Task: list the files"},
+		{Role: "assistant", Content: "main.go and go.mod"},
+		{Role: "system", Content: "another stale prompt"},
+		{Role: "user", Content: "Task: which is larger?"},
+		{Role: "assistant", Content: "main.go"},
+	}
+	resp := a.ExecuteWithHistory(context.Background(), "summarize what we found", history, 4)
+	if resp.Type != ResponseSuccess || resp.Result != "resumed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if len(provider.requests) != 1 {
+		t.Fatalf("expected 1 LLM request, got %d", len(provider.requests))
+	}
+	sent := provider.requests[0]
+
+	var systems int
+	for _, msg := range sent {
+		if msg.Role == "system" {
+			systems++
+		}
+	}
+	if systems != 1 || sent[0].Role != "system" {
+		t.Fatalf("expected exactly one system message, leading the conversation; got %d, first %q", systems, sent[0].Role)
+	}
+	prompt := sent[0].Content
+	for _, want := range []string{"You continue earlier work.", "stat_file", "maximum of 4 iterations"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected %q in the system prompt:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "stale prompt") {
+		t.Error("the previous run's system prompt was kept")
+	}
+
+	// History follows in order, then the new task
+	want := []llm.ChatMessage{history[1], history[2], history[4], history[5], {Role: "user", Content: "Task: summarize what we found"}}
+	if len(sent) != len(want)+1 {
+		t.Fatalf("expected %d messages, got %d: %+v", len(want)+1, len(sent), sent)
+	}
+	for i, msg := range want {
+		if got := sent[i+1]; got.Role != msg.Role || got.Content != msg.Content {
+			t.Errorf("message %d: expected %s %q, got %s %q", i+1, msg.Role, msg.Content, got.Role, got.Content)
+		}
+	}
+
+	// The caller's history is left as it was
+	if history[0].Content != "stale prompt from the previous run" || len(history) != 6 {
+		t.Error("ExecuteWithHistory modified the caller's history")
+	}
+}
@@ -0,0 +1,64 @@
+// Per-run resource budgets.
+//
+// A Budget caps the tokens and wall time of one agent run. Going over
+// either cancels the run's context, so in-flight LLM and tool calls stop,
+// and the run fails with an error matching ErrBudgetExceeded.
+//
+// Information Hiding:
+// - Context cancellation plumbing hidden
+// - Token accounting against the limit hidden
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Budget limits one agent run. Zero fields are unlimited.
+type Budget struct {
+	MaxTokens   uint32        // Prompt plus completion tokens across all LLM calls
+	MaxDuration time.Duration // Wall time
+}
+
+// IsZero reports whether the budget sets no limits.
+func (b Budget) IsZero() bool {
+	return b.MaxTokens == 0 && b.MaxDuration == 0
+}
+
+// budgetGuard enforces a Budget on a run's context. A nil guard enforces
+// nothing.
+type budgetGuard struct {
+	budget Budget
+	cancel context.CancelCauseFunc
+}
+
+// start derives the run's context from ctx. Call stop when the run ends.
+func (b Budget) start(ctx context.Context) (runCtx context.Context, guard *budgetGuard, stop func()) {
+	if b.IsZero() {
+		return ctx, nil, func() {}
+	}
+	runCtx, cancel := context.WithCancelCause(ctx)
+	stop = func() { cancel(nil) }
+	if b.MaxDuration > 0 {
+		var cancelTimeout context.CancelFunc
+		runCtx, cancelTimeout = context.WithTimeoutCause(runCtx, b.MaxDuration,
+			fmt.Errorf("%w: ran longer than %s", ErrBudgetExceeded, b.MaxDuration))
+		stop = func() {
+			cancelTimeout()
+			cancel(nil)
+		}
+	}
+	return runCtx, &budgetGuard{budget: b, cancel: cancel}, stop
+}
+
+// spend cancels the run once tokens used exceed the limit, and reports
+// whether it did.
+func (g *budgetGuard) spend(tokens uint32) bool {
+	if g == nil || g.budget.MaxTokens == 0 || tokens <= g.budget.MaxTokens {
+		return false
+	}
+	g.cancel(fmt.Errorf("%w: used %d tokens, limit %d", ErrBudgetExceeded, tokens, g.budget.MaxTokens))
+	return true
+}
@@ -3,11 +3,13 @@
 // Information Hiding:
 // - Builder state management hidden
 // - Default value application hidden
+// - Tool spec resolution hidden
 
 package agent
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/richinex/ariadne/tools"
@@ -20,8 +22,12 @@ type Builder struct {
 	description      string
 	systemPrompt     string
 	tools            []tools.Tool
+	toolSpecs        []ToolSpec
+	catalog          *ToolCatalog
 	responseSchema   json.RawMessage
 	returnToolOutput bool
+	examples         []Example
+	exampleBudget    int
 }
 
 // NewBuilder creates a new agent builder with the given name.
@@ -56,6 +62,25 @@ func (b *Builder) Tools(toolList []tools.Tool) *Builder {
 	return b
 }
 
+// ToolSpec adds a tool declared by name and options, resolved at Build.
+func (b *Builder) ToolSpec(spec ToolSpec) *Builder {
+	b.toolSpecs = append(b.toolSpecs, spec)
+	return b
+}
+
+// ToolSpecs adds multiple declared tools at once.
+func (b *Builder) ToolSpecs(specs []ToolSpec) *Builder {
+	b.toolSpecs = append(b.toolSpecs, specs...)
+	return b
+}
+
+// Catalog sets the catalog tool specs resolve against.
+// Defaults to DefaultToolCatalog().
+func (b *Builder) Catalog(catalog *ToolCatalog) *Builder {
+	b.catalog = catalog
+	return b
+}
+
 // ResponseSchema sets the JSON schema for structured outputs.
 func (b *Builder) ResponseSchema(schema json.RawMessage) *Builder {
 	b.responseSchema = schema
@@ -68,8 +93,68 @@ func (b *Builder) ReturnToolOutput(enabled bool) *Builder {
 	return b
 }
 
+// Examples adds few-shot exchanges to the system prompt.
+func (b *Builder) Examples(examples ...Example) *Builder {
+	b.examples = append(b.examples, examples...)
+	return b
+}
+
+// ExampleBudget caps the examples shown, in estimated tokens
+// (0 = DefaultExampleBudget, negative = no cap).
+func (b *Builder) ExampleBudget(tokens int) *Builder {
+	b.exampleBudget = tokens
+	return b
+}
+
+// Validate resolves the tool specs and reports every invalid one.
+func (b *Builder) Validate() error {
+	_, err := b.resolveSpecs()
+	return err
+}
+
+// resolveSpecs constructs the tools declared by specs.
+func (b *Builder) resolveSpecs() ([]tools.Tool, error) {
+	if len(b.toolSpecs) == 0 {
+		return nil, nil
+	}
+	catalog := b.catalog
+	if catalog == nil {
+		catalog = DefaultToolCatalog()
+	}
+	var resolved []tools.Tool
+	var errs []error
+	for _, spec := range b.toolSpecs {
+		tool, err := catalog.Resolve(spec)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resolved = append(resolved, tool)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("agent %q: %w", b.name, errors.Join(errs...))
+	}
+	return resolved, nil
+}
+
 // Build creates the agent configuration.
+// Panics if a tool spec is invalid; use TryBuild for user input.
 func (b *Builder) Build() Config {
+	config, err := b.TryBuild()
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+// TryBuild creates the agent configuration, reporting every invalid tool
+// spec instead of panicking.
+func (b *Builder) TryBuild() (Config, error) {
+	specTools, err := b.resolveSpecs()
+	if err != nil {
+		return Config{}, err
+	}
+
 	description := b.description
 	if description == "" {
 		description = fmt.Sprintf("Agent: %s", b.name)
@@ -87,10 +172,12 @@ func (b *Builder) Build() Config {
 		Name:             b.name,
 		Description:      description,
 		SystemPrompt:     systemPrompt,
-		Tools:            b.tools,
+		Tools:            append(append([]tools.Tool{}, b.tools...), specTools...),
 		ResponseSchema:   b.responseSchema,
 		ReturnToolOutput: b.returnToolOutput,
-	}
+		Examples:         b.examples,
+		ExampleBudget:    b.exampleBudget,
+	}, nil
 }
 
 // Name returns the builder's agent name.
@@ -100,7 +187,7 @@ func (b *Builder) Name() string {
 
 // ToolCount returns the number of tools registered.
 func (b *Builder) ToolCount() int {
-	return len(b.tools)
+	return len(b.tools) + len(b.toolSpecs)
 }
 
 // Collection manages multiple agent configurations.
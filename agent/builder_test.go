@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/tools"
+)
+
+// testCatalog has a tool with a required option besides the defaults.
+func testCatalog(t *testing.T) *ToolCatalog {
+	t.Helper()
+	catalog := DefaultToolCatalog()
+	err := catalog.Register(ToolFactory{
+		Name: "deploy",
+		Options: []ToolOption{
+			{Name: "target", Type: OptionString, Description: "Environment to deploy to", Required: true},
+			{Name: "dry_run", Type: OptionBool, Description: "Only print the plan"},
+		},
+		New: func(o ToolOptions) (tools.Tool, error) { return tools.NewStatFileTool(), nil },
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	return catalog
+}
+
+func TestBuilderToolSpecValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []ToolSpec
+		want  []string // Substrings of the error; none means valid
+	}{
+		{"valid defaults", []ToolSpec{{Name: "read_file"}, {Name: "glob"}}, nil},
+		{"valid options from the command line", []ToolSpec{
+			{Name: "execute_shell", Options: map[string]interface{}{"timeout": "60", "allowed_commands": "go|git"}},
+			{Name: "deploy", Options: map[string]interface{}{"target": "staging", "dry_run": "true"}},
+		}, nil},
+		{"valid options from a config file", []ToolSpec{
+			{Name: "ripgrep", Options: map[string]interface{}{"timeout": float64(10), "max_results": 50}},
+			{Name: "read_file", Options: map[string]interface{}{"allowed_paths": []interface{}{"src/", "docs/"}}},
+		}, nil},
+		{"unknown tool", []ToolSpec{{Name: "teleport"}}, []string{`unknown tool "teleport"`, "read_file"}},
+		{"unknown option", []ToolSpec{{Name: "glob", Options: map[string]interface{}{"depth": "3"}}},
+			[]string{`tool "glob": unknown option "depth"`, "max_results"}},
+		{"missing required option", []ToolSpec{{Name: "deploy"}},
+			[]string{`tool "deploy": missing required option "target"`, "Environment to deploy to"}},
+		{"badly typed int", []ToolSpec{{Name: "execute_shell", Options: map[string]interface{}{"timeout": "soon"}}},
+			[]string{`tool "execute_shell": option "timeout"`, "expected int"}},
+		{"fractional int", []ToolSpec{{Name: "glob", Options: map[string]interface{}{"max_results": 2.5}}},
+			[]string{`option "max_results"`}},
+		{"badly typed bool", []ToolSpec{{Name: "deploy", Options: map[string]interface{}{"target": "prod", "dry_run": "maybe"}}},
+			[]string{`option "dry_run"`, "expected bool"}},
+		{"list with a non-string", []ToolSpec{{Name: "read_file", Options: map[string]interface{}{"allowed_paths": []interface{}{"src/", 3}}}},
+			[]string{`option "allowed_paths"`, "list of strings"}},
+		{"every invalid spec reported", []ToolSpec{{Name: "teleport"}, {Name: "read_file"}, {Name: "deploy"}},
+			[]string{`unknown tool "teleport"`, `missing required option "target"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewBuilder("custom").Catalog(testCatalog(t)).ToolSpecs(tt.specs)
+			config, err := builder.TryBuild()
+			if validateErr := builder.Validate(); (validateErr == nil) != (err == nil) {
+				t.Errorf("Validate and TryBuild disagree: %v vs %v", validateErr, err)
+			}
+
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(config.Tools) != len(tt.specs) {
+					t.Errorf("expected %d tools, got %d", len(tt.specs), len(config.Tools))
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.HasPrefix(err.Error(), `agent "custom": `) {
+				t.Errorf("expected the agent name in %q", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected %q in %q", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilderBuildPanicsOnInvalidSpec(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Build to panic on an unknown tool")
+		}
+	}()
+	NewBuilder("custom").ToolSpec(ToolSpec{Name: "teleport"}).Build()
+}
+
+func TestBuilderAppendsSpecToolsAfterTools(t *testing.T) {
+	config, err := NewBuilder("custom").
+		Tool(tools.NewStatFileTool()).
+		ToolSpec(ToolSpec{Name: "glob"}).
+		TryBuild()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, tool := range config.Tools {
+		names = append(names, tool.Metadata().Name)
+	}
+	if got := strings.Join(names, ","); got != "stat_file,glob" {
+		t.Errorf("expected stat_file,glob, got %s", got)
+	}
+	if config.Description != "Agent: custom" || !strings.Contains(config.SystemPrompt, "custom") {
+		t.Errorf("expected defaults applied, got %q / %q", config.Description, config.SystemPrompt)
+	}
+}
+
+func TestParseAgentSpec(t *testing.T) {
+	tests := []struct {
+		in      string
+		name    string
+		specs   []ToolSpec
+		wantErr bool
+	}{
+		{in: "file", name: "file"},
+		{in: "ops=execute_shell:timeout=60;allowed_commands=go|git+glob", name: "ops", specs: []ToolSpec{
+			{Name: "execute_shell", Options: map[string]interface{}{"timeout": "60", "allowed_commands": "go|git"}},
+			{Name: "glob"},
+		}},
+		{in: "=glob", wantErr: true},
+		{in: "ops=+glob", wantErr: true},
+		{in: "ops=glob:max_results", wantErr: true},
+	}
+	for _, tt := range tests {
+		name, specs, err := ParseAgentSpec(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error %v", tt.in, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if name != tt.name || len(specs) != len(tt.specs) {
+			t.Errorf("%q: expected %s with %d specs, got %s with %+v", tt.in, tt.name, len(tt.specs), name, specs)
+			continue
+		}
+		for i, spec := range specs {
+			if spec.Name != tt.specs[i].Name || len(spec.Options) != len(tt.specs[i].Options) {
+				t.Errorf("%q: spec %d: expected %+v, got %+v", tt.in, i, tt.specs[i], spec)
+			}
+			for key, value := range tt.specs[i].Options {
+				if spec.Options[key] != value {
+					t.Errorf("%q: option %s: expected %v, got %v", tt.in, key, value, spec.Options[key])
+				}
+			}
+		}
+	}
+}
@@ -32,6 +32,13 @@ type Config struct {
 
 	// ReturnToolOutput returns the last tool output instead of final_answer.
 	ReturnToolOutput bool
+
+	// Examples are few-shot exchanges shown in the system prompt.
+	Examples []Example
+
+	// ExampleBudget caps the examples shown, in estimated tokens
+	// (0 = DefaultExampleBudget, negative = no cap).
+	ExampleBudget int
 }
 
 // DefaultConfig returns a basic agent configuration.
@@ -0,0 +1,40 @@
+// Agent failure classes.
+//
+// Failed and timed-out responses carry an Err wrapping one of these
+// sentinels, so callers branch with errors.Is instead of matching text.
+//
+// Information Hiding:
+// - Wrapping of underlying causes (provider, context, tool) hidden
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/richinex/ariadne/tools"
+)
+
+var (
+	// ErrMaxIterations means the loop ran out of iterations.
+	ErrMaxIterations = errors.New("max iterations reached")
+	// ErrLLM means a provider call failed.
+	ErrLLM = errors.New("LLM call failed")
+	// ErrToolDenied means a tool refused a call under its access policy.
+	ErrToolDenied = tools.ErrToolDenied
+	// ErrCancelled means the context was cancelled or timed out.
+	ErrCancelled = errors.New("execution cancelled")
+	// ErrBudgetExceeded means the run used up its token or time Budget.
+	ErrBudgetExceeded = errors.New("budget exceeded")
+)
+
+// LLMError wraps a provider failure so it matches ErrLLM.
+func LLMError(err error) error {
+	return fmt.Errorf("%w: %w", ErrLLM, err)
+}
+
+// CancelledError wraps a context error so it matches both ErrCancelled
+// and the context error.
+func CancelledError(err error) error {
+	return fmt.Errorf("%w: %w", ErrCancelled, err)
+}
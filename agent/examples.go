@@ -0,0 +1,135 @@
+// Few-shot examples for agent decisions.
+//
+// Smaller models often drift from the decision format: missing fields,
+// prose around the JSON, actions and final answers mixed. Showing them a
+// few complete exchanges (task, then good decision JSON and the observation
+// it got, until a final answer) fixes most of it. Examples are kept in a
+// library file keyed by agent name and added to the system prompt, as many
+// as fit the token budget.
+//
+// Information Hiding:
+// - Library file format and validation hidden
+// - Rendering and trimming to budget hidden
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultExampleBudget is the default cap, in estimated tokens, on the
+// examples added to a system prompt.
+const DefaultExampleBudget = 1000
+
+// exampleBytesPerToken approximates example size in tokens.
+const exampleBytesPerToken = 4
+
+// Example is one curated exchange: a task and the turns that solve it.
+type Example struct {
+	Task  string        `json:"task"`
+	Turns []ExampleTurn `json:"turns"`
+}
+
+// ExampleTurn is one good decision and, unless it is final, the
+// observation its action got.
+type ExampleTurn struct {
+	Decision    Decision `json:"decision"`
+	Observation string   `json:"observation,omitempty"`
+}
+
+// ExampleLibrary maps agent names to their examples. Examples under "*"
+// apply to every agent.
+type ExampleLibrary map[string][]Example
+
+// LoadExampleLibrary reads a JSON library file: an object mapping agent
+// names (or "*") to lists of examples. Every example must end in a final
+// decision, and every decision must be one the ReAct loop accepts.
+func LoadExampleLibrary(path string) (ExampleLibrary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var library ExampleLibrary
+	if err := json.Unmarshal(data, &library); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, examples := range library {
+		for i, example := range examples {
+			if err := example.validate(); err != nil {
+				return nil, fmt.Errorf("%s: %s example %d: %w", path, name, i+1, err)
+			}
+		}
+	}
+	return library, nil
+}
+
+// For returns the examples for the named agent, followed by those for
+// every agent. A nil library has none.
+func (l ExampleLibrary) For(name string) []Example {
+	if l == nil {
+		return nil
+	}
+	return append(append([]Example{}, l[name]...), l["*"]...)
+}
+
+func (e Example) validate() error {
+	if strings.TrimSpace(e.Task) == "" {
+		return fmt.Errorf("task is required")
+	}
+	if len(e.Turns) == 0 {
+		return fmt.Errorf("at least one turn is required")
+	}
+	for i, turn := range e.Turns {
+		if err := validateDecision(turn.Decision); err != nil {
+			return fmt.Errorf("turn %d: %w", i+1, err)
+		}
+		if last := i == len(e.Turns)-1; last != turn.Decision.IsFinal {
+			return fmt.Errorf("turn %d: only the last turn must be final", i+1)
+		}
+	}
+	return nil
+}
+
+// render formats the example the way the loop shows a conversation.
+func (e Example) render() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Task: %s\n", e.Task)
+	for _, turn := range e.Turns {
+		decision, err := json.Marshal(turn.Decision)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&sb, "Assistant: %s\n", decision)
+		if !turn.Decision.IsFinal {
+			fmt.Fprintf(&sb, "Observation: %s\n", turn.Observation)
+		}
+	}
+	return sb.String()
+}
+
+// examplesSection renders examples for the system prompt, in order,
+// skipping any that would take it over budget tokens (0 uses
+// DefaultExampleBudget, negative means no cap). It returns "" if none fit.
+func examplesSection(examples []Example, budget int) string {
+	if budget == 0 {
+		budget = DefaultExampleBudget
+	}
+	var rendered []string
+	used := 0
+	for _, example := range examples {
+		text := example.render()
+		cost := len(text) / exampleBytesPerToken
+		if budget > 0 && used+cost > budget {
+			continue
+		}
+		used += cost
+		rendered = append(rendered, fmt.Sprintf("Example %d:\n%s", len(rendered)+1, text))
+	}
+	if len(rendered) == 0 {
+		return ""
+	}
+	return "\n\nExamples of good decisions (tools and results are illustrative):\n\n" + strings.Join(rendered, "\n")
+}
@@ -0,0 +1,361 @@
+// Declarative tool specs.
+//
+// A ToolSpec names a tool and its options instead of constructing it, so
+// agent definitions can come from config files or the command line. Specs
+// are resolved against a ToolCatalog when the agent is built; unknown tools,
+// unknown or missing options and badly typed values are reported with the
+// tool and option names.
+//
+// Information Hiding:
+// - Option type coercion (strings from the CLI, typed values from config files) hidden
+// - Built-in tool construction hidden behind the default catalog
+
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/richinex/ariadne/tools"
+)
+
+// Option types.
+const (
+	OptionString  = "string"
+	OptionInt     = "int"
+	OptionBool    = "bool"
+	OptionStrings = "strings" // List; "a|b|c" when given as a string
+)
+
+// ToolSpec declares a tool by name with options.
+type ToolSpec struct {
+	Name    string                 `json:"name" yaml:"name"`
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ToolOption describes one option a tool accepts.
+type ToolOption struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	Default     interface{}
+}
+
+// ToolOptions holds validated option values, with defaults applied.
+type ToolOptions map[string]interface{}
+
+// String returns a string option ("" if unset).
+func (o ToolOptions) String(name string) string {
+	s, _ := o[name].(string)
+	return s
+}
+
+// Int returns an int option (0 if unset).
+func (o ToolOptions) Int(name string) int {
+	n, _ := o[name].(int)
+	return n
+}
+
+// Bool returns a bool option (false if unset).
+func (o ToolOptions) Bool(name string) bool {
+	b, _ := o[name].(bool)
+	return b
+}
+
+// Strings returns a list option (nil if unset).
+func (o ToolOptions) Strings(name string) []string {
+	s, _ := o[name].([]string)
+	return s
+}
+
+// ToolFactory constructs a tool from validated options.
+type ToolFactory struct {
+	Name    string
+	Options []ToolOption
+	New     func(opts ToolOptions) (tools.Tool, error)
+}
+
+// ToolCatalog maps tool names to factories.
+type ToolCatalog struct {
+	factories map[string]ToolFactory
+}
+
+// NewToolCatalog creates an empty catalog.
+func NewToolCatalog() *ToolCatalog {
+	return &ToolCatalog{factories: make(map[string]ToolFactory)}
+}
+
+// Register adds a factory. Returns error if the name is taken.
+func (c *ToolCatalog) Register(factory ToolFactory) error {
+	if factory.Name == "" || factory.New == nil {
+		return fmt.Errorf("tool factory needs a name and constructor")
+	}
+	if _, exists := c.factories[factory.Name]; exists {
+		return fmt.Errorf("tool '%s' already registered", factory.Name)
+	}
+	c.factories[factory.Name] = factory
+	return nil
+}
+
+// Names returns the catalog's tool names, sorted.
+func (c *ToolCatalog) Names() []string {
+	names := make([]string, 0, len(c.factories))
+	for name := range c.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve validates a spec and constructs its tool.
+func (c *ToolCatalog) Resolve(spec ToolSpec) (tools.Tool, error) {
+	factory, ok := c.factories[spec.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool %q (available: %s)", spec.Name, strings.Join(c.Names(), ", "))
+	}
+
+	known := make(map[string]ToolOption, len(factory.Options))
+	for _, opt := range factory.Options {
+		known[opt.Name] = opt
+	}
+
+	opts := make(ToolOptions)
+	for name, raw := range spec.Options {
+		opt, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("tool %q: unknown option %q (options: %s)", spec.Name, name, optionNames(factory.Options))
+		}
+		value, err := coerceOption(opt.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q: option %q: %w", spec.Name, name, err)
+		}
+		opts[name] = value
+	}
+	for _, opt := range factory.Options {
+		if _, set := opts[opt.Name]; set {
+			continue
+		}
+		if opt.Required {
+			return nil, fmt.Errorf("tool %q: missing required option %q (%s)", spec.Name, opt.Name, opt.Description)
+		}
+		if opt.Default != nil {
+			opts[opt.Name] = opt.Default
+		}
+	}
+
+	tool, err := factory.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("tool %q: %w", spec.Name, err)
+	}
+	return tool, nil
+}
+
+func optionNames(options []ToolOption) string {
+	if len(options) == 0 {
+		return "none"
+	}
+	names := make([]string, len(options))
+	for i, opt := range options {
+		names[i] = opt.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// coerceOption converts a config or command-line value to the option type.
+func coerceOption(typ string, raw interface{}) (interface{}, error) {
+	switch typ {
+	case OptionString:
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+	case OptionInt:
+		switch v := raw.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+		case string:
+			if n, err := strconv.Atoi(v); err == nil {
+				return n, nil
+			}
+		}
+	case OptionBool:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case OptionStrings:
+		switch v := raw.(type) {
+		case []string:
+			return v, nil
+		case string:
+			return strings.Split(v, "|"), nil
+		case []interface{}:
+			list := make([]string, len(v))
+			for i, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected a list of strings, got %v", raw)
+				}
+				list[i] = s
+			}
+			return list, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported option type %q", typ)
+	}
+	return nil, fmt.Errorf("expected %s, got %v", typ, raw)
+}
+
+// ParseToolSpec parses "name" or "name:key=value;key=value". List values
+// are separated by "|", e.g. "execute_shell:timeout=60;allowed_commands=go|git".
+func ParseToolSpec(s string) (ToolSpec, error) {
+	name, rest, hasOptions := strings.Cut(strings.TrimSpace(s), ":")
+	spec := ToolSpec{Name: strings.TrimSpace(name)}
+	if spec.Name == "" {
+		return spec, fmt.Errorf("invalid tool spec %q: missing tool name", s)
+	}
+	if !hasOptions {
+		return spec, nil
+	}
+	spec.Options = make(map[string]interface{})
+	for _, pair := range strings.Split(rest, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return spec, fmt.Errorf("invalid tool spec %q: expected key=value, got %q", s, pair)
+		}
+		spec.Options[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return spec, nil
+}
+
+// ParseAgentSpec parses an agent given as "name" or "name=tool+tool:opt=v".
+// Returns the agent name and its tool specs (nil for a bare name).
+func ParseAgentSpec(s string) (string, []ToolSpec, error) {
+	name, toolList, hasTools := strings.Cut(strings.TrimSpace(s), "=")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("invalid agent spec %q: missing agent name", s)
+	}
+	if !hasTools {
+		return name, nil, nil
+	}
+	var specs []ToolSpec
+	for _, item := range strings.Split(toolList, "+") {
+		spec, err := ParseToolSpec(item)
+		if err != nil {
+			return "", nil, fmt.Errorf("agent %q: %w", name, err)
+		}
+		specs = append(specs, spec)
+	}
+	return name, specs, nil
+}
+
+// DefaultToolCatalog returns a catalog of the built-in tools that need no
+// runtime wiring (ResultStore, providers).
+func DefaultToolCatalog() *ToolCatalog {
+	c := NewToolCatalog()
+	maxSize := ToolOption{Name: "max_size", Type: OptionInt, Description: "Maximum file size in bytes", Default: tools.DefaultMaxFileSize}
+	allowedPaths := ToolOption{Name: "allowed_paths", Type: OptionStrings, Description: "Allowed path prefixes"}
+	timeout := func(def int) ToolOption {
+		return ToolOption{Name: "timeout", Type: OptionInt, Description: "Timeout in seconds", Default: def}
+	}
+
+	factories := []ToolFactory{
+		{Name: "read_file", Options: []ToolOption{maxSize, allowedPaths}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewReadFileTool(int64(o.Int("max_size"))).WithAllowedPaths(o.Strings("allowed_paths")), nil
+		}},
+		{Name: "write_file", Options: []ToolOption{maxSize, allowedPaths}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewWriteFileTool(int64(o.Int("max_size"))).WithAllowedPaths(o.Strings("allowed_paths")), nil
+		}},
+		{Name: "append_file", Options: []ToolOption{maxSize, allowedPaths}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewAppendFileTool(int64(o.Int("max_size"))).WithAllowedPaths(o.Strings("allowed_paths")), nil
+		}},
+		{Name: "edit_file", Options: []ToolOption{maxSize, allowedPaths}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewEditFileTool(int64(o.Int("max_size"))).WithAllowedPaths(o.Strings("allowed_paths")), nil
+		}},
+		{Name: "stat_file", Options: []ToolOption{allowedPaths}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewStatFileTool().WithAllowedPaths(o.Strings("allowed_paths")), nil
+		}},
+		{Name: "glob", Options: []ToolOption{
+			{Name: "max_results", Type: OptionInt, Description: "Cap on max_results per call (0 = absolute limit)"},
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewGlobTool(o.Int("max_results")), nil
+		}},
+		{Name: "execute_shell", Options: []ToolOption{
+			timeout(tools.DefaultToolTimeout),
+			{Name: "allowed_commands", Type: OptionStrings, Description: "Command allowlist"},
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewShellTool(uint64(o.Int("timeout"))).WithAllowedCommands(o.Strings("allowed_commands")), nil
+		}},
+		{Name: "ripgrep", Options: []ToolOption{
+			timeout(tools.DefaultToolTimeout),
+			{Name: "max_results", Type: OptionInt, Description: "Default maximum matching lines", Default: 200},
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewRipgrepTool(uint64(o.Int("timeout"))).WithMaxResults(o.Int("max_results")), nil
+		}},
+		{Name: "grep_files", Options: []ToolOption{
+			maxSize,
+			{Name: "max_results", Type: OptionInt, Description: "Default maximum matching lines", Default: tools.DefaultGrepMaxResults},
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewGrepTool(int64(o.Int("max_size"))).WithMaxResults(o.Int("max_results")), nil
+		}},
+		{Name: "http_request", Options: []ToolOption{
+			timeout(tools.DefaultToolTimeout),
+			{Name: "allowed_domains", Type: OptionStrings, Description: "Domain allowlist"},
+			{Name: "retries", Type: OptionInt, Description: "Retries for transient failures"},
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewHTTPTool(uint64(o.Int("timeout"))).
+				WithAllowedDomains(o.Strings("allowed_domains")).
+				WithRetries(o.Int("retries")), nil
+		}},
+		{Name: "tail_log", Options: []ToolOption{
+			{Name: "max_lines", Type: OptionInt, Description: "Maximum lines returned (0 = default)"},
+			allowedPaths,
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewTailLogTool(o.Int("max_lines")).WithAllowedPaths(o.Strings("allowed_paths")), nil
+		}},
+		{Name: "log_histogram", New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewLogHistogramTool(), nil
+		}},
+		{Name: "run_build", Options: []ToolOption{
+			timeout(tools.DefaultBuildTimeout),
+			{Name: "command", Type: OptionStrings, Description: "Build command and arguments (default: go|build|./...)"},
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			tool := tools.NewBuildTool(uint64(o.Int("timeout")))
+			if cmd := o.Strings("command"); len(cmd) > 0 {
+				tool = tool.WithCommand(cmd...)
+			}
+			return tool, nil
+		}},
+		{Name: "run_lint", Options: []ToolOption{
+			timeout(tools.DefaultBuildTimeout),
+			{Name: "command", Type: OptionStrings, Description: "Lint command and arguments (default: golangci-lint|run|./...)"},
+		}, New: func(o ToolOptions) (tools.Tool, error) {
+			tool := tools.NewLintTool(uint64(o.Int("timeout")))
+			if cmd := o.Strings("command"); len(cmd) > 0 {
+				tool = tool.WithCommand(cmd...)
+			}
+			return tool, nil
+		}},
+		{Name: "format_code", Options: []ToolOption{timeout(tools.DefaultToolTimeout), allowedPaths}, New: func(o ToolOptions) (tools.Tool, error) {
+			return tools.NewFormatTool(uint64(o.Int("timeout"))).WithAllowedPaths(o.Strings("allowed_paths")), nil
+		}},
+	}
+	for _, f := range factories {
+		_ = c.Register(f) // Names are unique
+	}
+	return c
+}
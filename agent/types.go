@@ -5,6 +5,7 @@ package agent
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/richinex/ariadne/model"
 	"github.com/richinex/ariadne/llm"
@@ -57,6 +58,30 @@ func (d *Decision) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// decisionFormat constrains Decision replies on providers that enforce
+// JSON schemas. Not strict, since action.input is free-form.
+var decisionFormat = func() *llm.ResponseFormat {
+	format := llm.NewJSONSchemaFormat("agent_decision", json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "thought": {"type": "string"},
+    "action": {
+      "type": ["object", "null"],
+      "properties": {
+        "tool": {"type": "string"},
+        "input": {"type": "object"}
+      },
+      "required": ["tool", "input"]
+    },
+    "is_final": {"type": "boolean"},
+    "final_answer": {"type": ["string", "null"]}
+  },
+  "required": ["thought", "is_final"]
+}`))
+	format.JSONSchema.Strict = false
+	return format
+}()
+
 // Action represents an action to execute a tool.
 type Action struct {
 	Tool  string          `json:"tool"`
@@ -76,6 +101,19 @@ type Metadata struct {
 	ToolCalls       []ToolCall
 	TokenUsage      *llm.TokenUsage
 	LLMCalls        int // Number of LLM calls made by this agent
+	// Malformed decisions: correction requests sent, and decisions that
+	// stayed invalid and were treated as thoughts
+	DecisionRepairs   int
+	DecisionFallbacks int
+	// MaskedPII counts PII masked in tool results during the run, by kind
+	// (see tools.PIIScanner); set by the caller that owns the scanner
+	MaskedPII map[string]int
+	// DSAUsage counts the run's result store use; set by the caller that
+	// owns the store (nil without one)
+	DSAUsage *model.DSAUsage
+	// Environment is where and with what the run executed; set by the
+	// caller that captured it (nil if not captured)
+	Environment *model.Environment
 }
 
 // ResponseType indicates the type of agent response.
@@ -92,7 +130,8 @@ type Response struct {
 	Type          ResponseType
 	Result        string // For Success
 	Error         string // For Failure
-	PartialResult string // For Timeout
+	PartialResult string // For Timeout (and last tool output when cancelled)
+	Err           error  // Failure class for Failure and Timeout; see errors.go
 	Steps         []Step
 	Metadata      Metadata
 }
@@ -125,11 +164,19 @@ func NewFailureResponse(err string, steps []Step, executionTimeMs uint64) Respon
 	}
 }
 
+// NewErrorResponse creates a failure response from a typed error.
+func NewErrorResponse(err error, steps []Step, executionTimeMs uint64) Response {
+	resp := NewFailureResponse(err.Error(), steps, executionTimeMs)
+	resp.Err = err
+	return resp
+}
+
 // NewTimeoutResponse creates a timeout response.
 func NewTimeoutResponse(steps []Step, toolCalls []ToolCall, executionTimeMs uint64, tokenUsage *llm.TokenUsage, llmCalls int) Response {
 	return Response{
 		Type:          ResponseTimeout,
 		PartialResult: "Max iterations reached",
+		Err:           ErrMaxIterations,
 		Steps:         steps,
 		Metadata: Metadata{
 			ExecutionTimeMs: executionTimeMs,
@@ -154,6 +201,21 @@ func (r Response) ResultText() string {
 	}
 }
 
+// AsError returns nil for success, otherwise the response's typed error.
+// Failures built without one fall back to an error with the Error text.
+func (r Response) AsError() error {
+	switch {
+	case r.Type == ResponseSuccess:
+		return nil
+	case r.Err != nil:
+		return r.Err
+	case r.Type == ResponseFailure:
+		return errors.New(r.Error)
+	default:
+		return ErrMaxIterations
+	}
+}
+
 // IsSuccess checks if the response was successful.
 func (r Response) IsSuccess() bool {
 	return r.Type == ResponseSuccess
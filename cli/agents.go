@@ -7,6 +7,8 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/richinex/ariadne/agent"
 	"github.com/richinex/ariadne/llm"
 	"github.com/richinex/ariadne/storage"
@@ -24,16 +26,45 @@ const (
 )
 
 const (
-	defaultMaxFileSize = 1024 * 1024 // 1MB
-	defaultTimeout     = 30          // seconds
+	defaultMaxFileSize  = 1024 * 1024 // 1MB
+	defaultTimeout      = 30          // seconds
+	defaultBuildTimeout = 300         // seconds, for run_build/run_lint
 )
 
 // CreateAgent creates an agent by name with the given provider.
-// resultStore is optional - if provided, enables RLM pattern with full ResultStore capabilities.
+// resultStore is optional - if provided, enables RLM pattern with full ResultStore capabilities;
+// storeSessionID is the session its tools read and write.
 // fileContext is optional - if provided, uses shared context for tracking stored files.
-func CreateAgent(name string, systemPrompt string, provider llm.Provider, toolConfig tools.ToolConfig, resultStore *storage.ResultStore, fileContext *tools.StoredFileContext) (*agent.Agent, error) {
+// A name of the form "name=tool+tool:opt=value" declares a custom agent with
+// exactly those tools (see agent.ParseAgentSpec).
+func CreateAgent(name string, systemPrompt string, provider llm.Provider, toolConfig tools.ToolConfig, resultStore *storage.ResultStore, storeSessionID string, fileContext *tools.StoredFileContext) (*agent.Agent, error) {
+	config, err := agentConfig(name, systemPrompt, resultStore, storeSessionID, fileContext)
+	if err != nil {
+		return nil, err
+	}
+	return agent.New(config, provider).WithToolConfig(toolConfig), nil
+}
+
+// agentConfig builds the configuration of the agent CreateAgent creates.
+func agentConfig(name string, systemPrompt string, resultStore *storage.ResultStore, storeSessionID string, fileContext *tools.StoredFileContext) (agent.Config, error) {
 	var builder *agent.Builder
 
+	agentName, specs, err := agent.ParseAgentSpec(name)
+	if err != nil {
+		return agent.Config{}, err
+	}
+	if specs != nil {
+		prompt := systemPrompt
+		if prompt == "" {
+			prompt = "You are a helpful assistant. Use available tools to complete tasks."
+		}
+		return agent.NewBuilder(agentName).
+			Description("Custom agent").
+			SystemPrompt(prompt).
+			ToolSpecs(specs).
+			TryBuild()
+	}
+
 	switch AgentType(name) {
 	case AgentGeneral:
 		prompt := systemPrompt
@@ -73,29 +104,34 @@ NEVER provide a summary without first retrieving actual content via get_lines.`
 		if fileContext == nil {
 			fileContext = tools.NewStoredFileContext()
 		}
-		sessionID := "file" // Default session for file operations
+		sessionID := storeSessionID
 
 		// Create ReadFileTool with optional ResultStore for RLM pattern
 		readTool := tools.NewReadFileTool(defaultMaxFileSize)
+		grepTool := tools.NewGrepTool(defaultMaxFileSize)
 		if resultStore != nil {
-			readTool = readTool.WithContentStore(resultStore).WithFileContext(fileContext)
+			readTool = readTool.WithContentStore(resultStore.SessionContent(sessionID)).WithFileContext(fileContext)
+			grepTool = grepTool.WithContentStore(resultStore.SessionContent(sessionID)).WithFileContext(fileContext)
 		}
 
+		edits := tools.NewEditTracker()
 		builder = agent.NewBuilder("file").
 			Description("File operations agent with search capabilities").
 			SystemPrompt(prompt).
 			Tool(readTool).
-			Tool(tools.NewWriteFileTool(defaultMaxFileSize)).
-			Tool(tools.NewAppendFileTool(defaultMaxFileSize)).
+			Tool(tools.NewWriteFileTool(defaultMaxFileSize).WithEditTracker(edits)).
+			Tool(tools.NewAppendFileTool(defaultMaxFileSize).WithEditTracker(edits)).
+			Tool(tools.NewFormatTool(defaultTimeout).WithEditTracker(edits)).
+			Tool(tools.NewStatFileTool()).
+			Tool(grepTool).
 			Tool(tools.NewRipgrepTool(defaultTimeout)).
-			Tool(tools.NewShellTool(defaultTimeout))
+			Tool(tools.NewShellTool(defaultTimeout)).
+			Tool(tools.NewBuildTool(defaultBuildTimeout)).
+			Tool(tools.NewLintTool(defaultBuildTimeout))
 
 		// Add ResultStore tools if available (full RLM capabilities)
 		if resultStore != nil {
-			builder = builder.
-				Tool(tools.NewSearchStoredTool(resultStore, sessionID, fileContext)).
-				Tool(tools.NewGetLinesTool(resultStore, sessionID, fileContext)).
-				Tool(tools.NewListStoredTool(resultStore, sessionID, fileContext))
+			builder = builder.Tools(newStoreTools(resultStore, sessionID, fileContext))
 		}
 
 	case AgentShell:
@@ -129,27 +165,41 @@ NEVER provide a summary without first retrieving actual content via get_lines.`
 			SystemPrompt(prompt)
 	}
 
-	config := builder.Build()
-	a := agent.New(config, provider).WithToolConfig(toolConfig)
-
-	return a, nil
+	return builder.Build(), nil
 }
 
 // CreateDefaultAgents creates the default set of agents for orchestration.
-// resultStore is optional - if provided, file agent will use RLM pattern.
+// resultStore is optional - if provided, file agent will use RLM pattern
+// in storeSessionID.
 // fileContext is optional - if provided, shares context across agents.
-func CreateDefaultAgents(provider llm.Provider, toolConfig tools.ToolConfig, resultStore *storage.ResultStore, fileContext *tools.StoredFileContext) []*agent.Agent {
+func CreateDefaultAgents(provider llm.Provider, toolConfig tools.ToolConfig, resultStore *storage.ResultStore, storeSessionID string, fileContext *tools.StoredFileContext) []*agent.Agent {
 	agents := make([]*agent.Agent, 0, 4)
 
 	for _, agentType := range []AgentType{AgentGeneral, AgentFile, AgentShell, AgentWeb} {
 		// Error is always nil for known agent types
-		a, _ := CreateAgent(string(agentType), "", provider, toolConfig, resultStore, fileContext)
+		a, _ := CreateAgent(string(agentType), "", provider, toolConfig, resultStore, storeSessionID, fileContext)
 		agents = append(agents, a)
 	}
 
 	return agents
 }
 
+// attachExamples adds each agent's examples from the library at
+// opts.ExamplesPath, if one is set.
+func attachExamples(agents []*agent.Agent, opts Options) error {
+	if opts.ExamplesPath == "" {
+		return nil
+	}
+	library, err := agent.LoadExampleLibrary(opts.ExamplesPath)
+	if err != nil {
+		return fmt.Errorf("failed to load examples: %w", err)
+	}
+	for _, a := range agents {
+		a.WithExamples(library.For(a.Name()), opts.ExampleBudget)
+	}
+	return nil
+}
+
 // ListAvailableAgents returns the names and descriptions of available agents.
 func ListAvailableAgents() []agent.AgentInfo {
 	return []agent.AgentInfo{
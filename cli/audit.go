@@ -0,0 +1,47 @@
+// Audit log listing for `ariadne audit`.
+//
+// Information Hiding:
+// - Database opening and report formatting hidden
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/storage"
+)
+
+// AuditList prints audit entries from the database at dbPath, oldest
+// first. See storage.AuditFilter for the selection.
+func AuditList(ctx context.Context, dbPath string, filter storage.AuditFilter) error {
+	if dbPath == "" {
+		dbPath = defaultDBPath
+	}
+	db, err := storage.OpenSqlite(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	entries, err := db.ListAudit(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No audit entries found.")
+		return nil
+	}
+
+	fmt.Printf("%-19s  %-20s %-36s %-20s %-14s %-8s %s\n",
+		"TIME (UTC)", "SESSION", "RUN", "AGENT", "TOOL", "STATUS", "ARGS SHA-256")
+	for _, e := range entries {
+		fmt.Printf("%-19s  %-20s %-36s %-20s %-14s %-8s %s\n",
+			time.Unix(e.CreatedAt, 0).UTC().Format("2006-01-02 15:04:05"),
+			text.TruncateRunes(e.SessionID, 20), e.RunID, text.TruncateRunes(e.AgentID, 20),
+			e.ToolName, e.Status, e.ArgsHash)
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// Batch execution of independent tasks.
+//
+// `ariadne batch tasks.jsonl` runs each task in the file with its own
+// agent and result store session, up to a concurrency cap at a time
+// (enforced by an orchestration.RunScheduler lane, in file order). All
+// tasks share the process-wide provider rate limiter (see ratelimit.go),
+// so parallel tasks queue against one per-minute budget. Each task's
+// outcome is written as JSON to the output directory, with a summary of
+// the whole batch.
+//
+// Information Hiding:
+// - Task file parsing hidden
+// - Worker pool and result file layout hidden
+
+package cli
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/orchestration"
+	"github.com/richinex/ariadne/tools"
+)
+
+// BatchTask is one line of a batch file.
+type BatchTask struct {
+	ID           string `json:"id"`   // Names the result file; defaults to task-<line>
+	Task         string `json:"task"` // Required
+	Agent        string `json:"agent,omitempty"`
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	MaxIter      int    `json:"max_iter,omitempty"`
+}
+
+// BatchResult is the outcome of one task, written to <out>/<id>.json.
+type BatchResult struct {
+	ID            string          `json:"id"`
+	Task          string          `json:"task"`
+	Agent         string          `json:"agent"`
+	Status        string          `json:"status"` // success, failure or timeout
+	Result        string          `json:"result,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	PartialResult string          `json:"partial_result,omitempty"`
+	Steps         int             `json:"steps"`
+	LLMCalls      int             `json:"llm_calls"`
+	ToolCalls     int             `json:"tool_calls"`
+	Tokens        uint32          `json:"tokens"`
+	DurationMs    int64           `json:"duration_ms"`
+	DSAUsage      *model.DSAUsage `json:"dsa_usage,omitempty"`
+}
+
+// BatchSummary aggregates a batch, written to <out>/summary.json.
+type BatchSummary struct {
+	Tasks      int      `json:"tasks"`
+	Succeeded  int      `json:"succeeded"`
+	Failed     int      `json:"failed"`
+	TimedOut   int      `json:"timed_out"`
+	Tokens     uint64   `json:"tokens"`
+	DurationMs int64    `json:"duration_ms"`
+	FailedIDs  []string `json:"failed_ids,omitempty"`
+}
+
+// Batch status values.
+const (
+	batchSuccess = "success"
+	batchFailure = "failure"
+	batchTimeout = "timeout"
+)
+
+// Batch runs every task in tasksPath, at most concurrency at a time, with
+// agentName for tasks that don't name an agent. Results go to outDir. It
+// returns an error if any task did not succeed.
+func Batch(ctx context.Context, tasksPath, outDir, agentName string, concurrency int, opts Options) error {
+	tasks, err := loadBatchTasks(tasksPath)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return fmt.Errorf("%s: no tasks", tasksPath)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	concurrency = max(1, min(concurrency, len(tasks)))
+
+	provider, err := createProvider(opts.Provider, opts)
+	if err != nil {
+		return err
+	}
+	if err := validateToolFilter(tools.NewToolFilter(opts.Tools, opts.DenyTools), nil); err != nil {
+		return err
+	}
+	if err := attachExamples(nil, opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("Running %d tasks, %d at a time. Results in %s\n\n", len(tasks), concurrency, outDir)
+
+	start := time.Now()
+	results := make([]BatchResult, len(tasks))
+	var mu sync.Mutex
+	done := 0
+	record := func(i int, result BatchResult) {
+		writeErr := writeJSONFile(filepath.Join(outDir, result.ID+".json"), result)
+
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = result
+		done++
+		fmt.Printf("[%d/%d] %s: %s (%s, %d tokens)\n", done, len(tasks), result.ID, result.Status,
+			(time.Duration(result.DurationMs) * time.Millisecond).Round(100*time.Millisecond), result.Tokens)
+		if writeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", result.ID, writeErr)
+		}
+	}
+
+	// Slots are acquired in file order, so tasks start in the order given
+	scheduler := orchestration.NewRunScheduler(concurrency)
+	lane := orchestration.SchedulerLane("batch", provider.Name())
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		task.Agent = cmp.Or(task.Agent, agentName)
+		release, err := scheduler.Acquire(ctx, lane, nil)
+		if err != nil {
+			record(i, BatchResult{ID: task.ID, Task: task.Task, Agent: task.Agent, Status: batchFailure, Error: err.Error()})
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+			record(i, runBatchTask(ctx, task, provider, opts))
+		}()
+	}
+	wg.Wait()
+
+	summary := summarizeBatch(results, time.Since(start))
+	if err := writeJSONFile(filepath.Join(outDir, "summary.json"), summary); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	printBatchSummary(summary)
+	printRateLimitStats()
+
+	if failed := summary.Failed + summary.TimedOut; failed > 0 {
+		return fmt.Errorf("%d of %d batch tasks did not succeed", failed, summary.Tasks)
+	}
+	return nil
+}
+
+// loadBatchTasks parses a JSONL batch file. Blank lines are skipped; ids
+// must be unique and usable as file names.
+func loadBatchTasks(path string) ([]BatchTask, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tasks []BatchTask
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var task BatchTask
+		if err := json.Unmarshal([]byte(text), &task); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if strings.TrimSpace(task.Task) == "" {
+			return nil, fmt.Errorf("%s:%d: task is required", path, line)
+		}
+		task.ID = cmp.Or(task.ID, fmt.Sprintf("task-%03d", line))
+		if task.ID == "summary" || task.ID != filepath.Base(task.ID) || strings.HasPrefix(task.ID, ".") {
+			return nil, fmt.Errorf("%s:%d: id %q can't be used as a file name", path, line, task.ID)
+		}
+		if seen[task.ID] {
+			return nil, fmt.Errorf("%s:%d: duplicate id %q", path, line, task.ID)
+		}
+		seen[task.ID] = true
+		tasks = append(tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tasks, nil
+}
+
+// runBatchTask runs one task quietly with its own result store session
+// and reports how it ended. Setup errors are reported as failures.
+func runBatchTask(ctx context.Context, task BatchTask, provider llm.Provider, opts Options) (result BatchResult) {
+	start := time.Now()
+	result = BatchResult{ID: task.ID, Task: task.Task, Agent: task.Agent, Status: batchFailure}
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	taskOpts := opts
+	taskOpts.StoreSession = ""
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, taskOpts)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	fileContext, prompt := preStoreFilesFromPrompt(ctx, task.Task, resultStore, storeSessionID)
+	toolConfig, err := newToolConfig(opts, provider, storeSessionID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer toolConfig.Audit.Close()
+	a, err := CreateAgent(task.Agent, task.SystemPrompt, provider, toolConfig, resultStore, storeSessionID, fileContext)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := attachExamples([]*agent.Agent{a}, opts); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx, cancel := withDeadline(ctx, opts)
+	defer cancel()
+	response := a.Execute(ctx, prompt, cmp.Or(task.MaxIter, opts.MaxIter))
+
+	switch response.Type {
+	case agent.ResponseSuccess:
+		result.Status = batchSuccess
+		result.Result = response.Result
+	case agent.ResponseTimeout:
+		result.Status = batchTimeout
+		result.PartialResult = response.PartialResult
+		if err := response.AsError(); err != nil {
+			result.Error = err.Error()
+		}
+	default:
+		result.Error = cmp.Or(response.Error, "unknown response type")
+	}
+	result.Steps = len(response.Steps)
+	result.LLMCalls = response.Metadata.LLMCalls
+	result.ToolCalls = len(response.Metadata.ToolCalls)
+	if usage := response.Metadata.TokenUsage; usage != nil {
+		result.Tokens = usage.TotalTokens
+	}
+	if resultStore != nil {
+		dsa := resultStore.Usage()
+		result.DSAUsage = &dsa
+	}
+	return result
+}
+
+// summarizeBatch aggregates task results.
+func summarizeBatch(results []BatchResult, elapsed time.Duration) BatchSummary {
+	summary := BatchSummary{Tasks: len(results), DurationMs: elapsed.Milliseconds()}
+	for _, r := range results {
+		summary.Tokens += uint64(r.Tokens)
+		switch r.Status {
+		case batchSuccess:
+			summary.Succeeded++
+		case batchTimeout:
+			summary.TimedOut++
+			summary.FailedIDs = append(summary.FailedIDs, r.ID)
+		default:
+			summary.Failed++
+			summary.FailedIDs = append(summary.FailedIDs, r.ID)
+		}
+	}
+	return summary
+}
+
+func printBatchSummary(summary BatchSummary) {
+	fmt.Printf("\nBatch: %d tasks, %d succeeded, %d failed, %d timed out in %s (%d tokens)\n",
+		summary.Tasks, summary.Succeeded, summary.Failed, summary.TimedOut,
+		(time.Duration(summary.DurationMs) * time.Millisecond).Round(time.Second), summary.Tokens)
+	if len(summary.FailedIDs) > 0 {
+		fmt.Printf("Did not succeed: %s\n", strings.Join(summary.FailedIDs, ", "))
+	}
+}
+
+// writeJSONFile writes v as indented JSON.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
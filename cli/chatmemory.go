@@ -0,0 +1,134 @@
+// Session continuity for react-chat.
+//
+// A resumed chat starts with more than its message history: the system
+// prompt lists recent episodic memories of earlier turns and the files
+// already stored in the ResultStore, so the agent can reach for
+// search_stored/get_lines instead of re-reading them. Each completed turn
+// is saved as an episodic memory; a turn that stops without an answer is
+// saved with a summary of its tool calls, so the next turn (or run) can
+// pick up from there.
+//
+// Information Hiding:
+// - Memory queries and formatting hidden
+// - Stored-file ordering and limits hidden
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/storage"
+)
+
+const (
+	chatMemoryLimit      = 5  // Episodic memories injected into the prompt
+	chatStoredFilesLimit = 20 // Stored files listed in the prompt
+	chatMemoryAgentID    = "react-chat"
+	chatPartialCallLimit = 5   // Tool calls summarized for an unfinished turn
+	chatPartialOutputLen = 300 // Bytes of each tool output in the summary
+)
+
+// chatMemoryToolsSection describes the memory tools added with --session.
+const chatMemoryToolsSection = `
+
+MEMORY (persists across runs of this session):
+- store_memory: Save a fact, decision or preference worth keeping
+- recall_memory: Look up saved memories by keyword`
+
+// sessionContext builds the system prompt section carrying a chat session
+// across runs. Returns "" when there is nothing to add.
+func sessionContext(ctx context.Context, memories storage.MemoryStorage, session string, resultStore *storage.ResultStore, storeSessionID string) string {
+	var sections []string
+
+	if memories != nil {
+		memType := storage.MemoryEpisodic
+		entries, err := memories.QueryMemories(ctx, session, &memType, storage.MemoryRankWindow)
+		if err == nil && len(entries) > 0 {
+			// Keep the highest-ranked, then restore newest-first order
+			storage.RankMemories(entries, time.Now(), storage.DefaultMemoryHalfLife)
+			if len(entries) > chatMemoryLimit {
+				entries = entries[:chatMemoryLimit]
+			}
+			sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt > entries[j].CreatedAt })
+			lines := make([]string, 0, len(entries))
+			for i := len(entries) - 1; i >= 0; i-- { // Oldest first
+				lines = append(lines, storage.DescribeMemory(entries[i]))
+			}
+			sections = append(sections, "## Earlier in this session\n"+strings.Join(lines, "\n"))
+		}
+	}
+
+	if resultStore != nil {
+		stored, err := resultStore.List(ctx, storeSessionID, storage.QueryOptions{})
+		if err == nil {
+			// Most recently used first; user_prompt is per-run, not a file
+			sort.Slice(stored, func(i, j int) bool { return stored[i].AccessedAt.After(stored[j].AccessedAt) })
+			var lines []string
+			for _, meta := range stored {
+				if meta.Key.Key == "user_prompt" {
+					continue
+				}
+				if len(lines) == chatStoredFilesLimit {
+					lines = append(lines, fmt.Sprintf("- ... and %d more (use list_stored)", len(stored)-chatStoredFilesLimit))
+					break
+				}
+				lines = append(lines, fmt.Sprintf("- %s (%d lines)", meta.Key.Key, meta.LineCount))
+			}
+			if len(lines) > 0 {
+				sections = append(sections, "## Already stored (use search_stored/get_lines, no need to re-read)\n"+strings.Join(lines, "\n"))
+			}
+		}
+	}
+
+	if len(sections) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.Join(sections, "\n\n")
+}
+
+// rememberTurn saves a completed chat turn, whose messages are turn, as
+// an episodic memory.
+func rememberTurn(ctx context.Context, memories storage.MemoryStorage, session, input, answer string, turn []llm.ChatMessage) {
+	if memories == nil {
+		return
+	}
+	episode := storage.Episode{Task: input, Outcome: storage.EpisodeSuccess, Result: answer}
+	for _, m := range turn {
+		for _, tc := range m.ToolCalls {
+			episode.AddToolCall(tc.Name, tc.Arguments)
+		}
+	}
+	entry := episode.Memory(session).WithAgent(chatMemoryAgentID)
+	_ = memories.StoreMemory(ctx, entry) // Best-effort memory storage
+}
+
+// partialTurn summarizes the last tool calls of a turn that ended without
+// an answer, for the chat history. Returns "" if the turn made none.
+func partialTurn(turn []llm.ChatMessage) string {
+	calls := make(map[string]llm.ToolCall)
+	var lines []string
+	for _, m := range turn {
+		for _, tc := range m.ToolCalls {
+			calls[tc.ID] = tc
+		}
+		if m.Role != "tool" {
+			continue
+		}
+		tc := calls[m.ToolCallID]
+		output := text.Truncate(m.Content, chatPartialOutputLen)
+		lines = append(lines, fmt.Sprintf("- %s(%s): %s", tc.Name, tc.Arguments, output))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	if len(lines) > chatPartialCallLimit {
+		lines = lines[len(lines)-chatPartialCallLimit:]
+	}
+	return "(Stopped before answering. Last tool calls and their output:)\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,83 @@
+// Terminal colors and sizes.
+//
+// Shared by the markdown renderer and any other output that styles text
+// for a terminal. Styles are plain ANSI SGR sequences, so they work in
+// any modern terminal without a dependency.
+//
+// Information Hiding:
+// - Escape sequences hidden behind paint
+// - Width measurement that skips escape sequences
+
+package cli
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/richinex/ariadne/internal/text"
+)
+
+// ANSI text styles, combined by concatenation.
+const (
+	styleBold      = "\033[1m"
+	styleDim       = "\033[2m"
+	styleItalic    = "\033[3m"
+	styleUnderline = "\033[4m"
+	styleRed       = "\033[31m"
+	styleGreen     = "\033[32m"
+	styleYellow    = "\033[33m"
+	styleBlue      = "\033[34m"
+	styleMagenta   = "\033[35m"
+	styleCyan      = "\033[36m"
+	styleReset     = "\033[0m"
+)
+
+// defaultTerminalWidth is used when the width can't be determined.
+const defaultTerminalWidth = 80
+
+// ansiSequence matches an SGR escape sequence.
+var ansiSequence = regexp.MustCompile(`\033\[[0-9;]*m`)
+
+// paint wraps s in style, resetting after it.
+func paint(style, s string) string {
+	if style == "" || s == "" {
+		return s
+	}
+	return style + s + styleReset
+}
+
+// colorEnabled reports whether stdout gets colors: it is a terminal and
+// NO_COLOR is not set.
+func colorEnabled() bool {
+	return os.Getenv("NO_COLOR") == "" && stdoutIsTerminal()
+}
+
+// colorize is paint for stdout, leaving s plain unless colorEnabled.
+func colorize(style, s string) string {
+	if !colorEnabled() {
+		return s
+	}
+	return paint(style, s)
+}
+
+// visibleWidth is the number of columns s takes, not counting escape
+// sequences.
+func visibleWidth(s string) int {
+	return text.Width(ansiSequence.ReplaceAllString(s, ""))
+}
+
+// terminalWidth returns the width of the terminal w writes to, then
+// $COLUMNS, then defaultTerminalWidth.
+func terminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok {
+		if width := fileTerminalWidth(f); width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
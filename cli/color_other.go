@@ -0,0 +1,10 @@
+//go:build !unix
+
+package cli
+
+import "os"
+
+// fileTerminalWidth is not available here; callers fall back to $COLUMNS.
+func fileTerminalWidth(f *os.File) int {
+	return 0
+}
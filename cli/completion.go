@@ -0,0 +1,66 @@
+// Dynamic values for shell completion.
+//
+// Candidates use the "value\tdescription" form understood by cobra's
+// completion scripts. Lookups never fail: a missing database simply yields
+// no candidates, so completing never creates files.
+//
+// Information Hiding:
+// - Database access for session lookup hidden
+
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/storage"
+)
+
+// CompleteProviders returns the provider names accepted by --provider.
+func CompleteProviders() []string {
+	types := llm.ProviderTypes()
+	names := make([]string, len(types))
+	for i, p := range types {
+		names[i] = p.String() + "\tdefault model " + p.DefaultModel()
+	}
+	return append(names, llm.DryProviderName+"\tprint prompts without calling an LLM")
+}
+
+// CompleteAgents returns the agent preset names accepted by --agent.
+func CompleteAgents() []string {
+	agents := ListAvailableAgents()
+	names := make([]string, len(agents))
+	for i, a := range agents {
+		names[i] = a.Name + "\t" + a.Description
+	}
+	return names
+}
+
+// CompleteWorkflows returns the built-in workflow names.
+func CompleteWorkflows() []string {
+	names := make([]string, len(workflows))
+	for i, w := range workflows {
+		names[i] = w.Name + "\t" + w.Description
+	}
+	return names
+}
+
+// CompleteSessions returns the session IDs stored in the database at
+// dbPath, most recently updated first.
+func CompleteSessions(ctx context.Context, dbPath string) []string {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil
+	}
+	store, err := storage.OpenSqlite(dbPath)
+	if err != nil {
+		return nil
+	}
+	defer store.Close()
+
+	sessions, err := store.ListSessions(ctx)
+	if err != nil {
+		return nil
+	}
+	return sessions
+}
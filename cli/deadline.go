@@ -0,0 +1,114 @@
+// Run deadlines from the global --timeout flag.
+//
+// The deadline is set once at the top of a command (per turn for
+// react-chat), so provider calls, tools and MCP clients all see the same
+// context and stop together. When it passes, the runner prints what was
+// done so far instead of a bare error.
+//
+// Information Hiding:
+// - Deadline context construction hidden
+// - Partial-result extraction from the conversation hidden
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/orchestration"
+)
+
+// maxPartialBytes caps the partial result printed when a run is interrupted.
+const maxPartialBytes = 2000
+
+// withDeadline applies opts.Timeout to ctx. A zero timeout only adds cancel.
+func withDeadline(ctx context.Context, opts Options) (context.Context, context.CancelFunc) {
+	if opts.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(opts.Timeout)*time.Second)
+}
+
+// interrupted returns nil while ctx is live. Once it has ended, it prints
+// the partial result in messages and returns the cancellation error.
+func interrupted(ctx context.Context, messages []llm.ChatMessage, opts Options) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	progress := fmt.Sprintf("Completed %d tool calls.", countToolResults(messages))
+	printInterrupted(ctx.Err(), opts, progress, partialResult(messages))
+	return agent.CancelledError(ctx.Err())
+}
+
+// printInterrupted reports an interrupted run with what it produced so far.
+func printInterrupted(err error, opts Options, progress, partial string) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Printf("\nTimed out after %ds (--timeout). %s\n", opts.Timeout, progress)
+	} else {
+		fmt.Printf("\nCancelled. %s\n", progress)
+	}
+	if partial != "" {
+		if len(partial) > maxPartialBytes {
+			partial = text.Head(partial, maxPartialBytes) + "\n... (truncated)"
+		}
+		fmt.Printf("Partial result:\n%s\n", partial)
+	}
+}
+
+// partialResult returns the latest assistant text, or failing that the
+// latest tool output, from the current turn.
+func partialResult(messages []llm.ChatMessage) string {
+	var lastTool string
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Role == "user" || msg.Role == "system" {
+			break
+		}
+		if msg.Role == "assistant" && strings.TrimSpace(msg.Content) != "" {
+			return msg.Content
+		}
+		if msg.Role == "tool" && lastTool == "" {
+			lastTool = msg.Content
+		}
+	}
+	return lastTool
+}
+
+// orchestrationPartial formats the sub-goal results of an interrupted
+// orchestration.
+func orchestrationPartial(response orchestration.Response) string {
+	if response.Metadata == nil || len(response.Metadata.PartialResults) == 0 {
+		return ""
+	}
+	ids := make([]string, 0, len(response.Metadata.PartialResults))
+	for id := range response.Metadata.PartialResults {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var b strings.Builder
+	for _, id := range ids {
+		fmt.Fprintf(&b, "[%s] %s\n", id, response.Metadata.PartialResults[id])
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// countToolResults counts tool messages in the current turn.
+func countToolResults(messages []llm.ChatMessage) int {
+	n := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		switch messages[i].Role {
+		case "user", "system":
+			return n
+		case "tool":
+			n++
+		}
+	}
+	return n
+}
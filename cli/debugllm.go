@@ -0,0 +1,43 @@
+// LLM wire logging for --debug-llm.
+//
+// Information Hiding:
+// - Log location and one shared log per process hidden
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/richinex/ariadne/llm"
+)
+
+// wireLogPath is where --debug-llm writes provider traffic.
+const wireLogPath = ".ariadne/llm-wire.jsonl"
+
+var (
+	wireLogOnce sync.Once
+	wireLog     *llm.WireLog
+	wireLogErr  error
+)
+
+// debugWireLog returns the process-wide wire log, opening it on first use.
+// Providers share it so rotation sees every write.
+func debugWireLog(opts Options) (*llm.WireLog, error) {
+	wireLogOnce.Do(func() {
+		if err := os.MkdirAll(filepath.Dir(wireLogPath), 0755); err != nil {
+			wireLogErr = fmt.Errorf("failed to create wire log directory: %w", err)
+			return
+		}
+		wireLog, wireLogErr = llm.OpenWireLog(llm.WireLogOptions{
+			Path:           wireLogPath,
+			IncludeContent: opts.DebugLLMContent,
+		})
+		if wireLogErr == nil {
+			fmt.Fprintf(os.Stderr, "Logging LLM traffic to %s\n", wireLogPath)
+		}
+	})
+	return wireLog, wireLogErr
+}
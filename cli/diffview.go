@@ -0,0 +1,67 @@
+// Colored diffs of agent file changes.
+//
+// With --diff, or --verbose, every change write_file, append_file,
+// edit_file and format_code make is printed as a unified diff while the
+// run goes on, so agent modifications can be reviewed as they happen. On
+// a terminal, removed lines are red, added lines green and hunk headers
+// cyan.
+//
+// Information Hiding:
+// - Diff line classification and coloring
+// - Cutting long diffs
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/richinex/ariadne/tools"
+)
+
+// maxDiffViewLines bounds a printed diff; a new file's full content would
+// otherwise flood the terminal.
+const maxDiffViewLines = 200
+
+// printFileChange prints a file change as a diff.
+func printFileChange(change tools.FileChange) {
+	printLive(formatDiff(change.Diff(), colorEnabled()) + "\n")
+}
+
+// formatDiff cuts a unified diff to maxDiffViewLines lines and, if color
+// is set, colors it.
+func formatDiff(diff string, color bool) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	var cut int
+	if len(lines) > maxDiffViewLines {
+		cut = len(lines) - maxDiffViewLines
+		lines = lines[:maxDiffViewLines]
+	}
+
+	var sb strings.Builder
+	for _, line := range lines {
+		style := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			style = styleBold
+		case strings.HasPrefix(line, "@@"):
+			style = styleCyan
+		case strings.HasPrefix(line, "-"):
+			style = styleRed
+		case strings.HasPrefix(line, "+"):
+			style = styleGreen
+		}
+		if color {
+			line = paint(style, line)
+		}
+		sb.WriteString(line + "\n")
+	}
+	if cut > 0 {
+		note := fmt.Sprintf("... %d more diff lines", cut)
+		if color {
+			note = paint(styleDim, note)
+		}
+		sb.WriteString(note + "\n")
+	}
+	return sb.String()
+}
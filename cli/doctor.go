@@ -0,0 +1,311 @@
+// Environment diagnosis for `ariadne doctor`.
+//
+// Each check reports ok, warn or fail with a fix. Warnings cover optional
+// pieces (one provider key is enough; npx is only needed for npx-based MCP
+// servers); failures mean no command will work until fixed.
+//
+// Information Hiding:
+// - Provider pings, binary lookup and database probing hidden
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/config"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/mcp"
+	"github.com/richinex/ariadne/storage"
+)
+
+// doctorPingTimeout bounds each provider ping.
+const doctorPingTimeout = 20 * time.Second
+
+type checkStatus string
+
+const (
+	checkOK   checkStatus = "ok"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+)
+
+// doctorCheck is the outcome of one check.
+type doctorCheck struct {
+	name   string
+	status checkStatus
+	detail string
+	fix    string // Empty when nothing needs doing
+}
+
+// DoctorOptions selects what Doctor checks.
+type DoctorOptions struct {
+	DBPath        string // Database to probe (default .ariadne/ariadne.db)
+	MCPConfigPath string // Optional MCP config to validate
+	Ping          bool   // Send a one-line request to each provider with a key
+}
+
+// Doctor checks provider keys, external binaries, the database and the
+// MCP config, and prints a fix for each problem. It returns an error if
+// any check failed.
+func Doctor(ctx context.Context, opts DoctorOptions) error {
+	if opts.DBPath == "" {
+		opts.DBPath = defaultDBPath
+	}
+
+	var checks []doctorCheck
+	checks = append(checks, checkProviders(ctx, opts.Ping)...)
+	checks = append(checks, checkBinary("ripgrep", "rg",
+		"the ripgrep tool is unavailable (grep_files still works)",
+		"install ripgrep: https://github.com/BurntSushi/ripgrep#installation"))
+	checks = append(checks, checkBinary("npx", "npx",
+		"npx-based MCP servers cannot start",
+		"install Node.js (includes npx): https://nodejs.org"))
+	checks = append(checks, checkDatabase(ctx, opts.DBPath))
+	if opts.MCPConfigPath != "" {
+		checks = append(checks, checkMCPConfig(opts.MCPConfigPath)...)
+	}
+
+	failed, warned := 0, 0
+	for _, c := range checks {
+		fmt.Printf("[%-4s] %-12s %s\n", c.status, c.name, c.detail)
+		if c.fix != "" {
+			fmt.Printf("       %-12s fix: %s\n", "", c.fix)
+		}
+		switch c.status {
+		case checkFail:
+			failed++
+		case checkWarn:
+			warned++
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed (%d warnings)", failed, warned)
+	}
+	fmt.Printf("No problems found (%d warnings)\n", warned)
+	return nil
+}
+
+// checkProviders reports each provider's key, pinging those that have one.
+// Missing keys are warnings unless no provider is usable.
+func checkProviders(ctx context.Context, ping bool) []doctorCheck {
+	var checks []doctorCheck
+	usable := 0
+	for _, p := range llm.ProviderTypes() {
+		name := p.String()
+		key, err := config.APIKeyFor(name)
+		if err != nil {
+			checks = append(checks, doctorCheck{
+				name:   name,
+				status: checkWarn,
+				detail: fmt.Sprintf("no API key: %v", err),
+				fix:    fmt.Sprintf("export %s=... or configure ARIADNE_SECRETS_BACKEND", p.EnvVar()),
+			})
+			continue
+		}
+		if key == "" {
+			// Optional key (bedrock, gemini on Vertex AI): the provider
+			// authenticates with cloud credentials, which building it resolves
+			builder, _, err := doctorBuilder(p)
+			if err == nil {
+				_, err = builder.APIKey("")
+			}
+			if err != nil {
+				checks = append(checks, doctorCheck{
+					name:   name,
+					status: checkWarn,
+					detail: fmt.Sprintf("no credentials: %v", err),
+					fix:    credentialsFix(p),
+				})
+				continue
+			}
+		}
+		if !ping {
+			usable++
+			detail := "API key found (not pinged)"
+			if key == "" {
+				detail = "cloud credentials found (not pinged)"
+			}
+			checks = append(checks, doctorCheck{name: name, status: checkOK, detail: detail})
+			continue
+		}
+		checks = append(checks, pingProvider(ctx, p, key))
+		if checks[len(checks)-1].status == checkOK {
+			usable++
+		}
+	}
+	if usable == 0 {
+		for i := range checks {
+			if checks[i].status == checkWarn {
+				checks[i].status = checkFail
+			}
+		}
+	}
+	return checks
+}
+
+// pingProvider checks the key, model and connectivity with llm.Validate.
+func pingProvider(ctx context.Context, p llm.ProviderType, key string) doctorCheck {
+	name := p.String()
+	builder, model, err := doctorBuilder(p)
+	if err != nil {
+		return doctorCheck{name: name, status: checkFail, detail: err.Error()}
+	}
+	provider, err := builder.MaxTokens(16).APIKey(key)
+	if err != nil {
+		return doctorCheck{name: name, status: checkFail, detail: fmt.Sprintf("cannot create provider: %v", err)}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorPingTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := llm.Validate(ctx, provider); err != nil {
+		return doctorCheck{
+			name:   name,
+			status: checkFail,
+			detail: fmt.Sprintf("ping failed (%s): %v", model, err),
+			fix:    fmt.Sprintf("check %s is valid and %s is reachable, or set %s_MODEL", p.EnvVar(), name, strings.ToUpper(name)),
+		}
+	}
+	return doctorCheck{
+		name:   name,
+		status: checkOK,
+		detail: fmt.Sprintf("ping succeeded (%s, %s)", model, time.Since(start).Round(time.Millisecond)),
+	}
+}
+
+// doctorBuilder configures p's model and backend from the environment.
+func doctorBuilder(p llm.ProviderType) (*llm.ProviderBuilder, string, error) {
+	model, err := config.ModelFor(p.String())
+	if err != nil {
+		return nil, "", err
+	}
+	builder := p.Model(model)
+	project, location, err := config.VertexFor(p.String())
+	if err != nil {
+		return nil, "", err
+	}
+	if project != "" {
+		builder = builder.Vertex(project, location)
+	}
+	return builder, model, nil
+}
+
+// credentialsFix suggests how to give a keyless provider credentials.
+func credentialsFix(p llm.ProviderType) string {
+	if p == llm.ProviderGemini {
+		return "run gcloud auth application-default login or set GOOGLE_APPLICATION_CREDENTIALS"
+	}
+	return fmt.Sprintf("set AWS_REGION and AWS_PROFILE or AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY, or export %s=...", p.EnvVar())
+}
+
+// checkBinary warns when an optional executable is not on PATH.
+func checkBinary(name, executable, impact, fix string) doctorCheck {
+	path, err := exec.LookPath(executable)
+	if err != nil {
+		return doctorCheck{name: name, status: checkWarn, detail: fmt.Sprintf("%s not found on PATH: %s", executable, impact), fix: fix}
+	}
+	return doctorCheck{name: name, status: checkOK, detail: path}
+}
+
+// checkDatabase verifies the database directory is writable and an
+// existing database opens. It never creates the database itself.
+func checkDatabase(ctx context.Context, dbPath string) doctorCheck {
+	dir := filepath.Dir(dbPath)
+	probeDir := dir
+	for {
+		if _, err := os.Stat(probeDir); err == nil {
+			break
+		}
+		parent := filepath.Dir(probeDir)
+		if parent == probeDir {
+			break
+		}
+		probeDir = parent
+	}
+	probe, err := os.CreateTemp(probeDir, ".ariadne-doctor-*")
+	if err != nil {
+		return doctorCheck{
+			name:   "database",
+			status: checkFail,
+			detail: fmt.Sprintf("%s is not writable: %v", probeDir, err),
+			fix:    "fix the directory permissions or pass --db with a writable path",
+		}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return doctorCheck{name: "database", status: checkOK, detail: fmt.Sprintf("%s will be created on first use", dbPath)}
+	}
+	db, err := storage.OpenSqlite(dbPath)
+	if errors.Is(err, storage.ErrSchemaVersion) {
+		return doctorCheck{
+			name:   "database",
+			status: checkFail,
+			detail: fmt.Sprintf("%s: %v", dbPath, err),
+			fix:    fmt.Sprintf("ariadne migrate --db %s", dbPath),
+		}
+	}
+	if err != nil {
+		return doctorCheck{
+			name:   "database",
+			status: checkFail,
+			detail: fmt.Sprintf("%s cannot be opened: %v", dbPath, err),
+			fix:    "move the file aside to start fresh, or pass --db with another path",
+		}
+	}
+	defer db.Close()
+	sessions, err := db.ListSessions(ctx)
+	if err != nil {
+		return doctorCheck{name: "database", status: checkFail, detail: fmt.Sprintf("%s: %v", dbPath, err)}
+	}
+	return doctorCheck{name: "database", status: checkOK, detail: fmt.Sprintf("%s (%d sessions)", dbPath, len(sessions))}
+}
+
+// checkMCPConfig parses an MCP config and checks each server's command
+// resolves.
+func checkMCPConfig(path string) []doctorCheck {
+	cfg, err := mcp.LoadConfig(path)
+	if err != nil {
+		return []doctorCheck{{name: "mcp", status: checkFail, detail: err.Error(), fix: "fix the JSON; see the mcpServers format in README"}}
+	}
+	if len(cfg.MCPServers) == 0 {
+		return []doctorCheck{{name: "mcp", status: checkWarn, detail: fmt.Sprintf("%s defines no servers", path), fix: `add servers under "mcpServers"`}}
+	}
+
+	names := make([]string, 0, len(cfg.MCPServers))
+	for name := range cfg.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []doctorCheck
+	for _, name := range names {
+		server := cfg.MCPServers[name]
+		label := "mcp:" + name
+		if server.Command == "" {
+			checks = append(checks, doctorCheck{name: label, status: checkFail, detail: "no command", fix: `set "command" for this server`})
+			continue
+		}
+		if _, err := exec.LookPath(server.Command); err != nil {
+			fix := fmt.Sprintf("install %s or use an absolute path", server.Command)
+			if server.Command == "npx" || server.Command == "node" {
+				fix = "install Node.js (includes npx): https://nodejs.org"
+			}
+			checks = append(checks, doctorCheck{name: label, status: checkFail, detail: fmt.Sprintf("command %q not found", server.Command), fix: fix})
+			continue
+		}
+		checks = append(checks, doctorCheck{name: label, status: checkOK, detail: strings.Join(append([]string{server.Command}, server.Args...), " ")})
+	}
+	return checks
+}
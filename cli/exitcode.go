@@ -0,0 +1,80 @@
+// Exit codes and quiet mode for CI use.
+//
+// Commands map their error to a documented exit code so pipelines can gate
+// on the outcome without parsing output:
+//
+//	0  success
+//	1  other failure (bad flags, setup errors, stalled loops)
+//	2  partial result: iteration limit, --timeout or cancellation
+//	3  policy violation: a tool refused a call under its access policy
+//	4  provider error: an LLM call failed
+//
+// A dry run (--provider dry) stops at its first LLM call and exits 0.
+//
+// With --quiet, the final answer is the only thing written to stdout.
+//
+// Information Hiding:
+// - Error classification hidden
+// - Stdout redirection while quiet hidden
+
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+)
+
+// Exit codes returned by ExitCode.
+const (
+	ExitSuccess  = 0
+	ExitFailure  = 1
+	ExitPartial  = 2
+	ExitPolicy   = 3
+	ExitProvider = 4
+)
+
+// ExitCode classifies a command error into an exit code.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, llm.ErrDryRun):
+		return ExitSuccess
+	case errors.Is(err, agent.ErrToolDenied):
+		return ExitPolicy
+	case errors.Is(err, agent.ErrLLM):
+		return ExitProvider
+	case errors.Is(err, agent.ErrMaxIterations), errors.Is(err, agent.ErrCancelled):
+		return ExitPartial
+	default:
+		return ExitFailure
+	}
+}
+
+// answerOut receives final answers. It is the real stdout even while quiet
+// mode has silenced everything else.
+var answerOut io.Writer = os.Stdout
+
+// beginQuiet silences stdout when opts.Quiet is set, keeping answerOut on
+// the real stdout. The returned function restores it.
+func beginQuiet(opts Options) func() {
+	if !opts.Quiet {
+		return func() {}
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return func() {}
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	answerOut = stdout
+	return func() {
+		os.Stdout = stdout
+		answerOut = stdout
+		devNull.Close()
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/richinex/ariadne/tools"
+)
+
+func TestExtensionToolsNeedExplicitDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not supported")
+	}
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(tools.DefaultExternalToolsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// The executable leaves a marker whenever it is run
+	script := "#!/bin/sh\ntouch ran\necho '{\"name\":\"probe\",\"description\":\"Probe\",\"parameters\":[]}'\n"
+	if err := os.WriteFile(filepath.Join(tools.DefaultExternalToolsDir, "probe"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded := withExtensionTools(context.Background(), "", nil, nil); len(loaded) != 0 {
+		t.Errorf("expected no tools without a directory, got %d", len(loaded))
+	}
+	if _, err := os.Stat("ran"); err == nil {
+		t.Fatal("an executable in the default tools directory was run without being enabled")
+	}
+
+	loaded := withExtensionTools(context.Background(), tools.DefaultExternalToolsDir, nil, nil)
+	if len(loaded) != 1 || loaded[0].Metadata().Name != "probe" {
+		t.Errorf("expected the probe tool once enabled, got %d tools", len(loaded))
+	}
+}
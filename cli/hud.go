@@ -0,0 +1,105 @@
+// Live run status for --verbose.
+//
+// After each iteration of react-run and rlm, and each step of
+// react-orchestrate, verbose runs print one status line: iteration, tokens
+// so far, estimated cost, elapsed time and bytes kept out of the context.
+// It is there to help decide whether to stop an expensive run early.
+//
+// Information Hiding:
+// - Cost estimation across models hidden
+// - Line formatting hidden
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/orchestration"
+)
+
+// runHUD prints status lines for one run.
+type runHUD struct {
+	start time.Time
+}
+
+func newRunHUD() *runHUD {
+	return &runHUD{start: time.Now()}
+}
+
+// print writes the status line after iteration.
+func (h *runHUD) print(iteration int, usage llm.TokenUsage, cost runCost, bytesSaved int64) {
+	fmt.Printf("[status] iter %d | %d tokens | %s | %s elapsed | %s saved\n",
+		iteration, usage.TotalTokens, cost, time.Since(h.start).Round(time.Second), formatBytes(bytesSaved))
+}
+
+// modelUsage is token usage on one model.
+type modelUsage struct {
+	model string
+	usage llm.TokenUsage
+}
+
+// runCost is an estimated cost; known is false if a model has no price.
+type runCost struct {
+	usd   float64
+	known bool
+}
+
+func (c runCost) String() string {
+	if !c.known {
+		return "cost n/a"
+	}
+	return fmt.Sprintf("~$%.4f", c.usd)
+}
+
+// estimateCost sums the estimated cost of each model's usage.
+func estimateCost(parts ...modelUsage) runCost {
+	total := runCost{known: true}
+	for _, p := range parts {
+		if p.usage.TotalTokens == 0 {
+			continue
+		}
+		usd, ok := llm.EstimateCost(p.model, p.usage)
+		if !ok {
+			return runCost{}
+		}
+		total.usd += usd
+	}
+	return total
+}
+
+// formatBytes renders n as B, KB or MB.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// withStatusHUD prints a status line after each supervisor step when
+// opts.Verbose is set. Usage is priced at model, the agents' model.
+func withStatusHUD(supervisor *orchestration.Supervisor, opts Options, model string) *orchestration.Supervisor {
+	if !opts.Verbose {
+		return supervisor
+	}
+	hud := newRunHUD()
+	return supervisor.WithStepObserver(func(step int, stats orchestration.TokenStats) {
+		usage := llm.TokenUsage{
+			PromptTokens:       stats.PromptTokens,
+			CompletionTokens:   stats.CompletionTokens,
+			TotalTokens:        stats.TotalTokens,
+			CachedPromptTokens: stats.CachedPromptTokens,
+		}
+		hud.print(step, usage, estimateCost(modelUsage{model, usage}), int64(stats.BytesSaved))
+	})
+}
+
+func addTokenUsage(a, b llm.TokenUsage) llm.TokenUsage {
+	a.Add(&b)
+	return a
+}
@@ -0,0 +1,277 @@
+// Native tool-calling loop shared by react-run and the RLM root agent.
+//
+// The loop records what it does in the same shapes agent.Agent produces:
+// one model.Step per tool call, model.ToolCall metrics, token usage and an
+// agent.Response, so both commands report runs the same way.
+//
+// Information Hiding:
+// - Tool dispatch, observation capping and stall checks hidden
+// - Step and metadata accumulation hidden
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/tools"
+)
+
+// toolLoop configures one run of the native tool-calling loop.
+type toolLoop struct {
+	name         string // Agent name, also the verbose log prefix
+	provider     llm.Provider
+	tools        []tools.Tool
+	executor     *tools.Executor
+	usage        *tools.UsageTracker
+	observations *tools.ObservationBudget
+	maxIter      int
+	wrapUp       tools.WrapUpPolicy
+	verbose      bool
+	onLLMCall    func() // Optional metrics hooks
+	onToolCall   func()
+	// Optional, called after each tool-calling iteration with the run's
+	// token usage so far
+	onIteration func(iteration int, usage llm.TokenUsage)
+	// Optional, appended to the system message before each call (e.g. a
+	// notebook digest), replacing the previous one
+	systemDigest func(ctx context.Context) string
+	// Optional, the live progress line of non-verbose runs
+	progress *progressLine
+}
+
+// loopRun accumulates the record of one run.
+type loopRun struct {
+	start      time.Time
+	steps      []model.Step
+	toolCalls  []model.ToolCall
+	tokenUsage llm.TokenUsage
+	llmCalls   int
+	lastErr    error // Denied calls are reported if the loop stalls on them
+}
+
+func (r *loopRun) elapsedMs() uint64 {
+	return uint64(time.Since(r.start).Milliseconds())
+}
+
+// failure builds a failure response that keeps the run's record.
+func (r *loopRun) failure(err error, partial string) agent.Response {
+	resp := agent.NewErrorResponse(err, r.steps, r.elapsedMs())
+	resp.PartialResult = partial
+	resp.Metadata.ToolCalls = r.toolCalls
+	resp.Metadata.TokenUsage = &r.tokenUsage
+	resp.Metadata.LLMCalls = r.llmCalls
+	return resp
+}
+
+// run executes the loop until the model answers without tool calls, the
+// iteration limit is reached, the loop stalls, or ctx ends.
+func (l *toolLoop) run(ctx context.Context, messages []llm.ChatMessage) agent.Response {
+	run := &loopRun{start: time.Now()}
+	toolMap := make(map[string]tools.Tool, len(l.tools))
+	for _, t := range l.tools {
+		toolMap[t.Metadata().Name] = t
+	}
+	stall := tools.NewStallDetector()
+
+	var baseSystem string
+	if l.systemDigest != nil && len(messages) > 0 && messages[0].Role == "system" {
+		messages = append([]llm.ChatMessage(nil), messages...) // Don't touch the caller's
+		baseSystem = messages[0].Content
+	}
+
+	defer l.progress.finish()
+	for i := 0; i < l.maxIter; i++ {
+		if ctx.Err() != nil {
+			return run.failure(agent.CancelledError(ctx.Err()), partialResult(messages))
+		}
+		l.progress.advance(i, "thinking")
+
+		if l.verbose {
+			fmt.Printf("[%s:%d] Processing...\n", l.name, i)
+		}
+
+		if baseSystem != "" {
+			messages[0].Content = baseSystem
+			if digest := l.systemDigest(ctx); digest != "" {
+				messages[0].Content += "\n\n" + digest
+			}
+		}
+
+		// At the wrap-up threshold, ask for the answer instead of more calls
+		remaining := l.maxIter - i
+		forced := l.wrapUp.ForcesAnswer(remaining)
+		if forced {
+			messages = withNote(messages, l.wrapUp.SynthesisPrompt())
+		}
+
+		response, err := llm.ChatWithTools(ctx, l.provider, messages, convertToToolDefs(l.tools))
+		if l.onLLMCall != nil {
+			l.onLLMCall()
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return run.failure(agent.CancelledError(ctx.Err()), partialResult(messages))
+			}
+			return run.failure(agent.LLMError(err), partialResult(messages))
+		}
+		run.llmCalls++
+		run.tokenUsage.Add(response.Usage)
+
+		// No tool calls - final answer. A synthesis step's reply is the
+		// answer either way; its tool calls are not run.
+		if len(response.ToolCalls) == 0 || forced {
+			answer := response.Content
+			if strings.TrimSpace(answer) == "" {
+				answer = partialResult(messages)
+			}
+			run.steps = append(run.steps, model.Step{Iteration: i, Thought: response.Content, Observation: &answer})
+			return agent.NewSuccessResponse(answer, run.steps, run.toolCalls, run.elapsedMs(), l.name, &run.tokenUsage, run.llmCalls)
+		}
+
+		if l.verbose {
+			fmt.Printf("[%s:%d] %s\n", l.name, i, response.Content)
+			for _, tc := range response.ToolCalls {
+				args := text.Truncate(string(tc.Arguments), 100)
+				fmt.Println(colorize(styleYellow, fmt.Sprintf("[%s:%d] Calling: %s(%s)", l.name, i, tc.Name, args)))
+			}
+		}
+
+		// Add assistant message
+		messages = append(messages, llm.ChatMessage{
+			Role:      "assistant",
+			Content:   response.Content,
+			ToolCalls: response.ToolCalls,
+		})
+
+		// Execute tool calls
+		for _, tc := range response.ToolCalls {
+			output := l.callTool(ctx, run, toolMap, tc, i)
+			messages = append(messages, llm.ChatMessage{
+				Role:       "tool",
+				Content:    output,
+				ToolCallID: tc.ID,
+			})
+			action := tc.Name
+			run.steps = append(run.steps, model.Step{Iteration: i, Thought: response.Content, Action: &action, Observation: &output})
+		}
+		if warning := l.wrapUp.Warning(remaining); warning != "" {
+			messages = withNote(messages, warning)
+		}
+
+		// Stop or nudge the agent if it keeps repeating itself
+		if err := stall.CheckToolRound(response.ToolCalls, messages); err != nil {
+			if errors.Is(run.lastErr, agent.ErrToolDenied) {
+				// Repeating a denied call: report the denial too
+				err = fmt.Errorf("%w: %w", err, run.lastErr)
+			}
+			return run.failure(err, partialResult(messages))
+		}
+
+		if l.onIteration != nil {
+			l.onIteration(i+1, run.tokenUsage)
+		}
+	}
+
+	resp := agent.NewTimeoutResponse(run.steps, run.toolCalls, run.elapsedMs(), &run.tokenUsage, run.llmCalls)
+	if partial := partialResult(messages); partial != "" {
+		resp.PartialResult = partial
+	}
+	return resp
+}
+
+// withNote returns a copy of messages with note appended to the last
+// message, so it reaches the model without a turn of its own.
+func withNote(messages []llm.ChatMessage, note string) []llm.ChatMessage {
+	messages = append([]llm.ChatMessage(nil), messages...)
+	last := &messages[len(messages)-1]
+	last.Content += "\n\n" + note
+	return messages
+}
+
+// callTool executes one tool call, records it, and returns the observation.
+func (l *toolLoop) callTool(ctx context.Context, run *loopRun, toolMap map[string]tools.Tool, tc llm.ToolCall, iteration int) string {
+	tool, exists := toolMap[tc.Name]
+	run.lastErr = nil
+	if !exists {
+		return fmt.Sprintf("Error: tool '%s' not found", tc.Name)
+	}
+
+	l.progress.doing("running " + tc.Name)
+	callStart := time.Now()
+	result, err := l.executor.Execute(tools.WithAgent(ctx, l.name), tool, tc.Arguments)
+	if l.onToolCall != nil {
+		l.onToolCall()
+	}
+	if n := l.usage.Record(tc.Name, tc.Arguments, result, err, time.Since(callStart)); n >= tools.RepeatWarnThreshold {
+		fmt.Fprintln(os.Stderr, tools.RepeatWarning(tc.Name, n))
+	}
+	run.toolCalls = append(run.toolCalls, model.ToolCall{
+		Name:       tc.Name,
+		InputSize:  len(tc.Arguments),
+		OutputSize: len(result.Output),
+		DurationMs: uint64(time.Since(callStart).Milliseconds()),
+		Success:    err == nil && result.Success(),
+	})
+	run.lastErr = err
+	if err == nil && !result.Success() {
+		run.lastErr = result.Error
+	}
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	output := l.observations.Apply(ctx, tc.Name, toolObservation(result))
+
+	if l.verbose {
+		displayOutput := text.Truncate(output, 200)
+		fmt.Println(colorize(styleDim, fmt.Sprintf("[%s:%d] Result: %s", l.name, iteration, displayOutput)))
+	}
+	return output
+}
+
+// toolObservation is what the model sees of a finished call: its output, or
+// why it failed or was denied, followed by any output it produced.
+func toolObservation(result tools.ToolResult) string {
+	if !result.Success() {
+		if result.Output != "" {
+			return fmt.Sprintf("Error: %v\n\n%s", result.Error, result.Output)
+		}
+		return fmt.Sprintf("Error: %v", result.Error)
+	}
+	if result.Output == "" {
+		return "(empty result)"
+	}
+	return result.Output
+}
+
+// reportLoopResponse prints a loop response and returns its error, if any.
+func reportLoopResponse(ctx context.Context, resp agent.Response, opts Options) error {
+	if opts.Verbose {
+		printAgentSteps(resp.Steps)
+	}
+	switch resp.Type {
+	case agent.ResponseSuccess:
+		fmt.Fprintf(answerOut, "%s\n", formatAnswer(resp.Result, opts))
+		return nil
+	case agent.ResponseTimeout:
+		if resp.PartialResult != "" {
+			fmt.Printf("Partial result:\n%s\n", resp.PartialResult)
+		}
+		return fmt.Errorf("%w without completing", resp.Err)
+	default:
+		if ctx.Err() != nil {
+			progress := fmt.Sprintf("Completed %d tool calls.", len(resp.Metadata.ToolCalls))
+			printInterrupted(ctx.Err(), opts, progress, resp.PartialResult)
+		}
+		return resp.AsError()
+	}
+}
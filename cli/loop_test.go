@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/tools"
+)
+
+// toolCallingProvider asks for one tool call, then answers, keeping the
+// conversation of the second request.
+type toolCallingProvider struct {
+	call llm.ToolCall
+	seen []llm.ChatMessage
+}
+
+func (p *toolCallingProvider) Name() string  { return "openai" }
+func (p *toolCallingProvider) Model() string { return "gpt-4o" }
+
+func (p *toolCallingProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	return p.ChatWithTools(ctx, messages, nil)
+}
+
+func (p *toolCallingProvider) ChatWithFormat(ctx context.Context, messages []llm.ChatMessage, format *llm.ResponseFormat) (llm.LLMResponse, error) {
+	return p.ChatWithTools(ctx, messages, nil)
+}
+
+func (p *toolCallingProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	if p.seen == nil {
+		p.seen = messages // First request: ask for the call
+		return llm.LLMResponse{ToolCalls: []llm.ToolCall{p.call}}, nil
+	}
+	p.seen = append([]llm.ChatMessage(nil), messages...)
+	return llm.LLMResponse{Content: "done"}, nil
+}
+
+func (p *toolCallingProvider) StreamChat(ctx context.Context, messages []llm.ChatMessage, chunks chan<- string) (*llm.TokenUsage, error) {
+	return nil, nil
+}
+
+func TestToolLoopShowsDeniedCalls(t *testing.T) {
+	provider := &toolCallingProvider{call: llm.ToolCall{ID: "1", Name: "stat_file", Arguments: json.RawMessage(`{"path": "go.mod"}`)}}
+	config := tools.ToolConfig{Filter: tools.NewToolFilter(nil, []string{"stat_file"})}
+	loop := &toolLoop{
+		name:         "test",
+		provider:     provider,
+		tools:        []tools.Tool{tools.NewStatFileTool()},
+		executor:     tools.NewExecutor(config),
+		usage:        tools.NewUsageTracker("test"),
+		observations: tools.NewObservationBudget(0),
+		maxIter:      3,
+	}
+
+	resp := loop.run(context.Background(), []llm.ChatMessage{{Role: "user", Content: "stat go.mod"}})
+	if resp.Result != "done" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	var observation string
+	for _, msg := range provider.seen {
+		if msg.Role == "tool" && msg.ToolCallID == "1" {
+			observation = msg.Content
+		}
+	}
+	if !strings.HasPrefix(observation, "Error: ") || !strings.Contains(observation, "stat_file is disabled for this run") {
+		t.Errorf("expected the denial in the observation, got %q", observation)
+	}
+	if len(resp.Metadata.ToolCalls) != 1 || resp.Metadata.ToolCalls[0].Success {
+		t.Errorf("expected one failed tool call recorded, got %+v", resp.Metadata.ToolCalls)
+	}
+}
+
+func TestToolObservation(t *testing.T) {
+	tests := []struct {
+		name   string
+		result tools.ToolResult
+		want   string
+	}{
+		{"output", tools.SuccessResult("a.go"), "a.go"},
+		{"empty", tools.SuccessResult(""), "(empty result)"},
+		{"denied", tools.DeniedResultf("write_file is disabled for this run"), "Error: write_file is disabled for this run"},
+		{"failed with output", tools.ToolResult{Output: "exit status 1", Error: context.DeadlineExceeded}, "Error: context deadline exceeded\n\nexit status 1"},
+	}
+	for _, tt := range tests {
+		if got := toolObservation(tt.result); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+// Editor integration for `ariadne lsp-bridge`.
+//
+// The bridge speaks JSON-RPC 2.0 on stdin/stdout with the framing of the
+// Language Server Protocol: every message is a Content-Length header, a
+// blank line and the JSON body. An editor plugin starts it as a child
+// process, usually through the editor's own LSP client, and sends
+// "ariadne/explain", "ariadne/refactor" or "ariadne/ask" requests whose
+// params are a BridgeArgs object. The LSP lifecycle messages (initialize,
+// shutdown, exit) and $/cancelRequest are understood; the bridge offers no
+// language features of its own.
+//
+// Each request stores the active file (the editor's buffer, saved or not)
+// in a fresh result store session and runs a bounded ReAct agent with
+// read-only tools on it. Requests run concurrently. Edits are never
+// written: a refactor returns the replacement text for the editor to apply.
+//
+// Information Hiding:
+// - Message framing, request dispatch and cancellation hidden
+// - Stdout isolation hidden
+// - Prompts per operation hidden
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/storage"
+	"github.com/richinex/ariadne/tools"
+)
+
+// Bridge limits.
+const (
+	// defaultBridgeTimeout bounds each call when --timeout is not set.
+	defaultBridgeTimeout = 120 * time.Second
+	// maxBridgeSelectionBytes caps the selection quoted in the prompt;
+	// the agent reads the rest of the file with get_lines.
+	maxBridgeSelectionBytes = 16 * 1024
+	// maxBridgeMessageBytes caps one incoming message body.
+	maxBridgeMessageBytes = 64 << 20
+)
+
+// JSON-RPC 2.0 and LSP error codes.
+const (
+	rpcParseError       = -32700
+	rpcInvalidRequest   = -32600
+	rpcMethodNotFound   = -32601
+	rpcInvalidParams    = -32602
+	rpcRequestCancelled = -32800
+	rpcRequestFailed    = -32803
+)
+
+// BridgeArgs are the parameters of every bridge method.
+type BridgeArgs struct {
+	Path    string `json:"path"`              // File open in the editor
+	Content string `json:"content,omitempty"` // Buffer text; empty reads Path
+	// StartLine and EndLine select lines (1-based, inclusive); zero means
+	// the whole file.
+	StartLine int `json:"start_line,omitempty"`
+	EndLine   int `json:"end_line,omitempty"`
+	// Question is required for ariadne/ask; for ariadne/refactor it says
+	// what to change (default: improve readability without changing
+	// behavior).
+	Question string `json:"question,omitempty"`
+}
+
+// BridgeReply is the result of every bridge method.
+type BridgeReply struct {
+	Answer      string `json:"answer"`
+	Replacement string `json:"replacement,omitempty"` // Refactor: new text for the selection
+	Steps       int    `json:"steps"`
+	Tokens      uint32 `json:"tokens"`
+}
+
+// LSPBridge serves the bridge on stdin/stdout until the editor sends exit,
+// stdin closes or the process is interrupted. Anything else the run prints
+// goes to stderr.
+func LSPBridge(ctx context.Context, opts Options) error {
+	provider, err := createProvider(opts.Provider, opts)
+	if err != nil {
+		return err
+	}
+	toolConfig, err := newToolConfig(opts, provider, "lsp-bridge")
+	if err != nil {
+		return err
+	}
+	defer toolConfig.Audit.Close()
+	if err := validateToolFilter(toolConfig.Filter, nil); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The protocol owns stdout
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	bridge := &bridgeService{provider: provider, toolConfig: toolConfig, opts: opts}
+	fmt.Fprintln(os.Stderr, "ariadne lsp-bridge ready: ariadne/explain, ariadne/refactor, ariadne/ask")
+	done := make(chan error, 1)
+	go func() { done <- serveBridge(ctx, os.Stdin, stdout, bridge) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// rpcRequest is a JSON-RPC 2.0 request, or a notification when ID is
+// absent.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response: Result on success, Error
+// otherwise.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// bridgeConn writes framed responses and tracks running requests so they
+// can be cancelled.
+type bridgeConn struct {
+	mu      sync.Mutex
+	out     io.Writer
+	pending map[string]context.CancelFunc // By request ID
+}
+
+// reply writes a response with result (marshalled) or err.
+func (c *bridgeConn) reply(id json.RawMessage, result any, err *rpcError) {
+	resp := rpcResponse{JSONRPC: "2.0", ID: id, Error: err}
+	if err == nil {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			resp.Error = &rpcError{Code: rpcRequestFailed, Message: marshalErr.Error()}
+		} else {
+			resp.Result = data
+		}
+	}
+	body, _ := json.Marshal(resp)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, werr := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n%s", len(body), body); werr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write reply: %v\n", werr)
+	}
+}
+
+// start registers a running request and returns its context.
+func (c *bridgeConn) start(ctx context.Context, id json.RawMessage) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.pending[string(id)] = cancel
+	c.mu.Unlock()
+	return ctx, func() {
+		c.mu.Lock()
+		delete(c.pending, string(id))
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancel stops the request with id, if it is still running.
+func (c *bridgeConn) cancel(id json.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cancel, ok := c.pending[string(id)]; ok {
+		cancel()
+	}
+}
+
+// readBridgeMessage reads one Content-Length framed message body. It
+// returns io.EOF when in ends between messages.
+func readBridgeMessage(in *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read message header: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed message header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 || n > maxBridgeMessageBytes {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message has no Content-Length header")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(in, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+// serveBridge answers requests read from in until exit or the end of in,
+// then waits for running requests to finish. Malformed framing ends the
+// session, since the stream can't be resynchronized.
+func serveBridge(ctx context.Context, in io.Reader, out io.Writer, bridge *bridgeService) error {
+	conn := &bridgeConn{out: out, pending: make(map[string]context.CancelFunc)}
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	reader := bufio.NewReader(in)
+	shutdown := false
+	for {
+		body, err := readBridgeMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			conn.reply(json.RawMessage("null"), nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			if req.ID != nil {
+				conn.reply(req.ID, nil, &rpcError{Code: rpcInvalidRequest, Message: `expected a "2.0" request with a method`})
+			}
+			continue
+		}
+
+		switch req.Method {
+		case "exit":
+			return nil
+		case "$/cancelRequest":
+			var params struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if json.Unmarshal(req.Params, &params) == nil {
+				conn.cancel(params.ID)
+			}
+			continue
+		}
+		if req.ID == nil {
+			continue // Other notifications (initialized, didOpen, ...) need no answer
+		}
+
+		switch {
+		case shutdown:
+			conn.reply(req.ID, nil, &rpcError{Code: rpcInvalidRequest, Message: "server is shutting down"})
+		case req.Method == "initialize":
+			conn.reply(req.ID, map[string]any{
+				"capabilities": map[string]any{},
+				"serverInfo":   map[string]string{"name": "ariadne"},
+			}, nil)
+		case req.Method == "shutdown":
+			shutdown = true
+			conn.reply(req.ID, nil, nil)
+		default:
+			method, ok := bridge.method(req.Method)
+			if !ok {
+				conn.reply(req.ID, nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)})
+				continue
+			}
+			var args BridgeArgs
+			if err := json.Unmarshal(req.Params, &args); err != nil {
+				conn.reply(req.ID, nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()})
+				continue
+			}
+			callCtx, done := conn.start(ctx, req.ID)
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				defer done()
+				reply, err := method(callCtx, args)
+				switch {
+				case err == nil:
+					conn.reply(req.ID, reply, nil)
+				case callCtx.Err() != nil && ctx.Err() == nil:
+					conn.reply(req.ID, nil, &rpcError{Code: rpcRequestCancelled, Message: "request cancelled"})
+				default:
+					conn.reply(req.ID, nil, &rpcError{Code: rpcRequestFailed, Message: err.Error()})
+				}
+			}()
+		}
+	}
+}
+
+// bridgeService runs the bridge methods.
+type bridgeService struct {
+	provider   llm.Provider
+	toolConfig tools.ToolConfig
+	opts       Options
+}
+
+// method returns the bridge method called name.
+func (b *bridgeService) method(name string) (func(context.Context, BridgeArgs) (BridgeReply, error), bool) {
+	switch name {
+	case "ariadne/explain":
+		return b.explain, true
+	case "ariadne/refactor":
+		return b.refactor, true
+	case "ariadne/ask":
+		return b.ask, true
+	}
+	return nil, false
+}
+
+func (b *bridgeService) explain(ctx context.Context, args BridgeArgs) (BridgeReply, error) {
+	return b.run(ctx, args, func(where, selection string) string {
+		return fmt.Sprintf("Explain what %s does: its purpose, how it works, how it fits into the rest of the file, and anything surprising or risky. Be concise.%s", where, selection)
+	})
+}
+
+func (b *bridgeService) refactor(ctx context.Context, args BridgeArgs) (BridgeReply, error) {
+	instruction := args.Question
+	if instruction == "" {
+		instruction = "Improve readability without changing behavior."
+	}
+	reply, err := b.run(ctx, args, func(where, selection string) string {
+		return fmt.Sprintf("Refactor %s. %s\n\nKeep the surrounding code's style and indentation. Reply with the complete replacement for the selected lines in one fenced code block, then at most three sentences on what changed. Do not write any files.%s", where, instruction, selection)
+	})
+	if err != nil {
+		return reply, err
+	}
+	replacement, ok := firstCodeBlock(reply.Answer)
+	if !ok {
+		return reply, errors.New("refactor: the answer has no code block")
+	}
+	reply.Replacement = replacement
+	return reply, nil
+}
+
+func (b *bridgeService) ask(ctx context.Context, args BridgeArgs) (BridgeReply, error) {
+	if strings.TrimSpace(args.Question) == "" {
+		return BridgeReply{}, errors.New("question is required")
+	}
+	return b.run(ctx, args, func(where, selection string) string {
+		return fmt.Sprintf("Answer this question about %s:\n\n%s\n\nCite line numbers.%s", where, args.Question, selection)
+	})
+}
+
+// run stores the active file, builds the prompt from where the selection
+// is and its quoted text, and runs a read-only agent on it.
+func (b *bridgeService) run(ctx context.Context, args BridgeArgs, prompt func(where, selection string) string) (BridgeReply, error) {
+	var reply BridgeReply
+	if args.Path == "" {
+		return reply, errors.New("path is required")
+	}
+	content := args.Content
+	if content == "" {
+		data, err := os.ReadFile(args.Path)
+		if err != nil {
+			return reply, err
+		}
+		content = string(data)
+	}
+	lines := strings.Split(content, "\n")
+	if args.StartLine < 0 || args.EndLine < args.StartLine || args.EndLine > len(lines) || (args.StartLine == 0) != (args.EndLine == 0) {
+		return reply, fmt.Errorf("invalid selection %d-%d (file has %d lines)", args.StartLine, args.EndLine, len(lines))
+	}
+
+	timeout := defaultBridgeTimeout
+	if b.opts.Timeout > 0 {
+		timeout = time.Duration(b.opts.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	callOpts := b.opts
+	callOpts.StoreSession = ""
+	resultStore, sessionID, cleanup, err := createResultStore(ctx, callOpts)
+	if err != nil {
+		return reply, err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	fileContext := tools.NewStoredFileContext()
+	where := "the file " + args.Path
+	if args.StartLine > 0 {
+		where = fmt.Sprintf("lines %d-%d of %s", args.StartLine, args.EndLine, args.Path)
+	}
+	var selection string
+	if resultStore != nil {
+		if _, err := resultStore.SessionContent(sessionID).StoreContent(ctx, model.FileKey(args.Path), content); err != nil {
+			return reply, fmt.Errorf("failed to store %s: %w", args.Path, err)
+		}
+		fileContext.Add(args.Path)
+		selection = fmt.Sprintf("\n\n[%s is stored with %d lines - use get_lines with key %q to read it]", args.Path, len(lines), args.Path)
+	}
+	switch {
+	case args.StartLine > 0:
+		quoted := strings.Join(lines[args.StartLine-1:args.EndLine], "\n")
+		selection += "\n\nSelected lines:\n" + fence(text.Truncate(quoted, maxBridgeSelectionBytes))
+	case resultStore == nil:
+		selection += "\n\nFile:\n" + fence(text.Truncate(content, maxBridgeSelectionBytes))
+	}
+
+	a := agent.New(bridgeAgent(resultStore, sessionID, fileContext), b.provider).WithToolConfig(b.toolConfig)
+	if err := attachExamples([]*agent.Agent{a}, b.opts); err != nil {
+		return reply, err
+	}
+	response := a.Execute(ctx, prompt(where, selection), b.opts.MaxIter)
+	if response.Type != agent.ResponseSuccess {
+		return reply, response.AsError()
+	}
+	reply.Answer = response.Result
+	reply.Steps = len(response.Steps)
+	if usage := response.Metadata.TokenUsage; usage != nil {
+		reply.Tokens = usage.TotalTokens
+	}
+	return reply, nil
+}
+
+// bridgeAgent is a file agent without tools that write or run commands.
+func bridgeAgent(resultStore *storage.ResultStore, sessionID string, fileContext *tools.StoredFileContext) agent.Config {
+	readTool := tools.NewReadFileTool(defaultMaxFileSize)
+	grepTool := tools.NewGrepTool(defaultMaxFileSize)
+	if resultStore != nil {
+		readTool = readTool.WithContentStore(resultStore.SessionContent(sessionID)).WithFileContext(fileContext)
+		grepTool = grepTool.WithContentStore(resultStore.SessionContent(sessionID)).WithFileContext(fileContext)
+	}
+	builder := agent.NewBuilder("editor").
+		Description("Read-only code assistant for editor requests").
+		SystemPrompt(`You are a code assistant answering requests from a developer's editor.
+The active file is stored: use get_lines to read it and search_stored to find things in it. Read other files only when the answer depends on them.
+Answer from code you retrieved, never from guesses. Do not modify files.`).
+		Tool(readTool).
+		Tool(tools.NewStatFileTool()).
+		Tool(grepTool).
+		Tool(tools.NewRipgrepTool(defaultTimeout))
+	if resultStore != nil {
+		builder = builder.
+			Tool(tools.NewSearchStoredTool(resultStore, sessionID, fileContext)).
+			Tool(tools.NewGetLinesTool(resultStore, sessionID, fileContext)).
+			Tool(tools.NewListStoredTool(resultStore, sessionID, fileContext))
+	}
+	return builder.Build()
+}
+
+// firstCodeBlock returns the body of the first fenced code block in s.
+func firstCodeBlock(s string) (string, bool) {
+	start := strings.Index(s, "```")
+	if start < 0 {
+		return "", false
+	}
+	fenceEnd := start
+	for fenceEnd < len(s) && s[fenceEnd] == '`' {
+		fenceEnd++
+	}
+	ticks := s[start:fenceEnd]
+	newline := strings.IndexByte(s[fenceEnd:], '\n')
+	if newline < 0 {
+		return "", false
+	}
+	body := s[fenceEnd+newline+1:]
+	end := strings.Index(body, "\n"+ticks)
+	if end < 0 {
+		return "", false
+	}
+	return body[:end], true
+}
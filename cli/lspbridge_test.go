@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+)
+
+// answerProvider answers every request with a final decision whose answer
+// comes from reply, called with the last user message.
+type answerProvider struct {
+	reply func(ctx context.Context, prompt string) (string, error)
+}
+
+func (p *answerProvider) Name() string  { return "answer" }
+func (p *answerProvider) Model() string { return "answer" }
+
+func (p *answerProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	var prompt string
+	for _, m := range messages {
+		if m.Role == "user" {
+			prompt = m.Content
+		}
+	}
+	answer, err := p.reply(ctx, prompt)
+	if err != nil {
+		return llm.LLMResponse{}, err
+	}
+	decision, _ := json.Marshal(map[string]any{"thought": "done", "is_final": true, "final_answer": answer})
+	return llm.LLMResponse{Content: string(decision)}, nil
+}
+
+func (p *answerProvider) ChatWithFormat(ctx context.Context, messages []llm.ChatMessage, format *llm.ResponseFormat) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *answerProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *answerProvider) StreamChat(ctx context.Context, messages []llm.ChatMessage, chunks chan<- string) (*llm.TokenUsage, error) {
+	resp, err := p.Chat(ctx, messages)
+	if err == nil {
+		chunks <- resp.Content
+	}
+	return resp.Usage, err
+}
+
+// bridgeClient drives serveBridge over pipes.
+type bridgeClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	served chan error
+}
+
+func newBridgeClient(t *testing.T, provider llm.Provider) *bridgeClient {
+	t.Chdir(t.TempDir()) // The result store lives under .ariadne
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &bridgeClient{t: t, in: inW, out: bufio.NewReader(outR), served: make(chan error, 1)}
+	bridge := &bridgeService{provider: provider, opts: Options{MaxIter: 3}}
+	go func() {
+		c.served <- serveBridge(context.Background(), inR, outW, bridge)
+		outW.Close()
+	}()
+	t.Cleanup(func() { inW.Close() })
+	return c
+}
+
+// send writes one framed message.
+func (c *bridgeClient) send(body string) {
+	c.t.Helper()
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatalf("failed to send: %v", err)
+	}
+}
+
+// receive reads one framed response.
+func (c *bridgeClient) receive() rpcResponse {
+	c.t.Helper()
+	body, err := readBridgeMessage(c.out)
+	if err != nil {
+		c.t.Fatalf("failed to read response: %v", err)
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		c.t.Fatalf("invalid response %s: %v", body, err)
+	}
+	if resp.JSONRPC != "2.0" {
+		c.t.Errorf("expected jsonrpc 2.0, got %q", resp.JSONRPC)
+	}
+	return resp
+}
+
+// call sends a request and returns its response.
+func (c *bridgeClient) call(id int, method string, params any) rpcResponse {
+	c.t.Helper()
+	data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	c.send(string(data))
+	resp := c.receive()
+	if string(resp.ID) != fmt.Sprint(id) {
+		c.t.Errorf("expected id %d, got %s", id, resp.ID)
+	}
+	return resp
+}
+
+func TestLSPBridgeMethods(t *testing.T) {
+	c := newBridgeClient(t, &answerProvider{reply: func(ctx context.Context, prompt string) (string, error) {
+		switch {
+		case strings.Contains(prompt, "Refactor lines"):
+			return "```go\nreturn a + b\n```\nInlined the sum.", nil
+		case strings.Contains(prompt, "Answer this question"):
+			return "It adds. (line 2)", nil
+		}
+		return "Adds two numbers.", nil
+	}})
+	file := map[string]any{"path": "sum.go", "content": "func sum(a, b int) int {\n\ts := a + b\n\treturn s\n}", "start_line": 2, "end_line": 3}
+
+	resp := c.call(1, "initialize", map[string]any{"capabilities": map[string]any{}})
+	if resp.Error != nil || !strings.Contains(string(resp.Result), `"capabilities"`) {
+		t.Fatalf("unexpected initialize response: %+v %s", resp.Error, resp.Result)
+	}
+	c.send(`{"jsonrpc": "2.0", "method": "initialized", "params": {}}`) // Notifications get no reply
+
+	tests := []struct {
+		method string
+		params map[string]any
+		want   BridgeReply
+	}{
+		{"ariadne/explain", file, BridgeReply{Answer: "Adds two numbers.", Steps: 1}},
+		{"ariadne/refactor", file, BridgeReply{Answer: "```go\nreturn a + b\n```\nInlined the sum.", Replacement: "return a + b", Steps: 1}},
+		{"ariadne/ask", map[string]any{"path": "sum.go", "content": "x", "question": "what does it do?"}, BridgeReply{Answer: "It adds. (line 2)", Steps: 1}},
+	}
+	for i, tt := range tests {
+		resp := c.call(i+2, tt.method, tt.params)
+		if resp.Error != nil {
+			t.Fatalf("%s: unexpected error: %+v", tt.method, resp.Error)
+		}
+		var got BridgeReply
+		if err := json.Unmarshal(resp.Result, &got); err != nil {
+			t.Fatalf("%s: invalid result %s: %v", tt.method, resp.Result, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.method, tt.want, got)
+		}
+	}
+
+	if resp := c.call(10, "shutdown", nil); resp.Error != nil || string(resp.Result) != "null" {
+		t.Errorf("expected null shutdown result, got %+v %s", resp.Error, resp.Result)
+	}
+	if resp := c.call(11, "ariadne/explain", file); resp.Error == nil || resp.Error.Code != rpcInvalidRequest {
+		t.Errorf("expected requests after shutdown to fail, got %+v", resp.Error)
+	}
+	c.send(`{"jsonrpc": "2.0", "method": "exit"}`)
+	if err := <-c.served; err != nil {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+}
+
+func TestLSPBridgeErrors(t *testing.T) {
+	c := newBridgeClient(t, &answerProvider{reply: func(ctx context.Context, prompt string) (string, error) {
+		return "no code here", nil
+	}})
+
+	tests := []struct {
+		name   string
+		method string
+		params any
+		code   int
+	}{
+		{"unknown method", "Ariadne.Explain", map[string]any{"path": "a.go"}, rpcMethodNotFound},
+		{"params not an object", "ariadne/explain", []any{map[string]any{"path": "a.go"}}, rpcInvalidParams},
+		{"missing path", "ariadne/explain", map[string]any{"content": "x"}, rpcRequestFailed},
+		{"missing question", "ariadne/ask", map[string]any{"path": "a.go", "content": "x"}, rpcRequestFailed},
+		{"bad selection", "ariadne/explain", map[string]any{"path": "a.go", "content": "x", "start_line": 2, "end_line": 1}, rpcRequestFailed},
+		{"refactor without code block", "ariadne/refactor", map[string]any{"path": "a.go", "content": "x"}, rpcRequestFailed},
+	}
+	for i, tt := range tests {
+		resp := c.call(i+1, tt.method, tt.params)
+		if resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s: expected error code %d, got %+v", tt.name, tt.code, resp.Error)
+		}
+	}
+
+	c.send(`{"jsonrpc": "2.0", "id": 99, "method": `)
+	if resp := c.receive(); resp.Error == nil || resp.Error.Code != rpcParseError || string(resp.ID) != "null" {
+		t.Errorf("expected parse error with null id, got %+v (id %s)", resp.Error, resp.ID)
+	}
+
+	// Bad framing ends the session
+	fmt.Fprint(c.in, "Content-Type: application/json\r\n\r\n")
+	if err := <-c.served; err == nil || !strings.Contains(err.Error(), "Content-Length") {
+		t.Errorf("expected a framing error, got %v", err)
+	}
+}
+
+func TestLSPBridgeCancelRequest(t *testing.T) {
+	started := make(chan struct{})
+	c := newBridgeClient(t, &answerProvider{reply: func(ctx context.Context, prompt string) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}})
+
+	c.send(`{"jsonrpc": "2.0", "id": "slow", "method": "ariadne/explain", "params": {"path": "a.go", "content": "x"}}`)
+	<-started
+	c.send(`{"jsonrpc": "2.0", "method": "$/cancelRequest", "params": {"id": "slow"}}`)
+	resp := c.receive()
+	if string(resp.ID) != `"slow"` || resp.Error == nil || resp.Error.Code != rpcRequestCancelled {
+		t.Errorf("expected cancelled error for \"slow\", got %+v (id %s)", resp.Error, resp.ID)
+	}
+}
+
+func TestFirstCodeBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		ok   bool
+	}{
+		{"language tag", "Here:\n```go\nx := 1\n```\nDone.", "x := 1", true},
+		{"no tag", "```\na\nb\n```", "a\nb", true},
+		{"first of two", "```\none\n```\n```\ntwo\n```", "one", true},
+		{"longer fence keeps inner ticks", "````md\n```go\nx\n```\n````", "```go\nx\n```", true},
+		{"empty block", "```\n\n```", "", true},
+		{"no block", "just text", "", false},
+		{"unterminated", "```go\nx := 1\n", "", false},
+		{"fence without newline", "```go", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := firstCodeBlock(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: expected (%q, %v), got (%q, %v)", tt.name, tt.want, tt.ok, got, ok)
+		}
+	}
+}
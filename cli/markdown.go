@@ -0,0 +1,538 @@
+// Markdown rendering of final answers.
+//
+// With --render, final answers are rendered for the terminal instead of
+// printed as raw markdown: styled headings, emphasis, inline code and
+// links; lists and quotes wrapped to the terminal width; tables with
+// aligned columns; and fenced code blocks with syntax highlighting. Only
+// the markdown models commonly write is recognized. Anything else is
+// printed as a wrapped paragraph.
+//
+// Information Hiding:
+// - Block and inline parsing
+// - Word wrapping by visible width, never inside an inline span
+// - Keyword lists for code highlighting
+
+package cli
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/richinex/ariadne/internal/text"
+)
+
+// minRenderWidth keeps wrapping usable on very narrow terminals.
+const minRenderWidth = 20
+
+var (
+	mdFence       = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+#.-]*)")
+	mdHeading     = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdRule        = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdTableSep    = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdQuote       = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	mdListItem    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdInline      = regexp.MustCompile("`([^`]+)`|\\*\\*([^*]+)\\*\\*|__([^_]+)__|\\*([^*\\s][^*]*)\\*|\\b_([^_\\s][^_]*)_\\b|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)")
+	headingStyles = []string{styleBold + styleUnderline + styleMagenta, styleBold + styleCyan, styleBold}
+)
+
+// formatAnswer returns a final answer to print, rendered if opts.Render
+// is set.
+func formatAnswer(answer string, opts Options) string {
+	if !opts.Render {
+		return answer
+	}
+	return renderMarkdown(answer, terminalWidth(answerOut))
+}
+
+// renderMarkdown renders md for a terminal width columns wide.
+func renderMarkdown(md string, width int) string {
+	r := &mdRenderer{width: max(width, minRenderWidth)}
+	lines := strings.Split(strings.ReplaceAll(text.NormalizeNewlines(md), "\t", "    "), "\n")
+
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			r.wrap(strings.Join(paragraph, " "), "", "")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]); i++ {
+				code = append(code, lines[i])
+			}
+			r.codeBlock(m[2], code)
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			r.blank()
+			continue
+		}
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			flush()
+			style := headingStyles[min(len(m[1]), len(headingStyles))-1]
+			r.wrapStyled(m[2], style)
+			continue
+		}
+		if mdRule.MatchString(line) {
+			flush()
+			r.line(paint(styleDim, strings.Repeat("─", r.width)))
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "|") && i+1 < len(lines) && mdTableSep.MatchString(lines[i+1]) {
+			flush()
+			rows := [][]string{splitTableRow(line)}
+			align := tableAlignment(lines[i+1])
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, splitTableRow(lines[i]))
+			}
+			i--
+			r.table(rows, align)
+			continue
+		}
+		if m := mdQuote.FindStringSubmatch(line); m != nil {
+			flush()
+			quote := []string{m[1]}
+			for i+1 < len(lines) {
+				next := mdQuote.FindStringSubmatch(lines[i+1])
+				if next == nil {
+					break
+				}
+				quote = append(quote, next[1])
+				i++
+			}
+			bar := paint(styleDim, "│ ")
+			r.wrap(strings.Join(quote, " "), bar, bar)
+			continue
+		}
+		if m := mdListItem.FindStringSubmatch(line); m != nil {
+			flush()
+			item := []string{m[3]}
+			// Indented lines that don't start another block continue the item
+			for i+1 < len(lines) {
+				next := lines[i+1]
+				if strings.TrimSpace(next) == "" || !strings.HasPrefix(next, " ") ||
+					mdListItem.MatchString(next) || mdFence.MatchString(next) {
+					break
+				}
+				item = append(item, strings.TrimSpace(next))
+				i++
+			}
+			indent := strings.Repeat("  ", len(m[1])/2+1)
+			marker := m[2]
+			if strings.ContainsAny(marker, "-*+") {
+				marker = "•"
+			}
+			r.wrap(strings.Join(item, " "), indent+paint(styleCyan, marker)+" ",
+				indent+strings.Repeat(" ", utf8.RuneCountInString(marker)+1))
+			continue
+		}
+		paragraph = append(paragraph, strings.TrimSpace(line))
+	}
+	flush()
+	return strings.TrimRight(r.out.String(), "\n")
+}
+
+// mdRenderer accumulates rendered lines.
+type mdRenderer struct {
+	width     int
+	out       strings.Builder
+	lastBlank bool
+}
+
+func (r *mdRenderer) line(s string) {
+	r.out.WriteString(s)
+	r.out.WriteByte('\n')
+	r.lastBlank = false
+}
+
+// blank writes an empty line, collapsing runs of them.
+func (r *mdRenderer) blank() {
+	if !r.lastBlank && r.out.Len() > 0 {
+		r.out.WriteByte('\n')
+		r.lastBlank = true
+	}
+}
+
+// wrap renders s's inline markup and wraps it, starting the first line
+// with first and the others with rest.
+func (r *mdRenderer) wrap(s, first, rest string) {
+	r.wrapWords(inlineWords(s, ""), first, rest)
+}
+
+// wrapStyled is wrap for s shown entirely in style, such as a heading.
+func (r *mdRenderer) wrapStyled(s, style string) {
+	r.wrapWords(inlineWords(s, style), "", "")
+}
+
+func (r *mdRenderer) wrapWords(words []mdWord, first, rest string) {
+	line, lineWidth, empty := first, visibleWidth(first), true
+	for _, w := range words {
+		if !empty && lineWidth+1+w.width > r.width {
+			r.line(line)
+			line, lineWidth, empty = rest, visibleWidth(rest), true
+		}
+		if !empty {
+			line += " "
+			lineWidth++
+		}
+		line += w.text
+		lineWidth += w.width
+		empty = false
+	}
+	r.line(line)
+}
+
+// codeBlock renders a fenced code block, indented and highlighted. Code is
+// not wrapped.
+func (r *mdRenderer) codeBlock(lang string, code []string) {
+	if lang != "" {
+		r.line(paint(styleDim, "  "+lang))
+	}
+	syntax := lookupSyntax(lang)
+	for _, line := range code {
+		r.line("  " + highlightCode(syntax, line))
+	}
+}
+
+// table renders rows (the first is the header) with aligned columns,
+// clipping cells if the table is wider than the terminal.
+func (r *mdRenderer) table(rows [][]string, align []byte) {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	cells := make([][]mdWord, len(rows))
+	widths := make([]int, columns)
+	for i, row := range rows {
+		cells[i] = make([]mdWord, columns)
+		for j := range columns {
+			var cell mdWord
+			if j < len(row) {
+				style := ""
+				if i == 0 {
+					style = styleBold
+				}
+				cell = joinWords(inlineWords(row[j], style))
+			}
+			cells[i][j] = cell
+			widths[j] = max(widths[j], cell.width)
+		}
+	}
+
+	// Narrow the widest columns until the table fits: 3 columns of border
+	// and padding per cell, plus the closing border
+	const minColumn = 5
+	for total(widths)+3*columns+1 > r.width {
+		widest := 0
+		for j := range widths {
+			if widths[j] > widths[widest] {
+				widest = j
+			}
+		}
+		if widths[widest] <= minColumn {
+			break
+		}
+		widths[widest]--
+	}
+
+	border := func(left, mid, right string) string {
+		parts := make([]string, columns)
+		for j, w := range widths {
+			parts[j] = strings.Repeat("─", w+2)
+		}
+		return paint(styleDim, left+strings.Join(parts, mid)+right)
+	}
+	bar := paint(styleDim, "│")
+	r.line(border("┌", "┬", "┐"))
+	for i, row := range cells {
+		line := bar
+		for j, cell := range row {
+			if cell.width > widths[j] {
+				cell = clipWord(cell, widths[j])
+			}
+			var a byte
+			if j < len(align) {
+				a = align[j]
+			}
+			line += " " + pad(cell, widths[j], a) + " " + bar
+		}
+		r.line(line)
+		if i == 0 {
+			r.line(border("├", "┼", "┤"))
+		}
+	}
+	r.line(border("└", "┴", "┘"))
+}
+
+func total(widths []int) int {
+	sum := 0
+	for _, w := range widths {
+		sum += w
+	}
+	return sum
+}
+
+// pad aligns w in a cell width columns wide: 'l', 'r' or 'c'.
+func pad(w mdWord, width int, align byte) string {
+	space := width - w.width
+	switch align {
+	case 'r':
+		return strings.Repeat(" ", space) + w.text
+	case 'c':
+		return strings.Repeat(" ", space/2) + w.text + strings.Repeat(" ", space-space/2)
+	default:
+		return w.text + strings.Repeat(" ", space)
+	}
+}
+
+// clipWord shortens w to width columns. Styling is dropped, since a cut
+// could land inside it.
+func clipWord(w mdWord, width int) mdWord {
+	clipped := text.Clip(ansiSequence.ReplaceAllString(w.text, ""), width)
+	return mdWord{text: clipped, width: text.Width(clipped)}
+}
+
+// splitTableRow splits "| a | b |" into its trimmed cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// tableAlignment reads each column's alignment ('l', 'r' or 'c') from a
+// separator row such as "|:---|---:|".
+func tableAlignment(separator string) []byte {
+	cells := splitTableRow(separator)
+	align := make([]byte, len(cells))
+	for i, cell := range cells {
+		switch left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":"); {
+		case left && right:
+			align[i] = 'c'
+		case right:
+			align[i] = 'r'
+		default:
+			align[i] = 'l'
+		}
+	}
+	return align
+}
+
+// mdWord is a unit of wrapping: a word with any markup attached to it
+// rendered, and its visible width.
+type mdWord struct {
+	text  string
+	width int
+}
+
+// inlineWords renders md's inline markup and splits it into words.
+// Spans stay whole, and punctuation stays with the word or span it
+// touches. All of it is shown in base style, if set.
+func inlineWords(md, base string) []mdWord {
+	var words []mdWord
+	glue := false // Next piece joins the previous word
+	add := func(s string, width int) {
+		if glue && len(words) > 0 {
+			words[len(words)-1].text += s
+			words[len(words)-1].width += width
+		} else {
+			words = append(words, mdWord{text: s, width: width})
+		}
+		glue = true
+	}
+	plain := func(s string) {
+		for s != "" {
+			trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+			if len(trimmed) < len(s) {
+				glue = false
+			}
+			if trimmed == "" {
+				return
+			}
+			end := strings.IndexFunc(trimmed, unicode.IsSpace)
+			if end < 0 {
+				end = len(trimmed)
+			}
+			add(paint(base, trimmed[:end]), text.Width(trimmed[:end]))
+			s = trimmed[end:]
+		}
+	}
+
+	last := 0
+	for _, m := range mdInline.FindAllStringSubmatchIndex(md, -1) {
+		plain(md[last:m[0]])
+		span := renderSpan(md, m, base)
+		add(span, visibleWidth(span))
+		last = m[1]
+	}
+	plain(md[last:])
+	return words
+}
+
+// joinWords joins words with single spaces into one.
+func joinWords(words []mdWord) mdWord {
+	var joined mdWord
+	for i, w := range words {
+		if i > 0 {
+			joined.text += " "
+			joined.width++
+		}
+		joined.text += w.text
+		joined.width += w.width
+	}
+	return joined
+}
+
+// renderSpan renders the inline span m of mdInline in md.
+func renderSpan(md string, m []int, base string) string {
+	group := func(n int) string {
+		if m[2*n] < 0 {
+			return ""
+		}
+		return md[m[2*n]:m[2*n+1]]
+	}
+	switch {
+	case group(1) != "":
+		return paint(base+styleYellow, group(1))
+	case group(2) != "":
+		return paint(base+styleBold, group(2))
+	case group(3) != "":
+		return paint(base+styleBold, group(3))
+	case group(4) != "":
+		return paint(base+styleItalic, group(4))
+	case group(5) != "":
+		return paint(base+styleItalic, group(5))
+	default:
+		label, url := group(6), group(7)
+		if label == url {
+			return paint(base+styleUnderline+styleBlue, url)
+		}
+		return paint(base+styleUnderline+styleBlue, label) + paint(styleDim, " ("+url+")")
+	}
+}
+
+// codeSyntax is what highlightCode needs to know about a language.
+type codeSyntax struct {
+	comment  string // Line comment marker
+	quotes   string // String delimiters
+	keywords map[string]bool
+	upper    bool // Keywords match case-insensitively, written upper case
+}
+
+func keywords(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var codeSyntaxes = map[string]codeSyntax{
+	"go": {comment: "//", quotes: "\"'`", keywords: keywords(`break case chan const continue default defer else
+		fallthrough for func go goto if import interface map package range return select struct switch type var
+		nil true false`)},
+	"python": {comment: "#", quotes: `"'`, keywords: keywords(`and as assert async await break class continue def
+		del elif else except finally for from global if import in is lambda None nonlocal not or pass raise
+		return True False try while with yield`)},
+	"javascript": {comment: "//", quotes: "\"'`", keywords: keywords(`async await break case catch class const
+		continue default delete do else export extends false finally for function if import in instanceof
+		interface let new null return super switch this throw true try type typeof undefined var void while yield`)},
+	"rust": {comment: "//", quotes: `"`, keywords: keywords(`as async await break const continue crate else enum
+		extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super
+		trait true type unsafe use where while`)},
+	"sh": {comment: "#", quotes: `"'`, keywords: keywords(`if then else elif fi for while until do done case esac
+		in function return export local`)},
+	"sql": {comment: "--", quotes: `'"`, upper: true, keywords: keywords(`SELECT FROM WHERE AND OR NOT INSERT
+		INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER INDEX JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER
+		LIMIT AS NULL IS IN HAVING DISTINCT UNION`)},
+	"json": {quotes: `"`, keywords: keywords(`true false null`)},
+	"yaml": {comment: "#", quotes: `"'`, keywords: keywords(`true false null`)},
+}
+
+var syntaxAliases = map[string]string{
+	"golang": "go", "py": "python", "js": "javascript", "jsx": "javascript", "ts": "javascript",
+	"tsx": "javascript", "typescript": "javascript", "rs": "rust", "bash": "sh", "shell": "sh",
+	"zsh": "sh", "console": "sh", "yml": "yaml",
+}
+
+// lookupSyntax returns the syntax for a fence's language, or nil if it
+// isn't known; unknown code is shown unhighlighted.
+func lookupSyntax(lang string) *codeSyntax {
+	lang = strings.ToLower(lang)
+	if alias, ok := syntaxAliases[lang]; ok {
+		lang = alias
+	}
+	if syntax, ok := codeSyntaxes[lang]; ok {
+		return &syntax
+	}
+	return nil
+}
+
+// highlightCode colors one line of code: keywords, strings, numbers and
+// line comments. Multi-line strings and block comments aren't tracked.
+func highlightCode(syntax *codeSyntax, line string) string {
+	if syntax == nil {
+		return line
+	}
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		rest := line[i:]
+		c := rest[0]
+		switch {
+		case syntax.comment != "" && strings.HasPrefix(rest, syntax.comment):
+			b.WriteString(paint(styleDim, rest))
+			return b.String()
+		case strings.IndexByte(syntax.quotes, c) >= 0:
+			end := 1
+			for end < len(rest) && rest[end] != c {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(rest))
+			b.WriteString(paint(styleGreen, rest[:end]))
+			i += end
+		case isIdentByte(c) && !isDigit(c):
+			end := 1
+			for end < len(rest) && isIdentByte(rest[end]) {
+				end++
+			}
+			word := rest[:end]
+			if syntax.keywords[word] || syntax.upper && syntax.keywords[strings.ToUpper(word)] {
+				word = paint(styleMagenta, word)
+			}
+			b.WriteString(word)
+			i += end
+		case isDigit(c):
+			end := 1
+			for end < len(rest) && (isIdentByte(rest[end]) || rest[end] == '.') {
+				end++
+			}
+			b.WriteString(paint(styleCyan, rest[:end]))
+			i += end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentByte(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,44 @@
+// Memory maintenance for `ariadne memory`.
+//
+// Information Hiding:
+// - Database opening and report formatting hidden
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// PruneMemories removes low-scoring memories from the database at dbPath.
+// See storage.MemoryPruneOptions for how memories are selected.
+func PruneMemories(ctx context.Context, dbPath string, opts storage.MemoryPruneOptions) error {
+	if dbPath == "" {
+		dbPath = defaultDBPath
+	}
+	if opts.Keep == 0 && opts.MinScore <= 0 {
+		return fmt.Errorf("nothing to prune: set --keep or --min-score")
+	}
+	db, err := storage.OpenSqlite(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	n, err := db.PruneMemories(ctx, opts)
+	if err != nil {
+		return err
+	}
+	scope := "all sessions"
+	if opts.SessionID != "" {
+		scope = "session " + opts.SessionID
+	}
+	if opts.DryRun {
+		fmt.Printf("Would prune %d memories from %s\n", n, scope)
+	} else {
+		fmt.Printf("Pruned %d memories from %s\n", n, scope)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Database upgrades for `ariadne migrate`.
+//
+// Information Hiding:
+// - Report formatting hidden
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// Migrate upgrades the database at dbPath to the current schema version,
+// backing it up first. With dryRun it only lists the pending migrations.
+func Migrate(ctx context.Context, dbPath string, dryRun bool) error {
+	if dbPath == "" {
+		dbPath = defaultDBPath
+	}
+	report, err := storage.MigrateSqlite(ctx, dbPath, dryRun)
+	if err != nil {
+		return err
+	}
+	if len(report.Applied) == 0 {
+		fmt.Printf("%s is up to date (schema v%d)\n", dbPath, report.From)
+		return nil
+	}
+
+	verb := "Applied"
+	if dryRun {
+		verb = "Pending"
+	}
+	fmt.Printf("%s: schema v%d -> v%d\n", dbPath, report.From, report.To)
+	fmt.Printf("%s migrations:\n", verb)
+	for _, m := range report.Applied {
+		fmt.Printf("  %s\n", m)
+	}
+	if report.Backup != "" {
+		fmt.Printf("Backup: %s\n", report.Backup)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Live progress line for non-verbose runs.
+//
+// Without --verbose, react-run, rlm and react-orchestrate would print
+// nothing until they finish. On a terminal they keep one line updated in
+// place instead: iteration or step, elapsed time, an upper bound on the
+// time left, and what the run is doing now. The line is cleared before
+// the answer is printed. --quiet, --verbose and a redirected stdout turn
+// it off.
+//
+// Information Hiding:
+// - Redraw timing and terminal control sequences hidden
+// - Other output interleaved above the line (printLive)
+// - Time-left estimate hidden
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/orchestration"
+)
+
+const (
+	progressRedraw      = 200 * time.Millisecond
+	progressActivityLen = 40
+)
+
+// progressSpinner frames, advanced on every redraw.
+var progressSpinner = []string{"|", "/", "-", "\\"}
+
+// liveProgress is the progress line being drawn, if any. Output printed
+// while it is drawn goes through printLive.
+var (
+	liveMu       sync.Mutex
+	liveProgress *progressLine
+)
+
+// printLive prints s on stdout above the progress line, if one is drawn.
+func printLive(s string) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	p := liveProgress
+	if p == nil {
+		fmt.Print(s)
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprint(p.out, "\r\033[K"+s)
+	fmt.Fprint(p.out, p.render(time.Since(p.start)))
+}
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal.
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressLine is the updating status line of one run. All methods are
+// safe on nil, which is what newProgressLine returns when the line is off.
+type progressLine struct {
+	out   io.Writer
+	unit  string // "iter" or "step"
+	max   int
+	start time.Time
+
+	mu       sync.Mutex
+	done     int // Iterations or steps finished
+	activity string
+	frame    int
+
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// newProgressLine starts a progress line counting up to max units, or
+// returns nil if opts or stdout rule it out.
+func newProgressLine(opts Options, unit string, max int) *progressLine {
+	if opts.Verbose || opts.Quiet || !stdoutIsTerminal() {
+		return nil
+	}
+	p := &progressLine{
+		out:      os.Stdout,
+		unit:     unit,
+		max:      max,
+		start:    time.Now(),
+		activity: "starting",
+		stop:     make(chan struct{}),
+	}
+	liveMu.Lock()
+	liveProgress = p
+	liveMu.Unlock()
+	p.stopped.Add(1)
+	go p.redrawLoop()
+	return p
+}
+
+// advance records that done units have finished; the next one starts
+// with activity.
+func (p *progressLine) advance(done int, activity string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.done = done
+	p.activity = activity
+	p.mu.Unlock()
+}
+
+// doing sets what the run is doing now, e.g. the tool it is running.
+func (p *progressLine) doing(activity string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.activity = activity
+	p.mu.Unlock()
+}
+
+// finish stops redrawing and clears the line.
+func (p *progressLine) finish() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	p.stopped.Wait()
+	liveMu.Lock()
+	if liveProgress == p {
+		liveProgress = nil
+	}
+	liveMu.Unlock()
+	fmt.Fprint(p.out, "\r\033[K")
+}
+
+func (p *progressLine) redrawLoop() {
+	defer p.stopped.Done()
+	ticker := time.NewTicker(progressRedraw)
+	defer ticker.Stop()
+	for {
+		p.redraw()
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *progressLine) redraw() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frame++
+	fmt.Fprint(p.out, "\r\033[K"+p.render(time.Since(p.start)))
+}
+
+// render formats the line for a run elapsed long. Callers hold p.mu.
+func (p *progressLine) render(elapsed time.Duration) string {
+	current := min(p.done+1, p.max)
+	line := fmt.Sprintf("%s %s %d/%d | %s", progressSpinner[p.frame%len(progressSpinner)], p.unit, current, p.max, formatClock(elapsed))
+	if p.done > 0 && p.done < p.max {
+		// At the average pace so far, if every remaining unit is used
+		left := elapsed / time.Duration(p.done) * time.Duration(p.max-p.done)
+		line += " | ≤" + formatClock(left) + " left"
+	}
+	return line + " | " + text.Clip(text.OneLine(p.activity), progressActivityLen)
+}
+
+// formatClock formats d as m:ss, or h:mm:ss from an hour.
+func formatClock(d time.Duration) string {
+	s := int(d.Round(time.Second).Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// withProgressLine reports each orchestration step on progress.
+func withProgressLine(supervisor *orchestration.Supervisor, progress *progressLine) *orchestration.Supervisor {
+	if progress == nil {
+		return supervisor
+	}
+	return supervisor.WithStepObserver(func(step int, _ orchestration.TokenStats) {
+		progress.advance(step, "supervising")
+	})
+}
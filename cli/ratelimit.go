@@ -0,0 +1,62 @@
+// Shared provider rate limiting.
+//
+// Every provider the CLI creates consults one process-wide llm.RateLimiter,
+// so the root agent, sub-agents, supervisors and verifiers queue against
+// the same per-minute budget. Limits come from config
+// (LLM_REQUESTS_PER_MINUTE, LLM_TOKENS_PER_MINUTE, or per provider as
+// OPENAI_REQUESTS_PER_MINUTE etc).
+//
+// Information Hiding:
+// - Limiter lifetime (one per process) hidden
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/richinex/ariadne/config"
+	"github.com/richinex/ariadne/llm"
+)
+
+var (
+	rateLimiterOnce sync.Once
+	rateLimiter     *llm.RateLimiter
+)
+
+// sharedRateLimiter returns the process-wide limiter with settings' limit
+// registered for its provider and model, or nil if settings has none.
+func sharedRateLimiter(settings config.LLMConfig) *llm.RateLimiter {
+	if settings.RequestsPerMinute <= 0 && settings.TokensPerMinute <= 0 {
+		return nil
+	}
+	rateLimiterOnce.Do(func() {
+		rateLimiter = llm.NewRateLimiter(llm.RateLimit{})
+	})
+	rateLimiter.SetLimit(settings.Provider, settings.Model, llm.RateLimit{
+		RequestsPerMinute: settings.RequestsPerMinute,
+		TokensPerMinute:   settings.TokensPerMinute,
+	})
+	return rateLimiter
+}
+
+// printRateLimitStats reports calls that queued for the rate limit.
+func printRateLimitStats() {
+	if rateLimiter == nil {
+		return
+	}
+	stats := rateLimiter.Stats()
+	keys := make([]string, 0, len(stats))
+	for key, s := range stats {
+		if s.Queued > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := stats[key]
+		fmt.Printf("Rate limit %s: %d of %d calls queued, %s waiting\n", key, s.Queued, s.Requests, s.TotalWait.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,433 @@
+// Run reports for --report-out.
+//
+// react-orchestrate and rlm can write a self-contained report of a run
+// when it ends: the task, the supervisor's plan, a timeline of steps, the
+// spawn tree, token usage and estimated cost, and the final answer with
+// the files and pages stored as its sources. It is meant to be shared
+// with people who didn't watch the terminal. A path ending in .md or
+// .markdown gets Markdown; anything else gets one HTML file with its
+// styles inline.
+//
+// Information Hiding:
+// - Collection of report data from orchestration and loop responses
+// - Markdown and HTML layout
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/orchestration"
+	"github.com/richinex/ariadne/tools"
+)
+
+// maxReportObservationLen caps each step's observation in a report.
+const maxReportObservationLen = 2000
+
+// runReport is what a report shows about one run.
+type runReport struct {
+	Command     string
+	Task        string
+	Model       string
+	Started     time.Time
+	Duration    time.Duration
+	Outcome     string // success, failure or timeout
+	AnswerLabel string
+	Answer      string
+	Plan        []orchestration.PlanItem
+	Steps       []model.Step
+	Spawns      []reportSpawn
+	Messages    []orchestration.AgentMessage // ask_agent questions
+	Usage       []reportUsage
+	LLMCalls    int
+	ToolCalls   int
+	Cost        runCost
+	Environment *model.Environment
+	Sources     []string
+}
+
+// reportSpawn is one sub-agent in the spawn tree.
+type reportSpawn struct {
+	tools.SpawnRecord
+	Tokens uint32 // Whole subtree, on top-level spawns only
+}
+
+// reportUsage is token usage under a label (an agent depth, or a total).
+type reportUsage struct {
+	Label string
+	llm.TokenUsage
+}
+
+// orchestrationReport collects the report of a supervisor run.
+func orchestrationReport(task, modelName string, started time.Time, resp orchestration.Response, fileContext *tools.StoredFileContext) runReport {
+	r := runReport{
+		Command:  "react-orchestrate",
+		Task:     task,
+		Model:    modelName,
+		Started:  started,
+		Duration: time.Since(started),
+		Steps:    resp.Steps,
+		Sources:  reportSources(fileContext),
+	}
+	switch resp.Type {
+	case orchestration.ResponseSuccess:
+		r.Outcome, r.AnswerLabel, r.Answer = "success", "Final answer", resp.Result
+	case orchestration.ResponseTimeout:
+		r.Outcome, r.AnswerLabel, r.Answer = "timeout", "Partial result", resp.PartialResult
+	default:
+		r.Outcome, r.AnswerLabel, r.Answer = "failure", "Error", resp.Error
+	}
+	if meta := resp.Metadata; meta != nil {
+		r.Plan = meta.Plan
+		r.Messages = meta.AgentMessages
+		r.ToolCalls = len(meta.ToolCalls)
+		r.Environment = meta.Environment
+		if stats := meta.TokenStats; stats != nil {
+			usage := llm.TokenUsage{
+				PromptTokens:       stats.PromptTokens,
+				CompletionTokens:   stats.CompletionTokens,
+				TotalTokens:        stats.TotalTokens,
+				CachedPromptTokens: stats.CachedPromptTokens,
+			}
+			r.Usage = []reportUsage{{Label: "all agents", TokenUsage: usage}}
+			r.LLMCalls = stats.LLMCalls
+			r.Cost = estimateCost(modelUsage{modelName, usage})
+		}
+	}
+	return r
+}
+
+// rlmReport collects the report of an RLM run. resp is the root agent's
+// response; metrics must already include the root's tokens.
+func rlmReport(task, modelName string, started time.Time, resp agent.Response, metrics *tools.SpawnMetrics, control *tools.SpawnControl, cost runCost, fileContext *tools.StoredFileContext) runReport {
+	r := runReport{
+		Command:     "rlm",
+		Task:        task,
+		Model:       modelName,
+		Started:     started,
+		Duration:    time.Since(started),
+		Steps:       resp.Steps,
+		LLMCalls:    int(metrics.LLMCalls.Load()),
+		ToolCalls:   int(metrics.ToolCalls.Load()),
+		Cost:        cost,
+		Environment: resp.Metadata.Environment,
+		Sources:     reportSources(fileContext),
+	}
+	switch resp.Type {
+	case agent.ResponseSuccess:
+		r.Outcome, r.AnswerLabel, r.Answer = "success", "Final answer", resp.Result
+	case agent.ResponseTimeout:
+		r.Outcome, r.AnswerLabel, r.Answer = "timeout", "Partial result", resp.PartialResult
+	default:
+		r.Outcome, r.AnswerLabel, r.Answer = "failure", "Error", resp.Error
+		if resp.PartialResult != "" {
+			r.Answer += "\n\nPartial result:\n" + resp.PartialResult
+		}
+	}
+
+	byDepth := metrics.TokensByDepth()
+	depths := make([]int, 0, len(byDepth))
+	for d := range byDepth {
+		depths = append(depths, d)
+	}
+	sort.Ints(depths)
+	for _, d := range depths {
+		label := "root"
+		if d > 0 {
+			label = fmt.Sprintf("depth %d", d)
+		}
+		r.Usage = append(r.Usage, reportUsage{Label: label, TokenUsage: byDepth[d]})
+	}
+	if len(depths) > 1 {
+		r.Usage = append(r.Usage, reportUsage{Label: "total", TokenUsage: metrics.Tokens()})
+	}
+
+	bySubtree := metrics.TokensBySubtree()
+	for _, rec := range control.Finished() {
+		r.Spawns = append(r.Spawns, reportSpawn{SpawnRecord: rec, Tokens: bySubtree[rec.ID].TotalTokens})
+	}
+	return r
+}
+
+// reportSources lists the content stored during the run, sorted.
+func reportSources(fileContext *tools.StoredFileContext) []string {
+	if fileContext == nil {
+		return nil
+	}
+	var sources []string
+	for _, key := range fileContext.List() {
+		if key != "user_prompt" {
+			sources = append(sources, key)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// writeReport writes r to path, as Markdown or HTML by its extension,
+// and says where it went.
+func writeReport(path string, r runReport) {
+	var data []byte
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		data = []byte(r.markdown())
+	default:
+		html, err := r.html()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to render report: %v\n", err)
+			return
+		}
+		data = html
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write report: %v\n", err)
+			return
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write report: %v\n", err)
+		return
+	}
+	fmt.Printf("Report written to %s\n", path)
+}
+
+// reportObservation returns a step's observation, capped for a report.
+func reportObservation(step model.Step) string {
+	if step.Observation == nil {
+		return ""
+	}
+	return text.TruncateRunes(*step.Observation, maxReportObservationLen)
+}
+
+// markdown renders r as a Markdown document.
+func (r runReport) markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# ariadne %s report\n\n", r.Command)
+	fmt.Fprintf(&sb, "- Started: %s\n", r.Started.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "- Duration: %s\n", r.Duration.Round(time.Second))
+	fmt.Fprintf(&sb, "- Model: %s\n", r.Model)
+	fmt.Fprintf(&sb, "- Outcome: %s\n\n", r.Outcome)
+
+	sb.WriteString("## Task\n\n" + fence(r.Task) + "\n")
+
+	if len(r.Plan) > 0 {
+		sb.WriteString("## Plan\n\n| Sub-goal | Description | Status | Agent | After |\n|---|---|---|---|---|\n")
+		for _, item := range r.Plan {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", mdCell(item.ID), mdCell(item.Description),
+				item.Status, mdCell(item.Agent), mdCell(strings.Join(item.DependsOn, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Steps) > 0 {
+		sb.WriteString("## Timeline\n\n")
+		for _, step := range r.Steps {
+			fmt.Fprintf(&sb, "### Step %d\n\n", step.Iteration)
+			if step.Thought != "" {
+				sb.WriteString(step.Thought + "\n\n")
+			}
+			if step.Action != nil {
+				sb.WriteString("Action:\n\n" + fence(*step.Action) + "\n")
+			}
+			if obs := reportObservation(step); obs != "" {
+				sb.WriteString("Observation:\n\n" + fence(obs) + "\n")
+			}
+		}
+	}
+
+	if len(r.Spawns) > 0 {
+		sb.WriteString("## Spawn tree\n\n")
+		for _, s := range r.Spawns {
+			fmt.Fprintf(&sb, "%s- **%s** %s (%s", strings.Repeat("  ", max(s.Depth-1, 0)), s.ID, s.Task, s.Duration.Round(time.Millisecond))
+			if s.Tokens > 0 {
+				fmt.Fprintf(&sb, ", %d tokens", s.Tokens)
+			}
+			if s.Err != "" {
+				fmt.Fprintf(&sb, ", failed: %s", text.OneLine(s.Err))
+			}
+			sb.WriteString(")\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Messages) > 0 {
+		sb.WriteString("## Agent messages\n\n")
+		for _, m := range r.Messages {
+			fmt.Fprintf(&sb, "### %s asked %s (%dms)\n\n", m.From, m.To, m.DurationMs)
+			sb.WriteString(fence(m.Question) + "\n")
+			if m.Error != "" {
+				fmt.Fprintf(&sb, "Failed: %s\n\n", text.OneLine(m.Error))
+			} else {
+				sb.WriteString("Answer:\n\n" + fence(m.Answer) + "\n")
+			}
+		}
+	}
+
+	sb.WriteString("## Usage\n\n")
+	if len(r.Usage) > 0 {
+		sb.WriteString("| | Prompt | Cached | Completion | Total |\n|---|---:|---:|---:|---:|\n")
+		for _, u := range r.Usage {
+			fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d |\n", u.Label, u.PromptTokens, u.CachedPromptTokens, u.CompletionTokens, u.TotalTokens)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "LLM calls: %d, tool calls: %d, estimated cost: %s\n\n", r.LLMCalls, r.ToolCalls, r.Cost)
+
+	if env := r.Environment; env != nil {
+		sb.WriteString("## Environment\n\n")
+		for _, row := range environmentRows(env) {
+			fmt.Fprintf(&sb, "- %s: %s\n", row[0], row[1])
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "## %s\n\n%s\n\n", r.AnswerLabel, strings.TrimSpace(r.Answer))
+
+	if len(r.Sources) > 0 {
+		sb.WriteString("## Sources\n\n")
+		for _, src := range r.Sources {
+			fmt.Fprintf(&sb, "- `%s`\n", src)
+		}
+	}
+	return sb.String()
+}
+
+// environmentRows lists env as label/value pairs, tools sorted by name.
+func environmentRows(env *model.Environment) [][2]string {
+	rows := [][2]string{
+		{"ariadne", fmt.Sprintf("%s (%s, %s/%s)", env.AriadneVersion, env.GoVersion, env.OS, env.Arch)},
+		{"Provider", fmt.Sprintf("%s (%s)", env.Provider, env.Model)},
+	}
+	if env.GitCommit != "" {
+		workspace := env.GitCommit
+		if env.GitDirty {
+			workspace += " (dirty)"
+		}
+		rows = append(rows, [2]string{"Workspace", workspace})
+	}
+	names := make([]string, 0, len(env.Tools))
+	for name := range env.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rows = append(rows, [2]string{name, env.Tools[name]})
+	}
+	return rows
+}
+
+// fence wraps s in a code fence longer than any backtick run inside it.
+func fence(s string) string {
+	ticks := "```"
+	for strings.Contains(s, ticks) {
+		ticks += "`"
+	}
+	return ticks + "\n" + strings.TrimRight(s, "\n") + "\n" + ticks + "\n"
+}
+
+// mdCell makes s safe for one Markdown table cell.
+func mdCell(s string) string {
+	return strings.ReplaceAll(text.OneLine(s), "|", `\|`)
+}
+
+// html renders r as a self-contained HTML page.
+func (r runReport) html() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"rfc3339":     func(t time.Time) string { return t.Format(time.RFC3339) },
+	"seconds":     func(d time.Duration) time.Duration { return d.Round(time.Second) },
+	"millis":      func(d time.Duration) time.Duration { return d.Round(time.Millisecond) },
+	"indent":      func(depth int) int { return max(depth-1, 0) * 24 },
+	"join":        strings.Join,
+	"observation": reportObservation,
+	"environment": environmentRows,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ariadne {{.Command}} report</title>
+<style>
+body { font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #1f2328; }
+h1 { font-size: 1.6em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+h2 { font-size: 1.25em; margin-top: 1.8em; border-bottom: 1px solid #d0d7de; padding-bottom: .2em; }
+pre { background: #f6f8fa; padding: .8em; border-radius: 6px; white-space: pre-wrap; word-break: break-word; }
+table { border-collapse: collapse; margin: .5em 0; }
+th, td { border: 1px solid #d0d7de; padding: .3em .7em; text-align: left; vertical-align: top; }
+td.n { text-align: right; font-variant-numeric: tabular-nums; }
+.meta { color: #59636e; }
+.success { color: #1a7f37; } .failure, .failed { color: #cf222e; } .timeout { color: #9a6700; }
+details { margin: .4em 0; } summary { cursor: pointer; }
+.spawn { margin: .2em 0; }
+code { font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>ariadne {{.Command}} report</h1>
+<p class="meta">Started {{rfc3339 .Started}} &middot; ran {{seconds .Duration}} &middot; model {{.Model}} &middot; <span class="{{.Outcome}}">{{.Outcome}}</span></p>
+
+<h2>Task</h2>
+<pre>{{.Task}}</pre>
+{{if .Plan}}
+<h2>Plan</h2>
+<table>
+<tr><th>Sub-goal</th><th>Description</th><th>Status</th><th>Agent</th><th>After</th></tr>
+{{range .Plan}}<tr><td>{{.ID}}</td><td>{{.Description}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Agent}}</td><td>{{join .DependsOn ", "}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Steps}}
+<h2>Timeline</h2>
+{{range .Steps}}<details>
+<summary>Step {{.Iteration}}{{if .Thought}}: {{.Thought}}{{end}}</summary>
+{{if .Action}}<p>Action:</p><pre>{{.Action}}</pre>{{end}}
+{{with observation .}}<p>Observation:</p><pre>{{.}}</pre>{{end}}
+</details>
+{{end}}{{end}}{{if .Spawns}}
+<h2>Spawn tree</h2>
+{{range .Spawns}}<div class="spawn" style="margin-left: {{indent .Depth}}px"><strong>{{.ID}}</strong> {{.Task}} <span class="meta">({{millis .Duration}}{{if .Tokens}}, {{.Tokens}} tokens{{end}})</span>{{if .Err}} <span class="failed">failed: {{.Err}}</span>{{end}}</div>
+{{end}}{{end}}{{if .Messages}}
+<h2>Agent messages</h2>
+{{range .Messages}}<details>
+<summary>{{.From}} asked {{.To}}: {{.Question}}</summary>
+<pre>{{.Question}}</pre>
+{{if .Error}}<p class="failed">Failed: {{.Error}}</p>{{else}}<p>Answer:</p><pre>{{.Answer}}</pre>{{end}}
+</details>
+{{end}}{{end}}
+<h2>Usage</h2>
+{{if .Usage}}<table>
+<tr><th></th><th>Prompt</th><th>Cached</th><th>Completion</th><th>Total</th></tr>
+{{range .Usage}}<tr><td>{{.Label}}</td><td class="n">{{.PromptTokens}}</td><td class="n">{{.CachedPromptTokens}}</td><td class="n">{{.CompletionTokens}}</td><td class="n">{{.TotalTokens}}</td></tr>
+{{end}}</table>
+{{end}}<p>LLM calls: {{.LLMCalls}}, tool calls: {{.ToolCalls}}, estimated cost: {{.Cost}}</p>
+{{with .Environment}}
+<h2>Environment</h2>
+<table>
+{{range environment .}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>{{.AnswerLabel}}</h2>
+<pre>{{.Answer}}</pre>
+{{if .Sources}}
+<h2>Sources</h2>
+<ul>
+{{range .Sources}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
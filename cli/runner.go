@@ -9,13 +9,19 @@ package cli
 
 import (
 	"bufio"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/internal/text"
 	"github.com/richinex/ariadne/model"
 	"github.com/richinex/ariadne/config"
 	"github.com/richinex/ariadne/llm"
@@ -27,11 +33,115 @@ import (
 
 // Options holds CLI execution options.
 type Options struct {
-	Provider         string
-	SubagentProvider string // Optional: different provider for sub-agents in RLM mode
-	MaxIter          int
-	ToolRetries      uint32
-	Verbose          bool
+	Provider           string
+	SubagentProvider   string // Optional: different provider for sub-agents in RLM mode
+	AdaptiveSpawn      bool   // Size each RLM spawn's depth, iterations and timeout to its task
+	SubagentStoreWrite bool   // Let RLM sub-agents write to the result store (read-only by default)
+	MaxIter            int
+	ToolRetries        uint32
+	Verbose            bool
+	// MaxObservationBytes caps each tool observation in the conversation.
+	// Larger outputs are stored in ResultStore. Zero uses the default (8KB).
+	MaxObservationBytes int
+	HTTPProfilesPath    string // Optional: JSON file of named http_request auth profiles
+	HTTPRetries         int    // Retries for transient HTTP failures
+	// Artifacts redirects write_file/append_file into .ariadne/artifacts/<run-id>/
+	// instead of the working tree.
+	Artifacts bool
+	// ContextPack names a pack created with `ariadne context create` to mount
+	// read-only into the run's stored content.
+	ContextPack string
+	// Timeout bounds react-run, each react-chat turn, react-orchestrate,
+	// each batch task, workflow runs and each lsp-bridge request in
+	// seconds. Zero means no deadline (lsp-bridge defaults to 120s).
+	Timeout int
+	// Quiet makes the final answer the only output on stdout for
+	// react-run, react-orchestrate, rlm and workflow run.
+	Quiet bool
+	// Render renders markdown in final answers for the terminal.
+	Render bool
+	// ShowDiffs prints a diff of each file change tools make (implied by
+	// Verbose).
+	ShowDiffs bool
+	// ReportOut, if set, is where react-orchestrate and rlm write a report
+	// of the run (Markdown for .md, else HTML).
+	ReportOut string
+	// DebugLLM logs provider HTTP traffic to .ariadne/llm-wire.jsonl with
+	// secrets redacted and conversation content hashed.
+	DebugLLM bool
+	// DebugLLMContent also logs conversation content verbatim (implies DebugLLM).
+	DebugLLMContent bool
+	// ParallelSubGoals lets the supervisor run independent sub-goals on
+	// different agents at the same time (react-orchestrate).
+	ParallelSubGoals bool
+	// AgentMessages lets agents ask each other questions with ask_agent
+	// (react-orchestrate).
+	AgentMessages bool
+	// Verify checks each sub-goal result with an LLM before it is marked
+	// completed, retrying rejected ones with feedback (react-orchestrate).
+	Verify bool
+	// VerifyProvider is the provider used for verification; empty uses
+	// Provider. A cheaper model is usually enough.
+	VerifyProvider string
+	// StoreSession is the result store keyspace for files and outputs
+	// stored during the run. Empty generates a fresh one per run, so
+	// concurrent runs sharing the database don't see each other's content.
+	StoreSession string
+	// KeepStore retains the run's stored content after it finishes, for
+	// inspection (e.g. with list_stored in a later --store-session run).
+	KeepStore bool
+	// Summarizer names how stored content is summarized for agents: head
+	// (leading lines, the default), structure or llm (see
+	// storage.NewSummarizer).
+	Summarizer string
+	// SupervisorProvider is the provider for the supervisor's decisions in
+	// react-orchestrate; empty uses Provider.
+	SupervisorProvider string
+	// ScreenWebContent checks web content with an LLM classifier and
+	// withholds prompt injections from the model.
+	ScreenWebContent bool
+	// BlockInjectedCalls denies side-effecting tool calls whose arguments
+	// were copied from ingested files or web content.
+	BlockInjectedCalls bool
+	// Tools, if set, are the only tools offered to agents and sub-agents;
+	// DenyTools are never offered. Names are checked against the known tools.
+	Tools     []string
+	DenyTools []string
+	// EgressPolicyPath is an optional JSON egress policy for network tools
+	// (see tools.LoadEgressPolicy).
+	EgressPolicyPath string
+	// ExternalToolsDir is a directory whose executables are loaded as tools
+	// (see tools.LoadExternalTools). Empty loads none, since loading runs
+	// each executable.
+	ExternalToolsDir string
+	// Audit records file writes and shell commands in the audit log of the
+	// default database (also set by ARIADNE_AUDIT).
+	Audit bool
+	// MaskPII replaces emails, phone numbers and card numbers in tool
+	// results with placeholders before they reach a prompt (also set by
+	// ARIADNE_MASK_PII).
+	MaskPII bool
+	// PIIPatterns are extra patterns to mask, as NAME=REGEX; setting any
+	// implies MaskPII.
+	PIIPatterns []string
+	// ValidateProvider checks each provider's API key and model when it is
+	// created, before the run starts (also set by LLM_VALIDATE).
+	ValidateProvider bool
+	// WrapUp decides how agent loops wrap up near MaxIter: when they are
+	// warned, and whether the step at the threshold must answer without
+	// tools (see tools.WrapUpPolicy).
+	WrapUp tools.WrapUpPolicy
+	// SystemPrompt is prepended to the built-in system prompt of
+	// react-run, react-chat and rlm (the root agent), for house style or
+	// domain constraints. SystemPromptFile, if set, is read instead.
+	SystemPrompt     string
+	SystemPromptFile string
+	// ExamplesPath is a few-shot example library (see
+	// agent.LoadExampleLibrary) for agents that answer with decision JSON:
+	// react-orchestrate, batch and workflow run. ExampleBudget caps the
+	// examples each agent shows, in estimated tokens (0 = default).
+	ExamplesPath  string
+	ExampleBudget int
 }
 
 // DefaultOptions returns default CLI options.
@@ -45,25 +155,44 @@ func DefaultOptions() Options {
 
 // RunTask executes a single task with an agent.
 func RunTask(ctx context.Context, task, agentName, systemPrompt string, opts Options) error {
-	provider, err := createProvider(opts.Provider)
+	provider, err := createProvider(opts.Provider, opts)
 	if err != nil {
 		return err
 	}
 
+	environment := orchestration.CaptureEnvironment(ctx, provider)
+	if opts.Verbose {
+		printEnvironment(environment)
+	}
+
 	// Create ResultStore for RLM pattern
-	resultStore, cleanup := createResultStore()
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
 	if cleanup != nil {
 		defer cleanup()
 	}
 
 	// Pre-store any files mentioned in the task (automatic context)
-	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore)
+	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore, storeSessionID)
 
-	toolConfig := tools.ToolConfig{MaxRetries: opts.ToolRetries}
-	a, err := CreateAgent(agentName, systemPrompt, provider, toolConfig, resultStore, fileContext)
+	toolConfig, err := newToolConfig(opts, provider, storeSessionID)
+	if err != nil {
+		return err
+	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
+	if err := validateToolFilter(toolConfig.Filter, nil); err != nil {
+		return err
+	}
+	a, err := CreateAgent(agentName, systemPrompt, provider, toolConfig, resultStore, storeSessionID, fileContext)
 	if err != nil {
 		return err
 	}
+	if err := attachExamples([]*agent.Agent{a}, opts); err != nil {
+		return err
+	}
 
 	if opts.Verbose {
 		a = a.Verbose(true)
@@ -72,23 +201,25 @@ func RunTask(ctx context.Context, task, agentName, systemPrompt string, opts Opt
 	fmt.Printf("Running task with %s agent...\n\n", agentName)
 
 	response := a.Execute(ctx, task, opts.MaxIter)
+	response.Metadata.Environment = environment
+	response.Metadata.MaskedPII = toolConfig.PII.Report()
 
 	switch response.Type {
 	case agent.ResponseSuccess:
 		if opts.Verbose {
 			printAgentSteps(response.Steps)
 		}
-		fmt.Printf("%s\n\n", response.Result)
+		fmt.Fprintf(answerOut, "%s\n\n", formatAnswer(response.Result, opts))
 		if len(response.Steps) > 0 {
 			fmt.Printf("(%d steps)\n", len(response.Steps))
 		}
 		return nil
 	case agent.ResponseFailure:
 		fmt.Fprintf(os.Stderr, "Error: %s\n", response.Error)
-		return fmt.Errorf("task failed: %s", response.Error)
+		return fmt.Errorf("task failed: %w", response.AsError())
 	case agent.ResponseTimeout:
 		fmt.Printf("Timeout. Partial result:\n%s\n", response.PartialResult)
-		return fmt.Errorf("task timed out")
+		return fmt.Errorf("task timed out: %w", response.AsError())
 	default:
 		return fmt.Errorf("unknown response type: %v", response.Type)
 	}
@@ -96,13 +227,20 @@ func RunTask(ctx context.Context, task, agentName, systemPrompt string, opts Opt
 
 // Chat starts an interactive chat session.
 func Chat(ctx context.Context, agentName, systemPrompt, sessionID, dbPath string, opts Options) error {
-	provider, err := createProvider(opts.Provider)
+	provider, err := createProvider(opts.Provider, opts)
 	if err != nil {
 		return err
 	}
 
+	if opts.Verbose {
+		printEnvironment(orchestration.CaptureEnvironment(ctx, provider))
+	}
+
 	// Create ResultStore for RLM pattern
-	resultStore, cleanup := createResultStore()
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
 	if cleanup != nil {
 		defer cleanup()
 	}
@@ -110,11 +248,22 @@ func Chat(ctx context.Context, agentName, systemPrompt, sessionID, dbPath string
 	// Create file context for RLM (will be populated as files are read)
 	fileContext := tools.NewStoredFileContext()
 
-	toolConfig := tools.ToolConfig{MaxRetries: opts.ToolRetries}
-	a, err := CreateAgent(agentName, systemPrompt, provider, toolConfig, resultStore, fileContext)
+	toolConfig, err := newToolConfig(opts, provider, cmp.Or(sessionID, storeSessionID))
+	if err != nil {
+		return err
+	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
+	if err := validateToolFilter(toolConfig.Filter, nil); err != nil {
+		return err
+	}
+	a, err := CreateAgent(agentName, systemPrompt, provider, toolConfig, resultStore, storeSessionID, fileContext)
 	if err != nil {
 		return err
 	}
+	if err := attachExamples([]*agent.Agent{a}, opts); err != nil {
+		return err
+	}
 
 	// Set up storage if session provided
 	var store *storage.SqliteStorage
@@ -134,14 +283,15 @@ func Chat(ctx context.Context, agentName, systemPrompt, sessionID, dbPath string
 
 	// Load existing history
 	var history []llm.ChatMessage
+	var revision int64
 	if store != nil {
-		history, err = store.Load(ctx, session)
+		history, revision, err = store.LoadWithRevision(ctx, session, chatHistoryWindow)
 		if err != nil {
 			return fmt.Errorf("failed to load history: %w", err)
 		}
-		if len(history) > 0 {
-			fmt.Printf("Resuming session '%s' (%d messages)\n\n", session, len(history))
-		}
+		printResumedSession(ctx, store, session, len(history))
+		// Turns that fail leave their progress for the next one
+		a = a.WithStorage(store, session)
 	}
 
 	fmt.Printf("Chat with %s agent. Type 'exit' to quit.\n\n", agentName)
@@ -168,15 +318,16 @@ func Chat(ctx context.Context, agentName, systemPrompt, sessionID, dbPath string
 			fmt.Printf("\n%s\n\n", response.Result)
 
 			// Add to history
-			history = append(history,
-				llm.ChatMessage{Role: "user", Content: input},
-				llm.ChatMessage{Role: "assistant", Content: response.Result},
-			)
+			turn := []llm.ChatMessage{
+				{Role: "user", Content: input},
+				{Role: "assistant", Content: response.Result},
+			}
+			history = append(history, turn...)
 
 			// Save to storage
 			if store != nil {
-				if err := store.Save(ctx, session, history); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to save history: %v\n", err)
+				if err := appendChatTurn(ctx, store, session, turn, &revision); err != nil {
+					return err
 				}
 			}
 		case agent.ResponseFailure:
@@ -189,55 +340,110 @@ func Chat(ctx context.Context, agentName, systemPrompt, sessionID, dbPath string
 	return scanner.Err()
 }
 
+// agentBudget is the per-invocation budget supervisors give their agents.
+func agentBudget(settings config.AgentConfig) agent.Budget {
+	return agent.Budget{
+		MaxTokens:   settings.MaxAgentTokens,
+		MaxDuration: time.Duration(settings.MaxAgentSeconds) * time.Second,
+	}
+}
+
+// withVerifier adds an LLM verifier to the supervisor when opts.Verify is set.
+func withVerifier(supervisor *orchestration.Supervisor, opts Options) (*orchestration.Supervisor, error) {
+	if !opts.Verify {
+		return supervisor, nil
+	}
+	providerName := opts.VerifyProvider
+	if providerName == "" {
+		providerName = opts.Provider
+	}
+	provider, err := createProvider(providerName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verify provider: %w", err)
+	}
+	verifier := orchestration.NewLLMVerifier(llm.NewClient(provider))
+	return supervisor.WithVerifier(verifier, -1), nil
+}
+
 // Orchestrate executes a complex task across multiple agents.
 func Orchestrate(ctx context.Context, task string, agentNames []string, sessionID, dbPath string, opts Options) error {
-	provider, err := createProvider(opts.Provider)
+	provider, err := createProvider(opts.Provider, opts)
 	if err != nil {
 		return err
 	}
 
-	toolConfig := tools.ToolConfig{MaxRetries: opts.ToolRetries}
-	llmClient := llm.NewClient(provider)
+	environment := orchestration.CaptureEnvironment(ctx, provider)
+	if opts.Verbose {
+		printEnvironment(environment)
+	}
+
+	toolConfig, err := newToolConfig(opts, provider, sessionID)
+	if err != nil {
+		return err
+	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
+	if err := validateToolFilter(toolConfig.Filter, nil); err != nil {
+		return err
+	}
+	llmClient, err := supervisorClient(provider, opts)
+	if err != nil {
+		return err
+	}
 
 	// Create ResultStore for RLM pattern (used by both agents and supervisor)
-	resultStore, cleanup := createResultStore()
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
 	if cleanup != nil {
 		defer cleanup()
 	}
 
 	// Pre-store any files mentioned in the task (automatic context)
-	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore)
+	userTask := task
+	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore, storeSessionID)
 
 	// Create agents with shared file context for RLM pattern
 	var agents []*agent.Agent
 	if len(agentNames) > 0 {
 		for _, name := range agentNames {
-			a, err := CreateAgent(name, "", provider, toolConfig, resultStore, fileContext)
+			a, err := CreateAgent(name, "", provider, toolConfig, resultStore, storeSessionID, fileContext)
 			if err != nil {
 				return fmt.Errorf("failed to create agent %s: %w", name, err)
 			}
 			agents = append(agents, a)
 		}
 	} else {
-		agents = CreateDefaultAgents(provider, toolConfig, resultStore, fileContext)
+		agents = CreateDefaultAgents(provider, toolConfig, resultStore, storeSessionID, fileContext)
+	}
+	if err := attachExamples(agents, opts); err != nil {
+		return err
 	}
 
-	settings, err := config.New(opts.Provider)
+	agentSettings, err := config.Agent()
 	if err != nil {
 		return err
 	}
 
 	supervisorConfig := orchestration.SupervisorConfig{
-		MaxSubGoals:          settings.Agent.MaxSubGoals,
-		MaxIterations:        settings.Agent.MaxIterations,
+		MaxSubGoals:          agentSettings.MaxSubGoals,
+		MaxIterations:        agentSettings.MaxIterations,
 		LargeResultThreshold: 1024, // 1KB threshold
+		AgentBudget:          agentBudget(agentSettings),
+		ParallelSubGoals:     opts.ParallelSubGoals,
 	}
 
 	supervisor := orchestration.NewSupervisor(agents, llmClient, supervisorConfig)
+	supervisor, err = withVerifier(supervisor, opts)
+	if err != nil {
+		return err
+	}
+	supervisor = withStatusHUD(supervisor, opts, provider.Model())
 
 	// Also give ResultStore to supervisor for storing large agent results
 	if resultStore != nil {
-		supervisor = supervisor.WithResultStore(resultStore)
+		supervisor = supervisor.WithResultStore(resultStore).WithResultSession(storeSessionID)
 	}
 
 	if sessionID != "" {
@@ -255,15 +461,33 @@ func Orchestrate(ctx context.Context, task string, agentNames []string, sessionI
 	if opts.Verbose {
 		supervisor = supervisor.Verbose(true)
 	}
-
-	response := supervisor.Orchestrate(ctx, task, opts.MaxIter)
+	var bus *orchestration.MessageBus
+	if opts.AgentMessages {
+		bus = orchestration.NewMessageBus(0)
+		supervisor = supervisor.WithMessageBus(bus)
+	}
+
+	started := time.Now()
+	progress := newProgressLine(opts, "step", opts.MaxIter)
+	response := withProgressLine(supervisor, progress).Orchestrate(ctx, task, opts.MaxIter)
+	progress.finish()
+	if response.Metadata != nil {
+		response.Metadata.Environment = environment
+		response.Metadata.MaskedPII = toolConfig.PII.Report()
+		if bus != nil {
+			response.Metadata.AgentMessages = bus.Messages()
+		}
+	}
+	if opts.ReportOut != "" {
+		writeReport(opts.ReportOut, orchestrationReport(userTask, provider.Model(), started, response, fileContext))
+	}
 
 	switch response.Type {
 	case orchestration.ResponseSuccess:
 		if opts.Verbose {
 			printOrchestrationSteps(response.Steps)
 		}
-		fmt.Printf("%s\n\n", response.Result)
+		fmt.Fprintf(answerOut, "%s\n\n", formatAnswer(response.Result, opts))
 		fmt.Printf("Completed in %d steps\n", len(response.Steps))
 		printTokenStats(response.Metadata)
 		return nil
@@ -273,14 +497,15 @@ func Orchestrate(ctx context.Context, task string, agentNames []string, sessionI
 		}
 		fmt.Fprintf(os.Stderr, "Failed: %s\n", response.Error)
 		fmt.Fprintf(os.Stderr, "Completed %d steps before failure\n", len(response.Steps))
-		return fmt.Errorf("orchestration failed: %s", response.Error)
+		return fmt.Errorf("orchestration failed: %w", response.AsError())
 	case orchestration.ResponseTimeout:
 		if opts.Verbose {
 			printOrchestrationSteps(response.Steps)
 		}
 		fmt.Printf("Timeout. Partial: %s\n", response.PartialResult)
 		fmt.Printf("Completed %d steps\n", len(response.Steps))
-		return fmt.Errorf("orchestration timed out")
+		printNextSteps(response.CompletionStatus)
+		return fmt.Errorf("orchestration timed out: %w", response.AsError())
 	default:
 		return fmt.Errorf("unknown response type: %v", response.Type)
 	}
@@ -289,12 +514,13 @@ func Orchestrate(ctx context.Context, task string, agentNames []string, sessionI
 // RLM executes a task using the Recursive Language Model pattern.
 // Uses spawn-based architecture where the root agent can spawn sub-agents dynamically.
 func RLM(ctx context.Context, task string, maxDepth, timeoutSecs int, mcpServers []string, mcpConfigPath string, opts Options) error {
+	defer beginQuiet(opts)()
 	startTime := time.Now()
 
 	// Reset metrics for this session
 	metrics := tools.ResetMetrics()
 
-	provider, err := createProvider(opts.Provider)
+	provider, err := createProvider(opts.Provider, opts)
 	if err != nil {
 		return err
 	}
@@ -302,7 +528,7 @@ func RLM(ctx context.Context, task string, maxDepth, timeoutSecs int, mcpServers
 	// Create optional subagent provider for cost optimization
 	var subagentProvider llm.Provider
 	if opts.SubagentProvider != "" {
-		subagentProvider, err = createProvider(opts.SubagentProvider)
+		subagentProvider, err = createProvider(opts.SubagentProvider, opts)
 		if err != nil {
 			return fmt.Errorf("failed to create subagent provider: %w", err)
 		}
@@ -312,37 +538,48 @@ func RLM(ctx context.Context, task string, maxDepth, timeoutSecs int, mcpServers
 		}
 	}
 
+	environment := orchestration.CaptureEnvironment(ctx, provider)
+	if opts.Verbose {
+		printEnvironment(environment)
+	}
+
 	// Create ResultStore for DSA-based storage/search
-	resultStore, cleanup := createResultStore()
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
 	if cleanup != nil {
 		defer cleanup()
 	}
 
 	// Print metrics at the end
+	var storeUsage *model.DSAUsage
 	defer func() {
 		metrics.TotalDuration.Store(int64(time.Since(startTime)))
 		fmt.Printf("\n--- RLM Metrics ---\n%s\n", metrics.String())
+		printDSAUsage(storeUsage)
+		if breakdown := metrics.TokenBreakdown(); breakdown != "" {
+			fmt.Printf("Token usage:\n%s\n", breakdown)
+		}
+		printRateLimitStats()
 	}()
 
 	// Pre-store any files mentioned in the task
-	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore)
+	userTask := task
+	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore, storeSessionID)
 
 	// Store the user's prompt as searchable context (RLM pattern)
+	sessionID := storeSessionID
 	if resultStore != nil {
-		_, _ = resultStore.StoreContent(ctx, model.ContentKey{ContentType: "file", Path: "user_prompt"}, task)
+		_, _ = resultStore.SessionContent(sessionID).StoreContent(ctx, model.FileKey("user_prompt"), task)
 		fileContext.Add("user_prompt")
 	}
 
-	// Session ID for ResultStore - matches model.FileKey namespace so
-	// files stored by read_file can be searched by DSA tools
-	sessionID := "file"
-
-	// Clear stale session data from previous runs
-	// This ensures we only search content stored in THIS session, not old data
-	// whose in-memory content is no longer available
-	if resultStore != nil {
-		_ = resultStore.DeleteSession(ctx, sessionID)
+	note, err := mountContextPack(ctx, opts, resultStore, sessionID)
+	if err != nil {
+		return err
 	}
+	task += note
 
 	// Create spawn configuration
 	spawnConfig := tools.SpawnConfig{
@@ -350,34 +587,32 @@ func RLM(ctx context.Context, task string, maxDepth, timeoutSecs int, mcpServers
 		MaxIterations: opts.MaxIter,
 		Timeout:       time.Duration(timeoutSecs) * time.Second,
 	}
-	toolConfig := tools.ToolConfig{MaxRetries: opts.ToolRetries}
-
-	// Build available tools including DSA ResultStore tools
-	// Configure read_file to store content for DSA tools (RLM pattern)
-	readTool := tools.NewReadFileTool(defaultMaxFileSize)
-	if resultStore != nil {
-		readTool = readTool.WithContentStore(resultStore).WithFileContext(fileContext)
+	if opts.AdaptiveSpawn {
+		spawnConfig.Tune = tools.AdaptiveSpawnTuner
+	}
+	spawnConfig.StoreWrite = opts.SubagentStoreWrite
+	toolConfig, err := newToolConfig(opts, provider, sessionID)
+	if err != nil {
+		return err
 	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
 
-	availableTools := []tools.Tool{
-		readTool,
-		tools.NewWriteFileTool(defaultMaxFileSize),
-		tools.NewAppendFileTool(defaultMaxFileSize),
-		tools.NewEditFileTool(defaultMaxFileSize),
-		tools.NewShellTool(defaultTimeout),
-		tools.NewGlobTool(1000), // File discovery (paths only, no content)
-		tools.NewHTTPTool(defaultTimeout),
-		// NOTE: ripgrep intentionally excluded from RLM - use glob + DSA tools instead
+	httpTool, err := newHTTPTool(opts, toolConfig.Egress, resultStore, sessionID, fileContext)
+	if err != nil {
+		return err
 	}
 
-	// Add DSA-based ResultStore tools if store is available
-	if resultStore != nil {
-		availableTools = append(availableTools,
-			tools.NewSearchStoredTool(resultStore, sessionID, fileContext),
-			tools.NewGetLinesTool(resultStore, sessionID, fileContext),
-			tools.NewListStoredTool(resultStore, sessionID, fileContext),
-		)
+	artifacts, err := newArtifactDir(opts)
+	if err != nil {
+		return err
 	}
+	defer printArtifacts(artifacts)
+
+	// Build available tools including DSA ResultStore tools
+	// NOTE: ripgrep intentionally excluded from RLM - use glob + DSA tools instead
+	availableTools := tools.NewToolFilter(nil, []string{"ripgrep"}).
+		Apply(newRunTools(resultStore, sessionID, fileContext, artifacts, httpTool))
 
 	// Load and connect MCP servers
 	allMCPServers, err := loadMCPServers(mcpServers, mcpConfigPath, opts.Verbose)
@@ -389,22 +624,54 @@ func RLM(ctx context.Context, task string, maxDepth, timeoutSecs int, mcpServers
 
 	// Add MCP tools to available tools
 	availableTools, mcpConn.toolNames = mergeTools(availableTools, mcpConn.tools)
+	executor := tools.NewExecutor(toolConfig)
+	availableTools = withExtensionTools(ctx, opts.ExternalToolsDir, availableTools, executor)
+	availableTools, err = filterTools(toolConfig.Filter, availableTools)
+	if err != nil {
+		return err
+	}
+
+	// Cap observation size; overflow goes to ResultStore
+	observations := tools.NewObservationBudget(toolConfig.ObservationLimit()).
+		WithResultStore(resultStore, sessionID, fileContext)
 
 	// Create the spawn tool with available tools
+	control := tools.NewSpawnControl()
 	spawnTool := tools.NewSpawnAgentTool(provider, spawnConfig, toolConfig).
 		WithSubagentProvider(subagentProvider). // nil-safe, no-op if not configured
 		WithTools(availableTools).
+		WithObservationBudget(observations).
+		WithResultStore(resultStore, sessionID, fileContext).
+		WithControl(control).
 		Verbose(opts.Verbose)
 
+	// Let the user stop a runaway subtree while the run goes on
+	if opts.Verbose && stdinIsTerminal() {
+		controlCtx, stopControl := context.WithCancel(ctx)
+		defer stopControl()
+		go watchSpawnCommands(controlCtx, control, os.Stdin, os.Stdout)
+		fmt.Println("Type 'ps' to list running sub-agents, 'kill <id>' to stop one and its subtree.")
+	}
+
 	// Also create parallel spawn tool
 	parallelSpawn := tools.NewParallelSpawnTool(spawnTool)
 
+	// Code execution for symbolic recursion: spawn calls inside loops/maps
+	codeTool := tools.NewCodeExecTool(uint64(timeoutSecs)).
+		WithExecutor(executor).
+		WithHostTools(toolConfig.Filter.Apply(append([]tools.Tool{spawnTool, parallelSpawn}, availableTools...))...)
+
 	// Build root agent with spawn capabilities
-	allTools := append([]tools.Tool{spawnTool, parallelSpawn}, availableTools...)
-	toolMap := make(map[string]tools.Tool)
-	for _, t := range allTools {
-		toolMap[t.Metadata().Name] = t
+	allTools := append([]tools.Tool{spawnTool, parallelSpawn, codeTool}, availableTools...)
+
+	// Scratch notes for the root agent only; sub-agents answer and exit
+	var notebook *tools.Notebook
+	if resultStore != nil {
+		notebook = tools.NewNotebook(resultStore, sessionID)
+		allTools = append(allTools, tools.NewNotesTool(notebook))
 	}
+	allTools = append(allTools, tools.NewNextPageTool(observations))
+	allTools = toolConfig.Filter.Apply(allTools)
 
 	// Build system prompt with MCP tools if any
 	mcpToolsSection := buildMCPToolsSection(mcpConn.toolNames)
@@ -430,11 +697,15 @@ CONTENT STORAGE (stores for DSA search):
 DSA-POWERED SEARCH (requires files stored via read_file first):
 - search_stored: Search pattern across ALL stored content (SuffixArray - fast substring search)
 - get_lines: Get specific line range from stored content
+- next_page: Read the next page of a get_lines, search_stored or list_stored result too large to show at once
 - list_stored: List stored content with prefix filter (Trie)
+- pin_stored: Pin key findings or the task spec so they are kept after the run
+- notes: Jot intermediate conclusions by topic (append/read/list); your latest notes are shown at the end of this prompt
 
 SUB-AGENT DELEGATION:
 - spawn: Spawn a sub-agent for a specific task
 - parallel_spawn: Spawn multiple sub-agents in parallel
+- run_starlark: Run Starlark (Python-like, no imports) that calls spawn()/parallel_spawn()/call(tool, ...) in loops and combines the answers in code
 
 FILE MODIFICATION:
 - write_file, edit_file, append_file
@@ -459,13 +730,20 @@ OTHER:
 - ALWAYS use read_file to store before searching
 - ALWAYS use DSA tools (search_stored, get_lines) to examine content
 - DELEGATE file analysis to sub-agents for parallelism`, mcpToolsSection)
+	systemPrompt += tools.IngestedContentRule
+	systemPrompt, err = withCustomInstructions(systemPrompt, opts)
+	if err != nil {
+		return err
+	}
 
 	messages := []llm.ChatMessage{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: task},
 	}
 
-	executor := tools.NewExecutor(toolConfig)
+	// Record tool usage for `ariadne tools stats`
+	usage := tools.NewUsageTracker(uuid.New().String())
+	defer saveToolUsage(ctx, usage, "")
 
 	if len(mcpConn.toolNames) > 0 {
 		fmt.Printf("Running RLM task (max depth: %d, MCP tools: %d)...\n\n", maxDepth, len(mcpConn.toolNames))
@@ -474,159 +752,132 @@ OTHER:
 	}
 
 	// Run ReAct loop for root agent
-	for i := 0; i < opts.MaxIter; i++ {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-
-		if opts.Verbose {
-			fmt.Printf("[root:%d] Processing...\n", i)
-		}
-
-		response, err := provider.ChatWithTools(ctx, messages, convertToToolDefs(allTools))
-		metrics.LLMCalls.Add(1)
-		if err != nil {
-			return fmt.Errorf("LLM call failed: %w", err)
-		}
-
-		// No tool calls - final answer
-		if len(response.ToolCalls) == 0 {
-			fmt.Printf("%s\n", response.Content)
-			return nil
-		}
-
-		if opts.Verbose {
-			fmt.Printf("[root:%d] %s\n", i, response.Content)
-			for _, tc := range response.ToolCalls {
-				args := string(tc.Arguments)
-				if len(args) > 100 {
-					args = args[:100] + "..."
-				}
-				fmt.Printf("[root:%d] Calling: %s(%s)\n", i, tc.Name, args)
-			}
-		}
-
-		// Add assistant message
-		messages = append(messages, llm.ChatMessage{
-			Role:      "assistant",
-			Content:   response.Content,
-			ToolCalls: response.ToolCalls,
-		})
-
-		// Execute tool calls
-		for _, tc := range response.ToolCalls {
-			tool, exists := toolMap[tc.Name]
-			if !exists {
-				messages = append(messages, llm.ChatMessage{
-					Role:       "tool",
-					Content:    fmt.Sprintf("Error: tool '%s' not found", tc.Name),
-					ToolCallID: tc.ID,
-				})
-				continue
-			}
-
-			result, err := executor.Execute(ctx, tool, tc.Arguments)
-			metrics.ToolCalls.Add(1)
-			if err != nil {
-				messages = append(messages, llm.ChatMessage{
-					Role:       "tool",
-					Content:    fmt.Sprintf("Error: %v", err),
-					ToolCallID: tc.ID,
-				})
-				continue
-			}
-
-			output := result.Output
-			if output == "" {
-				output = "(empty result)"
-			}
-
-			if opts.Verbose {
-				displayOutput := output
-				if len(displayOutput) > 200 {
-					displayOutput = displayOutput[:200] + "..."
-				}
-				fmt.Printf("[root:%d] Result: %s\n", i, displayOutput)
-			}
-
-			messages = append(messages, llm.ChatMessage{
-				Role:       "tool",
-				Content:    output,
-				ToolCallID: tc.ID,
-			})
+	loop := &toolLoop{
+		name:         "root",
+		provider:     provider,
+		tools:        allTools,
+		executor:     executor,
+		usage:        usage,
+		observations: observations,
+		maxIter:      opts.MaxIter,
+		wrapUp:       toolConfig.WrapUp,
+		verbose:      opts.Verbose,
+		onLLMCall:    func() { metrics.LLMCalls.Add(1) },
+		onToolCall:   func() { metrics.ToolCalls.Add(1) },
+		progress:     newProgressLine(opts, "iter", opts.MaxIter),
+	}
+	if notebook != nil {
+		loop.systemDigest = notebook.Digest
+	}
+	subagentModel := provider.Model()
+	if subagentProvider != nil {
+		subagentModel = subagentProvider.Model()
+	}
+	if opts.Verbose {
+		hud := newRunHUD()
+		loop.onIteration = func(iteration int, root llm.TokenUsage) {
+			// Sub-agent usage; the root's is added to metrics when it ends
+			sub := metrics.Tokens()
+			total := addTokenUsage(root, sub)
+			cost := estimateCost(modelUsage{provider.Model(), root}, modelUsage{subagentModel, sub})
+			hud.print(iteration, total, cost, observations.BytesSaved())
 		}
 	}
-
-	return fmt.Errorf("reached max iterations without completing")
+	resp := loop.run(ctx, messages)
+	resp.Metadata.Environment = environment
+	resp.Metadata.MaskedPII = toolConfig.PII.Report()
+	storeUsage = recordDSAUsage(resultStore, observations, usage)
+	resp.Metadata.DSAUsage = storeUsage
+	// Priced before the root's usage joins the sub-agents' in metrics
+	var root llm.TokenUsage
+	if resp.Metadata.TokenUsage != nil {
+		root = *resp.Metadata.TokenUsage
+	}
+	cost := estimateCost(modelUsage{provider.Model(), root}, modelUsage{subagentModel, metrics.Tokens()})
+	metrics.AddTokens(0, "", resp.Metadata.TokenUsage)
+	if opts.ReportOut != "" {
+		writeReport(opts.ReportOut, rlmReport(userTask, provider.Model(), startTime, resp, metrics, control, cost, fileContext))
+	}
+	return reportLoopResponse(ctx, resp, opts)
 }
 
 // ReAct executes a task using the ReAct pattern with DSA tools for bounded context.
 // Unlike RLM, this uses a single agent without sub-agent spawning.
 func ReAct(ctx context.Context, task string, mcpServers []string, mcpConfigPath string, opts Options) error {
+	defer beginQuiet(opts)()
 	startTime := time.Now()
+	ctx, cancel := withDeadline(ctx, opts)
+	defer cancel()
 
-	provider, err := createProvider(opts.Provider)
+	provider, err := createProvider(opts.Provider, opts)
 	if err != nil {
 		return err
 	}
 
+	environment := orchestration.CaptureEnvironment(ctx, provider)
+	if opts.Verbose {
+		printEnvironment(environment)
+	}
+
 	// Create ResultStore for DSA-based storage/search
-	resultStore, cleanup := createResultStore()
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
 	if cleanup != nil {
 		defer cleanup()
 	}
 
 	// Print duration at the end
+	var storeUsage *model.DSAUsage
 	defer func() {
 		fmt.Printf("\n--- ReAct Metrics ---\n")
 		fmt.Printf("Duration: %s\n", time.Since(startTime).Round(time.Millisecond))
+		printDSAUsage(storeUsage)
 	}()
 
 	// Pre-store any files mentioned in the task
-	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore)
+	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore, storeSessionID)
 
 	// Store the user's prompt as searchable context
+	sessionID := storeSessionID
 	if resultStore != nil {
-		_, _ = resultStore.StoreContent(ctx, model.ContentKey{ContentType: "file", Path: "user_prompt"}, task)
+		_, _ = resultStore.SessionContent(sessionID).StoreContent(ctx, model.FileKey("user_prompt"), task)
 		fileContext.Add("user_prompt")
 	}
 
-	// Session ID for ResultStore
-	sessionID := "file"
-
-	// Clear stale session data from previous runs
-	if resultStore != nil {
-		_ = resultStore.DeleteSession(ctx, sessionID)
+	note, err := mountContextPack(ctx, opts, resultStore, sessionID)
+	if err != nil {
+		return err
 	}
+	task += note
 
-	toolConfig := tools.ToolConfig{MaxRetries: opts.ToolRetries}
+	toolConfig, err := newToolConfig(opts, provider, sessionID)
+	if err != nil {
+		return err
+	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
 
-	// Build available tools including DSA ResultStore tools
-	// Configure read_file to store content for DSA tools
-	readTool := tools.NewReadFileTool(defaultMaxFileSize)
-	if resultStore != nil {
-		readTool = readTool.WithContentStore(resultStore).WithFileContext(fileContext)
+	httpTool, err := newHTTPTool(opts, toolConfig.Egress, resultStore, sessionID, fileContext)
+	if err != nil {
+		return err
 	}
 
-	// All tools available for ReAct agent
-	availableTools := []tools.Tool{
-		readTool,
-		tools.NewWriteFileTool(defaultMaxFileSize),
-		tools.NewAppendFileTool(defaultMaxFileSize),
-		tools.NewEditFileTool(defaultMaxFileSize),
-		tools.NewShellTool(defaultTimeout),
-		tools.NewGlobTool(1000),
-		tools.NewHTTPTool(defaultTimeout),
-		tools.NewRipgrepTool(defaultTimeout),
+	artifacts, err := newArtifactDir(opts)
+	if err != nil {
+		return err
 	}
+	defer printArtifacts(artifacts)
 
-	// Add DSA-based ResultStore tools if store is available
+	// All tools available for ReAct agent, including DSA ResultStore tools
+	availableTools := newRunTools(resultStore, sessionID, fileContext, artifacts, httpTool)
+
+	// Scratch notes, shown back to the agent in its system prompt
+	var notebook *tools.Notebook
 	if resultStore != nil {
-		availableTools = append(availableTools,
-			tools.NewSearchStoredTool(resultStore, sessionID, fileContext),
-			tools.NewGetLinesTool(resultStore, sessionID, fileContext),
-			tools.NewListStoredTool(resultStore, sessionID, fileContext),
-		)
+		notebook = tools.NewNotebook(resultStore, sessionID)
+		availableTools = append(availableTools, tools.NewNotesTool(notebook))
 	}
 
 	// Load and connect MCP servers
@@ -639,11 +890,11 @@ func ReAct(ctx context.Context, task string, mcpServers []string, mcpConfigPath
 
 	// Add MCP tools to available tools
 	availableTools, mcpConn.toolNames = mergeTools(availableTools, mcpConn.tools)
-
-	// Build tool map
-	toolMap := make(map[string]tools.Tool)
-	for _, t := range availableTools {
-		toolMap[t.Metadata().Name] = t
+	executor := tools.NewExecutor(toolConfig)
+	availableTools = withExtensionTools(ctx, opts.ExternalToolsDir, availableTools, executor)
+	availableTools, err = filterTools(toolConfig.Filter, availableTools)
+	if err != nil {
+		return err
 	}
 
 	// Build system prompt with MCP tools if any
@@ -665,7 +916,10 @@ CONTENT STORAGE (stores for DSA search):
 DSA-POWERED SEARCH (requires files stored via read_file first):
 - search_stored: Search pattern across ALL stored content (O(m log n) SuffixArray search)
 - get_lines: Get specific line range from stored content
+- next_page: Read the next page of a get_lines, search_stored or list_stored result too large to show at once
 - list_stored: List stored content with prefix filter (O(m+k) Trie lookup)
+- pin_stored: Pin key findings or the task spec so they are kept after the run
+- notes: Jot intermediate conclusions by topic (append/read/list); your latest notes are shown at the end of this prompt
 
 FILE MODIFICATION:
 - write_file, edit_file, append_file
@@ -673,6 +927,7 @@ FILE MODIFICATION:
 OTHER:
 - execute_shell: Run shell commands
 - http_request: Make HTTP requests
+- grep_files: Regex search on disk; files with matches are stored for search_stored/get_lines
 - ripgrep: Search files on disk (fallback if DSA not applicable)%s
 
 ## RECOMMENDED WORKFLOW
@@ -687,13 +942,25 @@ OTHER:
 - read_file returns METADATA, not content - use get_lines to fetch specific sections
 - search_stored searches ALL stored files at once using SuffixArray
 - This is more efficient than ripgrep when analyzing multiple related files`, mcpToolsSection)
+	systemPrompt += tools.IngestedContentRule
+	systemPrompt, err = withCustomInstructions(systemPrompt, opts)
+	if err != nil {
+		return err
+	}
 
 	messages := []llm.ChatMessage{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: task},
 	}
 
-	executor := tools.NewExecutor(toolConfig)
+	// Record tool usage for `ariadne tools stats`
+	usage := tools.NewUsageTracker(uuid.New().String())
+	defer saveToolUsage(ctx, usage, "")
+
+	// Cap observation size; overflow goes to ResultStore
+	observations := tools.NewObservationBudget(toolConfig.ObservationLimit()).
+		WithResultStore(resultStore, sessionID, fileContext)
+	availableTools = append(availableTools, toolConfig.Filter.Apply([]tools.Tool{tools.NewNextPageTool(observations)})...)
 
 	if len(mcpConn.toolNames) > 0 {
 		fmt.Printf("Running ReAct task (MCP tools: %d)...\n\n", len(mcpConn.toolNames))
@@ -702,99 +969,58 @@ OTHER:
 	}
 
 	// Run ReAct loop
-	for i := 0; i < opts.MaxIter; i++ {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-
-		if opts.Verbose {
-			fmt.Printf("[react:%d] Processing...\n", i)
-		}
-
-		response, err := provider.ChatWithTools(ctx, messages, convertToToolDefs(availableTools))
-		if err != nil {
-			return fmt.Errorf("LLM call failed: %w", err)
-		}
-
-		// No tool calls - final answer
-		if len(response.ToolCalls) == 0 {
-			fmt.Printf("%s\n", response.Content)
-			return nil
-		}
-
-		if opts.Verbose {
-			fmt.Printf("[react:%d] %s\n", i, response.Content)
-			for _, tc := range response.ToolCalls {
-				args := string(tc.Arguments)
-				if len(args) > 100 {
-					args = args[:100] + "..."
-				}
-				fmt.Printf("[react:%d] Calling: %s(%s)\n", i, tc.Name, args)
-			}
-		}
-
-		// Add assistant message
-		messages = append(messages, llm.ChatMessage{
-			Role:      "assistant",
-			Content:   response.Content,
-			ToolCalls: response.ToolCalls,
-		})
-
-		// Execute tool calls
-		for _, tc := range response.ToolCalls {
-			tool, exists := toolMap[tc.Name]
-			if !exists {
-				messages = append(messages, llm.ChatMessage{
-					Role:       "tool",
-					Content:    fmt.Sprintf("Error: tool '%s' not found", tc.Name),
-					ToolCallID: tc.ID,
-				})
-				continue
-			}
-
-			result, err := executor.Execute(ctx, tool, tc.Arguments)
-			if err != nil {
-				messages = append(messages, llm.ChatMessage{
-					Role:       "tool",
-					Content:    fmt.Sprintf("Error: %v", err),
-					ToolCallID: tc.ID,
-				})
-				continue
-			}
-
-			output := result.Output
-			if output == "" {
-				output = "(empty result)"
-			}
-
-			if opts.Verbose {
-				displayOutput := output
-				if len(displayOutput) > 200 {
-					displayOutput = displayOutput[:200] + "..."
-				}
-				fmt.Printf("[react:%d] Result: %s\n", i, displayOutput)
-			}
-
-			messages = append(messages, llm.ChatMessage{
-				Role:       "tool",
-				Content:    output,
-				ToolCallID: tc.ID,
-			})
+	loop := &toolLoop{
+		name:         "react",
+		provider:     provider,
+		tools:        availableTools,
+		executor:     executor,
+		usage:        usage,
+		observations: observations,
+		maxIter:      opts.MaxIter,
+		wrapUp:       toolConfig.WrapUp,
+		verbose:      opts.Verbose,
+		progress:     newProgressLine(opts, "iter", opts.MaxIter),
+	}
+	if notebook != nil {
+		loop.systemDigest = notebook.Digest
+	}
+	if opts.Verbose {
+		hud := newRunHUD()
+		loop.onIteration = func(iteration int, usage llm.TokenUsage) {
+			hud.print(iteration, usage, estimateCost(modelUsage{provider.Model(), usage}), observations.BytesSaved())
 		}
 	}
-
-	return fmt.Errorf("reached max iterations without completing")
+	resp := loop.run(ctx, messages)
+	resp.Metadata.Environment = environment
+	resp.Metadata.MaskedPII = toolConfig.PII.Report()
+	storeUsage = recordDSAUsage(resultStore, observations, usage)
+	resp.Metadata.DSAUsage = storeUsage
+	return reportLoopResponse(ctx, resp, opts)
 }
 
 // ReactChat starts an interactive chat session using ReAct pattern with DSA tools.
 func ReactChat(ctx context.Context, sessionID, dbPath string, mcpServers []string, mcpConfigPath string, opts Options) error {
-	provider, err := createProvider(opts.Provider)
+	provider, err := createProvider(opts.Provider, opts)
 	if err != nil {
 		return err
 	}
 
+	if opts.Verbose {
+		printEnvironment(orchestration.CaptureEnvironment(ctx, provider))
+	}
+
+	// A resumable chat keeps its stored files with the session, so they
+	// are still there (and listed in the prompt) when it is resumed
+	if sessionID != "" && opts.StoreSession == "" {
+		opts.StoreSession = "chat-" + sessionID
+		opts.KeepStore = true
+	}
+
 	// Create ResultStore for DSA-based storage/search
-	resultStore, cleanup := createResultStore()
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
 	if cleanup != nil {
 		defer cleanup()
 	}
@@ -802,36 +1028,30 @@ func ReactChat(ctx context.Context, sessionID, dbPath string, mcpServers []strin
 	// Create file context for DSA tools
 	fileContext := tools.NewStoredFileContext()
 
-	// Session ID for ResultStore operations
-	storeSessionID := "file"
-
-	toolConfig := tools.ToolConfig{MaxRetries: opts.ToolRetries}
+	if _, err := mountContextPack(ctx, opts, resultStore, storeSessionID); err != nil {
+		return err
+	}
 
-	// Build available tools including DSA ResultStore tools
-	readTool := tools.NewReadFileTool(defaultMaxFileSize)
-	if resultStore != nil {
-		readTool = readTool.WithContentStore(resultStore).WithFileContext(fileContext)
+	toolConfig, err := newToolConfig(opts, provider, cmp.Or(sessionID, storeSessionID))
+	if err != nil {
+		return err
 	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
 
-	availableTools := []tools.Tool{
-		readTool,
-		tools.NewWriteFileTool(defaultMaxFileSize),
-		tools.NewAppendFileTool(defaultMaxFileSize),
-		tools.NewEditFileTool(defaultMaxFileSize),
-		tools.NewShellTool(defaultTimeout),
-		tools.NewGlobTool(1000),
-		tools.NewHTTPTool(defaultTimeout),
-		tools.NewRipgrepTool(defaultTimeout),
+	httpTool, err := newHTTPTool(opts, toolConfig.Egress, resultStore, storeSessionID, fileContext)
+	if err != nil {
+		return err
 	}
 
-	// Add DSA-based ResultStore tools if store is available
-	if resultStore != nil {
-		availableTools = append(availableTools,
-			tools.NewSearchStoredTool(resultStore, storeSessionID, fileContext),
-			tools.NewGetLinesTool(resultStore, storeSessionID, fileContext),
-			tools.NewListStoredTool(resultStore, storeSessionID, fileContext),
-		)
+	artifacts, err := newArtifactDir(opts)
+	if err != nil {
+		return err
 	}
+	defer printArtifacts(artifacts)
+
+	// Build available tools including DSA ResultStore tools
+	availableTools := newRunTools(resultStore, storeSessionID, fileContext, artifacts, httpTool)
 
 	// Load and connect MCP servers
 	allMCPServers, err := loadMCPServers(mcpServers, mcpConfigPath, opts.Verbose)
@@ -843,6 +1063,12 @@ func ReactChat(ctx context.Context, sessionID, dbPath string, mcpServers []strin
 
 	// Add MCP tools to available tools
 	availableTools, mcpConn.toolNames = mergeTools(availableTools, mcpConn.tools)
+	executor := tools.NewExecutor(toolConfig)
+	availableTools = withExtensionTools(ctx, opts.ExternalToolsDir, availableTools, executor)
+	availableTools, err = filterTools(toolConfig.Filter, availableTools)
+	if err != nil {
+		return err
+	}
 
 	// Build tool map
 	toolMap := make(map[string]tools.Tool)
@@ -869,7 +1095,9 @@ CONTENT STORAGE (stores for DSA search):
 DSA-POWERED SEARCH (requires files stored via read_file first):
 - search_stored: Search pattern across ALL stored content (O(m log n) SuffixArray search)
 - get_lines: Get specific line range from stored content
+- next_page: Read the next page of a get_lines, search_stored or list_stored result too large to show at once
 - list_stored: List stored content with prefix filter (O(m+k) Trie lookup)
+- pin_stored: Pin key findings or the task spec so they are kept after the run
 
 FILE MODIFICATION:
 - write_file, edit_file, append_file
@@ -877,6 +1105,7 @@ FILE MODIFICATION:
 OTHER:
 - execute_shell: Run shell commands
 - http_request: Make HTTP requests
+- grep_files: Regex search on disk; files with matches are stored for search_stored/get_lines
 - ripgrep: Search files on disk (fallback if DSA not applicable)%s
 
 ## RECOMMENDED WORKFLOW
@@ -891,6 +1120,11 @@ OTHER:
 - read_file returns METADATA, not content - use get_lines to fetch specific sections
 - search_stored searches ALL stored files at once using SuffixArray
 - This is more efficient than ripgrep when analyzing multiple related files`, mcpToolsSection)
+	systemPrompt += tools.IngestedContentRule
+	systemPrompt, err = withCustomInstructions(systemPrompt, opts)
+	if err != nil {
+		return err
+	}
 
 	// Set up conversation persistence if session provided
 	var store *storage.SqliteStorage
@@ -910,19 +1144,45 @@ OTHER:
 
 	// Load existing history
 	var history []llm.ChatMessage
+	var revision int64
 	if store != nil {
-		history, err = store.Load(ctx, session)
+		history, revision, err = store.LoadWithRevision(ctx, session, chatHistoryWindow)
 		if err != nil {
 			return fmt.Errorf("failed to load history: %w", err)
 		}
-		if len(history) > 0 {
-			fmt.Printf("Resuming session '%s' (%d messages)\n\n", session, len(history))
+		printResumedSession(ctx, store, session, len(history))
+	}
+
+	// Carry memories and stored files over from earlier runs
+	var memories storage.MemoryStorage
+	if store != nil {
+		memories = store
+		memoryTools := []tools.Tool{
+			tools.NewStoreMemoryTool(store, session).WithAgent(chatMemoryAgentID),
+			tools.NewRecallMemoryTool(store, session),
 		}
+		for _, t := range toolConfig.Filter.Apply(memoryTools) {
+			availableTools = append(availableTools, t)
+			toolMap[t.Metadata().Name] = t
+		}
+		systemPrompt += chatMemoryToolsSection + sessionContext(ctx, memories, session, resultStore, storeSessionID)
 	}
 
 	fmt.Printf("ReAct Chat with DSA tools. Type 'exit' to quit.\n\n")
 
-	executor := tools.NewExecutor(toolConfig)
+	// Record tool usage for `ariadne tools stats`
+	usage := tools.NewUsageTracker(uuid.New().String())
+	defer saveToolUsage(ctx, usage, dbPath)
+
+	// Cap observation size; overflow goes to ResultStore
+	observations := tools.NewObservationBudget(toolConfig.ObservationLimit()).
+		WithResultStore(resultStore, storeSessionID, fileContext)
+	// Runs before saveToolUsage, so the session's store usage is saved too
+	defer recordDSAUsage(resultStore, observations, usage)
+	for _, t := range toolConfig.Filter.Apply([]tools.Tool{tools.NewNextPageTool(observations)}) {
+		availableTools = append(availableTools, t)
+		toolMap[t.Metadata().Name] = t
+	}
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
@@ -942,7 +1202,7 @@ OTHER:
 		// Pre-store any files mentioned in input.
 		// We intentionally discard the returned fileContext - the main fileContext
 		// at the function level tracks all stored files across turns.
-		_, input = preStoreFilesFromPrompt(ctx, input, resultStore)
+		_, input = preStoreFilesFromPrompt(ctx, input, resultStore, storeSessionID)
 
 		// Build messages for this turn
 		messages := []llm.ChatMessage{
@@ -950,26 +1210,48 @@ OTHER:
 		}
 		messages = append(messages, history...)
 		messages = append(messages, llm.ChatMessage{Role: "user", Content: input})
+		turnStart := len(messages)
 
-		// Run ReAct loop for this turn
+		// Run ReAct loop for this turn; --timeout bounds each turn
 		var finalResponse string
+		stall := tools.NewStallDetector()
+		turnCtx, cancelTurn := withDeadline(ctx, opts)
 		for i := 0; i < opts.MaxIter; i++ {
 			if ctx.Err() != nil {
-				return ctx.Err()
+				cancelTurn()
+				return agent.CancelledError(ctx.Err())
+			}
+			if interrupted(turnCtx, messages, opts) != nil {
+				break
 			}
 
 			if opts.Verbose {
 				fmt.Printf("[react:%d] Processing...\n", i)
 			}
 
-			response, err := provider.ChatWithTools(ctx, messages, convertToToolDefs(availableTools))
+			// Near the limit, tell the model to wrap up; the note is sent
+			// with this call only, not kept in the chat history
+			remaining := opts.MaxIter - i
+			forced := toolConfig.WrapUp.ForcesAnswer(remaining)
+			callMessages := messages
+			if forced {
+				callMessages = withNote(messages, toolConfig.WrapUp.SynthesisPrompt())
+			} else if warning := toolConfig.WrapUp.Warning(remaining + 1); warning != "" {
+				callMessages = withNote(messages, warning)
+			}
+
+			response, err := llm.ChatWithTools(turnCtx, provider, callMessages, convertToToolDefs(availableTools))
 			if err != nil {
+				if ctx.Err() == nil && interrupted(turnCtx, messages, opts) != nil {
+					break
+				}
 				fmt.Fprintf(os.Stderr, "\nError: %v\n\n", err)
 				break
 			}
 
-			// No tool calls - final answer
-			if len(response.ToolCalls) == 0 {
+			// No tool calls - final answer. A synthesis step's reply is the
+			// answer either way; its tool calls are not run.
+			if len(response.ToolCalls) == 0 || forced {
 				finalResponse = response.Content
 				break
 			}
@@ -977,10 +1259,7 @@ OTHER:
 			if opts.Verbose {
 				fmt.Printf("[react:%d] %s\n", i, response.Content)
 				for _, tc := range response.ToolCalls {
-					args := string(tc.Arguments)
-					if len(args) > 100 {
-						args = args[:100] + "..."
-					}
+					args := text.Truncate(string(tc.Arguments), 100)
 					fmt.Printf("[react:%d] Calling: %s(%s)\n", i, tc.Name, args)
 				}
 			}
@@ -1004,7 +1283,11 @@ OTHER:
 					continue
 				}
 
-				result, err := executor.Execute(ctx, tool, tc.Arguments)
+				callStart := time.Now()
+				result, err := executor.Execute(tools.WithAgent(turnCtx, "react-chat"), tool, tc.Arguments)
+				if n := usage.Record(tc.Name, tc.Arguments, result, err, time.Since(callStart)); n >= tools.RepeatWarnThreshold {
+					fmt.Fprintln(os.Stderr, tools.RepeatWarning(tc.Name, n))
+				}
 				if err != nil {
 					messages = append(messages, llm.ChatMessage{
 						Role:       "tool",
@@ -1014,16 +1297,10 @@ OTHER:
 					continue
 				}
 
-				output := result.Output
-				if output == "" {
-					output = "(empty result)"
-				}
+				output := observations.Apply(turnCtx, tc.Name, toolObservation(result))
 
 				if opts.Verbose {
-					displayOutput := output
-					if len(displayOutput) > 200 {
-						displayOutput = displayOutput[:200] + "..."
-					}
+					displayOutput := text.Truncate(output, 200)
 					fmt.Printf("[react:%d] Result: %s\n", i, displayOutput)
 				}
 
@@ -1033,21 +1310,36 @@ OTHER:
 					ToolCallID: tc.ID,
 				})
 			}
+
+			// Stop or nudge the agent if it keeps repeating itself
+			if err := stall.CheckToolRound(response.ToolCalls, messages); err != nil {
+				fmt.Fprintf(os.Stderr, "\nError: %v\n\n", err)
+				break
+			}
 		}
+		cancelTurn()
 
-		if finalResponse != "" {
+		// An unfinished turn keeps a summary of its tool calls in place of
+		// the answer, so the next turn can continue from it
+		answer := finalResponse
+		if answer != "" {
 			fmt.Printf("\n%s\n\n", finalResponse)
-
+			rememberTurn(ctx, memories, session, input, finalResponse, messages[turnStart:])
+		} else {
+			answer = partialTurn(messages[turnStart:])
+		}
+		if answer != "" {
 			// Add to history (just user input and final response)
-			history = append(history,
-				llm.ChatMessage{Role: "user", Content: input},
-				llm.ChatMessage{Role: "assistant", Content: finalResponse},
-			)
+			turn := []llm.ChatMessage{
+				{Role: "user", Content: input},
+				{Role: "assistant", Content: answer},
+			}
+			history = append(history, turn...)
 
 			// Save to storage
 			if store != nil {
-				if err := store.Save(ctx, session, history); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to save history: %v\n", err)
+				if err := appendChatTurn(ctx, store, session, turn, &revision); err != nil {
+					return err
 				}
 			}
 		}
@@ -1058,53 +1350,87 @@ OTHER:
 
 // ReactOrchestrate executes a complex task across multiple agents using ReAct pattern with DSA tools.
 func ReactOrchestrate(ctx context.Context, task string, agentNames []string, sessionID, dbPath string, mcpServers []string, mcpConfigPath string, opts Options) error {
-	provider, err := createProvider(opts.Provider)
+	defer beginQuiet(opts)()
+	ctx, cancel := withDeadline(ctx, opts)
+	defer cancel()
+
+	provider, err := createProvider(opts.Provider, opts)
 	if err != nil {
 		return err
 	}
 
-	toolConfig := tools.ToolConfig{MaxRetries: opts.ToolRetries}
-	llmClient := llm.NewClient(provider)
+	environment := orchestration.CaptureEnvironment(ctx, provider)
+	if opts.Verbose {
+		printEnvironment(environment)
+	}
+
+	toolConfig, err := newToolConfig(opts, provider, sessionID)
+	if err != nil {
+		return err
+	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
+	if err := validateToolFilter(toolConfig.Filter, nil); err != nil {
+		return err
+	}
+	llmClient, err := supervisorClient(provider, opts)
+	if err != nil {
+		return err
+	}
 
 	// Create ResultStore for DSA-based storage/search
-	resultStore, cleanup := createResultStore()
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
 	if cleanup != nil {
 		defer cleanup()
 	}
 
 	// Pre-store any files mentioned in the task
-	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore)
+	userTask := task
+	fileContext, task := preStoreFilesFromPrompt(ctx, task, resultStore, storeSessionID)
 
 	// Create agents with shared file context
 	var agents []*agent.Agent
 	if len(agentNames) > 0 {
 		for _, name := range agentNames {
-			a, err := CreateAgent(name, "", provider, toolConfig, resultStore, fileContext)
+			a, err := CreateAgent(name, "", provider, toolConfig, resultStore, storeSessionID, fileContext)
 			if err != nil {
 				return fmt.Errorf("failed to create agent %s: %w", name, err)
 			}
 			agents = append(agents, a)
 		}
 	} else {
-		agents = CreateDefaultAgents(provider, toolConfig, resultStore, fileContext)
+		agents = CreateDefaultAgents(provider, toolConfig, resultStore, storeSessionID, fileContext)
+	}
+	if err := attachExamples(agents, opts); err != nil {
+		return err
 	}
 
-	settings, err := config.New(opts.Provider)
+	agentSettings, err := config.Agent()
 	if err != nil {
 		return err
 	}
 
 	supervisorConfig := orchestration.SupervisorConfig{
-		MaxSubGoals:          settings.Agent.MaxSubGoals,
-		MaxIterations:        settings.Agent.MaxIterations,
+		MaxSubGoals:          agentSettings.MaxSubGoals,
+		MaxIterations:        agentSettings.MaxIterations,
 		LargeResultThreshold: 1024, // 1KB threshold
+		AgentBudget:          agentBudget(agentSettings),
+		ParallelSubGoals:     opts.ParallelSubGoals,
 	}
 
 	supervisor := orchestration.NewSupervisor(agents, llmClient, supervisorConfig)
+	supervisor, err = withVerifier(supervisor, opts)
+	if err != nil {
+		return err
+	}
+	supervisor = withStatusHUD(supervisor, opts, provider.Model())
 
 	// Also give ResultStore to supervisor for storing large agent results
 	if resultStore != nil {
-		supervisor = supervisor.WithResultStore(resultStore)
+		supervisor = supervisor.WithResultStore(resultStore).WithResultSession(storeSessionID)
 	}
 
 	if sessionID != "" {
@@ -1123,14 +1449,24 @@ func ReactOrchestrate(ctx context.Context, task string, agentNames []string, ses
 		supervisor = supervisor.Verbose(true)
 	}
 
-	response := supervisor.Orchestrate(ctx, task, opts.MaxIter)
+	started := time.Now()
+	progress := newProgressLine(opts, "step", opts.MaxIter)
+	response := withProgressLine(supervisor, progress).Orchestrate(ctx, task, opts.MaxIter)
+	progress.finish()
+	if response.Metadata != nil {
+		response.Metadata.Environment = environment
+		response.Metadata.MaskedPII = toolConfig.PII.Report()
+	}
+	if opts.ReportOut != "" {
+		writeReport(opts.ReportOut, orchestrationReport(userTask, provider.Model(), started, response, fileContext))
+	}
 
 	switch response.Type {
 	case orchestration.ResponseSuccess:
 		if opts.Verbose {
 			printOrchestrationSteps(response.Steps)
 		}
-		fmt.Printf("%s\n\n", response.Result)
+		fmt.Fprintf(answerOut, "%s\n\n", formatAnswer(response.Result, opts))
 		fmt.Printf("Completed in %d steps\n", len(response.Steps))
 		printTokenStats(response.Metadata)
 		return nil
@@ -1138,16 +1474,21 @@ func ReactOrchestrate(ctx context.Context, task string, agentNames []string, ses
 		if opts.Verbose {
 			printOrchestrationSteps(response.Steps)
 		}
+		if errors.Is(response.Err, agent.ErrCancelled) {
+			printInterrupted(ctx.Err(), opts, response.PartialResult, orchestrationPartial(response))
+			return response.Err
+		}
 		fmt.Fprintf(os.Stderr, "Failed: %s\n", response.Error)
 		fmt.Fprintf(os.Stderr, "Completed %d steps before failure\n", len(response.Steps))
-		return fmt.Errorf("orchestration failed: %s", response.Error)
+		return fmt.Errorf("orchestration failed: %w", response.AsError())
 	case orchestration.ResponseTimeout:
 		if opts.Verbose {
 			printOrchestrationSteps(response.Steps)
 		}
 		fmt.Printf("Timeout. Partial: %s\n", response.PartialResult)
 		fmt.Printf("Completed %d steps\n", len(response.Steps))
-		return fmt.Errorf("orchestration timed out")
+		printNextSteps(response.CompletionStatus)
+		return fmt.Errorf("orchestration timed out: %w", response.AsError())
 	default:
 		return fmt.Errorf("unknown response type: %v", response.Type)
 	}
@@ -1188,7 +1529,7 @@ func convertToToolDefs(tools []tools.Tool) []llm.ToolDefinition {
 }
 
 // ListTools lists all available tools.
-func ListTools(verbose bool) {
+func ListTools(opts Options, verbose bool) {
 	registry := tools.NewRegistry()
 
 	// Register default tools (errors ignored - no duplicates in this list)
@@ -1196,10 +1537,27 @@ func ListTools(verbose bool) {
 	_ = registry.Register(tools.NewWriteFileTool(defaultMaxFileSize))
 	_ = registry.Register(tools.NewAppendFileTool(defaultMaxFileSize))
 	_ = registry.Register(tools.NewEditFileTool(defaultMaxFileSize))
+	_ = registry.Register(tools.NewFormatTool(defaultTimeout))
+	_ = registry.Register(tools.NewStatFileTool())
+	_ = registry.Register(tools.NewTailLogTool(0))
+	_ = registry.Register(tools.NewLogHistogramTool())
 	_ = registry.Register(tools.NewShellTool(defaultTimeout))
+	_ = registry.Register(tools.NewBuildTool(defaultBuildTimeout))
+	_ = registry.Register(tools.NewLintTool(defaultBuildTimeout))
 	_ = registry.Register(tools.NewHTTPTool(defaultTimeout))
+	_ = registry.Register(tools.NewGrepTool(defaultMaxFileSize))
 	_ = registry.Register(tools.NewRipgrepTool(defaultTimeout))
 
+	// User-defined tools from opts.ExternalToolsDir and .ariadne/tools
+	var base []tools.Tool
+	for _, name := range registry.Names() {
+		tool, _ := registry.Get(name)
+		base = append(base, tool)
+	}
+	for _, tool := range withExtensionTools(context.Background(), opts.ExternalToolsDir, base, nil)[len(base):] {
+		_ = registry.Register(tool)
+	}
+
 	fmt.Println("Available tools:")
 	fmt.Println()
 
@@ -1226,6 +1584,303 @@ func ListTools(verbose bool) {
 // defaultDBPath is the unified database path for all storage.
 const defaultDBPath = ".ariadne/ariadne.db"
 
+// newToolConfig returns the tool configuration for a run, with the tool
+// filter and egress policy from opts, an injection guard when opts enables
+// screening or call blocking, and an audit log for session when opts
+// enables auditing. Screening uses provider as the classifier. Callers
+// close the audit log when the run ends.
+func newToolConfig(opts Options, provider llm.Provider, session string) (tools.ToolConfig, error) {
+	config := tools.ToolConfig{
+		MaxRetries:          opts.ToolRetries,
+		MaxObservationBytes: opts.MaxObservationBytes,
+		Filter:              tools.NewToolFilter(opts.Tools, opts.DenyTools),
+		WrapUp:              opts.WrapUp,
+	}
+	if opts.ScreenWebContent || opts.BlockInjectedCalls {
+		var classifier tools.InjectionClassifier
+		if opts.ScreenWebContent {
+			classifier = tools.NewLLMInjectionClassifier(provider)
+		}
+		config.Injection = tools.NewInjectionGuard(classifier, opts.BlockInjectedCalls)
+	}
+	if opts.EgressPolicyPath != "" {
+		policy, err := tools.LoadEgressPolicy(opts.EgressPolicyPath)
+		if err != nil {
+			return tools.ToolConfig{}, err
+		}
+		config.Egress = policy
+	}
+	if opts.MaskPII || len(opts.PIIPatterns) > 0 {
+		scanner, err := tools.NewPIIScanner(opts.PIIPatterns)
+		if err != nil {
+			return tools.ToolConfig{}, err
+		}
+		config.PII = scanner
+	}
+	if opts.ShowDiffs || opts.Verbose {
+		config.FileChanges = printFileChange
+	}
+	if opts.Audit {
+		store, err := storage.OpenSqlite(defaultDBPath)
+		if err != nil {
+			return tools.ToolConfig{}, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		config.Audit = tools.NewAuditLogger(store, session, uuid.New().String())
+	}
+	return config, nil
+}
+
+// withCustomInstructions prepends opts.SystemPrompt (or the contents of
+// opts.SystemPromptFile) to a built-in system prompt.
+func withCustomInstructions(systemPrompt string, opts Options) (string, error) {
+	custom := opts.SystemPrompt
+	if opts.SystemPromptFile != "" {
+		data, err := os.ReadFile(opts.SystemPromptFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read system prompt: %w", err)
+		}
+		custom = string(data)
+	}
+	custom = strings.TrimSpace(custom)
+	if custom == "" {
+		return systemPrompt, nil
+	}
+	return "## Custom Instructions\n\n" + custom + "\n\nFollow these instructions throughout, together with the workflow below.\n\n" + systemPrompt, nil
+}
+
+// printMaskedPII reports how much PII a run masked, if any.
+func printMaskedPII(scanner *tools.PIIScanner) {
+	if report := scanner.Report(); report != nil {
+		fmt.Printf("\nMasked PII in tool results: %s\n", tools.FormatPIIReport(report))
+	}
+}
+
+// newRunTools returns the file, shell, search and web tools of an agent
+// run. read_file and grep_files store what they read in resultStore, if
+// there is one, and the tools that search it are added.
+func newRunTools(resultStore *storage.ResultStore, sessionID string, fileContext *tools.StoredFileContext, artifacts *tools.ArtifactDir, httpTool *tools.HTTPTool) []tools.Tool {
+	readTool := tools.NewReadFileTool(defaultMaxFileSize).WithArtifactDir(artifacts)
+	grepTool := tools.NewGrepTool(defaultMaxFileSize)
+	if resultStore != nil {
+		readTool = readTool.WithContentStore(resultStore.SessionContent(sessionID)).WithFileContext(fileContext)
+		grepTool = grepTool.WithContentStore(resultStore.SessionContent(sessionID)).WithFileContext(fileContext)
+	}
+
+	edits := tools.NewEditTracker()
+	runTools := []tools.Tool{
+		readTool,
+		tools.NewWriteFileTool(defaultMaxFileSize).WithArtifactDir(artifacts).WithEditTracker(edits),
+		tools.NewAppendFileTool(defaultMaxFileSize).WithArtifactDir(artifacts).WithEditTracker(edits),
+		tools.NewEditFileTool(defaultMaxFileSize).WithArtifactDir(artifacts).WithEditTracker(edits),
+		tools.NewFormatTool(defaultTimeout).WithArtifactDir(artifacts).WithEditTracker(edits),
+		tools.NewStatFileTool(),
+		tools.NewShellTool(defaultTimeout),
+		tools.NewBuildTool(defaultBuildTimeout),
+		tools.NewLintTool(defaultBuildTimeout),
+		tools.NewGlobTool(1000), // File discovery (paths only, no content)
+		tools.NewTailLogTool(0).WithResultStore(resultStore, sessionID, fileContext),
+		tools.NewLogHistogramTool().WithResultStore(resultStore, sessionID, fileContext),
+		httpTool,
+		grepTool, // Content search without ripgrep; stores files with matches
+		tools.NewRipgrepTool(defaultTimeout),
+	}
+	if resultStore != nil {
+		runTools = append(runTools, newStoreTools(resultStore, sessionID, fileContext)...)
+	}
+	return runTools
+}
+
+// newStoreTools returns the DSA-based tools that search, page through and
+// pin the content stored in resultStore.
+func newStoreTools(resultStore *storage.ResultStore, sessionID string, fileContext *tools.StoredFileContext) []tools.Tool {
+	return []tools.Tool{
+		tools.NewSearchStoredTool(resultStore, sessionID, fileContext),
+		tools.NewGetLinesTool(resultStore, sessionID, fileContext),
+		tools.NewListStoredTool(resultStore, sessionID, fileContext),
+		tools.NewPinStoredTool(resultStore, sessionID),
+		tools.NewDepGraphTool(resultStore, sessionID),
+	}
+}
+
+// knownToolNames returns the names of the tools a run can offer, from the
+// constructors the runs use: the run tools, every built-in agent, the
+// editor agent, the catalog custom agents draw on, and the tools runs add
+// around them. The instances are not configured to run.
+func knownToolNames() []string {
+	known := append(newRunTools(nil, "", nil, nil, tools.NewHTTPTool(defaultTimeout)), newStoreTools(nil, "", nil)...)
+	for _, agentType := range []AgentType{AgentGeneral, AgentFile, AgentShell, AgentWeb} {
+		config, _ := agentConfig(string(agentType), "", nil, "", nil) // Never fails for built-in agents
+		known = append(known, config.Tools...)
+	}
+	known = append(known, bridgeAgent(nil, "", nil).Tools...)
+
+	spawn := tools.NewSpawnAgentTool(nil, tools.SpawnConfig{}, tools.ToolConfig{})
+	known = append(known,
+		spawn,
+		tools.NewParallelSpawnTool(spawn),
+		tools.NewCodeExecTool(defaultTimeout),
+		tools.NewNotesTool(nil),
+		tools.NewNextPageTool(nil),
+		tools.NewStoreMemoryTool(nil, ""),
+		tools.NewRecallMemoryTool(nil, ""),
+	)
+
+	names := agent.DefaultToolCatalog().Names()
+	for _, tool := range known {
+		names = append(names, tool.Metadata().Name)
+	}
+	return names
+}
+
+// validateToolFilter returns an error if filter names a tool that no run
+// offers and that is not one of runTools (MCP and extension tools).
+func validateToolFilter(filter *tools.ToolFilter, runTools []tools.Tool) error {
+	known := knownToolNames()
+	for _, tool := range runTools {
+		known = append(known, tool.Metadata().Name)
+	}
+	if err := filter.Validate(known); err != nil {
+		return fmt.Errorf("--tools/--deny-tools: %w", err)
+	}
+	return nil
+}
+
+// filterTools validates filter against runTools and returns the run tools
+// it allows.
+func filterTools(filter *tools.ToolFilter, runTools []tools.Tool) ([]tools.Tool, error) {
+	if err := validateToolFilter(filter, runTools); err != nil {
+		return nil, err
+	}
+	return filter.Apply(runTools), nil
+}
+
+// newHTTPTool creates the http_request tool with auth profiles, retries, and
+// large-response storage configured from opts, under the egress policy.
+func newHTTPTool(opts Options, egress *tools.EgressPolicy, resultStore *storage.ResultStore, sessionID string, fileContext *tools.StoredFileContext) (*tools.HTTPTool, error) {
+	httpTool := tools.NewHTTPTool(defaultTimeout).WithRetries(opts.HTTPRetries).WithEgressPolicy(egress)
+	if opts.HTTPProfilesPath != "" {
+		profiles, err := tools.LoadHTTPProfiles(opts.HTTPProfilesPath)
+		if err != nil {
+			return nil, err
+		}
+		httpTool = httpTool.WithAuthProfiles(profiles)
+	}
+	if resultStore != nil {
+		httpTool = httpTool.WithResultStore(resultStore, sessionID, fileContext, 0)
+	}
+	return httpTool, nil
+}
+
+// mountContextPack mounts opts.ContextPack into the run's session and returns
+// a note for the task telling the agent the content is available.
+func mountContextPack(ctx context.Context, opts Options, resultStore *storage.ResultStore, sessionID string) (string, error) {
+	if opts.ContextPack == "" {
+		return "", nil
+	}
+	if resultStore == nil {
+		return "", fmt.Errorf("cannot mount context pack %q: result store unavailable", opts.ContextPack)
+	}
+	n, err := tools.MountContextPack(ctx, resultStore, opts.ContextPack, sessionID)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Mounted context pack %q (%d files)\n", opts.ContextPack, n)
+	return fmt.Sprintf("\n\n[Context pack %q is mounted: %d pre-ingested files are available via list_stored, search_stored and get_lines.]", opts.ContextPack, n), nil
+}
+
+// ContextCreate ingests paths into a named context pack in the default database.
+func ContextCreate(ctx context.Context, name string, paths []string) error {
+	db, err := storage.OpenSqlite(defaultDBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	store, err := storage.NewResultStore(db)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("failed to create result store: %w", err)
+	}
+	defer store.Close()
+
+	summary, err := tools.CreateContextPack(ctx, store, name, paths, defaultMaxFileSize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Context pack %q: %s (%d bytes written)\n", summary.Name, summary.IndexSummary, summary.Bytes)
+	fmt.Printf("Use with: ariadne --context %s react-run \"...\"\n", summary.Name)
+	return nil
+}
+
+// newArtifactDir creates this run's artifact directory when opts.Artifacts is set.
+// Returns nil otherwise, which leaves write tools writing to the working tree.
+func newArtifactDir(opts Options) (*tools.ArtifactDir, error) {
+	if !opts.Artifacts {
+		return nil, nil
+	}
+	runID := time.Now().Format("20060102-150405") + "-" + uuid.New().String()[:8]
+	dir, err := tools.NewArtifactDir(tools.DefaultArtifactsRoot, runID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Verbose {
+		fmt.Printf("Artifacts: %s\n", dir.Dir())
+	}
+	return dir, nil
+}
+
+// printArtifacts lists the files a run wrote to its artifact directory.
+func printArtifacts(dir *tools.ArtifactDir) {
+	if dir == nil {
+		return
+	}
+	artifacts, err := dir.List()
+	if err != nil || len(artifacts) == 0 {
+		return
+	}
+	fmt.Printf("\n--- Artifacts (run %s) ---\n", dir.RunID())
+	for _, a := range artifacts {
+		fmt.Printf("  %s (%d bytes)\n", a.Path, a.Size)
+	}
+	fmt.Printf("Retrieve with: ariadne artifacts get %s <path>\n", dir.RunID())
+}
+
+// ArtifactsList prints artifact runs under root, or one run's files when runID is set.
+func ArtifactsList(root, runID string) error {
+	if runID != "" {
+		artifacts, err := tools.ListRunArtifacts(root, runID)
+		if err != nil {
+			return err
+		}
+		for _, a := range artifacts {
+			fmt.Printf("%-50s %10d  %s\n", a.Path, a.Size, a.ModTime.Format(time.RFC3339))
+		}
+		return nil
+	}
+
+	runs, err := tools.ListArtifactRuns(root)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("No artifacts recorded yet.")
+		return nil
+	}
+	fmt.Printf("%-30s %6s %10s  %s\n", "RUN", "FILES", "BYTES", "MODIFIED")
+	for _, run := range runs {
+		fmt.Printf("%-30s %6d %10d  %s\n", run.RunID, run.Files, run.Bytes, run.ModTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ArtifactGet writes one artifact's content to stdout.
+func ArtifactGet(root, runID, path string) error {
+	content, err := tools.ReadArtifact(root, runID, path)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(content)
+	return err
+}
+
 // loadMCPServers loads MCP server commands from config and merges with explicit list.
 func loadMCPServers(mcpServers []string, mcpConfigPath string, verbose bool) ([]string, error) {
 	allServers := mcpServers
@@ -1303,6 +1958,43 @@ func buildMCPToolsSection(toolNames []string) string {
 	return fmt.Sprintf("\n\nMCP Tools (from connected servers):\n- %s", strings.Join(toolNames, "\n- "))
 }
 
+// withExtensionTools appends user-defined tools: executables in
+// externalDir, if set, then Starlark tools in .ariadne/tools, which may call
+// any tool before them. Executables are only run when asked for, since a
+// checkout's tools.d is as untrusted as the rest of it. Extensions that fail
+// to load or reuse an existing tool name are skipped with a warning.
+func withExtensionTools(ctx context.Context, externalDir string, available []tools.Tool, executor *tools.Executor) []tools.Tool {
+	var external []*tools.ExternalTool
+	if externalDir != "" {
+		var err error
+		external, err = tools.LoadExternalTools(ctx, externalDir, defaultTimeout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	names := make(map[string]bool)
+	for _, t := range available {
+		names[t.Metadata().Name] = true
+	}
+	for _, t := range external {
+		if names[t.Metadata().Name] {
+			fmt.Fprintf(os.Stderr, "Warning: skipping external tool %s: tool %q already exists\n", t.Path(), t.Metadata().Name)
+			continue
+		}
+		names[t.Metadata().Name] = true
+		available = append(available, t)
+	}
+
+	scripts, err := tools.LoadScriptTools(tools.DefaultScriptToolsDir, available)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, script := range scripts {
+		available = append(available, script.WithExecutor(executor))
+	}
+	return available
+}
+
 // mergeTools combines base tools with MCP tools, skipping MCP tools that duplicate base tool names.
 // This prevents "Duplicate function declaration" errors from LLM providers.
 // Returns the merged tools and the names of MCP tools that were actually added.
@@ -1331,32 +2023,198 @@ func mergeTools(baseTools []tools.Tool, mcpTools []tools.Tool) ([]tools.Tool, []
 	return result, addedNames
 }
 
+// saveToolUsage persists a run's tool usage records to the run's database
+// (dbPath, or the default database if empty). Best-effort: failures are
+// ignored so they never mask the run's own result.
+func saveToolUsage(ctx context.Context, usage *tools.UsageTracker, dbPath string) {
+	db, err := storage.OpenSqlite(cmp.Or(dbPath, defaultDBPath))
+	if err != nil {
+		return
+	}
+	defer db.Close()
+	_ = usage.Save(context.WithoutCancel(ctx), db)
+}
+
+// recordDSAUsage sets a run's result store usage on its tracker, which
+// saves it with the tool calls, and returns it (nil without a store).
+func recordDSAUsage(store *storage.ResultStore, observations *tools.ObservationBudget, usage *tools.UsageTracker) *model.DSAUsage {
+	if store == nil {
+		return nil
+	}
+	dsa := store.Usage()
+	dsa.BytesSaved = observations.BytesSaved()
+	usage.SetDSAUsage(dsa)
+	return &dsa
+}
+
+// printDSAUsage reports what a run did with its result store, if it had one.
+func printDSAUsage(dsa *model.DSAUsage) {
+	if dsa == nil {
+		return
+	}
+	fmt.Printf("Result store: %d stored (%s indexed), %d searches, %d get_lines calls, %d cache hits, %s kept out of context\n",
+		dsa.FilesStored, formatBytes(dsa.BytesIndexed), dsa.Searches, dsa.GetLinesCalls, dsa.CacheHits, formatBytes(dsa.BytesSaved))
+}
+
+// chatHistoryWindow is how many stored messages a resumed chat loads.
+const chatHistoryWindow = 200
+
+// printResumedSession reports how much of a resumed session was loaded.
+func printResumedSession(ctx context.Context, store *storage.SqliteStorage, session string, loaded int) {
+	if loaded == 0 {
+		return
+	}
+	total, err := store.MessageCount(ctx, session)
+	if err != nil || total <= loaded {
+		fmt.Printf("Resuming session '%s' (%d messages)\n\n", session, loaded)
+		return
+	}
+	fmt.Printf("Resuming session '%s' (last %d of %d messages)\n\n", session, loaded, total)
+}
+
+// appendChatTurn appends a chat turn to the session unless another process
+// saved it since it was loaded, in which case the chat must stop: its
+// history no longer matches what is stored.
+func appendChatTurn(ctx context.Context, store *storage.SqliteStorage, session string, turn []llm.ChatMessage, revision *int64) error {
+	next, err := store.AppendMessagesAtRevision(ctx, session, turn, *revision)
+	if errors.Is(err, storage.ErrSessionConflict) {
+		return fmt.Errorf("session '%s' was changed by another ariadne process; this turn was not saved, restart to continue from the latest history: %w", session, err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save history: %v\n", err)
+		return nil
+	}
+	*revision = next
+	return nil
+}
+
+// ToolStats prints aggregate tool usage recorded by previous runs.
+func ToolStats(ctx context.Context, dbPath string) error {
+	if dbPath == "" {
+		dbPath = defaultDBPath
+	}
+	db, err := storage.OpenSqlite(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	stats, err := db.ToolUsageStats(ctx)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Println("No tool usage recorded yet.")
+		return nil
+	}
+
+	var totalOutput int64
+	for _, st := range stats {
+		totalOutput += st.TotalOutputBytes
+	}
+
+	fmt.Printf("%-20s %6s %6s %8s %10s %8s %8s %8s\n",
+		"TOOL", "RUNS", "CALLS", "FAILED", "AVG OUT", "AVG MS", "REPEATS", "BUDGET")
+	for _, st := range stats {
+		share := 0.0
+		if totalOutput > 0 {
+			share = float64(st.TotalOutputBytes) * 100 / float64(totalOutput)
+		}
+		fmt.Printf("%-20s %6d %6d %7.0f%% %10d %8d %8d %7.1f%%\n",
+			text.TruncateRunes(st.ToolName, 20), st.Runs, st.Calls, st.FailureRate()*100,
+			st.AvgOutputSize(), st.AvgDurationMs(), st.RepeatedCalls, share)
+	}
+
+	for _, st := range stats {
+		if st.RepeatedCalls > 0 && st.RepeatedCalls*2 >= st.Calls {
+			fmt.Printf("\nWarning: %d of %d '%s' calls repeated identical arguments within a run (possible loops)\n",
+				st.RepeatedCalls, st.Calls, st.ToolName)
+		}
+	}
+
+	totals, err := db.DSAUsageTotals(ctx)
+	if err != nil {
+		return err
+	}
+	if totals.Runs > 0 {
+		fmt.Printf("\nResult store over %d runs: %d stored (%s indexed), %d searches, %d get_lines calls, %d cache hits, %s kept out of context\n",
+			totals.Runs, totals.FilesStored, formatBytes(totals.BytesIndexed), totals.Searches,
+			totals.GetLinesCalls, totals.CacheHits, formatBytes(totals.BytesSaved))
+	}
+	return nil
+}
+
 // createResultStore creates a ResultStore for RLM pattern.
 // Returns the store and a cleanup function (may be nil if creation fails).
-func createResultStore() (*storage.ResultStore, func()) {
+// createResultStore opens the result store and picks the run's session in
+// it. The returned cleanup deletes that session unless opts.KeepStore is set.
+func createResultStore(ctx context.Context, opts Options) (*storage.ResultStore, string, func(), error) {
+	sessionID := opts.StoreSession
+	if sessionID == "" {
+		sessionID = "run-" + time.Now().Format("20060102-150405") + "-" + uuid.New().String()[:8]
+	}
+
 	// Open unified SQLite storage for ContentStorage
 	db, err := storage.OpenSqlite(defaultDBPath)
 	if err != nil {
+		if errors.Is(err, storage.ErrSchemaVersion) {
+			// Running without the store would hide the problem; refuse instead
+			return nil, "", nil, fmt.Errorf("%s: %w", defaultDBPath, err)
+		}
 		fmt.Fprintf(os.Stderr, "Warning: RLM disabled, failed to open database: %v\n", err)
-		return nil, nil
+		return nil, sessionID, nil, nil
 	}
 
 	store, err := storage.NewResultStore(db)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: RLM disabled, failed to create store: %v\n", err)
 		db.Close()
-		return nil, nil
+		return nil, sessionID, nil, nil
+	}
+	if err := withSummarizer(store, opts); err != nil {
+		_ = store.Close()
+		return nil, "", nil, err
 	}
 
-	return store, func() {
+	return store, sessionID, func() {
+		if opts.KeepStore {
+			fmt.Fprintf(os.Stderr, "Stored content kept in session '%s' (inspect with --store-session %s)\n", sessionID, sessionID)
+		} else {
+			cleanupCtx := context.WithoutCancel(ctx)
+			_ = store.DeleteSession(cleanupCtx, sessionID)
+			if pinned, _ := store.List(cleanupCtx, sessionID, storage.QueryOptions{}); len(pinned) > 0 {
+				fmt.Fprintf(os.Stderr, "%d pinned results kept in session '%s' (inspect with --store-session %s)\n", len(pinned), sessionID, sessionID)
+			}
+		}
 		_ = store.Close() // Best-effort cleanup
 		_ = db.Close()
+	}, nil
+}
+
+// withSummarizer sets the store's summarizer from opts.Summarizer. The
+// LLM summarizer uses the run's provider.
+func withSummarizer(store *storage.ResultStore, opts Options) error {
+	if opts.Summarizer == "" {
+		return nil
 	}
+	var provider llm.Provider
+	if opts.Summarizer == storage.SummarizerLLM {
+		var err error
+		if provider, err = createProvider(opts.Provider, opts); err != nil {
+			return err
+		}
+	}
+	summarizer, err := storage.NewSummarizer(opts.Summarizer, provider)
+	if err != nil {
+		return err
+	}
+	store.WithSummarizer(summarizer)
+	return nil
 }
 
 // preStoreFilesFromPrompt detects file paths in the prompt and pre-stores them.
 // Returns the file context with stored files and a modified prompt with metadata.
-func preStoreFilesFromPrompt(ctx context.Context, prompt string, store *storage.ResultStore) (*tools.StoredFileContext, string) {
+func preStoreFilesFromPrompt(ctx context.Context, prompt string, store *storage.ResultStore, sessionID string) (*tools.StoredFileContext, string) {
 	fileContext := tools.NewStoredFileContext()
 	if store == nil {
 		return fileContext, prompt
@@ -1368,27 +2226,32 @@ func preStoreFilesFromPrompt(ctx context.Context, prompt string, store *storage.
 		return fileContext, prompt
 	}
 
-	var storedInfo []string
+	var reqs []storage.StoreRequest
 	for _, path := range paths {
 		// Check if file exists and read it
 		content, err := os.ReadFile(path)
 		if err != nil {
 			continue // Skip files that can't be read
 		}
+		reqs = append(reqs, storage.StoreRequest{
+			Key:     storage.ResultKey{SessionID: sessionID, Key: path},
+			Content: string(content),
+			Options: storage.DefaultStoreOptions(),
+		})
+	}
 
-		// Store in ResultStore
-		key := storage.ResultKey{
-			SessionID: "file",
-			Key:       path,
-		}
-		meta, err := store.Store(ctx, key, string(content), storage.DefaultStoreOptions())
-		if err != nil {
-			continue
-		}
+	// Store in ResultStore in one write
+	metas, err := store.StoreBatch(ctx, reqs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to pre-store files from prompt: %v\n", err)
+		return fileContext, prompt
+	}
 
+	var storedInfo []string
+	for _, meta := range metas {
 		// Track in context
-		fileContext.Add(path)
-		storedInfo = append(storedInfo, fmt.Sprintf("- %s (%d lines, %d bytes)", path, meta.LineCount, meta.ByteSize))
+		fileContext.Add(meta.Key.Key)
+		storedInfo = append(storedInfo, fmt.Sprintf("- %s (%d lines, %d bytes)", meta.Key.Key, meta.LineCount, meta.ByteSize))
 	}
 
 	// Add context info to prompt if files were stored
@@ -1418,9 +2281,45 @@ func extractFilePaths(text string) []string {
 	return paths
 }
 
-func createProvider(providerName string) (llm.Provider, error) {
+// WithProviderDefaults fills in the providers not given on the command line
+// from config for pattern (config.PatternReact or config.PatternRLM), so a
+// team's standard model split needs no flags.
+func (o Options) WithProviderDefaults(pattern string) Options {
+	if o.Provider == "" {
+		o.Provider = config.DefaultProvider(pattern)
+	}
+	if pattern == config.PatternRLM && o.SubagentProvider == "" {
+		o.SubagentProvider = config.DefaultProvider(config.PatternRLMSubagent)
+	}
+	if o.SupervisorProvider == "" {
+		o.SupervisorProvider = config.DefaultProvider(config.PatternSupervisor)
+	}
+	return o
+}
+
+// supervisorClient returns the LLM client for the supervisor: provider,
+// unless opts names a different supervisor provider.
+func supervisorClient(provider llm.Provider, opts Options) (*llm.Client, error) {
+	if opts.SupervisorProvider == "" || opts.SupervisorProvider == opts.Provider {
+		return llm.NewClient(provider), nil
+	}
+	supervisorProvider, err := createProvider(opts.SupervisorProvider, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create supervisor provider: %w", err)
+	}
+	if opts.Verbose {
+		fmt.Printf("Using %s (%s) for the supervisor\n", opts.SupervisorProvider, supervisorProvider.Model())
+	}
+	return llm.NewClient(supervisorProvider), nil
+}
+
+func createProvider(providerName string, opts Options) (llm.Provider, error) {
 	if providerName == "" {
-		return nil, fmt.Errorf("--provider is required for this command")
+		return nil, fmt.Errorf("--provider is required for this command (or set ARIADNE_PROVIDER)")
+	}
+	// A dry run sends nothing anywhere, sub-agents and supervisors included
+	if IsDryProvider(providerName) || IsDryProvider(opts.Provider) {
+		return llm.NewDryProvider(nil), nil
 	}
 
 	providerType, err := llm.ParseProviderType(providerName)
@@ -1438,11 +2337,72 @@ func createProvider(providerName string) (llm.Provider, error) {
 		return nil, err
 	}
 
-	return providerType.
+	builder := providerType.
 		Model(settings.LLM.Model).
 		MaxTokens(settings.LLM.MaxTokens).
-		Temperature(float32(settings.LLM.Temperature)).
-		APIKey(apiKey)
+		Temperature(float32(settings.LLM.Temperature))
+	httpClient, err := providerHTTPClient(settings.LLM.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", providerType, err)
+	}
+	if opts.DebugLLM || opts.DebugLLMContent {
+		wire, err := debugWireLog(opts)
+		if err != nil {
+			return nil, err
+		}
+		if httpClient == nil {
+			httpClient = wire.HTTPClient(providerType.String())
+		} else {
+			httpClient.Transport = wire.Transport(providerType.String(), httpClient.Transport)
+		}
+	}
+	if httpClient != nil {
+		builder = builder.HTTPClient(httpClient)
+	}
+	if limiter := sharedRateLimiter(settings.LLM); limiter != nil {
+		builder = builder.RateLimiter(limiter)
+	}
+	if settings.LLM.AnonymizeMap != "" {
+		anon, err := llm.LoadAnonymizer(settings.LLM.AnonymizeMap)
+		if err != nil {
+			return nil, err
+		}
+		builder = builder.Anonymizer(anon)
+	}
+	if settings.LLM.VertexProject != "" {
+		builder = builder.Vertex(settings.LLM.VertexProject, settings.LLM.VertexLocation)
+	}
+	if opts.ValidateProvider || settings.LLM.Validate {
+		builder = builder.Validate()
+	}
+	return builder.APIKey(apiKey)
+}
+
+// IsDryProvider reports whether name selects the dry-run provider, which
+// prints prompts instead of calling an LLM.
+func IsDryProvider(name string) bool {
+	switch strings.ToLower(name) {
+	case llm.DryProviderName, "none":
+		return true
+	}
+	return false
+}
+
+// providerHTTPClient returns the HTTP client for a provider's settings, or
+// nil if they are all defaults.
+func providerHTTPClient(cfg config.HTTPConfig) (*http.Client, error) {
+	httpOpts := llm.HTTPOptions{
+		Timeout:            time.Duration(cfg.RequestTimeoutSecs) * time.Second,
+		ConnectTimeout:     time.Duration(cfg.ConnectTimeoutSecs) * time.Second,
+		Proxy:              cfg.Proxy,
+		CACertFile:         cfg.CACert,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		Headers:            cfg.Headers,
+	}
+	if httpOpts.IsZero() {
+		return nil, nil
+	}
+	return llm.NewHTTPClient(httpOpts)
 }
 
 const (
@@ -1458,7 +2418,7 @@ func printAgentSteps(steps []agent.Step) {
 			fmt.Printf("    Action: %s\n", *step.Action)
 		}
 		if step.Observation != nil {
-			obs := truncateString(*step.Observation, maxAgentObservationLen)
+			obs := text.TruncateRunes(*step.Observation, maxAgentObservationLen)
 			fmt.Printf("    Observation: %s\n", obs)
 		}
 		fmt.Println()
@@ -1475,7 +2435,7 @@ func printOrchestrationSteps(steps []orchestration.Step) {
 			fmt.Printf("    Action: %s\n", *step.Action)
 		}
 		if step.Observation != nil {
-			obs := truncateString(*step.Observation, maxOrchestrationObservationLen)
+			obs := text.TruncateRunes(*step.Observation, maxOrchestrationObservationLen)
 			fmt.Printf("    Observation: %s\n", obs)
 		}
 		fmt.Println()
@@ -1484,18 +2444,43 @@ func printOrchestrationSteps(steps []orchestration.Step) {
 	fmt.Println()
 }
 
-// truncateString truncates a string to maxLen runes, preserving UTF-8 boundaries.
-func truncateString(s string, maxLen int) string {
-	runes := []rune(s)
-	if len(runes) <= maxLen {
-		return s
+// printEnvironment prints the run's environment snapshot.
+func printEnvironment(env *model.Environment) {
+	fmt.Printf("Environment:\n")
+	fmt.Printf("  ariadne %s (%s, %s/%s)\n", env.AriadneVersion, env.GoVersion, env.OS, env.Arch)
+	fmt.Printf("  Provider: %s (%s)\n", env.Provider, env.Model)
+	if env.GitCommit != "" {
+		dirty := ""
+		if env.GitDirty {
+			dirty = " (dirty)"
+		}
+		fmt.Printf("  Workspace: %s%s\n", env.GitCommit, dirty)
+	}
+	names := make([]string, 0, len(env.Tools))
+	for name := range env.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s: %s\n", name, env.Tools[name])
 	}
-	return string(runes[:maxLen]) + "..."
+	fmt.Println()
 }
 
 // bytesPerToken is the approximate bytes per token for estimation.
 const bytesPerToken = 4
 
+// printNextSteps lists the suggested follow-ups of an unfinished run.
+func printNextSteps(status *orchestration.CompletionStatus) {
+	if status == nil || len(status.NextSteps) == 0 {
+		return
+	}
+	fmt.Println("\nSuggested next steps:")
+	for i, step := range status.NextSteps {
+		fmt.Printf("  %d. %s\n", i+1, step)
+	}
+}
+
 // printTokenStats prints token usage statistics.
 func printTokenStats(meta *orchestration.Metadata) {
 	if meta == nil || meta.TokenStats == nil {
@@ -1505,10 +2490,20 @@ func printTokenStats(meta *orchestration.Metadata) {
 	fmt.Printf("\nToken Usage:\n")
 	fmt.Printf("  LLM calls: %d\n", stats.LLMCalls)
 	fmt.Printf("  Prompt tokens: %d\n", stats.PromptTokens)
+	if stats.CachedPromptTokens > 0 {
+		fmt.Printf("  Cached prompt tokens: %d\n", stats.CachedPromptTokens)
+	}
 	fmt.Printf("  Completion tokens: %d\n", stats.CompletionTokens)
 	fmt.Printf("  Total tokens: %d\n", stats.TotalTokens)
 	if stats.ResultsStored > 0 {
 		fmt.Printf("  Results stored: %d\n", stats.ResultsStored)
 		fmt.Printf("  Context bytes saved: %d (~%d tokens)\n", stats.BytesSaved, stats.BytesSaved/bytesPerToken)
 	}
+	if stats.DecisionRepairs > 0 || stats.DecisionFallbacks > 0 {
+		fmt.Printf("  Decision repairs: %d (%d unrecovered)\n", stats.DecisionRepairs, stats.DecisionFallbacks)
+	}
+	if stats.Verifications > 0 {
+		fmt.Printf("  Verifications: %d (%d rejected)\n", stats.Verifications, stats.VerifyRejections)
+	}
+	printRateLimitStats()
 }
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/tools"
+)
+
+func TestValidateToolFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		deny    []string
+		wantErr string
+	}{
+		{"run tools", []string{"read_file", "get_lines", "ripgrep"}, []string{"execute_shell", "http_request"}, ""},
+		{"tools runs add", []string{"spawn", "parallel_spawn", "run_starlark", "notes", "next_page", "store_memory", "recall_memory"}, nil, ""},
+		{"typo", nil, []string{"execute_shel"}, "unknown tools: execute_shel"},
+		{"old name", []string{"run_python"}, nil, "unknown tools: run_python"},
+	}
+	for _, tt := range tests {
+		err := validateToolFilter(tools.NewToolFilter(tt.allow, tt.deny), nil)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
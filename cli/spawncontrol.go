@@ -0,0 +1,86 @@
+// Interactive control of rlm spawn trees.
+//
+// With --verbose on a terminal, rlm reads commands from stdin while it
+// runs: "ps" lists the running sub-agents by tree ID, "kill <id>" stops
+// that sub-agent and its subtree. The parent sees a failed spawn and the
+// rest of the run carries on.
+//
+// Information Hiding:
+// - Command parsing hidden
+// - Terminal detection hidden
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/tools"
+)
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// watchSpawnCommands runs spawn control commands read from in until ctx
+// ends or in is closed.
+func watchSpawnCommands(ctx context.Context, control *tools.SpawnControl, in io.Reader, out io.Writer) {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			runSpawnCommand(control, line, out)
+		}
+	}
+}
+
+// runSpawnCommand executes one "ps" or "kill <id>" command.
+func runSpawnCommand(control *tools.SpawnControl, line string, out io.Writer) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	switch fields[0] {
+	case "ps":
+		running := control.Running()
+		if len(running) == 0 {
+			fmt.Fprintln(out, "[control] no sub-agents running")
+			return
+		}
+		for _, s := range running {
+			fmt.Fprintf(out, "[control] %-8s depth %d, %s: %s\n", s.ID, s.Depth, time.Since(s.Started).Round(time.Second), s.Task)
+		}
+	case "kill":
+		if len(fields) != 2 {
+			fmt.Fprintln(out, "[control] usage: kill <id>")
+			return
+		}
+		if err := control.Cancel(fields[1]); err != nil {
+			fmt.Fprintf(out, "[control] %v\n", err)
+			return
+		}
+		fmt.Fprintf(out, "[control] cancelled %s and its sub-agents\n", fields[1])
+	default:
+		fmt.Fprintln(out, "[control] commands: ps, kill <id>")
+	}
+}
@@ -0,0 +1,60 @@
+// Result store sharing for `ariadne store serve`.
+//
+// Information Hiding:
+// - Database opening and signal handling hidden
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// DefaultStoreSocket is where `ariadne store serve` listens by default.
+const DefaultStoreSocket = ".ariadne/store.sock"
+
+// StoreServe serves the result store in the database at dbPath on a unix
+// socket at socketPath until interrupted, so other local processes can
+// store, get and search content through one shared index (see
+// storage.ServeRPC). With readOnly, stores are refused.
+func StoreServe(ctx context.Context, dbPath, socketPath string, readOnly bool) error {
+	if dbPath == "" {
+		dbPath = defaultDBPath
+	}
+	if socketPath == "" {
+		socketPath = DefaultStoreSocket
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	db, err := storage.OpenSqlite(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	store, err := storage.NewResultStore(db)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("failed to create result store: %w", err)
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if readOnly {
+		ctx = storage.WithReadOnly(ctx)
+	}
+
+	mode := "read-write"
+	if readOnly {
+		mode = "read-only"
+	}
+	fmt.Fprintf(os.Stderr, "Serving %s (%s) on %s; press Ctrl-C to stop\n", dbPath, mode, socketPath)
+	return storage.ServeRPC(ctx, store, socketPath)
+}
@@ -0,0 +1,329 @@
+// Built-in workflows.
+//
+// A workflow is a named pipeline of agent steps that ends in a report with
+// a fixed layout, so common jobs (auditing a repo, writing an onboarding
+// guide, reviewing dependencies) need no agent or prompt design. Steps run
+// in order over one result store session: files a step stored are there
+// for the next, and each step's answer is passed on in later prompts. The
+// last step writes the report.
+//
+// Information Hiding:
+// - Step prompts and report layouts hidden
+// - Placeholder substitution hidden
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/tools"
+)
+
+// Workflow is a named pipeline of agent steps.
+type Workflow struct {
+	Name        string
+	Title       string // Report heading
+	Description string
+	Steps       []WorkflowStep
+}
+
+// WorkflowStep runs one agent. Prompt may use {{path}} for the target
+// directory and {{name}} for the answer of an earlier step.
+type WorkflowStep struct {
+	Name   string
+	Agent  string
+	Prompt string
+}
+
+// surveyStep maps the repository; the other steps build on its answer.
+var surveyStep = WorkflowStep{
+	Name:  "survey",
+	Agent: string(AgentFile),
+	Prompt: `Survey the repository at {{path}}. List its files (respecting .gitignore), read the README and build files, and skim the main entry points. Report:
+- Languages, frameworks and the build, test and run commands
+- The top-level directories and what each contains
+- Entry points and the main packages or modules, with their paths
+Keep it factual and cite paths. Do not guess at anything you did not read.`,
+}
+
+// workflows are the built-in workflows, in listing order.
+var workflows = []Workflow{
+	{
+		Name:        "code-audit",
+		Title:       "Code Audit",
+		Description: "Find security, correctness and maintainability issues, ranked by severity",
+		Steps: []WorkflowStep{
+			surveyStep,
+			{
+				Name:  "findings",
+				Agent: string(AgentFile),
+				Prompt: `Audit the repository at {{path}}. Its survey:
+
+{{survey}}
+
+Read the code most likely to hide problems: input handling, authentication, file and shell access, concurrency, error handling and resource cleanup. For each issue give the file and line, what is wrong, why it matters, and a concrete fix. Rate each critical, high, medium or low. Only report issues you saw in the code.`,
+			},
+			{
+				Name:  "report",
+				Agent: string(AgentGeneral),
+				Prompt: `Write a code audit report from the findings below, in Markdown with exactly these sections:
+
+## Summary
+Two or three sentences on overall health and the most urgent issue.
+
+## Findings
+A table with columns Severity, Location, Issue, Fix, sorted by severity. Keep every finding and its file:line location.
+
+## Recommendations
+Up to five next steps, most valuable first.
+
+Survey:
+{{survey}}
+
+Findings:
+{{findings}}`,
+			},
+		},
+	},
+	{
+		Name:        "onboarding-doc",
+		Title:       "Onboarding Guide",
+		Description: "Write a getting-started guide for developers new to the repository",
+		Steps: []WorkflowStep{
+			surveyStep,
+			{
+				Name:  "concepts",
+				Agent: string(AgentFile),
+				Prompt: `Explain how the repository at {{path}} works to a developer joining the team. Its survey:
+
+{{survey}}
+
+Read the core packages and trace one typical request or command from entry point to result. Report the main concepts and types, how the pieces fit together, the conventions the code follows (naming, errors, tests), and the files a newcomer should read first. Cite paths.`,
+			},
+			{
+				Name:  "report",
+				Agent: string(AgentGeneral),
+				Prompt: `Write an onboarding guide from the notes below, in Markdown with exactly these sections:
+
+## Overview
+What the project does and for whom.
+
+## Getting Started
+Prerequisites and the commands to build, test and run it.
+
+## Architecture
+The main components and how a typical request flows through them.
+
+## Key Concepts
+The types and ideas a newcomer must know, with the files that define them.
+
+## Conventions
+How code is named, structured, tested and how errors are handled.
+
+## Where to Start
+A short reading list of files, in order.
+
+Survey:
+{{survey}}
+
+Notes:
+{{concepts}}`,
+			},
+		},
+	},
+	{
+		Name:        "dependency-report",
+		Title:       "Dependency Report",
+		Description: "Inventory direct dependencies and flag outdated, risky or redundant ones",
+		Steps: []WorkflowStep{
+			{
+				Name:   "inventory",
+				Agent:  string(AgentFile),
+				Prompt: `Inventory the dependencies of the repository at {{path}}. Read its manifests and lock files (go.mod, package.json, requirements.txt, pyproject.toml, Cargo.toml, Gemfile, pom.xml and the like). For every direct dependency give its name, pinned version, the manifest that declares it, and whether it is a runtime or development dependency. Where a package manager can report newer versions offline or quickly (for example go list -m -u all), run it and note the latest version.`,
+			},
+			{
+				Name:  "risks",
+				Agent: string(AgentFile),
+				Prompt: `Review the dependencies of the repository at {{path}}. Inventory:
+
+{{inventory}}
+
+Search the code to see where each dependency is used. Flag dependencies that are unused, duplicated (two libraries for the same job), far behind their latest version, pinned to a pre-release or fork, or known to be deprecated. Say how widely each flagged dependency is used, with example paths.`,
+			},
+			{
+				Name:  "report",
+				Agent: string(AgentGeneral),
+				Prompt: `Write a dependency report from the notes below, in Markdown with exactly these sections:
+
+## Summary
+Counts of direct dependencies by ecosystem and of flagged ones.
+
+## Dependencies
+A table with columns Name, Version, Latest, Scope, Manifest, covering every direct dependency. Leave Latest blank where it is unknown.
+
+## Flagged
+A table with columns Name, Problem, Usage, Suggested action.
+
+## Recommendations
+Up to five next steps, most valuable first.
+
+Inventory:
+{{inventory}}
+
+Review:
+{{risks}}`,
+			},
+		},
+	},
+}
+
+// ListWorkflows returns the built-in workflows.
+func ListWorkflows() []Workflow {
+	return workflows
+}
+
+// findWorkflow returns the built-in workflow with the given name.
+func findWorkflow(name string) (Workflow, error) {
+	names := make([]string, len(workflows))
+	for i, w := range workflows {
+		if w.Name == name {
+			return w, nil
+		}
+		names[i] = w.Name
+	}
+	return Workflow{}, fmt.Errorf("unknown workflow %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// WorkflowList prints the built-in workflows and their steps.
+func WorkflowList() {
+	for _, w := range workflows {
+		steps := make([]string, len(w.Steps))
+		for i, s := range w.Steps {
+			steps[i] = s.Name
+		}
+		fmt.Printf("%-18s %s\n", w.Name, w.Description)
+		fmt.Printf("%-18s steps: %s\n", "", strings.Join(steps, " → "))
+	}
+}
+
+// WorkflowRun runs the named workflow on the repository at path and
+// writes its report to outPath (Markdown).
+func WorkflowRun(ctx context.Context, name, path, outPath string, opts Options) error {
+	w, err := findWorkflow(name)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+
+	defer beginQuiet(opts)()
+	startTime := time.Now()
+	ctx, cancel := withDeadline(ctx, opts)
+	defer cancel()
+
+	provider, err := createProvider(opts.Provider, opts)
+	if err != nil {
+		return err
+	}
+
+	resultStore, storeSessionID, cleanup, err := createResultStore(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	toolConfig, err := newToolConfig(opts, provider, storeSessionID)
+	if err != nil {
+		return err
+	}
+	defer toolConfig.Audit.Close()
+	defer printMaskedPII(toolConfig.PII)
+	if err := validateToolFilter(toolConfig.Filter, nil); err != nil {
+		return err
+	}
+
+	var usage llm.TokenUsage
+	defer func() {
+		fmt.Printf("\n--- Workflow Metrics ---\n")
+		fmt.Printf("Duration: %s\n", time.Since(startTime).Round(time.Millisecond))
+		fmt.Printf("Tokens: %d (%d prompt, %d completion)\n", usage.TotalTokens, usage.PromptTokens, usage.CompletionTokens)
+		if resultStore != nil {
+			dsa := resultStore.Usage()
+			printDSAUsage(&dsa)
+		}
+	}()
+
+	fileContext := tools.NewStoredFileContext()
+	answers := map[string]string{"path": path}
+	var report string
+	for i, step := range w.Steps {
+		fmt.Printf("Step %d/%d: %s (%s agent)\n", i+1, len(w.Steps), step.Name, step.Agent)
+		a, err := CreateAgent(step.Agent, "", provider, toolConfig, resultStore, storeSessionID, fileContext)
+		if err != nil {
+			return err
+		}
+		if err := attachExamples([]*agent.Agent{a}, opts); err != nil {
+			return err
+		}
+		if opts.Verbose {
+			a = a.Verbose(true)
+		}
+
+		stepStart := time.Now()
+		response := a.Execute(ctx, expandWorkflowPrompt(step.Prompt, answers), opts.MaxIter)
+		if tokens := response.Metadata.TokenUsage; tokens != nil {
+			usage.Add(tokens)
+		}
+		if response.Type != agent.ResponseSuccess {
+			if response.PartialResult != "" {
+				fmt.Printf("Partial result:\n%s\n", response.PartialResult)
+			}
+			return fmt.Errorf("workflow %s: step %s: %w", w.Name, step.Name, response.AsError())
+		}
+		fmt.Printf("  done in %s (%d steps)\n", time.Since(stepStart).Round(time.Second), len(response.Steps))
+		answers[step.Name] = response.Result
+		report = response.Result
+	}
+
+	report = fmt.Sprintf("# %s: %s\n\n_Generated by `ariadne workflow run %s` on %s._\n\n%s\n",
+		w.Title, filepath.Base(absPath(path)), w.Name, time.Now().Format("2006-01-02"), strings.TrimSpace(report))
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(outPath, []byte(report), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	fmt.Fprintf(answerOut, "\n%s\n", formatAnswer(report, opts))
+	fmt.Printf("Report written to %s\n", outPath)
+	return nil
+}
+
+// expandWorkflowPrompt replaces {{name}} placeholders with answers.
+func expandWorkflowPrompt(prompt string, answers map[string]string) string {
+	pairs := make([]string, 0, 2*len(answers))
+	for name, answer := range answers {
+		pairs = append(pairs, "{{"+name+"}}", answer)
+	}
+	return strings.NewReplacer(pairs...).Replace(prompt)
+}
+
+// absPath returns path made absolute, or path itself if that fails.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -5,18 +5,53 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/richinex/ariadne/agent"
 	"github.com/richinex/ariadne/cli"
+	"github.com/richinex/ariadne/config"
+	"github.com/richinex/ariadne/storage"
+	"github.com/richinex/ariadne/tools"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Global flags
-	provider    string
-	maxIter     int
-	toolRetries uint32
-	verbose     bool
+	provider     string
+	maxIter      int
+	toolRetries  uint32
+	verbose      bool
+	maxObsBytes  int
+	httpProfiles string
+	httpRetries  int
+	artifacts    bool
+	contextPack  string
+	runTimeout   int
+	quiet        bool
+	render       bool
+	showDiffs    bool
+	debugLLM     bool
+	debugContent bool
+	storeSession string
+	keepStore    bool
+	summarizer   string
+	validateLLM  bool
+	screenWeb    bool
+	blockCalls   bool
+	allowTools   []string
+	denyTools    []string
+	egressPolicy string
+	externalDir  string
+	audit        bool
+	maskPII      bool
+	piiPatterns  []string
+	wrapUpAt     int
+	wrapUpText   string
+	wrapUpForce  bool
+	examplesFile string
+	examplesMax  int
 )
 
 func main() {
@@ -35,28 +70,138 @@ func main() {
 Two patterns available:
 - react: Single agent with DSA tools (Suffix Array, Trie) for bounded context
 - rlm: Recursive Language Model with sub-agent spawning (stateless)`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// A dry run ends at its first LLM call; that is not a usage error
+			for _, name := range []string{provider, config.DefaultProvider(config.PatternReact), config.DefaultProvider(config.PatternRLM)} {
+				if cli.IsDryProvider(name) {
+					cmd.SilenceUsage = true
+				}
+			}
+		},
 	}
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&provider, "provider", "p", "", "LLM provider (openai, anthropic, deepseek, gemini)")
+	rootCmd.PersistentFlags().StringVarP(&provider, "provider", "p", "", "LLM provider (openai, anthropic, deepseek, gemini, bedrock, or dry to print prompts without calling one; default from ARIADNE_PROVIDER)")
+	_ = rootCmd.RegisterFlagCompletionFunc("provider", completeWith(cli.CompleteProviders))
 	rootCmd.PersistentFlags().IntVarP(&maxIter, "max-iter", "m", 10, "Maximum iterations for agent execution")
 	rootCmd.PersistentFlags().Uint32Var(&toolRetries, "tool-retries", 3, "Maximum retries for tool execution")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+	rootCmd.PersistentFlags().IntVar(&wrapUpAt, "wrap-up-threshold", tools.DefaultWrapUpThreshold, "Iterations left (counting the current one) when agents are told to wrap up (negative = never)")
+	rootCmd.PersistentFlags().StringVar(&wrapUpText, "wrap-up-instruction", "", "Wrap-up message added to observations; %d is replaced by the iterations left (default: \"WARNING: Only %d iterations remaining!\")")
+	rootCmd.PersistentFlags().BoolVar(&wrapUpForce, "wrap-up-synthesize", false, "At the wrap-up threshold, make agents answer from what they have instead of calling more tools")
+	rootCmd.PersistentFlags().StringVar(&examplesFile, "examples", "", "Few-shot example library (JSON, keyed by agent name) for agents that answer with decision JSON")
+	rootCmd.PersistentFlags().IntVar(&examplesMax, "examples-budget", agent.DefaultExampleBudget, "Estimated tokens of examples each agent shows (negative = no cap)")
+	rootCmd.PersistentFlags().IntVar(&maxObsBytes, "max-observation-bytes", 8192, "Maximum bytes per tool observation before overflow to the result store")
+	rootCmd.PersistentFlags().StringVar(&httpProfiles, "http-profiles", "", "Path to HTTP auth profiles JSON file")
+	rootCmd.PersistentFlags().IntVar(&httpRetries, "http-retries", 2, "Retries for transient HTTP failures (network errors, 429, 5xx)")
+	rootCmd.PersistentFlags().BoolVar(&artifacts, "artifacts", false, "Redirect write_file/append_file outputs to .ariadne/artifacts/<run-id>/")
+	rootCmd.PersistentFlags().StringVar(&contextPack, "context", "", "Context pack to mount read-only (see 'ariadne context create')")
+	rootCmd.PersistentFlags().IntVar(&runTimeout, "timeout", 0, "Deadline in seconds for react-run, each react-chat turn, react-orchestrate, each batch task, workflow runs, and each lsp-bridge request (0 = none)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only the final answer on stdout (react-run, react-orchestrate, rlm, workflow run)")
+	rootCmd.PersistentFlags().BoolVar(&render, "render", false, "Render markdown in final answers (headings, tables, highlighted code) for the terminal")
+	rootCmd.PersistentFlags().BoolVar(&showDiffs, "diff", false, "Print a colored diff of each file change agents make (implied by --verbose)")
+	rootCmd.PersistentFlags().BoolVar(&debugLLM, "debug-llm", false, "Log provider requests/responses to .ariadne/llm-wire.jsonl (secrets redacted, content hashed)")
+	rootCmd.PersistentFlags().BoolVar(&debugContent, "debug-llm-content", false, "With --debug-llm, log prompts and completions verbatim instead of hashed")
+	rootCmd.PersistentFlags().StringVar(&storeSession, "store-session", "", "Result store keyspace for this run's stored content (default: fresh per run)")
+	rootCmd.PersistentFlags().BoolVar(&keepStore, "keep-store", false, "Keep this run's stored content after it finishes, for inspection")
+	rootCmd.PersistentFlags().StringVar(&summarizer, "summarizer", "", "How stored content is summarized for agents: head, structure or llm (default from ARIADNE_SUMMARIZER, else head)")
+	rootCmd.PersistentFlags().BoolVar(&screenWeb, "screen-web", false, "Check web content for prompt injections with the LLM and withhold flagged responses")
+	rootCmd.PersistentFlags().BoolVar(&blockCalls, "block-injected-calls", false, "Deny commands, writes and requests whose arguments were copied from ingested files or web content")
+	rootCmd.PersistentFlags().StringSliceVar(&allowTools, "tools", nil, "Only offer these tools to agents and sub-agents (default from ARIADNE_TOOLS)")
+	rootCmd.PersistentFlags().StringSliceVar(&denyTools, "deny-tools", nil, "Never offer these tools, e.g. execute_shell,http_request (default from ARIADNE_DENY_TOOLS)")
+	rootCmd.PersistentFlags().StringVar(&egressPolicy, "egress-policy", "", "JSON egress policy for http_request: allowed/denied domains, private IP blocking, response size cap (default from ARIADNE_EGRESS_POLICY)")
+	rootCmd.PersistentFlags().StringVar(&externalDir, "external-tools", "", "Load the executables in this directory as tools, e.g. "+tools.DefaultExternalToolsDir+" (default from ARIADNE_EXTERNAL_TOOLS, else none)")
+	rootCmd.PersistentFlags().BoolVar(&audit, "audit", false, "Record file writes and shell commands in the audit log (see 'ariadne audit list'; default from ARIADNE_AUDIT)")
+	rootCmd.PersistentFlags().BoolVar(&maskPII, "mask-pii", false, "Mask emails, phone numbers and card numbers in tool results before they reach the provider (default from ARIADNE_MASK_PII)")
+	rootCmd.PersistentFlags().StringArrayVar(&piiPatterns, "pii-pattern", nil, "Extra pattern to mask, as NAME=REGEX (repeatable; implies --mask-pii)")
+	rootCmd.PersistentFlags().BoolVar(&validateLLM, "validate-provider", false, "Check the API key and model before running, failing fast with the available models")
 
 	// Add commands
 	rootCmd.AddCommand(reactRunCmd())
 	rootCmd.AddCommand(reactChatCmd())
 	rootCmd.AddCommand(reactOrchestrateCmd())
 	rootCmd.AddCommand(rlmCmd())
+	rootCmd.AddCommand(batchCmd())
+	rootCmd.AddCommand(workflowCmd())
+	rootCmd.AddCommand(lspBridgeCmd())
 	rootCmd.AddCommand(toolsCmd())
+	rootCmd.AddCommand(artifactsCmd())
+	rootCmd.AddCommand(contextCmd())
+	rootCmd.AddCommand(storeCmd())
+	rootCmd.AddCommand(completionCmd())
+	rootCmd.AddCommand(doctorCmd())
+	rootCmd.AddCommand(migrateCmd())
+	rootCmd.AddCommand(memoryCmd())
+	rootCmd.AddCommand(auditCmd())
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
+	}
+}
+
+// globalOptions builds CLI options from the global flags. Tool lists, the
+// egress policy and the external tools directory not given as flags come
+// from the environment.
+func globalOptions() cli.Options {
+	envAllow, envDeny := config.ToolFilter()
+	if len(allowTools) == 0 {
+		allowTools = envAllow
+	}
+	if len(denyTools) == 0 {
+		denyTools = envDeny
+	}
+	if egressPolicy == "" {
+		egressPolicy = config.EgressPolicyPath()
+	}
+	if summarizer == "" {
+		summarizer = config.Summarizer()
+	}
+	if externalDir == "" {
+		externalDir = config.ExternalToolsDir()
+	}
+	return cli.Options{
+		Provider:            provider,
+		MaxIter:             maxIter,
+		ToolRetries:         toolRetries,
+		Verbose:             verbose,
+		MaxObservationBytes: maxObsBytes,
+		HTTPProfilesPath:    httpProfiles,
+		HTTPRetries:         httpRetries,
+		Artifacts:           artifacts,
+		ContextPack:         contextPack,
+		Timeout:             runTimeout,
+		Quiet:               quiet,
+		Render:              render,
+		ShowDiffs:           showDiffs,
+		DebugLLM:            debugLLM,
+		DebugLLMContent:     debugContent,
+		StoreSession:        storeSession,
+		KeepStore:           keepStore,
+		Summarizer:          summarizer,
+		ValidateProvider:    validateLLM,
+		ScreenWebContent:    screenWeb,
+		BlockInjectedCalls:  blockCalls,
+		Tools:               allowTools,
+		DenyTools:           denyTools,
+		EgressPolicyPath:    egressPolicy,
+		ExternalToolsDir:    externalDir,
+		Audit:               audit || config.AuditEnabled(),
+		MaskPII:             maskPII || config.MaskPIIEnabled(),
+		PIIPatterns:         piiPatterns,
+		ExamplesPath:        examplesFile,
+		ExampleBudget:       examplesMax,
+		WrapUp: tools.WrapUpPolicy{
+			Threshold:   wrapUpAt,
+			Instruction: wrapUpText,
+			Synthesize:  wrapUpForce,
+		},
 	}
 }
 
 func reactRunCmd() *cobra.Command {
+	var systemPrompt, systemPromptFile string
 	var mcpServers []string
 	var mcpConfigPath string
 
@@ -71,23 +216,21 @@ DSA tools provide bounded context via:
 - SQLite: Content persistence across sessions`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := cli.Options{
-				Provider:    provider,
-				MaxIter:     maxIter,
-				ToolRetries: toolRetries,
-				Verbose:     verbose,
-			}
+			opts := globalOptions().WithProviderDefaults(config.PatternReact)
+			opts.SystemPrompt, opts.SystemPromptFile = systemPrompt, systemPromptFile
 			return cli.ReAct(context.Background(), args[0], mcpServers, mcpConfigPath, opts)
 		},
 	}
 
 	cmd.Flags().StringArrayVar(&mcpServers, "mcp", nil, "MCP server command (repeatable)")
 	cmd.Flags().StringVar(&mcpConfigPath, "mcp-config", "", "Path to MCP config file")
+	addSystemPromptFlags(cmd, &systemPrompt, &systemPromptFile)
 
 	return cmd
 }
 
 func reactChatCmd() *cobra.Command {
+	var systemPrompt, systemPromptFile string
 	var sessionID string
 	var dbPath string
 	var mcpServers []string
@@ -103,20 +246,18 @@ DSA tools provide bounded context via:
 - Radix Trie: O(m+k) prefix lookups
 - SQLite: Content persistence across sessions`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := cli.Options{
-				Provider:    provider,
-				MaxIter:     maxIter,
-				ToolRetries: toolRetries,
-				Verbose:     verbose,
-			}
+			opts := globalOptions().WithProviderDefaults(config.PatternReact)
+			opts.SystemPrompt, opts.SystemPromptFile = systemPrompt, systemPromptFile
 			return cli.ReactChat(context.Background(), sessionID, dbPath, mcpServers, mcpConfigPath, opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&sessionID, "session", "", "Session ID for conversation persistence")
+	_ = cmd.RegisterFlagCompletionFunc("session", completeSessions)
 	cmd.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path for storage")
 	cmd.Flags().StringArrayVar(&mcpServers, "mcp", nil, "MCP server command (repeatable)")
 	cmd.Flags().StringVar(&mcpConfigPath, "mcp-config", "", "Path to MCP config file")
+	addSystemPromptFlags(cmd, &systemPrompt, &systemPromptFile)
 
 	return cmd
 }
@@ -127,6 +268,11 @@ func reactOrchestrateCmd() *cobra.Command {
 	var dbPath string
 	var mcpServers []string
 	var mcpConfigPath string
+	var parallel bool
+	var agentMessages bool
+	var verify bool
+	var verifyProvider string
+	var reportOut string
 
 	cmd := &cobra.Command{
 		Use:   "react-orchestrate [task]",
@@ -139,31 +285,44 @@ DSA tools provide bounded context via:
 - SQLite: Content persistence across sessions`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := cli.Options{
-				Provider:    provider,
-				MaxIter:     maxIter,
-				ToolRetries: toolRetries,
-				Verbose:     verbose,
-			}
+			opts := globalOptions()
+			opts.ParallelSubGoals = parallel
+			opts.AgentMessages = agentMessages
+			opts.Verify = verify || verifyProvider != ""
+			opts.VerifyProvider = verifyProvider
+			opts.ReportOut = reportOut
+			opts = opts.WithProviderDefaults(config.PatternReact)
 			return cli.ReactOrchestrate(context.Background(), args[0], agentNames, sessionID, dbPath, mcpServers, mcpConfigPath, opts)
 		},
 	}
 
-	cmd.Flags().StringSliceVarP(&agentNames, "agent", "a", nil, "Agent(s) to use (can specify multiple)")
+	cmd.Flags().StringSliceVarP(&agentNames, "agent", "a", nil, "Agent(s) to use (can specify multiple); NAME=TOOL+TOOL:opt=v;opt=v declares a custom agent")
 	cmd.Flags().StringVar(&sessionID, "session", "", "Session ID for memory persistence")
+	_ = cmd.RegisterFlagCompletionFunc("agent", completeWith(cli.CompleteAgents))
+	_ = cmd.RegisterFlagCompletionFunc("session", completeSessions)
 	cmd.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path for storage")
 	cmd.Flags().StringArrayVar(&mcpServers, "mcp", nil, "MCP server command (repeatable)")
 	cmd.Flags().StringVar(&mcpConfigPath, "mcp-config", "", "Path to MCP config file")
+	cmd.Flags().BoolVar(&parallel, "parallel", false, "Run independent sub-goals on different agents concurrently")
+	cmd.Flags().BoolVar(&agentMessages, "agent-messages", false, "Let agents ask each other questions directly with ask_agent")
+	cmd.Flags().BoolVar(&verify, "verify", false, "Check each sub-goal result with an LLM and retry rejected ones with feedback")
+	cmd.Flags().StringVar(&verifyProvider, "verify-provider", "", "LLM provider for --verify (implies --verify; default: --provider)")
+	_ = cmd.RegisterFlagCompletionFunc("verify-provider", completeWith(cli.CompleteProviders))
+	cmd.Flags().StringVar(&reportOut, "report-out", "", "Write a report of the run to this file (.html, or .md for Markdown)")
 
 	return cmd
 }
 
 func rlmCmd() *cobra.Command {
+	var systemPrompt, systemPromptFile string
 	var maxDepth int
 	var timeout int
 	var mcpServers []string
 	var mcpConfigPath string
 	var subagentProvider string
+	var adaptive bool
+	var storeWrite bool
+	var reportOut string
 
 	cmd := &cobra.Command{
 		Use:   "rlm [task]",
@@ -186,26 +345,162 @@ MCP servers can be added with --mcp flag (repeatable) or --mcp-config file.
 Based on Alex Zhang's RLM architecture.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := cli.Options{
-				Provider:         provider,
-				SubagentProvider: subagentProvider,
-				MaxIter:          maxIter,
-				ToolRetries:      toolRetries,
-				Verbose:          verbose,
-			}
+			opts := globalOptions()
+			opts.SubagentProvider = subagentProvider
+			opts.AdaptiveSpawn = adaptive
+			opts.SubagentStoreWrite = storeWrite
+			opts.ReportOut = reportOut
+			opts.SystemPrompt, opts.SystemPromptFile = systemPrompt, systemPromptFile
+			opts = opts.WithProviderDefaults(config.PatternRLM)
 			return cli.RLM(context.Background(), args[0], maxDepth, timeout, mcpServers, mcpConfigPath, opts)
 		},
 	}
 
 	cmd.Flags().IntVar(&maxDepth, "depth", 3, "Maximum recursion depth for sub-agents")
 	cmd.Flags().IntVar(&timeout, "timeout", 120, "Timeout in seconds per sub-agent")
-	cmd.Flags().StringVar(&subagentProvider, "subagent-provider", "", "LLM provider for sub-agents (cost optimization): openai, anthropic, deepseek, gemini")
+	cmd.Flags().BoolVar(&adaptive, "adaptive", false, "Scale each sub-agent's depth, iterations and timeout to its task's size (--depth and --timeout become the base)")
+	cmd.Flags().BoolVar(&storeWrite, "subagent-store-write", false, "Let sub-agents store, pin and delete in the run's result store (by default they can only read it)")
+	cmd.Flags().StringVar(&reportOut, "report-out", "", "Write a report of the run to this file (.html, or .md for Markdown)")
+	cmd.Flags().StringVar(&subagentProvider, "subagent-provider", "", "LLM provider for sub-agents (cost optimization): openai, anthropic, deepseek, gemini, bedrock (default: ARIADNE_RLM_SUBAGENT_PROVIDER)")
+	_ = cmd.RegisterFlagCompletionFunc("subagent-provider", completeWith(cli.CompleteProviders))
 	cmd.Flags().StringArrayVar(&mcpServers, "mcp", nil, "MCP server command (repeatable)")
 	cmd.Flags().StringVar(&mcpConfigPath, "mcp-config", "", "Path to MCP config file")
+	addSystemPromptFlags(cmd, &systemPrompt, &systemPromptFile)
+
+	return cmd
+}
+
+func batchCmd() *cobra.Command {
+	var outDir string
+	var agentName string
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "batch [tasks.jsonl]",
+		Short: "Run many independent tasks and collect their results",
+		Long: `Run every task in a JSONL file, one JSON object per line:
+
+  {"id": "api-repo", "task": "Summarize the TODOs in ./repos/api", "agent": "file"}
+
+Only task is required. id names the result file (default task-<line>);
+agent, system_prompt and max_iter override the command's defaults.
+
+Each task runs with its own agent and result store session, up to
+--concurrency at a time, sharing the provider rate limits. Results are
+written to <out>/<id>.json with a summary in <out>/summary.json.
+
+Exits non-zero if any task failed or timed out.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true, // Failed tasks are not usage errors
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := globalOptions().WithProviderDefaults(config.PatternReact)
+			if outDir == "" {
+				outDir = filepath.Join(".ariadne", "batch", time.Now().Format("20060102-150405"))
+			}
+			return cli.Batch(context.Background(), args[0], outDir, agentName, concurrency, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outDir, "out", "o", "", "Directory for result files (default .ariadne/batch/<timestamp>)")
+	cmd.Flags().StringVarP(&agentName, "agent", "a", string(cli.AgentFile), "Agent for tasks that don't name one; NAME=TOOL+TOOL:opt=v;opt=v declares a custom agent")
+	_ = cmd.RegisterFlagCompletionFunc("agent", completeWith(cli.CompleteAgents))
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "j", 1, "Tasks to run at the same time")
 
 	return cmd
 }
 
+func workflowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workflow",
+		Short: "Run built-in workflows that produce standard reports",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List built-in workflows",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.WorkflowList()
+		},
+	})
+	cmd.AddCommand(workflowRunCmd())
+
+	return cmd
+}
+
+func workflowRunCmd() *cobra.Command {
+	var outPath string
+
+	cmd := &cobra.Command{
+		Use:   "run [workflow] [path]",
+		Short: "Run a workflow on a repository (default: current directory)",
+		Long: `Run a built-in workflow on the repository at path and write its report.
+
+Workflows (see 'ariadne workflow list'):
+- code-audit: security, correctness and maintainability findings by severity
+- onboarding-doc: a getting-started guide for new developers
+- dependency-report: direct dependencies, with outdated and risky ones flagged
+
+Each workflow runs a fixed pipeline of agents over one result store
+session and ends in a Markdown report with a standard layout. --max-iter
+applies to each step; audits of large repositories want 20 or more.`,
+		Args: cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return cli.CompleteWorkflows(), cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) == 2 {
+				path = args[1]
+			}
+			if outPath == "" {
+				outPath = filepath.Join(".ariadne", "reports", args[0]+"-"+time.Now().Format("20060102-150405")+".md")
+			}
+			opts := globalOptions().WithProviderDefaults(config.PatternReact)
+			return cli.WorkflowRun(context.Background(), args[0], path, outPath, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "Report file (default .ariadne/reports/<workflow>-<timestamp>.md)")
+
+	return cmd
+}
+
+func lspBridgeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp-bridge",
+		Short: "Serve editor requests as JSON-RPC on stdin/stdout",
+		Long: `Serve explain, refactor and question requests from an editor plugin
+(VS Code, Neovim) started as a child process. The protocol is JSON-RPC 2.0
+framed as in the Language Server Protocol, so an editor's LSP client can
+talk to it: each message is a Content-Length header, a blank line and the
+JSON body.
+
+  Content-Length: 121
+
+  {"jsonrpc": "2.0", "id": 1, "method": "ariadne/explain", "params": {"path": "main.go", "start_line": 10, "end_line": 30}}
+
+Methods are ariadne/explain, ariadne/refactor and ariadne/ask. Params may
+also carry "content" (the unsaved buffer) and "question" (required for ask;
+the refactoring wanted for refactor). Results carry "answer", "steps",
+"tokens" and, for refactor, "replacement" for the selected lines.
+initialize, shutdown, exit and $/cancelRequest work as in LSP.
+
+Each request stores the file and runs a read-only agent bounded by
+--max-iter and --timeout (default 120s). Files are never modified.
+Everything except replies is written to stderr.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := globalOptions().WithProviderDefaults(config.PatternReact)
+			return cli.LSPBridge(context.Background(), opts)
+		},
+	}
+}
+
 func toolsCmd() *cobra.Command {
 	var verboseTools bool
 
@@ -213,12 +508,326 @@ func toolsCmd() *cobra.Command {
 		Use:   "tools",
 		Short: "List available tools",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cli.ListTools(verboseTools)
+			cli.ListTools(globalOptions(), verboseTools)
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVarP(&verboseTools, "verbose", "V", false, "Show tool parameters")
 
+	cmd.AddCommand(toolsStatsCmd())
+
+	return cmd
+}
+
+func toolsStatsCmd() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show tool usage statistics from previous runs",
+		Long: `Show per-tool call counts, failure rates, average output size and
+duration recorded by react-run, react-chat and rlm, sorted by share of output budget.
+
+Tools frequently called with identical arguments are flagged as possible loops.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.ToolStats(context.Background(), dbPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path for storage")
+
+	return cmd
+}
+
+func artifactsCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "artifacts",
+		Short: "List and fetch files produced by runs with --artifacts",
+	}
+
+	cmd.PersistentFlags().StringVar(&dir, "dir", ".ariadne/artifacts", "Artifacts root directory")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "ls [run-id]",
+		Short: "List runs with artifacts, or the files of one run",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID := ""
+			if len(args) == 1 {
+				runID = args[0]
+			}
+			return cli.ArtifactsList(dir, runID)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <run-id> <path>",
+		Short: "Print an artifact's content",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.ArtifactGet(dir, args[0], args[1])
+		},
+	})
+
+	return cmd
+}
+
+func contextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage context packs (named bundles of pre-ingested content)",
+	}
+
+	var ingest []string
+	create := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Snapshot files into a named context pack",
+		Long: `Ingest files and directories into a named context pack stored in
+.ariadne/ariadne.db. Directories are walked recursively, honoring .gitignore.
+Re-running for an existing pack is incremental: files are compared by
+content hash, only new or changed files are re-stored, and files no longer
+on disk are dropped. A summary of added/updated/removed files is printed.
+
+Mount the pack in react-run, react-chat or rlm with --context <name>.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.ContextCreate(context.Background(), args[0], ingest)
+		},
+	}
+	create.Flags().StringArrayVar(&ingest, "ingest", nil, "File or directory to ingest (repeatable)")
+	_ = create.MarkFlagRequired("ingest")
+
+	cmd.AddCommand(create)
+	return cmd
+}
+
+func storeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Share the result store with other local processes",
+	}
+
+	var dbPath, socketPath string
+	var readOnly bool
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the result store on a unix socket",
+		Long: `Serve the result store on a unix socket so MCP servers and external
+workers on this machine can store, get and search content through one
+shared index instead of ingesting the same files again.
+
+The protocol is JSON-RPC 1.0: send {"method": "ResultStore.Search",
+"params": [{"SessionID": "...", "Pattern": "...", "Limit": 10}], "id": 1}.
+Methods are ResultStore.Store, ResultStore.Get and ResultStore.Search; Go
+callers can use storage.DialRPC. The socket is only accessible to the
+current user. Stop the server with Ctrl-C.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.StoreServe(context.Background(), dbPath, socketPath, readOnly)
+		},
+	}
+	serve.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path for storage")
+	serve.Flags().StringVar(&socketPath, "socket", cli.DefaultStoreSocket, "Unix socket to listen on")
+	serve.Flags().BoolVar(&readOnly, "read-only", false, "Refuse stores; clients can only get and search")
+
+	cmd.AddCommand(serve)
+	return cmd
+}
+
+func doctorCmd() *cobra.Command {
+	var dbPath string
+	var mcpConfigPath string
+	var noPing bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check provider keys, tools, database and MCP config",
+		Long: `Diagnose the environment and print a fix for each problem:
+
+- Provider API keys, each verified with a one-line request (skip with --no-ping)
+- ripgrep (rg) and npx on PATH
+- Database directory writable and existing database readable
+- MCP config parses and every server command resolves (with --mcp-config)
+
+Exits non-zero if a check failed. Missing optional pieces are warnings.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // Failed checks are not usage errors
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.Doctor(context.Background(), cli.DoctorOptions{
+				DBPath:        dbPath,
+				MCPConfigPath: mcpConfigPath,
+				Ping:          !noPing,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path to check")
+	cmd.Flags().StringVar(&mcpConfigPath, "mcp-config", "", "MCP config file to validate")
+	cmd.Flags().BoolVar(&noPing, "no-ping", false, "Check that keys exist without calling providers")
+
+	return cmd
+}
+
+func migrateCmd() *cobra.Command {
+	var dbPath string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade the database to the current schema version",
+		Long: `Upgrade a database created by an older ariadne to the schema this build
+uses. A consistent backup (<db>.v<from>-<timestamp>.bak) is written before
+any change, and each migration runs in its own transaction.
+
+Commands refuse to open a database at another schema version, so run this
+after upgrading ariadne when told to.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.Migrate(context.Background(), dbPath, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path to migrate")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List pending migrations without applying them")
+
+	return cmd
+}
+
+func memoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "memory",
+		Short: "Maintain stored agent memories",
+	}
+
+	cmd.AddCommand(memoryPruneCmd())
+
 	return cmd
 }
+
+func memoryPruneCmd() *cobra.Command {
+	var dbPath string
+	var opts storage.MemoryPruneOptions
+	var halfLifeHours float64
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove low-value memories",
+		Long: `Remove memories that are no longer worth recalling.
+
+Each memory is scored from its importance (0-1, set when stored), how
+recently it was used (halving every --half-life hours) and how often it
+has been recalled. Per session, memories scoring below --min-score are
+removed, and only the --keep highest-scoring are kept.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.HalfLife = time.Duration(halfLifeHours * float64(time.Hour))
+			return cli.PruneMemories(context.Background(), dbPath, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path for storage")
+	cmd.Flags().StringVar(&opts.SessionID, "session", "", "Prune only this session (default: all sessions)")
+	cmd.Flags().IntVar(&opts.Keep, "keep", 0, "Keep at most this many memories per session (0 = no cap)")
+	cmd.Flags().Float64Var(&opts.MinScore, "min-score", 0, "Remove memories scoring below this (0-1)")
+	cmd.Flags().Float64Var(&halfLifeHours, "half-life", storage.DefaultMemoryHalfLife.Hours(), "Hours for a memory's recency to halve")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Count memories that would be pruned without deleting them")
+	_ = cmd.RegisterFlagCompletionFunc("session", completeSessions)
+
+	return cmd
+}
+
+func auditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log of file writes and shell commands",
+	}
+
+	cmd.AddCommand(auditListCmd())
+
+	return cmd
+}
+
+func auditListCmd() *cobra.Command {
+	var dbPath string
+	var filter storage.AuditFilter
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List audited tool calls",
+		Long: `List the file writes, edits and shell commands recorded by runs with
+--audit, oldest first: time, session, run, acting agent, tool, outcome
+(success, failure or denied) and the SHA-256 of the call's arguments.
+
+The audit log is append-only; entries can't be changed or deleted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since > 0 {
+				filter.Since = time.Now().Add(-since).Unix()
+			}
+			return cli.AuditList(context.Background(), dbPath, filter)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", ".ariadne/ariadne.db", "Database path for storage")
+	cmd.Flags().StringVar(&filter.SessionID, "session", "", "Only this session")
+	cmd.Flags().StringVar(&filter.RunID, "run", "", "Only this run")
+	cmd.Flags().StringVar(&filter.ToolName, "tool", "", "Only this tool")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only entries newer than this (e.g. 24h)")
+	cmd.Flags().IntVar(&filter.Limit, "limit", 0, "Only the most recent N entries (0 = all)")
+	_ = cmd.RegisterFlagCompletionFunc("session", completeSessions)
+
+	return cmd
+}
+
+func completionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Generate a shell completion script",
+		Long: `Generate a completion script for bash, zsh or fish.
+
+Completions cover commands and flags, plus provider names for --provider,
+agent presets for --agent, and session IDs from the --db database for
+--session.
+
+  bash:  source <(ariadne completion bash)
+  zsh:   ariadne completion zsh > "${fpath[1]}/_ariadne"
+  fish:  ariadne completion fish > ~/.config/fish/completions/ariadne.fish`,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			default:
+				return root.GenFishCompletion(os.Stdout, true)
+			}
+		},
+	}
+}
+
+// completeWith completes a flag from a fixed list of candidates.
+func completeWith(values func() []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values(), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// addSystemPromptFlags registers --system-prompt and --system-prompt-file.
+func addSystemPromptFlags(cmd *cobra.Command, text, file *string) {
+	cmd.Flags().StringVar(text, "system-prompt", "", "Instructions to prepend to the built-in system prompt (house style, domain constraints)")
+	cmd.Flags().StringVar(file, "system-prompt-file", "", "File of instructions to prepend to the built-in system prompt")
+	cmd.MarkFlagsMutuallyExclusive("system-prompt", "system-prompt-file")
+}
+
+// completeSessions completes --session from the command's --db database.
+func completeSessions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dbPath, _ := cmd.Flags().GetString("db")
+	return cli.CompleteSessions(context.Background(), dbPath), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,391 @@
+// Secret backends for API keys.
+//
+// APIKeyFor resolves keys through a SecretProvider selected by the
+// ARIADNE_SECRETS_BACKEND environment variable:
+// - env (default): plain environment variables
+// - keychain: OS keychain (macOS `security`, Linux `secret-tool`)
+// - vault: HashiCorp Vault KV v2 over HTTP
+// - aws: AWS Secrets Manager via the aws CLI
+//
+// Non-env backends are wrapped in a TTL cache so rotated secrets are
+// picked up without a restart. Environment variables always take precedence.
+//
+// Information Hiding:
+// - Backend protocols and CLI invocations hidden
+// - Cache expiry and invalidation hidden
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Secret backend names accepted by ARIADNE_SECRETS_BACKEND.
+const (
+	SecretsBackendEnv      = "env"
+	SecretsBackendKeychain = "keychain"
+	SecretsBackendVault    = "vault"
+	SecretsBackendAWS      = "aws"
+)
+
+// DefaultSecretTTL is how long fetched secrets are cached before re-fetching.
+const DefaultSecretTTL = 5 * time.Minute
+
+// secretLookupTimeout bounds a single backend lookup.
+const secretLookupTimeout = 10 * time.Second
+
+// ErrSecretNotFound is returned when a backend has no value for a secret.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves named secrets. Names are the environment variable
+// names the key would otherwise use (e.g. OPENAI_API_KEY).
+type SecretProvider interface {
+	Name() string
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+var (
+	secretMu       sync.Mutex
+	secretProvider SecretProvider
+)
+
+// SetSecretProvider overrides the backend used by APIKeyFor.
+// Pass nil to go back to selecting from the environment.
+func SetSecretProvider(p SecretProvider) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secretProvider = p
+}
+
+// InvalidateSecrets drops cached secrets so the next lookup re-fetches them.
+// Call after rotating a key or when a provider rejects a key as expired.
+func InvalidateSecrets() {
+	secretMu.Lock()
+	p := secretProvider
+	secretMu.Unlock()
+	if cache, ok := p.(*CachedSecretProvider); ok {
+		cache.Invalidate("")
+	}
+}
+
+// activeSecretProvider returns the configured provider, building it from
+// the environment on first use.
+func activeSecretProvider() (SecretProvider, error) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	if secretProvider != nil {
+		return secretProvider, nil
+	}
+	p, err := SecretProviderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	secretProvider = p
+	return p, nil
+}
+
+// SecretProviderFromEnv builds the backend named by ARIADNE_SECRETS_BACKEND.
+// Backend settings:
+// - keychain: ARIADNE_KEYCHAIN_SERVICE (default "ariadne")
+// - vault: VAULT_ADDR, VAULT_TOKEN, ARIADNE_VAULT_PATH (default "secret/data/ariadne")
+// - aws: ARIADNE_AWS_SECRET_ID (default "ariadne"), AWS_REGION
+// ARIADNE_SECRETS_TTL_SECS sets the cache TTL for non-env backends.
+func SecretProviderFromEnv() (SecretProvider, error) {
+	backend := strings.ToLower(os.Getenv("ARIADNE_SECRETS_BACKEND"))
+
+	var p SecretProvider
+	switch backend {
+	case "", SecretsBackendEnv:
+		return EnvSecretProvider{}, nil
+	case SecretsBackendKeychain:
+		p = NewKeychainSecretProvider(getEnvString("ARIADNE_KEYCHAIN_SERVICE", "ariadne"))
+	case SecretsBackendVault:
+		addr := os.Getenv("VAULT_ADDR")
+		if addr == "" {
+			return nil, fmt.Errorf("VAULT_ADDR environment variable not set")
+		}
+		p = NewVaultSecretProvider(addr, os.Getenv("VAULT_TOKEN"),
+			getEnvString("ARIADNE_VAULT_PATH", "secret/data/ariadne"))
+	case SecretsBackendAWS:
+		p = NewAWSSecretProvider(getEnvString("ARIADNE_AWS_SECRET_ID", "ariadne"), os.Getenv("AWS_REGION"))
+	default:
+		return nil, fmt.Errorf("unknown secrets backend: %q", backend)
+	}
+
+	ttlSecs, err := getEnvInt("ARIADNE_SECRETS_TTL_SECS", int(DefaultSecretTTL/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	return NewCachedSecretProvider(p, time.Duration(ttlSecs)*time.Second), nil
+}
+
+// lookupAPIKey resolves a key env var name: the environment first, then the
+// configured backend.
+func lookupAPIKey(name string) (string, error) {
+	if key := os.Getenv(name); key != "" {
+		return key, nil
+	}
+
+	p, err := activeSecretProvider()
+	if err != nil {
+		return "", err
+	}
+	if _, isEnv := p.(EnvSecretProvider); isEnv {
+		return "", fmt.Errorf("%s environment variable not set", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretLookupTimeout)
+	defer cancel()
+	key, err := p.GetSecret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("%s not set in environment or %s secrets backend: %w", name, p.Name(), err)
+	}
+	return key, nil
+}
+
+// EnvSecretProvider reads secrets from environment variables.
+type EnvSecretProvider struct{}
+
+func (EnvSecretProvider) Name() string { return SecretsBackendEnv }
+
+func (EnvSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	if val := os.Getenv(name); val != "" {
+		return val, nil
+	}
+	return "", fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+}
+
+// KeychainSecretProvider reads secrets from the OS keychain. Entries are
+// stored with the service name and the secret name as the account/key.
+//
+//	macOS: security add-generic-password -s ariadne -a OPENAI_API_KEY -w <key>
+//	Linux: secret-tool store --label=ariadne service ariadne key OPENAI_API_KEY
+type KeychainSecretProvider struct {
+	service string
+}
+
+// NewKeychainSecretProvider creates a keychain backend for a service name.
+func NewKeychainSecretProvider(service string) *KeychainSecretProvider {
+	return &KeychainSecretProvider{service: service}
+}
+
+func (k *KeychainSecretProvider) Name() string { return SecretsBackendKeychain }
+
+func (k *KeychainSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", k.service, "-a", name, "-w")
+	case "linux":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", k.service, "key", name)
+	default:
+		return "", fmt.Errorf("keychain backend not supported on %s", runtime.GOOS)
+	}
+
+	out, err := runSecretCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("keychain lookup for %s failed: %w", name, err)
+	}
+	if out == "" {
+		return "", fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+	}
+	return out, nil
+}
+
+// VaultSecretProvider reads fields of a HashiCorp Vault KV v2 secret.
+// Each API key is a field of the secret at path, named like its env var.
+type VaultSecretProvider struct {
+	addr   string
+	token  string
+	path   string
+	client *http.Client
+}
+
+// NewVaultSecretProvider creates a Vault backend. path is the full KV v2 API
+// path, e.g. "secret/data/ariadne".
+func NewVaultSecretProvider(addr, token, path string) *VaultSecretProvider {
+	return &VaultSecretProvider{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		path:   strings.Trim(path, "/"),
+		client: &http.Client{Timeout: secretLookupTimeout},
+	}
+}
+
+func (v *VaultSecretProvider) Name() string { return SecretsBackendVault }
+
+func (v *VaultSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+v.path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %w", err)
+	}
+	if v.token != "" {
+		req.Header.Set("X-Vault-Token", v.token)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("vault path %s: %w", v.path, ErrSecretNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned HTTP %d for %s", resp.StatusCode, v.path)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	val, ok := body.Data.Data[name].(string)
+	if !ok || val == "" {
+		return "", fmt.Errorf("%s in vault path %s: %w", name, v.path, ErrSecretNotFound)
+	}
+	return val, nil
+}
+
+// AWSSecretProvider reads API keys from an AWS Secrets Manager secret whose
+// SecretString is a JSON object keyed by env var name. Lookups go through
+// the aws CLI so the usual credential chain (profiles, SSO, instance roles)
+// applies without an SDK dependency.
+type AWSSecretProvider struct {
+	secretID string
+	region   string
+}
+
+// NewAWSSecretProvider creates an AWS Secrets Manager backend.
+// region may be empty to use the CLI's default.
+func NewAWSSecretProvider(secretID, region string) *AWSSecretProvider {
+	return &AWSSecretProvider{secretID: secretID, region: region}
+}
+
+func (a *AWSSecretProvider) Name() string { return SecretsBackendAWS }
+
+func (a *AWSSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	args := []string{"secretsmanager", "get-secret-value",
+		"--secret-id", a.secretID, "--query", "SecretString", "--output", "text"}
+	if a.region != "" {
+		args = append(args, "--region", a.region)
+	}
+
+	out, err := runSecretCommand(exec.CommandContext(ctx, "aws", args...))
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager lookup failed: %w", err)
+	}
+	return secretField(out, name)
+}
+
+// secretField extracts name from a JSON object secret string.
+func secretField(secret, name string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret string is not a JSON object: %w", err)
+	}
+	val, ok := fields[name].(string)
+	if !ok || val == "" {
+		return "", fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+	}
+	return val, nil
+}
+
+// runSecretCommand runs a CLI lookup and returns trimmed stdout. Stderr is
+// included in the error, never the output, so secrets don't leak into logs.
+func runSecretCommand(cmd *exec.Cmd) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// CachedSecretProvider caches another provider's secrets for a TTL.
+// Expired entries are re-fetched, so rotated secrets are picked up within one TTL.
+type CachedSecretProvider struct {
+	inner   SecretProvider
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewCachedSecretProvider wraps a provider with a TTL cache.
+// A non-positive ttl uses DefaultSecretTTL.
+func NewCachedSecretProvider(inner SecretProvider, ttl time.Duration) *CachedSecretProvider {
+	if ttl <= 0 {
+		ttl = DefaultSecretTTL
+	}
+	return &CachedSecretProvider{
+		inner:   inner,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedSecret),
+	}
+}
+
+func (c *CachedSecretProvider) Name() string { return c.inner.Name() }
+
+func (c *CachedSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	val, err := c.inner.GetSecret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.entries[name] = cachedSecret{value: val, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return val, nil
+}
+
+// Invalidate drops a cached secret, or all of them when name is empty.
+func (c *CachedSecretProvider) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name == "" {
+		c.entries = make(map[string]cachedSecret)
+		return
+	}
+	delete(c.entries, name)
+}
+
+func getEnvString(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+type countingSecretProvider struct {
+	values map[string]string
+	calls  int
+}
+
+func (c *countingSecretProvider) Name() string { return "counting" }
+
+func (c *countingSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	c.calls++
+	val, ok := c.values[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return val, nil
+}
+
+func TestCachedSecretProviderTTL(t *testing.T) {
+	inner := &countingSecretProvider{values: map[string]string{"KEY": "v1"}}
+	cache := NewCachedSecretProvider(inner, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		val, err := cache.GetSecret(context.Background(), "KEY")
+		if err != nil || val != "v1" {
+			t.Fatalf("expected v1, got %q (%v)", val, err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected 1 backend call, got %d", inner.calls)
+	}
+
+	// Rotation: after expiry the new value is fetched
+	inner.values["KEY"] = "v2"
+	now = now.Add(2 * time.Minute)
+	val, _ := cache.GetSecret(context.Background(), "KEY")
+	if val != "v2" {
+		t.Errorf("expected rotated value v2, got %q", val)
+	}
+
+	inner.values["KEY"] = "v3"
+	cache.Invalidate("KEY")
+	val, _ = cache.GetSecret(context.Background(), "KEY")
+	if val != "v3" {
+		t.Errorf("expected v3 after invalidate, got %q", val)
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/ariadne" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"OPENAI_API_KEY":"sk-vault"}}}`))
+	}))
+	defer server.Close()
+
+	p := NewVaultSecretProvider(server.URL, "root", "secret/data/ariadne")
+	val, err := p.GetSecret(context.Background(), "OPENAI_API_KEY")
+	if err != nil || val != "sk-vault" {
+		t.Fatalf("expected sk-vault, got %q (%v)", val, err)
+	}
+
+	_, err = p.GetSecret(context.Background(), "ANTHROPIC_API_KEY")
+	if !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound, got %v", err)
+	}
+
+	_, err = NewVaultSecretProvider(server.URL, "wrong", "secret/data/ariadne").GetSecret(context.Background(), "OPENAI_API_KEY")
+	if err == nil {
+		t.Error("expected error for bad token")
+	}
+}
+
+func TestAPIKeyForUsesSecretProvider(t *testing.T) {
+	original := os.Getenv("OPENAI_API_KEY")
+	os.Unsetenv("OPENAI_API_KEY")
+	defer os.Setenv("OPENAI_API_KEY", original)
+
+	SetSecretProvider(&countingSecretProvider{values: map[string]string{"OPENAI_API_KEY": "sk-backend"}})
+	defer SetSecretProvider(nil)
+
+	key, err := APIKeyFor("openai")
+	if err != nil || key != "sk-backend" {
+		t.Fatalf("expected sk-backend, got %q (%v)", key, err)
+	}
+
+	// Environment overrides the backend
+	os.Setenv("OPENAI_API_KEY", "sk-env")
+	key, _ = APIKeyFor("openai")
+	if key != "sk-env" {
+		t.Errorf("expected env override sk-env, got %q", key)
+	}
+}
+
+func TestSecretFieldParsesJSON(t *testing.T) {
+	val, err := secretField(`{"GEMINI_API_KEY":"g-key"}`, "GEMINI_API_KEY")
+	if err != nil || val != "g-key" {
+		t.Fatalf("expected g-key, got %q (%v)", val, err)
+	}
+	if _, err := secretField("plain", "GEMINI_API_KEY"); err == nil {
+		t.Error("expected error for non-JSON secret string")
+	}
+}
+
+func TestSecretProviderFromEnvUnknown(t *testing.T) {
+	t.Setenv("ARIADNE_SECRETS_BACKEND", "bogus")
+	if _, err := SecretProviderFromEnv(); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
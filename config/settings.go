@@ -26,6 +26,30 @@ type LLMConfig struct {
 	Model       string
 	MaxTokens   uint32
 	Temperature float64
+	// Shared rate limit for this provider's model (0 = unlimited)
+	RequestsPerMinute int
+	TokensPerMinute   int
+	// Vertex AI project and location for gemini; empty for the API-key backend
+	VertexProject  string
+	VertexLocation string
+	// Validate checks the API key and model when the provider is created
+	Validate bool
+	// AnonymizeMap is a JSON map of identifiers hidden from this provider
+	// (see llm.LoadAnonymizer); empty sends them as they are
+	AnonymizeMap string
+	// HTTP client settings for this provider
+	HTTP HTTPConfig
+}
+
+// HTTPConfig holds a provider's HTTP client settings. Zero values keep the
+// defaults.
+type HTTPConfig struct {
+	RequestTimeoutSecs int // Whole request, including streaming (0 = none)
+	ConnectTimeoutSecs int
+	Proxy              string // Empty uses HTTP_PROXY/HTTPS_PROXY
+	CACert             string // PEM file trusted in addition to system roots
+	InsecureSkipVerify bool
+	Headers            map[string]string // Set on every request
 }
 
 // AgentConfig holds agent execution configuration.
@@ -33,6 +57,9 @@ type AgentConfig struct {
 	MaxIterations         int
 	MaxOrchestrationSteps int
 	MaxSubGoals           int
+	// Per-invocation limits for agents run by a supervisor (0 = unlimited)
+	MaxAgentTokens  uint32
+	MaxAgentSeconds int
 }
 
 // providerInfo holds configuration for a specific LLM provider.
@@ -40,19 +67,24 @@ type providerInfo struct {
 	modelEnv     string
 	defaultModel string
 	apiKeyEnv    string
+	// keyOptional marks providers that can authenticate without the key
+	// (Bedrock falls back to AWS credentials)
+	keyOptional bool
 }
 
 // Supported providers and their configuration.
 var providers = map[string]providerInfo{
-	"openai":    {"OPENAI_MODEL", "gpt-4o", "OPENAI_API_KEY"},
-	"anthropic": {"ANTHROPIC_MODEL", "claude-sonnet-4-20250514", "ANTHROPIC_API_KEY"},
-	"deepseek":  {"DEEPSEEK_MODEL", "deepseek-chat", "DEEPSEEK_API_KEY"},
-	"gemini":    {"GEMINI_MODEL", "gemini-2.5-flash", "GEMINI_API_KEY"},
+	"openai":    {"OPENAI_MODEL", "gpt-4o", "OPENAI_API_KEY", false},
+	"anthropic": {"ANTHROPIC_MODEL", "claude-sonnet-4-20250514", "ANTHROPIC_API_KEY", false},
+	"deepseek":  {"DEEPSEEK_MODEL", "deepseek-chat", "DEEPSEEK_API_KEY", false},
+	"gemini":    {"GEMINI_MODEL", "gemini-2.5-flash", "GEMINI_API_KEY", false},
+	"bedrock":   {"BEDROCK_MODEL", "anthropic.claude-sonnet-4-5-20250929-v1:0", "AWS_BEARER_TOKEN_BEDROCK", true},
 }
 
 // Provider aliases map to canonical names.
 var providerAliases = map[string]string{
 	"claude": "anthropic",
+	"aws":    "bedrock",
 	"google": "gemini",
 	"gpt":    "openai",
 }
@@ -77,17 +109,38 @@ func New(provider string) (Settings, error) {
 		return Settings{}, err
 	}
 
-	maxIterations, err := getEnvInt("AGENT_MAX_ITERATIONS", 10)
+	agent, err := Agent()
 	if err != nil {
 		return Settings{}, err
 	}
 
-	maxOrchestrationSteps, err := getEnvInt("AGENT_MAX_ORCHESTRATION_STEPS", 8)
+	// Provider-specific limits (OPENAI_REQUESTS_PER_MINUTE) override the
+	// LLM_* ones
+	requestsPerMinute, err := getProviderEnvInt(info, "REQUESTS_PER_MINUTE")
 	if err != nil {
 		return Settings{}, err
 	}
 
-	maxSubGoals, err := getEnvInt("AGENT_MAX_SUB_GOALS", 5)
+	tokensPerMinute, err := getProviderEnvInt(info, "TOKENS_PER_MINUTE")
+	if err != nil {
+		return Settings{}, err
+	}
+
+	validate, err := getEnvBool("LLM_VALIDATE", false)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	vertexProject, vertexLocation, err := VertexFor(provider)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	// OPENAI_ANONYMIZE_MAP overrides LLM_ANONYMIZE_MAP; set it empty to
+	// send one provider real names
+	anonymizeMap := getProviderEnv(info, "ANONYMIZE_MAP")
+
+	httpConfig, err := getHTTPConfig(info)
 	if err != nil {
 		return Settings{}, err
 	}
@@ -100,16 +153,56 @@ func New(provider string) (Settings, error) {
 
 	return Settings{
 		LLM: LLMConfig{
-			Provider:    provider,
-			Model:       model,
-			MaxTokens:   maxTokens,
-			Temperature: temperature,
-		},
-		Agent: AgentConfig{
-			MaxIterations:         maxIterations,
-			MaxOrchestrationSteps: maxOrchestrationSteps,
-			MaxSubGoals:           maxSubGoals,
+			Provider:          provider,
+			Model:             model,
+			MaxTokens:         maxTokens,
+			Temperature:       temperature,
+			RequestsPerMinute: requestsPerMinute,
+			TokensPerMinute:   tokensPerMinute,
+			VertexProject:     vertexProject,
+			VertexLocation:    vertexLocation,
+			Validate:          validate,
+			AnonymizeMap:      anonymizeMap,
+			HTTP:              httpConfig,
 		},
+		Agent: agent,
+	}, nil
+}
+
+// Agent returns the agent execution settings, which are the same for every
+// provider.
+func Agent() (AgentConfig, error) {
+	maxIterations, err := getEnvInt("AGENT_MAX_ITERATIONS", 10)
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
+	maxOrchestrationSteps, err := getEnvInt("AGENT_MAX_ORCHESTRATION_STEPS", 8)
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
+	maxSubGoals, err := getEnvInt("AGENT_MAX_SUB_GOALS", 5)
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
+	maxAgentTokens, err := getEnvUint32("AGENT_MAX_TOKENS", 0)
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
+	maxAgentSeconds, err := getEnvInt("AGENT_MAX_SECONDS", 0)
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
+	return AgentConfig{
+		MaxIterations:         maxIterations,
+		MaxOrchestrationSteps: maxOrchestrationSteps,
+		MaxSubGoals:           maxSubGoals,
+		MaxAgentTokens:        maxAgentTokens,
+		MaxAgentSeconds:       maxAgentSeconds,
 	}, nil
 }
 
@@ -142,7 +235,9 @@ func getProviderInfo(provider string) (providerInfo, error) {
 	return info, nil
 }
 
-// APIKeyFor returns the API key for a provider from environment variables.
+// APIKeyFor returns the API key for a provider from environment variables,
+// falling back to the secrets backend selected by ARIADNE_SECRETS_BACKEND.
+// For providers whose key is optional (bedrock), a missing key is "".
 func APIKeyFor(provider string) (string, error) {
 	provider = normalizeProvider(provider)
 
@@ -151,11 +246,106 @@ func APIKeyFor(provider string) (string, error) {
 		return "", err
 	}
 
-	key := os.Getenv(info.apiKeyEnv)
-	if key == "" {
-		return "", fmt.Errorf("%s environment variable not set", info.apiKeyEnv)
+	key, err := lookupAPIKey(info.apiKeyEnv)
+	if err != nil && (info.keyOptional || (provider == "gemini" && useVertex())) {
+		return "", nil
+	}
+	return key, err
+}
+
+// VertexFor returns the Vertex AI project and location when provider is
+// gemini and GOOGLE_GENAI_USE_VERTEXAI is set, or empty strings otherwise.
+// The project comes from GOOGLE_CLOUD_PROJECT and the location from
+// GOOGLE_CLOUD_LOCATION (default "global"); credentials are the
+// Application Default Credentials.
+func VertexFor(provider string) (project, location string, err error) {
+	if normalizeProvider(provider) != "gemini" || !useVertex() {
+		return "", "", nil
+	}
+	project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	if project == "" {
+		return "", "", fmt.Errorf("GOOGLE_GENAI_USE_VERTEXAI is set but GOOGLE_CLOUD_PROJECT is not")
+	}
+	return project, getEnvString("GOOGLE_CLOUD_LOCATION", "global"), nil
+}
+
+// Patterns that can have their own default provider (see DefaultProvider).
+const (
+	PatternReact       = "react"
+	PatternRLM         = "rlm"
+	PatternRLMSubagent = "rlm_subagent"
+	PatternSupervisor  = "supervisor"
+)
+
+// DefaultProvider returns the provider configured for pattern in
+// ARIADNE_<PATTERN>_PROVIDER (e.g. ARIADNE_RLM_SUBAGENT_PROVIDER), or ""
+// if there is none. The react and rlm patterns fall back to
+// ARIADNE_PROVIDER; sub-agents and supervisors fall back to the main
+// provider of their run instead, which the caller chooses.
+func DefaultProvider(pattern string) string {
+	if provider := os.Getenv("ARIADNE_" + strings.ToUpper(pattern) + "_PROVIDER"); provider != "" {
+		return provider
+	}
+	if pattern == PatternReact || pattern == PatternRLM {
+		return os.Getenv("ARIADNE_PROVIDER")
+	}
+	return ""
+}
+
+// ToolFilter returns the tool names in ARIADNE_TOOLS (the only tools
+// agents may use; empty = all) and ARIADNE_DENY_TOOLS (tools they may not
+// use), both comma-separated.
+func ToolFilter() (allow, deny []string) {
+	return splitList(os.Getenv("ARIADNE_TOOLS")), splitList(os.Getenv("ARIADNE_DENY_TOOLS"))
+}
+
+// EgressPolicyPath returns the egress policy file for network tools from
+// ARIADNE_EGRESS_POLICY, or "" if there is none.
+func EgressPolicyPath() string {
+	return os.Getenv("ARIADNE_EGRESS_POLICY")
+}
+
+// ExternalToolsDir returns the directory of external tool executables from
+// ARIADNE_EXTERNAL_TOOLS, or "" to load none.
+func ExternalToolsDir() string {
+	return os.Getenv("ARIADNE_EXTERNAL_TOOLS")
+}
+
+// Summarizer returns the result store summarizer named in
+// ARIADNE_SUMMARIZER (head, structure or llm), or "" for the default.
+func Summarizer() string {
+	return os.Getenv("ARIADNE_SUMMARIZER")
+}
+
+// AuditEnabled reports whether ARIADNE_AUDIT turns on the audit log of
+// mutating tool calls.
+func AuditEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("ARIADNE_AUDIT"))
+	return enabled
+}
+
+// MaskPIIEnabled reports whether ARIADNE_MASK_PII turns on masking of PII
+// in tool results.
+func MaskPIIEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("ARIADNE_MASK_PII"))
+	return enabled
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return key, nil
+	return items
+}
+
+// useVertex reports whether GOOGLE_GENAI_USE_VERTEXAI selects Vertex AI.
+func useVertex() bool {
+	use, _ := strconv.ParseBool(os.Getenv("GOOGLE_GENAI_USE_VERTEXAI"))
+	return use
 }
 
 // ModelFor returns the model for a provider, checking environment first.
@@ -196,6 +386,88 @@ func getEnvInt(key string, defaultVal int) (int, error) {
 	return i, nil
 }
 
+// getProviderEnvInt reads <PROVIDER>_<suffix>, falling back to LLM_<suffix>.
+func getProviderEnvInt(info providerInfo, suffix string) (int, error) {
+	fallback, err := getEnvInt("LLM_"+suffix, 0)
+	if err != nil {
+		return 0, err
+	}
+	prefix := strings.TrimSuffix(info.modelEnv, "MODEL")
+	return getEnvInt(prefix+suffix, fallback)
+}
+
+// getProviderEnv returns the provider-specific variable (OPENAI_ + suffix)
+// if it is set, even to "", else LLM_ + suffix.
+func getProviderEnv(info providerInfo, suffix string) string {
+	prefix := strings.TrimSuffix(info.modelEnv, "MODEL")
+	if val, ok := os.LookupEnv(prefix + suffix); ok {
+		return val
+	}
+	return os.Getenv("LLM_" + suffix)
+}
+
+// getHTTPConfig reads a provider's HTTP client settings. Each one is
+// <PROVIDER>_<NAME>, falling back to LLM_<NAME>: REQUEST_TIMEOUT and
+// CONNECT_TIMEOUT (seconds), PROXY, CA_CERT, TLS_INSECURE and HEADERS
+// ("Name=value,Name2=value2").
+func getHTTPConfig(info providerInfo) (HTTPConfig, error) {
+	requestTimeout, err := getProviderEnvInt(info, "REQUEST_TIMEOUT")
+	if err != nil {
+		return HTTPConfig{}, err
+	}
+	connectTimeout, err := getProviderEnvInt(info, "CONNECT_TIMEOUT")
+	if err != nil {
+		return HTTPConfig{}, err
+	}
+	if requestTimeout < 0 || connectTimeout < 0 {
+		return HTTPConfig{}, fmt.Errorf("HTTP timeouts for %s can't be negative", strings.TrimSuffix(info.modelEnv, "_MODEL"))
+	}
+	insecure, err := getProviderEnvBool(info, "TLS_INSECURE")
+	if err != nil {
+		return HTTPConfig{}, err
+	}
+	headers, err := parseHeaders(getProviderEnv(info, "HEADERS"))
+	if err != nil {
+		return HTTPConfig{}, err
+	}
+	return HTTPConfig{
+		RequestTimeoutSecs: requestTimeout,
+		ConnectTimeoutSecs: connectTimeout,
+		Proxy:              getProviderEnv(info, "PROXY"),
+		CACert:             getProviderEnv(info, "CA_CERT"),
+		InsecureSkipVerify: insecure,
+		Headers:            headers,
+	}, nil
+}
+
+// parseHeaders parses a comma-separated list of Name=value pairs.
+func parseHeaders(value string) (map[string]string, error) {
+	items := splitList(value)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(items))
+	for _, item := range items {
+		name, val, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q: expected Name=value", item)
+		}
+		headers[name] = strings.TrimSpace(val)
+	}
+	return headers, nil
+}
+
+// getProviderEnvBool reads <PROVIDER>_<suffix>, falling back to LLM_<suffix>.
+func getProviderEnvBool(info providerInfo, suffix string) (bool, error) {
+	fallback, err := getEnvBool("LLM_"+suffix, false)
+	if err != nil {
+		return false, err
+	}
+	prefix := strings.TrimSuffix(info.modelEnv, "MODEL")
+	return getEnvBool(prefix+suffix, fallback)
+}
+
 func getEnvUint32(key string, defaultVal uint32) (uint32, error) {
 	val := os.Getenv(key)
 	if val == "" {
@@ -208,6 +480,18 @@ func getEnvUint32(key string, defaultVal uint32) (uint32, error) {
 	return uint32(i), nil
 }
 
+func getEnvBool(key string, defaultVal bool) (bool, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal, nil
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %q: %w", key, val, err)
+	}
+	return b, nil
+}
+
 func getEnvFloat64(key string, defaultVal float64) (float64, error) {
 	val := os.Getenv(key)
 	if val == "" {
@@ -57,6 +57,41 @@ func TestAPIKeyForMissing(t *testing.T) {
 	}
 }
 
+func TestAPIKeyForOptionalKey(t *testing.T) {
+	t.Setenv("AWS_BEARER_TOKEN_BEDROCK", "")
+
+	key, err := APIKeyFor("aws")
+	if err != nil || key != "" {
+		t.Errorf("bedrock without a key should fall back to AWS credentials, got %q, %v", key, err)
+	}
+}
+
+func TestVertexFor(t *testing.T) {
+	t.Setenv("GOOGLE_GENAI_USE_VERTEXAI", "true")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	t.Setenv("GOOGLE_CLOUD_LOCATION", "")
+	t.Setenv("GEMINI_API_KEY", "")
+
+	if _, err := New("gemini"); err == nil {
+		t.Error("expected error when Vertex AI has no project")
+	}
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+	settings, err := New("google")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.LLM.VertexProject != "my-project" || settings.LLM.VertexLocation != "global" {
+		t.Errorf("unexpected Vertex settings: %+v", settings.LLM)
+	}
+	if key, err := APIKeyFor("gemini"); err != nil || key != "" {
+		t.Errorf("Vertex AI needs no API key, got %q, %v", key, err)
+	}
+	if project, _, _ := VertexFor("openai"); project != "" {
+		t.Error("Vertex AI only applies to gemini")
+	}
+}
+
 func TestAPIKeyForUnknownProvider(t *testing.T) {
 	_, err := APIKeyFor("unknown")
 	if err == nil {
@@ -85,6 +120,104 @@ func TestNewWithInvalidEnvVar(t *testing.T) {
 	}
 }
 
+func TestNewAgentBudgets(t *testing.T) {
+	t.Setenv("AGENT_MAX_TOKENS", "20000")
+	t.Setenv("AGENT_MAX_SECONDS", "90")
+
+	settings, err := New("openai")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.Agent.MaxAgentTokens != 20000 || settings.Agent.MaxAgentSeconds != 90 {
+		t.Errorf("unexpected agent budget: %+v", settings.Agent)
+	}
+}
+
+func TestNewRateLimits(t *testing.T) {
+	t.Setenv("LLM_REQUESTS_PER_MINUTE", "60")
+	t.Setenv("LLM_TOKENS_PER_MINUTE", "100000")
+	t.Setenv("ANTHROPIC_REQUESTS_PER_MINUTE", "20")
+
+	openai, err := New("openai")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if openai.LLM.RequestsPerMinute != 60 || openai.LLM.TokensPerMinute != 100000 {
+		t.Errorf("unexpected openai limits: %+v", openai.LLM)
+	}
+
+	anthropic, err := New("claude")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if anthropic.LLM.RequestsPerMinute != 20 || anthropic.LLM.TokensPerMinute != 100000 {
+		t.Errorf("provider limit should override LLM_*: %+v", anthropic.LLM)
+	}
+}
+
+func TestNewAnonymizeMap(t *testing.T) {
+	t.Setenv("LLM_ANONYMIZE_MAP", "anonymize.json")
+	t.Setenv("ANTHROPIC_ANONYMIZE_MAP", "")
+
+	openai, err := New("openai")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if openai.LLM.AnonymizeMap != "anonymize.json" {
+		t.Errorf("expected LLM_ANONYMIZE_MAP, got %q", openai.LLM.AnonymizeMap)
+	}
+
+	anthropic, err := New("anthropic")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if anthropic.LLM.AnonymizeMap != "" {
+		t.Errorf("empty provider setting should turn the map off, got %q", anthropic.LLM.AnonymizeMap)
+	}
+}
+
+func TestNewHTTPConfig(t *testing.T) {
+	t.Setenv("LLM_REQUEST_TIMEOUT", "120")
+	t.Setenv("LLM_HEADERS", "X-Team=search, X-Route=a=b")
+	t.Setenv("DEEPSEEK_REQUEST_TIMEOUT", "30")
+	t.Setenv("DEEPSEEK_PROXY", "http://proxy.internal:3128")
+	t.Setenv("DEEPSEEK_TLS_INSECURE", "true")
+
+	openai, err := New("openai")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if openai.LLM.HTTP.RequestTimeoutSecs != 120 || openai.LLM.HTTP.Proxy != "" || openai.LLM.HTTP.InsecureSkipVerify {
+		t.Errorf("unexpected openai HTTP config: %+v", openai.LLM.HTTP)
+	}
+	if openai.LLM.HTTP.Headers["X-Team"] != "search" || openai.LLM.HTTP.Headers["X-Route"] != "a=b" {
+		t.Errorf("unexpected headers: %v", openai.LLM.HTTP.Headers)
+	}
+
+	deepseek, err := New("deepseek")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deepseek.LLM.HTTP.RequestTimeoutSecs != 30 || deepseek.LLM.HTTP.Proxy != "http://proxy.internal:3128" || !deepseek.LLM.HTTP.InsecureSkipVerify {
+		t.Errorf("provider settings should override LLM_*: %+v", deepseek.LLM.HTTP)
+	}
+}
+
+func TestNewHTTPConfigInvalid(t *testing.T) {
+	for name, value := range map[string]string{
+		"LLM_HEADERS":         "no-equals-sign",
+		"LLM_CONNECT_TIMEOUT": "-5",
+		"OPENAI_TLS_INSECURE": "maybe",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := New("openai"); err == nil {
+				t.Errorf("expected error for %s=%q", name, value)
+			}
+		})
+	}
+}
+
 func TestMustNewPanics(t *testing.T) {
 	defer func() {
 		if r := recover(); r == nil {
@@ -100,3 +233,52 @@ func TestSupportedProviders(t *testing.T) {
 		t.Error("expected at least one supported provider")
 	}
 }
+
+func TestValidateSetting(t *testing.T) {
+	t.Setenv("LLM_VALIDATE", "true")
+	settings, err := New("openai")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !settings.LLM.Validate {
+		t.Error("LLM_VALIDATE should enable validation")
+	}
+
+	t.Setenv("LLM_VALIDATE", "maybe")
+	if _, err := New("openai"); err == nil {
+		t.Error("expected error for an invalid LLM_VALIDATE")
+	}
+}
+
+func TestDefaultProvider(t *testing.T) {
+	t.Setenv("ARIADNE_PROVIDER", "openai")
+	t.Setenv("ARIADNE_RLM_PROVIDER", "anthropic")
+	t.Setenv("ARIADNE_RLM_SUBAGENT_PROVIDER", "deepseek")
+	t.Setenv("ARIADNE_REACT_PROVIDER", "")
+	t.Setenv("ARIADNE_SUPERVISOR_PROVIDER", "")
+
+	tests := map[string]string{
+		PatternReact:       "openai",
+		PatternRLM:         "anthropic",
+		PatternRLMSubagent: "deepseek",
+		PatternSupervisor:  "", // falls back to the run's provider, not ARIADNE_PROVIDER
+	}
+	for pattern, want := range tests {
+		if got := DefaultProvider(pattern); got != want {
+			t.Errorf("DefaultProvider(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestToolFilter(t *testing.T) {
+	t.Setenv("ARIADNE_TOOLS", "")
+	t.Setenv("ARIADNE_DENY_TOOLS", "execute_shell, http_request,")
+
+	allow, deny := ToolFilter()
+	if len(allow) != 0 {
+		t.Errorf("expected no allowlist, got %v", allow)
+	}
+	if len(deny) != 2 || deny[0] != "execute_shell" || deny[1] != "http_request" {
+		t.Errorf("unexpected denylist: %v", deny)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/richinex/ariadne/internal/text"
 )
 
 // extractJSON finds and returns the JSON portion of a response string.
@@ -44,10 +46,7 @@ func extractJSON(response string) (string, error) {
 	}
 
 	// Create a preview for the error message
-	preview := response
-	if len(preview) > 100 {
-		preview = preview[:100] + "..."
-	}
+	preview := text.Truncate(response, 100)
 	return "", fmt.Errorf("failed to extract valid JSON from response: %q", preview)
 }
 
@@ -1,6 +1,7 @@
 package json
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -85,3 +86,31 @@ func TestInvalidJSON(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestDecodeValidated(t *testing.T) {
+	requireName := func(v TestStruct) error {
+		if v.Name == "" {
+			return errors.New("name is required")
+		}
+		return nil
+	}
+
+	result, err := DecodeValidated("```json\n{\"name\": \"test\", \"value\": 1}\n```", requireName)
+	if err != nil || result.Name != "test" {
+		t.Fatalf("expected valid decode, got %+v, %v", result, err)
+	}
+
+	for response, want := range map[string]string{
+		"no json here":                   "no JSON object",
+		`{"name": "test", "value": "x"}`: "does not match",
+		`{"value": 1}`:                   "name is required",
+	} {
+		_, err := DecodeValidated(response, requireName)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("DecodeValidated(%q) error = %v, want %q", response, err, want)
+		}
+		if prompt := RepairPrompt(err); !strings.Contains(prompt, want) {
+			t.Errorf("repair prompt does not name the problem: %q", prompt)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DecodeValidated extracts JSON from an LLM response into a T and checks it
+// with validate (which may be nil). Errors describe what was wrong in terms
+// the model can act on, so they can be fed back with RepairPrompt.
+func DecodeValidated[T any](response string, validate func(T) error) (T, error) {
+	var result T
+	jsonStr, err := extractJSON(response)
+	if err != nil {
+		return result, fmt.Errorf("no JSON object found in the reply")
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return result, fmt.Errorf("JSON does not match the required format: %w", err)
+	}
+	if validate != nil {
+		if err := validate(result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// RepairPrompt is the follow-up sent when a reply failed DecodeValidated.
+// It names the problem so the model fixes that rather than starting over.
+func RepairPrompt(err error) string {
+	return fmt.Sprintf(
+		"Your last reply could not be used: %v.\nReply again with only the corrected JSON object in the required format. No extra text.",
+		err,
+	)
+}
@@ -0,0 +1,358 @@
+// Package outline extracts a structural outline from source files:
+// package, imports, and exported symbols with line ranges.
+//
+// Go files are parsed with go/parser. Python and TypeScript/JavaScript use
+// lightweight line-based parsing, which is approximate but needs no
+// external toolchain.
+//
+// Information Hiding:
+// - Per-language parsing hidden behind Parse
+// - Symbol line range heuristics hidden
+package outline
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Supported language names.
+const (
+	LangGo         = "go"
+	LangPython     = "python"
+	LangTypeScript = "typescript"
+	LangJavaScript = "javascript"
+)
+
+// maxSymbols caps how many symbols String renders.
+const maxSymbols = 40
+
+// maxImports caps how many imports String renders.
+const maxImports = 15
+
+// maxSignatureLines is how far a TS/JS declaration is scanned for its opening brace.
+const maxSignatureLines = 10
+
+// Symbol is an exported declaration.
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"` // func, method, type, const, var, class, interface, enum
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// Outline is the structure of one source file.
+type Outline struct {
+	Language string   `json:"language"`
+	Package  string   `json:"package,omitempty"`
+	Imports  []string `json:"imports"`
+	Symbols  []Symbol `json:"symbols"`
+}
+
+// Language returns the outline language for a path, or "" if unsupported.
+func Language(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return LangGo
+	case ".py", ".pyi":
+		return LangPython
+	case ".ts", ".tsx", ".mts", ".cts":
+		return LangTypeScript
+	case ".js", ".jsx", ".mjs", ".cjs":
+		return LangJavaScript
+	}
+	return ""
+}
+
+// Parse builds an outline for content. Returns false for unsupported
+// languages or content that yields nothing useful.
+func Parse(path, content string) (*Outline, bool) {
+	var o *Outline
+	switch lang := Language(path); lang {
+	case LangGo:
+		o = parseGo(path, content)
+	case LangPython:
+		o = parsePython(content)
+	case LangTypeScript, LangJavaScript:
+		o = parseScript(content)
+		if o != nil {
+			o.Language = lang
+		}
+	}
+	if o == nil || (o.Package == "" && len(o.Imports) == 0 && len(o.Symbols) == 0) {
+		return nil, false
+	}
+	return o, true
+}
+
+// String renders a compact outline suitable as a stored-content summary.
+func (o *Outline) String() string {
+	var sb strings.Builder
+	sb.WriteString("[" + o.Language + " outline]")
+	if o.Package != "" {
+		sb.WriteString(" package " + o.Package)
+	}
+	sb.WriteString("\n")
+
+	if len(o.Imports) > 0 {
+		imports := o.Imports
+		more := ""
+		if len(imports) > maxImports {
+			more = fmt.Sprintf(", ... (%d total)", len(imports))
+			imports = imports[:maxImports]
+		}
+		sb.WriteString("imports: " + strings.Join(imports, ", ") + more + "\n")
+	}
+
+	if len(o.Symbols) > 0 {
+		sb.WriteString("exports:\n")
+		for i, sym := range o.Symbols {
+			if i == maxSymbols {
+				sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(o.Symbols)-maxSymbols))
+				break
+			}
+			if sym.EndLine > sym.StartLine {
+				sb.WriteString(fmt.Sprintf("  %s %s L%d-%d\n", sym.Kind, sym.Name, sym.StartLine, sym.EndLine))
+			} else {
+				sb.WriteString(fmt.Sprintf("  %s %s L%d\n", sym.Kind, sym.Name, sym.StartLine))
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// parseGo uses go/parser. Files with syntax errors still yield whatever
+// was parsed before the error.
+func parseGo(path, content string) *Outline {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, path, content, parser.SkipObjectResolution)
+	if file == nil {
+		return nil
+	}
+
+	o := &Outline{Language: LangGo, Package: file.Name.Name}
+	for _, imp := range file.Imports {
+		o.Imports = append(o.Imports, strings.Trim(imp.Path.Value, "\"`"))
+	}
+
+	lines := func(node ast.Node) (int, int) {
+		return fset.Position(node.Pos()).Line, fset.Position(node.End()).Line
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			start, end := lines(d)
+			sym := Symbol{Name: d.Name.Name, Kind: "func", StartLine: start, EndLine: end}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				sym.Kind = "method"
+				sym.Name = receiverName(d.Recv.List[0].Type) + "." + d.Name.Name
+			}
+			o.Symbols = append(o.Symbols, sym)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						start, end := lines(s)
+						o.Symbols = append(o.Symbols, Symbol{Name: s.Name.Name, Kind: "type", StartLine: start, EndLine: end})
+					}
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range s.Names {
+						if name.IsExported() {
+							start, end := lines(s)
+							o.Symbols = append(o.Symbols, Symbol{Name: name.Name, Kind: kind, StartLine: start, EndLine: end})
+						}
+					}
+				}
+			}
+		}
+	}
+	return o
+}
+
+// receiverName renders a method receiver type such as "*Server" or "List[T]".
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	}
+	return "?"
+}
+
+var (
+	pyImport     = regexp.MustCompile(`^import\s+(.+)`)
+	pyFromImport = regexp.MustCompile(`^from\s+(\S+)\s+import\b`)
+	pyDef        = regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)`)
+	pyClass      = regexp.MustCompile(`^class\s+(\w+)`)
+)
+
+// parsePython outlines top-level imports, functions and classes. A symbol
+// ends before the next top-level statement.
+func parsePython(content string) *Outline {
+	o := &Outline{Language: LangPython}
+	lines := splitLines(content)
+
+	open := -1 // Index in o.Symbols of the symbol whose end is not yet known
+	lastBody := 0
+	for i, line := range lines {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' || line[0] == ')' || line[0] == ']' || line[0] == '}' {
+			lastBody = lineNo
+			continue
+		}
+		if strings.HasPrefix(trimmed, "@") {
+			// Decorator: belongs to the next def/class, closes the previous one
+			if open >= 0 {
+				o.Symbols[open].EndLine = lastBody
+				open = -1
+			}
+			lastBody = lineNo
+			continue
+		}
+
+		// A new top-level statement closes the previous symbol
+		if open >= 0 {
+			o.Symbols[open].EndLine = lastBody
+			open = -1
+		}
+		lastBody = lineNo
+
+		if m := pyImport.FindStringSubmatch(line); m != nil {
+			for _, part := range strings.Split(m[1], ",") {
+				name, _, _ := strings.Cut(strings.TrimSpace(part), " ")
+				if name != "" {
+					o.Imports = append(o.Imports, name)
+				}
+			}
+			continue
+		}
+		if m := pyFromImport.FindStringSubmatch(line); m != nil {
+			o.Imports = append(o.Imports, m[1])
+			continue
+		}
+
+		name, kind := "", ""
+		if m := pyDef.FindStringSubmatch(line); m != nil {
+			name, kind = m[1], "func"
+		} else if m := pyClass.FindStringSubmatch(line); m != nil {
+			name, kind = m[1], "class"
+		}
+		if name != "" && !strings.HasPrefix(name, "_") {
+			o.Symbols = append(o.Symbols, Symbol{Name: name, Kind: kind, StartLine: lineNo, EndLine: lineNo})
+			open = len(o.Symbols) - 1
+		}
+	}
+	if open >= 0 {
+		o.Symbols[open].EndLine = lastBody
+	}
+	return o
+}
+
+var (
+	scriptImportFrom = regexp.MustCompile(`^\s*(?:import|export)\b[^'"]*\bfrom\s+['"]([^'"]+)['"]`)
+	scriptImportBare = regexp.MustCompile(`^\s*import\s+['"]([^'"]+)['"]`)
+	scriptRequire    = regexp.MustCompile(`\brequire\(\s*['"]([^'"]+)['"]\s*\)`)
+	scriptExport     = regexp.MustCompile(`^export\s+(?:default\s+)?(?:declare\s+)?(?:async\s+)?(?:abstract\s+)?(function\*?|class|const|let|var|interface|type|enum)\s+([A-Za-z_$][\w$]*)`)
+)
+
+// parseScript outlines TypeScript/JavaScript imports and top-level exports.
+// A symbol's range runs until its braces balance.
+func parseScript(content string) *Outline {
+	o := &Outline{}
+	lines := splitLines(content)
+	seen := make(map[string]bool)
+
+	addImport := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			o.Imports = append(o.Imports, path)
+		}
+	}
+
+	for i, line := range lines {
+		if m := scriptImportFrom.FindStringSubmatch(line); m != nil {
+			addImport(m[1])
+		} else if m := scriptImportBare.FindStringSubmatch(line); m != nil {
+			addImport(m[1])
+		}
+		for _, m := range scriptRequire.FindAllStringSubmatch(line, -1) {
+			addImport(m[1])
+		}
+
+		if m := scriptExport.FindStringSubmatch(line); m != nil {
+			kind := strings.TrimSuffix(m[1], "*")
+			switch kind {
+			case "function":
+				kind = "func"
+			case "let", "var":
+				kind = "var"
+			}
+			o.Symbols = append(o.Symbols, Symbol{Name: m[2], Kind: kind, StartLine: i + 1, EndLine: braceEnd(lines, i) + 1})
+		}
+	}
+	return o
+}
+
+// braceEnd returns the index of the line where braces opened at or after
+// start balance. Declarations without braces end on their own line.
+func braceEnd(lines []string, start int) int {
+	depth := 0
+	opened := false
+	for i := start; i < len(lines); i++ {
+		if i > start && !opened && (strings.HasPrefix(lines[i], "export") || strings.HasPrefix(lines[i], "import")) {
+			return i - 1
+		}
+		for _, r := range lines[i] {
+			switch r {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i
+		}
+		if !opened && strings.HasSuffix(strings.TrimSpace(lines[i]), ";") {
+			return i
+		}
+		if !opened && i >= start+maxSignatureLines {
+			return start
+		}
+	}
+	return start
+}
+
+func splitLines(content string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
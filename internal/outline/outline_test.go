@@ -0,0 +1,134 @@
+package outline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGo(t *testing.T) {
+	src := `package server
+
+import (
+	"context"
+	"net/http"
+)
+
+// Server serves requests.
+type Server struct {
+	addr string
+}
+
+const DefaultAddr = ":8080"
+
+func New(addr string) *Server {
+	return &Server{addr: addr}
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	return http.ListenAndServe(s.addr, nil)
+}
+
+func helper() {}
+`
+	o, ok := Parse("server.go", src)
+	if !ok {
+		t.Fatal("expected Go outline")
+	}
+	if o.Package != "server" || len(o.Imports) != 2 || o.Imports[1] != "net/http" {
+		t.Errorf("unexpected package/imports: %q %v", o.Package, o.Imports)
+	}
+
+	want := map[string][2]int{"Server": {9, 11}, "DefaultAddr": {13, 13}, "New": {15, 17}, "*Server.Start": {19, 21}}
+	if len(o.Symbols) != len(want) {
+		t.Fatalf("expected %d symbols, got %+v", len(want), o.Symbols)
+	}
+	for _, sym := range o.Symbols {
+		lines, found := want[sym.Name]
+		if !found || sym.StartLine != lines[0] || sym.EndLine != lines[1] {
+			t.Errorf("unexpected symbol %+v", sym)
+		}
+	}
+
+	rendered := o.String()
+	if !strings.Contains(rendered, "method *Server.Start L19-21") || strings.Contains(rendered, "helper") {
+		t.Errorf("unexpected rendering:\n%s", rendered)
+	}
+}
+
+func TestParsePython(t *testing.T) {
+	src := `import os, sys as system
+from collections import defaultdict
+
+def load(path):
+    with open(path) as f:
+        return f.read()
+
+def _private():
+    pass
+
+@dataclass
+class Config:
+    name: str
+
+VERSION = "1"
+`
+	o, ok := Parse("app.py", src)
+	if !ok {
+		t.Fatal("expected Python outline")
+	}
+	if strings.Join(o.Imports, ",") != "os,sys,collections" {
+		t.Errorf("unexpected imports %v", o.Imports)
+	}
+	if len(o.Symbols) != 2 {
+		t.Fatalf("expected load and Config, got %+v", o.Symbols)
+	}
+	if o.Symbols[0].Name != "load" || o.Symbols[0].StartLine != 4 || o.Symbols[0].EndLine != 6 {
+		t.Errorf("unexpected load symbol %+v", o.Symbols[0])
+	}
+	if o.Symbols[1].Name != "Config" || o.Symbols[1].StartLine != 12 || o.Symbols[1].EndLine != 13 {
+		t.Errorf("unexpected Config symbol %+v", o.Symbols[1])
+	}
+}
+
+func TestParseTypeScript(t *testing.T) {
+	src := `import { useState } from 'react';
+import './styles.css';
+const fs = require("fs");
+
+export interface Props {
+  name: string;
+}
+
+export const LIMIT = 10;
+
+export default function App(props: Props) {
+  return null;
+}
+`
+	o, ok := Parse("App.tsx", src)
+	if !ok {
+		t.Fatal("expected TypeScript outline")
+	}
+	if o.Language != LangTypeScript || strings.Join(o.Imports, ",") != "react,./styles.css,fs" {
+		t.Errorf("unexpected language/imports %q %v", o.Language, o.Imports)
+	}
+	want := []Symbol{
+		{Name: "Props", Kind: "interface", StartLine: 5, EndLine: 7},
+		{Name: "LIMIT", Kind: "const", StartLine: 9, EndLine: 9},
+		{Name: "App", Kind: "func", StartLine: 11, EndLine: 13},
+	}
+	if len(o.Symbols) != len(want) {
+		t.Fatalf("expected %d symbols, got %+v", len(want), o.Symbols)
+	}
+	for i, sym := range o.Symbols {
+		if sym != want[i] {
+			t.Errorf("symbol %d: expected %+v, got %+v", i, want[i], sym)
+		}
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	if _, ok := Parse("README.md", "# Title"); ok {
+		t.Error("expected no outline for markdown")
+	}
+}
@@ -0,0 +1,160 @@
+// Package text shortens and measures text for display and summaries.
+//
+// Previews of tool output, results and memories are cut to a size before
+// they are shown or put in a prompt. Cutting at a byte offset can split a
+// multi-byte character and leave invalid UTF-8; these helpers cut at
+// character boundaries instead. Terminal output is measured in columns,
+// where wide (CJK, emoji) characters take two and combining marks none.
+//
+// Information Hiding:
+// - Character boundary handling hidden behind Truncate, Head and Tail
+// - Column widths of Unicode ranges hidden behind Width and Clip
+package text
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ellipsis marks text that Truncate or TruncateRunes cut.
+const Ellipsis = "..."
+
+// Truncate returns s cut to at most n bytes, ending on a character
+// boundary, with Ellipsis appended if anything was cut.
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return Head(s, n) + Ellipsis
+}
+
+// TruncateRunes returns s cut to at most n characters, with Ellipsis
+// appended if anything was cut.
+func TruncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	i := 0
+	for range n {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return s[:i] + Ellipsis
+}
+
+// Head returns the longest prefix of s that has at most n bytes and ends
+// on a character boundary.
+func Head(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	n = max(n, 0)
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// Tail returns the longest suffix of s that has at most n bytes and
+// starts on a character boundary.
+func Tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := len(s) - max(n, 0)
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return s[i:]
+}
+
+// NormalizeNewlines converts CRLF and lone CR line endings to LF.
+func NormalizeNewlines(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+// OneLine collapses runs of whitespace, newlines included, to single
+// spaces, for previews shown on one line.
+func OneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Width returns the number of terminal columns s takes. It does not skip
+// escape sequences.
+func Width(s string) int {
+	width := 0
+	for _, r := range s {
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// RuneWidth returns the number of terminal columns r takes: 0 for
+// combining marks and control or format characters, 2 for wide
+// characters, 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc):
+		return 0
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Clip returns s cut to at most width columns, ending in "…" if anything
+// was cut.
+func Clip(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	used := 0
+	for i, r := range s {
+		w := RuneWidth(r)
+		if used+w > width-1 {
+			return s[:i] + "…"
+		}
+		used += w
+	}
+	return s
+}
+
+// wideRanges are the ranges of East Asian wide and fullwidth characters
+// and emoji that terminals draw two columns wide.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},   // Hangul Jamo initials
+	{0x2E80, 0x303E},   // CJK radicals, Kangxi, CJK symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE30, 0xFE4F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F300, 0x1F64F}, // Pictographs and emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x1FA70, 0x1FAFF}, // Symbols and pictographs extended-A
+	{0x20000, 0x3FFFD}, // CJK Extensions B and later
+}
+
+func isWide(r rune) bool {
+	if r < wideRanges[0].lo {
+		return false
+	}
+	for _, wr := range wideRanges {
+		if r >= wr.lo && r <= wr.hi {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package text
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsCharacters(t *testing.T) {
+	s := "héllo wörld" // é and ö are 2 bytes each
+	for n := 0; n <= len(s); n++ {
+		got := Truncate(s, n)
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) = %q, invalid UTF-8", s, n, got)
+		}
+		if len(got) > n+len(Ellipsis) {
+			t.Errorf("Truncate(%q, %d) = %q, too long", s, n, got)
+		}
+	}
+	if got := Truncate("héllo", 2); got != "h..." {
+		t.Errorf("Truncate cut inside é: %q", got)
+	}
+	if got := Truncate("short", 10); got != "short" {
+		t.Errorf("Truncate changed short text: %q", got)
+	}
+	if got := TruncateRunes("日本語テキスト", 3); got != "日本語..." {
+		t.Errorf("TruncateRunes() = %q", got)
+	}
+}
+
+func TestHeadTail(t *testing.T) {
+	s := "a日b" // 日 is 3 bytes
+	if got := Head(s, 2); got != "a" {
+		t.Errorf("Head() = %q", got)
+	}
+	if got := Tail(s, 2); got != "b" {
+		t.Errorf("Tail() = %q", got)
+	}
+	if got := Tail(s, 4); got != "日b" {
+		t.Errorf("Tail() = %q", got)
+	}
+}
+
+func TestWidthAndClip(t *testing.T) {
+	cases := []struct {
+		s     string
+		width int
+	}{
+		{"abc", 3},
+		{"日本", 4},
+		{"é", 1}, // e + combining acute
+		{"ok 👍", 5},
+	}
+	for _, c := range cases {
+		if got := Width(c.s); got != c.width {
+			t.Errorf("Width(%q) = %d, want %d", c.s, got, c.width)
+		}
+	}
+
+	if got := Clip("日本語テキスト", 7); got != "日本語…" {
+		t.Errorf("Clip() = %q", got)
+	}
+	if got := Clip("abcdef", 6); got != "abcdef" {
+		t.Errorf("Clip() changed text that fits: %q", got)
+	}
+}
+
+func TestNewlines(t *testing.T) {
+	if got := NormalizeNewlines("a\r\nb\rc\n"); got != "a\nb\nc\n" {
+		t.Errorf("NormalizeNewlines() = %q", got)
+	}
+	if got := OneLine("  a\n\tb  c "); got != "a b c" {
+		t.Errorf("OneLine() = %q", got)
+	}
+}
@@ -0,0 +1,272 @@
+// Reversible anonymization of code identifiers.
+//
+// Teams that can't send proprietary names to an external provider list
+// them in a local map file. An Anonymizer replaces each name with a
+// consistent pseudonym in every message sent, and a provider wrapped with
+// it (or built with ProviderBuilder.Anonymizer) puts the real names back
+// in responses and tool call arguments, so tools still see real paths
+// and symbols:
+//
+//	anon, err := llm.LoadAnonymizer("anonymize.json")
+//	provider, err := llm.ProviderOpenAI.Model(llm.ModelOpenAIGPT52).
+//	    Anonymizer(anon).
+//	    FromEnv()
+//
+// Information Hiding:
+// - Pseudonym scheme (per kind, numbered in map order)
+// - Whole-word matching and longest-name precedence
+// - Holding back partial words while streaming
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// AnonymizeMap lists the identifiers to hide, read from a JSON file:
+//
+//	{"packages": ["acmebilling"], "files": ["ledger_sync.go"],
+//	 "symbols": ["ReconcileInvoices"], "aliases": {"AcmeCorp": "VendorCo"}}
+type AnonymizeMap struct {
+	Packages []string `json:"packages,omitempty"` // Become anonpkg1, anonpkg2, ...
+	Files    []string `json:"files,omitempty"`    // Become anonfile1.go, ... (extension kept)
+	Symbols  []string `json:"symbols,omitempty"`  // Become AnonSym1 or anonSym1 (case of first letter kept)
+	// Aliases are identifiers with a chosen pseudonym.
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+// anonymizeIdent is the form of an identifier that can be anonymized:
+// word characters at both ends, so it matches as a whole word, and nothing
+// that needs escaping in JSON.
+var anonymizeIdent = regexp.MustCompile(`^[A-Za-z0-9_](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?$`)
+
+// Anonymizer maps identifiers to pseudonyms and back. Safe for concurrent
+// use.
+type Anonymizer struct {
+	forward  map[string]string // Identifier -> pseudonym
+	reverse  map[string]string // Pseudonym -> identifier
+	forwardR *regexp.Regexp
+	reverseR *regexp.Regexp
+}
+
+// LoadAnonymizer reads an AnonymizeMap from a JSON file.
+func LoadAnonymizer(path string) (*Anonymizer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anonymize map: %w", err)
+	}
+	var m AnonymizeMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse anonymize map: %w", err)
+	}
+	return NewAnonymizer(m)
+}
+
+// NewAnonymizer builds an Anonymizer from m. Pseudonyms are numbered in
+// list order, so the same map gives the same pseudonyms in every run.
+func NewAnonymizer(m AnonymizeMap) (*Anonymizer, error) {
+	a := &Anonymizer{forward: make(map[string]string), reverse: make(map[string]string)}
+
+	aliases := make([]string, 0, len(m.Aliases))
+	for ident := range m.Aliases {
+		aliases = append(aliases, ident)
+	}
+	sort.Strings(aliases)
+	for _, ident := range aliases {
+		if err := a.add(ident, m.Aliases[ident]); err != nil {
+			return nil, err
+		}
+	}
+	for i, ident := range m.Packages {
+		if err := a.add(ident, fmt.Sprintf("anonpkg%d", i+1)); err != nil {
+			return nil, err
+		}
+	}
+	for i, ident := range m.Files {
+		if err := a.add(ident, fmt.Sprintf("anonfile%d%s", i+1, path.Ext(ident))); err != nil {
+			return nil, err
+		}
+	}
+	for i, ident := range m.Symbols {
+		pseudonym := fmt.Sprintf("anonSym%d", i+1)
+		if unicode.IsUpper(rune(ident[0])) {
+			pseudonym = fmt.Sprintf("AnonSym%d", i+1)
+		}
+		if err := a.add(ident, pseudonym); err != nil {
+			return nil, err
+		}
+	}
+	if len(a.forward) == 0 {
+		return nil, fmt.Errorf("anonymize map lists no identifiers")
+	}
+
+	a.forwardR = wordsPattern(a.forward)
+	a.reverseR = wordsPattern(a.reverse)
+	return a, nil
+}
+
+// add records one identifier and its pseudonym.
+func (a *Anonymizer) add(ident, pseudonym string) error {
+	if ident == "" {
+		return nil
+	}
+	if !anonymizeIdent.MatchString(ident) {
+		return fmt.Errorf("anonymize map: %q is not an identifier or file name", ident)
+	}
+	if !anonymizeIdent.MatchString(pseudonym) {
+		return fmt.Errorf("anonymize map: pseudonym %q for %q is not an identifier", pseudonym, ident)
+	}
+	if existing, ok := a.forward[ident]; ok && existing != pseudonym {
+		return fmt.Errorf("anonymize map: %q is listed twice", ident)
+	}
+	if existing, ok := a.reverse[pseudonym]; ok && existing != ident {
+		return fmt.Errorf("anonymize map: %q and %q have the same pseudonym %q", existing, ident, pseudonym)
+	}
+	a.forward[ident] = pseudonym
+	a.reverse[pseudonym] = ident
+	return nil
+}
+
+// wordsPattern matches any key of words as a whole word, preferring the
+// longest.
+func wordsPattern(words map[string]string) *regexp.Regexp {
+	keys := make([]string, 0, len(words))
+	for word := range words {
+		keys = append(keys, regexp.QuoteMeta(word))
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	re := regexp.MustCompile(`\b(?:` + strings.Join(keys, "|") + `)\b`)
+	re.Longest()
+	return re
+}
+
+// Anonymize replaces identifiers in text with their pseudonyms. Safe on
+// nil.
+func (a *Anonymizer) Anonymize(text string) string {
+	if a == nil || text == "" {
+		return text
+	}
+	return a.forwardR.ReplaceAllStringFunc(text, func(ident string) string { return a.forward[ident] })
+}
+
+// Restore replaces pseudonyms in text with the identifiers they stand
+// for. Safe on nil.
+func (a *Anonymizer) Restore(text string) string {
+	if a == nil || text == "" {
+		return text
+	}
+	return a.reverseR.ReplaceAllStringFunc(text, func(pseudonym string) string { return a.reverse[pseudonym] })
+}
+
+// Wrap returns p with identifiers anonymized in requests and restored in
+// responses. Tool definitions are sent unchanged.
+func (a *Anonymizer) Wrap(p Provider) Provider {
+	return &anonymizedProvider{Provider: p, anon: a}
+}
+
+// anonymizeMessages returns a copy of messages with identifiers replaced.
+func (a *Anonymizer) anonymizeMessages(messages []ChatMessage) []ChatMessage {
+	out := make([]ChatMessage, len(messages))
+	for i, m := range messages {
+		m.Content = a.Anonymize(m.Content)
+		m.ToolCalls = a.mapToolCalls(m.ToolCalls, a.Anonymize)
+		out[i] = m
+	}
+	return out
+}
+
+// restoreResponse puts identifiers back into a response.
+func (a *Anonymizer) restoreResponse(response LLMResponse) LLMResponse {
+	response.Content = a.Restore(response.Content)
+	response.ToolCalls = a.mapToolCalls(response.ToolCalls, a.Restore)
+	return response
+}
+
+// mapToolCalls returns a copy of calls with f applied to their arguments.
+// Identifiers and pseudonyms need no JSON escaping, so the arguments stay
+// valid JSON.
+func (a *Anonymizer) mapToolCalls(calls []ToolCall, f func(string) string) []ToolCall {
+	if len(calls) == 0 {
+		return calls
+	}
+	out := make([]ToolCall, len(calls))
+	for i, call := range calls {
+		call.Arguments = json.RawMessage(f(string(call.Arguments)))
+		out[i] = call
+	}
+	return out
+}
+
+// anonymizedProvider anonymizes requests to and restores responses from
+// the wrapped provider.
+type anonymizedProvider struct {
+	Provider
+	anon *Anonymizer
+}
+
+func (p *anonymizedProvider) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	response, err := p.Provider.Chat(ctx, p.anon.anonymizeMessages(messages))
+	return p.anon.restoreResponse(response), err
+}
+
+func (p *anonymizedProvider) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	response, err := p.Provider.ChatWithFormat(ctx, p.anon.anonymizeMessages(messages), format)
+	return p.anon.restoreResponse(response), err
+}
+
+func (p *anonymizedProvider) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	response, err := p.Provider.ChatWithTools(ctx, p.anon.anonymizeMessages(messages), tools)
+	return p.anon.restoreResponse(response), err
+}
+
+// StreamChat restores pseudonyms in streamed chunks. A chunk's trailing
+// partial word is held back until the next chunk, since a pseudonym can be
+// split across chunks.
+func (p *anonymizedProvider) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error) {
+	inner := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var pending string
+		send := func(text string) {
+			if text == "" {
+				return
+			}
+			select {
+			case chunks <- p.anon.Restore(text):
+			case <-ctx.Done():
+			}
+		}
+		for chunk := range inner {
+			pending += chunk
+			cut := strings.LastIndexFunc(pending, func(r rune) bool { return !isWordRune(r) })
+			send(pending[:cut+1])
+			pending = pending[cut+1:]
+		}
+		send(pending)
+	}()
+
+	usage, err := p.Provider.StreamChat(ctx, p.anon.anonymizeMessages(messages), inner)
+	close(inner)
+	<-done
+	return usage, err
+}
+
+// SupportsJSONSchema keeps the wrapped provider's SchemaProvider answer.
+func (p *anonymizedProvider) SupportsJSONSchema() bool {
+	sp, ok := p.Provider.(SchemaProvider)
+	return ok && sp.SupportsJSONSchema()
+}
+
+// isWordRune reports whether r can be part of an identifier or pseudonym.
+func isWordRune(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
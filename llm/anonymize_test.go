@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// echoStub records the messages it is sent and answers with a fixed
+// response, streamed in fixed chunks.
+type echoStub struct {
+	sent     []ChatMessage
+	response LLMResponse
+	chunks   []string
+}
+
+func (p *echoStub) Name() string  { return "stub" }
+func (p *echoStub) Model() string { return "m" }
+
+func (p *echoStub) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	p.sent = messages
+	return p.response, nil
+}
+
+func (p *echoStub) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *echoStub) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *echoStub) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error) {
+	p.sent = messages
+	for _, chunk := range p.chunks {
+		chunks <- chunk
+	}
+	return nil, nil
+}
+
+func testAnonymizer(t *testing.T) *Anonymizer {
+	t.Helper()
+	anon, err := NewAnonymizer(AnonymizeMap{
+		Packages: []string{"acmebilling"},
+		Files:    []string{"ledger_sync.go"},
+		Symbols:  []string{"ReconcileInvoices", "ledgerCache"},
+		Aliases:  map[string]string{"AcmeCorp": "VendorCo"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return anon
+}
+
+func TestAnonymizerRoundTrip(t *testing.T) {
+	anon := testAnonymizer(t)
+
+	text := "AcmeCorp's acmebilling/ledger_sync.go calls acmebilling.ReconcileInvoices and ledgerCache, not ReconcileInvoicesV2."
+	masked := anon.Anonymize(text)
+	want := "VendorCo's anonpkg1/anonfile1.go calls anonpkg1.AnonSym1 and anonSym2, not ReconcileInvoicesV2."
+	if masked != want {
+		t.Errorf("Anonymize() = %q, want %q", masked, want)
+	}
+	if restored := anon.Restore(masked); restored != text {
+		t.Errorf("Restore() = %q, want %q", restored, text)
+	}
+}
+
+func TestNewAnonymizerRejectsBadMaps(t *testing.T) {
+	for name, m := range map[string]AnonymizeMap{
+		"empty":     {},
+		"quote":     {Symbols: []string{`say"hi`}},
+		"duplicate": {Packages: []string{"acme"}, Symbols: []string{"acme"}},
+		"collision": {Aliases: map[string]string{"a": "same", "b": "same"}},
+	} {
+		if _, err := NewAnonymizer(m); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestAnonymizedProvider(t *testing.T) {
+	stub := &echoStub{response: LLMResponse{
+		Content:   "Fix anonpkg1.AnonSym1",
+		ToolCalls: []ToolCall{{Name: "read_file", Arguments: []byte(`{"path": "anonpkg1/anonfile1.go"}`)}},
+	}}
+	provider := testAnonymizer(t).Wrap(stub)
+
+	response, err := provider.Chat(context.Background(), []ChatMessage{
+		UserMessage("Why does ReconcileInvoices fail?"),
+		{Role: "assistant", ToolCalls: []ToolCall{{Name: "read_file", Arguments: []byte(`{"path": "acmebilling/ledger_sync.go"}`)}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stub.sent[0].Content != "Why does AnonSym1 fail?" {
+		t.Errorf("prompt not anonymized: %q", stub.sent[0].Content)
+	}
+	if string(stub.sent[1].ToolCalls[0].Arguments) != `{"path": "anonpkg1/anonfile1.go"}` {
+		t.Errorf("tool call history not anonymized: %s", stub.sent[1].ToolCalls[0].Arguments)
+	}
+	if response.Content != "Fix acmebilling.ReconcileInvoices" {
+		t.Errorf("response not restored: %q", response.Content)
+	}
+	if string(response.ToolCalls[0].Arguments) != `{"path": "acmebilling/ledger_sync.go"}` {
+		t.Errorf("tool call not restored: %s", response.ToolCalls[0].Arguments)
+	}
+}
+
+func TestAnonymizedProviderStreamsAcrossChunks(t *testing.T) {
+	stub := &echoStub{chunks: []string{"See anon", "pkg1.Anon", "Sym1 now"}}
+	provider := testAnonymizer(t).Wrap(stub)
+
+	chunks := make(chan string, 10)
+	if _, err := provider.StreamChat(context.Background(), []ChatMessage{UserMessage("hi")}, chunks); err != nil {
+		t.Fatal(err)
+	}
+	close(chunks)
+	var b strings.Builder
+	for chunk := range chunks {
+		b.WriteString(chunk)
+	}
+	if b.String() != "See acmebilling.ReconcileInvoices now" {
+		t.Errorf("streamed = %q", b.String())
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
@@ -26,9 +27,16 @@ type AnthropicProvider struct {
 
 // NewAnthropicProvider creates a new Anthropic provider.
 func NewAnthropicProvider(apiKey, model string, maxTokens uint32, temperature float32) *AnthropicProvider {
-	client := anthropic.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+	return newAnthropicProvider(apiKey, model, maxTokens, temperature, nil)
+}
+
+// newAnthropicProvider creates an Anthropic provider using httpClient (nil for the default).
+func newAnthropicProvider(apiKey, model string, maxTokens uint32, temperature float32, httpClient *http.Client) *AnthropicProvider {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
+	}
+	client := anthropic.NewClient(opts...)
 
 	return &AnthropicProvider{
 		client:      client,
@@ -48,6 +56,19 @@ func (p *AnthropicProvider) Model() string {
 	return p.model
 }
 
+// ListModels returns the IDs of the models the API key can use.
+func (p *AnthropicProvider) ListModels(ctx context.Context) ([]string, error) {
+	var models []string
+	pager := p.client.Models.ListAutoPaging(ctx, anthropic.ModelListParams{})
+	for pager.Next() {
+		models = append(models, pager.Current().ID)
+	}
+	if err := pager.Err(); err != nil {
+		return nil, fmt.Errorf("list models failed: %w", err)
+	}
+	return models, nil
+}
+
 // Chat sends a chat completion request.
 func (p *AnthropicProvider) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
 	return p.ChatWithFormat(ctx, messages, nil)
@@ -0,0 +1,157 @@
+// Request batching for many small, independent prompts.
+//
+// Judging, labeling and similar calls send the same instructions with a
+// short text each time. Client.ChatBatch coalesces those texts into one
+// request per batch, asking for a JSON array of answers, so indexing or
+// judging a whole repository costs a handful of calls instead of one per
+// item. Batch size is per provider and can be overridden with
+// Client.WithBatchSize.
+//
+// Information Hiding:
+// - Numbered-item prompt and answer-array format hidden
+// - Per-item fallback when a batched request fails or its reply doesn't
+//   line up hidden
+
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	jsonutil "github.com/richinex/ariadne/internal/json"
+)
+
+const (
+	// defaultBatchSize is used for providers without a known batch size.
+	defaultBatchSize = 10
+	// maxBatchBytes caps the combined size of one batch's items, so long
+	// items don't push a batch past the context window.
+	maxBatchBytes = 32 << 10
+)
+
+// providerBatchSizes is how many items each provider gets per request.
+var providerBatchSizes = map[string]int{
+	"openai":    20,
+	"anthropic": 20,
+	"gemini":    20,
+	"deepseek":  10,
+}
+
+// BatchResult is the outcome of ChatBatch.
+type BatchResult struct {
+	Answers []string // One per item, in order
+	Usage   TokenUsage
+	Calls   int // Requests sent, including per-item fallbacks
+}
+
+// WithBatchSize overrides the provider's batch size for ChatBatch.
+func (c *Client) WithBatchSize(n int) *Client {
+	c.batchSize = n
+	return c
+}
+
+// ChatBatch answers each item under the same instructions, sending items
+// in batches. A batch whose request fails or whose reply doesn't hold
+// exactly one answer per item is retried one item at a time.
+func (c *Client) ChatBatch(ctx context.Context, instructions string, items []string) (BatchResult, error) {
+	result := BatchResult{Answers: make([]string, 0, len(items))}
+	for _, batch := range splitBatches(items, c.maxBatchSize()) {
+		answers, err := c.chatBatch(ctx, instructions, batch, &result)
+		if err != nil {
+			return result, err
+		}
+		result.Answers = append(result.Answers, answers...)
+	}
+	return result, nil
+}
+
+// maxBatchSize returns the configured or provider-specific batch size.
+func (c *Client) maxBatchSize() int {
+	if c.batchSize > 0 {
+		return c.batchSize
+	}
+	if n, ok := providerBatchSizes[c.provider.Name()]; ok {
+		return n
+	}
+	return defaultBatchSize
+}
+
+// chatBatch answers one batch, falling back to single calls.
+func (c *Client) chatBatch(ctx context.Context, instructions string, batch []string, result *BatchResult) ([]string, error) {
+	if len(batch) > 1 {
+		response, err := c.provider.ChatWithFormat(ctx, batchMessages(instructions, batch), NewJSONObjectFormat())
+		result.record(response.Usage)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("batch request failed: %w", ctx.Err())
+		}
+		// A failed batch (overload, timeout, a reply cut short) may still
+		// go through as smaller requests
+		if err == nil {
+			answers, err := jsonutil.DecodeValidated(response.Content, func(r batchReply) error {
+				if len(r.Answers) != len(batch) {
+					return fmt.Errorf("expected %d answers, got %d", len(batch), len(r.Answers))
+				}
+				return nil
+			})
+			if err == nil {
+				return answers.Answers, nil
+			}
+		}
+	}
+
+	answers := make([]string, 0, len(batch))
+	for _, item := range batch {
+		response, err := c.provider.Chat(ctx, []ChatMessage{SystemMessage(instructions), UserMessage(item)})
+		result.record(response.Usage)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		answers = append(answers, response.Content)
+	}
+	return answers, nil
+}
+
+// batchReply is the JSON a batched request asks for.
+type batchReply struct {
+	Answers []string `json:"answers"`
+}
+
+func batchMessages(instructions string, batch []string) []ChatMessage {
+	system := fmt.Sprintf(`%s
+
+You will receive %d numbered items. Handle each one independently, as if it were the only one.
+Reply with JSON only: {"answers": ["answer for item 1", "answer for item 2", ...]}
+with exactly %d answers, in item order.`, instructions, len(batch), len(batch))
+
+	var user strings.Builder
+	for i, item := range batch {
+		fmt.Fprintf(&user, "Item %d:\n%s\n\n", i+1, item)
+	}
+	return []ChatMessage{SystemMessage(system), UserMessage(strings.TrimSpace(user.String()))}
+}
+
+// splitBatches groups items into batches of at most size items and about
+// maxBatchBytes bytes. An item larger than that gets a batch of its own.
+func splitBatches(items []string, size int) [][]string {
+	var batches [][]string
+	var current []string
+	bytes := 0
+	for _, item := range items {
+		if len(current) > 0 && (len(current) == size || bytes+len(item) > maxBatchBytes) {
+			batches = append(batches, current)
+			current, bytes = nil, 0
+		}
+		current = append(current, item)
+		bytes += len(item)
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+func (r *BatchResult) record(usage *TokenUsage) {
+	r.Calls++
+	r.Usage.Add(usage)
+}
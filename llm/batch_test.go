@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// batchStub answers batched requests with one answer per item (or one
+// short, when drop is set) and single requests by echoing the item.
+type batchStub struct {
+	usageStub
+	drop    bool
+	fail    bool  // Batched requests fail
+	batches []int // Items per batched request
+}
+
+func (p *batchStub) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	n := strings.Count(messages[1].Content, "Item ")
+	p.batches = append(p.batches, n)
+	if p.fail {
+		return LLMResponse{}, fmt.Errorf("503 service unavailable")
+	}
+	if p.drop {
+		n--
+	}
+	answers := make([]string, n)
+	for i := range answers {
+		answers[i] = fmt.Sprintf("batched %d", i+1)
+	}
+	data, _ := json.Marshal(batchReply{Answers: answers})
+	return LLMResponse{Content: string(data), Usage: &TokenUsage{TotalTokens: 10}}, nil
+}
+
+func (p *batchStub) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	return LLMResponse{Content: "single " + messages[1].Content, Usage: &TokenUsage{TotalTokens: 1}}, nil
+}
+
+func TestChatBatchSplitsByBatchSize(t *testing.T) {
+	provider := &batchStub{}
+	client := NewClient(provider).WithBatchSize(2)
+
+	result, err := client.ChatBatch(context.Background(), "Label each text.", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("ChatBatch failed: %v", err)
+	}
+	want := []string{"batched 1", "batched 2", "single c"}
+	if strings.Join(result.Answers, "|") != strings.Join(want, "|") {
+		t.Errorf("answers = %q, want %q", result.Answers, want)
+	}
+	if len(provider.batches) != 1 || provider.batches[0] != 2 || result.Calls != 2 || result.Usage.TotalTokens != 11 {
+		t.Errorf("unexpected batching: batches %v, result %+v", provider.batches, result)
+	}
+}
+
+func TestChatBatchFallsBackOnMismatch(t *testing.T) {
+	provider := &batchStub{drop: true}
+	result, err := NewClient(provider).ChatBatch(context.Background(), "Judge.", []string{"x", "y"})
+	if err != nil {
+		t.Fatalf("ChatBatch failed: %v", err)
+	}
+	if strings.Join(result.Answers, "|") != "single x|single y" || result.Calls != 3 {
+		t.Errorf("a short batch reply should be retried per item: %+v", result)
+	}
+}
+
+func TestChatBatchFallsBackOnFailedRequest(t *testing.T) {
+	provider := &batchStub{fail: true}
+	result, err := NewClient(provider).ChatBatch(context.Background(), "Judge.", []string{"x", "y"})
+	if err != nil {
+		t.Fatalf("a failed batch should be retried per item, got %v", err)
+	}
+	if strings.Join(result.Answers, "|") != "single x|single y" || result.Calls != 3 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewClient(provider).ChatBatch(ctx, "Judge.", []string{"x", "y"}); err == nil {
+		t.Error("a cancelled batch should not fall back")
+	}
+}
+
+func TestSplitBatchesCapsBytes(t *testing.T) {
+	big := strings.Repeat("x", maxBatchBytes)
+	batches := splitBatches([]string{"a", big, "b"}, 20)
+	if len(batches) != 3 {
+		t.Errorf("an item filling a batch should stand alone, got %d batches", len(batches))
+	}
+	if got := NewClient(&batchStub{}).maxBatchSize(); got != defaultBatchSize {
+		t.Errorf("unknown providers should use the default batch size, got %d", got)
+	}
+}
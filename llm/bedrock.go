@@ -0,0 +1,314 @@
+// AWS Bedrock Provider implementation using the Bedrock Converse API.
+//
+// Converse gives every Bedrock model family (anthropic.claude-*, meta.llama*,
+// amazon.nova-*, ...) one request format with tool use. Requests are signed
+// with SigV4 from the standard AWS credentials, or carry a Bedrock API key
+// as a bearer token when one is given. The region comes from
+// ProviderBuilder.Region, else BEDROCK_REGION, AWS_REGION or
+// AWS_DEFAULT_REGION.
+//
+// Information Hiding:
+// - Converse request/response JSON and message merging hidden
+// - Endpoint, signing and credential resolution hidden
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// bedrockService is the SigV4 signing name of the Bedrock runtime.
+const bedrockService = "bedrock"
+
+// BedrockProvider implements the Provider interface for AWS Bedrock.
+type BedrockProvider struct {
+	httpClient  *http.Client
+	endpoint    string // https://bedrock-runtime.<region>.amazonaws.com
+	region      string
+	apiKey      string // Bedrock API key; SigV4 with creds when empty
+	creds       awsCredentials
+	model       string
+	maxTokens   int
+	temperature float32
+	initErr     error // Stores credential/region errors for deferred reporting
+}
+
+// NewBedrockProvider creates a Bedrock provider for region ("" to read it
+// from the environment) using the credentials of profile ("" for the
+// default chain). If no credentials or region are found, the error is
+// returned on first use.
+func NewBedrockProvider(region, profile, model string, maxTokens uint32, temperature float32) *BedrockProvider {
+	return newBedrockProvider("", region, profile, model, maxTokens, temperature, nil)
+}
+
+// newBedrockProvider creates a Bedrock provider using httpClient (nil for
+// the default). A non-empty apiKey is sent as a bearer token instead of
+// signing with AWS credentials.
+func newBedrockProvider(apiKey, region, profile, model string, maxTokens uint32, temperature float32, httpClient *http.Client) *BedrockProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	p := &BedrockProvider{
+		httpClient:  httpClient,
+		region:      bedrockRegion(region),
+		apiKey:      apiKey,
+		model:       model,
+		maxTokens:   int(maxTokens),
+		temperature: temperature,
+	}
+	if p.region == "" {
+		p.initErr = fmt.Errorf("bedrock: no region: set BEDROCK_REGION or AWS_REGION")
+		return p
+	}
+	p.endpoint = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", p.region)
+	if apiKey == "" {
+		creds, err := loadAWSCredentials(profile)
+		if err != nil {
+			p.initErr = fmt.Errorf("bedrock: %w", err)
+		}
+		p.creds = creds
+	}
+	return p
+}
+
+// bedrockRegion returns region, or the first region set in the environment.
+func bedrockRegion(region string) string {
+	for _, candidate := range []string{region, os.Getenv("BEDROCK_REGION"), os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")} {
+		if candidate != "" {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Name returns the provider name.
+func (p *BedrockProvider) Name() string {
+	return "bedrock"
+}
+
+// Model returns the current model.
+func (p *BedrockProvider) Model() string {
+	return p.model
+}
+
+// Chat sends a chat completion request.
+func (p *BedrockProvider) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	return p.ChatWithFormat(ctx, messages, nil)
+}
+
+// ChatWithFormat sends a chat completion request. Converse has no JSON
+// mode, so the format is left to the prompt.
+func (p *BedrockProvider) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	return p.converse(ctx, messages, nil)
+}
+
+// ChatWithTools sends a chat completion request with tool definitions.
+func (p *BedrockProvider) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	return p.converse(ctx, messages, tools)
+}
+
+// StreamChat sends the whole reply as one chunk: ConverseStream's binary
+// event-stream framing isn't decoded.
+func (p *BedrockProvider) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error) {
+	response, err := p.converse(ctx, messages, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.Content != "" {
+		select {
+		case chunks <- response.Content:
+		case <-ctx.Done():
+			return response.Usage, ctx.Err()
+		}
+	}
+	return response.Usage, nil
+}
+
+// Converse API wire types.
+type (
+	bedrockRequest struct {
+		Messages        []bedrockMessage       `json:"messages"`
+		System          []bedrockContent       `json:"system,omitempty"`
+		InferenceConfig bedrockInferenceConfig `json:"inferenceConfig"`
+		ToolConfig      *bedrockToolConfig     `json:"toolConfig,omitempty"`
+	}
+	bedrockMessage struct {
+		Role    string           `json:"role"`
+		Content []bedrockContent `json:"content"`
+	}
+	bedrockContent struct {
+		Text       string             `json:"text,omitempty"`
+		ToolUse    *bedrockToolUse    `json:"toolUse,omitempty"`
+		ToolResult *bedrockToolResult `json:"toolResult,omitempty"`
+	}
+	bedrockToolUse struct {
+		ToolUseID string          `json:"toolUseId"`
+		Name      string          `json:"name"`
+		Input     json.RawMessage `json:"input"`
+	}
+	bedrockToolResult struct {
+		ToolUseID string           `json:"toolUseId"`
+		Content   []bedrockContent `json:"content"`
+	}
+	bedrockInferenceConfig struct {
+		MaxTokens   int     `json:"maxTokens,omitempty"`
+		Temperature float32 `json:"temperature"`
+	}
+	bedrockToolConfig struct {
+		Tools []bedrockTool `json:"tools"`
+	}
+	bedrockTool struct {
+		ToolSpec bedrockToolSpec `json:"toolSpec"`
+	}
+	bedrockToolSpec struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		InputSchema map[string]interface{} `json:"inputSchema"`
+	}
+	bedrockResponse struct {
+		Output struct {
+			Message bedrockMessage `json:"message"`
+		} `json:"output"`
+		Usage struct {
+			InputTokens          uint32 `json:"inputTokens"`
+			OutputTokens         uint32 `json:"outputTokens"`
+			TotalTokens          uint32 `json:"totalTokens"`
+			CacheReadInputTokens uint32 `json:"cacheReadInputTokens"`
+		} `json:"usage"`
+	}
+)
+
+// converse sends one Converse request and maps the reply.
+func (p *BedrockProvider) converse(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	if p.initErr != nil {
+		return LLMResponse{}, p.initErr
+	}
+
+	request := convertToBedrockRequest(messages, tools)
+	request.InferenceConfig = bedrockInferenceConfig{MaxTokens: p.maxTokens, Temperature: p.temperature}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/model/"+awsURIEncode(p.model)+"/converse", bytes.NewReader(body))
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	} else {
+		signV4(req, body, p.creds, p.region, bedrockService, time.Now())
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("chat completion failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return LLMResponse{}, fmt.Errorf("chat completion failed: bedrock returned %s: %s", resp.Status, apiErr.Message)
+	}
+
+	var parsed bedrockResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return LLMResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	content := ""
+	var toolCalls []ToolCall
+	for _, block := range parsed.Output.Message.Content {
+		content += block.Text
+		if block.ToolUse != nil {
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        block.ToolUse.ToolUseID,
+				Name:      block.ToolUse.Name,
+				Arguments: block.ToolUse.Input,
+			})
+		}
+	}
+
+	usage := &TokenUsage{
+		PromptTokens:       parsed.Usage.InputTokens,
+		CompletionTokens:   parsed.Usage.OutputTokens,
+		TotalTokens:        parsed.Usage.TotalTokens,
+		CachedPromptTokens: parsed.Usage.CacheReadInputTokens,
+	}
+
+	return LLMResponse{Content: content, ToolCalls: toolCalls, Usage: usage}, nil
+}
+
+// convertToBedrockRequest converts our messages and tools to Converse
+// format. Converse needs alternating user/assistant turns, so consecutive
+// messages of one role (e.g. several tool results) are merged.
+func convertToBedrockRequest(messages []ChatMessage, tools []ToolDefinition) bedrockRequest {
+	var request bedrockRequest
+	add := func(role string, blocks ...bedrockContent) {
+		if n := len(request.Messages); n > 0 && request.Messages[n-1].Role == role {
+			request.Messages[n-1].Content = append(request.Messages[n-1].Content, blocks...)
+			return
+		}
+		request.Messages = append(request.Messages, bedrockMessage{Role: role, Content: blocks})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			request.System = append(request.System, bedrockContent{Text: msg.Content})
+		case "user":
+			add("user", bedrockContent{Text: msg.Content})
+		case "assistant":
+			var blocks []bedrockContent
+			if msg.Content != "" {
+				blocks = append(blocks, bedrockContent{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := tc.Arguments
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, bedrockContent{ToolUse: &bedrockToolUse{ToolUseID: tc.ID, Name: tc.Name, Input: input}})
+			}
+			if len(blocks) == 0 {
+				blocks = append(blocks, bedrockContent{Text: " "}) // Converse rejects empty turns
+			}
+			add("assistant", blocks...)
+		case "tool":
+			add("user", bedrockContent{ToolResult: &bedrockToolResult{
+				ToolUseID: msg.ToolCallID,
+				Content:   []bedrockContent{{Text: msg.Content}},
+			}})
+		}
+	}
+
+	if len(tools) > 0 {
+		request.ToolConfig = &bedrockToolConfig{}
+		for _, t := range tools {
+			request.ToolConfig.Tools = append(request.ToolConfig.Tools, bedrockTool{ToolSpec: bedrockToolSpec{
+				Name:        t.Name,
+				Description: t.Description,
+				InputSchema: map[string]interface{}{"json": t.Parameters},
+			}})
+		}
+	}
+	return request
+}
+
+// Verify BedrockProvider implements Provider
+var _ Provider = (*BedrockProvider)(nil)
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignV4 checks the signer against the get-vanilla case of the AWS
+// SigV4 test suite.
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q\nwant %q", got, want)
+	}
+}
+
+func TestCanonicalURIDoubleEncodes(t *testing.T) {
+	if got := canonicalURI("/model/anthropic.claude-v1%3A0/converse"); got != "/model/anthropic.claude-v1%253A0/converse" {
+		t.Errorf("canonicalURI = %q", got)
+	}
+}
+
+func TestLoadAWSCredentialsProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := "[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = secret1\n\n" +
+		"[work]\naws_access_key_id = AKIDWORK\naws_secret_access_key = secret2\naws_session_token = token\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_PROFILE", "work")
+
+	creds, err := loadAWSCredentials("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "AKIDWORK" || creds.SessionToken != "token" {
+		t.Errorf("AWS_PROFILE should select its section: %+v", creds)
+	}
+	if _, err := loadAWSCredentials("missing"); err == nil {
+		t.Error("unknown profile should fail")
+	}
+}
+
+func TestBedrockConverse(t *testing.T) {
+	var path, auth string
+	var request bedrockRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.EscapedPath(), r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[
+			{"text":"Looking."},
+			{"toolUse":{"toolUseId":"t2","name":"read_file","input":{"path":"b.go"}}}]}},
+			"usage":{"inputTokens":100,"outputTokens":20,"totalTokens":120,"cacheReadInputTokens":64}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	p := newBedrockProvider("", "us-west-2", "", ModelBedrockClaudeSonnet45, 512, 0.2, nil)
+	if p.initErr != nil {
+		t.Fatal(p.initErr)
+	}
+	p.endpoint = server.URL
+
+	messages := []ChatMessage{
+		SystemMessage("You are helpful."),
+		UserMessage("Read a.go and b.go"),
+		{Role: "assistant", ToolCalls: []ToolCall{
+			{ID: "t0", Name: "read_file", Arguments: json.RawMessage(`{"path":"a.go"}`)},
+			{ID: "t1", Name: "read_file", Arguments: json.RawMessage(`{"path":"b.go"}`)},
+		}},
+		{Role: "tool", ToolCallID: "t0", Content: "package a"},
+		{Role: "tool", ToolCallID: "t1", Content: "package b"},
+	}
+	tools := []ToolDefinition{{Name: "read_file", Description: "Read a file", Parameters: map[string]interface{}{"type": "object"}}}
+	response, err := p.ChatWithTools(context.Background(), messages, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/model/anthropic.claude-sonnet-4-5-20250929-v1%3A0/converse" {
+		t.Errorf("unexpected path %q", path)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-west-2/bedrock/aws4_request") {
+		t.Errorf("request should be SigV4 signed: %q", auth)
+	}
+	if len(request.System) != 1 || len(request.Messages) != 3 {
+		t.Fatalf("expected system prompt and 3 turns, got %+v", request)
+	}
+	if results := request.Messages[2].Content; len(results) != 2 || results[1].ToolResult == nil || results[1].ToolResult.ToolUseID != "t1" {
+		t.Errorf("consecutive tool results should share one user turn: %+v", results)
+	}
+	if request.ToolConfig == nil || request.ToolConfig.Tools[0].ToolSpec.InputSchema["json"] == nil {
+		t.Errorf("tools should be sent as toolSpec with a json schema: %+v", request.ToolConfig)
+	}
+
+	if response.Content != "Looking." || len(response.ToolCalls) != 1 || response.ToolCalls[0].ID != "t2" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if response.Usage.TotalTokens != 120 || response.Usage.CachedPromptTokens != 64 {
+		t.Errorf("unexpected usage: %+v", response.Usage)
+	}
+}
+
+func TestBedrockAPIKey(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"not authorized"}`))
+	}))
+	defer server.Close()
+
+	p := newBedrockProvider("bedrock-key", "eu-west-1", "", ModelBedrockNovaPro, 512, 0.2, nil)
+	p.endpoint = server.URL
+	_, err := p.Chat(context.Background(), []ChatMessage{UserMessage("hi")})
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Errorf("API errors should carry the service message: %v", err)
+	}
+	if auth != "Bearer bedrock-key" {
+		t.Errorf("an API key should be sent as a bearer token: %q", auth)
+	}
+}
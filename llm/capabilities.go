@@ -0,0 +1,128 @@
+// Model capability registry.
+//
+// Not every model supports every feature the providers expose: some
+// reasoning models have no native tool calling, Bedrock replies can't be
+// streamed, and only some providers constrain output to a JSON schema.
+// Callers consult Capabilities so features degrade gracefully instead of
+// failing mid-run.
+//
+// Information Hiding:
+// - Capability table and model name matching hidden
+// - Provider defaults for models that aren't listed
+
+package llm
+
+import (
+	"strings"
+	"sync"
+)
+
+// Capabilities describes what a provider and model support.
+type Capabilities struct {
+	// Tools is native tool calling through ChatWithTools. Without it,
+	// ChatWithTools emulates tool calls with prompted JSON.
+	Tools bool
+	// Streaming is incremental output from StreamChat. Without it the reply
+	// arrives as one chunk.
+	Streaming bool
+	// JSONSchema is output constrained to a ResponseFormatJSONSchema
+	// format (see SchemaProvider).
+	JSONSchema bool
+	// Vision is image input.
+	Vision bool
+	// ContextWindow is the maximum prompt size in tokens (0 = unknown).
+	ContextWindow int
+}
+
+// fullCapabilities is assumed for providers not in the registry, so
+// custom providers keep every feature they implement.
+var fullCapabilities = Capabilities{Tools: true, Streaming: true, JSONSchema: true}
+
+// providerCapabilities are the defaults for a provider's unlisted models.
+var providerCapabilities = map[string]Capabilities{
+	"openai":    {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 128_000},
+	"anthropic": {Tools: true, Streaming: true, Vision: true, ContextWindow: 200_000},
+	"deepseek":  {Tools: true, Streaming: true, ContextWindow: 128_000},
+	"gemini":    {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 1_048_576},
+	"bedrock":   {Tools: true, ContextWindow: 200_000},
+}
+
+// modelCapabilities is keyed by model ID or ID prefix, matched like
+// modelPrices. Entries override the provider default.
+var (
+	capabilitiesMu    sync.RWMutex
+	modelCapabilities = map[string]Capabilities{
+		ModelOpenAIGPT52:      {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 400_000},
+		ModelOpenAIGPT52Codex: {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 400_000},
+		ModelOpenAIGPT5:       {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 400_000},
+		ModelOpenAIO3Mini:     {Tools: true, Streaming: true, JSONSchema: true, ContextWindow: 200_000},
+		ModelOpenAIO1:         {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 200_000},
+		"o1-mini":             {Streaming: true, ContextWindow: 128_000},
+
+		ModelDeepSeekR1: {Streaming: true, ContextWindow: 64_000},
+
+		ModelGeminiFlash2: {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 1_048_576},
+		ModelGeminiPro2:   {Tools: true, Streaming: true, JSONSchema: true, Vision: true, ContextWindow: 2_097_152},
+
+		"amazon.nova-pro":   {Tools: true, Vision: true, ContextWindow: 300_000},
+		"amazon.nova-lite":  {Tools: true, Vision: true, ContextWindow: 300_000},
+		"amazon.nova-micro": {Tools: true, ContextWindow: 128_000},
+	}
+)
+
+// RegisterCapabilities records the capabilities of model (an ID or ID
+// prefix), replacing any built-in entry. Use it for self-hosted or newer
+// models the registry doesn't know.
+func RegisterCapabilities(model string, caps Capabilities) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	modelCapabilities[model] = caps
+}
+
+// CapabilitiesFor returns the capabilities of model on provider. Listed
+// models use their own entry; others get the provider's defaults, and
+// unknown providers are assumed to support everything.
+func CapabilitiesFor(provider, model string) Capabilities {
+	capabilitiesMu.RLock()
+	caps, ok := lookupModel(modelCapabilities, model)
+	capabilitiesMu.RUnlock()
+	if ok {
+		return caps
+	}
+	if caps, ok := providerCapabilities[provider]; ok {
+		return caps
+	}
+	return fullCapabilities
+}
+
+// CapabilitiesOf returns the capabilities of p's model. JSONSchema also
+// requires p to enforce schemas (see SchemaProvider).
+func CapabilitiesOf(p Provider) Capabilities {
+	caps := CapabilitiesFor(p.Name(), p.Model())
+	sp, ok := p.(SchemaProvider)
+	caps.JSONSchema = caps.JSONSchema && ok && sp.SupportsJSONSchema()
+	return caps
+}
+
+// lookupModel finds model in table, matching the longest listed prefix so
+// that dated IDs (claude-sonnet-4-20250514) find their base model. Bedrock
+// IDs are matched without their region and vendor prefixes.
+func lookupModel[V any](table map[string]V, model string) (V, bool) {
+	for _, prefix := range bedrockPrefixes {
+		model = strings.TrimPrefix(model, prefix)
+	}
+	if v, ok := table[model]; ok {
+		return v, true
+	}
+	best := ""
+	for name := range table {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	var zero V
+	if best == "" {
+		return zero, false
+	}
+	return table[best], true
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCapabilitiesFor(t *testing.T) {
+	if caps := CapabilitiesFor("openai", ModelOpenAIGPT52); !caps.Tools || !caps.JSONSchema || caps.ContextWindow != 400_000 {
+		t.Errorf("unexpected GPT-5.2 capabilities: %+v", caps)
+	}
+	if caps := CapabilitiesFor("openai", "o1-mini-2024-09-12"); caps.Tools {
+		t.Error("dated o1-mini should match its own entry, not o1")
+	}
+	if caps := CapabilitiesFor("deepseek", ModelDeepSeekR1); caps.Tools {
+		t.Error("DeepSeek R1 has no native tool calling")
+	}
+	if caps := CapabilitiesFor("anthropic", ModelAnthropicClaudeSonnet4); !caps.Tools || caps.JSONSchema {
+		t.Errorf("unlisted models should use the provider default: %+v", caps)
+	}
+	if caps := CapabilitiesFor("bedrock", "us."+ModelBedrockClaudeSonnet45); caps.Streaming {
+		t.Error("Bedrock replies are not streamed")
+	}
+	if caps := CapabilitiesFor("local", "llama"); caps != fullCapabilities {
+		t.Errorf("unknown providers should keep every feature: %+v", caps)
+	}
+
+	RegisterCapabilities("llama", Capabilities{Streaming: true, ContextWindow: 8192})
+	defer func() {
+		capabilitiesMu.Lock()
+		delete(modelCapabilities, "llama")
+		capabilitiesMu.Unlock()
+	}()
+	if caps := CapabilitiesFor("local", "llama-3-8b"); caps.Tools || caps.ContextWindow != 8192 {
+		t.Errorf("registered capabilities should apply: %+v", caps)
+	}
+}
+
+// promptedStub is a model without native tool calling that replies with
+// the next scripted answer and records the messages it was sent.
+type promptedStub struct {
+	usageStub
+	replies []string
+	sent    [][]ChatMessage
+}
+
+func (p *promptedStub) Name() string  { return "deepseek" }
+func (p *promptedStub) Model() string { return ModelDeepSeekR1 }
+
+func (p *promptedStub) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	p.sent = append(p.sent, messages)
+	reply := p.replies[0]
+	p.replies = p.replies[1:]
+	return LLMResponse{Content: reply}, nil
+}
+
+func (p *promptedStub) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	panic("native tool calling is not supported")
+}
+
+func TestChatWithToolsPromptedFallback(t *testing.T) {
+	provider := &promptedStub{replies: []string{
+		"```json\n{\"tool_calls\": [{\"name\": \"read_file\", \"arguments\": {\"path\": \"go.mod\"}}]}\n```",
+		"The module is ariadne.",
+	}}
+	tools := []ToolDefinition{{Name: "read_file", Description: "Read a file", Parameters: map[string]interface{}{"type": "object"}}}
+	messages := []ChatMessage{SystemMessage("You are helpful."), UserMessage("What is the module?")}
+	ctx := context.Background()
+
+	response, err := ChatWithTools(ctx, provider, messages, tools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(response.ToolCalls) != 1 || response.ToolCalls[0].Name != "read_file" || string(response.ToolCalls[0].Arguments) != `{"path": "go.mod"}` {
+		t.Fatalf("expected a parsed read_file call, got %+v", response)
+	}
+	if system := provider.sent[0][0]; system.Role != "system" || !strings.Contains(system.Content, "read_file: Read a file") {
+		t.Errorf("tools should be described in the system prompt: %q", system.Content)
+	}
+
+	messages = append(messages,
+		ChatMessage{Role: "assistant", ToolCalls: response.ToolCalls},
+		ChatMessage{Role: "tool", ToolCallID: response.ToolCalls[0].ID, Content: "module github.com/richinex/ariadne"},
+	)
+	response, err = ChatWithTools(ctx, provider, messages, tools)
+	if err != nil || len(response.ToolCalls) != 0 || response.Content != "The module is ariadne." {
+		t.Fatalf("expected a final answer, got %+v, %v", response, err)
+	}
+	for _, msg := range provider.sent[1] {
+		if msg.Role == "tool" || len(msg.ToolCalls) > 0 {
+			t.Errorf("tool history should be flattened to plain messages: %+v", msg)
+		}
+	}
+}
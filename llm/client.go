@@ -6,9 +6,11 @@ import (
 	"context"
 )
 
-// Client wraps a Provider with a simple interface.
+// Client wraps a Provider with a simple interface. Empty replies are
+// retried once before ErrEmptyResponse is returned (see retryEmpty).
 type Client struct {
-	provider Provider
+	provider  Provider
+	batchSize int // ChatBatch items per request (0 = provider default)
 }
 
 // NewClient creates a new LLM client from a provider.
@@ -18,7 +20,7 @@ func NewClient(provider Provider) *Client {
 
 // Chat sends a chat completion request and returns just the content.
 func (c *Client) Chat(ctx context.Context, messages []ChatMessage) (string, error) {
-	response, err := c.provider.Chat(ctx, messages)
+	response, err := c.chat(ctx, messages)
 	if err != nil {
 		return "", err
 	}
@@ -27,7 +29,7 @@ func (c *Client) Chat(ctx context.Context, messages []ChatMessage) (string, erro
 
 // ChatWithUsage sends a chat completion request and returns content with token usage.
 func (c *Client) ChatWithUsage(ctx context.Context, messages []ChatMessage) (string, *TokenUsage, error) {
-	response, err := c.provider.Chat(ctx, messages)
+	response, err := c.chat(ctx, messages)
 	if err != nil {
 		return "", nil, err
 	}
@@ -37,13 +39,46 @@ func (c *Client) ChatWithUsage(ctx context.Context, messages []ChatMessage) (str
 // ChatWithFormat sends a chat completion request with response format
 // and returns just the content.
 func (c *Client) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (string, error) {
-	response, err := c.provider.ChatWithFormat(ctx, messages, format)
+	response, err := c.chatWithFormat(ctx, messages, format)
 	if err != nil {
 		return "", err
 	}
 	return response.Content, nil
 }
 
+// ChatWithFormatAndUsage sends a chat completion request with response format
+// and returns content with token usage.
+func (c *Client) ChatWithFormatAndUsage(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (string, *TokenUsage, error) {
+	response, err := c.chatWithFormat(ctx, messages, format)
+	if err != nil {
+		return "", nil, err
+	}
+	return response.Content, response.Usage, nil
+}
+
+func (c *Client) chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	return retryEmpty(messages, func(messages []ChatMessage) (LLMResponse, error) {
+		return c.provider.Chat(ctx, messages)
+	})
+}
+
+func (c *Client) chatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	return retryEmpty(messages, func(messages []ChatMessage) (LLMResponse, error) {
+		return c.provider.ChatWithFormat(ctx, messages, format)
+	})
+}
+
+// SupportsJSONSchema reports whether the provider enforces JSON schema
+// response formats for its model (see SchemaProvider and Capabilities).
+func (c *Client) SupportsJSONSchema() bool {
+	return c.Capabilities().JSONSchema
+}
+
+// Capabilities returns what the provider's model supports.
+func (c *Client) Capabilities() Capabilities {
+	return CapabilitiesOf(c.provider)
+}
+
 // StreamChat streams a chat completion.
 func (c *Client) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error) {
 	return c.provider.StreamChat(ctx, messages, chunks)
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	openai "github.com/sashabaranov/go-openai"
 )
@@ -28,8 +29,16 @@ type DeepSeekProvider struct {
 
 // NewDeepSeekProvider creates a new DeepSeek provider.
 func NewDeepSeekProvider(apiKey, model string, maxTokens uint32, temperature float32) *DeepSeekProvider {
+	return newDeepSeekProvider(apiKey, model, maxTokens, temperature, nil)
+}
+
+// newDeepSeekProvider creates a DeepSeek provider using httpClient (nil for the default).
+func newDeepSeekProvider(apiKey, model string, maxTokens uint32, temperature float32, httpClient *http.Client) *DeepSeekProvider {
 	config := openai.DefaultConfig(apiKey)
 	config.BaseURL = deepseekBaseURL
+	if httpClient != nil {
+		config.HTTPClient = httpClient
+	}
 
 	return &DeepSeekProvider{
 		client:      openai.NewClientWithConfig(config),
@@ -49,6 +58,11 @@ func (p *DeepSeekProvider) Model() string {
 	return p.model
 }
 
+// ListModels returns the IDs of the models the API key can use.
+func (p *DeepSeekProvider) ListModels(ctx context.Context) ([]string, error) {
+	return listOpenAIModels(ctx, p.client)
+}
+
 // getTemperature returns the temperature (DeepSeek models don't have beta restrictions)
 func (p *DeepSeekProvider) getTemperature() float32 {
 	return p.temperature
@@ -69,8 +83,12 @@ func (p *DeepSeekProvider) ChatWithFormat(ctx context.Context, messages []ChatMe
 	}
 
 	if format != nil {
+		formatType := format.Type
+		if formatType == ResponseFormatJSONSchema {
+			formatType = ResponseFormatJSONObject // JSON mode only, no schemas
+		}
 		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
-			Type: openai.ChatCompletionResponseFormatType(format.Type),
+			Type: openai.ChatCompletionResponseFormatType(formatType),
 		}
 	}
 
@@ -84,12 +102,9 @@ func (p *DeepSeekProvider) ChatWithFormat(ctx context.Context, messages []ChatMe
 		content = resp.Choices[0].Message.Content
 	}
 
-	// DeepSeek returns token usage in the standard OpenAI format
-	usage := &TokenUsage{
-		PromptTokens:     uint32(resp.Usage.PromptTokens),
-		CompletionTokens: uint32(resp.Usage.CompletionTokens),
-		TotalTokens:      uint32(resp.Usage.TotalTokens),
-	}
+	// DeepSeek returns token usage in the standard OpenAI format, with
+	// prompt cache hits in prompt_tokens_details
+	usage := usageFromOpenAI(resp.Usage)
 
 	return LLMResponse{Content: content, Usage: usage}, nil
 }
@@ -122,11 +137,7 @@ func (p *DeepSeekProvider) ChatWithTools(ctx context.Context, messages []ChatMes
 		}
 	}
 
-	usage := &TokenUsage{
-		PromptTokens:     uint32(resp.Usage.PromptTokens),
-		CompletionTokens: uint32(resp.Usage.CompletionTokens),
-		TotalTokens:      uint32(resp.Usage.TotalTokens),
-	}
+	usage := usageFromOpenAI(resp.Usage)
 
 	return LLMResponse{Content: content, ToolCalls: toolCalls, Usage: usage}, nil
 }
@@ -162,11 +173,7 @@ func (p *DeepSeekProvider) StreamChat(ctx context.Context, messages []ChatMessag
 
 		// Capture token usage from final chunk
 		if response.Usage != nil {
-			usage = &TokenUsage{
-				PromptTokens:     uint32(response.Usage.PromptTokens),
-				CompletionTokens: uint32(response.Usage.CompletionTokens),
-				TotalTokens:      uint32(response.Usage.TotalTokens),
-			}
+			usage = usageFromOpenAI(*response.Usage)
 		}
 
 		if len(response.Choices) > 0 {
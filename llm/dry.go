@@ -0,0 +1,120 @@
+// Dry-run provider for inspecting prompts.
+//
+// A DryProvider never calls a model. Every request is printed instead:
+// each message with its role and estimated size, the tool schemas and
+// response format offered, and a token estimate for the whole request.
+// The call then fails with ErrDryRun, so a run stops at its first LLM call
+// having shown exactly what would have been sent, at no cost.
+//
+// Information Hiding:
+// - Request rendering hidden
+// - Token estimation hidden
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DryProviderName is the name DryProvider reports.
+const DryProviderName = "dry"
+
+// ErrDryRun is returned by every DryProvider call.
+var ErrDryRun = errors.New("dry run: stopped before calling an LLM")
+
+// DryProvider prints requests instead of sending them. Safe for concurrent
+// use; concurrent requests are printed one at a time.
+type DryProvider struct {
+	mu       sync.Mutex
+	out      io.Writer
+	requests int
+}
+
+// NewDryProvider returns a provider that prints requests to out (nil for
+// whatever os.Stdout is at the time of the call).
+func NewDryProvider(out io.Writer) *DryProvider {
+	return &DryProvider{out: out}
+}
+
+// Name returns the provider name.
+func (p *DryProvider) Name() string { return DryProviderName }
+
+// Model returns the model name.
+func (p *DryProvider) Model() string { return DryProviderName }
+
+// Chat prints the request and returns ErrDryRun.
+func (p *DryProvider) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	return LLMResponse{}, p.print("chat", messages, nil, nil)
+}
+
+// ChatWithFormat prints the request and returns ErrDryRun.
+func (p *DryProvider) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	return LLMResponse{}, p.print("chat", messages, nil, format)
+}
+
+// ChatWithTools prints the request and returns ErrDryRun.
+func (p *DryProvider) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	return LLMResponse{}, p.print("chat with tools", messages, tools, nil)
+}
+
+// StreamChat prints the request and returns ErrDryRun.
+func (p *DryProvider) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error) {
+	return nil, p.print("streamed chat", messages, nil, nil)
+}
+
+// print writes one request and returns ErrDryRun.
+func (p *DryProvider) print(kind string, messages []ChatMessage, tools []ToolDefinition, format *ResponseFormat) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests++
+
+	var sb strings.Builder
+	total := 0
+	fmt.Fprintf(&sb, "\n=== Dry run: request %d (%s) ===\n", p.requests, kind)
+	for i, m := range messages {
+		size := len(m.Content)
+		for _, call := range m.ToolCalls {
+			size += len(call.Name) + len(call.Arguments)
+		}
+		total += size
+		fmt.Fprintf(&sb, "\n--- [%d] %s (~%d tokens) ---\n", i+1, m.Role, size/bytesPerToken)
+		if m.ToolCallID != "" {
+			fmt.Fprintf(&sb, "(result of tool call %s)\n", m.ToolCallID)
+		}
+		sb.WriteString(m.Content)
+		if !strings.HasSuffix(m.Content, "\n") {
+			sb.WriteString("\n")
+		}
+		for _, call := range m.ToolCalls {
+			fmt.Fprintf(&sb, "-> tool call %s: %s %s\n", call.ID, call.Name, call.Arguments)
+		}
+	}
+	if len(tools) > 0 {
+		schemas, _ := json.MarshalIndent(tools, "", "  ")
+		total += len(schemas)
+		fmt.Fprintf(&sb, "\n--- Tools: %d (~%d tokens) ---\n", len(tools), len(schemas)/bytesPerToken)
+		sb.Write(schemas)
+		sb.WriteString("\n")
+	}
+	if format != nil {
+		spec, _ := json.MarshalIndent(format, "", "  ")
+		total += len(spec)
+		fmt.Fprintf(&sb, "\n--- Response format (~%d tokens) ---\n%s\n", len(spec)/bytesPerToken, spec)
+	}
+	fmt.Fprintf(&sb, "\n=== %d messages, ~%d prompt tokens (estimated at %d bytes per token) ===\n",
+		len(messages), total/bytesPerToken, bytesPerToken)
+
+	out := p.out
+	if out == nil {
+		out = os.Stdout
+	}
+	io.WriteString(out, sb.String())
+	return ErrDryRun
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDryProviderPrintsRequest(t *testing.T) {
+	var out strings.Builder
+	p := NewDryProvider(&out)
+	messages := []ChatMessage{
+		SystemMessage("You are a careful assistant."),
+		UserMessage("Summarize main.go"),
+	}
+	tools := []ToolDefinition{{
+		Name:        "read_file",
+		Description: "Read a file",
+		Parameters:  map[string]interface{}{"type": "object"},
+	}}
+
+	_, err := ChatWithTools(context.Background(), p, messages, tools)
+	if !errors.Is(err, ErrDryRun) {
+		t.Fatalf("expected ErrDryRun, got %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"request 1 (chat with tools)",
+		"[1] system",
+		"You are a careful assistant.",
+		"[2] user",
+		"Summarize main.go",
+		"Tools: 1",
+		`"name": "read_file"`,
+		"2 messages",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "=== Dry run") != 1 {
+		t.Errorf("a dry run should not be retried:\n%s", got)
+	}
+}
+
+func TestDryProviderNumbersRequests(t *testing.T) {
+	var out strings.Builder
+	p := NewDryProvider(&out)
+	p.Chat(context.Background(), []ChatMessage{UserMessage("one")})
+	p.ChatWithFormat(context.Background(), []ChatMessage{UserMessage("two")}, NewJSONObjectFormat())
+	if !strings.Contains(out.String(), "request 2 (chat)") || !strings.Contains(out.String(), "Response format") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
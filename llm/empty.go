@@ -0,0 +1,61 @@
+// Empty response handling.
+//
+// Providers occasionally answer with no content and no tool calls, which
+// leaves an agent loop with nothing to act on. ChatWithTools and Client
+// retry such a reply once, nudging the model to answer or call a tool,
+// and return ErrEmptyResponse if the retry is empty too.
+//
+// Information Hiding:
+// - Nudge placement (appended to the last user message or as a new one) hidden
+
+package llm
+
+import (
+	"errors"
+	"strings"
+)
+
+// EmptyResponseNudge is sent with the retry after an empty reply.
+const EmptyResponseNudge = "Your last reply was empty. Respond with your final answer or a tool call."
+
+// ErrEmptyResponse means the model's reply was empty, retry included.
+var ErrEmptyResponse = errors.New("empty response from LLM")
+
+// isEmptyResponse reports whether r has neither content nor tool calls.
+func isEmptyResponse(r LLMResponse) bool {
+	return strings.TrimSpace(r.Content) == "" && len(r.ToolCalls) == 0
+}
+
+// retryEmpty calls call with messages and, if the reply is empty, once
+// more with EmptyResponseNudge. The retry's usage includes the first
+// call's. messages is not modified.
+func retryEmpty(messages []ChatMessage, call func([]ChatMessage) (LLMResponse, error)) (LLMResponse, error) {
+	response, err := call(messages)
+	if err != nil || !isEmptyResponse(response) {
+		return response, err
+	}
+	retry, err := call(withNudge(messages))
+	if response.Usage != nil {
+		usage := *response.Usage
+		usage.Add(retry.Usage)
+		retry.Usage = &usage
+	}
+	if err != nil {
+		return retry, err
+	}
+	if isEmptyResponse(retry) {
+		return retry, ErrEmptyResponse
+	}
+	return retry, nil
+}
+
+// withNudge returns a copy of messages asking for a non-empty reply. The
+// nudge joins a trailing user message so turns still alternate.
+func withNudge(messages []ChatMessage) []ChatMessage {
+	nudged := append([]ChatMessage{}, messages...)
+	if last := len(nudged) - 1; last >= 0 && nudged[last].Role == "user" {
+		nudged[last].Content += "\n\n" + EmptyResponseNudge
+		return nudged
+	}
+	return append(nudged, UserMessage(EmptyResponseNudge))
+}
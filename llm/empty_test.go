@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChatWithToolsRetriesEmptyReply(t *testing.T) {
+	provider := &promptedStub{replies: []string{"  ", "The module is ariadne."}}
+	tools := []ToolDefinition{{Name: "read_file", Description: "Read a file", Parameters: map[string]interface{}{"type": "object"}}}
+	messages := []ChatMessage{SystemMessage("You are helpful."), UserMessage("What is the module?")}
+
+	response, err := ChatWithTools(context.Background(), provider, messages, tools)
+	if err != nil || response.Content != "The module is ariadne." {
+		t.Fatalf("expected the retried answer, got %+v, %v", response, err)
+	}
+	retry := provider.sent[1]
+	if last := retry[len(retry)-1]; last.Role != "user" || !strings.HasSuffix(last.Content, EmptyResponseNudge) {
+		t.Errorf("retry should nudge in the last user message, got %+v", last)
+	}
+	if strings.Contains(messages[1].Content, EmptyResponseNudge) {
+		t.Error("caller's messages should not be modified")
+	}
+}
+
+func TestClientReportsRepeatedEmptyReply(t *testing.T) {
+	provider := &promptedStub{replies: []string{"", ""}}
+	_, err := NewClient(provider).Chat(context.Background(), []ChatMessage{UserMessage("hi")})
+	if !errors.Is(err, ErrEmptyResponse) {
+		t.Fatalf("expected ErrEmptyResponse, got %v", err)
+	}
+	if len(provider.sent) != 2 {
+		t.Errorf("expected one retry, got %d calls", len(provider.sent))
+	}
+}
+
+func TestWithNudgeAfterToolResult(t *testing.T) {
+	messages := []ChatMessage{UserMessage("go"), {Role: "tool", ToolCallID: "1", Content: "done"}}
+	nudged := withNudge(messages)
+	if len(nudged) != 3 || nudged[2].Role != "user" || nudged[2].Content != EmptyResponseNudge {
+		t.Errorf("expected a new user message after a tool result, got %+v", nudged)
+	}
+}
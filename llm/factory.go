@@ -22,7 +22,9 @@
 package llm
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 )
@@ -39,6 +41,8 @@ const (
 	ProviderDeepSeek
 	// ProviderGemini is the Google Gemini provider.
 	ProviderGemini
+	// ProviderBedrock is AWS Bedrock (Claude and other models via Converse).
+	ProviderBedrock
 )
 
 // String returns the string representation of the provider type.
@@ -52,12 +56,15 @@ func (p ProviderType) String() string {
 		return "deepseek"
 	case ProviderGemini:
 		return "gemini"
+	case ProviderBedrock:
+		return "bedrock"
 	default:
 		return "unknown"
 	}
 }
 
 // EnvVar returns the environment variable name for this provider's API key.
+// Bedrock's key is optional: without it, AWS credentials are used.
 func (p ProviderType) EnvVar() string {
 	switch p {
 	case ProviderOpenAI:
@@ -68,6 +75,8 @@ func (p ProviderType) EnvVar() string {
 		return "DEEPSEEK_API_KEY"
 	case ProviderGemini:
 		return "GEMINI_API_KEY"
+	case ProviderBedrock:
+		return "AWS_BEARER_TOKEN_BEDROCK"
 	default:
 		return ""
 	}
@@ -84,11 +93,18 @@ func (p ProviderType) DefaultModel() string {
 		return ModelDeepSeekV32
 	case ProviderGemini:
 		return ModelGeminiFlash3
+	case ProviderBedrock:
+		return ModelBedrockClaudeSonnet45
 	default:
 		return ""
 	}
 }
 
+// ProviderTypes returns all supported providers.
+func ProviderTypes() []ProviderType {
+	return []ProviderType{ProviderOpenAI, ProviderAnthropic, ProviderDeepSeek, ProviderGemini, ProviderBedrock}
+}
+
 // ParseProviderType parses a provider from string (case-insensitive).
 func ParseProviderType(s string) (ProviderType, error) {
 	switch strings.ToLower(s) {
@@ -100,6 +116,8 @@ func ParseProviderType(s string) (ProviderType, error) {
 		return ProviderDeepSeek, nil
 	case "gemini", "google":
 		return ProviderGemini, nil
+	case "bedrock", "aws":
+		return ProviderBedrock, nil
 	default:
 		return 0, fmt.Errorf("unknown provider: %s", s)
 	}
@@ -126,6 +144,15 @@ type ProviderBuilder struct {
 	model        string
 	maxTokens    uint32
 	temperature  *float32
+	httpClient   *http.Client
+	rateLimiter  *RateLimiter
+	anonymizer   *Anonymizer
+	region       string // Bedrock only
+	profile      string // Bedrock only
+	vertex       bool   // Gemini on Vertex AI
+	project      string // Vertex AI only
+	location     string // Vertex AI only
+	validate     bool
 }
 
 // NewProviderBuilder creates a new builder for the given provider.
@@ -153,11 +180,63 @@ func (b *ProviderBuilder) Temperature(temp float32) *ProviderBuilder {
 	return b
 }
 
+// HTTPClient sets the HTTP client for API calls (e.g. WireLog.HTTPClient).
+func (b *ProviderBuilder) HTTPClient(client *http.Client) *ProviderBuilder {
+	b.httpClient = client
+	return b
+}
+
+// RateLimiter makes the provider wait on limiter before each call. Share
+// one limiter between providers to keep them within a common budget.
+func (b *ProviderBuilder) RateLimiter(limiter *RateLimiter) *ProviderBuilder {
+	b.rateLimiter = limiter
+	return b
+}
+
+// Anonymizer hides the identifiers in anon's map from the provider,
+// restoring them in responses.
+func (b *ProviderBuilder) Anonymizer(anon *Anonymizer) *ProviderBuilder {
+	b.anonymizer = anon
+	return b
+}
+
+// Region sets the AWS region (Bedrock only; default from BEDROCK_REGION,
+// AWS_REGION or AWS_DEFAULT_REGION).
+func (b *ProviderBuilder) Region(region string) *ProviderBuilder {
+	b.region = region
+	return b
+}
+
+// Profile sets the AWS shared-credentials profile (Bedrock only; default
+// from AWS_PROFILE).
+func (b *ProviderBuilder) Profile(profile string) *ProviderBuilder {
+	b.profile = profile
+	return b
+}
+
+// Vertex runs Gemini on Vertex AI in project and location (e.g.
+// "us-central1" or "global"), authenticating with Application Default
+// Credentials instead of an API key.
+func (b *ProviderBuilder) Vertex(project, location string) *ProviderBuilder {
+	b.vertex = true
+	b.project = project
+	b.location = location
+	return b
+}
+
+// Validate checks the API key and model when the provider is built (see
+// Validate), so a bad configuration fails at startup with an actionable
+// error instead of on the first agent iteration.
+func (b *ProviderBuilder) Validate() *ProviderBuilder {
+	b.validate = true
+	return b
+}
+
 // FromEnv builds the provider, reading API key from environment.
 func (b *ProviderBuilder) FromEnv() (Provider, error) {
 	envVar := b.providerType.EnvVar()
 	apiKey := os.Getenv(envVar)
-	if apiKey == "" {
+	if apiKey == "" && !b.keyOptional() {
 		return nil, fmt.Errorf("%s: %s environment variable not set", b.providerType, envVar)
 	}
 	return b.build(apiKey)
@@ -184,18 +263,53 @@ func (b *ProviderBuilder) build(apiKey string) (Provider, error) {
 		temperature = *b.temperature
 	}
 
+	var provider Provider
 	switch b.providerType {
 	case ProviderOpenAI:
-		return NewOpenAIProvider(apiKey, model, maxTokens, temperature), nil
+		provider = newOpenAIProvider(apiKey, model, maxTokens, temperature, b.httpClient)
 	case ProviderAnthropic:
-		return NewAnthropicProvider(apiKey, model, maxTokens, temperature), nil
+		provider = newAnthropicProvider(apiKey, model, maxTokens, temperature, b.httpClient)
 	case ProviderDeepSeek:
-		return NewDeepSeekProvider(apiKey, model, maxTokens, temperature), nil
+		provider = newDeepSeekProvider(apiKey, model, maxTokens, temperature, b.httpClient)
 	case ProviderGemini:
-		return NewGeminiProvider(apiKey, model, maxTokens, temperature), nil
+		if !b.vertex {
+			provider = newGeminiProvider(apiKey, model, maxTokens, temperature, b.httpClient)
+			break
+		}
+		gemini := newVertexGeminiProvider(b.project, b.location, model, maxTokens, temperature, b.httpClient)
+		if gemini.initErr != nil {
+			return nil, gemini.initErr
+		}
+		provider = gemini
+	case ProviderBedrock:
+		bedrock := newBedrockProvider(apiKey, b.region, b.profile, model, maxTokens, temperature, b.httpClient)
+		if bedrock.initErr != nil {
+			return nil, bedrock.initErr
+		}
+		provider = bedrock
 	default:
 		return nil, fmt.Errorf("unknown provider type: %v", b.providerType)
 	}
+	if b.validate {
+		ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+		defer cancel()
+		if err := Validate(ctx, provider); err != nil {
+			return nil, err
+		}
+	}
+	if b.anonymizer != nil {
+		provider = b.anonymizer.Wrap(provider)
+	}
+	if b.rateLimiter != nil {
+		provider = b.rateLimiter.Wrap(provider)
+	}
+	return provider, nil
+}
+
+// keyOptional reports whether the provider can authenticate without an API
+// key (Bedrock with AWS credentials, Gemini on Vertex AI).
+func (b *ProviderBuilder) keyOptional() bool {
+	return b.providerType == ProviderBedrock || (b.providerType == ProviderGemini && b.vertex)
 }
 
 // Model identifier constants for all supported providers.
@@ -251,3 +365,16 @@ const (
 	// ModelGeminiPro2 is Gemini 2.0 Pro: Legacy model.
 	ModelGeminiPro2 = "gemini-2.0-pro"
 )
+
+// Bedrock model identifiers (January 2026). Newer models may need a
+// cross-region inference profile ID instead, e.g. "us." + the model ID.
+const (
+	// ModelBedrockClaudeOpus45 is Claude Opus 4.5 on Bedrock.
+	ModelBedrockClaudeOpus45 = "anthropic.claude-opus-4-5-20251101-v1:0"
+	// ModelBedrockClaudeSonnet45 is Claude Sonnet 4.5 on Bedrock.
+	ModelBedrockClaudeSonnet45 = "anthropic.claude-sonnet-4-5-20250929-v1:0"
+	// ModelBedrockClaudeHaiku45 is Claude Haiku 4.5 on Bedrock.
+	ModelBedrockClaudeHaiku45 = "anthropic.claude-haiku-4-5-20251001-v1:0"
+	// ModelBedrockNovaPro is Amazon Nova Pro.
+	ModelBedrockNovaPro = "amazon.nova-pro-v1:0"
+)
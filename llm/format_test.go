@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// captureRequests serves a canned chat completion and returns a client
+// routing every request to it, plus the decoded request bodies.
+func captureRequests(t *testing.T) (*http.Client, *[]map[string]any) {
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("request body is not JSON: %s", data)
+		}
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	target, _ := url.Parse(server.URL)
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	return client, &bodies
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestJSONSchemaFormatPerProvider(t *testing.T) {
+	format := NewJSONSchemaFormat("decision", json.RawMessage(`{"type":"object"}`))
+	messages := []ChatMessage{{Role: "user", Content: "hi"}}
+	httpClient, bodies := captureRequests(t)
+
+	openaiProvider := newOpenAIProvider("sk-test", "gpt-4o", 100, 0, httpClient)
+	if _, err := openaiProvider.ChatWithFormat(context.Background(), messages, format); err != nil {
+		t.Fatalf("openai ChatWithFormat failed: %v", err)
+	}
+	rf, _ := (*bodies)[0]["response_format"].(map[string]any)
+	schema, _ := rf["json_schema"].(map[string]any)
+	if rf["type"] != "json_schema" || schema["name"] != "decision" || schema["strict"] != true {
+		t.Errorf("openai should send the schema, got %v", rf)
+	}
+
+	deepseek := newDeepSeekProvider("sk-test", "deepseek-chat", 100, 0, httpClient)
+	if _, err := deepseek.ChatWithFormat(context.Background(), messages, format); err != nil {
+		t.Fatalf("deepseek ChatWithFormat failed: %v", err)
+	}
+	rf, _ = (*bodies)[1]["response_format"].(map[string]any)
+	if rf["type"] != "json_object" || rf["json_schema"] != nil {
+		t.Errorf("deepseek should fall back to JSON mode, got %v", rf)
+	}
+
+	config := &genai.GenerateContentConfig{}
+	applyGeminiFormat(config, format)
+	if config.ResponseMIMEType != "application/json" || config.ResponseJsonSchema == nil {
+		t.Errorf("gemini should get JSON mode with the schema, got %+v", config)
+	}
+
+	if !NewClient(openaiProvider).SupportsJSONSchema() || NewClient(deepseek).SupportsJSONSchema() {
+		t.Error("unexpected SupportsJSONSchema results")
+	}
+}
@@ -1,7 +1,8 @@
 // Google Gemini Provider implementation using official google.golang.org/genai SDK.
 //
 // Information Hiding:
-// - API authentication and client creation
+// - API authentication and client creation (API key, or Vertex AI with
+//   Application Default Credentials)
 // - Request/response format for Gemini API
 // - System instruction handling via config
 // - Streaming via official SDK iterator
@@ -12,10 +13,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
+	"cloud.google.com/go/auth/credentials"
+	"cloud.google.com/go/auth/httptransport"
 	"google.golang.org/genai"
 )
 
+// vertexScope is the OAuth scope Vertex AI calls need.
+const vertexScope = "https://www.googleapis.com/auth/cloud-platform"
+
 // GeminiProvider implements the Provider interface for Google Gemini.
 type GeminiProvider struct {
 	client      *genai.Client
@@ -28,10 +36,16 @@ type GeminiProvider struct {
 // NewGeminiProvider creates a new Gemini provider.
 // If client initialization fails, the error is stored and returned on first use.
 func NewGeminiProvider(apiKey, model string, maxTokens uint32, temperature float32) *GeminiProvider {
+	return newGeminiProvider(apiKey, model, maxTokens, temperature, nil)
+}
+
+// newGeminiProvider creates a Gemini provider using httpClient (nil for the default).
+func newGeminiProvider(apiKey, model string, maxTokens uint32, temperature float32, httpClient *http.Client) *GeminiProvider {
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: httpClient,
 	})
 	if err != nil {
 		// Store initialization error to return on first use - preserves constructor signature
@@ -53,6 +67,55 @@ func NewGeminiProvider(apiKey, model string, maxTokens uint32, temperature float
 	}
 }
 
+// NewVertexGeminiProvider creates a Gemini provider on the Vertex AI backend
+// for project and location, authenticated with Application Default
+// Credentials (GOOGLE_APPLICATION_CREDENTIALS, gcloud login or the metadata
+// server). If client initialization fails, the error is returned on first use.
+func NewVertexGeminiProvider(project, location, model string, maxTokens uint32, temperature float32) *GeminiProvider {
+	return newVertexGeminiProvider(project, location, model, maxTokens, temperature, nil)
+}
+
+// newVertexGeminiProvider creates a Vertex AI Gemini provider using
+// httpClient (nil for the default) underneath the credentials.
+func newVertexGeminiProvider(project, location, model string, maxTokens uint32, temperature float32, httpClient *http.Client) *GeminiProvider {
+	p := &GeminiProvider{
+		model:       model,
+		maxTokens:   int32(maxTokens),
+		temperature: temperature,
+	}
+
+	creds, err := credentials.DetectDefault(&credentials.DetectOptions{Scopes: []string{vertexScope}})
+	if err != nil {
+		p.initErr = fmt.Errorf("failed to find Google application default credentials: %w", err)
+		return p
+	}
+	config := &genai.ClientConfig{
+		Backend:     genai.BackendVertexAI,
+		Project:     project,
+		Location:    location,
+		Credentials: creds,
+	}
+	if httpClient != nil {
+		// A custom client bypasses the SDK's own authentication, so the
+		// credentials go on its transport
+		config.HTTPClient, err = httptransport.NewClient(&httptransport.Options{
+			Credentials:      creds,
+			BaseRoundTripper: httpClient.Transport,
+		})
+		if err != nil {
+			p.initErr = fmt.Errorf("failed to initialize Vertex AI client: %w", err)
+			return p
+		}
+		config.HTTPClient.Timeout = httpClient.Timeout
+	}
+
+	p.client, err = genai.NewClient(context.Background(), config)
+	if err != nil {
+		p.initErr = fmt.Errorf("failed to initialize Vertex AI client: %w", err)
+	}
+	return p
+}
+
 // Name returns the provider name.
 func (p *GeminiProvider) Name() string {
 	return "gemini"
@@ -63,13 +126,29 @@ func (p *GeminiProvider) Model() string {
 	return p.model
 }
 
+// ListModels returns the names of the base models the credentials can use,
+// without their "models/" or publisher path.
+func (p *GeminiProvider) ListModels(ctx context.Context) ([]string, error) {
+	if p.initErr != nil {
+		return nil, p.initErr
+	}
+	var models []string
+	for model, err := range p.client.Models.All(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("list models failed: %w", err)
+		}
+		models = append(models, model.Name[strings.LastIndex(model.Name, "/")+1:])
+	}
+	return models, nil
+}
+
 // Chat sends a chat completion request.
 func (p *GeminiProvider) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
 	return p.ChatWithFormat(ctx, messages, nil)
 }
 
 // ChatWithFormat sends a chat completion request with optional response format.
-func (p *GeminiProvider) ChatWithFormat(ctx context.Context, messages []ChatMessage, _ *ResponseFormat) (LLMResponse, error) {
+func (p *GeminiProvider) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
 	if p.initErr != nil {
 		return LLMResponse{}, p.initErr
 	}
@@ -87,6 +166,7 @@ func (p *GeminiProvider) ChatWithFormat(ctx context.Context, messages []ChatMess
 	if systemInstruction != "" {
 		config.SystemInstruction = genai.NewContentFromText(systemInstruction, genai.RoleUser)
 	}
+	applyGeminiFormat(config, format)
 
 	response, err := p.client.Models.GenerateContent(ctx, p.model, contents, config)
 	if err != nil {
@@ -110,6 +190,22 @@ func (p *GeminiProvider) ChatWithFormat(ctx context.Context, messages []ChatMess
 	return LLMResponse{Content: content, Usage: usage}, nil
 }
 
+// applyGeminiFormat maps a response format onto Gemini's JSON output mode.
+func applyGeminiFormat(config *genai.GenerateContentConfig, format *ResponseFormat) {
+	if format == nil || format.Type == ResponseFormatText {
+		return
+	}
+	config.ResponseMIMEType = "application/json"
+	if format.JSONSchema != nil {
+		config.ResponseJsonSchema = format.JSONSchema.Schema
+	}
+}
+
+// SupportsJSONSchema reports that response schemas are enforced.
+func (p *GeminiProvider) SupportsJSONSchema() bool {
+	return true
+}
+
 // ChatWithTools sends a chat completion request with tool definitions.
 func (p *GeminiProvider) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
 	if p.initErr != nil {
@@ -0,0 +1,121 @@
+// Per-provider HTTP client settings.
+//
+// A slow or unreachable provider otherwise holds a run until the TCP stack
+// gives up. NewHTTPClient builds the client a provider's SDK uses from
+// HTTPOptions: overall and connect timeouts, a proxy, extra trusted CAs,
+// and headers added to every request (for gateways that route or
+// authenticate on them). Pass the result to ProviderBuilder.HTTPClient:
+//
+//	client, err := llm.NewHTTPClient(llm.HTTPOptions{Timeout: 2 * time.Minute})
+//	provider, err := llm.NewProviderBuilder(llm.ProviderOpenAI).
+//	    HTTPClient(client).
+//	    FromEnv()
+//
+// Information Hiding:
+// - Transport cloning and TLS setup hidden
+// - Header injection hidden
+
+package llm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPOptions configures a provider's HTTP client. Zero fields keep Go's
+// defaults.
+type HTTPOptions struct {
+	// Timeout bounds a whole request, including reading a streamed
+	// response (0 = no limit)
+	Timeout time.Duration
+	// ConnectTimeout bounds establishing the connection (0 = 30s)
+	ConnectTimeout time.Duration
+	// Proxy is the proxy URL; empty uses HTTP_PROXY, HTTPS_PROXY and
+	// NO_PROXY
+	Proxy string
+	// CACertFile is a PEM bundle trusted in addition to the system roots
+	CACertFile string
+	// InsecureSkipVerify turns off certificate verification (testing only)
+	InsecureSkipVerify bool
+	// Headers are set on every request
+	Headers map[string]string
+}
+
+// IsZero reports whether o leaves every default in place.
+func (o HTTPOptions) IsZero() bool {
+	return o.Timeout == 0 && o.ConnectTimeout == 0 && o.Proxy == "" &&
+		o.CACertFile == "" && !o.InsecureSkipVerify && len(o.Headers) == 0
+}
+
+// NewHTTPClient returns a client configured by opts, on a transport of its
+// own so settings for one provider never affect another.
+func NewHTTPClient(opts HTTPOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.ConnectTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   opts.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		transport.TLSHandshakeTimeout = opts.ConnectTimeout
+	}
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if opts.CACertFile != "" || opts.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+		if opts.CACertFile != "" {
+			pool, err := certPool(opts.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var rt http.RoundTripper = transport
+	if len(opts.Headers) > 0 {
+		rt = &headerTransport{headers: opts.Headers, next: transport}
+	}
+	return &http.Client{Transport: rt, Timeout: opts.Timeout}, nil
+}
+
+// certPool returns the system roots plus the certificates in path.
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
+
+// headerTransport sets fixed headers on every request.
+type headerTransport struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.next.RoundTrip(req)
+}
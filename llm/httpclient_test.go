@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClientHeadersAndTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		io.WriteString(w, r.Header.Get("X-Gateway-Route")+"|"+r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(HTTPOptions{
+		Timeout: 50 * time.Millisecond,
+		Headers: map[string]string{"X-Gateway-Route": "team-a"},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Authorization", "Bearer sk-test")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "team-a|Bearer sk-test" {
+		t.Errorf("unexpected headers at server: %q", body)
+	}
+	if req.Header.Get("X-Gateway-Route") != "" {
+		t.Error("caller's request should not be modified")
+	}
+
+	if _, err := client.Get(server.URL + "/slow"); err == nil {
+		t.Error("expected the request timeout to fire")
+	}
+}
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(HTTPOptions{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	resp, err := client.Get("http://api.example.invalid/v1/models")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if proxied != "http://api.example.invalid/v1/models" {
+		t.Errorf("request did not go through the proxy: %q", proxied)
+	}
+}
+
+func TestNewHTTPClientErrors(t *testing.T) {
+	if _, err := NewHTTPClient(HTTPOptions{Proxy: "not a url"}); err == nil {
+		t.Error("expected an error for a bad proxy URL")
+	}
+	if _, err := NewHTTPClient(HTTPOptions{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(path, []byte("not a certificate"), 0o600)
+	if _, err := NewHTTPClient(HTTPOptions{CACertFile: path}); err == nil || !strings.Contains(err.Error(), "no PEM") {
+		t.Errorf("expected a no-certificates error, got %v", err)
+	}
+}
+
+func TestHTTPOptionsIsZero(t *testing.T) {
+	if !(HTTPOptions{}).IsZero() {
+		t.Error("empty options should be zero")
+	}
+	if (HTTPOptions{ConnectTimeout: time.Second}).IsZero() {
+		t.Error("options with a connect timeout should not be zero")
+	}
+}
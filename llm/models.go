@@ -61,6 +61,20 @@ type TokenUsage struct {
 	PromptTokens     uint32
 	CompletionTokens uint32
 	TotalTokens      uint32
+	// CachedPromptTokens is the part of PromptTokens served from the
+	// provider's prompt cache (cheaper), when the provider reports it.
+	CachedPromptTokens uint32
+}
+
+// Add adds other's counts to u. A nil other is ignored.
+func (u *TokenUsage) Add(other *TokenUsage) {
+	if other == nil {
+		return
+	}
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.CachedPromptTokens += other.CachedPromptTokens
 }
 
 // ResponseFormatType defines the type of response format.
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	openai "github.com/sashabaranov/go-openai"
 )
@@ -26,8 +27,17 @@ type OpenAIProvider struct {
 
 // NewOpenAIProvider creates a new OpenAI provider.
 func NewOpenAIProvider(apiKey, model string, maxTokens uint32, temperature float32) *OpenAIProvider {
+	return newOpenAIProvider(apiKey, model, maxTokens, temperature, nil)
+}
+
+// newOpenAIProvider creates an OpenAI provider using httpClient (nil for the default).
+func newOpenAIProvider(apiKey, model string, maxTokens uint32, temperature float32, httpClient *http.Client) *OpenAIProvider {
+	config := openai.DefaultConfig(apiKey)
+	if httpClient != nil {
+		config.HTTPClient = httpClient
+	}
 	return &OpenAIProvider{
-		client:      openai.NewClient(apiKey),
+		client:      openai.NewClientWithConfig(config),
 		model:       model,
 		maxTokens:   int(maxTokens),
 		temperature: temperature,
@@ -44,6 +54,11 @@ func (p *OpenAIProvider) Model() string {
 	return p.model
 }
 
+// ListModels returns the IDs of the models the API key can use.
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]string, error) {
+	return listOpenAIModels(ctx, p.client)
+}
+
 // isBetaModel checks if a model has beta restrictions
 func (p *OpenAIProvider) isBetaModel() bool {
 	// gpt-5.2 and other beta models have fixed parameters
@@ -76,6 +91,14 @@ func (p *OpenAIProvider) ChatWithFormat(ctx context.Context, messages []ChatMess
 		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
 			Type: openai.ChatCompletionResponseFormatType(format.Type),
 		}
+		if format.JSONSchema != nil {
+			req.ResponseFormat.JSONSchema = &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:        format.JSONSchema.Name,
+				Description: format.JSONSchema.Description,
+				Schema:      format.JSONSchema.Schema,
+				Strict:      format.JSONSchema.Strict,
+			}
+		}
 	}
 
 	resp, err := p.client.CreateChatCompletion(ctx, req)
@@ -88,15 +111,16 @@ func (p *OpenAIProvider) ChatWithFormat(ctx context.Context, messages []ChatMess
 		content = resp.Choices[0].Message.Content
 	}
 
-	usage := &TokenUsage{
-		PromptTokens:     uint32(resp.Usage.PromptTokens),
-		CompletionTokens: uint32(resp.Usage.CompletionTokens),
-		TotalTokens:      uint32(resp.Usage.TotalTokens),
-	}
+	usage := usageFromOpenAI(resp.Usage)
 
 	return LLMResponse{Content: content, Usage: usage}, nil
 }
 
+// SupportsJSONSchema reports that structured outputs are enforced.
+func (p *OpenAIProvider) SupportsJSONSchema() bool {
+	return true
+}
+
 // ChatWithTools sends a chat completion request with tool definitions.
 func (p *OpenAIProvider) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
 	req := openai.ChatCompletionRequest{
@@ -126,11 +150,7 @@ func (p *OpenAIProvider) ChatWithTools(ctx context.Context, messages []ChatMessa
 		}
 	}
 
-	usage := &TokenUsage{
-		PromptTokens:     uint32(resp.Usage.PromptTokens),
-		CompletionTokens: uint32(resp.Usage.CompletionTokens),
-		TotalTokens:      uint32(resp.Usage.TotalTokens),
-	}
+	usage := usageFromOpenAI(resp.Usage)
 
 	return LLMResponse{Content: content, ToolCalls: toolCalls, Usage: usage}, nil
 }
@@ -166,11 +186,7 @@ func (p *OpenAIProvider) StreamChat(ctx context.Context, messages []ChatMessage,
 
 		// Capture token usage from final chunk
 		if response.Usage != nil {
-			usage = &TokenUsage{
-				PromptTokens:     uint32(response.Usage.PromptTokens),
-				CompletionTokens: uint32(response.Usage.CompletionTokens),
-				TotalTokens:      uint32(response.Usage.TotalTokens),
-			}
+			usage = usageFromOpenAI(*response.Usage)
 		}
 
 		if len(response.Choices) > 0 {
@@ -249,3 +265,31 @@ func convertToOpenAITools(tools []ToolDefinition) []openai.Tool {
 
 // Verify OpenAIProvider implements Provider
 var _ Provider = (*OpenAIProvider)(nil)
+
+// listOpenAIModels lists model IDs through an OpenAI-compatible API
+// (OpenAI and DeepSeek).
+func listOpenAIModels(ctx context.Context, client *openai.Client) ([]string, error) {
+	list, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list models failed: %w", err)
+	}
+	models := make([]string, len(list.Models))
+	for i, m := range list.Models {
+		models[i] = m.ID
+	}
+	return models, nil
+}
+
+// usageFromOpenAI converts OpenAI-format usage, including prompt tokens
+// served from the prompt cache (reported by OpenAI and DeepSeek).
+func usageFromOpenAI(u openai.Usage) *TokenUsage {
+	usage := &TokenUsage{
+		PromptTokens:     uint32(u.PromptTokens),
+		CompletionTokens: uint32(u.CompletionTokens),
+		TotalTokens:      uint32(u.TotalTokens),
+	}
+	if u.PromptTokensDetails != nil {
+		usage.CachedPromptTokens = uint32(u.PromptTokensDetails.CachedTokens)
+	}
+	return usage
+}
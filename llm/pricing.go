@@ -0,0 +1,79 @@
+// Model pricing for cost estimates.
+//
+// Prices are list prices per million tokens at the time of writing, used
+// only to show a rough running cost; they are not billing data. Models not
+// listed have no estimate. Where the provider discounts prompt-cache hits
+// (DeepSeek, OpenAI, Anthropic reads), the cache-hit price is listed too.
+//
+// Information Hiding:
+// - Price table and model name matching hidden
+
+package llm
+
+// ModelPrice is a model's price in USD per million tokens.
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+	// CachedInputPerMillion applies to prompt tokens served from the
+	// provider's prompt cache; 0 means cache hits cost the input price.
+	CachedInputPerMillion float64
+}
+
+// modelPrices is keyed by model ID or ID prefix (dated variants match
+// their base name).
+var modelPrices = map[string]ModelPrice{
+	ModelOpenAIGPT52:      {1.75, 14, 0.175},
+	ModelOpenAIGPT52Codex: {1.75, 14, 0.175},
+	ModelOpenAIGPT5:       {1.25, 10, 0.125},
+	ModelOpenAIO3Mini:     {1.10, 4.40, 0.55},
+	ModelOpenAIO1:         {15, 60, 7.50},
+	ModelOpenAIGPT4o:      {2.50, 10, 1.25},
+	ModelOpenAIGPT4oMini:  {0.15, 0.60, 0.075},
+
+	"claude-opus-4-5": {5, 25, 0.50},
+	"claude-sonnet-4": {3, 15, 0.30},
+	"claude-haiku-4":  {1, 5, 0.10},
+
+	ModelDeepSeekV32:    {0.28, 0.42, 0.028},
+	ModelDeepSeekV31:    {0.27, 1.10, 0.07},
+	ModelDeepSeekR1:     {0.55, 2.19, 0.14},
+	"deepseek-chat":     {0.28, 0.42, 0.028},
+	"deepseek-reasoner": {0.28, 0.42, 0.028},
+
+	ModelGeminiPro3:    {2, 12, 0.20},
+	ModelGeminiFlash3:  {0.50, 3, 0.05},
+	"gemini-2.5-pro":   {1.25, 10, 0.125},
+	"gemini-2.5-flash": {0.30, 2.50, 0.03},
+	ModelGeminiFlash2:  {0.10, 0.40, 0.025},
+
+	ModelBedrockNovaPro: {0.80, 3.20, 0.20},
+}
+
+// bedrockPrefixes are stripped from Bedrock model and inference profile IDs
+// (us.anthropic.claude-sonnet-4-...) so Claude models find their price.
+var bedrockPrefixes = []string{"us.", "eu.", "apac.", "global.", "anthropic."}
+
+// PriceFor returns the price of model, matching the longest listed prefix
+// so that dated IDs (claude-sonnet-4-20250514) find their base model.
+// Bedrock IDs are matched without their region and vendor prefixes.
+func PriceFor(model string) (ModelPrice, bool) {
+	return lookupModel(modelPrices, model)
+}
+
+// EstimateCost returns the approximate USD cost of usage on model, and
+// false if the model has no known price. Cached prompt tokens are billed at
+// the cache-hit rate.
+func EstimateCost(model string, usage TokenUsage) (float64, bool) {
+	price, ok := PriceFor(model)
+	if !ok {
+		return 0, false
+	}
+	cachedRate := price.CachedInputPerMillion
+	if cachedRate == 0 {
+		cachedRate = price.InputPerMillion
+	}
+	cached := min(usage.CachedPromptTokens, usage.PromptTokens)
+	return (float64(usage.PromptTokens-cached)*price.InputPerMillion +
+		float64(cached)*cachedRate +
+		float64(usage.CompletionTokens)*price.OutputPerMillion) / 1e6, true
+}
@@ -0,0 +1,29 @@
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	usage := TokenUsage{PromptTokens: 1_000_000, CompletionTokens: 100_000, TotalTokens: 1_100_000}
+
+	cost, ok := EstimateCost(ModelAnthropicClaudeSonnet4, usage)
+	if !ok || math.Abs(cost-4.5) > 1e-9 {
+		t.Errorf("dated model should match its base price: %v, %v", cost, ok)
+	}
+	if cost, ok := EstimateCost(ModelOpenAIGPT52Codex, usage); !ok || math.Abs(cost-3.15) > 1e-9 {
+		t.Errorf("exact model should use its own price: %v, %v", cost, ok)
+	}
+	if cost, ok := EstimateCost("us."+ModelBedrockClaudeSonnet45, usage); !ok || math.Abs(cost-4.5) > 1e-9 {
+		t.Errorf("Bedrock IDs should match the Claude price: %v, %v", cost, ok)
+	}
+	if _, ok := EstimateCost("local-model", usage); ok {
+		t.Error("unknown models should have no estimate")
+	}
+
+	cached := TokenUsage{PromptTokens: 1_000_000, CachedPromptTokens: 800_000, TotalTokens: 1_000_000}
+	if cost, ok := EstimateCost(ModelDeepSeekV32, cached); !ok || math.Abs(cost-(0.2*0.28+0.8*0.028)) > 1e-9 {
+		t.Errorf("cache hits should use the cached input price: %v, %v", cost, ok)
+	}
+}
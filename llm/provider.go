@@ -37,3 +37,10 @@ type Provider interface {
 	// Returns token usage (available in final chunk when supported by provider).
 	StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error)
 }
+
+// SchemaProvider is implemented by providers that can constrain output to
+// the JSON schema of a ResponseFormatJSONSchema format. Other providers
+// ignore the schema, so callers must still validate what comes back.
+type SchemaProvider interface {
+	SupportsJSONSchema() bool
+}
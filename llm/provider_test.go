@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	openai "github.com/sashabaranov/go-openai"
 )
 
 // TestOpenAIErrorNoAPIKeyLeak verifies OpenAI errors don't contain API keys
@@ -195,3 +197,24 @@ func TestToolCallErrorNoAPIKeyLeak(t *testing.T) {
 		t.Errorf("Tool call error message leaked API key: %v", errStr)
 	}
 }
+
+// TestUsageFromOpenAICachedTokens verifies prompt cache hits are reported
+func TestUsageFromOpenAICachedTokens(t *testing.T) {
+	usage := usageFromOpenAI(openai.Usage{
+		PromptTokens:        1200,
+		CompletionTokens:    300,
+		TotalTokens:         1500,
+		PromptTokensDetails: &openai.PromptTokensDetails{CachedTokens: 1024},
+	})
+	if usage.PromptTokens != 1200 || usage.TotalTokens != 1500 || usage.CachedPromptTokens != 1024 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+
+	var total TokenUsage
+	total.Add(usage)
+	total.Add(nil)
+	total.Add(usageFromOpenAI(openai.Usage{PromptTokens: 10, TotalTokens: 10}))
+	if total.PromptTokens != 1210 || total.CachedPromptTokens != 1024 {
+		t.Errorf("Add should sum usage and skip nil: %+v", total)
+	}
+}
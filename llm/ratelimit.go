@@ -0,0 +1,283 @@
+// Provider-level rate limiting shared across agents.
+//
+// A RateLimiter keeps a requests-per-minute and a tokens-per-minute token
+// bucket for each provider/model pair. Providers built with
+// ProviderBuilder.RateLimiter (or wrapped with RateLimiter.Wrap) wait on
+// both buckets before every call, so parallel spawns, supervisors and
+// verifiers draw from one budget instead of each hitting the API on its own:
+//
+//	limiter := llm.NewRateLimiter(llm.RateLimit{RequestsPerMinute: 500})
+//	limiter.SetLimit("openai", "", llm.RateLimit{TokensPerMinute: 200000})
+//	provider, err := llm.ProviderOpenAI.Model(llm.ModelOpenAIGPT52).
+//	    RateLimiter(limiter).
+//	    FromEnv()
+//
+// Information Hiding:
+// - Bucket refill and reservation arithmetic hidden
+// - Prompt token estimation (corrected once usage is reported) hidden
+
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// bytesPerToken approximates prompt size before the provider reports usage.
+const bytesPerToken = 4
+
+// RateLimit is the allowance for one provider/model. Zero fields are
+// unlimited.
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// RateLimitStats reports queuing for one provider/model.
+type RateLimitStats struct {
+	Requests  int64         // Calls admitted
+	Queued    int64         // Calls that had to wait
+	Waiting   int           // Calls waiting right now
+	TotalWait time.Duration // Time spent waiting, summed over calls
+}
+
+// RateLimiter shares request and token budgets between every provider
+// that consults it. Safe for concurrent use.
+type RateLimiter struct {
+	mu       sync.Mutex
+	fallback RateLimit
+	limits   map[string]RateLimit // "provider/model" or "provider/"
+	buckets  map[string]*rateBuckets
+	now      func() time.Time
+}
+
+// rateBuckets holds the buckets and stats of one provider/model.
+type rateBuckets struct {
+	requests *bucket // nil when unlimited
+	tokens   *bucket
+	stats    RateLimitStats
+}
+
+// NewRateLimiter creates a limiter applying fallback to every
+// provider/model without its own limit.
+func NewRateLimiter(fallback RateLimit) *RateLimiter {
+	return &RateLimiter{
+		fallback: fallback,
+		limits:   make(map[string]RateLimit),
+		buckets:  make(map[string]*rateBuckets),
+		now:      time.Now,
+	}
+}
+
+// SetLimit sets the limit for a provider's model, or for all its models
+// when model is "". Takes effect for buckets not yet in use.
+func (l *RateLimiter) SetLimit(provider, model string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[provider+"/"+model] = limit
+}
+
+// Wait blocks until provider/model may send a request of about tokens
+// tokens, or ctx ends. The tokens are reserved; correct the estimate with
+// Adjust once actual usage is known.
+func (l *RateLimiter) Wait(ctx context.Context, provider, model string, tokens int) error {
+	l.mu.Lock()
+	b := l.bucketsFor(provider, model)
+	now := l.now()
+	wait := max(b.requests.reserve(1, now), b.tokens.reserve(float64(tokens), now))
+	b.stats.Requests++
+	if wait <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	b.stats.Queued++
+	b.stats.Waiting++
+	b.stats.TotalWait += wait
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		l.mu.Lock()
+		b.stats.Waiting--
+		l.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		// Give the reservation back so other callers aren't held up by it
+		l.mu.Lock()
+		b.stats.Waiting--
+		b.stats.Requests--
+		b.requests.release(1)
+		b.tokens.release(float64(tokens))
+		l.mu.Unlock()
+		return fmt.Errorf("waiting for %s/%s rate limit: %w", provider, model, ctx.Err())
+	}
+}
+
+// Adjust corrects a token reservation by delta (actual minus estimated).
+func (l *RateLimiter) Adjust(provider, model string, delta int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucketsFor(provider, model)
+	b.tokens.refill(l.now())
+	b.tokens.release(float64(-delta))
+}
+
+// Stats returns queuing stats keyed by "provider/model".
+func (l *RateLimiter) Stats() map[string]RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := make(map[string]RateLimitStats, len(l.buckets))
+	for key, b := range l.buckets {
+		stats[key] = b.stats
+	}
+	return stats
+}
+
+// Wrap returns p limited by l. Calls wait for both buckets, and the token
+// reservation (estimated from the prompt) is corrected by reported usage.
+func (l *RateLimiter) Wrap(p Provider) Provider {
+	return &rateLimitedProvider{Provider: p, limiter: l}
+}
+
+// bucketsFor returns the buckets of provider/model, creating them from the
+// most specific limit. Callers hold l.mu.
+func (l *RateLimiter) bucketsFor(provider, model string) *rateBuckets {
+	key := provider + "/" + model
+	if b, ok := l.buckets[key]; ok {
+		return b
+	}
+	limit, ok := l.limits[key]
+	if !ok {
+		limit, ok = l.limits[provider+"/"]
+	}
+	if !ok {
+		limit = l.fallback
+	}
+	now := l.now()
+	b := &rateBuckets{
+		requests: newBucket(limit.RequestsPerMinute, now),
+		tokens:   newBucket(limit.TokensPerMinute, now),
+	}
+	l.buckets[key] = b
+	return b
+}
+
+// bucket is a token bucket refilled continuously at perMinute/60 per second.
+// Reservations may take it negative; the deficit is the caller's wait.
+type bucket struct {
+	capacity  float64
+	available float64
+	rate      float64 // Per second
+	last      time.Time
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		rate:      float64(perMinute) / 60,
+		last:      now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b == nil {
+		return
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.available = min(b.capacity, b.available+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// reserve takes n and returns how long until the bucket is out of debt.
+func (b *bucket) reserve(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.refill(now)
+	b.available -= n
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.rate * float64(time.Second))
+}
+
+func (b *bucket) release(n float64) {
+	if b == nil {
+		return
+	}
+	b.available = min(b.capacity, b.available+n)
+}
+
+// rateLimitedProvider waits on a RateLimiter before each call.
+type rateLimitedProvider struct {
+	Provider
+	limiter *RateLimiter
+}
+
+func (p *rateLimitedProvider) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	return p.limited(ctx, messages, func() (LLMResponse, error) {
+		return p.Provider.Chat(ctx, messages)
+	})
+}
+
+func (p *rateLimitedProvider) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	return p.limited(ctx, messages, func() (LLMResponse, error) {
+		return p.Provider.ChatWithFormat(ctx, messages, format)
+	})
+}
+
+func (p *rateLimitedProvider) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	return p.limited(ctx, messages, func() (LLMResponse, error) {
+		return p.Provider.ChatWithTools(ctx, messages, tools)
+	})
+}
+
+func (p *rateLimitedProvider) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error) {
+	estimate := estimatePromptTokens(messages)
+	if err := p.limiter.Wait(ctx, p.Name(), p.Model(), estimate); err != nil {
+		return nil, err
+	}
+	usage, err := p.Provider.StreamChat(ctx, messages, chunks)
+	p.correct(estimate, usage)
+	return usage, err
+}
+
+// SupportsJSONSchema keeps the wrapped provider's SchemaProvider answer.
+func (p *rateLimitedProvider) SupportsJSONSchema() bool {
+	sp, ok := p.Provider.(SchemaProvider)
+	return ok && sp.SupportsJSONSchema()
+}
+
+func (p *rateLimitedProvider) limited(ctx context.Context, messages []ChatMessage, call func() (LLMResponse, error)) (LLMResponse, error) {
+	estimate := estimatePromptTokens(messages)
+	if err := p.limiter.Wait(ctx, p.Name(), p.Model(), estimate); err != nil {
+		return LLMResponse{}, err
+	}
+	response, err := call()
+	p.correct(estimate, response.Usage)
+	return response, err
+}
+
+func (p *rateLimitedProvider) correct(estimate int, usage *TokenUsage) {
+	if usage != nil {
+		p.limiter.Adjust(p.Name(), p.Model(), int(usage.TotalTokens)-estimate)
+	}
+}
+
+// estimatePromptTokens approximates the prompt size of messages.
+func estimatePromptTokens(messages []ChatMessage) int {
+	n := 0
+	for _, m := range messages {
+		n += len(m.Content)
+	}
+	return n / bytesPerToken
+}
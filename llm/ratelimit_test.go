@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// usageStub answers every call with fixed token usage.
+type usageStub struct {
+	usage TokenUsage
+}
+
+func (p *usageStub) Name() string             { return "stub" }
+func (p *usageStub) Model() string            { return "m" }
+func (p *usageStub) SupportsJSONSchema() bool { return true }
+
+func (p *usageStub) Chat(ctx context.Context, messages []ChatMessage) (LLMResponse, error) {
+	usage := p.usage
+	return LLMResponse{Content: "ok", Usage: &usage}, nil
+}
+
+func (p *usageStub) ChatWithFormat(ctx context.Context, messages []ChatMessage, format *ResponseFormat) (LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *usageStub) ChatWithTools(ctx context.Context, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *usageStub) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) (*TokenUsage, error) {
+	usage := p.usage
+	return &usage, nil
+}
+
+func TestRateLimiterQueuesOverBudget(t *testing.T) {
+	clock := time.Unix(0, 0)
+	limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 2})
+	limiter.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(ctx, "openai", "gpt", 0); err != nil {
+			t.Fatalf("call %d within budget failed: %v", i, err)
+		}
+	}
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(short, "openai", "gpt", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("third call should wait past the deadline, got %v", err)
+	}
+	stats := limiter.Stats()["openai/gpt"]
+	if stats.Requests != 2 || stats.Queued != 1 || stats.Waiting != 0 || stats.TotalWait != 30*time.Second {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	clock = clock.Add(30 * time.Second) // One request refilled
+	again, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(again, "openai", "gpt", 0); err != nil {
+		t.Fatalf("refilled bucket should admit without waiting: %v", err)
+	}
+}
+
+func TestRateLimiterLimitsPerProviderAndModel(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 1})
+	limiter.SetLimit("openai", "", RateLimit{RequestsPerMinute: 100})
+	limiter.SetLimit("openai", "small", RateLimit{})
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if b := limiter.bucketsFor("openai", "gpt"); b.requests.capacity != 100 {
+		t.Errorf("provider limit should apply to its models, got %v", b.requests.capacity)
+	}
+	if b := limiter.bucketsFor("openai", "small"); b.requests != nil {
+		t.Error("a zero model limit should be unlimited")
+	}
+	if b := limiter.bucketsFor("anthropic", "claude"); b.requests.capacity != 1 {
+		t.Errorf("other providers should get the fallback, got %v", b.requests.capacity)
+	}
+}
+
+func TestRateLimitedProviderCorrectsEstimate(t *testing.T) {
+	clock := time.Unix(0, 0)
+	limiter := NewRateLimiter(RateLimit{TokensPerMinute: 1000})
+	limiter.now = func() time.Time { return clock }
+	provider := limiter.Wrap(&usageStub{usage: TokenUsage{TotalTokens: 900}})
+
+	if _, err := provider.Chat(context.Background(), []ChatMessage{UserMessage("hi")}); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	limiter.mu.Lock()
+	available := limiter.buckets["stub/m"].tokens.available
+	limiter.mu.Unlock()
+	if available != 100 {
+		t.Errorf("reported usage should be charged, %v tokens left", available)
+	}
+
+	if sp, ok := provider.(SchemaProvider); !ok || !sp.SupportsJSONSchema() {
+		t.Error("wrapping should keep JSON schema support")
+	}
+}
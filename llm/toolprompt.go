@@ -0,0 +1,116 @@
+// Prompted tool calling for models without native tool support.
+//
+// Information Hiding:
+// - Tool descriptions rendered into the system prompt
+// - Tool call history flattened to plain messages
+// - Tool calls parsed back out of a JSON reply
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jsonutil "github.com/richinex/ariadne/internal/json"
+)
+
+// ChatWithTools sends messages with tool definitions to p. Models with
+// native tool calling (see Capabilities) use p.ChatWithTools; for others
+// the tools are described in the system prompt and tool calls are parsed
+// from a JSON reply, so callers see the same LLMResponse either way. An
+// empty reply is retried once (see retryEmpty).
+func ChatWithTools(ctx context.Context, p Provider, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	return retryEmpty(messages, func(messages []ChatMessage) (LLMResponse, error) {
+		return chatWithTools(ctx, p, messages, tools)
+	})
+}
+
+func chatWithTools(ctx context.Context, p Provider, messages []ChatMessage, tools []ToolDefinition) (LLMResponse, error) {
+	if len(tools) == 0 || CapabilitiesOf(p).Tools {
+		return p.ChatWithTools(ctx, messages, tools)
+	}
+	response, err := p.Chat(ctx, promptedToolMessages(messages, tools))
+	if err != nil {
+		return response, err
+	}
+	if calls := parsePromptedToolCalls(response.Content, len(messages)); len(calls) > 0 {
+		response.Content = ""
+		response.ToolCalls = calls
+	}
+	return response, nil
+}
+
+// promptedReply is the reply format the tool prompt asks for.
+type promptedReply struct {
+	ToolCalls []promptedCall `json:"tool_calls"`
+}
+
+// promptedCall is one tool call in a promptedReply.
+type promptedCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// promptedToolMessages returns messages with the tools described in the
+// system prompt and tool calls and results rewritten as plain text.
+func promptedToolMessages(messages []ChatMessage, tools []ToolDefinition) []ChatMessage {
+	var prompt strings.Builder
+	prompt.WriteString("You can call these tools:\n")
+	for _, tool := range tools {
+		params, _ := json.Marshal(tool.Parameters)
+		fmt.Fprintf(&prompt, "\n- %s: %s\n  Parameters (JSON Schema): %s\n", tool.Name, tool.Description, params)
+	}
+	prompt.WriteString("\nTo call tools, reply with only a JSON object, no other text:\n")
+	prompt.WriteString(`{"tool_calls": [{"name": "<tool name>", "arguments": {<arguments>}}]}`)
+	prompt.WriteString("\nTool results come back in the next message. To give your final answer, reply without a tool_calls object.")
+
+	out := make([]ChatMessage, 0, len(messages)+1)
+	if len(messages) == 0 || messages[0].Role != "system" {
+		out = append(out, SystemMessage(prompt.String()))
+	}
+	for i, msg := range messages {
+		switch {
+		case i == 0 && msg.Role == "system":
+			out = append(out, SystemMessage(msg.Content+"\n\n"+prompt.String()))
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			var calls promptedReply
+			for _, tc := range msg.ToolCalls {
+				calls.ToolCalls = append(calls.ToolCalls, promptedCall{Name: tc.Name, Arguments: tc.Arguments})
+			}
+			data, _ := json.Marshal(calls)
+			out = append(out, AssistantMessage(strings.TrimSpace(msg.Content+"\n"+string(data))))
+		case msg.Role == "tool":
+			out = append(out, UserMessage(fmt.Sprintf("Tool result (%s):\n%s", msg.ToolCallID, msg.Content)))
+		default:
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// parsePromptedToolCalls returns the tool calls in a prompted reply, or nil
+// if it is a final answer. IDs are unique within a conversation because
+// turn (the message count) grows with every call.
+func parsePromptedToolCalls(content string, turn int) []ToolCall {
+	if !strings.Contains(content, "tool_calls") {
+		return nil
+	}
+	var reply promptedReply
+	if err := jsonutil.ExtractJSONFromResponseWithType(content, &reply); err != nil {
+		return nil
+	}
+	calls := make([]ToolCall, 0, len(reply.ToolCalls))
+	for i, tc := range reply.ToolCalls {
+		if tc.Name == "" {
+			return nil
+		}
+		args := tc.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+		calls = append(calls, ToolCall{ID: fmt.Sprintf("call_%d_%d", turn, i), Name: tc.Name, Arguments: args})
+	}
+	return calls
+}
@@ -0,0 +1,102 @@
+// Provider validation.
+//
+// A wrong API key or model name otherwise surfaces on the first real call,
+// which may be deep into an orchestration. Validate checks both up front
+// with the cheapest call the provider offers.
+//
+// Information Hiding:
+// - Model listing per provider
+// - Choice of probe (model list or one-token chat)
+// - Suggestions of similar models in errors
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// validateTimeout bounds the probe run by ProviderBuilder.Validate.
+const validateTimeout = 15 * time.Second
+
+// maxSuggestedModels caps the models listed in a model-not-found error.
+const maxSuggestedModels = 10
+
+// ErrModelNotFound is returned by Validate when the provider doesn't offer
+// the configured model.
+var ErrModelNotFound = errors.New("model not found")
+
+// ModelLister is implemented by providers that can list the models their
+// credentials can use.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// Validate checks that p's credentials work and that its model exists.
+// Providers that list models are checked against the list, which costs no
+// tokens; others get a one-message chat. An unknown model is reported with
+// the available models most like it.
+func Validate(ctx context.Context, p Provider) error {
+	lister, ok := p.(ModelLister)
+	if !ok {
+		if _, err := p.Chat(ctx, []ChatMessage{UserMessage("Reply with OK.")}); err != nil {
+			return fmt.Errorf("%s: validating model %s failed (check the API key and model name): %w", p.Name(), p.Model(), err)
+		}
+		return nil
+	}
+
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: listing models failed (check the API key): %w", p.Name(), err)
+	}
+	model := p.Model()
+	for _, m := range models {
+		// Aliases (claude-sonnet-4-5) name a listed dated model
+		if m == model || strings.HasPrefix(m, model+"-") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w: %s — available: %s", p.Name(), ErrModelNotFound, model, suggestModels(model, models))
+}
+
+// suggestModels lists the models sharing the longest name prefix with
+// model, or all of them if none do, capped at maxSuggestedModels.
+func suggestModels(model string, models []string) string {
+	if len(models) == 0 {
+		return "none"
+	}
+	best := 0
+	var similar []string
+	for _, m := range models {
+		n := commonPrefixLen(model, m)
+		switch {
+		case n > best:
+			best = n
+			similar = []string{m}
+		case n == best:
+			similar = append(similar, m)
+		}
+	}
+	if best == 0 {
+		similar = models
+	}
+	similar = append([]string(nil), similar...)
+	sort.Strings(similar)
+	if len(similar) > maxSuggestedModels {
+		return fmt.Sprintf("%s and %d more", strings.Join(similar[:maxSuggestedModels], ", "), len(similar)-maxSuggestedModels)
+	}
+	return strings.Join(similar, ", ")
+}
+
+// commonPrefixLen returns the length of the common prefix of a and b.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// listingStub lists a fixed set of models.
+type listingStub struct {
+	usageStub
+	model  string
+	models []string
+	err    error
+}
+
+func (p *listingStub) Model() string { return p.model }
+
+func (p *listingStub) ListModels(ctx context.Context) ([]string, error) {
+	return p.models, p.err
+}
+
+func TestValidateModelList(t *testing.T) {
+	ctx := context.Background()
+	models := []string{"gpt-5", "gpt-5.2", "gpt-5.2-codex", "gpt-4o", "claude-sonnet-4-5-20250929"}
+
+	for _, model := range []string{"gpt-5.2", "claude-sonnet-4-5"} {
+		if err := Validate(ctx, &listingStub{model: model, models: models}); err != nil {
+			t.Errorf("%s should validate: %v", model, err)
+		}
+	}
+
+	err := Validate(ctx, &listingStub{model: "gpt-5.3", models: models})
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("expected ErrModelNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "available: gpt-5.2, gpt-5.2-codex") || strings.Contains(err.Error(), "claude") {
+		t.Errorf("error should suggest the similar models: %v", err)
+	}
+
+	err = Validate(ctx, &listingStub{model: "gpt-5.2", err: errors.New("401 unauthorized")})
+	if err == nil || !strings.Contains(err.Error(), "check the API key") {
+		t.Errorf("listing failures should point at the API key: %v", err)
+	}
+}
+
+func TestValidateFallsBackToChat(t *testing.T) {
+	if err := Validate(context.Background(), &usageStub{}); err != nil {
+		t.Errorf("a working chat should validate: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVertexNeedsNoAPIKey(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+
+	_, err := ProviderGemini.Model(ModelGeminiFlash3).Vertex("my-project", "us-central1").FromEnv()
+	if err == nil || strings.Contains(err.Error(), "GEMINI_API_KEY") {
+		t.Errorf("Vertex AI should fail on missing credentials, not the API key: %v", err)
+	}
+}
+
+func TestVertexWithServiceAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sa.json")
+	sa := `{"type": "service_account", "project_id": "my-project", "client_email": "bot@my-project.iam.gserviceaccount.com", "private_key": "unused", "token_uri": "https://oauth2.googleapis.com/token"}`
+	if err := os.WriteFile(path, []byte(sa), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+	provider, err := ProviderGemini.Model(ModelGeminiFlash3).Vertex("my-project", "us-central1").APIKey("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.Name() != "gemini" || provider.Model() != ModelGeminiFlash3 {
+		t.Errorf("unexpected provider %s/%s", provider.Name(), provider.Model())
+	}
+}
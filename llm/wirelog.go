@@ -0,0 +1,362 @@
+// LLM wire log - JSONL record of provider HTTP traffic for debugging.
+//
+// Each request/response pair becomes one JSON line: method, URL, status,
+// duration, request headers and both bodies. Streamed responses are
+// logged once the stream has been read. Use with ProviderBuilder.HTTPClient:
+//
+//	wire, err := llm.OpenWireLog(llm.WireLogOptions{Path: "llm-wire.jsonl"})
+//	provider, err := llm.NewProviderBuilder(llm.ProviderOpenAI).
+//	    HTTPClient(wire.HTTPClient("openai")).
+//	    FromEnv()
+//
+// Information Hiding:
+// - Secret redaction (auth headers, key query parameters) hidden
+// - Content hashing of prompts, completions and tool arguments hidden
+//   (tool schemas are kept, since they are what usually needs debugging)
+// - Size-based rotation hidden
+
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWireLogMaxBytes is the size at which the wire log rotates.
+	DefaultWireLogMaxBytes = 10 << 20
+	// wireLogBodyLimit caps how much of each body is logged.
+	wireLogBodyLimit = 1 << 20
+	redacted         = "[REDACTED]"
+)
+
+// WireLogOptions configures a wire log.
+type WireLogOptions struct {
+	Path string
+	// MaxBytes rotates the log to Path+".1" when it would grow past this
+	// size. Zero uses DefaultWireLogMaxBytes.
+	MaxBytes int64
+	// IncludeContent logs prompts, completions and tool arguments verbatim
+	// instead of as hashes. Secrets are redacted either way.
+	IncludeContent bool
+}
+
+// WireLog appends provider HTTP exchanges to a JSONL file.
+// Safe for concurrent use by several providers.
+type WireLog struct {
+	mu   sync.Mutex
+	opts WireLogOptions
+	file *os.File
+	size int64
+}
+
+// OpenWireLog opens (appending) or creates the log at opts.Path.
+func OpenWireLog(opts WireLogOptions) (*WireLog, error) {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultWireLogMaxBytes
+	}
+	l := &WireLog{opts: opts}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *WireLog) open() error {
+	file, err := os.OpenFile(l.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open wire log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat wire log: %w", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Close closes the log file.
+func (l *WireLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// HTTPClient returns a client that logs its traffic under provider's name.
+func (l *WireLog) HTTPClient(provider string) *http.Client {
+	return &http.Client{Transport: l.Transport(provider, http.DefaultTransport)}
+}
+
+// Transport wraps next so that every exchange is logged.
+func (l *WireLog) Transport(provider string, next http.RoundTripper) http.RoundTripper {
+	return &wireTransport{log: l, provider: provider, next: next}
+}
+
+// wireEntry is one line of the log.
+type wireEntry struct {
+	Time           string            `json:"time"`
+	Provider       string            `json:"provider"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Status         int               `json:"status,omitempty"`
+	DurationMS     int64             `json:"duration_ms"`
+	RequestHeaders map[string]string `json:"request_headers,omitempty"`
+	Request        any               `json:"request,omitempty"`
+	Response       any               `json:"response,omitempty"`
+	Error          string            `json:"error,omitempty"`
+}
+
+func (l *WireLog) write(entry wireEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxBytes {
+		l.rotate()
+	}
+	n, _ := l.file.Write(line) // Logging must never fail a request
+	l.size += int64(n)
+}
+
+// rotate moves the current log to Path.1, replacing any older backup.
+func (l *WireLog) rotate() {
+	l.file.Close()
+	_ = os.Rename(l.opts.Path, l.opts.Path+".1")
+	if err := l.open(); err != nil {
+		fmt.Fprintf(os.Stderr, "llm: wire log rotation failed: %v\n", err)
+	}
+}
+
+type wireTransport struct {
+	log      *WireLog
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *wireTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	entry := wireEntry{
+		Time:           start.UTC().Format(time.RFC3339Nano),
+		Provider:       t.provider,
+		Method:         req.Method,
+		URL:            redactURL(req.URL),
+		RequestHeaders: redactHeaders(req.Header),
+	}
+
+	if body := t.peekRequestBody(req); body != nil {
+		entry.Request = t.log.formatBody(body, req.Header.Get("Content-Type"))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		entry.DurationMS = time.Since(start).Milliseconds()
+		entry.Error = err.Error()
+		t.log.write(entry)
+		return nil, err
+	}
+
+	entry.Status = resp.StatusCode
+	resp.Body = &wireBody{
+		ReadCloser: resp.Body,
+		finish: func(body []byte) {
+			entry.DurationMS = time.Since(start).Milliseconds()
+			entry.Response = t.log.formatBody(body, resp.Header.Get("Content-Type"))
+			t.log.write(entry)
+		},
+	}
+	return resp, nil
+}
+
+// peekRequestBody returns the request body without consuming it.
+func (t *wireTransport) peekRequestBody(req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		defer body.Close()
+		data, _ := io.ReadAll(io.LimitReader(body, wireLogBodyLimit))
+		return data
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	if len(data) > wireLogBodyLimit {
+		return data[:wireLogBodyLimit]
+	}
+	return data
+}
+
+// wireBody captures a response body as the caller reads it and logs the
+// exchange at EOF or Close, whichever comes first.
+type wireBody struct {
+	io.ReadCloser
+	buf    bytes.Buffer
+	once   sync.Once
+	finish func([]byte)
+}
+
+func (b *wireBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := wireLogBodyLimit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	if err == io.EOF {
+		b.once.Do(func() { b.finish(b.buf.Bytes()) })
+	}
+	return n, err
+}
+
+func (b *wireBody) Close() error {
+	b.once.Do(func() { b.finish(b.buf.Bytes()) })
+	return b.ReadCloser.Close()
+}
+
+// formatBody renders a body for the log: JSON bodies as JSON, event
+// streams line by line, anything else as text. Content is hashed unless
+// IncludeContent is set.
+func (l *WireLog) formatBody(body []byte, contentType string) any {
+	if len(body) == 0 {
+		return nil
+	}
+	var parsed any
+	if json.Unmarshal(body, &parsed) == nil {
+		if l.opts.IncludeContent {
+			return parsed
+		}
+		return redactContent("", parsed)
+	}
+	if strings.HasPrefix(contentType, "text/event-stream") && !l.opts.IncludeContent {
+		lines := strings.Split(string(body), "\n")
+		for i, line := range lines {
+			data, ok := strings.CutPrefix(line, "data: ")
+			var event any
+			if !ok || json.Unmarshal([]byte(data), &event) != nil {
+				continue
+			}
+			if out, err := json.Marshal(redactContent("", event)); err == nil {
+				lines[i] = "data: " + string(out)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	if l.opts.IncludeContent {
+		return string(body)
+	}
+	return contentHash(string(body))
+}
+
+// Keys whose string values are conversation content.
+var wireTextKeys = map[string]bool{
+	"content": true, "text": true, "arguments": true, "partial_json": true,
+	"thinking": true, "system": true, "prompt": true,
+}
+
+// Keys whose values, of any shape, are tool inputs or outputs.
+var wirePayloadKeys = map[string]bool{"input": true, "args": true, "response": true}
+
+// Keys holding JSON schemas, which are logged as-is.
+var wireSchemaKeys = map[string]bool{
+	"parameters": true, "input_schema": true, "parametersJsonSchema": true,
+	"schema": true, "json_schema": true, "responseSchema": true,
+}
+
+// redactContent replaces conversation content under key with hashes.
+func redactContent(key string, v any) any {
+	if wireSchemaKeys[key] {
+		return v
+	}
+	if wirePayloadKeys[key] {
+		data, _ := json.Marshal(v)
+		return contentHash(string(data))
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		for k, x := range val {
+			val[k] = redactContent(k, x)
+		}
+	case []any:
+		for i, x := range val {
+			val[i] = redactContent(key, x)
+		}
+	case string:
+		if wireTextKeys[key] {
+			return contentHash(val)
+		}
+	}
+	return v
+}
+
+// contentHash identifies content without revealing it, so identical
+// prompts can still be matched across entries.
+func contentHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return fmt.Sprintf("[sha256:%s %d bytes]", hex.EncodeToString(sum[:8]), len(s))
+}
+
+// redactHeaders copies headers, hiding credentials.
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if isSecretName(name) {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// redactURL hides credentials passed as query parameters.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for name := range query {
+		if isSecretName(name) {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.String()
+}
+
+// isSecretName reports whether a header or parameter name carries a credential.
+func isSecretName(name string) bool {
+	lower := strings.ToLower(name)
+	if lower == "authorization" || lower == "proxy-authorization" || lower == "cookie" || lower == "key" {
+		return true
+	}
+	for _, marker := range []string{"api-key", "api_key", "apikey", "token", "secret"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
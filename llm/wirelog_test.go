@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readWireLog(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open wire log: %v", err)
+	}
+	defer file.Close()
+	var entries []map[string]any
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 4<<20)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("bad log line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestWireLogRedactsSecretsAndContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"the secret plan"}}]}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "wire.jsonl")
+	wire, err := OpenWireLog(WireLogOptions{Path: path})
+	if err != nil {
+		t.Fatalf("OpenWireLog failed: %v", err)
+	}
+	defer wire.Close()
+
+	body := `{"messages":[{"role":"user","content":"my private question"}],` +
+		`"tools":[{"type":"function","function":{"name":"read_file","parameters":{"type":"object","properties":{"content":{"type":"string"}}}}}]}`
+	req, _ := http.NewRequest("POST", server.URL+"/v1/chat?key=AIza-secret", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := wire.HTTPClient("openai").Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(got), "the secret plan") {
+		t.Fatalf("response body altered: %s", got)
+	}
+
+	raw, _ := os.ReadFile(path)
+	for _, secret := range []string{"sk-secret", "AIza-secret", "my private question", "the secret plan"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("wire log leaks %q: %s", secret, raw)
+		}
+	}
+	entries := readWireLog(t, path)
+	if len(entries) != 1 || entries[0]["status"] != float64(200) || entries[0]["provider"] != "openai" {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	if !strings.Contains(string(raw), `"properties":{"content":{"type":"string"}}`) {
+		t.Errorf("tool schema should be logged verbatim: %s", raw)
+	}
+	if !strings.Contains(string(raw), "[sha256:") {
+		t.Errorf("content should be replaced by hashes: %s", raw)
+	}
+}
+
+func TestWireLogIncludeContentAndRotation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"delta\":{\"text\":\"streamed words\"}}\n\n")
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "wire.jsonl")
+	wire, err := OpenWireLog(WireLogOptions{Path: path, MaxBytes: 600, IncludeContent: true})
+	if err != nil {
+		t.Fatalf("OpenWireLog failed: %v", err)
+	}
+	defer wire.Close()
+
+	client := wire.HTTPClient("anthropic")
+	for i := 0; i < 3; i++ {
+		resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"content":"hello"}`))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	entries := readWireLog(t, path)
+	if len(entries) == 0 || len(entries) == 3 {
+		t.Fatalf("expected rotation to leave 1-2 entries, got %d", len(entries))
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("rotated log missing: %v", err)
+	}
+	last := entries[len(entries)-1]
+	if !strings.Contains(last["response"].(string), "streamed words") {
+		t.Errorf("IncludeContent should log the stream verbatim: %v", last["response"])
+	}
+	if req := last["request"].(map[string]any); req["content"] != "hello" {
+		t.Errorf("IncludeContent should log the request verbatim: %v", req)
+	}
+}
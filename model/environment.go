@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Environment describes where and with what a run was executed.
+// Attached to run metadata so results can be interpreted and reproduced later.
+type Environment struct {
+	AriadneVersion string            `json:"ariadne_version"`
+	GoVersion      string            `json:"go_version"`
+	OS             string            `json:"os"`
+	Arch           string            `json:"arch"`
+	Provider       string            `json:"provider,omitempty"`
+	Model          string            `json:"model,omitempty"`
+	GitCommit      string            `json:"git_commit,omitempty"` // Workspace HEAD, empty outside a git repo
+	GitDirty       bool              `json:"git_dirty,omitempty"`  // Workspace has uncommitted changes
+	Tools          map[string]string `json:"tools,omitempty"`      // External tool name -> version line
+	CapturedAt     time.Time         `json:"captured_at"`
+}
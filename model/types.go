@@ -55,4 +55,5 @@ type StoredContent struct {
 	Lines     int    // Total number of lines
 	Bytes     int    // Total size in bytes
 	Preview   string // First few lines for quick viewing
+	Outline   bool   // Preview is a structural code outline (package, imports, exports)
 }
@@ -0,0 +1,13 @@
+package model
+
+// DSAUsage counts what a run did with its ResultStore: content stored and
+// indexed for search, and reads answered from the store instead of the
+// conversation. Attached to run metadata to show what bounded context saved.
+type DSAUsage struct {
+	FilesStored   int   `json:"files_stored"`
+	BytesIndexed  int64 `json:"bytes_indexed"` // New content only; duplicates share an index entry
+	Searches      int   `json:"searches"`
+	CacheHits     int   `json:"cache_hits"` // Repeated reads answered without the store
+	GetLinesCalls int   `json:"get_lines_calls"`
+	BytesSaved    int64 `json:"bytes_saved"` // Tool output kept out of the conversation
+}
@@ -0,0 +1,280 @@
+// Ensemble - Voting Across Providers.
+//
+// Runs the same task on several providers in parallel and reconciles
+// the answers: majority vote for structured (JSON) outputs, an LLM judge
+// for free text. Disagreement between members is reported in metadata.
+//
+// Information Hiding:
+// - Parallel member execution hidden
+// - Answer normalization and voting hidden
+// - Judge prompting hidden
+
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	jsonutil "github.com/richinex/ariadne/internal/json"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+)
+
+// Reconciliation methods reported in EnsembleStats.Method.
+const (
+	EnsembleMajority = "majority"
+	EnsembleJudge    = "judge"
+	EnsembleSingle   = "single"
+)
+
+// EnsembleAnswer is one member's answer.
+type EnsembleAnswer struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Result   string `json:"result,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// EnsembleStats describes how an ensemble reached its answer.
+type EnsembleStats struct {
+	Members   int    `json:"members"`
+	Succeeded int    `json:"succeeded"`
+	Method    string `json:"method"`
+	Winner    int    `json:"winner"` // Index into Answers
+	Votes     int    `json:"votes"`  // Members agreeing with the winner
+	// Disagreement is 1 - votes/succeeded: 0 when all members agree,
+	// approaching 1 when every member answered differently.
+	Disagreement float64          `json:"disagreement"`
+	Answers      []EnsembleAnswer `json:"answers"`
+}
+
+// Ensemble runs one agent configuration on multiple providers.
+type Ensemble struct {
+	members       []*agent.Agent
+	providers     []llm.Provider
+	judge         *llm.Client
+	maxIterations int
+}
+
+// NewEnsemble creates an ensemble running config on each provider.
+func NewEnsemble(config agent.Config, providers []llm.Provider) *Ensemble {
+	members := make([]*agent.Agent, len(providers))
+	for i, p := range providers {
+		members[i] = agent.New(config, p)
+	}
+	return &Ensemble{
+		members:       members,
+		providers:     providers,
+		maxIterations: DefaultSupervisorConfig().MaxIterations,
+	}
+}
+
+// WithJudge sets the provider used to pick among free-text answers.
+// Without a judge, free text is reconciled by exact-match majority.
+func (e *Ensemble) WithJudge(provider llm.Provider) *Ensemble {
+	e.judge = llm.NewClient(provider)
+	return e
+}
+
+// WithMaxIterations sets the ReAct iteration limit for each member.
+func (e *Ensemble) WithMaxIterations(n int) *Ensemble {
+	e.maxIterations = n
+	return e
+}
+
+// Execute runs the task on all members in parallel and reconciles the answers.
+func (e *Ensemble) Execute(ctx context.Context, task string) Response {
+	startTime := time.Now()
+	tokenStats := &TokenStats{}
+
+	responses := make([]agent.Response, len(e.members))
+	var wg sync.WaitGroup
+	for i, member := range e.members {
+		wg.Add(1)
+		go func(i int, member *agent.Agent) {
+			defer wg.Done()
+			responses[i] = member.Execute(ctx, task, e.maxIterations)
+		}(i, member)
+	}
+	wg.Wait()
+
+	stats := &EnsembleStats{
+		Members: len(e.members),
+		Winner:  -1,
+		Answers: make([]EnsembleAnswer, len(e.members)),
+	}
+	var steps []Step
+	var succeeded []int
+	var memberErrs []error
+
+	for i, resp := range responses {
+		tokenStats.AddUsage(resp.Metadata.TokenUsage)
+		tokenStats.LLMCalls += resp.Metadata.LLMCalls
+		tokenStats.addDecisionStats(resp.Metadata)
+
+		answer := EnsembleAnswer{
+			Provider: e.providers[i].Name(),
+			Model:    e.providers[i].Model(),
+		}
+		if resp.IsSuccess() {
+			answer.Result = resp.Result
+			succeeded = append(succeeded, i)
+		} else {
+			answer.Error = resp.ResultText()
+			memberErrs = append(memberErrs, resp.AsError())
+		}
+		stats.Answers[i] = answer
+
+		action := fmt.Sprintf("%s:%s", answer.Provider, answer.Model)
+		observation := resp.ResultText()
+		steps = append(steps, model.Step{
+			Iteration:   i,
+			Thought:     "ensemble member",
+			Action:      &action,
+			Observation: &observation,
+		})
+	}
+	stats.Succeeded = len(succeeded)
+
+	metadata := buildMetadata(tokenStats, nil)
+	metadata.Ensemble = stats
+
+	if len(succeeded) == 0 {
+		metadata.ExecutionTimeMs = uint64(time.Since(startTime).Milliseconds())
+		resp := NewFailureResponse(
+			"all ensemble members failed",
+			steps,
+			metadata,
+			&CompletionStatus{Type: StatusFailed, Error: "all ensemble members failed", Recoverable: true},
+		)
+		resp.Err = fmt.Errorf("all ensemble members failed: %w", errors.Join(memberErrs...))
+		return resp
+	}
+
+	e.reconcile(ctx, task, succeeded, stats, tokenStats)
+	metadata.ExecutionTimeMs = uint64(time.Since(startTime).Milliseconds())
+
+	return NewSuccessResponse(
+		stats.Answers[stats.Winner].Result,
+		steps,
+		metadata,
+		&CompletionStatus{Type: StatusComplete},
+	)
+}
+
+// reconcile picks the winning answer among successful members.
+func (e *Ensemble) reconcile(ctx context.Context, task string, succeeded []int, stats *EnsembleStats, tokenStats *TokenStats) {
+	// Group answers by normalized form
+	groups := make(map[string][]int)
+	var order []string
+	allStructured := true
+	for _, i := range succeeded {
+		key, structured := normalizeAnswer(stats.Answers[i].Result)
+		if !structured {
+			allStructured = false
+		}
+		if _, exists := groups[key]; !exists {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	// Largest group wins; ties go to the earliest answer
+	best := order[0]
+	for _, key := range order[1:] {
+		if len(groups[key]) > len(groups[best]) {
+			best = key
+		}
+	}
+	stats.Winner = groups[best][0]
+	stats.Votes = len(groups[best])
+	stats.Disagreement = 1 - float64(stats.Votes)/float64(len(succeeded))
+
+	switch {
+	case len(succeeded) == 1:
+		stats.Method = EnsembleSingle
+	case allStructured || e.judge == nil || len(groups) == 1:
+		stats.Method = EnsembleMajority
+	default:
+		stats.Method = EnsembleMajority
+		if winner, ok := e.askJudge(ctx, task, succeeded, stats.Answers, tokenStats); ok {
+			stats.Method = EnsembleJudge
+			stats.Winner = winner
+			key, _ := normalizeAnswer(stats.Answers[winner].Result)
+			stats.Votes = len(groups[key])
+		}
+	}
+}
+
+// judgeDecision is the judge LLM's verdict.
+type judgeDecision struct {
+	Choice int    `json:"choice"`
+	Reason string `json:"reason"`
+}
+
+// askJudge asks the judge to pick the best free-text answer.
+// Returns the index into answers and false if the judge gave no usable choice.
+func (e *Ensemble) askJudge(ctx context.Context, task string, succeeded []int, answers []EnsembleAnswer, tokenStats *TokenStats) (int, bool) {
+	var sb strings.Builder
+	for n, i := range succeeded {
+		fmt.Fprintf(&sb, "Answer %d:\n%s\n\n", n+1, answers[i].Result)
+	}
+
+	messages := []llm.ChatMessage{
+		{
+			Role: "system",
+			Content: `You are a judge comparing answers from several assistants to the same task.
+Pick the single most correct and complete answer.
+
+Respond in this EXACT JSON format:
+{"choice": <answer number>, "reason": "short justification"}`,
+		},
+		{
+			Role:    "user",
+			Content: fmt.Sprintf("Task: %s\n\n%s", task, sb.String()),
+		},
+	}
+
+	response, usage, err := e.judge.ChatWithUsage(ctx, messages)
+	if err != nil {
+		return 0, false
+	}
+	tokenStats.LLMCalls++
+	tokenStats.AddUsage(usage)
+
+	extracted, err := jsonutil.ExtractJSON(response)
+	if err != nil {
+		return 0, false
+	}
+	var decision judgeDecision
+	if err := json.Unmarshal([]byte(extracted), &decision); err != nil {
+		return 0, false
+	}
+	if decision.Choice < 1 || decision.Choice > len(succeeded) {
+		return 0, false
+	}
+	return succeeded[decision.Choice-1], true
+}
+
+// normalizeAnswer returns a comparison key for an answer and whether it is
+// structured. JSON values are re-marshaled so key order and whitespace do not
+// matter; free text is trimmed and lower-cased.
+func normalizeAnswer(answer string) (string, bool) {
+	trimmed := strings.TrimSpace(answer)
+	var v interface{}
+	if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+		if _, isString := v.(string); !isString {
+			canonical, err := json.Marshal(v)
+			if err == nil {
+				return string(canonical), true
+			}
+		}
+	}
+	return strings.ToLower(strings.Join(strings.Fields(trimmed), " ")), false
+}
@@ -0,0 +1,96 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+)
+
+func finalAnswer(answer string) string {
+	return `{"thought": "done", "is_final": true, "final_answer": ` + answer + `}`
+}
+
+func TestEnsembleMajorityStructured(t *testing.T) {
+	providers := []llm.Provider{
+		&scriptedProvider{responses: []string{finalAnswer(`{"a": 1, "b": 2}`)}},
+		&scriptedProvider{responses: []string{finalAnswer(`{"b": 2, "a": 1}`)}},
+		&scriptedProvider{responses: []string{finalAnswer(`{"a": 3}`)}},
+	}
+	ensemble := NewEnsemble(agent.NewBuilder("voter").Build(), providers).WithMaxIterations(2)
+
+	resp := ensemble.Execute(context.Background(), "task")
+	if resp.Type != ResponseSuccess {
+		t.Fatalf("expected success, got %v: %s", resp.Type, resp.Error)
+	}
+
+	stats := resp.Metadata.Ensemble
+	if stats == nil {
+		t.Fatal("expected ensemble stats in metadata")
+	}
+	if stats.Method != EnsembleMajority || stats.Votes != 2 || stats.Winner != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.Disagreement < 0.33 || stats.Disagreement > 0.34 {
+		t.Errorf("expected disagreement ~0.33, got %f", stats.Disagreement)
+	}
+}
+
+func TestEnsembleJudgeFreeText(t *testing.T) {
+	providers := []llm.Provider{
+		&scriptedProvider{responses: []string{finalAnswer(`"Paris is the capital"`)}},
+		&scriptedProvider{responses: []string{finalAnswer(`"It is Lyon"`)}},
+	}
+	judge := &scriptedProvider{responses: []string{`{"choice": 1, "reason": "correct"}`}}
+	ensemble := NewEnsemble(agent.NewBuilder("voter").Build(), providers).
+		WithJudge(judge).
+		WithMaxIterations(2)
+
+	resp := ensemble.Execute(context.Background(), "capital of France?")
+	if resp.Result != "Paris is the capital" {
+		t.Errorf("expected judge's choice, got %q", resp.Result)
+	}
+	stats := resp.Metadata.Ensemble
+	if stats.Method != EnsembleJudge || stats.Disagreement != 0.5 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestEnsembleFailures(t *testing.T) {
+	providers := []llm.Provider{
+		&scriptedProvider{err: errors.New("boom")},
+		&scriptedProvider{responses: []string{finalAnswer(`"only answer"`)}},
+	}
+	ensemble := NewEnsemble(agent.NewBuilder("voter").Build(), providers).WithMaxIterations(2)
+
+	resp := ensemble.Execute(context.Background(), "task")
+	if resp.Result != "only answer" || resp.Metadata.Ensemble.Method != EnsembleSingle {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.Metadata.Ensemble.Answers[0].Error == "" {
+		t.Error("expected failed member to record its error")
+	}
+
+	allFail := NewEnsemble(agent.NewBuilder("voter").Build(), providers[:1])
+	resp = allFail.Execute(context.Background(), "task")
+	if resp.Type != ResponseFailure {
+		t.Errorf("expected failure when all members fail, got %v", resp.Type)
+	}
+	if !errors.Is(resp.AsError(), agent.ErrLLM) {
+		t.Errorf("expected error to wrap agent.ErrLLM, got %v", resp.Err)
+	}
+}
+
+func TestEnsembleCancelled(t *testing.T) {
+	providers := []llm.Provider{&scriptedProvider{responses: []string{finalAnswer(`"late"`)}}}
+	ensemble := NewEnsemble(agent.NewBuilder("voter").Build(), providers)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp := ensemble.Execute(ctx, "task")
+	if !errors.Is(resp.Err, agent.ErrCancelled) || !errors.Is(resp.Err, context.Canceled) {
+		t.Errorf("expected cancellation error, got %v", resp.Err)
+	}
+}
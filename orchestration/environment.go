@@ -0,0 +1,134 @@
+// Environment Snapshot.
+//
+// Captures provider/model, external tool versions, OS, workspace git
+// commit, and ariadne version at the start of a run, so a result can be
+// interpreted and reproduced later.
+//
+// Information Hiding:
+// - Build info lookup hidden
+// - External command probing hidden
+
+package orchestration
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+)
+
+// ariadneModule is the module path used to find ariadne's version in build info.
+const ariadneModule = "github.com/richinex/ariadne"
+
+// probeTimeout bounds each external command run while capturing the environment.
+const probeTimeout = 2 * time.Second
+
+// toolProbes are the external tools whose versions are recorded, with the
+// arguments that print a version. Tools not on PATH are skipped.
+var toolProbes = map[string][]string{
+	"rg":      {"--version"},
+	"kubectl": {"version", "--client"},
+	"git":     {"--version"},
+}
+
+// CaptureEnvironment snapshots the current environment for run metadata.
+// provider may be nil. Probes run in parallel and failures are omitted.
+func CaptureEnvironment(ctx context.Context, provider llm.Provider) *model.Environment {
+	env := &model.Environment{
+		AriadneVersion: ariadneVersion(),
+		GoVersion:      runtime.Version(),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		Tools:          make(map[string]string),
+		CapturedAt:     time.Now(),
+	}
+	if provider != nil {
+		env.Provider = provider.Name()
+		env.Model = provider.Model()
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, args := range toolProbes {
+		wg.Add(1)
+		go func(name string, args []string) {
+			defer wg.Done()
+			if version, ok := probe(ctx, name, args...); ok {
+				mu.Lock()
+				env.Tools[name] = version
+				mu.Unlock()
+			}
+		}(name, args)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		commit, ok := probe(ctx, "git", "rev-parse", "HEAD")
+		if !ok {
+			return
+		}
+		status, _ := probe(ctx, "git", "status", "--porcelain")
+		mu.Lock()
+		env.GitCommit = commit
+		env.GitDirty = status != ""
+		mu.Unlock()
+	}()
+
+	wg.Wait()
+	return env
+}
+
+// probe runs an external command and returns the first line of its output.
+func probe(ctx context.Context, name string, args ...string) (string, bool) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", false
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", false
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), true
+}
+
+// ariadneVersion returns the ariadne module version from build info: the
+// main module when running the CLI, or the dependency when used as a library.
+func ariadneVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	version := ""
+	if info.Main.Path == ariadneModule {
+		version = info.Main.Version
+	} else {
+		for _, dep := range info.Deps {
+			if dep.Path == ariadneModule {
+				version = dep.Version
+				break
+			}
+		}
+	}
+
+	// Local builds report "(devel)"; fall back to the VCS revision
+	if version == "" || version == "(devel)" {
+		version = "devel"
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+				version = "devel+" + setting.Value[:12]
+			}
+		}
+	}
+	return version
+}
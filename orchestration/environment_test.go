@@ -0,0 +1,34 @@
+package orchestration
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestCaptureEnvironment(t *testing.T) {
+	env := CaptureEnvironment(context.Background(), &scriptedProvider{})
+
+	if env.Provider != "scripted" || env.Model != "scripted" {
+		t.Errorf("expected provider and model from provider, got %q/%q", env.Provider, env.Model)
+	}
+	if env.OS != runtime.GOOS || env.Arch != runtime.GOARCH {
+		t.Errorf("unexpected platform %s/%s", env.OS, env.Arch)
+	}
+	if env.GoVersion != runtime.Version() {
+		t.Errorf("expected go version %s, got %s", runtime.Version(), env.GoVersion)
+	}
+	if env.AriadneVersion == "" {
+		t.Error("expected ariadne version to be set")
+	}
+	if env.CapturedAt.IsZero() {
+		t.Error("expected capture time to be set")
+	}
+}
+
+func TestCaptureEnvironmentNilProvider(t *testing.T) {
+	env := CaptureEnvironment(context.Background(), nil)
+	if env.Provider != "" || env.Model != "" {
+		t.Errorf("expected empty provider fields, got %q/%q", env.Provider, env.Model)
+	}
+}
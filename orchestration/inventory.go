@@ -0,0 +1,73 @@
+// Stored-content inventory for the supervisor.
+//
+// Agents share one result store session: files pre-stored from the task,
+// files agents read, and large agent results. The supervisor has no store
+// tools of its own, so without a list it can't point agents at what is
+// already there and they read the same files again. The inventory lists
+// each key with its line count and tags at the end of the supervisor's
+// system prompt, and Orchestrate refreshes it whenever the store changes.
+//
+// Information Hiding:
+// - Entry format and truncation hidden
+// - Change detection (store generation) hidden
+
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// maxInventoryEntries caps the keys listed; the rest are counted.
+const maxInventoryEntries = 40
+
+// resultSessionID returns the session results are stored under.
+func (s *Supervisor) resultSessionID() string {
+	if s.resultSession != "" {
+		return s.resultSession
+	}
+	return s.sessionID
+}
+
+// storeGeneration returns the result store's change counter, or 0 without
+// a store.
+func (s *Supervisor) storeGeneration() uint64 {
+	if s.resultStore == nil {
+		return 0
+	}
+	return s.resultStore.Generation()
+}
+
+// storedInventory renders the result session's keys for the system prompt,
+// in the order they were stored, or "" if nothing is stored.
+func (s *Supervisor) storedInventory(ctx context.Context) string {
+	if s.resultStore == nil {
+		return ""
+	}
+	metas, err := s.resultStore.List(ctx, s.resultSessionID(), storage.QueryOptions{})
+	if err != nil || len(metas) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n\nSTORED CONTENT (shared by all agents, %d in total):\n", len(metas))
+	sb.WriteString("- Tell agents which keys to read with get_lines or search_stored instead of reading files again\n")
+	for i, meta := range metas {
+		if i == maxInventoryEntries {
+			fmt.Fprintf(&sb, "- ... and %d more (agents can see them with list_stored)\n", len(metas)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "- %s: %d lines", meta.Key.Key, meta.LineCount)
+		if tags := meta.Tags.String(); tags != "" {
+			fmt.Fprintf(&sb, " (%s)", tags)
+		}
+		if meta.Pinned {
+			sb.WriteString(" [pinned]")
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,75 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestSupervisorStoredInventory(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	defer store.Close()
+	if _, err := store.Store(ctx, storage.ResultKey{SessionID: "run", Key: "/repo/main.go"}, "package main\n\nfunc main() {}", storage.DefaultStoreOptions()); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	worker := newScriptedAgent("worker", `{"thought": "done", "is_final": true, "final_answer": "`+strings.Repeat("finding ", 20)+`"}`)
+	provider := &promptRecorder{scriptedProvider: scriptedProvider{responses: []string{
+		`{"thought": "delegate", "agent_to_invoke": "worker", "agent_task": "review /repo/main.go", "sub_goal_id": "review", "is_final": false}`,
+		`{"thought": "done", "is_final": true, "final_answer": "reviewed"}`,
+	}}}
+	config := DefaultSupervisorConfig()
+	config.LargeResultThreshold = 50
+	supervisor := NewSupervisor([]*agent.Agent{worker}, llm.NewClient(provider), config).
+		WithResultStore(store).
+		WithResultSession("run")
+
+	resp := supervisor.Orchestrate(ctx, "review main.go", 5)
+	if resp.Type != ResponseSuccess {
+		t.Fatalf("expected success, got %+v", resp)
+	}
+	var systemPrompts []string
+	for _, prompt := range provider.prompts {
+		if strings.HasPrefix(prompt, "You are a supervisor") {
+			systemPrompts = append(systemPrompts, prompt)
+		}
+	}
+	if len(systemPrompts) != 2 {
+		t.Fatalf("expected 2 supervisor calls, got %d", len(systemPrompts))
+	}
+	first, second := systemPrompts[0], systemPrompts[1]
+	if !strings.Contains(first, "STORED CONTENT (shared by all agents, 1 in total") || !strings.Contains(first, "- /repo/main.go: 3 lines (code, go, small)") {
+		t.Errorf("first prompt should list the pre-stored file:\n%s", first)
+	}
+	if !strings.Contains(second, "STORED CONTENT (shared by all agents, 2 in total") || !strings.Contains(second, "- worker/review:") {
+		t.Errorf("inventory should be refreshed with the stored agent result:\n%s", second)
+	}
+}
+
+func TestStoredInventoryTruncates(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	defer store.Close()
+	for i := range maxInventoryEntries + 5 {
+		key := storage.ResultKey{SessionID: "run", Key: "notes/" + strings.Repeat("x", i+1)}
+		store.Store(ctx, key, strings.Repeat("y", i+1), storage.DefaultStoreOptions())
+	}
+	supervisor := NewSupervisor(nil, nil, DefaultSupervisorConfig()).WithResultStore(store).WithResultSession("run")
+
+	inventory := supervisor.storedInventory(ctx)
+	if strings.Count(inventory, "\n- notes/") != maxInventoryEntries {
+		t.Errorf("expected %d listed keys:\n%s", maxInventoryEntries, inventory)
+	}
+	if !strings.Contains(inventory, "and 5 more") {
+		t.Errorf("expected the rest to be counted:\n%s", inventory)
+	}
+
+	if got := NewSupervisor(nil, nil, DefaultSupervisorConfig()).storedInventory(ctx); got != "" {
+		t.Errorf("no store should mean no inventory, got %q", got)
+	}
+}
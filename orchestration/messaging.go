@@ -0,0 +1,329 @@
+// Agent-to-Agent Messaging.
+//
+// Direct question/answer channel between agents during orchestration,
+// so chatty exchanges do not have to round-trip through the supervisor.
+// An agent is not safe for concurrent use, so one that is already running
+// (a sub-goal, or another question) can't be asked; the supervisor waits
+// for an agent's questions to finish before giving it a sub-goal.
+//
+// Information Hiding:
+// - Hop tracking and cycle detection hidden
+// - Busy-agent tracking hidden
+// - Tool registration lifecycle hidden
+// - Message log storage hidden
+
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/tools"
+)
+
+// AskAgentToolName is the name of the tool agents use to message each other.
+const AskAgentToolName = "ask_agent"
+
+// DefaultMaxHops is the default limit on nested agent-to-agent questions.
+const DefaultMaxHops = 2
+
+// errNoAnswer marks questions the target agent ran but could not answer.
+// The asking agent sees these as observations rather than tool failures,
+// so the executor does not re-run the target agent.
+var errNoAnswer = errors.New("no answer")
+
+// AgentMessage is a logged question/answer exchange between two agents.
+type AgentMessage struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Question   string    `json:"question"`
+	Answer     string    `json:"answer,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Hop        int       `json:"hop"`
+	DurationMs uint64    `json:"duration_ms"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// MessageBus routes direct questions between agents.
+// Each question runs the target agent synchronously; nesting is bounded by maxHops.
+type MessageBus struct {
+	mu            sync.RWMutex
+	agents        map[string]*agent.Agent
+	busy          map[string]chan struct{} // Running agents; closed when they are free
+	maxHops       int
+	maxIterations int
+	log           []AgentMessage
+}
+
+// NewMessageBus creates a message bus allowing at most maxHops nested questions.
+// Non-positive values fall back to DefaultMaxHops.
+func NewMessageBus(maxHops int) *MessageBus {
+	if maxHops <= 0 {
+		maxHops = DefaultMaxHops
+	}
+	return &MessageBus{
+		agents:        make(map[string]*agent.Agent),
+		busy:          make(map[string]chan struct{}),
+		maxHops:       maxHops,
+		maxIterations: DefaultSupervisorConfig().MaxIterations,
+	}
+}
+
+// MaxHops returns the nesting limit for agent-to-agent questions.
+func (b *MessageBus) MaxHops() int {
+	return b.maxHops
+}
+
+// Messages returns a copy of all logged exchanges in order.
+func (b *MessageBus) Messages() []AgentMessage {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	result := make([]AgentMessage, len(b.log))
+	copy(result, b.log)
+	return result
+}
+
+// askChainKey is the context key holding the chain of agents awaiting answers.
+type askChainKey struct{}
+
+func askChain(ctx context.Context) []string {
+	chain, _ := ctx.Value(askChainKey{}).([]string)
+	return chain
+}
+
+// Ask sends a question from one agent to another and returns the answer.
+// Fails if the target is unknown, already waiting in the chain, or the hop limit is reached.
+func (b *MessageBus) Ask(ctx context.Context, from, to, question string) (string, error) {
+	chain := askChain(ctx)
+	hop := len(chain) + 1
+	msg := AgentMessage{
+		From:      from,
+		To:        to,
+		Question:  question,
+		Hop:       hop,
+		Timestamp: time.Now(),
+	}
+
+	answer, err := b.deliver(ctx, chain, msg)
+	msg.DurationMs = uint64(time.Since(msg.Timestamp).Milliseconds())
+	if err != nil {
+		msg.Error = err.Error()
+	} else {
+		msg.Answer = answer
+	}
+
+	b.mu.Lock()
+	b.log = append(b.log, msg)
+	b.mu.Unlock()
+
+	return answer, err
+}
+
+// deliver runs the target agent for a single message.
+func (b *MessageBus) deliver(ctx context.Context, chain []string, msg AgentMessage) (string, error) {
+	if msg.From == msg.To {
+		return "", fmt.Errorf("asking yourself is not allowed")
+	}
+	if msg.Hop > b.maxHops {
+		return "", fmt.Errorf("more than %d nested questions not allowed: answer with what you have", b.maxHops)
+	}
+	for _, name := range chain {
+		if name == msg.To {
+			return "", fmt.Errorf("asking agent '%s' is not allowed: it is already waiting on this conversation", msg.To)
+		}
+	}
+
+	b.mu.RLock()
+	target, exists := b.agents[msg.To]
+	maxIterations := b.maxIterations
+	b.mu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("agent '%s' not found", msg.To)
+	}
+
+	release, ok := b.tryClaim(msg.To)
+	if !ok {
+		return "", fmt.Errorf("asking agent '%s' is not allowed: it is busy with another task, answer with what you have", msg.To)
+	}
+	defer release()
+
+	nextChain := make([]string, 0, len(chain)+1)
+	nextChain = append(nextChain, chain...)
+	nextChain = append(nextChain, msg.From)
+	askCtx := context.WithValue(ctx, askChainKey{}, nextChain)
+
+	task := fmt.Sprintf("Question from agent '%s': %s", msg.From, msg.Question)
+	response := target.Execute(askCtx, task, maxIterations)
+
+	switch response.Type {
+	case agent.ResponseSuccess:
+		return response.Result, nil
+	case agent.ResponseTimeout:
+		if response.PartialResult != "" {
+			return response.PartialResult, nil
+		}
+		return "", fmt.Errorf("%w: agent '%s' timed out", errNoAnswer, msg.To)
+	default:
+		return "", fmt.Errorf("%w: agent '%s' failed: %w", errNoAnswer, msg.To, response.AsError())
+	}
+}
+
+// tryClaim marks an agent as running, or returns false if it already is.
+// Call release when it is done.
+func (b *MessageBus) tryClaim(name string) (release func(), ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, running := b.busy[name]; running {
+		return nil, false
+	}
+	return b.markBusy(name), true
+}
+
+// claim waits until an agent is free, then marks it as running. Call
+// release when it is done.
+func (b *MessageBus) claim(ctx context.Context, name string) (release func(), err error) {
+	for {
+		b.mu.Lock()
+		free, running := b.busy[name]
+		if !running {
+			release := b.markBusy(name)
+			b.mu.Unlock()
+			return release, nil
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-free:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// markBusy records name as running and returns its release function.
+// Caller holds b.mu.
+func (b *MessageBus) markBusy(name string) func() {
+	free := make(chan struct{})
+	b.busy[name] = free
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.busy, name)
+			b.mu.Unlock()
+			close(free)
+		})
+	}
+}
+
+// attach registers agents on the bus and gives each one an ask_agent tool.
+// The returned function removes the tools again; call it when orchestration ends.
+func (b *MessageBus) attach(agents map[string]*agent.Agent, maxIterations int) func() {
+	b.mu.Lock()
+	for name, a := range agents {
+		b.agents[name] = a
+	}
+	if maxIterations > 0 {
+		b.maxIterations = maxIterations
+	}
+	b.mu.Unlock()
+
+	var attached []*agent.Agent
+	for _, a := range agents {
+		if err := a.AddTool(newAskAgentTool(b, a.Name())); err == nil {
+			attached = append(attached, a)
+		}
+	}
+
+	return func() {
+		for _, a := range attached {
+			a.RemoveTool(AskAgentToolName)
+		}
+		b.mu.Lock()
+		for name := range agents {
+			delete(b.agents, name)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// peers returns the names of agents other than self, sorted.
+func (b *MessageBus) peers(self string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	names := make([]string, 0, len(b.agents))
+	for name := range b.agents {
+		if name != self {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// askAgentTool lets an agent put a question directly to a peer agent.
+type askAgentTool struct {
+	tools.BaseTool
+	bus  *MessageBus
+	from string
+}
+
+func newAskAgentTool(bus *MessageBus, from string) *askAgentTool {
+	return &askAgentTool{bus: bus, from: from}
+}
+
+func (t *askAgentTool) Metadata() tools.ToolMetadata {
+	return tools.ToolMetadata{
+		Name: AskAgentToolName,
+		Description: fmt.Sprintf(
+			"Ask another agent a direct question and wait for its answer. Available agents: %s. Keep questions short and specific.",
+			strings.Join(t.bus.peers(t.from), ", "),
+		),
+		Parameters: []tools.ToolParameter{
+			{Name: "agent", ParamType: "string", Description: "Name of the agent to ask", Required: true},
+			{Name: "question", ParamType: "string", Description: "The question to ask", Required: true},
+		},
+	}
+}
+
+type askAgentArgs struct {
+	Agent    string `json:"agent"`
+	Question string `json:"question"`
+}
+
+func (t *askAgentTool) Validate(args json.RawMessage) error {
+	var a askAgentArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Agent == "" {
+		return fmt.Errorf("agent is required")
+	}
+	if strings.TrimSpace(a.Question) == "" {
+		return fmt.Errorf("question is required")
+	}
+	return nil
+}
+
+func (t *askAgentTool) Execute(ctx context.Context, args json.RawMessage) (tools.ToolResult, error) {
+	var a askAgentArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return tools.FailureResultf("invalid arguments: %v", err), nil
+	}
+
+	answer, err := t.bus.Ask(ctx, t.from, a.Agent, a.Question)
+	if errors.Is(err, errNoAnswer) {
+		return tools.SuccessResult(err.Error()), nil
+	}
+	if err != nil {
+		return tools.FailureResult(err), nil
+	}
+	return tools.SuccessResult(fmt.Sprintf("Answer from '%s': %s", a.Agent, answer)), nil
+}
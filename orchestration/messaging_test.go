@@ -0,0 +1,208 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+)
+
+// scriptedProvider replays canned responses in order, repeating the last one.
+// If err is set, every call fails with it.
+type scriptedProvider struct {
+	mu        sync.Mutex
+	responses []string
+	calls     int
+	err       error
+}
+
+func (p *scriptedProvider) Name() string  { return "scripted" }
+func (p *scriptedProvider) Model() string { return "scripted" }
+
+func (p *scriptedProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return llm.LLMResponse{}, p.err
+	}
+	idx := p.calls
+	if idx >= len(p.responses) {
+		idx = len(p.responses) - 1
+	}
+	p.calls++
+	return llm.LLMResponse{Content: p.responses[idx]}, nil
+}
+
+func (p *scriptedProvider) ChatWithFormat(ctx context.Context, messages []llm.ChatMessage, format *llm.ResponseFormat) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *scriptedProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *scriptedProvider) StreamChat(ctx context.Context, messages []llm.ChatMessage, chunks chan<- string) (*llm.TokenUsage, error) {
+	resp, err := p.Chat(ctx, messages)
+	if err == nil {
+		chunks <- resp.Content
+	}
+	return resp.Usage, err
+}
+
+func newScriptedAgent(name string, responses ...string) *agent.Agent {
+	config := agent.NewBuilder(name).Build()
+	return agent.New(config, &scriptedProvider{responses: responses})
+}
+
+func TestMessageBusAsk(t *testing.T) {
+	bus := NewMessageBus(2)
+	expert := newScriptedAgent("expert", `{"thought": "easy", "is_final": true, "final_answer": "42"}`)
+	asker := newScriptedAgent("asker", `{"thought": "done", "is_final": true, "final_answer": "ok"}`)
+
+	detach := bus.attach(map[string]*agent.Agent{"expert": expert, "asker": asker}, 3)
+	defer detach()
+
+	answer, err := bus.Ask(context.Background(), "asker", "expert", "what is the answer?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer != "42" {
+		t.Errorf("expected answer '42', got %q", answer)
+	}
+
+	msgs := bus.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 logged message, got %d", len(msgs))
+	}
+	if msgs[0].From != "asker" || msgs[0].To != "expert" || msgs[0].Hop != 1 {
+		t.Errorf("unexpected log entry: %+v", msgs[0])
+	}
+}
+
+func TestMessageBusRejects(t *testing.T) {
+	bus := NewMessageBus(1)
+	a := newScriptedAgent("a", `{"thought": "x", "is_final": true, "final_answer": "x"}`)
+	b := newScriptedAgent("b", `{"thought": "x", "is_final": true, "final_answer": "x"}`)
+	detach := bus.attach(map[string]*agent.Agent{"a": a, "b": b}, 3)
+	defer detach()
+
+	nested := context.WithValue(context.Background(), askChainKey{}, []string{"a"})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		from    string
+		to      string
+		wantErr string
+	}{
+		{"self", context.Background(), "a", "a", "yourself"},
+		{"unknown", context.Background(), "a", "missing", "not found"},
+		{"hop limit", nested, "b", "c", "nested questions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bus.Ask(tt.ctx, tt.from, tt.to, "q")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if got := len(bus.Messages()); got != len(tests) {
+		t.Errorf("expected %d logged messages, got %d", len(tests), got)
+	}
+}
+
+func TestMessageBusCycle(t *testing.T) {
+	bus := NewMessageBus(5)
+	a := newScriptedAgent("a", `{"thought": "x", "is_final": true, "final_answer": "x"}`)
+	b := newScriptedAgent("b", `{"thought": "x", "is_final": true, "final_answer": "x"}`)
+	detach := bus.attach(map[string]*agent.Agent{"a": a, "b": b}, 3)
+	defer detach()
+
+	// a asked b, and b now tries to ask a back
+	ctx := context.WithValue(context.Background(), askChainKey{}, []string{"a"})
+	_, err := bus.Ask(ctx, "b", "a", "q")
+	if err == nil || !strings.Contains(err.Error(), "already waiting") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+}
+
+func TestAskAgentToolLifecycle(t *testing.T) {
+	bus := NewMessageBus(0)
+	a := newScriptedAgent("a", `{"thought": "x", "is_final": true, "final_answer": "x"}`)
+	b := newScriptedAgent("b", `{"thought": "x", "is_final": true, "final_answer": "from b"}`)
+
+	detach := bus.attach(map[string]*agent.Agent{"a": a, "b": b}, 3)
+	if !a.RemoveTool(AskAgentToolName) {
+		t.Fatal("expected ask_agent to be registered during orchestration")
+	}
+	_ = a.AddTool(newAskAgentTool(bus, "a"))
+
+	tool := newAskAgentTool(bus, "a")
+	if !strings.Contains(tool.Metadata().Description, "b") {
+		t.Errorf("expected peers in description, got %q", tool.Metadata().Description)
+	}
+
+	args, _ := json.Marshal(askAgentArgs{Agent: "b", Question: "hi"})
+	result, err := tool.Execute(context.Background(), args)
+	if err != nil || !result.Success() || !strings.Contains(result.Output, "from b") {
+		t.Errorf("unexpected result: %+v, err %v", result, err)
+	}
+
+	detach()
+	if a.RemoveTool(AskAgentToolName) {
+		t.Error("expected ask_agent to be removed after orchestration")
+	}
+}
+
+func TestMessageBusRefusesBusyAgent(t *testing.T) {
+	bus := NewMessageBus(2)
+	expert := newScriptedAgent("expert", `{"thought": "easy", "is_final": true, "final_answer": "42"}`)
+	detach := bus.attach(map[string]*agent.Agent{"expert": expert, "asker": newScriptedAgent("asker")}, 3)
+	defer detach()
+
+	// The supervisor is running expert on a sub-goal
+	release, err := bus.claim(context.Background(), "expert")
+	if err != nil {
+		t.Fatalf("unexpected claim error: %v", err)
+	}
+
+	_, err = bus.Ask(context.Background(), "asker", "expert", "what is the answer?")
+	if err == nil || !strings.Contains(err.Error(), "busy") {
+		t.Fatalf("expected busy error, got %v", err)
+	}
+
+	release()
+	answer, err := bus.Ask(context.Background(), "asker", "expert", "what is the answer?")
+	if err != nil || answer != "42" {
+		t.Fatalf("expected '42' once expert is free, got %q, %v", answer, err)
+	}
+}
+
+func TestMessageBusClaimWaitsForRelease(t *testing.T) {
+	bus := NewMessageBus(2)
+	release, _ := bus.claim(context.Background(), "expert")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := bus.claim(ctx, "expert"); err == nil {
+		t.Fatal("expected claim on a busy agent to stop when ctx is done")
+	}
+
+	claimed := make(chan struct{})
+	go func() {
+		next, err := bus.claim(context.Background(), "expert")
+		if err == nil {
+			next()
+		}
+		close(claimed)
+	}()
+	release()
+	<-claimed
+}
@@ -0,0 +1,130 @@
+// Follow-up suggestions for unfinished orchestrations.
+//
+// When the supervisor runs out of steps, it asks its LLM for two or three
+// concrete next steps, given the task, the sub-goal status and the last
+// observations. If the call fails (or the run was cancelled), the
+// suggestions are derived from the sub-goals instead: retry the failed
+// ones, run the ready ones, finish the ones in progress.
+//
+// Information Hiding:
+// - Suggestion prompt and reply parsing hidden
+// - Rule-based fallback hidden
+
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	jsonutil "github.com/richinex/ariadne/internal/json"
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+)
+
+const (
+	maxNextSteps = 3
+	// nextStepObservations is how many of the last observations the
+	// suggestion prompt shows, each capped at nextStepObservationLen bytes.
+	nextStepObservations   = 3
+	nextStepObservationLen = 500
+)
+
+// nextStepsReply is the LLM's reply format.
+type nextStepsReply struct {
+	NextSteps []string `json:"next_steps"`
+}
+
+// suggestNextSteps returns up to maxNextSteps follow-ups for an
+// orchestration that stopped before finishing task.
+func (s *Supervisor) suggestNextSteps(ctx context.Context, task string, progress *taskProgress, steps []Step, tokenStats *TokenStats) []string {
+	fallback := progress.nextStepHints(maxNextSteps)
+	if ctx.Err() != nil {
+		return fallback
+	}
+
+	var observations []string
+	for i := len(steps) - 1; i >= 0 && len(observations) < nextStepObservations; i-- {
+		if obs := steps[i].Observation; obs != nil && *obs != "" {
+			observations = append(observations, text.Truncate(*obs, nextStepObservationLen))
+		}
+	}
+
+	messages := []llm.ChatMessage{
+		{
+			Role: "system",
+			Content: `A multi-agent run stopped before finishing its task. Suggest 2 or 3 concrete next steps a user could take to finish it, based on the sub-goals that are not done and the last observations. Name the sub-goal, file or error each step is about. Don't suggest only "try again".
+
+Respond in this EXACT JSON format:
+{"next_steps": ["first step", "second step"]}`,
+		},
+		{
+			Role: "user",
+			Content: fmt.Sprintf("Task: %s\n%s\nLast observations (newest first):\n%s",
+				task, progress.detailedStatus(), strings.Join(observations, "\n---\n")),
+		},
+	}
+
+	response, usage, err := s.llmClient.ChatWithUsage(ctx, messages)
+	if err != nil {
+		return fallback
+	}
+	tokenStats.LLMCalls++
+	tokenStats.AddUsage(usage)
+
+	reply, err := jsonutil.DecodeValidated(response, func(r nextStepsReply) error {
+		if len(r.NextSteps) == 0 {
+			return fmt.Errorf("next_steps is empty")
+		}
+		return nil
+	})
+	if err != nil {
+		return fallback
+	}
+	var suggestions []string
+	for _, step := range reply.NextSteps {
+		if step = strings.TrimSpace(step); step != "" && len(suggestions) < maxNextSteps {
+			suggestions = append(suggestions, step)
+		}
+	}
+	if len(suggestions) == 0 {
+		return fallback
+	}
+	return suggestions
+}
+
+// nextStepHints derives up to limit follow-ups from the sub-goals: failed
+// ones first, then those ready to run, then those left in progress.
+func (p *taskProgress) nextStepHints(limit int) []string {
+	var hints []string
+	add := func(hint string) {
+		if len(hints) < limit {
+			hints = append(hints, hint)
+		}
+	}
+	for _, id := range p.order {
+		if g := p.goalsByID[id]; g.Status == subGoalFailed {
+			reason := ""
+			if g.Result != nil {
+				reason = ", which failed with: " + text.Truncate(*g.Result, 120)
+			}
+			add(fmt.Sprintf("Retry sub-goal %s (%s)%s", id, g.Description, reason))
+		}
+	}
+	for _, id := range p.readyGoals() {
+		add(fmt.Sprintf("Run sub-goal %s: %s", id, p.goalsByID[id].Description))
+	}
+	for _, id := range p.order {
+		if g := p.goalsByID[id]; g.Status == subGoalInProgress {
+			hint := fmt.Sprintf("Finish sub-goal %s: %s", id, g.Description)
+			if g.AssignedAgent != nil {
+				hint += " (started by " + *g.AssignedAgent + ")"
+			}
+			add(hint)
+		}
+	}
+	if len(hints) == 0 {
+		add("Run again with more orchestration steps, starting from the partial results")
+	}
+	return hints
+}
@@ -0,0 +1,54 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+)
+
+func TestSupervisorSuggestsNextSteps(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		`{"thought": "split it", "sub_goals": [{"id": "parse", "description": "parse the logs"}], "is_final": false}`,
+		`{"next_steps": ["Run sub-goal parse on logs/app.log", "Check the timestamp format", "Summarize the errors", "One too many"]}`,
+	}}
+	supervisor := NewSupervisor(nil, llm.NewClient(provider), DefaultSupervisorConfig())
+
+	resp := supervisor.Orchestrate(context.Background(), "summarize the logs", 1)
+	if resp.Type != ResponseTimeout {
+		t.Fatalf("expected timeout, got %+v", resp)
+	}
+	steps := resp.CompletionStatus.NextSteps
+	if len(steps) != maxNextSteps || steps[0] != "Run sub-goal parse on logs/app.log" {
+		t.Errorf("expected the LLM's first %d suggestions, got %q", maxNextSteps, steps)
+	}
+	if resp.Metadata.TokenStats.LLMCalls != 2 {
+		t.Errorf("suggestion call should be counted, got %d LLM calls", resp.Metadata.TokenStats.LLMCalls)
+	}
+}
+
+func TestNextStepHints(t *testing.T) {
+	progress := newTaskProgress()
+	progress.addSubGoal("fetch", "download the data")
+	progress.addSubGoal("clean", "clean the data")
+	progress.addSubGoal("report", "write the report")
+	progress.addSubGoal("chart", "draw a chart")
+	progress.goalsByID["report"].DependsOn = []string{"clean"}
+	progress.markFailed("fetch", "connection refused")
+	progress.markInProgress("chart", "plotter")
+
+	hints := progress.nextStepHints(maxNextSteps)
+	want := []string{
+		"Retry sub-goal fetch (download the data), which failed with: connection refused",
+		"Run sub-goal clean: clean the data",
+		"Finish sub-goal chart: draw a chart (started by plotter)",
+	}
+	if strings.Join(hints, "\n") != strings.Join(want, "\n") {
+		t.Errorf("nextStepHints() =\n%s\nwant\n%s", strings.Join(hints, "\n"), strings.Join(want, "\n"))
+	}
+
+	if hints := newTaskProgress().nextStepHints(maxNextSteps); len(hints) != 1 {
+		t.Errorf("expected one generic hint without sub-goals, got %q", hints)
+	}
+}
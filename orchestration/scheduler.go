@@ -0,0 +1,253 @@
+// Run Scheduling.
+//
+// Caps concurrent runs per lane (typically tenant and provider) when
+// ariadne is embedded in a shared service. Excess runs wait in a FIFO
+// queue with bounded length, callers are told their queue position as it
+// changes, and per-lane metrics are exposed for monitoring. `ariadne
+// batch` uses one lane to cap how many of its tasks run at once.
+//
+// Information Hiding:
+// - Lane bookkeeping and FIFO queue hidden
+// - Position notification and cancellation races hidden
+
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxConcurrentRuns is the default per-lane concurrency limit.
+const DefaultMaxConcurrentRuns = 2
+
+// DefaultMaxQueuedRuns is the default per-lane queue length.
+const DefaultMaxQueuedRuns = 32
+
+// ErrQueueFull is returned when a lane's queue is at capacity (backpressure).
+var ErrQueueFull = errors.New("run queue full")
+
+// SchedulerLane builds a lane key from a tenant and provider.
+func SchedulerLane(tenantID, provider string) string {
+	return tenantID + "/" + provider
+}
+
+// LaneStats reports one lane's current load.
+type LaneStats struct {
+	Lane    string `json:"lane"`
+	Running int    `json:"running"`
+	Queued  int    `json:"queued"`
+}
+
+// SchedulerStats reports scheduler load and lifetime counters.
+type SchedulerStats struct {
+	Lanes    []LaneStats `json:"lanes"`
+	Started  uint64      `json:"started"`
+	Rejected uint64      `json:"rejected"`
+	Canceled uint64      `json:"canceled"`
+	// AvgWaitMs is the mean time started runs spent queued.
+	AvgWaitMs uint64 `json:"avg_wait_ms"`
+}
+
+// RunScheduler limits concurrent runs per lane and queues the rest.
+type RunScheduler struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	maxQueued     int
+	lanes         map[string]*schedulerLane
+	started       uint64
+	rejected      uint64
+	canceled      uint64
+	totalWait     time.Duration
+}
+
+type schedulerLane struct {
+	running int
+	queue   []*runTicket
+}
+
+// runTicket is one queued run. ready is closed when it is granted a slot;
+// position carries the latest queue position (1 = next to run).
+type runTicket struct {
+	ready      chan struct{}
+	position   chan int
+	enqueuedAt time.Time
+}
+
+// NewRunScheduler creates a scheduler allowing maxConcurrent runs per lane.
+// Non-positive values fall back to DefaultMaxConcurrentRuns.
+func NewRunScheduler(maxConcurrent int) *RunScheduler {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentRuns
+	}
+	return &RunScheduler{
+		maxConcurrent: maxConcurrent,
+		maxQueued:     DefaultMaxQueuedRuns,
+		lanes:         make(map[string]*schedulerLane),
+	}
+}
+
+// WithMaxQueued sets how many runs may wait per lane before Acquire returns
+// ErrQueueFull. Zero rejects any run that can't start immediately.
+func (s *RunScheduler) WithMaxQueued(n int) *RunScheduler {
+	if n >= 0 {
+		s.maxQueued = n
+	}
+	return s
+}
+
+// Acquire waits for a run slot in lane and returns a release function that
+// must be called when the run finishes. onPosition, if non-nil, is called
+// with the caller's queue position each time it changes while waiting.
+// Returns ErrQueueFull when the lane's queue is full, or the context error
+// if ctx is done before a slot frees up.
+func (s *RunScheduler) Acquire(ctx context.Context, lane string, onPosition func(position int)) (func(), error) {
+	s.mu.Lock()
+	l := s.lane(lane)
+	if l.running < s.maxConcurrent && len(l.queue) == 0 {
+		l.running++
+		s.started++
+		s.mu.Unlock()
+		return s.releaser(lane), nil
+	}
+	if len(l.queue) >= s.maxQueued {
+		s.rejected++
+		s.mu.Unlock()
+		return nil, fmt.Errorf("lane %s: %w (%d queued)", lane, ErrQueueFull, len(l.queue))
+	}
+
+	t := &runTicket{
+		ready:      make(chan struct{}),
+		position:   make(chan int, 1),
+		enqueuedAt: time.Now(),
+	}
+	l.queue = append(l.queue, t)
+	t.notify(len(l.queue))
+	s.mu.Unlock()
+
+	for {
+		select {
+		case <-t.ready:
+			return s.releaser(lane), nil
+		case pos := <-t.position:
+			if onPosition != nil {
+				onPosition(pos)
+			}
+		case <-ctx.Done():
+			if !s.cancel(lane, t) {
+				// Granted concurrently with cancellation: hand the slot back
+				s.releaser(lane)()
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Run acquires a slot in lane, runs fn, and releases the slot.
+func (s *RunScheduler) Run(ctx context.Context, lane string, onPosition func(position int), fn func(ctx context.Context) error) error {
+	release, err := s.Acquire(ctx, lane, onPosition)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+// Stats returns current per-lane load (sorted by lane) and lifetime counters.
+func (s *RunScheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SchedulerStats{
+		Lanes:    make([]LaneStats, 0, len(s.lanes)),
+		Started:  s.started,
+		Rejected: s.rejected,
+		Canceled: s.canceled,
+	}
+	for name, l := range s.lanes {
+		stats.Lanes = append(stats.Lanes, LaneStats{Lane: name, Running: l.running, Queued: len(l.queue)})
+	}
+	sort.Slice(stats.Lanes, func(i, j int) bool { return stats.Lanes[i].Lane < stats.Lanes[j].Lane })
+	if s.started > 0 {
+		stats.AvgWaitMs = uint64(s.totalWait.Milliseconds()) / s.started
+	}
+	return stats
+}
+
+// lane returns the named lane, creating it. Caller holds s.mu.
+func (s *RunScheduler) lane(name string) *schedulerLane {
+	l, ok := s.lanes[name]
+	if !ok {
+		l = &schedulerLane{}
+		s.lanes[name] = l
+	}
+	return l
+}
+
+// releaser returns an idempotent release function for one slot in lane.
+func (s *RunScheduler) releaser(lane string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			l := s.lane(lane)
+			l.running--
+			if len(l.queue) > 0 {
+				next := l.queue[0]
+				l.queue = l.queue[1:]
+				l.running++
+				s.started++
+				s.totalWait += time.Since(next.enqueuedAt)
+				close(next.ready)
+				l.notifyPositions()
+			}
+			if l.running == 0 && len(l.queue) == 0 {
+				delete(s.lanes, lane)
+			}
+		})
+	}
+}
+
+// cancel removes a waiting ticket. Returns false if it was already granted.
+func (s *RunScheduler) cancel(lane string, t *runTicket) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.lanes[lane]
+	if !ok {
+		return false
+	}
+	for i, queued := range l.queue {
+		if queued == t {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			s.canceled++
+			l.notifyPositions()
+			if l.running == 0 && len(l.queue) == 0 {
+				delete(s.lanes, lane)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// notifyPositions tells every waiting ticket its new position. Caller holds s.mu.
+func (l *schedulerLane) notifyPositions() {
+	for i, t := range l.queue {
+		t.notify(i + 1)
+	}
+}
+
+// notify replaces any unread position with the latest one without blocking.
+func (t *runTicket) notify(position int) {
+	select {
+	case <-t.position:
+	default:
+	}
+	t.position <- position
+}
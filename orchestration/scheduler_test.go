@@ -0,0 +1,90 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunSchedulerQueuesBeyondLimit(t *testing.T) {
+	s := NewRunScheduler(1)
+	ctx := context.Background()
+
+	release, err := s.Acquire(ctx, "acme/openai", nil)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	positions := make(chan int, 4)
+	granted := make(chan func())
+	go func() {
+		r, err := s.Acquire(ctx, "acme/openai", func(pos int) { positions <- pos })
+		if err != nil {
+			t.Errorf("queued Acquire failed: %v", err)
+		}
+		granted <- r
+	}()
+
+	if pos := <-positions; pos != 1 {
+		t.Errorf("expected queue position 1, got %d", pos)
+	}
+	stats := s.Stats()
+	if len(stats.Lanes) != 1 || stats.Lanes[0].Running != 1 || stats.Lanes[0].Queued != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// Other lanes are not blocked
+	other, err := s.Acquire(ctx, "globex/openai", nil)
+	if err != nil {
+		t.Fatalf("Acquire in other lane failed: %v", err)
+	}
+	other()
+
+	release()
+	select {
+	case r := <-granted:
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("queued run was not granted after release")
+	}
+
+	if stats := s.Stats(); len(stats.Lanes) != 0 || stats.Started != 3 {
+		t.Errorf("expected idle scheduler with 3 started runs, got %+v", stats)
+	}
+}
+
+func TestRunSchedulerBackpressure(t *testing.T) {
+	s := NewRunScheduler(1).WithMaxQueued(0)
+	release, _ := s.Acquire(context.Background(), "lane", nil)
+	defer release()
+
+	_, err := s.Acquire(context.Background(), "lane", nil)
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if s.Stats().Rejected != 1 {
+		t.Errorf("expected 1 rejected run, got %d", s.Stats().Rejected)
+	}
+}
+
+func TestRunSchedulerCancelWhileQueued(t *testing.T) {
+	s := NewRunScheduler(1)
+	release, _ := s.Acquire(context.Background(), "lane", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := s.Run(ctx, "lane", nil, func(context.Context) error {
+		t.Error("canceled run should not execute")
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	release()
+	stats := s.Stats()
+	if stats.Canceled != 1 || len(stats.Lanes) != 0 {
+		t.Errorf("expected 1 canceled run and no lanes, got %+v", stats)
+	}
+}
@@ -0,0 +1,148 @@
+// Sub-goal priorities and dependencies.
+//
+// The supervisor may declare a priority and depends_on list for each
+// sub-goal. A sub-goal is ready once everything it depends on has
+// completed; ready sub-goals are offered highest priority first, and an
+// agent is only started on a sub-goal that is ready.
+//
+// Information Hiding:
+// - Cycle rejection at declaration time hidden
+// - Readiness ordering and dependency graph rendering hidden
+
+package orchestration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// String names the status for prompts.
+func (s subGoalStatus) String() string {
+	switch s {
+	case subGoalInProgress:
+		return "in progress"
+	case subGoalCompleted:
+		return "completed"
+	case subGoalFailed:
+		return "failed"
+	default:
+		return "pending"
+	}
+}
+
+// declare adds a declared sub-goal, or updates the priority and
+// dependencies of one already known. Dependencies on itself or that would
+// form a cycle are dropped.
+func (p *taskProgress) declare(decl subGoalDeclaration) {
+	p.addSubGoal(decl.ID, decl.Description)
+	goal := p.goalsByID[decl.ID]
+	goal.Priority = decl.Priority
+	goal.DependsOn = nil
+	for _, dep := range decl.DependsOn {
+		if dep == decl.ID || p.dependsOn(dep, decl.ID, map[string]bool{}) {
+			continue
+		}
+		goal.DependsOn = append(goal.DependsOn, dep)
+	}
+}
+
+// dependsOn reports whether id depends, directly or not, on target.
+func (p *taskProgress) dependsOn(id, target string, seen map[string]bool) bool {
+	goal, ok := p.goalsByID[id]
+	if !ok || seen[id] {
+		return false
+	}
+	seen[id] = true
+	for _, dep := range goal.DependsOn {
+		if dep == target || p.dependsOn(dep, target, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// unmetDependencies describes the dependencies of id that haven't
+// completed, e.g. "goal_1 (failed)". Empty means id is ready to run.
+func (p *taskProgress) unmetDependencies(id string) []string {
+	goal, ok := p.goalsByID[id]
+	if !ok {
+		return nil
+	}
+	var unmet []string
+	for _, dep := range goal.DependsOn {
+		depGoal, ok := p.goalsByID[dep]
+		switch {
+		case !ok:
+			unmet = append(unmet, dep+" (not declared)")
+		case depGoal.Status != subGoalCompleted:
+			unmet = append(unmet, fmt.Sprintf("%s (%s)", dep, depGoal.Status))
+		}
+	}
+	return unmet
+}
+
+// readyGoals returns the IDs of pending sub-goals whose dependencies have
+// all completed, highest priority first, then in declaration order.
+func (p *taskProgress) readyGoals() []string {
+	var ready []string
+	for _, id := range p.order {
+		if p.goalsByID[id].Status == subGoalPending && len(p.unmetDependencies(id)) == 0 {
+			ready = append(ready, id)
+		}
+	}
+	sort.SliceStable(ready, func(i, j int) bool {
+		return p.goalsByID[ready[i]].Priority > p.goalsByID[ready[j]].Priority
+	})
+	return ready
+}
+
+// goalLine renders one sub-goal with its priority and dependencies.
+func (g *subGoal) goalLine() string {
+	line := g.ID + ": " + g.Description
+	var notes []string
+	if g.Priority != 0 {
+		notes = append(notes, fmt.Sprintf("priority %d", g.Priority))
+	}
+	if len(g.DependsOn) > 0 {
+		notes = append(notes, "after "+strings.Join(g.DependsOn, ", "))
+	}
+	if len(notes) > 0 {
+		line += " (" + strings.Join(notes, "; ") + ")"
+	}
+	return line
+}
+
+// PlanItem is one sub-goal of an orchestration's plan, as it stood when
+// the run ended.
+type PlanItem struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Agent       string   `json:"agent,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+}
+
+// plan lists the sub-goals in declaration order; nil for a nil p.
+func (p *taskProgress) plan() []PlanItem {
+	if p == nil || len(p.order) == 0 {
+		return nil
+	}
+	items := make([]PlanItem, 0, len(p.order))
+	for _, id := range p.order {
+		g := p.goalsByID[id]
+		item := PlanItem{
+			ID:          g.ID,
+			Description: g.Description,
+			Status:      g.Status.String(),
+			Priority:    g.Priority,
+			DependsOn:   g.DependsOn,
+		}
+		if g.AssignedAgent != nil {
+			item.Agent = *g.AssignedAgent
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,116 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+)
+
+func TestTaskProgressDependencies(t *testing.T) {
+	progress := newTaskProgress()
+	progress.declare(subGoalDeclaration{ID: "fetch", Description: "Fetch data"})
+	progress.declare(subGoalDeclaration{ID: "lint", Description: "Lint code", Priority: 2})
+	progress.declare(subGoalDeclaration{ID: "report", Description: "Write report", DependsOn: []string{"fetch", "lint"}})
+	// Would close a cycle, so the dependency is dropped
+	progress.declare(subGoalDeclaration{ID: "fetch", Description: "Fetch data", DependsOn: []string{"report"}})
+
+	if got := strings.Join(progress.readyGoals(), ","); got != "lint,fetch" {
+		t.Errorf("ready goals = %s, want lint,fetch", got)
+	}
+	if unmet := progress.unmetDependencies("report"); len(unmet) != 2 || unmet[0] != "fetch (pending)" {
+		t.Errorf("unexpected unmet dependencies: %v", unmet)
+	}
+
+	progress.markCompleted("fetch", "ok")
+	progress.markFailed("lint", "boom")
+	if unmet := progress.unmetDependencies("report"); len(unmet) != 1 || unmet[0] != "lint (failed)" {
+		t.Errorf("unexpected unmet dependencies: %v", unmet)
+	}
+
+	status := progress.detailedStatus()
+	for _, want := range []string{"[✓] fetch: Fetch data\n", "lint: Lint code (priority 2)", "report: Write report (after fetch, lint)"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("status missing %q:\n%s", want, status)
+		}
+	}
+}
+
+func TestSupervisorWaitsForDependencies(t *testing.T) {
+	worker := newScriptedAgent("worker", `{"thought": "done", "is_final": true, "final_answer": "ok"}`)
+	provider := &scriptedProvider{responses: []string{
+		`{"thought": "plan", "sub_goals": [{"id": "a", "description": "first"}, {"id": "b", "description": "second", "depends_on": ["a"]}], "agent_to_invoke": "worker", "agent_task": "do b", "sub_goal_id": "b", "is_final": false}`,
+		`{"thought": "a first", "agent_to_invoke": "worker", "agent_task": "do a", "sub_goal_id": "a", "is_final": false}`,
+		`{"thought": "now b", "agent_to_invoke": "worker", "agent_task": "do b", "sub_goal_id": "b", "is_final": false}`,
+		`{"thought": "done", "is_final": true, "final_answer": "all done"}`,
+	}}
+	supervisor := NewSupervisor([]*agent.Agent{worker}, llm.NewClient(provider), DefaultSupervisorConfig())
+
+	resp := supervisor.Orchestrate(context.Background(), "two steps", 6)
+	if resp.Type != ResponseSuccess {
+		t.Fatalf("expected success, got %+v", resp)
+	}
+	if len(resp.Steps) != 4 {
+		t.Fatalf("expected 4 steps, got %d", len(resp.Steps))
+	}
+	if obs := *resp.Steps[0].Observation; !strings.Contains(obs, "'b' is waiting on a (pending)") || !strings.Contains(obs, "ready to run: a") {
+		t.Errorf("expected b to wait for a, got %q", obs)
+	}
+	for _, s := range resp.Steps[1:3] {
+		if !strings.HasPrefix(*s.Observation, "SUCCESS") {
+			t.Errorf("expected success once ready, got %q", *s.Observation)
+		}
+	}
+	plan := resp.Metadata.Plan
+	if len(plan) != 2 || plan[1].ID != "b" || plan[1].Status != "completed" || plan[1].Agent != "worker" || plan[1].DependsOn[0] != "a" {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+}
+
+// barrierProvider answers only once every expected caller has arrived, so
+// it deadlocks (and times out) unless its callers run concurrently.
+type barrierProvider struct {
+	scriptedProvider
+	arrived *sync.WaitGroup
+}
+
+func (p *barrierProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	p.arrived.Done()
+	done := make(chan struct{})
+	go func() { p.arrived.Wait(); close(done) }()
+	select {
+	case <-done:
+		return p.scriptedProvider.Chat(ctx, messages)
+	case <-time.After(2 * time.Second):
+		return llm.LLMResponse{Content: `{"thought": "alone", "is_final": true, "final_answer": "ran alone"}`}, nil
+	}
+}
+
+func TestSupervisorRunsIndependentSubGoalsInParallel(t *testing.T) {
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	final := `{"thought": "done", "is_final": true, "final_answer": "together"}`
+	left := agent.New(agent.NewBuilder("left").Build(), &barrierProvider{scriptedProvider: scriptedProvider{responses: []string{final}}, arrived: &arrived})
+	right := agent.New(agent.NewBuilder("right").Build(), &barrierProvider{scriptedProvider: scriptedProvider{responses: []string{final}}, arrived: &arrived})
+	provider := &scriptedProvider{responses: []string{
+		`{"thought": "both", "agent_to_invoke": "left", "agent_task": "l", "sub_goal_id": "l", "parallel_tasks": [{"agent_to_invoke": "right", "agent_task": "r", "sub_goal_id": "r"}], "is_final": false}`,
+		`{"thought": "done", "is_final": true, "final_answer": "merged"}`,
+	}}
+	config := DefaultSupervisorConfig()
+	config.ParallelSubGoals = true
+	supervisor := NewSupervisor([]*agent.Agent{left, right}, llm.NewClient(provider), config)
+
+	resp := supervisor.Orchestrate(context.Background(), "split", 3)
+	if resp.Type != ResponseSuccess || len(resp.Steps) != 3 {
+		t.Fatalf("expected two agent steps and a final step, got %+v", resp)
+	}
+	for _, s := range resp.Steps[:2] {
+		if *s.Observation != "SUCCESS: together" {
+			t.Errorf("agents did not run concurrently: %q", *s.Observation)
+		}
+	}
+}
@@ -15,11 +15,16 @@ package orchestration
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/internal/text"
 	"github.com/richinex/ariadne/model"
 	jsonutil "github.com/richinex/ariadne/internal/json"
 	"github.com/richinex/ariadne/llm"
@@ -28,8 +33,18 @@ import (
 
 // SubGoalDeclaration is a sub-goal declared during task planning.
 type subGoalDeclaration struct {
-	ID          string `json:"id"`
-	Description string `json:"description"`
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Priority    int      `json:"priority,omitempty"`   // Higher runs first
+	DependsOn   []string `json:"depends_on,omitempty"` // IDs that must complete first
+}
+
+// agentAssignment is one agent invocation in a supervisor decision.
+type agentAssignment struct {
+	Agent     string `json:"agent_to_invoke"`
+	Task      string `json:"agent_task"`
+	SubGoalID string `json:"sub_goal_id,omitempty"`
+	goal      string // Sub-goal description, for verification
 }
 
 // supervisorDecision is returned by LLM for next action.
@@ -41,6 +56,9 @@ type supervisorDecision struct {
 	SubGoalID     *string               `json:"sub_goal_id,omitempty"`
 	IsFinal       bool                  `json:"is_final"`
 	FinalAnswer   *string               `json:"final_answer,omitempty"`
+	// ParallelTasks runs further independent sub-goals in the same step
+	// (only with SupervisorConfig.ParallelSubGoals).
+	ParallelTasks []agentAssignment `json:"parallel_tasks,omitempty"`
 }
 
 // subGoalStatus represents the status of a sub-goal.
@@ -60,6 +78,8 @@ type subGoal struct {
 	Status        subGoalStatus
 	AssignedAgent *string
 	Result        *string
+	Priority      int
+	DependsOn     []string
 }
 
 // taskProgress tracks progress across sub-goals.
@@ -114,6 +134,17 @@ func (p *taskProgress) markFailed(id, errMsg string) {
 	}
 }
 
+// completedResults maps completed sub-goal IDs to their results.
+func (p *taskProgress) completedResults() map[string]string {
+	results := make(map[string]string)
+	for _, id := range p.order {
+		if g := p.goalsByID[id]; g.Status == subGoalCompleted && g.Result != nil {
+			results[id] = *g.Result
+		}
+	}
+	return results
+}
+
 func (p *taskProgress) hasGoal(id string) bool {
 	_, exists := p.goalsByID[id]
 	return exists
@@ -145,7 +176,10 @@ func (p *taskProgress) detailedStatus() string {
 		case subGoalFailed:
 			icon = "[✗]"
 		}
-		status += fmt.Sprintf("  %s %s\n", icon, g.Description)
+		status += fmt.Sprintf("  %s %s\n", icon, g.goalLine())
+	}
+	if ready := p.readyGoals(); len(ready) > 0 {
+		status += fmt.Sprintf("Ready to run: %s\n", strings.Join(ready, ", "))
 	}
 	return status
 }
@@ -157,6 +191,21 @@ type SupervisorConfig struct {
 	// LargeResultThreshold is the byte size above which results are stored
 	// in ResultStore instead of being passed in conversation. Default: 2KB.
 	LargeResultThreshold int
+	// ParallelSubGoals lets one decision start several ready sub-goals
+	// (parallel_tasks), which then run concurrently on different agents.
+	ParallelSubGoals bool
+	// AgentBudget limits the tokens and wall time of each agent invocation;
+	// AgentBudgets overrides it by agent name. Zero fields are unlimited.
+	AgentBudget  agent.Budget
+	AgentBudgets map[string]agent.Budget
+}
+
+// budgetFor returns the budget for one invocation of the named agent.
+func (c SupervisorConfig) budgetFor(agentName string) agent.Budget {
+	if budget, ok := c.AgentBudgets[agentName]; ok {
+		return budget
+	}
+	return c.AgentBudget
 }
 
 // DefaultSupervisorConfig returns default supervisor configuration.
@@ -177,6 +226,11 @@ type Supervisor struct {
 	handoffCoordinator *Coordinator
 	storage            storage.MemoryStorage
 	resultStore        *storage.ResultStore
+	resultSession      string
+	messageBus         *MessageBus
+	verifier           Verifier
+	verifyRetries      int
+	onStep             func(step int, stats TokenStats)
 	sessionID          string
 	verbose            bool
 }
@@ -217,6 +271,27 @@ func (s *Supervisor) WithResultStore(store *storage.ResultStore) *Supervisor {
 	return s
 }
 
+// WithResultSession stores large results under sessionID instead of the
+// memory session, so agents' DSA tools scoped to it can search them.
+func (s *Supervisor) WithResultSession(sessionID string) *Supervisor {
+	s.resultSession = sessionID
+	return s
+}
+
+// WithMessageBus lets agents ask each other questions directly via the
+// ask_agent tool. The tool is only registered while Orchestrate is running.
+func (s *Supervisor) WithMessageBus(bus *MessageBus) *Supervisor {
+	s.messageBus = bus
+	return s
+}
+
+// WithStepObserver calls fn after each orchestration step with the number
+// of steps done and the token stats so far (e.g. for a live status line).
+func (s *Supervisor) WithStepObserver(fn func(step int, stats TokenStats)) *Supervisor {
+	s.onStep = fn
+	return s
+}
+
 // Verbose enables verbose output (shows LLM reasoning).
 func (s *Supervisor) Verbose(enabled bool) *Supervisor {
 	s.verbose = enabled
@@ -237,6 +312,12 @@ func (s *Supervisor) Orchestrate(ctx context.Context, task string, maxOrchestrat
 	// Store task initiation
 	s.storeOrchestrationMemory(ctx, fmt.Sprintf("Started orchestration: %s", task), nil)
 
+	// Enable direct agent-to-agent questions for the duration of this run
+	if s.messageBus != nil {
+		detach := s.messageBus.attach(s.agents, s.config.MaxIterations)
+		defer detach()
+	}
+
 	var conversation []llm.ChatMessage
 	var allSteps []Step
 	agentResultsContext := make(map[string]interface{})
@@ -255,6 +336,14 @@ func (s *Supervisor) Orchestrate(ctx context.Context, task string, maxOrchestrat
 		priorContextSection = "\n\n" + priorContext + "\n"
 	}
 
+	parallelField, parallelRule := "", ""
+	if s.config.ParallelSubGoals {
+		parallelField = `
+  "parallel_tasks": [{"agent_to_invoke": "...", "agent_task": "...", "sub_goal_id": "..."}, ...] or null,`
+		parallelRule = `
+- parallel_tasks starts more ready sub-goals in the same step, running alongside agent_to_invoke; use it for independent sub-goals on different agents`
+	}
+
 	systemPrompt := fmt.Sprintf(
 		`You are a supervisor that coordinates multiple specialized agents to accomplish complex tasks.
 
@@ -283,14 +372,19 @@ IMPORTANT - Using Agent Results Efficiently:
 You MUST respond in this EXACT JSON format:
 {
   "thought": "your reasoning about what to do next",
-  "sub_goals": [{"id": "goal_1", "description": "..."}, ...] or null,
+  "sub_goals": [{"id": "goal_1", "description": "...", "priority": 0, "depends_on": []}, ...] or null,
   "agent_to_invoke": "agent_name or null",
   "agent_task": "specific task description as a plain text STRING or null",
-  "sub_goal_id": "which sub-goal this addresses or null",
+  "sub_goal_id": "which sub-goal this addresses or null",%s
   "is_final": false,
   "final_answer": null
 }
 
+SUB-GOAL ORDERING:
+- depends_on lists the sub-goal ids that must complete before this one can start
+- priority orders sub-goals that are ready at the same time (higher first, default 0)
+- An agent can only be invoked for a sub-goal whose dependencies have completed%s
+
 CRITICAL FORMAT RULES:
 - agent_task MUST be a plain string like "read the go.mod file", NOT an object
 - All string values must be simple text, never nested JSON objects
@@ -301,12 +395,17 @@ Respond with valid JSON only. No extra text.%s`,
 		maxOrchestrationSteps,
 		s.config.MaxSubGoals,
 		s.config.MaxSubGoals,
+		parallelField,
+		parallelRule,
 		priorContextSection,
 	)
 
+	// The stored-content inventory follows the fixed prompt and is
+	// refreshed whenever agents store more
+	inventoryGeneration := s.storeGeneration()
 	conversation = append(conversation, llm.ChatMessage{
 		Role:    "system",
-		Content: systemPrompt,
+		Content: systemPrompt + s.storedInventory(ctx),
 	})
 
 	conversation = append(conversation, llm.ChatMessage{
@@ -315,28 +414,31 @@ Respond with valid JSON only. No extra text.%s`,
 	})
 
 	for step := 0; step < maxOrchestrationSteps; step++ {
+		if step > 0 && s.onStep != nil {
+			s.onStep(step, *tokenStats)
+		}
+
 		// Check context cancellation
 		if ctx.Err() != nil {
-			return NewFailureResponse(
-				fmt.Sprintf("orchestration cancelled: %v", ctx.Err()),
-				allSteps,
-				buildMetadata(tokenStats),
-				&CompletionStatus{
-					Type:        StatusFailed,
-					Error:       ctx.Err().Error(),
-					Recoverable: true,
-				},
-			)
+			return cancelledResponse(ctx, allSteps, tokenStats, progress)
 		}
 
 		remainingSteps := maxOrchestrationSteps - step
 
+		if generation := s.storeGeneration(); generation != inventoryGeneration {
+			inventoryGeneration = generation
+			conversation[0].Content = systemPrompt + s.storedInventory(ctx)
+		}
+
 		decision, err := s.decideNextAction(ctx, conversation, tokenStats)
+		if err != nil && ctx.Err() != nil {
+			return cancelledResponse(ctx, allSteps, tokenStats, progress)
+		}
 		if err != nil {
-			return NewFailureResponse(
-				fmt.Sprintf("Supervisor decision failed: %v", err),
+			return NewErrorResponse(
+				fmt.Errorf("supervisor decision failed: %w", err),
 				allSteps,
-				buildMetadata(tokenStats),
+				buildMetadata(tokenStats, progress),
 				&CompletionStatus{
 					Type:        StatusFailed,
 					Error:       fmt.Sprintf("Supervisor reasoning failed: %v", err),
@@ -352,7 +454,7 @@ Respond with valid JSON only. No extra text.%s`,
 				goalsToAdd = goalsToAdd[:s.config.MaxSubGoals]
 			}
 			for _, decl := range goalsToAdd {
-				progress.addSubGoal(decl.ID, decl.Description)
+				progress.declare(decl)
 			}
 		}
 
@@ -364,10 +466,7 @@ Respond with valid JSON only. No extra text.%s`,
 			}
 
 			// Store completion in memory
-			preview := finalAnswer
-			if len(preview) > 200 {
-				preview = preview[:200] + "..."
-			}
+			preview := text.Truncate(finalAnswer, 200)
 			s.storeOrchestrationMemory(ctx, fmt.Sprintf("Orchestration completed: %s", preview), nil)
 
 			allSteps = append(allSteps, model.Step{
@@ -379,154 +478,125 @@ Respond with valid JSON only. No extra text.%s`,
 			return NewSuccessResponse(
 				finalAnswer,
 				allSteps,
-				buildMetadata(tokenStats),
+				buildMetadata(tokenStats, progress),
 				&CompletionStatus{Type: StatusComplete},
 			)
 		}
 
-		// Invoke agent if specified
-		if decision.AgentToInvoke != nil && decision.AgentTask != nil {
-			agentName := *decision.AgentToInvoke
-			agentTask := *decision.AgentTask
+		assignments := s.assignments(decision, step)
+		if len(assignments) == 0 {
+			// No agent invoked
+			warning := "Supervisor must either invoke an agent or mark task as final"
+			conversation = append(conversation, llm.ChatMessage{
+				Role:    "user",
+				Content: fmt.Sprintf("%s\nPlease either invoke an agent or set is_final=true", warning),
+			})
+			allSteps = append(allSteps, model.Step{
+				Iteration:   step,
+				Thought:     decision.Thought,
+				Observation: &warning,
+			})
+			continue
+		}
 
-			subGoalID := fmt.Sprintf("goal_%d", step)
-			if decision.SubGoalID != nil {
-				subGoalID = *decision.SubGoalID
+		// Start only assignments whose agent exists and whose sub-goal is ready
+		var batch []agentAssignment
+		var reports []string
+		for _, assignment := range assignments {
+			agentName := assignment.Agent
+			action := agentName
+			if !progress.hasGoal(assignment.SubGoalID) {
+				progress.addSubGoal(assignment.SubGoalID, assignment.Task)
 			}
 
-			if !progress.hasGoal(subGoalID) {
-				progress.addSubGoal(subGoalID, agentTask)
+			var errorMsg string
+			if _, exists := s.agents[agentName]; !exists {
+				errorMsg = fmt.Sprintf("Agent '%s' not found", agentName)
+			} else if unmet := progress.unmetDependencies(assignment.SubGoalID); len(unmet) > 0 {
+				errorMsg = fmt.Sprintf("Sub-goal '%s' is waiting on %s", assignment.SubGoalID, strings.Join(unmet, ", "))
+				if ready := progress.readyGoals(); len(ready) > 0 {
+					errorMsg += fmt.Sprintf("; ready to run: %s", strings.Join(ready, ", "))
+				}
+				action = fmt.Sprintf("%s:%s", agentName, assignment.Task)
+			} else if progress.goalsByID[assignment.SubGoalID].Status == subGoalInProgress {
+				errorMsg = fmt.Sprintf("Sub-goal '%s' was assigned twice in one step", assignment.SubGoalID)
+				action = fmt.Sprintf("%s:%s", agentName, assignment.Task)
 			}
-
-			progress.markInProgress(subGoalID, agentName)
-
-			selectedAgent, exists := s.agents[agentName]
-			if !exists {
-				errorMsg := fmt.Sprintf("Agent '%s' not found", agentName)
-				conversation = append(conversation, llm.ChatMessage{
-					Role:    "user",
-					Content: fmt.Sprintf("Error: %s", errorMsg),
-				})
+			if errorMsg != "" {
+				reports = append(reports, fmt.Sprintf("Error: %s", errorMsg))
 				allSteps = append(allSteps, model.Step{
 					Iteration:   step,
 					Thought:     decision.Thought,
-					Action:      &agentName,
+					Action:      &action,
 					Observation: &errorMsg,
 				})
 				continue
 			}
 
-			// Build context from previous agent results
-			var contextData json.RawMessage
-			if len(agentResultsContext) > 0 {
-				contextData, _ = json.Marshal(agentResultsContext)
-			}
-
-			// Propagate verbose setting to agent
-			selectedAgent.Verbose(s.verbose)
-
-			agentResponse := selectedAgent.ExecuteWithContext(ctx, agentTask, contextData, s.config.MaxIterations)
-
-			var resultSummary string
-			switch agentResponse.Type {
-			case agent.ResponseSuccess:
-				// Aggregate agent token usage and LLM calls
-				if agentResponse.Metadata.TokenUsage != nil {
-					tokenStats.AddUsage(agentResponse.Metadata.TokenUsage)
-				}
-				tokenStats.LLMCalls += agentResponse.Metadata.LLMCalls
-
-				// Process result - store in ResultStore if large
-				processedResult := s.processAgentResult(ctx, agentName, subGoalID, agentResponse.Result, tokenStats)
-				progress.markCompleted(subGoalID, processedResult)
-
-				// Store agent execution result
-				preview := processedResult
-				if len(preview) > 200 {
-					preview = preview[:200] + "..."
-				}
-				s.storeOrchestrationMemory(ctx, fmt.Sprintf(
-					"Agent '%s' completed sub-goal '%s': %s",
-					agentName, subGoalID, preview,
-				), &agentName)
-
-				// Add to context for future agents
-				var resultValue interface{}
-				if err := json.Unmarshal([]byte(processedResult), &resultValue); err != nil {
-					resultValue = processedResult
-				}
-				agentResultsContext[agentName+"_output"] = resultValue
-
-				resultSummary = fmt.Sprintf("SUCCESS: %s", processedResult)
-
-			case agent.ResponseFailure:
-				// Still count LLM calls from failed agents
-				tokenStats.LLMCalls += agentResponse.Metadata.LLMCalls
-				if agentResponse.Metadata.TokenUsage != nil {
-					tokenStats.AddUsage(agentResponse.Metadata.TokenUsage)
-				}
-				progress.markFailed(subGoalID, agentResponse.Error)
-				resultSummary = fmt.Sprintf("FAILED: %s", agentResponse.Error)
-
-			case agent.ResponseTimeout:
-				// Still count LLM calls from timed-out agents
-				tokenStats.LLMCalls += agentResponse.Metadata.LLMCalls
-				if agentResponse.Metadata.TokenUsage != nil {
-					tokenStats.AddUsage(agentResponse.Metadata.TokenUsage)
-				}
-				progress.markFailed(subGoalID, agentResponse.PartialResult)
-				resultSummary = fmt.Sprintf("TIMEOUT: %s", agentResponse.PartialResult)
-			}
+			progress.markInProgress(assignment.SubGoalID, agentName)
+			assignment.goal = progress.goalsByID[assignment.SubGoalID].Description
+			batch = append(batch, assignment)
+		}
 
-			// Update conversation
-			assistantJSON, err := json.Marshal(supervisorDecision{
-				Thought:       decision.Thought,
-				AgentToInvoke: &agentName,
-				AgentTask:     &agentTask,
-				SubGoalID:     &subGoalID,
-				IsFinal:       false,
-			})
-			if err != nil {
-				assistantJSON = []byte(fmt.Sprintf(`{"thought": %q}`, decision.Thought))
-			}
+		if len(batch) == 0 {
 			conversation = append(conversation, llm.ChatMessage{
-				Role:    "assistant",
-				Content: string(assistantJSON),
+				Role:    "user",
+				Content: strings.Join(reports, "\n"),
 			})
+			continue
+		}
 
-			urgencyMsg := fmt.Sprintf("\n\nYou have %d orchestration steps remaining.", remainingSteps-1)
-			if remainingSteps-1 <= 2 {
-				urgencyMsg = fmt.Sprintf("\n\nWARNING: Only %d orchestration steps remaining!", remainingSteps-1)
-			}
+		// Build context from previous agent results
+		var contextData json.RawMessage
+		if len(agentResultsContext) > 0 {
+			contextData, _ = json.Marshal(agentResultsContext)
+		}
 
-			conversation = append(conversation, llm.ChatMessage{
-				Role: "user",
-				Content: fmt.Sprintf(
-					"Agent '%s' completed the task.\nResult: %s%s\n%s\n\nIf all sub-goals are complete, set is_final=true and provide the final_answer.",
-					agentName, resultSummary, urgencyMsg, progress.detailedStatus(),
-				),
-			})
+		outcomes := s.runAssignments(ctx, batch, contextData)
+
+		for i, assignment := range batch {
+			resultSummary := s.recordOutcome(ctx, assignment, outcomes[i], tokenStats, progress, agentResultsContext)
+			reports = append(reports, fmt.Sprintf("Agent '%s' completed the task.\nResult: %s", assignment.Agent, resultSummary))
 
-			action := fmt.Sprintf("%s:%s", agentName, agentTask)
+			action := fmt.Sprintf("%s:%s", assignment.Agent, assignment.Task)
 			allSteps = append(allSteps, model.Step{
 				Iteration:   step,
 				Thought:     decision.Thought,
 				Action:      &action,
 				Observation: &resultSummary,
 			})
-		} else {
-			// No agent invoked
-			warning := "Supervisor must either invoke an agent or mark task as final"
-			conversation = append(conversation, llm.ChatMessage{
-				Role:    "user",
-				Content: fmt.Sprintf("%s\nPlease either invoke an agent or set is_final=true", warning),
-			})
-			allSteps = append(allSteps, model.Step{
-				Iteration:   step,
-				Thought:     decision.Thought,
-				Observation: &warning,
-			})
 		}
+
+		// Update conversation
+		ran := supervisorDecision{
+			Thought:       decision.Thought,
+			AgentToInvoke: &batch[0].Agent,
+			AgentTask:     &batch[0].Task,
+			SubGoalID:     &batch[0].SubGoalID,
+			IsFinal:       false,
+			ParallelTasks: batch[1:],
+		}
+		assistantJSON, err := json.Marshal(ran)
+		if err != nil {
+			assistantJSON = []byte(fmt.Sprintf(`{"thought": %q}`, decision.Thought))
+		}
+		conversation = append(conversation, llm.ChatMessage{
+			Role:    "assistant",
+			Content: string(assistantJSON),
+		})
+
+		urgencyMsg := fmt.Sprintf("\n\nYou have %d orchestration steps remaining.", remainingSteps-1)
+		if remainingSteps-1 <= 2 {
+			urgencyMsg = fmt.Sprintf("\n\nWARNING: Only %d orchestration steps remaining!", remainingSteps-1)
+		}
+
+		conversation = append(conversation, llm.ChatMessage{
+			Role: "user",
+			Content: fmt.Sprintf(
+				"%s%s\n%s\n\nIf all sub-goals are complete, set is_final=true and provide the final_answer.",
+				strings.Join(reports, "\n\n"), urgencyMsg, progress.detailedStatus(),
+			),
+		})
 	}
 
 	// Max orchestration steps reached
@@ -535,56 +605,311 @@ Respond with valid JSON only. No extra text.%s`,
 		progress.progressSummary(),
 	)
 
-	return NewTimeoutResponse(
+	resp := NewTimeoutResponse(
 		partialResult,
 		allSteps,
-		buildMetadata(tokenStats),
+		buildMetadata(tokenStats, progress),
 		&CompletionStatus{
 			Type:      StatusPartial,
-			NextSteps: []string{"Increase max_orchestration_steps"},
+			NextSteps: s.suggestNextSteps(ctx, task, progress, allSteps, tokenStats),
 		},
 	)
+	resp.Err = agent.ErrMaxIterations
+	return resp
 }
 
-// decideNextAction asks the supervisor LLM to decide the next action.
-// Uses streaming when verbose mode is enabled to show tokens in real-time.
-func (s *Supervisor) decideNextAction(ctx context.Context, conversation []llm.ChatMessage, tokenStats *TokenStats) (supervisorDecision, error) {
-	var response string
-	var err error
-	var usage *llm.TokenUsage
+// assignments lists the agent invocations in a decision. Sub-goals
+// without an ID are named after the step.
+func (s *Supervisor) assignments(d supervisorDecision, step int) []agentAssignment {
+	var assignments []agentAssignment
+	if d.AgentToInvoke != nil && d.AgentTask != nil {
+		subGoalID := fmt.Sprintf("goal_%d", step)
+		if d.SubGoalID != nil {
+			subGoalID = *d.SubGoalID
+		}
+		assignments = append(assignments, agentAssignment{Agent: *d.AgentToInvoke, Task: *d.AgentTask, SubGoalID: subGoalID})
+	}
+	if s.config.ParallelSubGoals {
+		for i, task := range d.ParallelTasks {
+			if task.SubGoalID == "" {
+				task.SubGoalID = fmt.Sprintf("goal_%d_%d", step, i+1)
+			}
+			assignments = append(assignments, task)
+		}
+	}
+	return assignments
+}
 
-	if s.verbose {
-		response, usage, err = s.decideWithStreaming(ctx, conversation)
-	} else {
-		response, usage, err = s.llmClient.ChatWithUsage(ctx, conversation)
+// runAssignments runs a batch of agent invocations, concurrently when
+// parallel sub-goals are enabled. Tasks for the same agent always run one
+// after another, since an agent is not safe for concurrent use.
+func (s *Supervisor) runAssignments(ctx context.Context, batch []agentAssignment, contextData json.RawMessage) []agentOutcome {
+	outcomes := make([]agentOutcome, len(batch))
+	run := func(i int) {
+		outcomes[i] = s.runVerified(ctx, batch[i], contextData)
 	}
 
-	if err != nil {
-		return supervisorDecision{}, fmt.Errorf("LLM chat failed: %w", err)
+	if !s.config.ParallelSubGoals || len(batch) == 1 {
+		for i := range batch {
+			run(i)
+		}
+		return outcomes
 	}
 
-	// Track token usage
-	tokenStats.LLMCalls++
-	tokenStats.AddUsage(usage)
+	byAgent := make(map[string][]int)
+	for i, assignment := range batch {
+		byAgent[assignment.Agent] = append(byAgent[assignment.Agent], i)
+	}
+	var wg sync.WaitGroup
+	for _, indexes := range byAgent {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			for _, i := range indexes {
+				run(i)
+			}
+		}(indexes)
+	}
+	wg.Wait()
+	return outcomes
+}
 
-	extracted, err := jsonutil.ExtractJSON(response)
-	if err != nil {
-		// Could not extract JSON - treat as a thought without action
-		return supervisorDecision{
-			Thought: response,
-			IsFinal: false,
-		}, nil
+// recordOutcome folds one assignment's outcome into the orchestration
+// state and returns the summary reported back to the supervisor.
+func (s *Supervisor) recordOutcome(ctx context.Context, assignment agentAssignment, outcome agentOutcome, tokenStats *TokenStats, progress *taskProgress, agentResultsContext map[string]interface{}) string {
+	agentName, subGoalID := assignment.Agent, assignment.SubGoalID
+	agentResponse := outcome.response
+	tokenStats.AddUsage(&outcome.usage)
+	tokenStats.Verifications += outcome.checks
+	tokenStats.VerifyRejections += outcome.rejections
+
+	var resultSummary string
+	switch {
+	case agentResponse.Type == agent.ResponseSuccess && outcome.rejected != "":
+		// Still rejected after retries: the supervisor decides what next
+		tokenStats.AddUsage(agentResponse.Metadata.TokenUsage)
+		tokenStats.LLMCalls += agentResponse.Metadata.LLMCalls
+		tokenStats.addDecisionStats(agentResponse.Metadata)
+		preview := text.Truncate(agentResponse.Result, 500)
+		resultSummary = fmt.Sprintf("VERIFICATION FAILED after %d attempts: %s\nLast result: %s", outcome.attempts, outcome.rejected, preview)
+		progress.markFailed(subGoalID, resultSummary)
+
+	case agentResponse.Type == agent.ResponseSuccess:
+		// Aggregate agent token usage and LLM calls
+		if agentResponse.Metadata.TokenUsage != nil {
+			tokenStats.AddUsage(agentResponse.Metadata.TokenUsage)
+		}
+		tokenStats.LLMCalls += agentResponse.Metadata.LLMCalls
+		tokenStats.addDecisionStats(agentResponse.Metadata)
+
+		// Process result - store in ResultStore if large
+		processedResult := s.processAgentResult(ctx, agentName, subGoalID, agentResponse.Result, tokenStats)
+		progress.markCompleted(subGoalID, processedResult)
+
+		// Store agent execution result
+		preview := text.Truncate(processedResult, 200)
+		s.storeOrchestrationMemory(ctx, fmt.Sprintf(
+			"Agent '%s' completed sub-goal '%s': %s",
+			agentName, subGoalID, preview,
+		), &agentName)
+
+		// Add to context for future agents
+		var resultValue interface{}
+		if err := json.Unmarshal([]byte(processedResult), &resultValue); err != nil {
+			resultValue = processedResult
+		}
+		agentResultsContext[agentName+"_output"] = resultValue
+
+		resultSummary = fmt.Sprintf("SUCCESS: %s", processedResult)
+
+	case agentResponse.Type == agent.ResponseFailure:
+		// Still count LLM calls from failed agents
+		tokenStats.LLMCalls += agentResponse.Metadata.LLMCalls
+		tokenStats.addDecisionStats(agentResponse.Metadata)
+		if agentResponse.Metadata.TokenUsage != nil {
+			tokenStats.AddUsage(agentResponse.Metadata.TokenUsage)
+		}
+		progress.markFailed(subGoalID, agentResponse.Error)
+		resultSummary = fmt.Sprintf("FAILED: %s", agentResponse.Error)
+		if errors.Is(agentResponse.Err, agent.ErrBudgetExceeded) {
+			resultSummary = budgetOverrunSummary(agentResponse)
+		}
+
+	case agentResponse.Type == agent.ResponseTimeout:
+		// Still count LLM calls from timed-out agents
+		tokenStats.LLMCalls += agentResponse.Metadata.LLMCalls
+		tokenStats.addDecisionStats(agentResponse.Metadata)
+		if agentResponse.Metadata.TokenUsage != nil {
+			tokenStats.AddUsage(agentResponse.Metadata.TokenUsage)
+		}
+		progress.markFailed(subGoalID, agentResponse.PartialResult)
+		resultSummary = fmt.Sprintf("TIMEOUT: %s", agentResponse.PartialResult)
+	}
+	return resultSummary
+}
+
+// budgetOverrunSummary reports an agent stopped for going over its budget,
+// with whatever it had produced by then.
+func budgetOverrunSummary(resp agent.Response) string {
+	summary := fmt.Sprintf("BUDGET EXCEEDED: %s (after %d steps", resp.Error, len(resp.Steps))
+	if resp.Metadata.TokenUsage != nil {
+		summary += fmt.Sprintf(", %d tokens", resp.Metadata.TokenUsage.TotalTokens)
+	}
+	summary += ")"
+	if resp.PartialResult != "" {
+		partial := text.Truncate(resp.PartialResult, 500)
+		summary += "\nPartial result: " + partial
 	}
+	return summary
+}
+
+// decideNextAction asks the supervisor LLM to decide the next action.
+// Models that enforce JSON schemas get the decision schema; otherwise
+// streaming is used when verbose mode is enabled and the model supports it,
+// to show tokens in real-time.
+// Invalid replies get up to maxDecisionRepairs correction requests before
+// being treated as a thought without action.
+func (s *Supervisor) decideNextAction(ctx context.Context, conversation []llm.ChatMessage, tokenStats *TokenStats) (supervisorDecision, error) {
+	attempt := conversation
+	var lastResponse string
+
+	for repair := 0; repair <= maxDecisionRepairs; repair++ {
+		var response string
+		var err error
+		var usage *llm.TokenUsage
+
+		switch caps := s.llmClient.Capabilities(); {
+		case caps.JSONSchema:
+			// Schema-constrained output can't stream, so print it whole
+			response, usage, err = s.llmClient.ChatWithFormatAndUsage(ctx, attempt, s.decisionFormat())
+			if err == nil && s.verbose {
+				fmt.Printf("\n[Supervisor] %s\n\n", response)
+			}
+		case s.verbose && caps.Streaming:
+			response, usage, err = s.decideWithStreaming(ctx, attempt)
+		default:
+			response, usage, err = s.llmClient.ChatWithUsage(ctx, attempt)
+			if err == nil && s.verbose {
+				fmt.Printf("\n[Supervisor] %s\n\n", response)
+			}
+		}
 
-	var decision supervisorDecision
-	if err := json.Unmarshal([]byte(extracted), &decision); err != nil {
-		return supervisorDecision{
-			Thought: response,
-			IsFinal: false,
-		}, nil
+		if err != nil {
+			return supervisorDecision{}, agent.LLMError(err)
+		}
+
+		// Track token usage
+		tokenStats.LLMCalls++
+		tokenStats.AddUsage(usage)
+		lastResponse = response
+
+		decision, err := jsonutil.DecodeValidated(response, validateSupervisorDecision)
+		if err == nil {
+			return decision, nil
+		}
+		if repair == maxDecisionRepairs {
+			break
+		}
+
+		tokenStats.DecisionRepairs++
+		if s.verbose {
+			fmt.Printf("[Supervisor] Invalid decision (%v), asking for a corrected reply\n", err)
+		}
+		attempt = append(attempt[:len(attempt):len(attempt)],
+			llm.ChatMessage{Role: "assistant", Content: response},
+			llm.ChatMessage{Role: "user", Content: jsonutil.RepairPrompt(err)},
+		)
 	}
 
-	return decision, nil
+	// Could not get a usable decision - treat as a thought without action
+	tokenStats.DecisionFallbacks++
+	return supervisorDecision{
+		Thought: lastResponse,
+		IsFinal: false,
+	}, nil
+}
+
+// maxDecisionRepairs bounds the correction requests sent for one decision.
+const maxDecisionRepairs = 2
+
+// decisionFormat returns the supervisorDecision schema. Strict mode needs
+// every field required, so optional ones are nullable; agent_to_invoke is
+// limited to the registered agents.
+func (s *Supervisor) decisionFormat() *llm.ResponseFormat {
+	names := s.AgentNames()
+	sort.Strings(names)
+	agentEnum := make([]any, 0, len(names)+1)
+	for _, name := range names {
+		agentEnum = append(agentEnum, name)
+	}
+	agentEnum = append(agentEnum, nil)
+
+	nullableString := map[string]any{"type": []string{"string", "null"}}
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"thought": map[string]any{"type": "string"},
+			"sub_goals": map[string]any{
+				"type": []string{"array", "null"},
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id":          map[string]any{"type": "string"},
+						"description": map[string]any{"type": "string"},
+						"priority":    map[string]any{"type": []string{"integer", "null"}},
+						"depends_on":  map[string]any{"type": []string{"array", "null"}, "items": map[string]any{"type": "string"}},
+					},
+					"required":             []string{"id", "description", "priority", "depends_on"},
+					"additionalProperties": false,
+				},
+			},
+			"agent_to_invoke": map[string]any{"type": []string{"string", "null"}, "enum": agentEnum},
+			"agent_task":      nullableString,
+			"sub_goal_id":     nullableString,
+			"is_final":        map[string]any{"type": "boolean"},
+			"final_answer":    nullableString,
+		},
+		"required":             []string{"thought", "sub_goals", "agent_to_invoke", "agent_task", "sub_goal_id", "is_final", "final_answer"},
+		"additionalProperties": false,
+	}
+	if s.config.ParallelSubGoals {
+		schema["properties"].(map[string]any)["parallel_tasks"] = map[string]any{
+			"type": []string{"array", "null"},
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"agent_to_invoke": map[string]any{"type": "string", "enum": names},
+					"agent_task":      map[string]any{"type": "string"},
+					"sub_goal_id":     nullableString,
+				},
+				"required":             []string{"agent_to_invoke", "agent_task", "sub_goal_id"},
+				"additionalProperties": false,
+			},
+		}
+		schema["required"] = append(schema["required"].([]string), "parallel_tasks")
+	}
+	data, _ := json.Marshal(schema) // Plain maps and slices always marshal
+	return llm.NewJSONSchemaFormat("supervisor_decision", data)
+}
+
+// validateSupervisorDecision checks that a decision either finishes,
+// invokes an agent, or declares sub-goals.
+func validateSupervisorDecision(d supervisorDecision) error {
+	if d.IsFinal {
+		return nil
+	}
+	for _, task := range d.ParallelTasks {
+		if task.Agent == "" || task.Task == "" {
+			return fmt.Errorf("each parallel_tasks entry needs agent_to_invoke and agent_task")
+		}
+	}
+	switch {
+	case d.AgentToInvoke != nil && (d.AgentTask == nil || *d.AgentTask == ""):
+		return fmt.Errorf("agent_task is required when agent_to_invoke is set")
+	case d.AgentToInvoke == nil && len(d.SubGoals) == 0 && len(d.ParallelTasks) == 0:
+		return fmt.Errorf("either agent_to_invoke with agent_task, sub_goals, or is_final=true is required")
+	}
+	return nil
 }
 
 // streamResult holds the result of a streaming call.
@@ -654,10 +979,14 @@ func (s *Supervisor) loadPriorContext(ctx context.Context) string {
 	}
 
 	memType := storage.MemoryOrchestration
-	memories, err := s.storage.QueryMemories(ctx, s.sessionID, &memType, 5)
+	memories, err := s.storage.QueryMemories(ctx, s.sessionID, &memType, storage.MemoryRankWindow)
 	if err != nil || len(memories) == 0 {
 		return ""
 	}
+	storage.RankMemories(memories, time.Now(), storage.DefaultMemoryHalfLife)
+	if len(memories) > 5 {
+		memories = memories[:5]
+	}
 
 	lines := make([]string, 0, len(memories))
 	for _, m := range memories {
@@ -676,10 +1005,31 @@ func (s *Supervisor) AgentNames() []string {
 	return names
 }
 
-// buildMetadata creates metadata with token stats.
-func buildMetadata(stats *TokenStats) *Metadata {
+// cancelledResponse reports a cancelled or timed-out orchestration,
+// keeping the sub-goal results completed so far.
+func cancelledResponse(ctx context.Context, steps []Step, tokenStats *TokenStats, progress *taskProgress) Response {
+	metadata := buildMetadata(tokenStats, progress)
+	metadata.PartialResults = progress.completedResults()
+	resp := NewErrorResponse(
+		agent.CancelledError(ctx.Err()),
+		steps,
+		metadata,
+		&CompletionStatus{
+			Type:        StatusFailed,
+			Error:       ctx.Err().Error(),
+			Recoverable: true,
+		},
+	)
+	resp.PartialResult = progress.progressSummary()
+	return resp
+}
+
+// buildMetadata creates metadata with token stats and, if progress is
+// set, the plan so far.
+func buildMetadata(stats *TokenStats, progress *taskProgress) *Metadata {
 	return &Metadata{
 		TokenStats: stats,
+		Plan:       progress.plan(),
 	}
 }
 
@@ -703,7 +1053,7 @@ func (s *Supervisor) processAgentResult(ctx context.Context, agentName, subGoalI
 
 	// Store large result
 	key := storage.ResultKey{
-		SessionID: s.sessionID,
+		SessionID: s.resultSessionID(),
 		Key:       fmt.Sprintf("%s/%s", agentName, subGoalID),
 	}
 
@@ -751,5 +1101,5 @@ func (s *Supervisor) truncateResult(result string) string {
 	// Show first and last portions
 	halfSize := threshold / 2
 	return fmt.Sprintf("%s\n\n... [%d bytes truncated] ...\n\n%s",
-		result[:halfSize], len(result)-threshold, result[len(result)-halfSize:])
+		text.Head(result, halfSize), len(result)-threshold, text.Tail(result, halfSize))
 }
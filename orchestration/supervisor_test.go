@@ -0,0 +1,182 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+)
+
+func TestSupervisorRepairsMalformedDecisions(t *testing.T) {
+	worker := newScriptedAgent("worker",
+		`{"thought": "let me think"}`,
+		`{"thought": "done", "is_final": true, "final_answer": "42"}`,
+	)
+	provider := &scriptedProvider{responses: []string{
+		"I'll ask the worker.",
+		`{"thought": "delegate", "agent_to_invoke": "worker", "agent_task": "find the answer", "is_final": false}`,
+		`{"thought": "done", "is_final": true, "final_answer": "the answer is 42"}`,
+	}}
+	supervisor := NewSupervisor([]*agent.Agent{worker}, llm.NewClient(provider), DefaultSupervisorConfig())
+
+	resp := supervisor.Orchestrate(context.Background(), "what is the answer?", 5)
+	if resp.Type != ResponseSuccess || resp.Result != "the answer is 42" {
+		t.Fatalf("expected success, got %+v", resp)
+	}
+	stats := resp.Metadata.TokenStats
+	if stats.DecisionRepairs != 2 || stats.DecisionFallbacks != 0 {
+		t.Errorf("expected 2 repairs (supervisor and agent) and no fallbacks, got %d, %d", stats.DecisionRepairs, stats.DecisionFallbacks)
+	}
+	if stats.LLMCalls != 5 {
+		t.Errorf("repair calls should be counted: got %d LLM calls, want 5", stats.LLMCalls)
+	}
+}
+
+func TestSupervisorDecisionFallsBackAfterRepairs(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{`{"thought": "pondering"}`}}
+	supervisor := NewSupervisor(nil, llm.NewClient(provider), DefaultSupervisorConfig())
+
+	resp := supervisor.Orchestrate(context.Background(), "anything", 1)
+	if resp.Type != ResponseTimeout {
+		t.Fatalf("expected timeout, got %+v", resp)
+	}
+	stats := resp.Metadata.TokenStats
+	if stats.DecisionRepairs != maxDecisionRepairs || stats.DecisionFallbacks != 1 {
+		t.Errorf("expected %d repairs and 1 fallback, got %d, %d", maxDecisionRepairs, stats.DecisionRepairs, stats.DecisionFallbacks)
+	}
+	// The decision and its repairs, then the next-step suggestions
+	if provider.calls != maxDecisionRepairs+2 {
+		t.Errorf("expected %d LLM calls, got %d", maxDecisionRepairs+2, provider.calls)
+	}
+}
+
+// schemaProvider is a scriptedProvider that enforces JSON schemas and
+// records the format of each request.
+type schemaProvider struct {
+	scriptedProvider
+	mu      sync.Mutex
+	formats []*llm.ResponseFormat
+}
+
+func (p *schemaProvider) SupportsJSONSchema() bool { return true }
+
+func (p *schemaProvider) ChatWithFormat(ctx context.Context, messages []llm.ChatMessage, format *llm.ResponseFormat) (llm.LLMResponse, error) {
+	p.mu.Lock()
+	p.formats = append(p.formats, format)
+	p.mu.Unlock()
+	return p.Chat(ctx, messages)
+}
+
+func TestSupervisorPassesDecisionSchemas(t *testing.T) {
+	workerLLM := &schemaProvider{scriptedProvider: scriptedProvider{responses: []string{
+		`{"thought": "done", "is_final": true, "final_answer": "42"}`,
+	}}}
+	worker := agent.New(agent.NewBuilder("worker").Build(), workerLLM)
+	supervisorLLM := &schemaProvider{scriptedProvider: scriptedProvider{responses: []string{
+		`{"thought": "delegate", "sub_goals": null, "agent_to_invoke": "worker", "agent_task": "find it", "sub_goal_id": null, "is_final": false, "final_answer": null}`,
+		`{"thought": "done", "sub_goals": null, "agent_to_invoke": null, "agent_task": null, "sub_goal_id": null, "is_final": true, "final_answer": "42"}`,
+	}}}
+	supervisor := NewSupervisor([]*agent.Agent{worker}, llm.NewClient(supervisorLLM), DefaultSupervisorConfig())
+
+	if resp := supervisor.Orchestrate(context.Background(), "what is the answer?", 5); resp.Type != ResponseSuccess {
+		t.Fatalf("expected success, got %+v", resp)
+	}
+
+	if len(supervisorLLM.formats) != 2 || supervisorLLM.formats[0] == nil {
+		t.Fatalf("expected a schema on every supervisor call, got %v", supervisorLLM.formats)
+	}
+	schema := supervisorLLM.formats[0].JSONSchema
+	if schema.Name != "supervisor_decision" || !schema.Strict {
+		t.Errorf("unexpected supervisor schema: %s strict=%v", schema.Name, schema.Strict)
+	}
+	var parsed struct {
+		Properties struct {
+			AgentToInvoke struct {
+				Enum []*string `json:"enum"`
+			} `json:"agent_to_invoke"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(schema.Schema, &parsed); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	if enum := parsed.Properties.AgentToInvoke.Enum; len(enum) != 2 || enum[0] == nil || *enum[0] != "worker" || enum[1] != nil {
+		t.Errorf("agent_to_invoke should allow only registered agents or null, got %s", schema.Schema)
+	}
+
+	if len(workerLLM.formats) != 1 || workerLLM.formats[0].JSONSchema.Name != "agent_decision" {
+		t.Fatalf("expected the agent decision schema, got %v", workerLLM.formats)
+	}
+	if !strings.Contains(string(workerLLM.formats[0].JSONSchema.Schema), `"action"`) {
+		t.Errorf("agent schema lacks action: %s", workerLLM.formats[0].JSONSchema.Schema)
+	}
+}
+
+// meteredProvider is a scriptedProvider that reports fixed token usage.
+type meteredProvider struct {
+	scriptedProvider
+	tokens uint32
+}
+
+func (p *meteredProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	resp, err := p.scriptedProvider.Chat(ctx, messages)
+	resp.Usage = &llm.TokenUsage{TotalTokens: p.tokens}
+	return resp, err
+}
+
+func TestSupervisorEnforcesAgentBudgets(t *testing.T) {
+	workerLLM := &meteredProvider{tokens: 100, scriptedProvider: scriptedProvider{responses: []string{
+		`{"thought": "digging", "action": {"tool": "dig", "input": {}}, "is_final": false}`,
+	}}}
+	worker := agent.New(agent.NewBuilder("worker").Build(), workerLLM)
+	provider := &scriptedProvider{responses: []string{
+		`{"thought": "delegate", "agent_to_invoke": "worker", "agent_task": "dig forever", "is_final": false}`,
+		`{"thought": "give up", "is_final": true, "final_answer": "stopped"}`,
+	}}
+	config := DefaultSupervisorConfig()
+	config.AgentBudget = agent.Budget{MaxTokens: 1000}
+	config.AgentBudgets = map[string]agent.Budget{"worker": {MaxTokens: 150}}
+	supervisor := NewSupervisor([]*agent.Agent{worker}, llm.NewClient(provider), config)
+
+	resp := supervisor.Orchestrate(context.Background(), "dig", 5)
+	if resp.Type != ResponseSuccess {
+		t.Fatalf("expected the supervisor to carry on, got %+v", resp)
+	}
+	if workerLLM.calls != 2 {
+		t.Errorf("worker should stop once over budget: %d calls", workerLLM.calls)
+	}
+	if len(resp.Steps) == 0 || resp.Steps[0].Observation == nil || !strings.HasPrefix(*resp.Steps[0].Observation, "BUDGET EXCEEDED") {
+		t.Fatalf("overrun not recorded in steps: %+v", resp.Steps)
+	}
+	if !strings.Contains(*resp.Steps[0].Observation, "200 tokens") {
+		t.Errorf("overrun should report usage: %s", *resp.Steps[0].Observation)
+	}
+	if resp.Metadata.TokenStats.TotalTokens != 200 {
+		t.Errorf("worker tokens should still be counted, got %d", resp.Metadata.TokenStats.TotalTokens)
+	}
+}
+
+func TestSupervisorReportsSteps(t *testing.T) {
+	worker := newScriptedAgent("worker", `{"thought": "done", "is_final": true, "final_answer": "42"}`)
+	provider := &scriptedProvider{responses: []string{
+		`{"thought": "delegate", "agent_to_invoke": "worker", "agent_task": "find the answer", "is_final": false}`,
+		`{"thought": "done", "is_final": true, "final_answer": "42"}`,
+	}}
+	var steps []int
+	var calls []int
+	supervisor := NewSupervisor([]*agent.Agent{worker}, llm.NewClient(provider), DefaultSupervisorConfig()).
+		WithStepObserver(func(step int, stats TokenStats) {
+			steps = append(steps, step)
+			calls = append(calls, stats.LLMCalls)
+		})
+
+	if resp := supervisor.Orchestrate(context.Background(), "what is the answer?", 5); resp.Type != ResponseSuccess {
+		t.Fatalf("expected success, got %+v", resp)
+	}
+	if len(steps) != 1 || steps[0] != 1 || calls[0] != 2 {
+		t.Errorf("expected one report after step 1 with 2 LLM calls, got steps %v, calls %v", steps, calls)
+	}
+}
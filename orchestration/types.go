@@ -4,6 +4,9 @@
 package orchestration
 
 import (
+	"errors"
+
+	"github.com/richinex/ariadne/agent"
 	"github.com/richinex/ariadne/model"
 	"github.com/richinex/ariadne/llm"
 )
@@ -139,9 +142,24 @@ type TokenStats struct {
 	CompletionTokens uint32 `json:"completion_tokens"`
 	TotalTokens      uint32 `json:"total_tokens"`
 	LLMCalls         int    `json:"llm_calls"`
+	// Prompt tokens served from the provider's prompt cache
+	CachedPromptTokens uint32 `json:"cached_prompt_tokens,omitempty"`
 	// Context savings from ResultStore
 	BytesSaved    int `json:"bytes_saved,omitempty"`
 	ResultsStored int `json:"results_stored,omitempty"`
+	// Malformed decisions: correction requests sent, and decisions that
+	// stayed invalid and were treated as thoughts
+	DecisionRepairs   int `json:"decision_repairs,omitempty"`
+	DecisionFallbacks int `json:"decision_fallbacks,omitempty"`
+	// Sub-goal verification: checks run and results rejected
+	Verifications    int `json:"verifications,omitempty"`
+	VerifyRejections int `json:"verify_rejections,omitempty"`
+}
+
+// addDecisionStats adds an agent's decision repair counts.
+func (ts *TokenStats) addDecisionStats(meta agent.Metadata) {
+	ts.DecisionRepairs += meta.DecisionRepairs
+	ts.DecisionFallbacks += meta.DecisionFallbacks
 }
 
 // AddUsage adds token usage from an LLM call.
@@ -152,18 +170,24 @@ func (ts *TokenStats) AddUsage(usage *llm.TokenUsage) {
 	ts.PromptTokens += usage.PromptTokens
 	ts.CompletionTokens += usage.CompletionTokens
 	ts.TotalTokens += usage.TotalTokens
+	ts.CachedPromptTokens += usage.CachedPromptTokens
 }
 
 // Metadata contains metadata about orchestration execution.
 type Metadata struct {
-	ExecutionTimeMs  uint64            `json:"execution_time_ms"`
-	TokensUsed       *uint32           `json:"tokens_used,omitempty"` // Deprecated: use TokenStats
-	TokenStats       *TokenStats       `json:"token_stats,omitempty"`
-	PartialResults   map[string]string `json:"partial_results"`
-	SchemaVersion    *string           `json:"schema_version,omitempty"`
-	ValidationResult *ValidationResult `json:"validation_result,omitempty"`
-	AgentName        *string           `json:"agent_name,omitempty"`
-	ToolCalls        []ToolCallInfo    `json:"tool_calls"`
+	ExecutionTimeMs  uint64             `json:"execution_time_ms"`
+	TokensUsed       *uint32            `json:"tokens_used,omitempty"` // Deprecated: use TokenStats
+	TokenStats       *TokenStats        `json:"token_stats,omitempty"`
+	PartialResults   map[string]string  `json:"partial_results"`
+	SchemaVersion    *string            `json:"schema_version,omitempty"`
+	ValidationResult *ValidationResult  `json:"validation_result,omitempty"`
+	AgentName        *string            `json:"agent_name,omitempty"`
+	ToolCalls        []ToolCallInfo     `json:"tool_calls"`
+	Ensemble         *EnsembleStats     `json:"ensemble,omitempty"`
+	Environment      *model.Environment `json:"environment,omitempty"`
+	MaskedPII        map[string]int     `json:"masked_pii,omitempty"` // By kind; see tools.PIIScanner
+	Plan             []PlanItem         `json:"plan,omitempty"`       // Supervisor sub-goals
+	AgentMessages    []AgentMessage     `json:"agent_messages,omitempty"`
 }
 
 // ResponseType indicates the type of orchestration response.
@@ -181,7 +205,8 @@ type Response struct {
 	Type             ResponseType
 	Result           string // For Success
 	Error            string // For Failure
-	PartialResult    string // For Timeout
+	PartialResult    string // For Timeout (and progress when cancelled)
+	Err              error  // Failure class (agent.ErrLLM, agent.ErrCancelled, ...)
 	Steps            []Step
 	Metadata         *Metadata
 	CompletionStatus *CompletionStatus
@@ -209,6 +234,13 @@ func NewFailureResponse(err string, steps []Step, metadata *Metadata, status *Co
 	}
 }
 
+// NewErrorResponse creates a failure orchestration response from a typed error.
+func NewErrorResponse(err error, steps []Step, metadata *Metadata, status *CompletionStatus) Response {
+	resp := NewFailureResponse(err.Error(), steps, metadata, status)
+	resp.Err = err
+	return resp
+}
+
 // NewTimeoutResponse creates a timeout orchestration response.
 func NewTimeoutResponse(partialResult string, steps []Step, metadata *Metadata, status *CompletionStatus) Response {
 	return Response{
@@ -219,3 +251,18 @@ func NewTimeoutResponse(partialResult string, steps []Step, metadata *Metadata,
 		CompletionStatus: status,
 	}
 }
+
+// AsError returns nil for success, otherwise the response's typed error.
+// Failures built without one fall back to an error with the Error text.
+func (r Response) AsError() error {
+	switch {
+	case r.Type == ResponseSuccess:
+		return nil
+	case r.Err != nil:
+		return r.Err
+	case r.Type == ResponseFailure:
+		return errors.New(r.Error)
+	default:
+		return agent.ErrMaxIterations
+	}
+}
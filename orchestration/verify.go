@@ -0,0 +1,234 @@
+// Sub-goal outcome verification.
+//
+// A Verifier checks each successful agent result against the sub-goal it
+// was meant to accomplish before the supervisor marks it completed. A
+// rejected result is sent back to the same agent with the verifier's
+// feedback, up to a retry limit; if it is still rejected the sub-goal
+// fails and the supervisor sees why.
+//
+// Information Hiding:
+// - Retry-with-feedback loop and usage merging hidden
+// - LLM verifier prompt and reply parsing hidden
+
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/richinex/ariadne/agent"
+	jsonutil "github.com/richinex/ariadne/internal/json"
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+)
+
+// DefaultVerifyRetries is how many times a rejected result is retried.
+const DefaultVerifyRetries = 1
+
+// verifyResultLimit caps how much of a result is shown to an LLM verifier.
+const verifyResultLimit = 8000
+
+// SubGoalCheck is what a Verifier judges.
+type SubGoalCheck struct {
+	SubGoalID   string
+	Description string // Sub-goal description
+	Agent       string
+	Task        string // Task given to the agent
+	Result      string
+}
+
+// Verdict is a Verifier's judgement. Feedback says what is wrong when
+// Passed is false.
+type Verdict struct {
+	Passed   bool
+	Feedback string
+	Usage    *llm.TokenUsage // LLM cost of the check, if any
+}
+
+// Verifier checks an agent result against its sub-goal. An error means
+// the check itself failed; the result is then accepted unverified.
+type Verifier interface {
+	Verify(ctx context.Context, check SubGoalCheck) (Verdict, error)
+}
+
+// VerifierFunc adapts a function, typically a rule, to a Verifier.
+type VerifierFunc func(ctx context.Context, check SubGoalCheck) (Verdict, error)
+
+// Verify calls f.
+func (f VerifierFunc) Verify(ctx context.Context, check SubGoalCheck) (Verdict, error) {
+	return f(ctx, check)
+}
+
+// LLMVerifier asks a (typically cheaper) model to judge results.
+type LLMVerifier struct {
+	client *llm.Client
+}
+
+// NewLLMVerifier creates a verifier backed by client.
+func NewLLMVerifier(client *llm.Client) *LLMVerifier {
+	return &LLMVerifier{client: client}
+}
+
+// verdictReply is the JSON an LLM verifier answers with.
+type verdictReply struct {
+	Passed   *bool  `json:"passed"`
+	Feedback string `json:"feedback"`
+}
+
+var verdictFormat = llm.NewJSONSchemaFormat("verdict", json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "passed": {"type": "boolean"},
+    "feedback": {"type": "string"}
+  },
+  "required": ["passed", "feedback"],
+  "additionalProperties": false
+}`))
+
+// Verify asks the model whether the result accomplishes the sub-goal.
+func (v *LLMVerifier) Verify(ctx context.Context, check SubGoalCheck) (Verdict, error) {
+	result := check.Result
+	if len(result) > verifyResultLimit {
+		result = text.Head(result, verifyResultLimit) + "\n[... truncated]"
+	}
+	messages := []llm.ChatMessage{
+		{
+			Role: "system",
+			Content: `You check whether an agent's result accomplishes the sub-goal it was given.
+Reject results that are wrong, incomplete, off-topic, or claim work without showing it.
+Do not ask for more than the sub-goal requires.
+
+Respond with JSON only:
+{"passed": true or false, "feedback": "what is wrong or missing; empty if passed"}`,
+		},
+		{
+			Role:    "user",
+			Content: fmt.Sprintf("Sub-goal: %s\n\nTask given to the agent: %s\n\nResult:\n%s", check.Description, check.Task, result),
+		},
+	}
+
+	var content string
+	var usage *llm.TokenUsage
+	var err error
+	if v.client.SupportsJSONSchema() {
+		content, usage, err = v.client.ChatWithFormatAndUsage(ctx, messages, verdictFormat)
+	} else {
+		content, usage, err = v.client.ChatWithUsage(ctx, messages)
+	}
+	if err != nil {
+		return Verdict{}, agent.LLMError(err)
+	}
+
+	reply, err := jsonutil.DecodeValidated(content, func(r verdictReply) error {
+		if r.Passed == nil {
+			return fmt.Errorf("passed is required")
+		}
+		return nil
+	})
+	if err != nil {
+		return Verdict{Usage: usage}, fmt.Errorf("unusable verdict: %w", err)
+	}
+	return Verdict{Passed: *reply.Passed, Feedback: reply.Feedback, Usage: usage}, nil
+}
+
+// WithVerifier checks each successful agent result with v before its
+// sub-goal is marked completed. Rejected results are retried with the
+// verifier's feedback up to retries times (negative uses DefaultVerifyRetries).
+func (s *Supervisor) WithVerifier(v Verifier, retries int) *Supervisor {
+	if retries < 0 {
+		retries = DefaultVerifyRetries
+	}
+	s.verifier = v
+	s.verifyRetries = retries
+	return s
+}
+
+// agentOutcome is the result of one assignment, after any retries.
+type agentOutcome struct {
+	response   agent.Response // Last attempt, with usage summed over all attempts
+	attempts   int
+	checks     int            // Verifications run
+	rejections int            // Verifications that rejected the result
+	rejected   string         // Feedback when the last attempt was rejected
+	usage      llm.TokenUsage // Verifier cost
+}
+
+// runVerified runs an assignment and, with a verifier set, retries it with
+// feedback while its result is rejected.
+func (s *Supervisor) runVerified(ctx context.Context, assignment agentAssignment, contextData json.RawMessage) agentOutcome {
+	selectedAgent := s.agents[assignment.Agent]
+	// Wait for questions the agent is answering; others can't ask it meanwhile
+	if s.messageBus != nil {
+		release, err := s.messageBus.claim(ctx, assignment.Agent)
+		if err != nil {
+			return agentOutcome{attempts: 1, response: agent.NewFailureResponse(err.Error(), nil, 0)}
+		}
+		defer release()
+	}
+	// Propagate verbose setting to agent
+	selectedAgent.Verbose(s.verbose)
+	budget := s.config.budgetFor(assignment.Agent)
+
+	var outcome agentOutcome
+	var prior agent.Metadata
+	task := assignment.Task
+	for {
+		outcome.attempts++
+		resp := selectedAgent.ExecuteWithBudget(ctx, task, contextData, s.config.MaxIterations, budget)
+		addAgentUsage(&resp.Metadata, prior)
+		outcome.response = resp
+		outcome.rejected = ""
+		if resp.Type != agent.ResponseSuccess || s.verifier == nil {
+			return outcome
+		}
+
+		verdict, err := s.verifier.Verify(ctx, SubGoalCheck{
+			SubGoalID:   assignment.SubGoalID,
+			Description: assignment.goal,
+			Agent:       assignment.Agent,
+			Task:        assignment.Task,
+			Result:      resp.Result,
+		})
+		outcome.usage.Add(verdict.Usage)
+		if err != nil {
+			if s.verbose {
+				fmt.Printf("[Supervisor] Verification of '%s' skipped: %v\n", assignment.SubGoalID, err)
+			}
+			return outcome
+		}
+		outcome.checks++
+		if verdict.Passed {
+			return outcome
+		}
+
+		outcome.rejections++
+		outcome.rejected = verdict.Feedback
+		if outcome.rejected == "" {
+			outcome.rejected = "result does not accomplish the sub-goal"
+		}
+		if outcome.attempts > s.verifyRetries || ctx.Err() != nil {
+			return outcome
+		}
+		if s.verbose {
+			fmt.Printf("[Supervisor] Result for '%s' rejected (%s), retrying\n", assignment.SubGoalID, outcome.rejected)
+		}
+		prior = resp.Metadata
+		preview := text.Truncate(resp.Result, 1000)
+		task = fmt.Sprintf("%s\n\nA reviewer rejected your previous result: %s\n\nPrevious result:\n%s\n\nAddress the feedback and give a corrected result.",
+			assignment.Task, outcome.rejected, preview)
+	}
+}
+
+// addAgentUsage adds an earlier attempt's LLM usage to meta.
+func addAgentUsage(meta *agent.Metadata, prior agent.Metadata) {
+	meta.LLMCalls += prior.LLMCalls
+	meta.DecisionRepairs += prior.DecisionRepairs
+	meta.DecisionFallbacks += prior.DecisionFallbacks
+	if prior.TokenUsage == nil {
+		return
+	}
+	total := *prior.TokenUsage
+	total.Add(meta.TokenUsage)
+	meta.TokenUsage = &total
+}
@@ -0,0 +1,97 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/richinex/ariadne/agent"
+	"github.com/richinex/ariadne/llm"
+)
+
+// promptRecorder remembers every prompt its agent was sent.
+type promptRecorder struct {
+	scriptedProvider
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (p *promptRecorder) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	p.mu.Lock()
+	for _, m := range messages {
+		p.prompts = append(p.prompts, m.Content)
+	}
+	p.mu.Unlock()
+	return p.scriptedProvider.Chat(ctx, messages)
+}
+
+func verifiedSupervisor(workerLLM llm.Provider, verifier Verifier) *Supervisor {
+	worker := agent.New(agent.NewBuilder("worker").Build(), workerLLM)
+	provider := &scriptedProvider{responses: []string{
+		`{"thought": "plan", "sub_goals": [{"id": "sum", "description": "Add 2 and 2"}], "agent_to_invoke": "worker", "agent_task": "add the numbers", "sub_goal_id": "sum", "is_final": false}`,
+		`{"thought": "done", "is_final": true, "final_answer": "4"}`,
+	}}
+	return NewSupervisor([]*agent.Agent{worker}, llm.NewClient(provider), DefaultSupervisorConfig()).
+		WithVerifier(verifier, -1)
+}
+
+func TestSupervisorRetriesRejectedResults(t *testing.T) {
+	workerLLM := &promptRecorder{scriptedProvider: scriptedProvider{responses: []string{
+		`{"thought": "guess", "is_final": true, "final_answer": "5"}`,
+		`{"thought": "fixed", "is_final": true, "final_answer": "4"}`,
+	}}}
+	var checks []SubGoalCheck
+	verifier := VerifierFunc(func(ctx context.Context, check SubGoalCheck) (Verdict, error) {
+		checks = append(checks, check)
+		if check.Result != "4" {
+			return Verdict{Feedback: "2 + 2 is not 5"}, nil
+		}
+		return Verdict{Passed: true}, nil
+	})
+
+	resp := verifiedSupervisor(workerLLM, verifier).Orchestrate(context.Background(), "add", 3)
+	if resp.Type != ResponseSuccess {
+		t.Fatalf("expected success, got %+v", resp)
+	}
+	if len(checks) != 2 || checks[0].Description != "Add 2 and 2" || checks[1].Task != "add the numbers" {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+	if obs := *resp.Steps[0].Observation; obs != "SUCCESS: 4" {
+		t.Errorf("expected the corrected result, got %q", obs)
+	}
+	if !strings.Contains(strings.Join(workerLLM.prompts, "\n"), "A reviewer rejected your previous result: 2 + 2 is not 5") {
+		t.Error("retry did not include the verifier's feedback")
+	}
+	if stats := resp.Metadata.TokenStats; stats.Verifications != 2 || stats.VerifyRejections != 1 {
+		t.Errorf("unexpected verification stats: %+v", stats)
+	}
+}
+
+func TestSupervisorFailsPersistentlyRejectedSubGoal(t *testing.T) {
+	workerLLM := &scriptedProvider{responses: []string{`{"thought": "guess", "is_final": true, "final_answer": "5"}`}}
+	verifier := VerifierFunc(func(ctx context.Context, check SubGoalCheck) (Verdict, error) {
+		return Verdict{Feedback: "wrong sum"}, nil
+	})
+
+	resp := verifiedSupervisor(workerLLM, verifier).Orchestrate(context.Background(), "add", 3)
+	obs := *resp.Steps[0].Observation
+	if !strings.HasPrefix(obs, "VERIFICATION FAILED after 2 attempts: wrong sum") || !strings.Contains(obs, "Last result: 5") {
+		t.Errorf("unexpected observation: %q", obs)
+	}
+}
+
+func TestLLMVerifier(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		`{"passed": false, "feedback": "no sources cited"}`,
+	}}
+	verdict, err := NewLLMVerifier(llm.NewClient(provider)).Verify(context.Background(), SubGoalCheck{Description: "Cite sources", Result: "trust me"})
+	if err != nil || verdict.Passed || verdict.Feedback != "no sources cited" {
+		t.Errorf("unexpected verdict: %+v, %v", verdict, err)
+	}
+
+	provider = &scriptedProvider{responses: []string{`{"feedback": "looks fine"}`}}
+	if _, err := NewLLMVerifier(llm.NewClient(provider)).Verify(context.Background(), SubGoalCheck{}); err == nil {
+		t.Error("expected an error for a verdict without passed")
+	}
+}
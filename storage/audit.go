@@ -0,0 +1,49 @@
+// Package storage provides an append-only audit log of mutating tool calls.
+//
+// AuditStorage records who changed what: every file write and shell
+// command an agent runs, with a hash of its arguments rather than the
+// arguments themselves. Entries can be listed but never changed or
+// removed.
+
+package storage
+
+import (
+	"context"
+)
+
+// Audit entry statuses.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	AuditDenied  = "denied" // Refused by a tool or run policy
+)
+
+// AuditStorage persists audit entries.
+type AuditStorage interface {
+	// RecordAudit appends an entry.
+	RecordAudit(ctx context.Context, entry AuditEntry) error
+
+	// ListAudit returns entries matching filter, oldest first.
+	ListAudit(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
+
+// AuditEntry is one mutating tool call.
+type AuditEntry struct {
+	ID        int64  // Assigned on insert; increases with time
+	SessionID string // Session the run belonged to
+	RunID     string // Run that made the call
+	AgentID   string // Agent or sub-agent that made the call
+	ToolName  string
+	ArgsHash  string // SHA-256 of the compacted arguments
+	Status    string // AuditSuccess, AuditFailure or AuditDenied
+	CreatedAt int64  // Unix timestamp
+}
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	SessionID string
+	RunID     string
+	ToolName  string
+	Since     int64 // Unix timestamp; entries at or after it
+	Limit     int   // Most recent N entries (0 = all)
+}
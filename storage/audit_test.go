@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSqliteAuditLog(t *testing.T) {
+	storage, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	entries := []AuditEntry{
+		{SessionID: "s1", RunID: "run1", AgentID: "root", ToolName: "write_file", ArgsHash: "a", Status: AuditSuccess},
+		{SessionID: "s1", RunID: "run1", AgentID: "sub-agent 1", ToolName: "execute_shell", ArgsHash: "b", Status: AuditDenied},
+		{SessionID: "s2", RunID: "run2", AgentID: "root", ToolName: "edit_file", ArgsHash: "c", Status: AuditFailure},
+	}
+	for _, e := range entries {
+		if err := storage.RecordAudit(ctx, e); err != nil {
+			t.Fatalf("RecordAudit failed: %v", err)
+		}
+	}
+
+	got, err := storage.ListAudit(ctx, AuditFilter{SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("ListAudit failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ToolName != "write_file" || got[1].AgentID != "sub-agent 1" || got[1].CreatedAt == 0 {
+		t.Errorf("expected session s1's entries oldest first, got %+v", got)
+	}
+
+	got, err = storage.ListAudit(ctx, AuditFilter{Limit: 1})
+	if err != nil || len(got) != 1 || got[0].ToolName != "edit_file" {
+		t.Errorf("expected the most recent entry, got %+v, %v", got, err)
+	}
+
+	if _, err := storage.db.ExecContext(ctx, "UPDATE audit_log SET status = 'success'"); err == nil {
+		t.Error("audit entries should not be updatable")
+	}
+	if _, err := storage.db.ExecContext(ctx, "DELETE FROM audit_log"); err == nil {
+		t.Error("audit entries should not be deletable")
+	}
+}
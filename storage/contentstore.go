@@ -14,6 +14,9 @@ type ContentStorage interface {
 	// StoreResult stores a content result.
 	StoreResult(ctx context.Context, result ContentResult) error
 
+	// StoreResults stores several results in one transaction.
+	StoreResults(ctx context.Context, results []ContentResult) error
+
 	// LoadAllResults loads all results from storage.
 	LoadAllResults(ctx context.Context) ([]ContentResult, error)
 
@@ -23,11 +26,23 @@ type ContentStorage interface {
 	// UpdateResultAccess updates access timestamp and count for a result.
 	UpdateResultAccess(ctx context.Context, sessionID, key string) error
 
-	// DeleteResult removes a specific result.
+	// DeleteResult removes a specific result. Its version history is kept.
 	DeleteResult(ctx context.Context, sessionID, key string) error
 
-	// DeleteSessionResults removes all results for a session.
+	// DeleteSessionResults removes all unpinned results for a session.
 	DeleteSessionResults(ctx context.Context, sessionID string) error
+
+	// SetResultPinned pins or unpins a result. Pinned results survive
+	// DeleteSessionResults.
+	SetResultPinned(ctx context.Context, sessionID, key string, pinned bool) error
+
+	// ListResultVersions lists every stored version of a key, newest
+	// first, without content.
+	ListResultVersions(ctx context.Context, sessionID, key string) ([]ContentResult, error)
+
+	// GetResultVersion returns one version of a key, or nil if it
+	// doesn't exist.
+	GetResultVersion(ctx context.Context, sessionID, key string, version int) (*ContentResult, error)
 }
 
 // ContentResult represents stored content metadata including access tracking.
@@ -42,4 +57,7 @@ type ContentResult struct {
 	CreatedAt   int64  // Unix timestamp of creation
 	AccessedAt  int64  // Unix timestamp of last access
 	AccessCount int    // Number of times accessed
+	Version     int    // Increments each time the key's content changes
+	Metadata    string // JSON, e.g. {"tags": {...}}; empty before tagging
+	Pinned      bool   // Survives session cleanup
 }
@@ -0,0 +1,129 @@
+// Structured episodic memories.
+//
+// An episodic memory records one finished run or chat turn. Its content
+// is a one-line "Task: ... | Result: ..." for listings and search; its
+// metadata holds an Episode with the task, outcome, result, tools used and
+// the files and URLs the run touched. Prompts are built from the episodes
+// with DescribeMemory, so a later run can tell at a glance what was done
+// before, how it went and where. Memories stored without an episode are
+// described by their content.
+//
+// Information Hiding:
+// - Episode layout in the metadata column
+// - Which tool arguments count as references
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/richinex/ariadne/internal/text"
+)
+
+// Episode outcomes.
+const (
+	EpisodeSuccess = "success"
+	EpisodeTimeout = "timeout"
+)
+
+// Episode limits: bytes of the result kept in the metadata, bytes of the
+// task and result in descriptions and content, and references kept.
+const (
+	episodeResultLen   = 1000
+	episodeDescribeLen = 200
+	episodeContentLen  = 150
+	episodeReferences  = 10
+)
+
+// referenceArgs are the tool argument names whose values are files or
+// URLs a run touched.
+var referenceArgs = []string{"path", "paths", "file", "files", "file_path", "url"}
+
+// Episode is the metadata of an episodic memory.
+type Episode struct {
+	Task       string   `json:"task"`
+	Outcome    string   `json:"outcome"`
+	Result     string   `json:"result,omitempty"`
+	Tools      []string `json:"tools,omitempty"`      // In order of first use
+	References []string `json:"references,omitempty"` // Files and URLs, in order of first use
+}
+
+// AddToolCall records a call to tool with JSON arguments args: the tool,
+// and any files or URLs the arguments name.
+func (e *Episode) AddToolCall(tool string, args json.RawMessage) {
+	if !slices.Contains(e.Tools, tool) {
+		e.Tools = append(e.Tools, tool)
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(args, &fields) != nil {
+		return
+	}
+	add := func(ref string) {
+		if ref != "" && len(e.References) < episodeReferences && !slices.Contains(e.References, ref) {
+			e.References = append(e.References, ref)
+		}
+	}
+	for _, name := range referenceArgs {
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var one string
+		var many []string
+		if json.Unmarshal(raw, &one) == nil {
+			add(one)
+		} else if json.Unmarshal(raw, &many) == nil {
+			for _, ref := range many {
+				add(ref)
+			}
+		}
+	}
+}
+
+// Memory returns an episodic memory entry for the episode.
+func (e Episode) Memory(sessionID string) MemoryEntry {
+	e.Result = text.Truncate(e.Result, episodeResultLen)
+	content := fmt.Sprintf("Task: %s | Result: %s", text.OneLine(e.Task), text.Truncate(text.OneLine(e.Result), episodeContentLen))
+	entry := NewMemoryEntry(sessionID, MemoryEpisodic, content)
+	if metadata, err := json.Marshal(e); err == nil {
+		entry = entry.WithMetadata(string(metadata))
+	}
+	return entry
+}
+
+// Describe formats the episode as a list item for a prompt.
+func (e Episode) Describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- Task: %s\n  Outcome: %s", text.Truncate(text.OneLine(e.Task), episodeDescribeLen), e.Outcome)
+	if e.Result != "" {
+		fmt.Fprintf(&b, "\n  Result: %s", text.Truncate(text.OneLine(e.Result), episodeDescribeLen))
+	}
+	if len(e.Tools) > 0 {
+		fmt.Fprintf(&b, "\n  Tools used: %s", strings.Join(e.Tools, ", "))
+	}
+	if len(e.References) > 0 {
+		fmt.Fprintf(&b, "\n  References: %s", strings.Join(e.References, ", "))
+	}
+	return b.String()
+}
+
+// ParseEpisode returns the episode in m's metadata, if it has one.
+func ParseEpisode(m MemoryEntry) (Episode, bool) {
+	var e Episode
+	if m.Metadata == "" || json.Unmarshal([]byte(m.Metadata), &e) != nil || e.Outcome == "" {
+		return Episode{}, false
+	}
+	return e, true
+}
+
+// DescribeMemory formats a memory as a list item for a prompt: from its
+// episode if it has one, otherwise from its content.
+func DescribeMemory(m MemoryEntry) string {
+	if e, ok := ParseEpisode(m); ok {
+		return e.Describe()
+	}
+	return "- " + m.Content
+}
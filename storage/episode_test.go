@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEpisodeMemoryRoundTrip(t *testing.T) {
+	e := Episode{Task: "fix the\nparser", Outcome: EpisodeSuccess, Result: "Fixed the off-by-one in parse.go"}
+	e.AddToolCall("read_file", json.RawMessage(`{"path": "parse.go"}`))
+	e.AddToolCall("grep", json.RawMessage(`{"pattern": "idx", "paths": ["parse.go", "lex.go"]}`))
+	e.AddToolCall("read_file", json.RawMessage(`{"path": "lex.go"}`))
+	e.AddToolCall("http_request", json.RawMessage(`{"url": "https://go.dev/ref/spec"}`))
+
+	if got := strings.Join(e.Tools, ","); got != "read_file,grep,http_request" {
+		t.Errorf("Tools = %s", got)
+	}
+	if got := strings.Join(e.References, ","); got != "parse.go,lex.go,https://go.dev/ref/spec" {
+		t.Errorf("References = %s", got)
+	}
+
+	m := e.Memory("s")
+	if m.Type != MemoryEpisodic || m.Content != "Task: fix the parser | Result: Fixed the off-by-one in parse.go" {
+		t.Errorf("unexpected entry: %+v", m)
+	}
+	parsed, ok := ParseEpisode(m)
+	if !ok || parsed.Outcome != EpisodeSuccess || len(parsed.References) != 3 {
+		t.Fatalf("ParseEpisode() = %+v, %v", parsed, ok)
+	}
+
+	want := "- Task: fix the parser\n  Outcome: success\n  Result: Fixed the off-by-one in parse.go\n" +
+		"  Tools used: read_file, grep, http_request\n  References: parse.go, lex.go, https://go.dev/ref/spec"
+	if got := DescribeMemory(m); got != want {
+		t.Errorf("DescribeMemory() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDescribeMemoryWithoutEpisode(t *testing.T) {
+	m := NewMemoryEntry("s", MemoryEpisodic, "Task: a | Result: b")
+	if got := DescribeMemory(m); got != "- Task: a | Result: b" {
+		t.Errorf("DescribeMemory() = %q", got)
+	}
+	if _, ok := ParseEpisode(m.WithMetadata(`{"steps": []}`)); ok {
+		t.Error("metadata without an outcome is not an episode")
+	}
+}
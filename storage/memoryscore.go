@@ -0,0 +1,140 @@
+// Memory scoring and pruning.
+//
+// A memory's score combines how important it was judged to be, how
+// recently it was used, and how often it has been accessed:
+//
+//	score = 0.5*importance + 0.3*recency + 0.2*use
+//	recency = 0.5 ^ (time since last access / half-life)
+//	use = 1 - 1/(1 + access count)
+//
+// Every term is in [0, 1], so the score is too. Retrieval ranks by score
+// and pruning drops the lowest-scoring memories.
+//
+// Information Hiding:
+// - Score weights and decay curve hidden
+// - Per-session grouping during pruning hidden
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultMemoryImportance is the importance of memories that don't set one.
+	DefaultMemoryImportance = 0.5
+	// DefaultMemoryHalfLife is how long it takes recency to halve.
+	DefaultMemoryHalfLife = 7 * 24 * time.Hour
+	// MemoryRankWindow is how many recent memories callers fetch before
+	// ranking, so an important older memory can outrank newer ones.
+	MemoryRankWindow = 50
+
+	importanceWeight = 0.5
+	recencyWeight    = 0.3
+	useWeight        = 0.2
+)
+
+// MemoryScore scores a memory at time now. A zero halfLife uses
+// DefaultMemoryHalfLife.
+func MemoryScore(entry MemoryEntry, now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		halfLife = DefaultMemoryHalfLife
+	}
+	age := now.Sub(time.Unix(entry.AccessedAt, 0))
+	if age < 0 {
+		age = 0
+	}
+	recency := math.Pow(0.5, float64(age)/float64(halfLife))
+	use := 1 - 1/(1+float64(entry.AccessCount))
+	return importanceWeight*entry.Importance + recencyWeight*recency + useWeight*use
+}
+
+// RankMemories sorts entries by MemoryScore, highest first. Ties keep
+// their original order.
+func RankMemories(entries []MemoryEntry, now time.Time, halfLife time.Duration) {
+	scores := make(map[string]float64, len(entries))
+	for _, e := range entries {
+		scores[e.ID] = MemoryScore(e, now, halfLife)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return scores[entries[i].ID] > scores[entries[j].ID]
+	})
+}
+
+// MemoryPruneOptions selects which memories PruneMemories removes.
+type MemoryPruneOptions struct {
+	SessionID string        // Session to prune; empty prunes every session
+	Keep      int           // Keep at most this many per session (0 = no cap)
+	MinScore  float64       // Remove memories scoring below this
+	HalfLife  time.Duration // Recency half-life (0 = DefaultMemoryHalfLife)
+	DryRun    bool          // Count without deleting
+}
+
+// PruneMemories removes, per session, memories scoring below MinScore and
+// the lowest-scoring memories beyond Keep. Returns how many were removed
+// (or would be, with DryRun).
+func (s *SqliteStorage) PruneMemories(ctx context.Context, opts MemoryPruneOptions) (int, error) {
+	query := "SELECT id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata, importance FROM memories"
+	var args []interface{}
+	if opts.SessionID != "" {
+		query += " WHERE session_id = ?"
+		args = append(args, opts.SessionID)
+	}
+	query += " ORDER BY created_at DESC, rowid DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query memories: %w", err)
+	}
+	bySession := make(map[string][]MemoryEntry)
+	for rows.Next() {
+		entry, err := s.scanMemoryRow(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		bySession[entry.SessionID] = append(bySession[entry.SessionID], entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating memories: %w", err)
+	}
+
+	now := time.Now()
+	var doomed []string
+	for _, entries := range bySession {
+		RankMemories(entries, now, opts.HalfLife)
+		for i, e := range entries {
+			if (opts.Keep > 0 && i >= opts.Keep) || MemoryScore(e, now, opts.HalfLife) < opts.MinScore {
+				doomed = append(doomed, e.ID)
+			}
+		}
+	}
+	if opts.DryRun || len(doomed) == 0 {
+		return len(doomed), nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, "DELETE FROM memories WHERE id = ?")
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare delete statement: %w", err)
+	}
+	defer stmt.Close()
+	for _, id := range doomed {
+		if _, err := stmt.ExecContext(ctx, id); err != nil {
+			return 0, fmt.Errorf("failed to delete memory: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(doomed), nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryScoreDecaysAndRanks(t *testing.T) {
+	now := time.Now()
+	fresh := NewMemoryEntry("s", MemoryFact, "fresh")
+	stale := NewMemoryEntry("s", MemoryFact, "stale")
+	stale.AccessedAt = now.Add(-DefaultMemoryHalfLife).Unix()
+	important := NewMemoryEntry("s", MemoryFact, "important").WithImportance(1)
+	important.AccessedAt = stale.AccessedAt
+
+	if got, want := MemoryScore(fresh, now, 0)-MemoryScore(stale, now, 0), recencyWeight/2; got < want-0.01 || got > want+0.01 {
+		t.Errorf("one half-life should cost half the recency weight: got %.3f, want %.3f", got, want)
+	}
+	if imp := NewMemoryEntry("s", MemoryFact, "x").WithImportance(7); imp.Importance != 1 {
+		t.Errorf("importance not clamped: %v", imp.Importance)
+	}
+
+	entries := []MemoryEntry{stale, fresh, important}
+	RankMemories(entries, now, 0)
+	if entries[0].Content != "important" || entries[2].Content != "stale" {
+		t.Errorf("unexpected ranking: %s, %s, %s", entries[0].Content, entries[1].Content, entries[2].Content)
+	}
+}
+
+func TestSqliteStoragePruneMemories(t *testing.T) {
+	store, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	old := time.Now().Add(-30 * 24 * time.Hour).Unix()
+	for _, e := range []MemoryEntry{
+		NewMemoryEntry("s1", MemoryFact, "keep: important").WithImportance(1),
+		NewMemoryEntry("s1", MemoryFact, "keep: recent"),
+		func() MemoryEntry {
+			e := NewMemoryEntry("s1", MemoryEpisodic, "drop: old and minor").WithImportance(0.1)
+			e.CreatedAt, e.AccessedAt = old, old
+			return e
+		}(),
+		NewMemoryEntry("s2", MemoryFact, "other session"),
+	} {
+		if err := store.StoreMemory(ctx, e); err != nil {
+			t.Fatalf("StoreMemory failed: %v", err)
+		}
+	}
+
+	opts := MemoryPruneOptions{SessionID: "s1", MinScore: 0.2, DryRun: true}
+	if n, err := store.PruneMemories(ctx, opts); err != nil || n != 1 {
+		t.Fatalf("dry run = %d, %v; want 1", n, err)
+	}
+	if all, _ := store.QueryMemories(ctx, "s1", nil, 10); len(all) != 3 {
+		t.Fatalf("dry run deleted memories: %d left", len(all))
+	}
+
+	opts.DryRun = false
+	opts.Keep = 1
+	if n, err := store.PruneMemories(ctx, opts); err != nil || n != 2 {
+		t.Fatalf("prune = %d, %v; want 2", n, err)
+	}
+	left, _ := store.QueryMemories(ctx, "s1", nil, 10)
+	if len(left) != 1 || left[0].Content != "keep: important" {
+		t.Errorf("unexpected survivors: %+v", left)
+	}
+	if other, _ := store.QueryMemories(ctx, "s2", nil, 10); len(other) != 1 {
+		t.Errorf("prune touched another session: %d left", len(other))
+	}
+}
@@ -8,6 +8,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -24,6 +25,11 @@ const (
 	MemoryOrchestration MemoryType = "orchestration"
 	// MemoryConversation represents chat history (existing conversation storage).
 	MemoryConversation MemoryType = "conversation"
+	// MemoryFact represents facts an agent chose to remember (store_memory).
+	MemoryFact MemoryType = "fact"
+	// MemoryPartial represents the progress of a run that failed or timed
+	// out, kept so a later run can pick up where it stopped.
+	MemoryPartial MemoryType = "partial"
 )
 
 // String returns the string representation of the memory type.
@@ -40,6 +46,10 @@ func ParseMemoryType(s string) (MemoryType, error) {
 		return MemoryOrchestration, nil
 	case "conversation":
 		return MemoryConversation, nil
+	case "fact":
+		return MemoryFact, nil
+	case "partial":
+		return MemoryPartial, nil
 	default:
 		return "", fmt.Errorf("unknown memory type: %s", s)
 	}
@@ -65,6 +75,8 @@ type MemoryEntry struct {
 	AccessCount uint32 `json:"access_count"`
 	// Metadata is optional JSON metadata for extensibility (empty if none).
 	Metadata string `json:"metadata,omitempty"`
+	// Importance is how much the memory matters, from 0 to 1.
+	Importance float64 `json:"importance"`
 }
 
 // NewMemoryEntry creates a new memory entry with defaults.
@@ -80,6 +92,7 @@ func NewMemoryEntry(sessionID string, memoryType MemoryType, content string) Mem
 		AccessedAt:  now,
 		AccessCount: 0, // Not accessed yet
 		Metadata:    "", // Empty means no metadata
+		Importance:  DefaultMemoryImportance,
 	}
 }
 
@@ -95,6 +108,12 @@ func (m MemoryEntry) WithMetadata(metadata string) MemoryEntry {
 	return m
 }
 
+// WithImportance sets the importance, clamped to [0, 1].
+func (m MemoryEntry) WithImportance(importance float64) MemoryEntry {
+	m.Importance = math.Min(math.Max(importance, 0), 1)
+	return m
+}
+
 // MemoryStorage is the extended storage interface for rich memory capabilities.
 // Extends ConversationStorage with structured memory operations.
 type MemoryStorage interface {
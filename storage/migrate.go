@@ -0,0 +1,400 @@
+// Schema versioning and migration for the SQLite database.
+//
+// The schema version is kept in SQLite's user_version pragma. A new
+// database is created at the current version. An existing database at an
+// older or newer version is refused with ErrSchemaVersion rather than
+// read or written with the wrong table layout; MigrateSqlite upgrades it
+// after taking a backup.
+//
+// To change the schema, append a migration with the next version. Never
+// edit a migration that has shipped.
+//
+// Information Hiding:
+// - Version bookkeeping (user_version) hidden
+// - Backup and transactional upgrade steps hidden
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrSchemaVersion means the database schema version does not match the
+// version this build supports.
+var ErrSchemaVersion = errors.New("incompatible database schema version")
+
+// SchemaVersionError reports a schema version mismatch. It matches
+// ErrSchemaVersion.
+type SchemaVersionError struct {
+	Found     int
+	Supported int
+}
+
+func (e *SchemaVersionError) Error() string {
+	if e.Found > e.Supported {
+		return fmt.Sprintf("database schema version %d is newer than this build supports (%d); upgrade ariadne", e.Found, e.Supported)
+	}
+	return fmt.Sprintf("database schema version %d is older than %d; run 'ariadne migrate' to upgrade it", e.Found, e.Supported)
+}
+
+// Is reports whether target is ErrSchemaVersion.
+func (e *SchemaVersionError) Is(target error) bool {
+	return target == ErrSchemaVersion
+}
+
+// migration upgrades the schema from version-1 to version.
+type migration struct {
+	version     int
+	description string
+	statements  string
+}
+
+// migrations lists every schema change in order. Version 1 is the layout
+// that predates version tracking; its statements are idempotent so
+// unversioned databases upgrade in place.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "sessions, messages, memories, results and tool_calls tables",
+		statements: `
+	CREATE TABLE IF NOT EXISTS sessions (
+		session_id TEXT PRIMARY KEY,
+		created_at TEXT NOT NULL DEFAULT (datetime('now')),
+		updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+	);
+
+	CREATE TABLE IF NOT EXISTS messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id TEXT NOT NULL,
+		message_index INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		content TEXT NOT NULL,
+		FOREIGN KEY (session_id) REFERENCES sessions(session_id) ON DELETE CASCADE,
+		UNIQUE(session_id, message_index)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_messages_session
+	ON messages(session_id, message_index);
+
+	CREATE TABLE IF NOT EXISTS memories (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		agent_id TEXT,
+		memory_type TEXT NOT NULL,
+		content TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		accessed_at INTEGER NOT NULL,
+		access_count INTEGER DEFAULT 1,
+		metadata TEXT,
+		FOREIGN KEY (session_id) REFERENCES sessions(session_id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_memories_session_type
+	ON memories(session_id, memory_type, created_at DESC);
+
+	CREATE TABLE IF NOT EXISTS results (
+		session_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		content_hash TEXT NOT NULL,
+		content TEXT NOT NULL,
+		summary TEXT NOT NULL,
+		line_count INTEGER NOT NULL,
+		byte_size INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		accessed_at INTEGER NOT NULL,
+		access_count INTEGER DEFAULT 1,
+		PRIMARY KEY (session_id, key)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_results_session
+	ON results(session_id);
+
+	CREATE INDEX IF NOT EXISTS idx_results_hash
+	ON results(content_hash);
+
+	CREATE TABLE IF NOT EXISTS tool_calls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		args_hash TEXT NOT NULL,
+		input_size INTEGER NOT NULL,
+		output_size INTEGER NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		success INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tool_calls_tool
+	ON tool_calls(tool_name, run_id);
+`,
+	},
+	{
+		version:     2,
+		description: "tool calls and tool call IDs on messages",
+		statements: `
+		ALTER TABLE messages ADD COLUMN tool_calls TEXT;
+		ALTER TABLE messages ADD COLUMN tool_call_id TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version:     3,
+		description: "importance scores on memories",
+		statements: `
+		ALTER TABLE memories ADD COLUMN importance REAL NOT NULL DEFAULT 0.5;
+`,
+	},
+	{
+		version:     4,
+		description: "version history for stored results",
+		statements: `
+		ALTER TABLE results ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+		CREATE TABLE result_versions (
+			session_id TEXT NOT NULL,
+			key TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			content_hash TEXT NOT NULL,
+			content TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			line_count INTEGER NOT NULL,
+			byte_size INTEGER NOT NULL,
+			stored_at INTEGER NOT NULL,
+			PRIMARY KEY (session_id, key, version)
+		);
+
+		INSERT INTO result_versions
+		SELECT session_id, key, 1, content_hash, content, summary, line_count, byte_size, created_at
+		FROM results;
+`,
+	},
+	{
+		version:     5,
+		description: "revision counter on sessions for concurrent writers",
+		statements: `
+		ALTER TABLE sessions ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version:     6,
+		description: "append-only audit log of mutating tool calls",
+		statements: `
+		CREATE TABLE audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			run_id TEXT NOT NULL,
+			agent_id TEXT NOT NULL,
+			tool_name TEXT NOT NULL,
+			args_hash TEXT NOT NULL,
+			status TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE INDEX idx_audit_log_session ON audit_log(session_id, id);
+
+		CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+		BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END;
+
+		CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+		BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END;
+`,
+	},
+	{
+		version:     7,
+		description: "metadata (content tags) on stored results",
+		statements: `
+		ALTER TABLE results ADD COLUMN metadata TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version:     8,
+		description: "pinned flag on stored results",
+		statements: `
+		ALTER TABLE results ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version:     9,
+		description: "per-run result store usage",
+		statements: `
+		CREATE TABLE dsa_usage (
+			run_id TEXT PRIMARY KEY,
+			files_stored INTEGER NOT NULL,
+			bytes_indexed INTEGER NOT NULL,
+			searches INTEGER NOT NULL,
+			cache_hits INTEGER NOT NULL,
+			get_lines_calls INTEGER NOT NULL,
+			bytes_saved INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+`,
+	},
+}
+
+// SchemaVersion is the schema version this build reads and writes.
+var SchemaVersion = migrations[len(migrations)-1].version
+
+// MigrationReport describes what MigrateSqlite did or would do.
+type MigrationReport struct {
+	From    int
+	To      int
+	Applied []string // Descriptions of the migrations applied, in order
+	Backup  string   // Backup path; empty if nothing was applied
+}
+
+// initSchema creates a new database at SchemaVersion, and refuses an
+// existing database at any other version.
+func (s *SqliteStorage) initSchema(ctx context.Context) error {
+	empty, err := isEmptyDatabase(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	if empty {
+		if err := createSchema(ctx, s.db); err != nil {
+			return err
+		}
+	}
+
+	version, err := schemaVersion(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	if version != SchemaVersion {
+		return &SchemaVersionError{Found: version, Supported: SchemaVersion}
+	}
+	return nil
+}
+
+// MigrateSqlite upgrades the database at path to SchemaVersion. Before
+// changing anything it writes a consistent copy next to the database
+// (path.v<from>-<timestamp>.bak). With dryRun it only reports the pending
+// migrations. Each migration runs in its own transaction, so a failure
+// leaves the database at the last completed version.
+func MigrateSqlite(ctx context.Context, path string, dryRun bool) (MigrationReport, error) {
+	if _, err := os.Stat(path); err != nil {
+		return MigrationReport{}, fmt.Errorf("database not found: %w", err)
+	}
+	db, err := openSqliteDB(path)
+	if err != nil {
+		return MigrationReport{}, err
+	}
+	defer db.Close()
+
+	from, err := schemaVersion(ctx, db)
+	if err != nil {
+		return MigrationReport{}, err
+	}
+	report := MigrationReport{From: from, To: SchemaVersion}
+	if from > SchemaVersion {
+		return report, &SchemaVersionError{Found: from, Supported: SchemaVersion}
+	}
+	for _, m := range migrations {
+		if m.version > from {
+			report.Applied = append(report.Applied, fmt.Sprintf("v%d: %s", m.version, m.description))
+		}
+	}
+	if len(report.Applied) == 0 || dryRun {
+		return report, nil
+	}
+
+	report.Backup = fmt.Sprintf("%s.v%d-%s.bak", path, from, time.Now().Format("20060102-150405"))
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", report.Backup); err != nil {
+		return report, fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	if err := applyMigrations(ctx, db, from); err != nil {
+		return report, fmt.Errorf("%w (backup: %s)", err, report.Backup)
+	}
+	return report, nil
+}
+
+// createSchema creates every table in one transaction at SchemaVersion.
+// Another process may have created the database since it was found
+// empty; then this does nothing.
+func createSchema(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin schema creation: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var tables int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		return fmt.Errorf("failed to inspect database: %w", err)
+	}
+	if tables > 0 {
+		return nil
+	}
+	for _, m := range migrations {
+		if _, err := tx.ExecContext(ctx, m.statements); err != nil {
+			return fmt.Errorf("migration to v%d failed: %w", m.version, err)
+		}
+	}
+	// PRAGMA does not take bound parameters; version is a trusted int
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to record schema v%d: %w", SchemaVersion, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schema: %w", err)
+	}
+	return nil
+}
+
+// applyMigrations runs every migration newer than from. Each migration
+// re-checks the version under the write lock, so a concurrent migrate
+// doesn't apply it twice.
+func applyMigrations(ctx context.Context, db *sql.DB, from int) error {
+	for _, m := range migrations {
+		if m.version <= from {
+			continue
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration to v%d: %w", m.version, err)
+		}
+		var current int
+		if err := tx.QueryRowContext(ctx, "PRAGMA user_version").Scan(&current); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if current >= m.version {
+			tx.Rollback()
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, m.statements); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration to v%d failed: %w", m.version, err)
+		}
+		// PRAGMA does not take bound parameters; version is a trusted int
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record schema v%d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration to v%d: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+// schemaVersion reads the database's user_version.
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// isEmptyDatabase reports whether the database has no tables yet.
+func isEmptyDatabase(ctx context.Context, db *sql.DB) (bool, error) {
+	var tables int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		return false, fmt.Errorf("failed to inspect database: %w", err)
+	}
+	return tables == 0, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// createUnversionedDB creates a database with the pre-versioning layout:
+// tables but user_version 0 and no tool_calls table.
+func createUnversionedDB(t *testing.T, path string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`
+		CREATE TABLE sessions (session_id TEXT PRIMARY KEY, created_at TEXT NOT NULL DEFAULT (datetime('now')), updated_at TEXT NOT NULL DEFAULT (datetime('now')));
+		INSERT INTO sessions (session_id) VALUES ('old-session');
+	`); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+}
+
+func TestOpenSqliteStampsNewDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.db")
+	store, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("OpenSqlite failed: %v", err)
+	}
+	version, err := schemaVersion(context.Background(), store.db)
+	store.Close()
+	if err != nil {
+		t.Fatalf("schemaVersion failed: %v", err)
+	}
+	if version != SchemaVersion {
+		t.Errorf("version = %d, want %d", version, SchemaVersion)
+	}
+
+	// Reopening a current database succeeds
+	store, err = OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	store.Close()
+}
+
+func TestOpenSqliteRefusesOldSchemaUntilMigrated(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "old.db")
+	createUnversionedDB(t, path)
+
+	if _, err := OpenSqlite(path); !errors.Is(err, ErrSchemaVersion) {
+		t.Fatalf("expected ErrSchemaVersion, got %v", err)
+	}
+
+	report, err := MigrateSqlite(ctx, path, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if report.From != 0 || report.To != SchemaVersion || len(report.Applied) == 0 || report.Backup != "" {
+		t.Errorf("unexpected dry-run report: %+v", report)
+	}
+	if _, err := OpenSqlite(path); !errors.Is(err, ErrSchemaVersion) {
+		t.Fatalf("dry run must not migrate, got %v", err)
+	}
+
+	report, err = MigrateSqlite(ctx, path, false)
+	if err != nil {
+		t.Fatalf("MigrateSqlite failed: %v", err)
+	}
+	if _, err := os.Stat(report.Backup); err != nil {
+		t.Errorf("backup not written: %v", err)
+	}
+
+	store, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("OpenSqlite after migrate failed: %v", err)
+	}
+	defer store.Close()
+	sessions, err := store.ListSessions(ctx)
+	if err != nil || len(sessions) != 1 || sessions[0] != "old-session" {
+		t.Errorf("sessions after migrate = %v, %v", sessions, err)
+	}
+	if err := store.RecordToolCalls(ctx, []ToolCallRecord{{RunID: "r", ToolName: "t"}}); err != nil {
+		t.Errorf("tool_calls table missing after migrate: %v", err)
+	}
+
+	// Migrating again is a no-op
+	report, err = MigrateSqlite(ctx, path, false)
+	if err != nil || len(report.Applied) != 0 || report.Backup != "" {
+		t.Errorf("second migrate = %+v, %v", report, err)
+	}
+}
+
+func TestSchemaTooNewRefused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.db")
+	store, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("OpenSqlite failed: %v", err)
+	}
+	if _, err := store.db.Exec("PRAGMA user_version = 999"); err != nil {
+		t.Fatalf("set version: %v", err)
+	}
+	store.Close()
+
+	if _, err := OpenSqlite(path); !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("OpenSqlite: expected ErrSchemaVersion, got %v", err)
+	}
+	if _, err := MigrateSqlite(context.Background(), path, false); !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("MigrateSqlite: expected ErrSchemaVersion, got %v", err)
+	}
+}
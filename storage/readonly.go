@@ -0,0 +1,32 @@
+// Read-only access to a ResultStore.
+//
+// A context marked with WithReadOnly can read from a ResultStore but not
+// change it: Store, StoreBatch, Delete, DeleteSession, Pin and Unpin fail
+// with ErrReadOnly. Spawned sub-agents run their tools with such a context
+// so they can search the parent's stored content without clobbering it.
+//
+// Information Hiding:
+// - Context key hidden
+
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrReadOnly is returned when a read-only context writes to a ResultStore.
+var ErrReadOnly = errors.New("result store is read-only")
+
+type readOnlyKey struct{}
+
+// WithReadOnly returns a context that can only read from a ResultStore.
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+// IsReadOnly reports whether ctx was marked with WithReadOnly.
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
@@ -19,14 +19,16 @@ type ResultKey struct {
 // ResultMetadata contains summary information about stored content.
 // This is what the supervisor receives instead of full content.
 type ResultMetadata struct {
-	Key         ResultKey `json:"key"`
-	ContentHash string    `json:"content_hash"` // Hash of content for deduplication
-	Summary     string    `json:"summary"`      // First N characters or lines
-	LineCount   int       `json:"line_count"`   // Total lines in content
-	ByteSize    int       `json:"byte_size"`    // Size in bytes
-	CreatedAt   time.Time `json:"created_at"`
-	AccessedAt  time.Time `json:"accessed_at"`
-	AccessCount int       `json:"access_count"`
+	Key         ResultKey  `json:"key"`
+	ContentHash string     `json:"content_hash"` // Hash of content for deduplication
+	Summary     string     `json:"summary"`      // First N characters or lines
+	LineCount   int        `json:"line_count"`   // Total lines in content
+	ByteSize    int        `json:"byte_size"`    // Size in bytes
+	CreatedAt   time.Time  `json:"created_at"`
+	AccessedAt  time.Time  `json:"accessed_at"`
+	AccessCount int        `json:"access_count"`
+	Tags        ResultTags `json:"tags"`             // Set at Store time
+	Pinned      bool       `json:"pinned,omitempty"` // Protected from Delete and DeleteSession
 }
 
 // Result contains the full stored content with metadata.
@@ -35,6 +37,19 @@ type Result struct {
 	Content  string // Full content
 }
 
+// ResultVersion describes one version of a key's content. A key gets a new
+// version each time it is stored with different content.
+type ResultVersion struct {
+	Key         ResultKey `json:"key"`
+	Version     int       `json:"version"` // 1 for the first content stored under the key
+	ContentHash string    `json:"content_hash"`
+	Summary     string    `json:"summary"`
+	LineCount   int       `json:"line_count"`
+	ByteSize    int       `json:"byte_size"`
+	StoredAt    time.Time `json:"stored_at"`
+	Current     bool      `json:"current"` // The key currently holds this version
+}
+
 // SearchMatch represents a pattern match within stored results.
 type SearchMatch struct {
 	Key      ResultKey // Which result this match is in
@@ -48,6 +63,21 @@ type StoreOptions struct {
 	SummaryLength int  // Max characters for summary (default: 200)
 	SummaryLines  int  // Max lines for summary (default: 5)
 	ForceStore    bool // Store even if below threshold
+	// Summary replaces the default leading-lines summary when set
+	// (e.g. a structural outline of source code).
+	Summary string
+	// Summarizer writes the summary when Summary is empty, overriding the
+	// store's (see ResultStore.WithSummarizer).
+	Summarizer Summarizer
+	// Pin pins the key (see ResultStore.Pin).
+	Pin bool
+}
+
+// StoreRequest is one entry of a StoreBatch call.
+type StoreRequest struct {
+	Key     ResultKey
+	Content string
+	Options StoreOptions // Zero values get the defaults, as with Store
 }
 
 // DefaultStoreOptions returns sensible defaults.
@@ -61,8 +91,9 @@ func DefaultStoreOptions() StoreOptions {
 
 // QueryOptions configures result retrieval.
 type QueryOptions struct {
-	Limit  int // Max results to return
-	Offset int // Skip first N results
+	Limit  int        // Max results to return
+	Offset int        // Skip first N results
+	Tags   ResultTags // Only results with these tags (empty fields match anything)
 }
 
 // LineRange specifies a range of lines to retrieve.
@@ -9,23 +9,34 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cespare/xxhash/v2"
 	"github.com/richinex/ariadne/model"
 	"github.com/richinex/ariadne/internal/dsa"
+	"github.com/richinex/ariadne/internal/outline"
+	"github.com/richinex/ariadne/internal/text"
 )
 
+// ErrPinned is returned when deleting a pinned result; unpin it first.
+var ErrPinned = errors.New("result is pinned")
+
 // ResultStoreInterface is the interface for result storage operations.
 type ResultStoreInterface interface {
 	// Store saves content with the given key.
 	// Returns metadata (for lightweight passing to supervisor).
 	Store(ctx context.Context, key ResultKey, content string, opts StoreOptions) (ResultMetadata, error)
 
+	// StoreBatch saves several results in one write.
+	StoreBatch(ctx context.Context, reqs []StoreRequest) ([]ResultMetadata, error)
+
 	// Get retrieves full content by key.
 	Get(ctx context.Context, key ResultKey) (*Result, error)
 
@@ -38,18 +49,34 @@ type ResultStoreInterface interface {
 	// Search finds pattern across all stored content in session.
 	Search(ctx context.Context, sessionID string, pattern string, limit int) ([]SearchMatch, error)
 
-	// GetByPrefix returns all results with keys starting with prefix.
-	GetByPrefix(ctx context.Context, sessionID string, prefix string) ([]ResultMetadata, error)
+	// GetByPrefix returns results with keys starting with prefix, filtered
+	// and paged by opts.
+	GetByPrefix(ctx context.Context, sessionID string, prefix string, opts QueryOptions) ([]ResultMetadata, error)
 
-	// Delete removes a stored result.
+	// Delete removes a stored result. It fails with ErrPinned if the
+	// result is pinned.
 	Delete(ctx context.Context, key ResultKey) error
 
-	// DeleteSession removes all results for a session.
+	// DeleteSession removes all unpinned results for a session.
 	DeleteSession(ctx context.Context, sessionID string) error
 
-	// List returns all result metadata for a session.
+	// Pin protects a stored result from Delete and DeleteSession.
+	Pin(ctx context.Context, key ResultKey) error
+
+	// Unpin removes the protection Pin added.
+	Unpin(ctx context.Context, key ResultKey) error
+
+	// List returns result metadata for a session, filtered and paged by
+	// opts.
 	List(ctx context.Context, sessionID string, opts QueryOptions) ([]ResultMetadata, error)
 
+	// ListVersions returns the versions of a key, newest first.
+	ListVersions(ctx context.Context, key ResultKey) ([]ResultVersion, error)
+
+	// GetVersion retrieves one version of a key's content, or nil if it
+	// doesn't exist.
+	GetVersion(ctx context.Context, key ResultKey, version int) (*Result, error)
+
 	// Close releases resources.
 	Close() error
 }
@@ -65,15 +92,28 @@ type ResultStore struct {
 	keyToHash    map[string]string    // compositeKey -> contentHash for O(1) lookup
 	contentIndex map[string]*Result   // ContentHash -> Result for dedup
 	sessionIndex map[string][]string  // SessionID -> list of keys
+	hashRefs     map[string]int       // ContentHash -> number of keys referencing it
+	versions     map[string]int       // compositeKey -> current version
+	pinned       map[string]bool      // compositeKey -> pinned (survives DeleteSession)
 
 	// Lazy-built suffix array for search
 	searchIndex     *dsa.SuffixArray
 	searchContent   string           // Concatenated content for search
 	searchPositions []searchPosition // Map positions back to results
 	searchDirty     bool             // Need to rebuild search index
+	generation      uint64           // Bumped on every write (see Generation)
+	usage           storeUsage       // Work done since the store was opened (see Usage)
 
 	// SQLite storage for persistence (optional)
 	contentDB ContentStorage
+
+	// Writes summaries when StoreOptions has none (nil: leading lines)
+	summarizer Summarizer
+}
+
+// storeUsage counts a store's work since it was opened.
+type storeUsage struct {
+	stored, indexedBytes, searches, lineReads, cachedReads atomic.Int64
 }
 
 // searchPosition maps suffix array positions to results.
@@ -95,6 +135,9 @@ func NewResultStore(contentDB ContentStorage) (*ResultStore, error) {
 		keyToHash:    make(map[string]string),
 		contentIndex: make(map[string]*Result),
 		sessionIndex: make(map[string][]string),
+		hashRefs:     make(map[string]int),
+		versions:     make(map[string]int),
+		pinned:       make(map[string]bool),
 		searchDirty:  true,
 		contentDB:    contentDB,
 	}
@@ -116,78 +159,171 @@ func NewInMemoryResultStore() *ResultStore {
 		keyToHash:    make(map[string]string),
 		contentIndex: make(map[string]*Result),
 		sessionIndex: make(map[string][]string),
+		hashRefs:     make(map[string]int),
+		versions:     make(map[string]int),
+		pinned:       make(map[string]bool),
 		searchDirty:  true,
 	}
 }
 
+// WithSummarizer sets the summarizer for content stored without a summary
+// or a summarizer of its own. Set it before the store is shared.
+func (s *ResultStore) WithSummarizer(summarizer Summarizer) *ResultStore {
+	s.summarizer = summarizer
+	return s
+}
+
 // Store saves content with the given key.
 // Content is stored in memory for fast access and persisted to SQLite.
 func (s *ResultStore) Store(ctx context.Context, key ResultKey, content string, opts StoreOptions) (ResultMetadata, error) {
-	// Apply defaults for zero values
-	if opts.SummaryLength <= 0 {
-		opts.SummaryLength = 200
-	}
-	if opts.SummaryLines <= 0 {
-		opts.SummaryLines = 5
+	metas, err := s.StoreBatch(ctx, []StoreRequest{{Key: key, Content: content, Options: opts}})
+	if err != nil {
+		return ResultMetadata{}, err
 	}
+	return metas[0], nil
+}
 
-	// Compute content hash for deduplication
-	hash := computeContentHash(content)
-	compositeKey := composeResultKey(key)
+// StoreBatch saves several results at once: the indexes are updated under
+// one lock, the search index is invalidated once, and SQLite is written in
+// a single transaction. Metadata is returned in request order. If the
+// SQLite write fails, nothing is persisted but the in-memory store keeps
+// the batch.
+func (s *ResultStore) StoreBatch(ctx context.Context, reqs []StoreRequest) ([]ResultMetadata, error) {
+	if IsReadOnly(ctx) {
+		return nil, ErrReadOnly
+	}
+	if len(reqs) == 0 {
+		return nil, nil
+	}
 
+	// Summaries may call a model, so write them before taking the lock
+	type pending struct {
+		meta            ResultMetadata
+		content         string
+		explicitSummary bool
+		pin             bool
+	}
+	batch := make([]pending, len(reqs))
 	now := time.Now()
-	meta := ResultMetadata{
-		Key:         key,
-		ContentHash: hash,
-		Summary:     generateResultSummary(content, opts),
-		LineCount:   countResultLines(content),
-		ByteSize:    len(content),
-		CreatedAt:   now,
-		AccessedAt:  now,
-		AccessCount: 1,
+	reqs = slices.Clone(reqs)
+	hashes := make([]string, len(reqs))
+	for i := range reqs {
+		opts := &reqs[i].Options
+		// Apply defaults for zero values
+		if opts.SummaryLength <= 0 {
+			opts.SummaryLength = 200
+		}
+		if opts.SummaryLines <= 0 {
+			opts.SummaryLines = 5
+		}
+		// Compute content hash for deduplication
+		hashes[i] = computeContentHash(reqs[i].Content)
+	}
+	summaries := s.summarizeBatch(ctx, reqs, hashes)
+
+	for i, req := range reqs {
+		opts := req.Options
+		hash := hashes[i]
+
+		explicitSummary := opts.Summary != ""
+		if !explicitSummary {
+			opts.Summary = summaries[i]
+			if opts.Summary == "" {
+				opts.Summary = s.summarize(ctx, req.Key, hash, req.Content, opts)
+			}
+		}
+
+		batch[i] = pending{
+			meta: ResultMetadata{
+				Key:         req.Key,
+				ContentHash: hash,
+				Summary:     opts.Summary,
+				LineCount:   countResultLines(req.Content),
+				ByteSize:    len(req.Content),
+				CreatedAt:   now,
+				AccessedAt:  now,
+				AccessCount: 1,
+				Tags:        ClassifyContent(req.Key.Key, req.Content),
+			},
+			content:         req.Content,
+			explicitSummary: explicitSummary,
+			pin:             opts.Pin,
+		}
 	}
 
 	// Update in-memory indexes under lock
 	s.mu.Lock()
-	if existing, ok := s.contentIndex[hash]; ok {
-		// Content already exists - just add new key mapping
-		existing.Metadata.AccessedAt = now
-		existing.Metadata.AccessCount++
-		meta = existing.Metadata
-		meta.Key = key // Return with requested key
-	} else {
-		result := &Result{
-			Metadata: meta,
-			Content:  content,
+	for i := range batch {
+		p := &batch[i]
+		key, hash := p.meta.Key, p.meta.ContentHash
+		compositeKey := composeResultKey(key)
+		if existing, ok := s.contentIndex[hash]; ok {
+			// Content already exists - just add new key mapping
+			existing.Metadata.AccessedAt = now
+			existing.Metadata.AccessCount++
+			if p.explicitSummary {
+				existing.Metadata.Summary = p.meta.Summary
+			}
+			p.meta = existing.Metadata
+			p.meta.Key = key // Return with requested key
+		} else {
+			s.contentIndex[hash] = &Result{
+				Metadata: p.meta,
+				Content:  p.content,
+			}
+			s.usage.indexedBytes.Add(int64(len(p.content)))
+		}
+		if oldHash, found := s.keyToHash[compositeKey]; !found {
+			s.hashRefs[hash]++
+			s.versions[compositeKey]++
+		} else if oldHash != hash {
+			s.hashRefs[hash]++
+			s.releaseHash(oldHash)
+			s.versions[compositeKey]++
+		}
+		s.keyIndex.Insert(compositeKey, key)
+		s.keyToHash[compositeKey] = hash
+		s.updateSessionIndex(key)
+		if p.pin {
+			s.pinned[compositeKey] = true
 		}
-		s.contentIndex[hash] = result
+		p.meta.Pinned = s.pinned[compositeKey]
 	}
-	s.keyIndex.Insert(compositeKey, key)
-	s.keyToHash[compositeKey] = hash
-	s.updateSessionIndex(key)
 	s.searchDirty = true
+	s.generation++
 	s.mu.Unlock()
+	s.usage.stored.Add(int64(len(batch)))
+
+	metas := make([]ResultMetadata, len(batch))
+	for i, p := range batch {
+		metas[i] = p.meta
+	}
 
 	// Persist to SQLite if available (outside lock)
 	if s.contentDB != nil {
-		result := ContentResult{
-			SessionID:   meta.Key.SessionID,
-			Key:         meta.Key.Key,
-			ContentHash: meta.ContentHash,
-			Content:     content, // Store actual content in SQLite
-			Summary:     meta.Summary,
-			LineCount:   meta.LineCount,
-			ByteSize:    meta.ByteSize,
-			CreatedAt:   meta.CreatedAt.Unix(),
-			AccessedAt:  meta.AccessedAt.Unix(),
-			AccessCount: meta.AccessCount,
+		results := make([]ContentResult, len(batch))
+		for i, p := range batch {
+			results[i] = ContentResult{
+				SessionID:   p.meta.Key.SessionID,
+				Key:         p.meta.Key.Key,
+				ContentHash: p.meta.ContentHash,
+				Content:     p.content, // Store actual content in SQLite
+				Summary:     p.meta.Summary,
+				LineCount:   p.meta.LineCount,
+				ByteSize:    p.meta.ByteSize,
+				CreatedAt:   p.meta.CreatedAt.Unix(),
+				AccessedAt:  p.meta.AccessedAt.Unix(),
+				AccessCount: p.meta.AccessCount,
+				Metadata:    encodeTags(p.meta.Tags),
+				Pinned:      p.meta.Pinned,
+			}
 		}
-		if err := s.contentDB.StoreResult(ctx, result); err != nil {
-			return ResultMetadata{}, fmt.Errorf("failed to persist to SQLite: %w", err)
+		if err := s.contentDB.StoreResults(ctx, results); err != nil {
+			return nil, fmt.Errorf("failed to persist to SQLite: %w", err)
 		}
 	}
 
-	return meta, nil
+	return metas, nil
 }
 
 // Get retrieves full content by key.
@@ -210,6 +346,7 @@ func (s *ResultStore) Get(ctx context.Context, key ResultKey) (*Result, error) {
 
 	metadata := result.Metadata
 	metadata.Key = key // Return with requested key
+	metadata.Pinned = s.pinned[compositeKey]
 	content := result.Content
 	s.mu.RUnlock()
 
@@ -256,6 +393,7 @@ func (s *ResultStore) GetMetadata(ctx context.Context, key ResultKey) (*ResultMe
 
 	meta := result.Metadata
 	meta.Key = key // Return with requested key
+	meta.Pinned = s.pinned[compositeKey]
 	s.mu.RUnlock()
 
 	return &meta, nil
@@ -263,6 +401,7 @@ func (s *ResultStore) GetMetadata(ctx context.Context, key ResultKey) (*ResultMe
 
 // GetLines retrieves a specific line range from stored content.
 func (s *ResultStore) GetLines(ctx context.Context, key ResultKey, lineRange LineRange) (string, error) {
+	s.usage.lineReads.Add(1)
 	result, err := s.Get(ctx, key)
 	if err != nil {
 		return "", err
@@ -290,6 +429,7 @@ func (s *ResultStore) GetLines(ctx context.Context, key ResultKey, lineRange Lin
 
 // Search finds pattern across all stored content in session.
 func (s *ResultStore) Search(ctx context.Context, sessionID string, pattern string, limit int) ([]SearchMatch, error) {
+	s.usage.searches.Add(1)
 	// Check if rebuild needed
 	s.mu.RLock()
 	needsRebuild := s.searchDirty
@@ -353,8 +493,9 @@ func (s *ResultStore) Search(ctx context.Context, sessionID string, pattern stri
 	return matches, nil
 }
 
-// GetByPrefix returns all results with keys starting with prefix.
-func (s *ResultStore) GetByPrefix(ctx context.Context, sessionID string, prefix string) ([]ResultMetadata, error) {
+// GetByPrefix returns results with keys starting with prefix, filtered
+// and paged by opts.
+func (s *ResultStore) GetByPrefix(ctx context.Context, sessionID string, prefix string, opts QueryOptions) ([]ResultMetadata, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -374,18 +515,27 @@ func (s *ResultStore) GetByPrefix(ctx context.Context, sessionID string, prefix
 			continue
 		}
 
+		if !result.Metadata.Tags.Matches(opts.Tags) {
+			continue
+		}
+
 		// Get the actual key for this composite key
 		rk, _ := s.keyIndex.Search(compositeKey)
 		meta := result.Metadata
 		meta.Key = rk // Return with the requested key
+		meta.Pinned = s.pinned[compositeKey]
 		results = append(results, meta)
 	}
 
-	return results, nil
+	return page(results, opts), nil
 }
 
-// Delete removes a stored result.
+// Delete removes a stored result. It fails with ErrPinned if the result
+// is pinned.
 func (s *ResultStore) Delete(ctx context.Context, key ResultKey) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnly
+	}
 	compositeKey := composeResultKey(key)
 
 	// Get hash under lock
@@ -395,9 +545,13 @@ func (s *ResultStore) Delete(ctx context.Context, key ResultKey) error {
 		s.mu.Unlock()
 		return nil
 	}
+	if s.pinned[compositeKey] {
+		s.mu.Unlock()
+		return fmt.Errorf("delete %s: %w", key.Key, ErrPinned)
+	}
 
-	// Remove from indexes
-	delete(s.contentIndex, hash)
+	// Remove from indexes; content shared with other keys is kept
+	s.releaseHash(hash)
 	delete(s.keyToHash, compositeKey)
 	s.keyIndex.Delete(compositeKey)
 
@@ -412,6 +566,7 @@ func (s *ResultStore) Delete(ctx context.Context, key ResultKey) error {
 	}
 
 	s.searchDirty = true
+	s.generation++
 	s.mu.Unlock()
 
 	// Delete from SQLite outside lock
@@ -424,8 +579,12 @@ func (s *ResultStore) Delete(ctx context.Context, key ResultKey) error {
 	return nil
 }
 
-// DeleteSession removes all results for a session.
+// DeleteSession removes all unpinned results for a session. Pinned
+// results stay in the session.
 func (s *ResultStore) DeleteSession(ctx context.Context, sessionID string) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnly
+	}
 	s.mu.Lock()
 	keys, ok := s.sessionIndex[sessionID]
 	if !ok {
@@ -433,19 +592,29 @@ func (s *ResultStore) DeleteSession(ctx context.Context, sessionID string) error
 		return nil
 	}
 
+	var kept []string
 	for _, key := range keys {
 		rk := ResultKey{SessionID: sessionID, Key: key}
 		compositeKey := composeResultKey(rk)
+		if s.pinned[compositeKey] {
+			kept = append(kept, key)
+			continue
+		}
 
 		if hash, found := s.keyToHash[compositeKey]; found {
-			delete(s.contentIndex, hash)
+			s.releaseHash(hash)
 			delete(s.keyToHash, compositeKey)
 		}
 		s.keyIndex.Delete(compositeKey)
 	}
 
-	delete(s.sessionIndex, sessionID)
+	if len(kept) > 0 {
+		s.sessionIndex[sessionID] = kept
+	} else {
+		delete(s.sessionIndex, sessionID)
+	}
 	s.searchDirty = true
+	s.generation++
 	s.mu.Unlock()
 
 	// Delete from SQLite outside lock
@@ -458,30 +627,169 @@ func (s *ResultStore) DeleteSession(ctx context.Context, sessionID string) error
 	return nil
 }
 
-// List returns all result metadata for a session.
+// Pin protects a stored result from Delete and DeleteSession, so it
+// outlives the run's session cleanup. Storing new content under the key
+// keeps it pinned.
+func (s *ResultStore) Pin(ctx context.Context, key ResultKey) error {
+	return s.setPinned(ctx, key, true)
+}
+
+// Unpin removes the protection Pin added.
+func (s *ResultStore) Unpin(ctx context.Context, key ResultKey) error {
+	return s.setPinned(ctx, key, false)
+}
+
+func (s *ResultStore) setPinned(ctx context.Context, key ResultKey, pinned bool) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnly
+	}
+	compositeKey := composeResultKey(key)
+
+	s.mu.Lock()
+	if _, found := s.keyToHash[compositeKey]; !found {
+		s.mu.Unlock()
+		return fmt.Errorf("no stored result %q", key.Key)
+	}
+	if pinned {
+		s.pinned[compositeKey] = true
+	} else {
+		delete(s.pinned, compositeKey)
+	}
+	s.mu.Unlock()
+
+	if s.contentDB != nil {
+		if err := s.contentDB.SetResultPinned(ctx, key.SessionID, key.Key, pinned); err != nil {
+			return fmt.Errorf("failed to persist pin: %w", err)
+		}
+	}
+	return nil
+}
+
+// List returns result metadata for a session, filtered and paged by opts.
 func (s *ResultStore) List(ctx context.Context, sessionID string, opts QueryOptions) ([]ResultMetadata, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var results []ResultMetadata
-	for _, result := range s.contentIndex {
-		if result.Metadata.Key.SessionID == sessionID {
-			results = append(results, result.Metadata)
+	for _, key := range s.sessionIndex[sessionID] {
+		rk := ResultKey{SessionID: sessionID, Key: key}
+		result, ok := s.contentIndex[s.keyToHash[composeResultKey(rk)]]
+		if !ok || !result.Metadata.Tags.Matches(opts.Tags) {
+			continue
 		}
+		meta := result.Metadata
+		meta.Key = rk // Content may be shared with keys in other sessions
+		meta.Pinned = s.pinned[composeResultKey(rk)]
+		results = append(results, meta)
 	}
 
-	// Apply pagination
+	return page(results, opts), nil
+}
+
+// page applies opts.Offset and opts.Limit to results.
+func page(results []ResultMetadata, opts QueryOptions) []ResultMetadata {
 	if opts.Offset > 0 && opts.Offset < len(results) {
 		results = results[opts.Offset:]
 	} else if opts.Offset >= len(results) {
-		return []ResultMetadata{}, nil
+		return []ResultMetadata{}
 	}
 
 	if opts.Limit > 0 && opts.Limit < len(results) {
 		results = results[:opts.Limit]
 	}
+	return results
+}
 
-	return results, nil
+// ListVersions returns the versions of a key, newest first. With SQLite
+// persistence every version is kept, including those of deleted keys;
+// without it only the current version is available.
+//
+// The in-memory version counter is authoritative only without SQLite;
+// with it, version numbers come from the database.
+func (s *ResultStore) ListVersions(ctx context.Context, key ResultKey) ([]ResultVersion, error) {
+	compositeKey := composeResultKey(key)
+
+	s.mu.RLock()
+	currentHash, found := s.keyToHash[compositeKey]
+	var current *ResultVersion
+	if result, ok := s.contentIndex[currentHash]; found && ok {
+		current = &ResultVersion{
+			Key:         key,
+			Version:     s.versions[compositeKey],
+			ContentHash: currentHash,
+			Summary:     result.Metadata.Summary,
+			LineCount:   result.Metadata.LineCount,
+			ByteSize:    result.Metadata.ByteSize,
+			StoredAt:    result.Metadata.CreatedAt,
+			Current:     true,
+		}
+	}
+	s.mu.RUnlock()
+
+	if s.contentDB == nil {
+		if current == nil {
+			return nil, nil
+		}
+		return []ResultVersion{*current}, nil
+	}
+
+	stored, err := s.contentDB.ListResultVersions(ctx, key.SessionID, key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	versions := make([]ResultVersion, 0, len(stored))
+	for i, r := range stored {
+		versions = append(versions, ResultVersion{
+			Key:         key,
+			Version:     r.Version,
+			ContentHash: r.ContentHash,
+			Summary:     r.Summary,
+			LineCount:   r.LineCount,
+			ByteSize:    r.ByteSize,
+			StoredAt:    time.Unix(r.CreatedAt, 0),
+			Current:     i == 0 && current != nil && r.ContentHash == current.ContentHash,
+		})
+	}
+	return versions, nil
+}
+
+// GetVersion retrieves one version of a key's content, or nil if it
+// doesn't exist. Unlike Get, it does not count as an access.
+func (s *ResultStore) GetVersion(ctx context.Context, key ResultKey, version int) (*Result, error) {
+	if s.contentDB == nil {
+		compositeKey := composeResultKey(key)
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		hash, found := s.keyToHash[compositeKey]
+		result, ok := s.contentIndex[hash]
+		if !found || !ok || s.versions[compositeKey] != version {
+			return nil, nil
+		}
+		current := Result{Metadata: result.Metadata, Content: result.Content}
+		current.Metadata.Key = key
+		return &current, nil
+	}
+
+	r, err := s.contentDB.GetResultVersion(ctx, key.SessionID, key.Key, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+	if r == nil {
+		return nil, nil
+	}
+	storedAt := time.Unix(r.CreatedAt, 0)
+	return &Result{
+		Metadata: ResultMetadata{
+			Key:         key,
+			ContentHash: r.ContentHash,
+			Summary:     r.Summary,
+			LineCount:   r.LineCount,
+			ByteSize:    r.ByteSize,
+			CreatedAt:   storedAt,
+			AccessedAt:  storedAt,
+		},
+		Content: r.Content,
+	}, nil
 }
 
 // Close releases resources including the ContentStorage.
@@ -491,6 +799,8 @@ func (s *ResultStore) Close() error {
 	s.contentIndex = nil
 	s.keyToHash = nil
 	s.sessionIndex = nil
+	s.hashRefs = nil
+	s.versions = nil
 	s.searchIndex = nil
 	s.searchContent = ""
 	s.searchPositions = nil
@@ -509,6 +819,12 @@ func (s *ResultStore) Close() error {
 
 // Helper functions
 
+// ContentHash returns the hash Store records for content, so callers can
+// detect unchanged content without re-storing it.
+func ContentHash(content string) string {
+	return computeContentHash(content)
+}
+
 // computeContentHash uses xxHash for fast, high-quality content hashing.
 // xxHash is non-cryptographic but ideal for deduplication (10-30x faster than SHA256).
 // See: https://github.com/cespare/xxhash
@@ -519,6 +835,68 @@ func computeContentHash(content string) string {
 	return hex.EncodeToString(buf[:])
 }
 
+// summarizeBatch summarizes the requests that use the store's summarizer
+// together, if it is a BatchSummarizer and more than one needs a summary.
+// Other requests (and all of them, if the batch fails) get "" and are
+// summarized one at a time.
+func (s *ResultStore) summarizeBatch(ctx context.Context, reqs []StoreRequest, hashes []string) []string {
+	summaries := make([]string, len(reqs))
+	batcher, ok := s.summarizer.(BatchSummarizer)
+	if !ok {
+		return summaries
+	}
+	var pending []StoreRequest
+	var indexes []int
+	s.mu.RLock()
+	for i, req := range reqs {
+		if req.Options.Summary != "" || req.Options.Summarizer != nil {
+			continue
+		}
+		if _, stored := s.contentIndex[hashes[i]]; stored {
+			continue // Replaced by the stored summary
+		}
+		pending = append(pending, req)
+		indexes = append(indexes, i)
+	}
+	s.mu.RUnlock()
+	if len(pending) < 2 {
+		return summaries
+	}
+
+	batched, err := batcher.SummarizeBatch(ctx, pending)
+	if err != nil || len(batched) != len(pending) {
+		return summaries
+	}
+	for j, i := range indexes {
+		summaries[i] = batched[j]
+	}
+	return summaries
+}
+
+// summarize writes the summary of content with the options' or the
+// store's summarizer. Content already stored keeps its summary, so the
+// summarizer (possibly an LLM call) is skipped for it.
+func (s *ResultStore) summarize(ctx context.Context, key ResultKey, hash, content string, opts StoreOptions) string {
+	summarizer := opts.Summarizer
+	if summarizer == nil {
+		summarizer = s.summarizer
+	}
+	if summarizer == nil {
+		return generateResultSummary(content, opts)
+	}
+	s.mu.RLock()
+	_, stored := s.contentIndex[hash]
+	s.mu.RUnlock()
+	if stored {
+		return generateResultSummary(content, opts) // Replaced by the stored summary
+	}
+	summary, err := summarizer.Summarize(ctx, key, content, opts)
+	if err != nil || summary == "" {
+		return generateResultSummary(content, opts)
+	}
+	return summary
+}
+
 func generateResultSummary(content string, opts StoreOptions) string {
 	lines := strings.Split(content, "\n")
 
@@ -544,11 +922,7 @@ func generateResultSummary(content string, opts StoreOptions) string {
 		lineCount++
 	}
 
-	s := summary.String()
-	if len(s) > maxLen {
-		s = s[:maxLen] + "..."
-	}
-	return s
+	return text.Truncate(summary.String(), maxLen)
 }
 
 func countResultLines(content string) int {
@@ -562,6 +936,16 @@ func composeResultKey(key ResultKey) string {
 	return key.SessionID + ":" + key.Key
 }
 
+// releaseHash drops one key's reference to content, removing the content
+// once no key references it. Caller holds s.mu.
+func (s *ResultStore) releaseHash(hash string) {
+	s.hashRefs[hash]--
+	if s.hashRefs[hash] <= 0 {
+		delete(s.hashRefs, hash)
+		delete(s.contentIndex, hash)
+	}
+}
+
 func (s *ResultStore) updateSessionIndex(key ResultKey) {
 	keys := s.sessionIndex[key.SessionID]
 	for _, k := range keys {
@@ -582,12 +966,14 @@ func (s *ResultStore) rebuildSearchIndexForSession(sessionID string) error {
 	var items []indexItem
 
 	s.mu.RLock()
-	for _, result := range s.contentIndex {
-		if result.Metadata.Key.SessionID != sessionID {
+	for _, key := range s.sessionIndex[sessionID] {
+		rk := ResultKey{SessionID: sessionID, Key: key}
+		result, ok := s.contentIndex[s.keyToHash[composeResultKey(rk)]]
+		if !ok {
 			continue
 		}
 		items = append(items, indexItem{
-			key:     result.Metadata.Key,
+			key:     rk,
 			content: result.Content,
 		})
 	}
@@ -650,6 +1036,11 @@ func (s *ResultStore) loadFromContentStorage() error {
 			AccessedAt:  time.Unix(r.AccessedAt, 0),
 			AccessCount: r.AccessCount,
 		}
+		tags, ok := decodeTags(r.Metadata)
+		if !ok {
+			tags = ClassifyContent(r.Key, r.Content) // Stored before tagging
+		}
+		meta.Tags = tags
 		result := &Result{
 			Metadata: meta,
 			Content:  r.Content, // Content loaded from SQLite
@@ -657,8 +1048,15 @@ func (s *ResultStore) loadFromContentStorage() error {
 		s.contentIndex[meta.ContentHash] = result
 
 		compositeKey := composeResultKey(meta.Key)
+		if _, found := s.keyToHash[compositeKey]; !found {
+			s.hashRefs[meta.ContentHash]++
+		}
 		s.keyIndex.Insert(compositeKey, meta.Key)
 		s.keyToHash[compositeKey] = meta.ContentHash
+		s.versions[compositeKey] = r.Version
+		if r.Pinned {
+			s.pinned[compositeKey] = true
+		}
 		s.updateSessionIndex(meta.Key)
 	}
 
@@ -671,12 +1069,67 @@ func (s *ResultStore) loadFromContentStorage() error {
 // This allows tools to store content and return references.
 func (s *ResultStore) StoreContent(ctx context.Context, key model.ContentKey, content string) (model.StoredContent, error) {
 	// Map ContentKey to ResultKey, using content type as session for isolation
+	return s.storeContent(ctx, key.ContentType, key, content)
+}
+
+// Usage returns what the store did since it was opened: results stored,
+// bytes of new content indexed, searches, line reads, and reads callers
+// answered from their own cache (see CountCachedRead).
+func (s *ResultStore) Usage() model.DSAUsage {
+	return model.DSAUsage{
+		FilesStored:   int(s.usage.stored.Load()),
+		BytesIndexed:  s.usage.indexedBytes.Load(),
+		Searches:      int(s.usage.searches.Load()),
+		CacheHits:     int(s.usage.cachedReads.Load()),
+		GetLinesCalls: int(s.usage.lineReads.Load()),
+	}
+}
+
+// CountCachedRead records a search or line read a caller answered from its
+// cache instead of the store.
+func (s *ResultStore) CountCachedRead() {
+	s.usage.cachedReads.Add(1)
+}
+
+// Generation returns a counter that changes whenever stored content is
+// added, replaced or deleted, so callers can tell if cached reads are stale.
+func (s *ResultStore) Generation() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.generation
+}
+
+// SessionContent returns a model.ContentStore that stores everything in
+// sessionID, so a run's files are kept apart from other runs sharing the
+// database and are found by DSA tools scoped to the same session.
+func (s *ResultStore) SessionContent(sessionID string) model.ContentStore {
+	return sessionContentStore{store: s, sessionID: sessionID}
+}
+
+type sessionContentStore struct {
+	store     *ResultStore
+	sessionID string
+}
+
+func (c sessionContentStore) StoreContent(ctx context.Context, key model.ContentKey, content string) (model.StoredContent, error) {
+	return c.store.storeContent(ctx, c.sessionID, key, content)
+}
+
+func (s *ResultStore) storeContent(ctx context.Context, sessionID string, key model.ContentKey, content string) (model.StoredContent, error) {
 	resultKey := ResultKey{
-		SessionID: key.ContentType,
+		SessionID: sessionID,
 		Key:       key.Path,
 	}
 
-	meta, err := s.Store(ctx, resultKey, content, DefaultStoreOptions())
+	// Source files are summarized by structure rather than leading lines
+	opts := DefaultStoreOptions()
+	if key.ContentType == "file" {
+		if o, ok := outline.Parse(key.Path, content); ok {
+			opts.Summary = o.String()
+		}
+	}
+
+	meta, err := s.Store(ctx, resultKey, content, opts)
 	if err != nil {
 		return model.StoredContent{}, err
 	}
@@ -686,5 +1139,6 @@ func (s *ResultStore) StoreContent(ctx context.Context, key model.ContentKey, co
 		Lines:     meta.LineCount,
 		Bytes:     meta.ByteSize,
 		Preview:   meta.Summary,
+		Outline:   opts.Summary != "",
 	}, nil
 }
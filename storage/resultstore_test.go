@@ -2,10 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/richinex/ariadne/model"
 )
 
 func TestInMemoryResultStore(t *testing.T) {
@@ -139,7 +142,7 @@ func TestResultStoreGetByPrefix(t *testing.T) {
 	_, _ = store.Store(ctx, ResultKey{SessionID: "test", Key: "src/util.go"}, "content2", DefaultStoreOptions())
 	_, _ = store.Store(ctx, ResultKey{SessionID: "test", Key: "test/main_test.go"}, "content3", DefaultStoreOptions())
 
-	results, err := store.GetByPrefix(ctx, "test", "src/")
+	results, err := store.GetByPrefix(ctx, "test", "src/", QueryOptions{})
 	if err != nil {
 		t.Fatalf("GetByPrefix failed: %v", err)
 	}
@@ -356,3 +359,330 @@ func TestResultStoreList(t *testing.T) {
 		t.Errorf("expected 2 results with offset, got %d", len(list))
 	}
 }
+
+func TestResultStoreSharedContentAcrossSessions(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+
+	ctx := context.Background()
+
+	pack := ResultKey{SessionID: "context/docs", Key: "guide.md"}
+	run := ResultKey{SessionID: "file", Key: "guide.md"}
+	_, _ = store.Store(ctx, pack, "shared guide content", DefaultStoreOptions())
+	_, _ = store.Store(ctx, run, "shared guide content", DefaultStoreOptions())
+
+	for _, session := range []string{"context/docs", "file"} {
+		list, _ := store.List(ctx, session, QueryOptions{})
+		if len(list) != 1 || list[0].Key.SessionID != session {
+			t.Errorf("expected one entry listed under %s, got %v", session, list)
+		}
+	}
+
+	matches, _ := store.Search(ctx, "file", "guide", 10)
+	if len(matches) != 1 || matches[0].Key != run {
+		t.Errorf("expected one match in run session, got %v", matches)
+	}
+
+	// Deleting one session must not drop content still referenced by another
+	_ = store.DeleteSession(ctx, "file")
+	result, _ := store.Get(ctx, pack)
+	if result == nil || result.Content != "shared guide content" {
+		t.Fatalf("expected pack content to survive deleting run session, got %v", result)
+	}
+
+	_ = store.Delete(ctx, pack)
+	if result, _ := store.Get(ctx, pack); result != nil {
+		t.Error("expected content removed after last reference deleted")
+	}
+}
+
+func TestStoreContentOutlinesSourceFiles(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+
+	ctx := context.Background()
+
+	stored, err := store.StoreContent(ctx, model.FileKey("pkg/util.go"), "package util\n\nimport \"strings\"\n\nfunc Upper(s string) string {\n\treturn strings.ToUpper(s)\n}\n")
+	if err != nil {
+		t.Fatalf("StoreContent failed: %v", err)
+	}
+	if !stored.Outline || !strings.Contains(stored.Preview, "func Upper L5-7") {
+		t.Errorf("expected Go outline preview, got %q", stored.Preview)
+	}
+
+	stored, _ = store.StoreContent(ctx, model.FileKey("notes.txt"), "line one\nline two")
+	if stored.Outline || stored.Preview != "line one\nline two" {
+		t.Errorf("expected leading-lines preview for text, got %+v", stored)
+	}
+}
+
+func TestSessionContentIsolatesRuns(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+
+	ctx := context.Background()
+
+	if _, err := store.SessionContent("run-a").StoreContent(ctx, model.FileKey("main.go"), "package a\n"); err != nil {
+		t.Fatalf("StoreContent failed: %v", err)
+	}
+	if _, err := store.SessionContent("run-b").StoreContent(ctx, model.FileKey("main.go"), "package b\n"); err != nil {
+		t.Fatalf("StoreContent failed: %v", err)
+	}
+
+	for session, want := range map[string]string{"run-a": "package a\n", "run-b": "package b\n"} {
+		result, err := store.Get(ctx, ResultKey{SessionID: session, Key: "main.go"})
+		if err != nil || result == nil || result.Content != want {
+			t.Errorf("%s: got %+v, %v; want %q", session, result, err, want)
+		}
+	}
+
+	if err := store.DeleteSession(ctx, "run-a"); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if left, _ := store.List(ctx, "run-b", QueryOptions{}); len(left) != 1 {
+		t.Errorf("deleting one run's session touched another: %d left", len(left))
+	}
+}
+
+func TestResultStoreVersionHistory(t *testing.T) {
+	db, err := OpenSqlite(filepath.Join(t.TempDir(), "versions.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	store, err := NewResultStore(db)
+	if err != nil {
+		db.Close()
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	key := ResultKey{SessionID: "s", Key: "notes.md"}
+	for _, content := range []string{"first draft", "second draft", "second draft"} {
+		if _, err := store.Store(ctx, key, content, DefaultStoreOptions()); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+	}
+
+	versions, err := store.ListVersions(ctx, key)
+	if err != nil {
+		t.Fatalf("ListVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 || !versions[0].Current || versions[1].Current {
+		t.Fatalf("unexpected versions (identical content must not add one): %+v", versions)
+	}
+
+	old, err := store.GetVersion(ctx, key, 1)
+	if err != nil || old == nil || old.Content != "first draft" {
+		t.Fatalf("GetVersion(1) = %+v, %v", old, err)
+	}
+	if missing, _ := store.GetVersion(ctx, key, 9); missing != nil {
+		t.Errorf("GetVersion(9) = %+v, want nil", missing)
+	}
+
+	// History outlives the key, and numbering continues after it
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Store(ctx, key, "third draft", DefaultStoreOptions()); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	versions, _ = store.ListVersions(ctx, key)
+	if len(versions) != 3 || versions[0].Version != 3 || !versions[0].Current {
+		t.Errorf("versions after delete and re-store: %+v", versions)
+	}
+
+	if err := store.DeleteSession(ctx, "s"); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if versions, _ = store.ListVersions(ctx, key); len(versions) != 0 {
+		t.Errorf("DeleteSession kept %d versions", len(versions))
+	}
+}
+
+func TestInMemoryResultStoreVersions(t *testing.T) {
+	store := NewInMemoryResultStore()
+	ctx := context.Background()
+	key := ResultKey{SessionID: "s", Key: "a"}
+	store.Store(ctx, key, "one", DefaultStoreOptions())
+	store.Store(ctx, key, "two", DefaultStoreOptions())
+
+	versions, _ := store.ListVersions(ctx, key)
+	if len(versions) != 1 || versions[0].Version != 2 {
+		t.Fatalf("expected only the current version 2, got %+v", versions)
+	}
+	if result, _ := store.GetVersion(ctx, key, 2); result == nil || result.Content != "two" {
+		t.Errorf("GetVersion(2) = %+v", result)
+	}
+	if result, _ := store.GetVersion(ctx, key, 1); result != nil {
+		t.Error("in-memory store should not keep old versions")
+	}
+}
+
+func TestResultStorePinnedSurviveCleanup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins.db")
+	db, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	store, err := NewResultStore(db)
+	if err != nil {
+		db.Close()
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	ctx := context.Background()
+	spec := ResultKey{SessionID: "run", Key: "spec.md"}
+	_, _ = store.Store(ctx, spec, "the task", DefaultStoreOptions())
+	_, _ = store.Store(ctx, ResultKey{SessionID: "run", Key: "scratch"}, "temp", DefaultStoreOptions())
+	findings := ResultKey{SessionID: "run", Key: "findings"}
+	opts := DefaultStoreOptions()
+	opts.Pin = true
+	if meta, _ := store.Store(ctx, findings, "race in pool.go", opts); !meta.Pinned {
+		t.Error("StoreOptions.Pin should pin the key")
+	}
+
+	if err := store.Pin(ctx, spec); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	if err := store.Pin(ctx, ResultKey{SessionID: "run", Key: "missing"}); err == nil {
+		t.Error("pinning a missing key should fail")
+	}
+	if err := store.Delete(ctx, spec); !errors.Is(err, ErrPinned) {
+		t.Errorf("Delete of a pinned key = %v, want ErrPinned", err)
+	}
+
+	if err := store.DeleteSession(ctx, "run"); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	kept, _ := store.List(ctx, "run", QueryOptions{})
+	if len(kept) != 2 {
+		t.Fatalf("expected the 2 pinned keys to survive, got %+v", kept)
+	}
+	store.Close()
+
+	// Pins are persisted, and the pinned rows survive in SQLite
+	db, err = OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	store, err = NewResultStore(db)
+	if err != nil {
+		db.Close()
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	meta, _ := store.GetMetadata(ctx, spec)
+	if meta == nil || !meta.Pinned {
+		t.Fatalf("reloaded spec = %+v, want pinned", meta)
+	}
+	if versions, _ := store.ListVersions(ctx, spec); len(versions) != 1 {
+		t.Errorf("pinned key lost its history: %+v", versions)
+	}
+
+	if err := store.Unpin(ctx, spec); err != nil {
+		t.Fatalf("Unpin failed: %v", err)
+	}
+	if err := store.Delete(ctx, spec); err != nil {
+		t.Errorf("Delete after Unpin failed: %v", err)
+	}
+}
+
+func TestResultStoreStoreBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.db")
+	db, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	store, err := NewResultStore(db)
+	if err != nil {
+		db.Close()
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	ctx := context.Background()
+	before := store.Generation()
+	reqs := []StoreRequest{
+		{Key: ResultKey{SessionID: "s", Key: "a.go"}, Content: "package a"},
+		{Key: ResultKey{SessionID: "s", Key: "b.go"}, Content: "package b\n\nfunc B() {}"},
+		{Key: ResultKey{SessionID: "s", Key: "copy.go"}, Content: "package a"},
+		{Key: ResultKey{SessionID: "s", Key: "a.go"}, Content: "package a // v2"},
+	}
+	metas, err := store.StoreBatch(ctx, reqs)
+	if err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+	if len(metas) != len(reqs) || metas[1].Key.Key != "b.go" || metas[1].LineCount != 3 {
+		t.Fatalf("unexpected metadata: %+v", metas)
+	}
+	if metas[0].ContentHash != metas[2].ContentHash {
+		t.Error("identical content should share a hash")
+	}
+	if store.Generation() != before+1 {
+		t.Errorf("generation moved by %d, want 1 for the whole batch", store.Generation()-before)
+	}
+	if matches, _ := store.Search(ctx, "s", "func B", 10); len(matches) != 1 {
+		t.Errorf("batch content not searchable: %+v", matches)
+	}
+	// Identical content is indexed once
+	indexed := int64(len("package a") + len("package b\n\nfunc B() {}") + len("package a // v2"))
+	if usage := store.Usage(); usage.FilesStored != 4 || usage.BytesIndexed != indexed || usage.Searches != 1 {
+		t.Errorf("usage = %+v, want 4 stored, %d bytes indexed, 1 search", usage, indexed)
+	}
+	store.Close()
+
+	// The batch was persisted, with a version per content change
+	db, err = OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	store, err = NewResultStore(db)
+	if err != nil {
+		db.Close()
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	if all, _ := store.List(ctx, "s", QueryOptions{}); len(all) != 3 {
+		t.Errorf("expected 3 keys after reload, got %+v", all)
+	}
+	versions, _ := store.ListVersions(ctx, ResultKey{SessionID: "s", Key: "a.go"})
+	if len(versions) != 2 || !versions[0].Current {
+		t.Errorf("a.go versions = %+v", versions)
+	}
+	if result, _ := store.Get(ctx, ResultKey{SessionID: "s", Key: "a.go"}); result == nil || result.Content != "package a // v2" {
+		t.Errorf("a.go = %+v", result)
+	}
+}
+
+func TestResultStoreReadOnlyContext(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+
+	ctx := context.Background()
+	key := ResultKey{SessionID: "s", Key: "a.txt"}
+	if _, err := store.Store(ctx, key, "parent content", DefaultStoreOptions()); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	ro := WithReadOnly(ctx)
+	if _, err := store.Store(ro, key, "child content", DefaultStoreOptions()); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Store = %v, want ErrReadOnly", err)
+	}
+	for name, err := range map[string]error{
+		"Delete":        store.Delete(ro, key),
+		"DeleteSession": store.DeleteSession(ro, "s"),
+		"Pin":           store.Pin(ro, key),
+	} {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s = %v, want ErrReadOnly", name, err)
+		}
+	}
+
+	// Reads are unaffected
+	if result, _ := store.Get(ro, key); result == nil || result.Content != "parent content" {
+		t.Errorf("Get = %+v", result)
+	}
+	if matches, _ := store.Search(ro, "s", "parent", 10); len(matches) != 1 {
+		t.Errorf("Search found %d matches, want 1", len(matches))
+	}
+}
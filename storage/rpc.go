@@ -0,0 +1,242 @@
+// Local RPC access to a ResultStore.
+//
+// ServeRPC shares one ResultStore with other processes on the machine (MCP
+// servers, external workers) over a unix socket, so they reuse its content
+// index instead of ingesting the same files again. The protocol is JSON-RPC
+// 1.0 (net/rpc/jsonrpc): one JSON object per request with "method" set to
+// "ResultStore.Store", "ResultStore.Get" or "ResultStore.Search" and
+// "params" holding a single RPCStoreArgs, RPCGetArgs or RPCSearchArgs.
+// Go callers use DialRPC.
+//
+// Information Hiding:
+// - Socket setup, permissions and stale-socket cleanup hidden
+// - net/rpc service registration and codec hidden
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RPCStoreArgs are the parameters of ResultStore.Store.
+type RPCStoreArgs struct {
+	Key     ResultKey
+	Content string
+	Summary string // Optional: replaces the store's summary
+	Pin     bool   // Pin the key (see ResultStore.Pin)
+}
+
+// RPCGetArgs are the parameters of ResultStore.Get.
+type RPCGetArgs struct {
+	Key ResultKey
+}
+
+// RPCGetReply is the result of ResultStore.Get. Found is false if the key
+// holds nothing.
+type RPCGetReply struct {
+	Found  bool
+	Result Result
+}
+
+// RPCSearchArgs are the parameters of ResultStore.Search.
+type RPCSearchArgs struct {
+	SessionID string
+	Pattern   string
+	Limit     int
+}
+
+// rpcService is registered as "ResultStore". Its exported methods are the
+// RPC methods; calls run with the ServeRPC context, so a context from
+// WithReadOnly makes the socket read-only.
+type rpcService struct {
+	ctx   context.Context
+	store ResultStoreInterface
+}
+
+func (s *rpcService) Store(args *RPCStoreArgs, reply *ResultMetadata) error {
+	opts := DefaultStoreOptions()
+	opts.Summary = args.Summary
+	opts.Pin = args.Pin
+	meta, err := s.store.Store(s.ctx, args.Key, args.Content, opts)
+	if err != nil {
+		return err
+	}
+	*reply = meta
+	return nil
+}
+
+func (s *rpcService) Get(args *RPCGetArgs, reply *RPCGetReply) error {
+	result, err := s.store.Get(s.ctx, args.Key)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		*reply = RPCGetReply{Found: true, Result: *result}
+	}
+	return nil
+}
+
+func (s *rpcService) Search(args *RPCSearchArgs, reply *[]SearchMatch) error {
+	matches, err := s.store.Search(s.ctx, args.SessionID, args.Pattern, args.Limit)
+	if err != nil {
+		return err
+	}
+	*reply = matches
+	return nil
+}
+
+// ServeRPC serves store on a unix socket at socketPath until ctx is done.
+// A stale socket left by a crashed server is replaced; a live one is an
+// error. The socket is readable and writable by the owner only and is
+// removed on return. It is never reachable with looser permissions: it is
+// created in a private directory, restricted, then moved into place.
+func ServeRPC(ctx context.Context, store ResultStoreInterface, socketPath string) error {
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s: a result store is already served there", socketPath)
+	}
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := listenPrivate(socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("ResultStore", &rpcService{ctx: ctx, store: store}); err != nil {
+		listener.Close()
+		return err
+	}
+
+	var conns sync.WaitGroup
+	var mu sync.Mutex
+	open := make(map[net.Conn]struct{})
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		mu.Lock()
+		for conn := range open {
+			conn.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			conns.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept failed: %w", err)
+		}
+		mu.Lock()
+		open[conn] = struct{}{}
+		mu.Unlock()
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			server.ServeCodec(jsonrpc.NewServerCodec(conn))
+			mu.Lock()
+			delete(open, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+// listenPrivate listens on a unix socket at socketPath that only the owner
+// can connect to. The socket is bound inside a new 0700 directory next to
+// socketPath, so nobody else can reach it before it is restricted to 0600
+// and renamed into place.
+func listenPrivate(socketPath string) (*net.UnixListener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(socketPath), ".sock-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	private := filepath.Join(dir, filepath.Base(socketPath))
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: private, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	// The socket leaves private below; ServeRPC removes it at socketPath
+	listener.SetUnlinkOnClose(false)
+	if err := os.Chmod(private, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	if err := os.Rename(private, socketPath); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	return listener, nil
+}
+
+// RPCClient calls a ResultStore served by ServeRPC.
+type RPCClient struct {
+	client *rpc.Client
+}
+
+// DialRPC connects to the result store served at socketPath.
+func DialRPC(socketPath string) (*RPCClient, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to result store at %s: %w", socketPath, err)
+	}
+	return &RPCClient{client: jsonrpc.NewClient(conn)}, nil
+}
+
+// Store saves content under key in the shared store.
+func (c *RPCClient) Store(ctx context.Context, args RPCStoreArgs) (ResultMetadata, error) {
+	var meta ResultMetadata
+	err := c.call(ctx, "ResultStore.Store", &args, &meta)
+	return meta, err
+}
+
+// Get retrieves content by key, or nil if the key holds nothing.
+func (c *RPCClient) Get(ctx context.Context, key ResultKey) (*Result, error) {
+	var reply RPCGetReply
+	if err := c.call(ctx, "ResultStore.Get", &RPCGetArgs{Key: key}, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Found {
+		return nil, nil
+	}
+	return &reply.Result, nil
+}
+
+// Search finds pattern across a session's stored content.
+func (c *RPCClient) Search(ctx context.Context, sessionID, pattern string, limit int) ([]SearchMatch, error) {
+	var matches []SearchMatch
+	err := c.call(ctx, "ResultStore.Search", &RPCSearchArgs{SessionID: sessionID, Pattern: pattern, Limit: limit}, &matches)
+	return matches, err
+}
+
+// Close closes the connection.
+func (c *RPCClient) Close() error {
+	return c.client.Close()
+}
+
+// call makes an RPC call that gives up when ctx is done. The server still
+// finishes a call the client gave up on.
+func (c *RPCClient) call(ctx context.Context, method string, args, reply any) error {
+	call := c.client.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveRPC serves store on a socket in a temp dir until the test ends.
+func serveRPC(t *testing.T, ctx context.Context, store ResultStoreInterface) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "store.sock")
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- ServeRPC(ctx, store, socket) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("ServeRPC returned %v", err)
+		}
+		if _, err := os.Stat(socket); !os.IsNotExist(err) {
+			t.Errorf("socket not removed: %v", err)
+		}
+	})
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(socket); err == nil {
+			return socket
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("socket never appeared")
+	return ""
+}
+
+func TestRPCSharesStore(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+	ctx := context.Background()
+	socket := serveRPC(t, ctx, store)
+
+	client, err := DialRPC(socket)
+	if err != nil {
+		t.Fatalf("DialRPC failed: %v", err)
+	}
+	defer client.Close()
+
+	key := ResultKey{SessionID: "shared", Key: "src/main.go"}
+	meta, err := client.Store(ctx, RPCStoreArgs{Key: key, Content: "package main\n\nfunc main() {}"})
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if meta.LineCount != 3 || meta.Tags.Language != "go" {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+
+	// Stored over the socket, visible in the process serving it
+	if local, _ := store.Get(ctx, key); local == nil {
+		t.Fatal("content stored over RPC is missing from the served store")
+	}
+
+	result, err := client.Get(ctx, key)
+	if err != nil || result == nil || !strings.Contains(result.Content, "func main") {
+		t.Fatalf("Get = %+v, %v", result, err)
+	}
+	if missing, err := client.Get(ctx, ResultKey{SessionID: "shared", Key: "nope"}); err != nil || missing != nil {
+		t.Errorf("Get(missing) = %+v, %v", missing, err)
+	}
+
+	matches, err := client.Search(ctx, "shared", "func main", 10)
+	if err != nil || len(matches) != 1 || matches[0].Line != 3 {
+		t.Errorf("Search = %+v, %v", matches, err)
+	}
+
+	// A second server on the same socket is refused
+	if err := ServeRPC(ctx, store, socket); err == nil {
+		t.Error("expected an error serving on a live socket")
+	}
+}
+
+func TestRPCReadOnly(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+	socket := serveRPC(t, WithReadOnly(context.Background()), store)
+
+	client, err := DialRPC(socket)
+	if err != nil {
+		t.Fatalf("DialRPC failed: %v", err)
+	}
+	defer client.Close()
+
+	_, err = client.Store(context.Background(), RPCStoreArgs{Key: ResultKey{SessionID: "s", Key: "k"}, Content: "x"})
+	if err == nil || !strings.Contains(err.Error(), ErrReadOnly.Error()) {
+		t.Errorf("Store on a read-only socket = %v, want %v", err, ErrReadOnly)
+	}
+}
+
+func TestRPCSocketIsPrivate(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+	socket := serveRPC(t, context.Background(), store)
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected socket mode 0600, got %o", perm)
+	}
+	// The private directory it was created in is gone
+	entries, _ := os.ReadDir(filepath.Dir(socket))
+	if len(entries) != 1 {
+		t.Errorf("expected only the socket next to it, got %d entries", len(entries))
+	}
+}
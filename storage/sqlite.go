@@ -4,12 +4,16 @@
 // - SQLite connection management hidden behind interface
 // - Schema and migration details encapsulated
 // - Thread-safe via sql.DB's built-in connection pooling
+// - Multi-process safe: WAL journaling, a busy timeout, and a per-session
+//   revision that makes conditional saves fail instead of overwriting
 
 package storage
 
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,8 +21,18 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
 )
 
+// sqliteBusyTimeout is how long (ms) a connection waits for another
+// process's write lock before failing with "database is locked".
+const sqliteBusyTimeout = 5000
+
+// ErrSessionConflict is returned by SaveAtRevision and
+// AppendMessagesAtRevision when another writer saved the session since it
+// was loaded.
+var ErrSessionConflict = errors.New("session was modified by another writer")
+
 // SqliteStorage implements ConversationStorage and MemoryStorage using SQLite.
 // Stores conversation history and memories in a SQLite database file.
 // Thread-safe: sql.DB handles connection pooling and concurrent access.
@@ -37,13 +51,13 @@ func OpenSqlite(path string) (*SqliteStorage, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite3", path)
+	db, err := openSqliteDB(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+		return nil, err
 	}
 
 	storage := &SqliteStorage{db: db}
-	if err := storage.createSchema(); err != nil {
+	if err := storage.initSchema(context.Background()); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
@@ -51,6 +65,19 @@ func OpenSqlite(path string) (*SqliteStorage, error) {
 	return storage, nil
 }
 
+// openSqliteDB opens the database file for use by several processes at
+// once. WAL lets readers run alongside a writer; transactions begin
+// IMMEDIATE so they take the write lock up front, and the busy timeout
+// makes a second process wait for it instead of failing.
+func openSqliteDB(path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s?_busy_timeout=%d&_journal_mode=WAL&_txlock=immediate", path, sqliteBusyTimeout)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	return db, nil
+}
+
 // NewSqliteInMemory creates an in-memory database (useful for testing).
 func NewSqliteInMemory() (*SqliteStorage, error) {
 	db, err := sql.Open("sqlite3", ":memory:")
@@ -59,7 +86,7 @@ func NewSqliteInMemory() (*SqliteStorage, error) {
 	}
 
 	storage := &SqliteStorage{db: db}
-	if err := storage.createSchema(); err != nil {
+	if err := storage.initSchema(context.Background()); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
@@ -72,71 +99,6 @@ func (s *SqliteStorage) Close() error {
 	return s.db.Close()
 }
 
-func (s *SqliteStorage) createSchema() error {
-	schema := `
-		CREATE TABLE IF NOT EXISTS sessions (
-			session_id TEXT PRIMARY KEY,
-			created_at TEXT NOT NULL DEFAULT (datetime('now')),
-			updated_at TEXT NOT NULL DEFAULT (datetime('now'))
-		);
-
-		CREATE TABLE IF NOT EXISTS messages (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			session_id TEXT NOT NULL,
-			message_index INTEGER NOT NULL,
-			role TEXT NOT NULL,
-			content TEXT NOT NULL,
-			FOREIGN KEY (session_id) REFERENCES sessions(session_id) ON DELETE CASCADE,
-			UNIQUE(session_id, message_index)
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_messages_session
-		ON messages(session_id, message_index);
-
-		CREATE TABLE IF NOT EXISTS memories (
-			id TEXT PRIMARY KEY,
-			session_id TEXT NOT NULL,
-			agent_id TEXT,
-			memory_type TEXT NOT NULL,
-			content TEXT NOT NULL,
-			created_at INTEGER NOT NULL,
-			accessed_at INTEGER NOT NULL,
-			access_count INTEGER DEFAULT 1,
-			metadata TEXT,
-			FOREIGN KEY (session_id) REFERENCES sessions(session_id) ON DELETE CASCADE
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_memories_session_type
-		ON memories(session_id, memory_type, created_at DESC);
-
-		CREATE TABLE IF NOT EXISTS results (
-			session_id TEXT NOT NULL,
-			key TEXT NOT NULL,
-			content_hash TEXT NOT NULL,
-			content TEXT NOT NULL,
-			summary TEXT NOT NULL,
-			line_count INTEGER NOT NULL,
-			byte_size INTEGER NOT NULL,
-			created_at INTEGER NOT NULL,
-			accessed_at INTEGER NOT NULL,
-			access_count INTEGER DEFAULT 1,
-			PRIMARY KEY (session_id, key)
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_results_session
-		ON results(session_id);
-
-		CREATE INDEX IF NOT EXISTS idx_results_hash
-		ON results(content_hash);
-	`
-
-	_, err := s.db.Exec(schema)
-	if err != nil {
-		return fmt.Errorf("failed to create schema: %w", err)
-	}
-	return nil
-}
-
 func (s *SqliteStorage) ensureSession(ctx context.Context, sessionID string) error {
 	_, err := s.db.ExecContext(ctx,
 		"INSERT OR IGNORE INTO sessions (session_id) VALUES (?)",
@@ -148,62 +110,181 @@ func (s *SqliteStorage) ensureSession(ctx context.Context, sessionID string) err
 	return nil
 }
 
-// Save saves conversation history for a session.
+// Save saves conversation history for a session, including assistant tool
+// calls and the tool call IDs of tool results. It rewrites every message;
+// chat loops should use AppendMessages, and SaveAtRevision detects
+// concurrent writers.
 func (s *SqliteStorage) Save(ctx context.Context, sessionID string, history []llm.ChatMessage) error {
-	if err := s.ensureSession(ctx, sessionID); err != nil {
-		return err
-	}
+	_, err := s.writeMessages(ctx, sessionID, history, true, -1)
+	return err
+}
+
+// SaveAtRevision saves history only if the session is still at revision
+// (as returned by LoadWithRevision or a previous write), returning the new
+// revision. Returns ErrSessionConflict if another writer saved in between.
+func (s *SqliteStorage) SaveAtRevision(ctx context.Context, sessionID string, history []llm.ChatMessage, revision int64) (int64, error) {
+	return s.writeMessages(ctx, sessionID, history, true, revision)
+}
+
+// AppendMessages adds messages to the end of a session's history without
+// rewriting the messages already stored.
+func (s *SqliteStorage) AppendMessages(ctx context.Context, sessionID string, messages []llm.ChatMessage) error {
+	_, err := s.writeMessages(ctx, sessionID, messages, false, -1)
+	return err
+}
+
+// AppendMessagesAtRevision appends messages only if the session is still at
+// revision, returning the new revision. Returns ErrSessionConflict if
+// another writer saved in between.
+func (s *SqliteStorage) AppendMessagesAtRevision(ctx context.Context, sessionID string, messages []llm.ChatMessage, revision int64) (int64, error) {
+	return s.writeMessages(ctx, sessionID, messages, false, revision)
+}
 
+// writeMessages stores messages, replacing the session's history or
+// appending to it, and bumps the session revision. A negative expected
+// revision skips the conflict check.
+func (s *SqliteStorage) writeMessages(ctx context.Context, sessionID string, messages []llm.ChatMessage, replace bool, expected int64) (int64, error) {
 	// Start transaction
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	// defer tx.Rollback() is safe even after Commit() - it becomes a no-op
 	defer func() { _ = tx.Rollback() }()
 
-	// Clear existing messages for this session
-	_, err = tx.ExecContext(ctx, "DELETE FROM messages WHERE session_id = ?", sessionID)
-	if err != nil {
-		return fmt.Errorf("failed to clear old messages: %w", err)
+	// The transaction holds the write lock, so the revision can't change
+	// between this check and the commit
+	if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO sessions (session_id) VALUES (?)", sessionID); err != nil {
+		return 0, fmt.Errorf("failed to ensure session: %w", err)
+	}
+	var revision int64
+	if err := tx.QueryRowContext(ctx, "SELECT revision FROM sessions WHERE session_id = ?", sessionID).Scan(&revision); err != nil {
+		return 0, fmt.Errorf("failed to read session revision: %w", err)
+	}
+	if expected >= 0 && revision != expected {
+		return 0, fmt.Errorf("%w: session %s is at revision %d, expected %d", ErrSessionConflict, sessionID, revision, expected)
+	}
+
+	next := 0
+	if replace {
+		// Clear existing messages for this session
+		_, err = tx.ExecContext(ctx, "DELETE FROM messages WHERE session_id = ?", sessionID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to clear old messages: %w", err)
+		}
+	} else {
+		err = tx.QueryRowContext(ctx,
+			"SELECT COALESCE(MAX(message_index) + 1, 0) FROM messages WHERE session_id = ?",
+			sessionID).Scan(&next)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find end of history: %w", err)
+		}
 	}
 
-	// Insert all messages
+	// Insert messages after the existing ones
 	stmt, err := tx.PrepareContext(ctx,
-		"INSERT INTO messages (session_id, message_index, role, content) VALUES (?, ?, ?, ?)")
+		"INSERT INTO messages (session_id, message_index, role, content, tool_calls, tool_call_id) VALUES (?, ?, ?, ?, ?, ?)")
 	if err != nil {
-		return fmt.Errorf("failed to prepare insert statement: %w", err)
+		return 0, fmt.Errorf("failed to prepare insert statement: %w", err)
 	}
 	defer stmt.Close()
 
-	for i, msg := range history {
-		_, err = stmt.ExecContext(ctx, sessionID, i, msg.Role, msg.Content)
+	for i, msg := range messages {
+		var toolCalls sql.NullString
+		if len(msg.ToolCalls) > 0 {
+			data, err := json.Marshal(msg.ToolCalls)
+			if err != nil {
+				return 0, fmt.Errorf("failed to encode tool calls: %w", err)
+			}
+			toolCalls = sql.NullString{String: string(data), Valid: true}
+		}
+		_, err = stmt.ExecContext(ctx, sessionID, next+i, msg.Role, msg.Content, toolCalls, msg.ToolCallID)
 		if err != nil {
-			return fmt.Errorf("failed to insert message: %w", err)
+			return 0, fmt.Errorf("failed to insert message: %w", err)
 		}
 	}
 
-	// Update session timestamp
+	// Update session timestamp and revision
 	_, err = tx.ExecContext(ctx,
-		"UPDATE sessions SET updated_at = datetime('now') WHERE session_id = ?",
+		"UPDATE sessions SET updated_at = datetime('now'), revision = revision + 1 WHERE session_id = ?",
 		sessionID)
 	if err != nil {
-		return fmt.Errorf("failed to update session timestamp: %w", err)
+		return 0, fmt.Errorf("failed to update session timestamp: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return revision + 1, nil
 }
 
 // Load loads conversation history for a session.
 // Returns empty slice if session doesn't exist.
 func (s *SqliteStorage) Load(ctx context.Context, sessionID string) ([]llm.ChatMessage, error) {
-	rows, err := s.db.QueryContext(ctx,
-		"SELECT role, content FROM messages WHERE session_id = ? ORDER BY message_index ASC",
-		sessionID)
+	return loadMessages(ctx, s.db, sessionID, 0)
+}
+
+// LoadLastN loads the last n messages of a session, oldest first. The
+// window never starts with tool results, whose assistant tool call would be
+// cut off, so it may hold fewer than n messages. n <= 0 loads everything.
+func (s *SqliteStorage) LoadLastN(ctx context.Context, sessionID string, n int) ([]llm.ChatMessage, error) {
+	return loadMessages(ctx, s.db, sessionID, n)
+}
+
+// LoadWithRevision loads the last n messages (all if n <= 0, as with
+// LoadLastN) with the session's revision, for a later SaveAtRevision or
+// AppendMessagesAtRevision. A missing session has revision 0.
+func (s *SqliteStorage) LoadWithRevision(ctx context.Context, sessionID string, n int) ([]llm.ChatMessage, int64, error) {
+	// One transaction, so the messages match the revision
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var revision int64
+	err = tx.QueryRowContext(ctx, "SELECT revision FROM sessions WHERE session_id = ?", sessionID).Scan(&revision)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, 0, fmt.Errorf("failed to read session revision: %w", err)
+	}
+	messages, err := loadMessages(ctx, tx, sessionID, n)
+	if err != nil {
+		return nil, 0, err
+	}
+	return messages, revision, nil
+}
+
+// MessageCount returns how many messages a session has stored.
+func (s *SqliteStorage) MessageCount(ctx context.Context, sessionID string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM messages WHERE session_id = ?",
+		sessionID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count messages: %w", err)
+	}
+	return count, nil
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadMessages reads a session's messages in order, only the last n when
+// n > 0.
+func loadMessages(ctx context.Context, q queryer, sessionID string, n int) ([]llm.ChatMessage, error) {
+	query := "SELECT role, content, tool_calls, tool_call_id FROM messages WHERE session_id = ? ORDER BY message_index ASC"
+	args := []interface{}{sessionID}
+	if n > 0 {
+		query = `SELECT role, content, tool_calls, tool_call_id FROM (
+			SELECT role, content, tool_calls, tool_call_id, message_index FROM messages
+			WHERE session_id = ? ORDER BY message_index DESC LIMIT ?
+		) ORDER BY message_index ASC`
+		args = append(args, n)
+	}
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query messages: %w", err)
 	}
@@ -212,9 +293,15 @@ func (s *SqliteStorage) Load(ctx context.Context, sessionID string) ([]llm.ChatM
 	messages := []llm.ChatMessage{} // Start with empty slice, not nil
 	for rows.Next() {
 		var msg llm.ChatMessage
-		if err := rows.Scan(&msg.Role, &msg.Content); err != nil {
+		var toolCalls sql.NullString
+		if err := rows.Scan(&msg.Role, &msg.Content, &toolCalls, &msg.ToolCallID); err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
+		if toolCalls.Valid {
+			if err := json.Unmarshal([]byte(toolCalls.String), &msg.ToolCalls); err != nil {
+				return nil, fmt.Errorf("failed to decode tool calls: %w", err)
+			}
+		}
 		messages = append(messages, msg)
 	}
 
@@ -222,6 +309,13 @@ func (s *SqliteStorage) Load(ctx context.Context, sessionID string) ([]llm.ChatM
 		return nil, fmt.Errorf("error iterating messages: %w", err)
 	}
 
+	// A window must not open on tool results without their tool call
+	if n > 0 {
+		for len(messages) > 0 && messages[0].Role == "tool" {
+			messages = messages[1:]
+		}
+	}
+
 	return messages, nil
 }
 
@@ -293,8 +387,8 @@ func (s *SqliteStorage) StoreMemory(ctx context.Context, entry MemoryEntry) erro
 
 	_, err := s.db.ExecContext(ctx, `
 		INSERT OR REPLACE INTO memories
-		(id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		(id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata, importance)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		entry.ID,
 		entry.SessionID,
 		agentID,
@@ -304,6 +398,7 @@ func (s *SqliteStorage) StoreMemory(ctx context.Context, entry MemoryEntry) erro
 		entry.AccessedAt,
 		entry.AccessCount,
 		metadata,
+		entry.Importance,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to store memory: %w", err)
@@ -319,18 +414,18 @@ func (s *SqliteStorage) QueryMemories(ctx context.Context, sessionID string, mem
 
 	if memoryType != nil {
 		rows, err = s.db.QueryContext(ctx, `
-			SELECT id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata
+			SELECT id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata, importance
 			FROM memories
 			WHERE session_id = ? AND memory_type = ?
-			ORDER BY created_at DESC
+			ORDER BY created_at DESC, rowid DESC
 			LIMIT ?`,
 			sessionID, memoryType.String(), limit)
 	} else {
 		rows, err = s.db.QueryContext(ctx, `
-			SELECT id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata
+			SELECT id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata, importance
 			FROM memories
 			WHERE session_id = ?
-			ORDER BY created_at DESC
+			ORDER BY created_at DESC, rowid DESC
 			LIMIT ?`,
 			sessionID, limit)
 	}
@@ -372,6 +467,7 @@ func (s *SqliteStorage) scanMemoryRow(rows *sql.Rows) (MemoryEntry, error) {
 		&entry.AccessedAt,
 		&entry.AccessCount,
 		&metadata,
+		&entry.Importance,
 	)
 	if err != nil {
 		return MemoryEntry{}, fmt.Errorf("failed to scan memory: %w", err)
@@ -408,7 +504,7 @@ func (s *SqliteStorage) GetMemory(ctx context.Context, id string) (*MemoryEntry,
 	var agentID, metadata sql.NullString
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata
+		SELECT id, session_id, agent_id, memory_type, content, created_at, accessed_at, access_count, metadata, importance
 		FROM memories WHERE id = ?`,
 		id).Scan(
 		&entry.ID,
@@ -420,6 +516,7 @@ func (s *SqliteStorage) GetMemory(ctx context.Context, id string) (*MemoryEntry,
 		&entry.AccessedAt,
 		&entry.AccessCount,
 		&metadata,
+		&entry.Importance,
 	)
 
 	if err == sql.ErrNoRows {
@@ -479,12 +576,106 @@ func (s *SqliteStorage) DeleteSessionMemories(ctx context.Context, sessionID str
 
 // ContentStorage implementation
 
-// StoreResult stores a content result.
+// StoreResult stores a content result. When the key's content changes,
+// the new content is recorded as the next version; earlier versions are kept.
 func (s *SqliteStorage) StoreResult(ctx context.Context, result ContentResult) error {
-	_, err := s.db.ExecContext(ctx, `
-		INSERT OR REPLACE INTO results
-		(session_id, key, content_hash, content, summary, line_count, byte_size, created_at, accessed_at, access_count)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	return s.StoreResults(ctx, []ContentResult{result})
+}
+
+// StoreResults stores results in one transaction, reusing prepared
+// statements across them. Versions are recorded as by StoreResult.
+func (s *SqliteStorage) StoreResults(ctx context.Context, results []ContentResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	w, err := prepareResultWriter(ctx, tx)
+	if err != nil {
+		return err
+	}
+	defer w.close()
+
+	for _, result := range results {
+		if err := w.store(ctx, result); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit result: %w", err)
+	}
+	return nil
+}
+
+// resultWriter holds the statements StoreResults prepares once per
+// transaction.
+type resultWriter struct {
+	current       *sql.Stmt
+	nextVersion   *sql.Stmt
+	insertVersion *sql.Stmt
+	upsert        *sql.Stmt
+}
+
+func prepareResultWriter(ctx context.Context, tx *sql.Tx) (*resultWriter, error) {
+	w := &resultWriter{}
+	for _, p := range []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&w.current, "SELECT content_hash, version FROM results WHERE session_id = ? AND key = ?"},
+		// History survives DeleteResult, so number after the highest version
+		{&w.nextVersion, "SELECT COALESCE(MAX(version), 0) + 1 FROM result_versions WHERE session_id = ? AND key = ?"},
+		{&w.insertVersion, `
+			INSERT INTO result_versions
+			(session_id, key, version, content_hash, content, summary, line_count, byte_size, stored_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`},
+		{&w.upsert, `
+			INSERT OR REPLACE INTO results
+			(session_id, key, content_hash, content, summary, line_count, byte_size, created_at, accessed_at, access_count, version, metadata, pinned)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`},
+	} {
+		stmt, err := tx.PrepareContext(ctx, p.query)
+		if err != nil {
+			w.close()
+			return nil, fmt.Errorf("failed to prepare result statement: %w", err)
+		}
+		*p.stmt = stmt
+	}
+	return w, nil
+}
+
+func (w *resultWriter) close() {
+	for _, stmt := range []*sql.Stmt{w.current, w.nextVersion, w.insertVersion, w.upsert} {
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+	}
+}
+
+// store writes one result, recording a new version if its content changed.
+func (w *resultWriter) store(ctx context.Context, result ContentResult) error {
+	var currentHash string
+	var version int
+	err := w.current.QueryRowContext(ctx, result.SessionID, result.Key).Scan(&currentHash, &version)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read current version: %w", err)
+	}
+	if err == sql.ErrNoRows || currentHash != result.ContentHash {
+		if err := w.nextVersion.QueryRowContext(ctx, result.SessionID, result.Key).Scan(&version); err != nil {
+			return fmt.Errorf("failed to number version: %w", err)
+		}
+		if _, err := w.insertVersion.ExecContext(ctx,
+			result.SessionID, result.Key, version, result.ContentHash, result.Content,
+			result.Summary, result.LineCount, result.ByteSize, time.Now().Unix()); err != nil {
+			return fmt.Errorf("failed to store result version: %w", err)
+		}
+	}
+
+	_, err = w.upsert.ExecContext(ctx,
 		result.SessionID,
 		result.Key,
 		result.ContentHash,
@@ -495,6 +686,9 @@ func (s *SqliteStorage) StoreResult(ctx context.Context, result ContentResult) e
 		result.CreatedAt,
 		result.AccessedAt,
 		result.AccessCount,
+		version,
+		result.Metadata,
+		result.Pinned,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to store result: %w", err)
@@ -505,7 +699,7 @@ func (s *SqliteStorage) StoreResult(ctx context.Context, result ContentResult) e
 // LoadAllResults loads all results from storage.
 func (s *SqliteStorage) LoadAllResults(ctx context.Context) ([]ContentResult, error) {
 	return s.queryResults(ctx, `
-		SELECT session_id, key, content_hash, content, summary, line_count, byte_size, created_at, accessed_at, access_count
+		SELECT session_id, key, content_hash, content, summary, line_count, byte_size, created_at, accessed_at, access_count, version, metadata, pinned
 		FROM results
 		ORDER BY accessed_at DESC`)
 }
@@ -513,7 +707,7 @@ func (s *SqliteStorage) LoadAllResults(ctx context.Context) ([]ContentResult, er
 // LoadResultsBySession loads results for a specific session.
 func (s *SqliteStorage) LoadResultsBySession(ctx context.Context, sessionID string) ([]ContentResult, error) {
 	return s.queryResults(ctx, `
-		SELECT session_id, key, content_hash, content, summary, line_count, byte_size, created_at, accessed_at, access_count
+		SELECT session_id, key, content_hash, content, summary, line_count, byte_size, created_at, accessed_at, access_count, version, metadata, pinned
 		FROM results
 		WHERE session_id = ?
 		ORDER BY accessed_at DESC`, sessionID)
@@ -541,6 +735,9 @@ func (s *SqliteStorage) queryResults(ctx context.Context, query string, args ...
 			&r.CreatedAt,
 			&r.AccessedAt,
 			&r.AccessCount,
+			&r.Version,
+			&r.Metadata,
+			&r.Pinned,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan failed: %w", err)
@@ -566,7 +763,7 @@ func (s *SqliteStorage) UpdateResultAccess(ctx context.Context, sessionID, key s
 	return nil
 }
 
-// DeleteResult removes a specific result.
+// DeleteResult removes a specific result. Its version history is kept.
 func (s *SqliteStorage) DeleteResult(ctx context.Context, sessionID, key string) error {
 	_, err := s.db.ExecContext(ctx, "DELETE FROM results WHERE session_id = ? AND key = ?", sessionID, key)
 	if err != nil {
@@ -575,16 +772,264 @@ func (s *SqliteStorage) DeleteResult(ctx context.Context, sessionID, key string)
 	return nil
 }
 
-// DeleteSessionResults removes all results for a session.
+// DeleteSessionResults removes all unpinned results for a session, with
+// their version history.
 func (s *SqliteStorage) DeleteSessionResults(ctx context.Context, sessionID string) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM results WHERE session_id = ?", sessionID)
+	_, err := s.db.ExecContext(ctx, "DELETE FROM results WHERE session_id = ? AND pinned = 0", sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to delete session results: %w", err)
 	}
+	_, err = s.db.ExecContext(ctx, `
+		DELETE FROM result_versions
+		WHERE session_id = ?
+		AND key NOT IN (SELECT key FROM results WHERE session_id = ? AND pinned = 1)`, sessionID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session result versions: %w", err)
+	}
+	return nil
+}
+
+// SetResultPinned pins or unpins a result.
+func (s *SqliteStorage) SetResultPinned(ctx context.Context, sessionID, key string, pinned bool) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE results SET pinned = ? WHERE session_id = ? AND key = ?", pinned, sessionID, key)
+	if err != nil {
+		return fmt.Errorf("failed to set pinned: %w", err)
+	}
 	return nil
 }
 
+// ListResultVersions lists every stored version of a key, newest first,
+// without content. CreatedAt holds when each version was stored.
+func (s *SqliteStorage) ListResultVersions(ctx context.Context, sessionID, key string) ([]ContentResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT version, content_hash, summary, line_count, byte_size, stored_at
+		FROM result_versions
+		WHERE session_id = ? AND key = ?
+		ORDER BY version DESC`, sessionID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query result versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []ContentResult
+	for rows.Next() {
+		r := ContentResult{SessionID: sessionID, Key: key}
+		if err := rows.Scan(&r.Version, &r.ContentHash, &r.Summary, &r.LineCount, &r.ByteSize, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		versions = append(versions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iteration failed: %w", err)
+	}
+	return versions, nil
+}
+
+// GetResultVersion returns one version of a key with its content, or nil
+// if it doesn't exist.
+func (s *SqliteStorage) GetResultVersion(ctx context.Context, sessionID, key string, version int) (*ContentResult, error) {
+	r := ContentResult{SessionID: sessionID, Key: key, Version: version}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT content_hash, content, summary, line_count, byte_size, stored_at
+		FROM result_versions
+		WHERE session_id = ? AND key = ? AND version = ?`, sessionID, key, version).
+		Scan(&r.ContentHash, &r.Content, &r.Summary, &r.LineCount, &r.ByteSize, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get result version: %w", err)
+	}
+	return &r, nil
+}
+
 // Verify SqliteStorage implements all interfaces
 var _ ConversationStorage = (*SqliteStorage)(nil)
 var _ MemoryStorage = (*SqliteStorage)(nil)
 var _ ContentStorage = (*SqliteStorage)(nil)
+
+// ToolStatsStorage implementation
+
+// RecordToolCalls stores a batch of tool invocation records.
+func (s *SqliteStorage) RecordToolCalls(ctx context.Context, records []ToolCallRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO tool_calls
+		(run_id, tool_name, args_hash, input_size, output_size, duration_ms, success, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range records {
+		createdAt := r.CreatedAt
+		if createdAt == 0 {
+			createdAt = time.Now().Unix()
+		}
+		_, err = stmt.ExecContext(ctx,
+			r.RunID, r.ToolName, r.ArgsHash, r.InputSize, r.OutputSize, r.DurationMs, r.Success, createdAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert tool call: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ToolUsageStats returns aggregate statistics per tool, largest output first.
+func (s *SqliteStorage) ToolUsageStats(ctx context.Context) ([]ToolUsageStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			tool_name,
+			COUNT(DISTINCT run_id),
+			COUNT(*),
+			SUM(CASE WHEN success THEN 0 ELSE 1 END),
+			COUNT(*) - COUNT(DISTINCT run_id || ':' || args_hash),
+			SUM(output_size),
+			SUM(duration_ms)
+		FROM tool_calls
+		GROUP BY tool_name
+		ORDER BY SUM(output_size) DESC, COUNT(*) DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tool stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []ToolUsageStats{}
+	for rows.Next() {
+		var st ToolUsageStats
+		if err := rows.Scan(&st.ToolName, &st.Runs, &st.Calls, &st.Failures,
+			&st.RepeatedCalls, &st.TotalOutputBytes, &st.TotalDurationMs); err != nil {
+			return nil, fmt.Errorf("failed to scan tool stats: %w", err)
+		}
+		stats = append(stats, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tool stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// RecordDSAUsage stores a run's result store usage, replacing any earlier
+// record of the run.
+func (s *SqliteStorage) RecordDSAUsage(ctx context.Context, runID string, usage model.DSAUsage) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO dsa_usage
+		(run_id, files_stored, bytes_indexed, searches, cache_hits, get_lines_calls, bytes_saved, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		runID, usage.FilesStored, usage.BytesIndexed, usage.Searches, usage.CacheHits,
+		usage.GetLinesCalls, usage.BytesSaved, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record result store usage: %w", err)
+	}
+	return nil
+}
+
+// DSAUsageTotals sums result store usage across runs.
+func (s *SqliteStorage) DSAUsageTotals(ctx context.Context) (DSAUsageTotals, error) {
+	var totals DSAUsageTotals
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(files_stored), 0),
+			COALESCE(SUM(bytes_indexed), 0),
+			COALESCE(SUM(searches), 0),
+			COALESCE(SUM(cache_hits), 0),
+			COALESCE(SUM(get_lines_calls), 0),
+			COALESCE(SUM(bytes_saved), 0)
+		FROM dsa_usage`).Scan(&totals.Runs, &totals.FilesStored, &totals.BytesIndexed,
+		&totals.Searches, &totals.CacheHits, &totals.GetLinesCalls, &totals.BytesSaved)
+	if err != nil {
+		return DSAUsageTotals{}, fmt.Errorf("failed to query result store usage: %w", err)
+	}
+	return totals, nil
+}
+
+// AuditStorage implementation
+
+var _ AuditStorage = (*SqliteStorage)(nil)
+
+// RecordAudit appends an audit entry.
+func (s *SqliteStorage) RecordAudit(ctx context.Context, entry AuditEntry) error {
+	createdAt := entry.CreatedAt
+	if createdAt == 0 {
+		createdAt = time.Now().Unix()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO audit_log
+		(session_id, run_id, agent_id, tool_name, args_hash, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.SessionID, entry.RunID, entry.AgentID, entry.ToolName, entry.ArgsHash, entry.Status, createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAudit returns entries matching filter, oldest first.
+func (s *SqliteStorage) ListAudit(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	query := `SELECT id, session_id, run_id, agent_id, tool_name, args_hash, status, created_at
+		FROM audit_log WHERE 1 = 1`
+	var args []interface{}
+	if filter.SessionID != "" {
+		query += " AND session_id = ?"
+		args = append(args, filter.SessionID)
+	}
+	if filter.RunID != "" {
+		query += " AND run_id = ?"
+		args = append(args, filter.RunID)
+	}
+	if filter.ToolName != "" {
+		query += " AND tool_name = ?"
+		args = append(args, filter.ToolName)
+	}
+	if filter.Since > 0 {
+		query += " AND created_at >= ?"
+		args = append(args, filter.Since)
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.RunID, &e.AgentID, &e.ToolName, &e.ArgsHash, &e.Status, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+
+	// Newest were selected for the limit; return them oldest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
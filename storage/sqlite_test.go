@@ -2,6 +2,10 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -200,6 +204,32 @@ func TestSqliteStorageStoreAndQueryMemory(t *testing.T) {
 	}
 }
 
+func TestSqliteStoragePartialMemory(t *testing.T) {
+	storage, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	entry := NewMemoryEntry("test-session", MemoryPartial, "Task: fix build\nStopped after 3 steps").
+		WithAgent("coder").
+		WithMetadata(`{"task": "fix build", "steps": []}`)
+	if err := storage.StoreMemory(ctx, entry); err != nil {
+		t.Fatalf("StoreMemory failed: %v", err)
+	}
+
+	memType := MemoryPartial
+	memories, err := storage.QueryMemories(ctx, "test-session", &memType, 10)
+	if err != nil {
+		t.Fatalf("QueryMemories failed: %v", err)
+	}
+	if len(memories) != 1 || memories[0].Type != MemoryPartial || memories[0].Metadata != entry.Metadata {
+		t.Errorf("partial memory did not round-trip: %+v", memories)
+	}
+}
+
 func TestSqliteStorageQueryMemoriesByType(t *testing.T) {
 	storage, err := NewSqliteInMemory()
 	if err != nil {
@@ -609,3 +639,180 @@ func TestSqliteStorageDeleteSessionResults(t *testing.T) {
 		t.Errorf("expected 1 result for session-2, got %d", len(session2))
 	}
 }
+
+func TestSqliteStorageSaveAndLoadToolCalls(t *testing.T) {
+	storage, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	messages := []llm.ChatMessage{
+		{Role: "user", Content: "Read go.mod"},
+		{Role: "assistant", Content: "Reading it", ToolCalls: []llm.ToolCall{
+			{ID: "call_1", Name: "read_file", Arguments: []byte(`{"path":"go.mod"}`)},
+		}},
+		{Role: "tool", Content: "module example", ToolCallID: "call_1"},
+	}
+
+	if err := storage.Save(ctx, "tools-session", messages); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := storage.Load(ctx, "tools-session")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(loaded))
+	}
+	if loaded[0].ToolCalls != nil || loaded[0].ToolCallID != "" {
+		t.Errorf("user message gained tool data: %+v", loaded[0])
+	}
+	calls := loaded[1].ToolCalls
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "read_file" || string(calls[0].Arguments) != `{"path":"go.mod"}` {
+		t.Errorf("tool calls not restored: %+v", calls)
+	}
+	if loaded[2].ToolCallID != "call_1" {
+		t.Errorf("expected tool call ID 'call_1', got '%s'", loaded[2].ToolCallID)
+	}
+}
+
+func TestSqliteStorageSaveAtRevisionDetectsConcurrentWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.db")
+	first, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("OpenSqlite failed: %v", err)
+	}
+	defer first.Close()
+	second, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("OpenSqlite failed: %v", err)
+	}
+	defer second.Close()
+	ctx := context.Background()
+
+	// Both processes load the same (new) session
+	_, revA, err := first.LoadWithRevision(ctx, "s", 0)
+	if err != nil || revA != 0 {
+		t.Fatalf("LoadWithRevision = %d, %v", revA, err)
+	}
+	_, revB, _ := second.LoadWithRevision(ctx, "s", 0)
+
+	revA, err = first.SaveAtRevision(ctx, "s", []llm.ChatMessage{{Role: "user", Content: "from A"}}, revA)
+	if err != nil || revA != 1 {
+		t.Fatalf("first save = %d, %v", revA, err)
+	}
+	if _, err := second.SaveAtRevision(ctx, "s", []llm.ChatMessage{{Role: "user", Content: "from B"}}, revB); !errors.Is(err, ErrSessionConflict) {
+		t.Fatalf("stale save: expected ErrSessionConflict, got %v", err)
+	}
+
+	history, rev, err := second.LoadWithRevision(ctx, "s", 0)
+	if err != nil || rev != 1 || len(history) != 1 || history[0].Content != "from A" {
+		t.Fatalf("after conflict: %v rev %d, %v", history, rev, err)
+	}
+
+	// Unconditional saves still bump the revision
+	if err := first.Save(ctx, "s", history); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := second.SaveAtRevision(ctx, "s", history, rev); !errors.Is(err, ErrSessionConflict) {
+		t.Errorf("expected conflict after unconditional save, got %v", err)
+	}
+}
+
+func TestSqliteStorageConcurrentSavesSerialize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.db")
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store, err := OpenSqlite(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer store.Close()
+			for i := 0; i < 10; i++ {
+				msg := llm.ChatMessage{Role: "user", Content: fmt.Sprintf("w%d-%d", w, i)}
+				if err := store.Save(ctx, "s", []llm.ChatMessage{msg, msg}); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent save failed: %v", err)
+	}
+
+	store, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("OpenSqlite failed: %v", err)
+	}
+	defer store.Close()
+	history, rev, err := store.LoadWithRevision(ctx, "s", 0)
+	if err != nil || rev != 40 {
+		t.Fatalf("revision = %d, %v; want 40", rev, err)
+	}
+	// Saves never interleave: the two messages come from the same write
+	if len(history) != 2 || history[0].Content != history[1].Content {
+		t.Errorf("interleaved history: %+v", history)
+	}
+}
+
+func TestSqliteStorageAppendMessagesAndLoadLastN(t *testing.T) {
+	store, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Save(ctx, "s", []llm.ChatMessage{{Role: "user", Content: "one"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	toolTurn := []llm.ChatMessage{
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "c1", Name: "read_file", Arguments: []byte(`{}`)}}},
+		{Role: "tool", Content: "contents", ToolCallID: "c1"},
+		{Role: "assistant", Content: "two"},
+	}
+	if err := store.AppendMessages(ctx, "s", toolTurn); err != nil {
+		t.Fatalf("AppendMessages failed: %v", err)
+	}
+
+	all, err := store.Load(ctx, "s")
+	if err != nil || len(all) != 4 || all[0].Content != "one" || all[3].Content != "two" {
+		t.Fatalf("Load after append = %+v, %v", all, err)
+	}
+	if count, _ := store.MessageCount(ctx, "s"); count != 4 {
+		t.Errorf("MessageCount = %d, want 4", count)
+	}
+
+	last, err := store.LoadLastN(ctx, "s", 3)
+	if err != nil || len(last) != 3 || last[0].ToolCalls == nil || last[2].Content != "two" {
+		t.Errorf("LoadLastN(3) = %+v, %v", last, err)
+	}
+	// A window starting on a tool result drops it
+	last, _ = store.LoadLastN(ctx, "s", 2)
+	if len(last) != 1 || last[0].Content != "two" {
+		t.Errorf("LoadLastN(2) = %+v, want only the final answer", last)
+	}
+
+	// Appends take part in the revision check
+	_, rev, _ := store.LoadWithRevision(ctx, "s", 0)
+	if _, err := store.AppendMessagesAtRevision(ctx, "s", []llm.ChatMessage{{Role: "user", Content: "three"}}, rev-1); !errors.Is(err, ErrSessionConflict) {
+		t.Errorf("stale append: expected ErrSessionConflict, got %v", err)
+	}
+	if next, err := store.AppendMessagesAtRevision(ctx, "s", []llm.ChatMessage{{Role: "user", Content: "three"}}, rev); err != nil || next != rev+1 {
+		t.Errorf("append = %d, %v", next, err)
+	}
+}
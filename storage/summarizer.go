@@ -0,0 +1,295 @@
+// Summarizers for stored content previews.
+//
+// Every stored result keeps a short summary that agents see in place of
+// the content. The first lines say little about a JSON blob or a long
+// log, so the summary is written by a Summarizer: the leading lines by
+// default, a structural description (source outline, JSON shape, log level
+// counts), or an LLM's description. A store-wide summarizer is set with
+// ResultStore.WithSummarizer; StoreOptions.Summarizer overrides it for one
+// Store call. A summarizer that is also a BatchSummarizer summarizes a
+// StoreBatch call's contents together; the LLM summarizer sends them as
+// one request per batch (see llm.Client.ChatBatch).
+//
+// Information Hiding:
+// - Content kind detection (source, JSON, log) hidden
+// - LLM prompt, input cutting and batching hidden
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/richinex/ariadne/internal/outline"
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/llm"
+)
+
+// Summarizer writes the summary kept with stored content. opts carries the
+// summary limits (SummaryLength, SummaryLines), already defaulted. If it
+// fails, Store falls back to the leading lines.
+type Summarizer interface {
+	Summarize(ctx context.Context, key ResultKey, content string, opts StoreOptions) (string, error)
+}
+
+// BatchSummarizer is a Summarizer that can summarize several contents at
+// once. StoreBatch uses it for requests that share the store's summarizer.
+type BatchSummarizer interface {
+	Summarizer
+	// SummarizeBatch returns one summary per request, in order. Requests
+	// left with an empty summary, or all of them if it fails, are passed
+	// to Summarize one at a time. Options are defaulted.
+	SummarizeBatch(ctx context.Context, reqs []StoreRequest) ([]string, error)
+}
+
+// SummarizerFunc adapts a function to a Summarizer.
+type SummarizerFunc func(ctx context.Context, key ResultKey, content string, opts StoreOptions) (string, error)
+
+// Summarize calls f.
+func (f SummarizerFunc) Summarize(ctx context.Context, key ResultKey, content string, opts StoreOptions) (string, error) {
+	return f(ctx, key, content, opts)
+}
+
+// Summarizer names, as accepted by NewSummarizer.
+const (
+	SummarizerHead      = "head"
+	SummarizerStructure = "structure"
+	SummarizerLLM       = "llm"
+)
+
+// NewSummarizer returns the summarizer called name ("" is head). provider
+// is only used by the LLM summarizer.
+func NewSummarizer(name string, provider llm.Provider) (Summarizer, error) {
+	switch name {
+	case "", SummarizerHead:
+		return HeadSummarizer{}, nil
+	case SummarizerStructure:
+		return StructureSummarizer{}, nil
+	case SummarizerLLM:
+		if provider == nil {
+			return nil, fmt.Errorf("summarizer %q needs an LLM provider", name)
+		}
+		return NewLLMSummarizer(llm.NewClient(provider)), nil
+	default:
+		return nil, fmt.Errorf("unknown summarizer %q (want %s, %s or %s)", name, SummarizerHead, SummarizerStructure, SummarizerLLM)
+	}
+}
+
+// HeadSummarizer summarizes content by its leading lines. It is the
+// default.
+type HeadSummarizer struct{}
+
+// Summarize returns up to opts.SummaryLines leading lines, cut to
+// opts.SummaryLength bytes.
+func (HeadSummarizer) Summarize(_ context.Context, _ ResultKey, content string, opts StoreOptions) (string, error) {
+	return generateResultSummary(content, opts), nil
+}
+
+// StructureSummarizer describes the structure of content: an outline of
+// source files, the shape of JSON, and the level counts and first error of
+// logs. Anything else gets the leading lines.
+type StructureSummarizer struct{}
+
+// Summarize describes content by its kind. Outlines are not cut to
+// opts.SummaryLength; their size is bounded by the outline itself.
+func (StructureSummarizer) Summarize(_ context.Context, key ResultKey, content string, opts StoreOptions) (string, error) {
+	if o, ok := outline.Parse(key.Key, content); ok {
+		return o.String(), nil
+	}
+	if summary, ok := jsonSummary(content); ok {
+		return text.Truncate(summary, opts.SummaryLength), nil
+	}
+	if summary, ok := logSummary(content); ok {
+		return text.Truncate(summary, opts.SummaryLength), nil
+	}
+	return generateResultSummary(content, opts), nil
+}
+
+// maxSummaryKeys caps the object keys a JSON summary lists.
+const maxSummaryKeys = 10
+
+// jsonSummary describes a JSON object or array: its keys, or its length
+// and the keys of its first element.
+func jsonSummary(content string) (string, bool) {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	var value any
+	if err := json.Unmarshal([]byte(trimmed), &value); err != nil {
+		return "", false
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return fmt.Sprintf("JSON object with %d keys: %s", len(v), summaryKeys(v)), true
+	case []any:
+		summary := fmt.Sprintf("JSON array of %d items", len(v))
+		if len(v) > 0 {
+			if first, ok := v[0].(map[string]any); ok {
+				summary += "; items are objects with keys: " + summaryKeys(first)
+			}
+		}
+		return summary, true
+	}
+	return "", false
+}
+
+// summaryKeys lists up to maxSummaryKeys keys of m, sorted.
+func summaryKeys(m map[string]any) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) > maxSummaryKeys {
+		return strings.Join(keys[:maxSummaryKeys], ", ") + fmt.Sprintf(", ... (%d more)", len(keys)-maxSummaryKeys)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// logLinePattern matches the start of a timestamped log line: a date, a
+// time of day, or a bracketed level.
+var logLinePattern = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}|\d{2}:\d{2}:\d{2}|[A-Z][a-z]{2} [ \d]\d |(?i:error|warn|warning|info|debug)\])`)
+
+// logSampleLines is how many leading lines decide whether content is a log.
+const logSampleLines = 20
+
+// looksLikeLog reports whether most of the leading lines start with a
+// timestamp or level.
+func looksLikeLog(lines []string) bool {
+	sample := lines[:min(len(lines), logSampleLines)]
+	if len(sample) < 3 {
+		return false
+	}
+	stamped := 0
+	for _, line := range sample {
+		if logLinePattern.MatchString(line) {
+			stamped++
+		}
+	}
+	return stamped*2 >= len(sample)
+}
+
+// logSummary counts errors and warnings in content that looks like a log
+// (most of its leading lines start with a timestamp) and shows the first
+// error.
+func logSummary(content string) (string, bool) {
+	lines := strings.Split(strings.TrimRight(text.NormalizeNewlines(content), "\n"), "\n")
+	if !looksLikeLog(lines) {
+		return "", false
+	}
+
+	var errs, warnings int
+	var firstError string
+	for _, line := range lines {
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.Contains(upper, "ERROR") || strings.Contains(upper, "FATAL") || strings.Contains(upper, "PANIC"):
+			errs++
+			if firstError == "" {
+				firstError = text.OneLine(line)
+			}
+		case strings.Contains(upper, "WARN"):
+			warnings++
+		}
+	}
+	summary := fmt.Sprintf("Log of %d lines (%d errors, %d warnings)", len(lines), errs, warnings)
+	if firstError != "" {
+		summary += "\nFirst error: " + firstError
+	}
+	summary += "\nLast line: " + text.OneLine(lines[len(lines)-1])
+	return summary, true
+}
+
+// llmSummaryInputBytes caps how much content is sent to the model.
+const llmSummaryInputBytes = 12000
+
+// llmSummaryMinBytes is the size below which the leading lines are left
+// as the summary; a model call isn't worth it for small content.
+const llmSummaryMinBytes = 1024
+
+// LLMSummarizer has a model describe the content. Content shorter than
+// 1KB keeps its leading lines.
+type LLMSummarizer struct {
+	client *llm.Client
+}
+
+// NewLLMSummarizer creates a summarizer that calls client.
+func NewLLMSummarizer(client *llm.Client) *LLMSummarizer {
+	return &LLMSummarizer{client: client}
+}
+
+// Summarize asks the model for a description of at most opts.SummaryLength
+// characters, sending at most the first 12KB of content.
+func (s *LLMSummarizer) Summarize(ctx context.Context, key ResultKey, content string, opts StoreOptions) (string, error) {
+	if len(content) < llmSummaryMinBytes {
+		return generateResultSummary(content, opts), nil
+	}
+	messages := []llm.ChatMessage{
+		{Role: "system", Content: llmSummaryInstructions(opts.SummaryLength)},
+		{Role: "user", Content: llmSummaryInput(key, content)},
+	}
+	summary, err := s.client.Chat(ctx, messages)
+	if err != nil {
+		return "", fmt.Errorf("summarize %s: %w", key.Key, err)
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", fmt.Errorf("summarize %s: empty response", key.Key)
+	}
+	return text.Truncate(summary, opts.SummaryLength), nil
+}
+
+// SummarizeBatch describes every content of at least 1KB with batched
+// model calls; smaller contents keep their leading lines.
+func (s *LLMSummarizer) SummarizeBatch(ctx context.Context, reqs []StoreRequest) ([]string, error) {
+	summaries := make([]string, len(reqs))
+	var inputs []string
+	var indexes []int
+	length := 0
+	for i, req := range reqs {
+		if len(req.Content) < llmSummaryMinBytes {
+			summaries[i] = generateResultSummary(req.Content, req.Options)
+			continue
+		}
+		inputs = append(inputs, llmSummaryInput(req.Key, req.Content))
+		indexes = append(indexes, i)
+		if length == 0 || req.Options.SummaryLength < length {
+			length = req.Options.SummaryLength
+		}
+	}
+	if len(inputs) == 0 {
+		return summaries, nil
+	}
+
+	result, err := s.client.ChatBatch(ctx, llmSummaryInstructions(length), inputs)
+	if err != nil {
+		return nil, fmt.Errorf("summarize %d contents: %w", len(inputs), err)
+	}
+	for j, answer := range result.Answers {
+		i := indexes[j]
+		summaries[i] = text.Truncate(strings.TrimSpace(answer), reqs[i].Options.SummaryLength)
+	}
+	return summaries, nil
+}
+
+// llmSummaryInstructions is the system prompt asking for a summary of at
+// most length characters.
+func llmSummaryInstructions(length int) string {
+	return fmt.Sprintf(`Describe stored content for an agent deciding whether to read it.
+Say what it is, how it is structured, and the most notable items (names, errors, figures).
+Answer in at most %d characters of plain text, with no preamble.`, length)
+}
+
+// llmSummaryInput is the content sent to the model, cut to 12KB.
+func llmSummaryInput(key ResultKey, content string) string {
+	input := text.Head(content, llmSummaryInputBytes)
+	if len(input) < len(content) {
+		input += fmt.Sprintf("\n[... %d more bytes not shown]", len(content)-len(input))
+	}
+	return fmt.Sprintf("Key: %s (%d lines, %d bytes)\n\n%s", key.Key, countResultLines(content), len(content), input)
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+)
+
+func TestStructureSummarizer(t *testing.T) {
+	ctx := context.Background()
+	opts := DefaultStoreOptions()
+	summarize := func(key, content string) string {
+		summary, err := StructureSummarizer{}.Summarize(ctx, ResultKey{Key: key}, content, opts)
+		if err != nil {
+			t.Fatalf("Summarize(%s) failed: %v", key, err)
+		}
+		return summary
+	}
+
+	if got := summarize("resp.json", `{"items": [], "total": 3, "next": null}`); got != "JSON object with 3 keys: items, next, total" {
+		t.Errorf("object summary = %q", got)
+	}
+	if got := summarize("list", `[{"id": 1, "name": "a"}, {"id": 2}]`); got != "JSON array of 2 items; items are objects with keys: id, name" {
+		t.Errorf("array summary = %q", got)
+	}
+
+	log := "2026-01-02 10:00:00 INFO starting\n" +
+		"2026-01-02 10:00:01 WARN slow disk\n" +
+		"2026-01-02 10:00:02 ERROR open /data: permission denied\n" +
+		"2026-01-02 10:00:03 INFO retrying\n"
+	want := "Log of 4 lines (1 errors, 1 warnings)\nFirst error: 2026-01-02 10:00:02 ERROR open /data: permission denied\nLast line: 2026-01-02 10:00:03 INFO retrying"
+	if got := summarize("app.log", log); got != want {
+		t.Errorf("log summary =\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := summarize("main.go", "package main\n\nfunc Run() {}\n"); !strings.Contains(got, "Run") {
+		t.Errorf("expected a Go outline, got %q", got)
+	}
+	if got := summarize("notes.txt", "plain\ntext"); got != "plain\ntext" {
+		t.Errorf("expected leading lines, got %q", got)
+	}
+}
+
+// replyProvider answers every chat with reply, or fails with err.
+type replyProvider struct {
+	llm.Provider
+	reply string
+	err   error
+	calls int
+}
+
+func (p *replyProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	p.calls++
+	return llm.LLMResponse{Content: p.reply}, p.err
+}
+
+func TestStoreUsesSummarizer(t *testing.T) {
+	ctx := context.Background()
+	provider := &replyProvider{reply: "  Build log of the nightly job; fails in the linker.  "}
+	summarizer, err := NewSummarizer(SummarizerLLM, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewInMemoryResultStore().WithSummarizer(summarizer)
+	defer store.Close()
+
+	big := strings.Repeat("ld: undefined reference\n", 100)
+	meta, err := store.Store(ctx, ResultKey{SessionID: "s", Key: "build.log"}, big, DefaultStoreOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Summary != "Build log of the nightly job; fails in the linker." {
+		t.Errorf("Summary = %q", meta.Summary)
+	}
+
+	// Stored content keeps its summary without another model call
+	if _, err := store.Store(ctx, ResultKey{SessionID: "s", Key: "copy.log"}, big, DefaultStoreOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected 1 model call, got %d", provider.calls)
+	}
+
+	// Small content, and model failures, fall back to leading lines
+	meta, _ = store.Store(ctx, ResultKey{SessionID: "s", Key: "small"}, "one\ntwo", DefaultStoreOptions())
+	if meta.Summary != "one\ntwo" || provider.calls != 1 {
+		t.Errorf("small content: Summary = %q, calls = %d", meta.Summary, provider.calls)
+	}
+	provider.err = errors.New("rate limited")
+	meta, _ = store.Store(ctx, ResultKey{SessionID: "s", Key: "other.log"}, big+"x", DefaultStoreOptions())
+	if !strings.HasPrefix(meta.Summary, "ld: undefined reference\n") {
+		t.Errorf("expected leading lines after a failure, got %q", meta.Summary)
+	}
+
+	// StoreOptions.Summarizer overrides the store's
+	opts := DefaultStoreOptions()
+	opts.Summarizer = SummarizerFunc(func(context.Context, ResultKey, string, StoreOptions) (string, error) {
+		return "custom", nil
+	})
+	meta, _ = store.Store(ctx, ResultKey{SessionID: "s", Key: "c"}, "fresh content", opts)
+	if meta.Summary != "custom" {
+		t.Errorf("Summary = %q, want custom", meta.Summary)
+	}
+}
+
+// batchReplyProvider answers batched requests with one summary per item,
+// or fails them with batchErr, and single requests with "single".
+type batchReplyProvider struct {
+	llm.Provider
+	batchErr error
+	batches  int
+	singles  int
+}
+
+func (p *batchReplyProvider) Name() string { return "stub" }
+
+func (p *batchReplyProvider) ChatWithFormat(ctx context.Context, messages []llm.ChatMessage, format *llm.ResponseFormat) (llm.LLMResponse, error) {
+	p.batches++
+	if p.batchErr != nil {
+		return llm.LLMResponse{}, p.batchErr
+	}
+	n := strings.Count(messages[1].Content, "Key: ")
+	answers := make([]string, n)
+	for i := range answers {
+		answers[i] = fmt.Sprintf("batched %d", i+1)
+	}
+	data, _ := json.Marshal(map[string][]string{"answers": answers})
+	return llm.LLMResponse{Content: string(data)}, nil
+}
+
+func (p *batchReplyProvider) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	p.singles++
+	return llm.LLMResponse{Content: "single"}, nil
+}
+
+func TestStoreBatchSummarizesTogether(t *testing.T) {
+	ctx := context.Background()
+	big := strings.Repeat("line of content\n", 100)
+	reqs := []StoreRequest{
+		{Key: ResultKey{SessionID: "s", Key: "a.txt"}, Content: big + "a", Options: DefaultStoreOptions()},
+		{Key: ResultKey{SessionID: "s", Key: "small"}, Content: "tiny", Options: DefaultStoreOptions()},
+		{Key: ResultKey{SessionID: "s", Key: "b.txt"}, Content: big + "b", Options: DefaultStoreOptions()},
+	}
+
+	provider := &batchReplyProvider{}
+	store := NewInMemoryResultStore().WithSummarizer(NewLLMSummarizer(llm.NewClient(provider)))
+	defer store.Close()
+	metas, err := store.StoreBatch(ctx, reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metas[0].Summary != "batched 1" || metas[1].Summary != "tiny" || metas[2].Summary != "batched 2" {
+		t.Errorf("unexpected summaries: %q, %q, %q", metas[0].Summary, metas[1].Summary, metas[2].Summary)
+	}
+	if provider.batches != 1 || provider.singles != 0 {
+		t.Errorf("expected one batched call, got %d batched and %d single", provider.batches, provider.singles)
+	}
+
+	// A failed batch request falls back to one call per item
+	provider = &batchReplyProvider{batchErr: errors.New("503 service unavailable")}
+	store = NewInMemoryResultStore().WithSummarizer(NewLLMSummarizer(llm.NewClient(provider)))
+	defer store.Close()
+	metas, err = store.StoreBatch(ctx, reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metas[0].Summary != "single" || metas[2].Summary != "single" || provider.singles != 2 {
+		t.Errorf("expected per-item summaries after a failed batch, got %q, %q (%d single calls)", metas[0].Summary, metas[2].Summary, provider.singles)
+	}
+}
+
+func TestNewSummarizerRejectsUnknown(t *testing.T) {
+	if _, err := NewSummarizer("magic", nil); err == nil {
+		t.Error("expected an error for an unknown summarizer")
+	}
+	if _, err := NewSummarizer(SummarizerLLM, nil); err == nil {
+		t.Error("expected an error for the LLM summarizer without a provider")
+	}
+}
@@ -0,0 +1,214 @@
+// Content tags for stored results.
+//
+// Store tags every result with its language, the kind of content it is
+// (code, log, config, doc, data or text) and a size bucket, so agents can
+// list what is relevant without fetching it. Tags come from the key's file
+// extension first, then from the content itself (JSON, timestamped log
+// lines, a shebang).
+//
+// Information Hiding:
+// - Extension tables and content sniffing hidden behind ClassifyContent
+// - Size bucket boundaries hidden
+
+package storage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/richinex/ariadne/internal/text"
+)
+
+// Content kinds.
+const (
+	KindCode   = "code"
+	KindLog    = "log"
+	KindConfig = "config"
+	KindDoc    = "doc"
+	KindData   = "data"
+	KindText   = "text"
+)
+
+// Size buckets.
+const (
+	SizeSmall  = "small"  // Under 4KB
+	SizeMedium = "medium" // Under 64KB
+	SizeLarge  = "large"  // Under 1MB
+	SizeHuge   = "huge"
+)
+
+// ResultTags classify stored content.
+type ResultTags struct {
+	Language string `json:"language,omitempty"` // e.g. go, python, yaml; empty if unknown
+	Kind     string `json:"kind,omitempty"`
+	Size     string `json:"size,omitempty"`
+}
+
+// Matches reports whether t has every tag set in filter; empty filter
+// fields match anything.
+func (t ResultTags) Matches(filter ResultTags) bool {
+	return (filter.Language == "" || strings.EqualFold(filter.Language, t.Language)) &&
+		(filter.Kind == "" || strings.EqualFold(filter.Kind, t.Kind)) &&
+		(filter.Size == "" || strings.EqualFold(filter.Size, t.Size))
+}
+
+// String renders the tags set, e.g. "code, go, small".
+func (t ResultTags) String() string {
+	var parts []string
+	for _, tag := range []string{t.Kind, t.Language, t.Size} {
+		if tag != "" {
+			parts = append(parts, tag)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// extensionTags maps file extensions to their kind and language.
+var extensionTags = map[string]ResultTags{
+	".go":         {Kind: KindCode, Language: "go"},
+	".py":         {Kind: KindCode, Language: "python"},
+	".ts":         {Kind: KindCode, Language: "typescript"},
+	".tsx":        {Kind: KindCode, Language: "typescript"},
+	".js":         {Kind: KindCode, Language: "javascript"},
+	".jsx":        {Kind: KindCode, Language: "javascript"},
+	".mjs":        {Kind: KindCode, Language: "javascript"},
+	".rs":         {Kind: KindCode, Language: "rust"},
+	".java":       {Kind: KindCode, Language: "java"},
+	".kt":         {Kind: KindCode, Language: "kotlin"},
+	".c":          {Kind: KindCode, Language: "c"},
+	".h":          {Kind: KindCode, Language: "c"},
+	".cc":         {Kind: KindCode, Language: "cpp"},
+	".cpp":        {Kind: KindCode, Language: "cpp"},
+	".hpp":        {Kind: KindCode, Language: "cpp"},
+	".cs":         {Kind: KindCode, Language: "csharp"},
+	".rb":         {Kind: KindCode, Language: "ruby"},
+	".php":        {Kind: KindCode, Language: "php"},
+	".swift":      {Kind: KindCode, Language: "swift"},
+	".scala":      {Kind: KindCode, Language: "scala"},
+	".sh":         {Kind: KindCode, Language: "shell"},
+	".bash":       {Kind: KindCode, Language: "shell"},
+	".sql":        {Kind: KindCode, Language: "sql"},
+	".css":        {Kind: KindCode, Language: "css"},
+	".yaml":       {Kind: KindConfig, Language: "yaml"},
+	".yml":        {Kind: KindConfig, Language: "yaml"},
+	".toml":       {Kind: KindConfig, Language: "toml"},
+	".ini":        {Kind: KindConfig, Language: "ini"},
+	".cfg":        {Kind: KindConfig, Language: "ini"},
+	".conf":       {Kind: KindConfig},
+	".env":        {Kind: KindConfig},
+	".properties": {Kind: KindConfig},
+	".mod":        {Kind: KindConfig},
+	".md":         {Kind: KindDoc, Language: "markdown"},
+	".markdown":   {Kind: KindDoc, Language: "markdown"},
+	".rst":        {Kind: KindDoc, Language: "rst"},
+	".adoc":       {Kind: KindDoc, Language: "asciidoc"},
+	".txt":        {Kind: KindDoc},
+	".html":       {Kind: KindDoc, Language: "html"},
+	".htm":        {Kind: KindDoc, Language: "html"},
+	".log":        {Kind: KindLog},
+	".json":       {Kind: KindData, Language: "json"},
+	".jsonl":      {Kind: KindData, Language: "json"},
+	".csv":        {Kind: KindData, Language: "csv"},
+	".tsv":        {Kind: KindData, Language: "csv"},
+	".xml":        {Kind: KindData, Language: "xml"},
+}
+
+// configNames are files without a telling extension that hold config.
+var configNames = map[string]string{
+	"dockerfile":     "dockerfile",
+	"makefile":       "make",
+	"package.json":   "json",
+	"tsconfig.json":  "json",
+	"cargo.toml":     "toml",
+	"pyproject.toml": "toml",
+	".gitignore":     "",
+	".editorconfig":  "ini",
+}
+
+// interpreters maps shebang interpreters to languages.
+var interpreters = map[string]string{
+	"sh":      "shell",
+	"bash":    "shell",
+	"zsh":     "shell",
+	"python":  "python",
+	"python3": "python",
+	"node":    "javascript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+}
+
+// ClassifyContent tags content stored under key, which is usually a path
+// or URL.
+func ClassifyContent(key, content string) ResultTags {
+	tags := classifyKind(key, content)
+	tags.Size = sizeBucket(len(content))
+	return tags
+}
+
+func classifyKind(key, content string) ResultTags {
+	name := strings.ToLower(filepath.Base(key))
+	if lang, ok := configNames[name]; ok {
+		return ResultTags{Kind: KindConfig, Language: lang}
+	}
+	if tags, ok := extensionTags[strings.ToLower(filepath.Ext(name))]; ok {
+		return tags
+	}
+
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "#!") {
+		line, _, _ := strings.Cut(trimmed, "\n")
+		fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+		if len(fields) > 0 {
+			interpreter := filepath.Base(fields[0])
+			if interpreter == "env" && len(fields) > 1 {
+				interpreter = fields[1]
+			}
+			return ResultTags{Kind: KindCode, Language: interpreters[interpreter]}
+		}
+	}
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return ResultTags{Kind: KindData, Language: "json"}
+	}
+	lines := strings.Split(text.NormalizeNewlines(trimmed), "\n")
+	if looksLikeLog(lines) {
+		return ResultTags{Kind: KindLog}
+	}
+	return ResultTags{Kind: KindText}
+}
+
+func sizeBucket(n int) string {
+	switch {
+	case n < 4<<10:
+		return SizeSmall
+	case n < 64<<10:
+		return SizeMedium
+	case n < 1<<20:
+		return SizeLarge
+	default:
+		return SizeHuge
+	}
+}
+
+// encodeTags renders tags for the metadata column.
+func encodeTags(tags ResultTags) string {
+	data, err := json.Marshal(struct {
+		Tags ResultTags `json:"tags"`
+	}{tags})
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// decodeTags reads tags from the metadata column; ok is false if there
+// are none (rows stored before tagging).
+func decodeTags(metadata string) (ResultTags, bool) {
+	var m struct {
+		Tags *ResultTags `json:"tags"`
+	}
+	if metadata == "" || json.Unmarshal([]byte(metadata), &m) != nil || m.Tags == nil {
+		return ResultTags{}, false
+	}
+	return *m.Tags, true
+}
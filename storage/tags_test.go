@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassifyContent(t *testing.T) {
+	log := "2026-01-02 10:00:00 INFO starting\n2026-01-02 10:00:01 WARN slow\n2026-01-02 10:00:02 ERROR failed\n"
+	tests := []struct {
+		key, content string
+		want         ResultTags
+	}{
+		{"src/main.go", "package main", ResultTags{Kind: KindCode, Language: "go", Size: SizeSmall}},
+		{"config/app.YAML", "a: 1", ResultTags{Kind: KindConfig, Language: "yaml", Size: SizeSmall}},
+		{"Dockerfile", "FROM alpine", ResultTags{Kind: KindConfig, Language: "dockerfile", Size: SizeSmall}},
+		{"README.md", "# Title", ResultTags{Kind: KindDoc, Language: "markdown", Size: SizeSmall}},
+		{"build", log, ResultTags{Kind: KindLog, Size: SizeSmall}},
+		{"api:response", `{"ok": true}`, ResultTags{Kind: KindData, Language: "json", Size: SizeSmall}},
+		{"script", "#!/usr/bin/env python3\nprint(1)", ResultTags{Kind: KindCode, Language: "python", Size: SizeSmall}},
+		{"notes", "just some words", ResultTags{Kind: KindText, Size: SizeSmall}},
+		{"big.txt", strings.Repeat("x", 5000), ResultTags{Kind: KindDoc, Size: SizeMedium}},
+		{"huge.txt", strings.Repeat("x", 2<<20), ResultTags{Kind: KindDoc, Size: SizeHuge}},
+	}
+	for _, tt := range tests {
+		if got := ClassifyContent(tt.key, tt.content); got != tt.want {
+			t.Errorf("ClassifyContent(%q) = %+v, want %+v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestResultTagsMatches(t *testing.T) {
+	tags := ResultTags{Kind: KindCode, Language: "go", Size: SizeSmall}
+	if !tags.Matches(ResultTags{}) {
+		t.Error("empty filter should match")
+	}
+	if !tags.Matches(ResultTags{Kind: "CODE", Language: "go"}) {
+		t.Error("filter should match case-insensitively")
+	}
+	if tags.Matches(ResultTags{Kind: KindCode, Size: SizeLarge}) {
+		t.Error("filter with a different size should not match")
+	}
+	if got := tags.String(); got != "code, go, small" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestListFiltersByTags(t *testing.T) {
+	store := NewInMemoryResultStore()
+	defer store.Close()
+	ctx := context.Background()
+
+	_, _ = store.Store(ctx, ResultKey{SessionID: "s", Key: "src/main.go"}, "package main", DefaultStoreOptions())
+	_, _ = store.Store(ctx, ResultKey{SessionID: "s", Key: "src/app.yaml"}, "a: 1", DefaultStoreOptions())
+	_, _ = store.Store(ctx, ResultKey{SessionID: "s", Key: "docs/guide.md"}, "# Guide", DefaultStoreOptions())
+
+	code, err := store.List(ctx, "s", QueryOptions{Tags: ResultTags{Kind: KindCode}})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(code) != 1 || code[0].Key.Key != "src/main.go" {
+		t.Errorf("List(kind=code) = %+v", code)
+	}
+
+	config, err := store.GetByPrefix(ctx, "s", "src/", QueryOptions{Tags: ResultTags{Language: "yaml"}})
+	if err != nil {
+		t.Fatalf("GetByPrefix failed: %v", err)
+	}
+	if len(config) != 1 || config[0].Key.Key != "src/app.yaml" {
+		t.Errorf("GetByPrefix(src/, language=yaml) = %+v", config)
+	}
+}
+
+func TestTagsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags.db")
+	ctx := context.Background()
+
+	open := func() *ResultStore {
+		db, err := OpenSqlite(path)
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		store, err := NewResultStore(db)
+		if err != nil {
+			db.Close()
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		return store
+	}
+
+	store := open()
+	if _, err := store.Store(ctx, ResultKey{SessionID: "s", Key: "lib.py"}, "def f(): pass", DefaultStoreOptions()); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	store.Close()
+
+	db, err := OpenSqlite(path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	rows, err := db.LoadResultsBySession(ctx, "s")
+	db.Close()
+	if err != nil || len(rows) != 1 || !strings.Contains(rows[0].Metadata, `"language":"python"`) {
+		t.Fatalf("persisted rows = %+v, %v", rows, err)
+	}
+
+	store = open()
+	defer store.Close()
+	meta, err := store.GetMetadata(ctx, ResultKey{SessionID: "s", Key: "lib.py"})
+	if err != nil || meta == nil {
+		t.Fatalf("GetMetadata = %v, %v", meta, err)
+	}
+	if want := (ResultTags{Kind: KindCode, Language: "python", Size: SizeSmall}); meta.Tags != want {
+		t.Errorf("reloaded tags = %+v, want %+v", meta.Tags, want)
+	}
+}
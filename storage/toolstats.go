@@ -0,0 +1,82 @@
+// Package storage provides persistent tool usage analytics.
+//
+// ToolStatsStorage records individual tool invocations per run so that
+// aggregate statistics (call counts, failure rates, output sizes) can be
+// reported across runs. It also keeps each run's result store usage.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/richinex/ariadne/model"
+)
+
+// ToolStatsStorage persists tool invocation records and aggregates them.
+type ToolStatsStorage interface {
+	// RecordToolCalls stores a batch of tool invocation records.
+	RecordToolCalls(ctx context.Context, records []ToolCallRecord) error
+
+	// ToolUsageStats returns aggregate statistics per tool, largest output first.
+	ToolUsageStats(ctx context.Context) ([]ToolUsageStats, error)
+
+	// RecordDSAUsage stores a run's result store usage, replacing any
+	// earlier record of the run.
+	RecordDSAUsage(ctx context.Context, runID string, usage model.DSAUsage) error
+
+	// DSAUsageTotals sums result store usage across runs.
+	DSAUsageTotals(ctx context.Context) (DSAUsageTotals, error)
+}
+
+// ToolCallRecord is a single persisted tool invocation.
+type ToolCallRecord struct {
+	RunID      string // Run that made the call
+	ToolName   string // Name of the invoked tool
+	ArgsHash   string // Hash of the call arguments (identical calls share a hash)
+	InputSize  int    // Size of arguments in bytes
+	OutputSize int    // Size of output in bytes
+	DurationMs uint64 // Execution time
+	Success    bool   // Whether the call succeeded
+	CreatedAt  int64  // Unix timestamp
+}
+
+// ToolUsageStats aggregates tool invocations across runs.
+type ToolUsageStats struct {
+	ToolName         string
+	Runs             int    // Distinct runs that used the tool
+	Calls            int    // Total invocations
+	Failures         int    // Failed invocations
+	RepeatedCalls    int    // Calls repeating identical arguments within a run
+	TotalOutputBytes int64  // Sum of output sizes
+	TotalDurationMs  uint64 // Sum of execution times
+}
+
+// FailureRate returns the fraction of calls that failed.
+func (s ToolUsageStats) FailureRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Calls)
+}
+
+// AvgOutputSize returns the mean output size in bytes.
+func (s ToolUsageStats) AvgOutputSize() int64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalOutputBytes / int64(s.Calls)
+}
+
+// AvgDurationMs returns the mean execution time in milliseconds.
+func (s ToolUsageStats) AvgDurationMs() uint64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalDurationMs / uint64(s.Calls)
+}
+
+// DSAUsageTotals sums result store usage across runs.
+type DSAUsageTotals struct {
+	Runs int // Runs with recorded usage
+	model.DSAUsage
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/richinex/ariadne/model"
+)
+
+func TestSqliteToolUsageStats(t *testing.T) {
+	storage, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	records := []ToolCallRecord{
+		{RunID: "run1", ToolName: "read_file", ArgsHash: "a", OutputSize: 100, DurationMs: 10, Success: true},
+		{RunID: "run1", ToolName: "read_file", ArgsHash: "a", OutputSize: 100, DurationMs: 10, Success: true},
+		{RunID: "run2", ToolName: "read_file", ArgsHash: "a", OutputSize: 100, DurationMs: 10, Success: false},
+		{RunID: "run1", ToolName: "glob", ArgsHash: "b", OutputSize: 10, DurationMs: 2, Success: true},
+	}
+	if err := storage.RecordToolCalls(ctx, records); err != nil {
+		t.Fatalf("RecordToolCalls failed: %v", err)
+	}
+
+	stats, err := storage.ToolUsageStats(ctx)
+	if err != nil {
+		t.Fatalf("ToolUsageStats failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(stats))
+	}
+
+	read := stats[0]
+	if read.ToolName != "read_file" {
+		t.Fatalf("expected read_file first (largest output), got %s", read.ToolName)
+	}
+	if read.Calls != 3 || read.Runs != 2 || read.Failures != 1 {
+		t.Errorf("unexpected counts: %+v", read)
+	}
+	if read.RepeatedCalls != 1 {
+		t.Errorf("expected 1 repeated call (same args within run1), got %d", read.RepeatedCalls)
+	}
+	if read.AvgOutputSize() != 100 || read.AvgDurationMs() != 10 {
+		t.Errorf("unexpected averages: out=%d ms=%d", read.AvgOutputSize(), read.AvgDurationMs())
+	}
+	if rate := read.FailureRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("expected failure rate ~0.33, got %f", rate)
+	}
+}
+
+func TestSqliteToolUsageStatsEmpty(t *testing.T) {
+	storage, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.RecordToolCalls(context.Background(), nil); err != nil {
+		t.Fatalf("RecordToolCalls with no records failed: %v", err)
+	}
+	stats, err := storage.ToolUsageStats(context.Background())
+	if err != nil {
+		t.Fatalf("ToolUsageStats failed: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Errorf("expected no stats, got %d", len(stats))
+	}
+}
+
+func TestSqliteDSAUsage(t *testing.T) {
+	storage, err := NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	totals, err := storage.DSAUsageTotals(ctx)
+	if err != nil || totals.Runs != 0 || totals.FilesStored != 0 {
+		t.Fatalf("empty totals = %+v, %v", totals, err)
+	}
+
+	usage := model.DSAUsage{FilesStored: 3, BytesIndexed: 300, Searches: 2, CacheHits: 1, GetLinesCalls: 4, BytesSaved: 1000}
+	for _, runID := range []string{"run1", "run2", "run2"} {
+		if err := storage.RecordDSAUsage(ctx, runID, usage); err != nil {
+			t.Fatalf("RecordDSAUsage failed: %v", err)
+		}
+	}
+
+	totals, err = storage.DSAUsageTotals(ctx)
+	if err != nil {
+		t.Fatalf("DSAUsageTotals failed: %v", err)
+	}
+	want := DSAUsageTotals{Runs: 2, DSAUsage: model.DSAUsage{
+		FilesStored: 6, BytesIndexed: 600, Searches: 4, CacheHits: 2, GetLinesCalls: 8, BytesSaved: 2000,
+	}}
+	if totals != want {
+		t.Errorf("totals = %+v, want %+v", totals, want)
+	}
+}
@@ -0,0 +1,239 @@
+// Artifacts Directory.
+//
+// A managed area (.ariadne/artifacts/<run-id>/) that write_file outputs
+// are redirected into for sandboxed runs, keeping agent-produced files out
+// of the user's repository. append_file, edit_file and format_code work
+// copy-on-write: the first change to a file copies the original into the
+// run's directory and changes the copy. read_file reads the copy once
+// there is one, so the agent sees what it wrote.
+//
+// Information Hiding:
+// - Path confinement (absolute paths and .. are mapped inside the run dir) hidden
+// - Copy-on-write of existing files hidden
+// - Directory layout hidden
+
+package tools
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultArtifactsRoot is where run artifact directories are created.
+const DefaultArtifactsRoot = ".ariadne/artifacts"
+
+// Artifact describes one file produced during a run.
+type Artifact struct {
+	Path    string    `json:"path"` // Relative to the run's artifact directory
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ArtifactRun summarizes one run's artifact directory.
+type ArtifactRun struct {
+	RunID   string    `json:"run_id"`
+	Files   int       `json:"files"`
+	Bytes   int64     `json:"bytes"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ArtifactDir is the artifact directory of a single run.
+type ArtifactDir struct {
+	runID string
+	dir   string
+}
+
+// NewArtifactDir creates the artifact directory for a run under root.
+func NewArtifactDir(root, runID string) (*ArtifactDir, error) {
+	if err := validateRunID(runID); err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(root, runID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+	return &ArtifactDir{runID: runID, dir: dir}, nil
+}
+
+// RunID returns the run this directory belongs to.
+func (a *ArtifactDir) RunID() string {
+	return a.runID
+}
+
+// Dir returns the directory path.
+func (a *ArtifactDir) Dir() string {
+	return a.dir
+}
+
+// Resolve maps a path requested by the agent to a location inside the
+// artifact directory. Absolute paths are made relative and ".." segments
+// can't escape the directory.
+func (a *ArtifactDir) Resolve(path string) string {
+	rel := filepath.Clean("/" + filepath.ToSlash(path))
+	return filepath.Join(a.dir, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+}
+
+// Locate returns where the run sees path: its artifact copy if one exists,
+// otherwise the original. Paths already inside the directory (as recorded
+// by an EditTracker) are returned unchanged.
+func (a *ArtifactDir) Locate(path string) string {
+	if a.contains(path) {
+		return path
+	}
+	if target := a.Resolve(path); fileExists(target) {
+		return target
+	}
+	return path
+}
+
+// CopyOnWrite returns the artifact path to change instead of path, first
+// copying the original there if the run hasn't written a copy yet. A
+// missing original leaves the copy missing too.
+func (a *ArtifactDir) CopyOnWrite(path string) (string, error) {
+	if a.contains(path) {
+		return path, nil
+	}
+	target := a.Resolve(path)
+	if fileExists(target) {
+		return target, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return target, nil
+		}
+		return "", fmt.Errorf("failed to read original: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(target, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to copy original: %w", err)
+	}
+	return target, nil
+}
+
+// contains reports whether path is inside the directory.
+func (a *ArtifactDir) contains(path string) bool {
+	dir, err := filepath.Abs(a.dir)
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// fileExists reports whether path is an existing regular file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// List returns the run's artifacts sorted by path.
+func (a *ArtifactDir) List() ([]Artifact, error) {
+	return listArtifacts(a.dir)
+}
+
+// ListArtifactRuns returns the runs under root, newest first.
+func ListArtifactRuns(root string) ([]ArtifactRun, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read artifacts directory: %w", err)
+	}
+
+	var runs []ArtifactRun
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		artifacts, err := listArtifacts(filepath.Join(root, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		run := ArtifactRun{RunID: entry.Name(), Files: len(artifacts)}
+		if info, err := entry.Info(); err == nil {
+			run.ModTime = info.ModTime()
+		}
+		for _, artifact := range artifacts {
+			run.Bytes += artifact.Size
+			if artifact.ModTime.After(run.ModTime) {
+				run.ModTime = artifact.ModTime
+			}
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ModTime.After(runs[j].ModTime) })
+	return runs, nil
+}
+
+// ListRunArtifacts returns the artifacts of a run under root.
+func ListRunArtifacts(root, runID string) ([]Artifact, error) {
+	if err := validateRunID(runID); err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(root, runID)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("run %q has no artifacts: %w", runID, err)
+	}
+	return listArtifacts(dir)
+}
+
+// ReadArtifact reads one artifact of a run under root.
+func ReadArtifact(root, runID, path string) ([]byte, error) {
+	if err := validateRunID(runID); err != nil {
+		return nil, err
+	}
+	dir := &ArtifactDir{runID: runID, dir: filepath.Join(root, runID)}
+	content, err := os.ReadFile(dir.Resolve(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	return content, nil
+}
+
+func listArtifacts(dir string) ([]Artifact, error) {
+	var artifacts []Artifact
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, Artifact{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path < artifacts[j].Path })
+	return artifacts, nil
+}
+
+// validateRunID rejects run IDs that would escape the artifacts root.
+func validateRunID(runID string) error {
+	if runID == "" || runID == "." || runID == ".." || strings.ContainsAny(runID, `/\`) {
+		return fmt.Errorf("invalid run ID: %q", runID)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArtifactDirResolveStaysInside(t *testing.T) {
+	dir, err := NewArtifactDir(t.TempDir(), "run-1")
+	if err != nil {
+		t.Fatalf("NewArtifactDir failed: %v", err)
+	}
+
+	for _, path := range []string{"out.txt", "/etc/passwd", "../../escape.txt", "a/../../b.txt"} {
+		resolved := dir.Resolve(path)
+		if !strings.HasPrefix(resolved, dir.Dir()+string(filepath.Separator)) {
+			t.Errorf("Resolve(%q) = %q escapes %q", path, resolved, dir.Dir())
+		}
+	}
+
+	if _, err := NewArtifactDir(t.TempDir(), "../run"); err == nil {
+		t.Error("expected error for run ID with path separator")
+	}
+}
+
+func TestWriteFileToolRedirectsToArtifacts(t *testing.T) {
+	root := t.TempDir()
+	dir, _ := NewArtifactDir(root, "run-1")
+
+	write := NewWriteFileTool(1024).WithArtifactDir(dir)
+	args, _ := json.Marshal(map[string]string{"path": "/reports/summary.md", "content": "# Summary"})
+	result, err := write.Execute(context.Background(), args)
+	if err != nil || !result.Success() {
+		t.Fatalf("write failed: %v %v", err, result.Error)
+	}
+
+	appendTool := NewAppendFileTool(1024).WithArtifactDir(dir)
+	args, _ = json.Marshal(map[string]string{"path": "reports/summary.md", "content": "\nmore"})
+	if result, _ := appendTool.Execute(context.Background(), args); !result.Success() {
+		t.Fatalf("append failed: %v", result.Error)
+	}
+
+	if _, err := os.Stat("/reports/summary.md"); err == nil {
+		t.Fatal("write escaped the artifact directory")
+	}
+
+	content, err := ReadArtifact(root, "run-1", "reports/summary.md")
+	if err != nil || string(content) != "# Summary\nmore" {
+		t.Fatalf("unexpected artifact content %q (%v)", content, err)
+	}
+
+	runs, err := ListArtifactRuns(root)
+	if err != nil || len(runs) != 1 || runs[0].RunID != "run-1" || runs[0].Files != 1 {
+		t.Errorf("unexpected runs %+v (%v)", runs, err)
+	}
+
+	artifacts, _ := ListRunArtifacts(root, "run-1")
+	if len(artifacts) != 1 || artifacts[0].Path != "reports/summary.md" {
+		t.Errorf("unexpected artifacts %+v", artifacts)
+	}
+}
+
+func TestEditFileToolEditsArtifactCopy(t *testing.T) {
+	repo := t.TempDir()
+	original := filepath.Join(repo, "config.txt")
+	if err := os.WriteFile(original, []byte("mode = debug\nlevel = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := NewArtifactDir(t.TempDir(), "run-1")
+	edit := NewEditFileTool(1024).WithArtifactDir(dir)
+
+	for _, replace := range [][2]string{{"debug", "release"}, {"level = 1", "level = 2"}} {
+		args, _ := json.Marshal(map[string]string{"path": original, "search": replace[0], "replace": replace[1]})
+		if result, _ := edit.Execute(context.Background(), args); !result.Success() {
+			t.Fatalf("edit failed: %v", result.Error)
+		}
+	}
+
+	if content, _ := os.ReadFile(original); string(content) != "mode = debug\nlevel = 1\n" {
+		t.Errorf("original was modified: %q", content)
+	}
+	if content, _ := os.ReadFile(dir.Resolve(original)); string(content) != "mode = release\nlevel = 2\n" {
+		t.Errorf("unexpected artifact copy %q", content)
+	}
+
+	args, _ := json.Marshal(map[string]string{"path": filepath.Join(repo, "missing.txt"), "search": "a", "replace": "b"})
+	if result, _ := edit.Execute(context.Background(), args); result.Success() {
+		t.Error("editing a missing file should fail")
+	}
+}
+
+func TestFormatToolFormatsArtifactCopy(t *testing.T) {
+	repo := t.TempDir()
+	original := filepath.Join(repo, "main.go")
+	unformatted := "package main\n\nfunc main() {\nx:=1\n_ = x\n}\n"
+	if err := os.WriteFile(original, []byte(unformatted), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := NewArtifactDir(t.TempDir(), "run-1")
+	format := NewFormatTool(30).WithArtifactDir(dir)
+
+	args, _ := json.Marshal(formatArgs{Paths: []string{original}, Check: true})
+	if result, _ := format.Execute(context.Background(), args); !result.Success() || !strings.Contains(result.Output, "Would reformat 1 of 1 files") {
+		t.Fatalf("unexpected check result: %+v", result)
+	}
+	if _, err := os.Stat(dir.Resolve(original)); err == nil {
+		t.Error("check mode should not create an artifact copy")
+	}
+
+	args, _ = json.Marshal(formatArgs{Paths: []string{original}})
+	if result, _ := format.Execute(context.Background(), args); !result.Success() || !strings.Contains(result.Output, "Formatted 1 of 1 files") {
+		t.Fatalf("unexpected format result: %+v", result)
+	}
+	if content, _ := os.ReadFile(original); string(content) != unformatted {
+		t.Errorf("original was modified: %q", content)
+	}
+	if content, _ := os.ReadFile(dir.Resolve(original)); !strings.Contains(string(content), "\tx := 1\n") {
+		t.Errorf("artifact copy not formatted:\n%s", content)
+	}
+}
+
+func TestAppendFileToolAppendsToArtifactCopy(t *testing.T) {
+	repo := t.TempDir()
+	original := filepath.Join(repo, "CHANGELOG.md")
+	if err := os.WriteFile(original, []byte("# Changes\n- first\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := NewArtifactDir(t.TempDir(), "run-1")
+	appendTool := NewAppendFileTool(1024).WithArtifactDir(dir)
+
+	for _, line := range []string{"- second\n", "- third\n"} {
+		args, _ := json.Marshal(map[string]string{"path": original, "content": line})
+		if result, _ := appendTool.Execute(context.Background(), args); !result.Success() {
+			t.Fatalf("append failed: %v", result.Error)
+		}
+	}
+
+	if got, _ := os.ReadFile(original); string(got) != "# Changes\n- first\n" {
+		t.Errorf("original was modified: %q", got)
+	}
+	// The artifact holds the whole file, so promoting it loses nothing
+	if got, _ := os.ReadFile(dir.Resolve(original)); string(got) != "# Changes\n- first\n- second\n- third\n" {
+		t.Errorf("unexpected artifact content %q", got)
+	}
+}
+
+func TestReadFileToolReadsArtifactCopy(t *testing.T) {
+	repo := t.TempDir()
+	original := filepath.Join(repo, "notes.txt")
+	if err := os.WriteFile(original, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := NewArtifactDir(t.TempDir(), "run-1")
+	read := NewReadFileTool(1024).WithArtifactDir(dir)
+	readPath := func(path string) ToolResult {
+		args, _ := json.Marshal(map[string]string{"path": path})
+		result, _ := read.Execute(context.Background(), args)
+		return result
+	}
+
+	// Nothing written yet: the original is read
+	if result := readPath(original); result.Output != "original" {
+		t.Fatalf("expected the original, got %q (%v)", result.Output, result.Error)
+	}
+
+	args, _ := json.Marshal(map[string]string{"path": original, "content": "rewritten"})
+	if result, _ := NewWriteFileTool(1024).WithArtifactDir(dir).Execute(context.Background(), args); !result.Success() {
+		t.Fatalf("write failed: %v", result.Error)
+	}
+	if result := readPath(original); result.Output != "rewritten" {
+		t.Errorf("expected the run's copy, got %q (%v)", result.Output, result.Error)
+	}
+
+	// A new file that only exists as an artifact can be read back too
+	args, _ = json.Marshal(map[string]string{"path": "plan.md", "content": "# Plan"})
+	if result, _ := NewWriteFileTool(1024).WithArtifactDir(dir).Execute(context.Background(), args); !result.Success() {
+		t.Fatalf("write failed: %v", result.Error)
+	}
+	if result := readPath("plan.md"); result.Output != "# Plan" {
+		t.Errorf("expected the new artifact, got %q (%v)", result.Output, result.Error)
+	}
+}
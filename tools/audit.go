@@ -0,0 +1,132 @@
+// Audit Logging of Mutating Tool Calls.
+//
+// Every file write and shell command run through an Executor with an
+// AuditLogger is recorded as it completes: tool, acting agent, session,
+// run, outcome and a SHA-256 of the arguments. Arguments themselves are
+// not kept, so the log holds no file contents or secrets but can still
+// prove which call was made.
+//
+// Information Hiding:
+// - Which tools count as mutating
+// - Argument hashing
+// - Acting agent carried in the context
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// mutatingTools change files or run commands.
+var mutatingTools = map[string]bool{
+	"write_file":    true,
+	"edit_file":     true,
+	"append_file":   true,
+	"format_code":   true,
+	"execute_shell": true,
+	"execute_bash":  true,
+}
+
+// IsMutating reports whether calls to toolName are audited.
+func IsMutating(toolName string) bool {
+	return mutatingTools[toolName]
+}
+
+// agentKey is the context key holding the acting agent's name.
+type agentKey struct{}
+
+// WithAgent returns a context whose tool calls are attributed to agent.
+func WithAgent(ctx context.Context, agent string) context.Context {
+	return context.WithValue(ctx, agentKey{}, agent)
+}
+
+// AgentFrom returns the agent set by WithAgent, or "" if there is none.
+func AgentFrom(ctx context.Context) string {
+	agent, _ := ctx.Value(agentKey{}).(string)
+	return agent
+}
+
+// AuditLogger records a run's mutating tool calls. Share one logger
+// between a run's executors so sub-agents' calls are included.
+type AuditLogger struct {
+	store     storage.AuditStorage
+	sessionID string
+	runID     string
+}
+
+// NewAuditLogger creates a logger writing to store for one run.
+func NewAuditLogger(store storage.AuditStorage, sessionID, runID string) *AuditLogger {
+	return &AuditLogger{store: store, sessionID: sessionID, runID: runID}
+}
+
+// Close closes the logger's store if it can be closed. Safe on nil.
+func (l *AuditLogger) Close() error {
+	if l == nil {
+		return nil
+	}
+	if closer, ok := l.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Record appends an entry for a completed call if toolName is mutating.
+// Entries are written immediately, so a crashed run keeps its trail. A
+// failed write is reported on stderr rather than failing a call that has
+// already happened.
+func (l *AuditLogger) Record(ctx context.Context, toolName string, args json.RawMessage, result ToolResult, err error) {
+	if l == nil || !IsMutating(toolName) {
+		return
+	}
+	agent := AgentFrom(ctx)
+	if agent == "" {
+		agent = "root"
+	}
+	entry := storage.AuditEntry{
+		SessionID: l.sessionID,
+		RunID:     l.runID,
+		AgentID:   agent,
+		ToolName:  toolName,
+		ArgsHash:  auditHash(args),
+		Status:    auditStatus(result, err),
+		CreatedAt: time.Now().Unix(),
+	}
+	// Record even when the run was cancelled mid-call
+	if err := l.store.RecordAudit(context.WithoutCancel(ctx), entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: audit log: %v\n", err)
+	}
+}
+
+// auditStatus classifies a call's outcome.
+func auditStatus(result ToolResult, err error) string {
+	switch {
+	case err == nil && result.Success():
+		return storage.AuditSuccess
+	case errors.Is(result.Error, ErrToolDenied):
+		return storage.AuditDenied
+	default:
+		return storage.AuditFailure
+	}
+}
+
+// auditHash returns the SHA-256 of args after compacting whitespace.
+func auditHash(args json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, args); err != nil {
+		buf.Reset()
+		buf.Write(args)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// memoryAudit keeps audit entries in memory.
+type memoryAudit struct {
+	entries []storage.AuditEntry
+}
+
+func (m *memoryAudit) RecordAudit(ctx context.Context, entry storage.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryAudit) ListAudit(ctx context.Context, filter storage.AuditFilter) ([]storage.AuditEntry, error) {
+	return m.entries, nil
+}
+
+func TestExecutorAuditsMutatingCalls(t *testing.T) {
+	store := &memoryAudit{}
+	executor := NewExecutor(ToolConfig{
+		Audit:  NewAuditLogger(store, "session-1", "run-1"),
+		Filter: NewToolFilter(nil, []string{"execute_shell"}),
+	})
+	ctx := WithAgent(context.Background(), "sub-agent 1.2")
+
+	_, _ = executor.Execute(ctx, cannedTool{name: "write_file", output: "ok"}, json.RawMessage(`{"path": "a.txt",  "content": "secret"}`))
+	_, _ = executor.Execute(ctx, cannedTool{name: "read_file", output: "text"}, json.RawMessage(`{"path": "a.txt"}`))
+	_, _ = executor.Execute(context.Background(), cannedTool{name: "execute_shell"}, json.RawMessage(`{"command": "rm"}`))
+
+	if len(store.entries) != 2 {
+		t.Fatalf("expected write_file and execute_shell to be audited, got %+v", store.entries)
+	}
+	write := store.entries[0]
+	if write.SessionID != "session-1" || write.RunID != "run-1" || write.AgentID != "sub-agent 1.2" || write.Status != storage.AuditSuccess {
+		t.Errorf("unexpected write_file entry: %+v", write)
+	}
+	if write.ArgsHash != auditHash(json.RawMessage(`{"path":"a.txt","content":"secret"}`)) || len(write.ArgsHash) != 64 {
+		t.Errorf("arguments should be stored as a SHA-256 of their compact form: %q", write.ArgsHash)
+	}
+	if shell := store.entries[1]; shell.Status != storage.AuditDenied || shell.AgentID != "root" {
+		t.Errorf("a denied call should be audited as denied by the root agent: %+v", shell)
+	}
+}
@@ -150,7 +150,7 @@ func (t *BashTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 
 	// Validate command
 	if !t.isCommandAllowed(a.Command) {
-		return FailureResultf("command '%s' is not allowed", a.Command), nil
+		return DeniedResultf("command '%s' is not allowed", a.Command), nil
 	}
 
 	// Validate subcommand if policy requires it
@@ -160,7 +160,7 @@ func (t *BashTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 			return FailureResultf("subcommand required but not provided"), nil
 		}
 		if !t.isSubcommandAllowed(subcommand) {
-			return FailureResultf("subcommand '%s' is not allowed", subcommand), nil
+			return DeniedResultf("subcommand '%s' is not allowed", subcommand), nil
 		}
 	}
 
@@ -173,7 +173,7 @@ func (t *BashTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 				return FailureResultf("resource type required but not provided"), nil
 			}
 			if !t.isResourceAllowed(resource) {
-				return FailureResultf("resource type '%s' is not allowed", resource), nil
+				return DeniedResultf("resource type '%s' is not allowed", resource), nil
 			}
 		}
 	}
@@ -193,20 +193,20 @@ func (t *BashTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 		if !endOfFlags && strings.HasPrefix(arg, "-") {
 			flag := normalizeFlag(arg)
 			if !t.isFlagAllowed(flag) {
-				return FailureResultf("flag '%s' is not allowed", flag), nil
+				return DeniedResultf("flag '%s' is not allowed", flag), nil
 			}
 			continue
 		}
 
 		if !t.isArgAllowed(arg) {
-			return FailureResultf("argument '%s' is not allowed", arg), nil
+			return DeniedResultf("argument '%s' is not allowed", arg), nil
 		}
 	}
 
 	// Validate environment variables
 	for key := range a.Env {
 		if !t.isEnvAllowed(key) {
-			return FailureResultf("environment variable '%s' is not allowed", key), nil
+			return DeniedResultf("environment variable '%s' is not allowed", key), nil
 		}
 	}
 
@@ -220,7 +220,7 @@ func (t *BashTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 			return FailureResultf("working directory is not a directory: %s", a.Cwd), nil
 		}
 		if !t.isCwdAllowed(a.Cwd) {
-			return FailureResultf("working directory '%s' is not allowed", a.Cwd), nil
+			return DeniedResultf("working directory '%s' is not allowed", a.Cwd), nil
 		}
 	}
 
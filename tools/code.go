@@ -0,0 +1,343 @@
+// Code Execution Tool - Starlark REPL for symbolic recursion.
+//
+// Zhang's RLM pattern embeds spawn calls inside code: a loop or map over
+// sub-agent answers instead of the LLM orchestrating each call by hand.
+// run_starlark executes a snippet of Starlark, the Python dialect used by
+// script tools, where spawn(), parallel_spawn(), call() and try_call()
+// reach back into the host's tools, so results can be filtered, combined
+// and aggregated in code.
+//
+// Starlark is the sandbox: it has no imports, no file, network or process
+// access and no way to reach the host except the tools it is given. Each
+// snippet runs on a fresh thread with a step limit and is cancelled at
+// the timeout. Memory is not limited: starlark-go keeps no allocation
+// accounting, so the bounds are its 1GiB cap on a single value, the step
+// limit on how many values a snippet builds, and printed output kept only
+// up to the output cap. Given the run's Executor, host calls are filtered,
+// checked, audited and masked like the agent's own calls.
+//
+// Information Hiding:
+// - Interpreter setup and final-expression evaluation hidden
+// - Spawn result unwrapping hidden
+// - Output capping hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/richinex/ariadne/internal/text"
+)
+
+// DefaultCodeOutputBytes caps the output run_starlark returns.
+const DefaultCodeOutputBytes = 64 * 1024
+
+// CodeExecTool runs Starlark snippets that can call back into host tools.
+type CodeExecTool struct {
+	BaseTool
+	timeoutSecs    uint64
+	maxOutputBytes int
+	hostTools      map[string]Tool
+	executor       *Executor
+}
+
+// NewCodeExecTool creates a run_starlark tool with the given timeout.
+func NewCodeExecTool(timeoutSecs uint64) *CodeExecTool {
+	return &CodeExecTool{
+		timeoutSecs:    timeoutSecs,
+		maxOutputBytes: DefaultCodeOutputBytes,
+		hostTools:      make(map[string]Tool),
+	}
+}
+
+// WithHostTools makes tools callable from code via call(name, **args).
+// Tools named "spawn" and "parallel_spawn" back the spawn helpers.
+func (t *CodeExecTool) WithHostTools(tools ...Tool) *CodeExecTool {
+	for _, tool := range tools {
+		t.hostTools[tool.Metadata().Name] = tool
+	}
+	return t
+}
+
+// WithExecutor runs host tool calls through executor.
+func (t *CodeExecTool) WithExecutor(executor *Executor) *CodeExecTool {
+	t.executor = executor
+	return t
+}
+
+// WithMaxOutput caps the captured output returned to the agent.
+func (t *CodeExecTool) WithMaxOutput(bytes int) *CodeExecTool {
+	t.maxOutputBytes = bytes
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *CodeExecTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name: "run_starlark",
+		Description: fmt.Sprintf(`Run a Starlark snippet (a Python dialect) in a sandboxed interpreter. Use it to combine sub-agent results in code instead of one tool call at a time:
+  answers = parallel_spawn(["Summarize " + f for f in files])
+  [a for a in answers if "TODO" in a]
+Built-ins: spawn(task, context="") -> str, parallel_spawn(tasks) -> list[str], call(tool_name, **args) -> str (fails the snippet on tool errors), try_call(tool_name, **args) -> {"ok", "output", "error"}, json.encode/json.decode.
+Callable tools: %s. The value of a final expression is returned along with printed output.
+Starlark has no import, class, try/except or file, network and process access; use try_call() to handle errors and call() with host tools for everything else.`, t.hostToolNames()),
+		Parameters: []ToolParameter{
+			{Name: "code", ParamType: "string", Description: "Starlark (Python-like) source to execute", Required: true},
+		},
+	}
+}
+
+type codeArgs struct {
+	Code string `json:"code"`
+}
+
+// Validate validates the arguments.
+func (t *CodeExecTool) Validate(args json.RawMessage) error {
+	var a codeArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(a.Code) == "" {
+		return fmt.Errorf("code cannot be empty")
+	}
+	return nil
+}
+
+// Execute runs the code.
+func (t *CodeExecTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	var a codeArgs
+	_ = json.Unmarshal(args, &a) // validated above
+
+	file, err := scriptFileOptions.Parse("<code>", a.Code, 0)
+	if err != nil {
+		return FailureResultf("syntax error: %v", err), nil
+	}
+	// A trailing expression is evaluated separately so its value is shown
+	var last syntax.Expr
+	if n := len(file.Stmts); n > 0 {
+		if stmt, ok := file.Stmts[n-1].(*syntax.ExprStmt); ok {
+			last = stmt.X
+			file.Stmts = file.Stmts[:n-1]
+		}
+	}
+
+	host := &scriptHost{tools: t.hostTools, self: t, executor: t.executor}
+	predeclared := host.predeclared()
+	predeclared["spawn"] = starlark.NewBuiltin("spawn", host.builtinSpawn)
+	predeclared["parallel_spawn"] = starlark.NewBuiltin("parallel_spawn", host.builtinParallelSpawn)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.timeoutSecs)*time.Second)
+	defer cancel()
+	thread, stop := newScriptThread(ctx, "run_starlark")
+	defer stop()
+	output := &tailBuffer{max: t.maxOutputBytes}
+	thread.Print = func(_ *starlark.Thread, msg string) {
+		output.WriteString(msg)
+		output.WriteString("\n")
+	}
+
+	value, err := t.run(thread, file, last, predeclared)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return FailureResultf("run_starlark timed out after %d seconds", t.timeoutSecs), nil
+	}
+	if err != nil {
+		detail := err.Error()
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			detail = evalErr.Backtrace()
+		}
+		return FailureResultf("starlark error:\n%s\noutput:\n%s", strings.TrimSpace(detail), output.String()), nil
+	}
+	if value != nil && value != starlark.None {
+		output.WriteString("=> " + value.String() + "\n")
+	}
+	if strings.TrimSpace(output.String()) == "" {
+		return SuccessResult("(no output)"), nil
+	}
+	return SuccessResult(output.String()), nil
+}
+
+// run executes file, then evaluates last (if any) in its globals.
+func (t *CodeExecTool) run(thread *starlark.Thread, file *syntax.File, last syntax.Expr, predeclared starlark.StringDict) (starlark.Value, error) {
+	program, err := starlark.FileProgram(file, predeclared.Has)
+	if err != nil {
+		return nil, err
+	}
+	globals, err := program.Init(thread, predeclared)
+	if err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, nil
+	}
+	env := make(starlark.StringDict, len(predeclared)+len(globals))
+	for name, v := range predeclared {
+		env[name] = v
+	}
+	for name, v := range globals {
+		env[name] = v
+	}
+	return starlark.EvalExprOptions(scriptFileOptions, thread, last, env)
+}
+
+// builtinSpawn implements spawn(task, context=""): runs a sub-agent and
+// returns its answer.
+func (h *scriptHost) builtinSpawn(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var task, taskContext string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "task", &task, "context?", &taskContext); err != nil {
+		return nil, err
+	}
+	output, err := h.callJSON(thread, "spawn", map[string]string{"task": task, "context": taskContext})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.String(spawnAnswer(json.RawMessage(output))), nil
+}
+
+// builtinParallelSpawn implements parallel_spawn(tasks): runs sub-agents
+// concurrently and returns their answers in order. Tasks are strings or
+// {"task", "context"} dicts; failed tasks yield "error: ..." strings.
+func (h *scriptHost) builtinParallelSpawn(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var tasks *starlark.List
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "tasks", &tasks); err != nil {
+		return nil, err
+	}
+	items := make([]map[string]string, tasks.Len())
+	for i := range items {
+		switch v := tasks.Index(i).(type) {
+		case starlark.String:
+			items[i] = map[string]string{"task": string(v)}
+		case *starlark.Dict:
+			item := make(map[string]string)
+			for _, key := range []string{"task", "context"} {
+				if value, found, _ := v.Get(starlark.String(key)); found {
+					s, ok := starlark.AsString(value)
+					if !ok {
+						return nil, fmt.Errorf("%s: task %d: %s must be a string", b.Name(), i, key)
+					}
+					item[key] = s
+				}
+			}
+			items[i] = item
+		default:
+			return nil, fmt.Errorf("%s: task %d must be a string or a dict, got %s", b.Name(), i, v.Type())
+		}
+	}
+
+	output, err := h.callJSON(thread, "parallel_spawn", map[string]any{"tasks": items})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	var results map[string]struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		return nil, fmt.Errorf("%s: unexpected output: %w", b.Name(), err)
+	}
+	answers := make([]starlark.Value, len(items))
+	for i := range items {
+		r, ok := results[fmt.Sprintf("task_%d", i)]
+		switch {
+		case ok && len(r.Result) > 0:
+			answers[i] = starlark.String(spawnAnswer(r.Result))
+		case ok && r.Error != "":
+			answers[i] = starlark.String("error: " + r.Error)
+		default:
+			answers[i] = starlark.String("error: no result")
+		}
+	}
+	return starlark.NewList(answers), nil
+}
+
+// callJSON runs a host tool with args and returns its output, or an error
+// if it is missing or fails.
+func (h *scriptHost) callJSON(thread *starlark.Thread, name string, args any) (string, error) {
+	tool, ok := h.tools[name]
+	if !ok {
+		return "", fmt.Errorf("%s is not available", name)
+	}
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	result, err := h.execute(thread, tool, raw)
+	if err != nil {
+		return "", err
+	}
+	if !result.Success() {
+		return "", result.Error
+	}
+	return result.Output, nil
+}
+
+// spawnAnswer returns the answer of a sub-agent result: sub-agents reply
+// with {"answer", "confidence", ...}, code wants the answer. raw may be
+// that object, a JSON string holding it, or plain text.
+func spawnAnswer(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		text = string(raw)
+	}
+	var structured struct {
+		Answer *string `json:"answer"`
+	}
+	if err := json.Unmarshal([]byte(text), &structured); err == nil && structured.Answer != nil {
+		return *structured.Answer
+	}
+	return text
+}
+
+func (t *CodeExecTool) hostToolNames() string {
+	if len(t.hostTools) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(t.hostTools))
+	for name := range t.hostTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// tailBuffer collects output, keeping the last max bytes (all if max <= 0)
+// where errors and results appear. It drops older output as it is written,
+// so a snippet that prints in a loop can't grow it without bound.
+type tailBuffer struct {
+	max     int
+	buf     []byte
+	dropped int
+}
+
+func (b *tailBuffer) WriteString(s string) {
+	b.buf = append(b.buf, s...)
+	if b.max > 0 && len(b.buf) > 2*b.max {
+		cut := len(b.buf) - b.max
+		b.dropped += cut
+		b.buf = append(b.buf[:0], b.buf[cut:]...)
+	}
+}
+
+// String returns the kept output, marked if any was dropped.
+func (b *tailBuffer) String() string {
+	s := string(b.buf)
+	if b.max > 0 {
+		s = text.Tail(s, b.max)
+	}
+	if dropped := b.dropped + len(b.buf) - len(s); dropped > 0 {
+		return fmt.Sprintf("[... %d bytes truncated ...]\n%s", dropped, s)
+	}
+	return s
+}
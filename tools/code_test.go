@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// echoTool answers spawn calls so code can be tested without an LLM.
+type echoTool struct {
+	BaseTool
+	name string
+}
+
+func (e echoTool) Metadata() ToolMetadata { return ToolMetadata{Name: e.name} }
+
+func (e echoTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a spawnArgs
+	_ = json.Unmarshal(args, &a)
+	if a.Task == "fail" {
+		return FailureResultf("task failed"), nil
+	}
+	return SuccessResult("answer to " + a.Task), nil
+}
+
+func newTestCodeTool(t *testing.T) *CodeExecTool {
+	return NewCodeExecTool(30).WithHostTools(echoTool{name: "spawn"})
+}
+
+func runCode(t *testing.T, tool *CodeExecTool, code string) ToolResult {
+	args, _ := json.Marshal(codeArgs{Code: code})
+	result, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	return result
+}
+
+func TestCodeExecSpawnInLoop(t *testing.T) {
+	tool := newTestCodeTool(t)
+	result := runCode(t, tool, `
+answers = [spawn("q%d" % i) for i in range(3)]
+print(len(answers))
+[a.upper() for a in answers]
+`)
+	if !result.Success() {
+		t.Fatalf("expected success, got %v", result.Error)
+	}
+	want := "3\n=> [\"ANSWER TO Q0\", \"ANSWER TO Q1\", \"ANSWER TO Q2\"]\n"
+	if result.Output != want {
+		t.Errorf("got %q, want %q", result.Output, want)
+	}
+}
+
+func TestCodeExecToolErrors(t *testing.T) {
+	tool := newTestCodeTool(t)
+
+	result := runCode(t, tool, `
+r = try_call("spawn", task="fail")
+print(r["ok"], r["error"])
+`)
+	if !result.Success() || result.Output != "False task failed\n" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	result = runCode(t, tool, `spawn("fail")`)
+	if result.Success() || !strings.Contains(result.Error.Error(), "task failed") {
+		t.Errorf("expected spawn failure, got %+v", result)
+	}
+
+	result = runCode(t, tool, `call("missing")`)
+	if result.Success() || !strings.Contains(result.Error.Error(), `unknown tool "missing"`) {
+		t.Errorf("expected unknown tool error, got %+v", result)
+	}
+}
+
+func TestCodeExecRestrictions(t *testing.T) {
+	tool := newTestCodeTool(t)
+
+	for _, code := range []string{
+		"import os",
+		`__name__ = "x"\nimport os`,
+		`open("/etc/passwd")`,
+		`__import__("os")`,
+		`call("run_starlark", code="1")`,
+	} {
+		result := runCode(t, tool, code)
+		if result.Success() {
+			t.Errorf("expected %q to be blocked, got %q", code, result.Output)
+		}
+	}
+
+	if result := runCode(t, tool, "json.encode(sorted([3, 1, 2]))"); !result.Success() || result.Output != "=> \"[1,2,3]\"\n" {
+		t.Errorf("expected json module to work, got %+v", result)
+	}
+
+	// Runaway loops stop at the step limit or the timeout, whichever is first
+	tool.timeoutSecs = 1
+	result := runCode(t, tool, "while True:\n    pass")
+	if result.Success() || !(strings.Contains(result.Error.Error(), "timed out") || strings.Contains(result.Error.Error(), "too many steps")) {
+		t.Errorf("expected loop to be stopped, got %+v", result)
+	}
+}
+
+func TestCodeExecCallsGoThroughExecutor(t *testing.T) {
+	store := &memoryAudit{}
+	executor := NewExecutor(ToolConfig{
+		Audit:  NewAuditLogger(store, "session-1", "run-1"),
+		Filter: NewToolFilter(nil, []string{"execute_shell"}),
+	})
+	shellCalls := 0
+	tool := NewCodeExecTool(30).
+		WithExecutor(executor).
+		WithHostTools(cannedTool{name: "write_file", output: "written"}, cannedTool{name: "execute_shell", calls: &shellCalls})
+
+	result := runCode(t, tool, `
+print(call("write_file", path = "a.txt", content = "hi"))
+try_call("execute_shell", command = "rm -rf /")["error"]
+`)
+	if !result.Success() || !strings.Contains(result.Output, "written") || !strings.Contains(result.Output, "disabled") {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if shellCalls != 0 {
+		t.Error("a tool denied by the run's filter should not run from code")
+	}
+	if len(store.entries) != 2 || store.entries[0].ToolName != "write_file" || store.entries[1].Status != storage.AuditDenied {
+		t.Errorf("expected write_file and the denied execute_shell in the audit log, got %+v", store.entries)
+	}
+}
+
+func TestCodeExecCapsOutputAsPrinted(t *testing.T) {
+	tool := newTestCodeTool(t).WithMaxOutput(10)
+	output := &tailBuffer{max: tool.maxOutputBytes}
+	for i := 0; i < 1000; i++ {
+		output.WriteString("é")
+	}
+	if len(output.buf) > 2*tool.maxOutputBytes {
+		t.Errorf("expected at most %d bytes kept, got %d", 2*tool.maxOutputBytes, len(output.buf))
+	}
+	if got := output.String(); got != "[... 1990 bytes truncated ...]\n"+strings.Repeat("é", 5) {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	result := runCode(t, tool, "for i in range(1000):\n    print(\"line\")\n\"end\"")
+	if !result.Success() || !strings.HasPrefix(result.Output, "[... ") || !strings.HasSuffix(result.Output, "=> \"end\"\n") {
+		t.Errorf("expected the output truncated to its end, got %+v", result)
+	}
+}
@@ -0,0 +1,98 @@
+// Context Packs.
+//
+// A context pack is a named snapshot of files ingested once into its own
+// ResultStore namespace ("context/<name>"). Runs mount a pack by linking
+// its entries into the run's session, so repeated tasks over the same
+// docs or sources skip re-ingestion. The pack namespace itself is never
+// written by a run, so mounting is read-only.
+//
+// Information Hiding:
+// - Pack namespace layout hidden
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// contextPackPrefix namespaces context packs in the ResultStore.
+const contextPackPrefix = "context/"
+
+// ContextPackSession returns the ResultStore session holding a pack.
+func ContextPackSession(name string) string {
+	return contextPackPrefix + name
+}
+
+// ContextPackSummary reports what changed in a pack.
+type ContextPackSummary struct {
+	Name string
+	IndexSummary
+}
+
+// CreateContextPack ingests files under paths into a named pack. Re-running
+// it for an existing pack is incremental: only new or changed files are
+// re-stored and files no longer present are dropped. Directories are walked
+// recursively, honoring .gitignore; binary files and files over maxFileSize
+// are skipped.
+func CreateContextPack(ctx context.Context, store storage.ResultStoreInterface, name string, paths []string, maxFileSize int64) (ContextPackSummary, error) {
+	summary := ContextPackSummary{Name: name}
+	if err := validateContextPackName(name); err != nil {
+		return summary, err
+	}
+
+	index, err := IndexPaths(ctx, store, ContextPackSession(name), paths, maxFileSize)
+	summary.IndexSummary = index
+	if err != nil {
+		return summary, fmt.Errorf("failed to build context pack %q: %w", name, err)
+	}
+	return summary, nil
+}
+
+// MountContextPack links every entry of a pack into sessionID. Content is
+// deduplicated by hash, so this adds index entries rather than copies.
+// Returns the number of mounted files.
+func MountContextPack(ctx context.Context, store storage.ResultStoreInterface, name, sessionID string) (int, error) {
+	if err := validateContextPackName(name); err != nil {
+		return 0, err
+	}
+	session := ContextPackSession(name)
+	entries, err := store.List(ctx, session, storage.QueryOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list context pack %q: %w", name, err)
+	}
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("context pack %q not found (create it with: ariadne context create %s --ingest <path>)", name, name)
+	}
+
+	reqs := make([]storage.StoreRequest, 0, len(entries))
+	for _, meta := range entries {
+		result, err := store.Get(ctx, meta.Key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load %s from context pack %q: %w", meta.Key.Key, name, err)
+		}
+		if result == nil {
+			continue
+		}
+		reqs = append(reqs, storage.StoreRequest{
+			Key:     storage.ResultKey{SessionID: sessionID, Key: meta.Key.Key},
+			Content: result.Content,
+			Options: storage.DefaultStoreOptions(),
+		})
+	}
+	if _, err := store.StoreBatch(ctx, reqs); err != nil {
+		return 0, fmt.Errorf("failed to mount context pack %q: %w", name, err)
+	}
+	return len(entries), nil
+}
+
+// validateContextPackName keeps pack names usable as a namespace segment.
+func validateContextPackName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\ `) {
+		return fmt.Errorf("invalid context pack name: %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestContextPackCreateAndMount(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("docs/intro.md", "welcome to the intro")
+	write("docs/build/out.txt", "generated")
+	write("docs/logo.png", "\x89PNG\x00\x00")
+	write(".gitignore", "build/\n")
+
+	store := storage.NewInMemoryResultStore()
+	ctx := context.Background()
+
+	summary, err := CreateContextPack(ctx, store, "docs", []string{root}, 1024)
+	if err != nil {
+		t.Fatalf("CreateContextPack failed: %v", err)
+	}
+	// .gitignore and intro.md ingested; build/ ignored; the png skipped as binary
+	if summary.Files() != 2 || summary.Skipped != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	n, err := MountContextPack(ctx, store, "docs", "file")
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 mounted files, got %d (%v)", n, err)
+	}
+	matches, _ := store.Search(ctx, "file", "welcome", 10)
+	if len(matches) != 1 {
+		t.Errorf("expected mounted content to be searchable, got %v", matches)
+	}
+
+	// Clearing the run session leaves the pack intact
+	_ = store.DeleteSession(ctx, "file")
+	if n, err := MountContextPack(ctx, store, "docs", "file"); err != nil || n != 2 {
+		t.Errorf("expected pack to survive run cleanup, got %d (%v)", n, err)
+	}
+
+	if _, err := MountContextPack(ctx, store, "missing", "file"); err == nil {
+		t.Error("expected error mounting unknown pack")
+	}
+}
@@ -0,0 +1,427 @@
+// Dependency Graph Tool - structural view of stored code.
+//
+// Parses imports of stored Go, Python and TypeScript/JavaScript files and
+// builds a package-level dependency graph, rendered as JSON or Graphviz DOT,
+// with queries for what a package depends on and what depends on it.
+//
+// Information Hiding:
+// - Per-language node naming and import resolution hidden
+// - Graph traversal for transitive queries hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/richinex/ariadne/internal/outline"
+	"github.com/richinex/ariadne/storage"
+)
+
+// Dependency graph actions.
+const (
+	DepGraphBuild        = "build"
+	DepGraphDependents   = "dependents"
+	DepGraphDependencies = "dependencies"
+)
+
+// DepGraphNode is a package (Go directory, Python module, or TS/JS file).
+type DepGraphNode struct {
+	ID       string `json:"id"`
+	Language string `json:"language,omitempty"`
+	Files    int    `json:"files,omitempty"`
+	External bool   `json:"external,omitempty"` // Imported but not among stored files
+}
+
+// DepGraphEdge is an import from one node to another.
+type DepGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DepGraph is a package-level import graph.
+type DepGraph struct {
+	Nodes []DepGraphNode `json:"nodes"`
+	Edges []DepGraphEdge `json:"edges"`
+}
+
+// DepGraphTool builds dependency graphs from stored source files.
+type DepGraphTool struct {
+	BaseTool
+	store     *storage.ResultStore
+	sessionID string
+}
+
+// NewDepGraphTool creates a dependency graph tool over a ResultStore session.
+func NewDepGraphTool(store *storage.ResultStore, sessionID string) *DepGraphTool {
+	return &DepGraphTool{store: store, sessionID: sessionID}
+}
+
+func (t *DepGraphTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "build_depgraph",
+		Description: "Build a package dependency graph from imports of stored Go/Python/TypeScript/JavaScript files (read them with read_file first). Actions: build (graph as JSON or DOT), dependents (what imports target), dependencies (what target imports).",
+		Parameters: []ToolParameter{
+			{Name: "action", ParamType: "string", Description: "build (default), dependents, or dependencies", Required: false},
+			{Name: "target", ParamType: "string", Description: "Package/module for dependents/dependencies queries (e.g. 'storage', 'app.models', 'src/utils')", Required: false},
+			{Name: "format", ParamType: "string", Description: "Output for build: json (default) or dot", Required: false},
+			{Name: "prefix", ParamType: "string", Description: "Only include stored files whose key starts with this prefix", Required: false},
+			{Name: "transitive", ParamType: "boolean", Description: "For queries, follow edges transitively (default false)", Required: false},
+			{Name: "include_external", ParamType: "boolean", Description: "Include third-party/stdlib imports as nodes (default false)", Required: false},
+		},
+	}
+}
+
+type depGraphArgs struct {
+	Action          string `json:"action"`
+	Target          string `json:"target"`
+	Format          string `json:"format"`
+	Prefix          string `json:"prefix"`
+	Transitive      bool   `json:"transitive"`
+	IncludeExternal bool   `json:"include_external"`
+}
+
+func (t *DepGraphTool) Validate(args json.RawMessage) error {
+	var a depGraphArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	switch a.Action {
+	case "", DepGraphBuild:
+		if a.Format != "" && a.Format != "json" && a.Format != "dot" {
+			return fmt.Errorf("format must be json or dot, got %q", a.Format)
+		}
+	case DepGraphDependents, DepGraphDependencies:
+		if a.Target == "" {
+			return fmt.Errorf("target cannot be empty for %s", a.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q (use build, dependents, or dependencies)", a.Action)
+	}
+	return nil
+}
+
+func (t *DepGraphTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if t.store == nil {
+		return FailureResultf("no result store available"), nil
+	}
+
+	var a depGraphArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+
+	files, err := t.sourceFiles(ctx, a.Prefix)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	if len(files) == 0 {
+		return FailureResultf("no stored Go/Python/TypeScript/JavaScript files found; read source files with read_file first"), nil
+	}
+
+	graph := BuildDepGraph(files, a.IncludeExternal)
+
+	switch a.Action {
+	case "", DepGraphBuild:
+		if a.Format == "dot" {
+			return SuccessResult(graph.DOT()), nil
+		}
+		out, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return FailureResult(fmt.Errorf("failed to encode graph: %w", err)), nil
+		}
+		return SuccessResult(string(out)), nil
+	default:
+		target, ok := graph.resolve(a.Target)
+		if !ok {
+			return FailureResultf("target %q not found in graph (%d nodes); use action=build to list nodes", a.Target, len(graph.Nodes)), nil
+		}
+		reverse := a.Action == DepGraphDependents
+		related := graph.related(target, reverse, a.Transitive)
+
+		relation := "imports"
+		if reverse {
+			relation = "is imported by"
+		}
+		if len(related) == 0 {
+			return SuccessResult(fmt.Sprintf("%s %s nothing among the stored files", target, relation)), nil
+		}
+		scope := "directly"
+		if a.Transitive {
+			scope = "transitively"
+		}
+		return SuccessResult(fmt.Sprintf("%s %s %s %d packages:\n- %s", target, scope, relation, len(related), strings.Join(related, "\n- "))), nil
+	}
+}
+
+// sourceFiles loads stored files with a supported language, keyed by path.
+func (t *DepGraphTool) sourceFiles(ctx context.Context, prefix string) (map[string]string, error) {
+	var metas []storage.ResultMetadata
+	var err error
+	if prefix != "" {
+		metas, err = t.store.GetByPrefix(ctx, t.sessionID, prefix, storage.QueryOptions{})
+	} else {
+		metas, err = t.store.List(ctx, t.sessionID, storage.QueryOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored content: %w", err)
+	}
+
+	files := make(map[string]string)
+	for _, meta := range metas {
+		if outline.Language(meta.Key.Key) == "" {
+			continue
+		}
+		result, err := t.store.Get(ctx, meta.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", meta.Key.Key, err)
+		}
+		if result != nil {
+			files[meta.Key.Key] = result.Content
+		}
+	}
+	return files, nil
+}
+
+// BuildDepGraph builds a package-level graph from source files keyed by path.
+// Go files are grouped by directory, Python files by module, and TS/JS files
+// are their own nodes. Imports that don't resolve to a stored package are
+// external and only kept when includeExternal is set.
+func BuildDepGraph(files map[string]string, includeExternal bool) *DepGraph {
+	type parsed struct {
+		node    string
+		dir     string
+		lang    string
+		imports []string
+	}
+
+	root := absoluteRoot(files)
+	nodes := make(map[string]*DepGraphNode)
+	var all []parsed
+	for key, content := range files {
+		lang := outline.Language(key)
+		if lang == "" {
+			continue
+		}
+		// Files with no imports or symbols still become nodes
+		var imports []string
+		if o, ok := outline.Parse(key, content); ok {
+			imports = o.Imports
+		}
+		filePath := path.Clean(strings.ReplaceAll(key, "\\", "/"))
+		if root != "" {
+			filePath = strings.TrimPrefix(filePath, strings.TrimSuffix(root, "/")+"/")
+		}
+		node := depNodeID(filePath, lang)
+		if n, exists := nodes[node]; exists {
+			n.Files++
+		} else {
+			nodes[node] = &DepGraphNode{ID: node, Language: lang, Files: 1}
+		}
+		all = append(all, parsed{node: node, dir: path.Dir(filePath), lang: lang, imports: imports})
+	}
+
+	edges := make(map[DepGraphEdge]bool)
+	for _, p := range all {
+		for _, imp := range p.imports {
+			to, internal := resolveImport(imp, p.dir, p.lang, nodes)
+			if to == p.node {
+				continue
+			}
+			if !internal {
+				if !includeExternal {
+					continue
+				}
+				if _, exists := nodes[to]; !exists {
+					nodes[to] = &DepGraphNode{ID: to, External: true}
+				}
+			}
+			edges[DepGraphEdge{From: p.node, To: to}] = true
+		}
+	}
+
+	graph := &DepGraph{}
+	for _, n := range nodes {
+		graph.Nodes = append(graph.Nodes, *n)
+	}
+	for e := range edges {
+		graph.Edges = append(graph.Edges, e)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph
+}
+
+// absoluteRoot returns the common parent directory when every key is an
+// absolute path, so nodes are named relative to the project rather than
+// the filesystem. Returns "" otherwise.
+func absoluteRoot(files map[string]string) string {
+	root := ""
+	first := true
+	for key := range files {
+		if outline.Language(key) == "" {
+			continue
+		}
+		key = strings.ReplaceAll(key, "\\", "/")
+		if !strings.HasPrefix(key, "/") {
+			return ""
+		}
+		dir := path.Dir(path.Clean(key))
+		if first {
+			root, first = dir, false
+			continue
+		}
+		for root != "/" && dir != root && !strings.HasPrefix(dir, root+"/") {
+			root = path.Dir(root)
+		}
+	}
+	if first {
+		return ""
+	}
+	// Keep the root's own name so files directly in it get a named node
+	return path.Dir(root)
+}
+
+// depNodeID names the node a file belongs to.
+func depNodeID(filePath, lang string) string {
+	switch lang {
+	case outline.LangGo:
+		return path.Dir(filePath)
+	case outline.LangPython:
+		module := strings.TrimSuffix(strings.TrimSuffix(filePath, ".pyi"), ".py")
+		module = strings.TrimSuffix(module, "/__init__")
+		return strings.ReplaceAll(strings.TrimPrefix(module, "/"), "/", ".")
+	default:
+		return strings.TrimSuffix(filePath, path.Ext(filePath))
+	}
+}
+
+// resolveImport maps an import to a stored node. Returns the import itself
+// and false when it is external.
+func resolveImport(imp, dir, lang string, nodes map[string]*DepGraphNode) (string, bool) {
+	switch lang {
+	case outline.LangGo:
+		// Import paths end with the package directory: match the longest suffix
+		best := ""
+		for id, n := range nodes {
+			if n.Language != outline.LangGo || n.External {
+				continue
+			}
+			if (imp == id || strings.HasSuffix(imp, "/"+strings.TrimPrefix(id, "./"))) && len(id) > len(best) {
+				best = id
+			}
+		}
+		if best != "" {
+			return best, true
+		}
+	case outline.LangPython:
+		if strings.HasPrefix(imp, ".") {
+			// Relative import: one dot is the current package
+			base := dir
+			rest := strings.TrimLeft(imp, ".")
+			for i := 1; i < len(imp)-len(rest); i++ {
+				base = path.Dir(base)
+			}
+			imp = strings.ReplaceAll(strings.TrimPrefix(path.Join(base, strings.ReplaceAll(rest, ".", "/")), "/"), "/", ".")
+		}
+		for candidate := imp; candidate != ""; {
+			for id, n := range nodes {
+				if n.Language == outline.LangPython && (id == candidate || strings.HasSuffix(id, "."+candidate)) {
+					return id, true
+				}
+			}
+			// "from pkg.mod import name" may name a module or a symbol in one
+			i := strings.LastIndex(candidate, ".")
+			if i < 0 {
+				break
+			}
+			candidate = candidate[:i]
+		}
+	default:
+		if strings.HasPrefix(imp, ".") {
+			target := path.Join(dir, imp)
+			target = strings.TrimSuffix(target, path.Ext(target))
+			for _, candidate := range []string{target, target + "/index"} {
+				if n, ok := nodes[candidate]; ok && !n.External {
+					return candidate, true
+				}
+			}
+		}
+	}
+	return imp, false
+}
+
+// resolve finds a node by exact ID or unique suffix match.
+func (g *DepGraph) resolve(target string) (string, bool) {
+	var matches []string
+	for _, n := range g.Nodes {
+		if n.ID == target {
+			return n.ID, true
+		}
+		if strings.HasSuffix(n.ID, "/"+target) || strings.HasSuffix(n.ID, "."+target) {
+			matches = append(matches, n.ID)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+	return "", false
+}
+
+// related returns nodes target imports (or, if reverse, nodes importing
+// target), optionally following edges transitively.
+func (g *DepGraph) related(target string, reverse, transitive bool) []string {
+	adjacent := make(map[string][]string)
+	for _, e := range g.Edges {
+		if reverse {
+			adjacent[e.To] = append(adjacent[e.To], e.From)
+		} else {
+			adjacent[e.From] = append(adjacent[e.From], e.To)
+		}
+	}
+
+	seen := map[string]bool{target: true}
+	queue := []string{target}
+	var result []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacent[current] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			result = append(result, next)
+			if transitive {
+				queue = append(queue, next)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// DOT renders the graph in Graphviz DOT format. External nodes are dashed.
+func (g *DepGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph deps {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		if n.External {
+			sb.WriteString(fmt.Sprintf("  %q [style=dashed];\n", n.ID))
+		}
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %q -> %q;\n", e.From, e.To))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestBuildDepGraph(t *testing.T) {
+	files := map[string]string{
+		"cli/runner.go":         "package cli\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/storage\"\n\t\"example.com/app/tools\"\n)\n",
+		"tools/read.go":         "package tools\n\nimport \"example.com/app/storage\"\n",
+		"storage/store.go":      "package storage\n\nimport \"sync\"\n",
+		"app/models.py":         "import os\nfrom app.db import Session\n",
+		"app/db.py":             "from .config import DEBUG\n",
+		"app/config.py":         "DEBUG = True\n",
+		"web/src/index.ts":      "import { api } from './api';\nimport React from 'react';\n",
+		"web/src/api/index.ts":  "export function api() {}\n",
+		"web/src/unrelated.txt": "ignored",
+	}
+
+	graph := BuildDepGraph(files, false)
+	edges := make(map[string]bool)
+	for _, e := range graph.Edges {
+		edges[e.From+" -> "+e.To] = true
+	}
+	for _, want := range []string{
+		"cli -> storage",
+		"cli -> tools",
+		"tools -> storage",
+		"app.models -> app.db",
+		"app.db -> app.config",
+		"web/src/index -> web/src/api/index",
+	} {
+		if !edges[want] {
+			t.Errorf("missing edge %q in %v", want, graph.Edges)
+		}
+	}
+	if len(graph.Edges) != 6 {
+		t.Errorf("expected only internal edges, got %v", graph.Edges)
+	}
+
+	withExternal := BuildDepGraph(files, true)
+	if !strings.Contains(withExternal.DOT(), `"fmt" [style=dashed]`) {
+		t.Errorf("expected external fmt node in DOT:\n%s", withExternal.DOT())
+	}
+
+	if got := graph.related("storage", true, false); strings.Join(got, ",") != "cli,tools" {
+		t.Errorf("unexpected dependents of storage: %v", got)
+	}
+	if got := graph.related("app.models", false, true); strings.Join(got, ",") != "app.config,app.db" {
+		t.Errorf("unexpected transitive dependencies of app.models: %v", got)
+	}
+}
+
+func TestBuildDepGraphAbsolutePaths(t *testing.T) {
+	files := map[string]string{
+		"/home/dev/app/main.go":           "package main\n\nimport \"example.com/app/internal/db\"\n",
+		"/home/dev/app/internal/db/db.go": "package db\n",
+	}
+	graph := BuildDepGraph(files, false)
+	if len(graph.Edges) != 1 || graph.Edges[0].From != "app" || graph.Edges[0].To != "app/internal/db" {
+		t.Errorf("unexpected edges %v", graph.Edges)
+	}
+}
+
+func TestDepGraphToolQueries(t *testing.T) {
+	store := storage.NewInMemoryResultStore()
+	ctx := context.Background()
+	for key, content := range map[string]string{
+		"a/a.go": "package a\n\nimport \"example.com/m/b\"\n",
+		"b/b.go": "package b\n\nimport \"example.com/m/c\"\n",
+		"c/c.go": "package c\n",
+	} {
+		if _, err := store.Store(ctx, storage.ResultKey{SessionID: "file", Key: key}, content, storage.DefaultStoreOptions()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := NewDepGraphTool(store, "file")
+
+	result, _ := tool.Execute(ctx, json.RawMessage(`{"action":"dependents","target":"c","transitive":true}`))
+	if !result.Success() || !strings.Contains(result.Output, "- a\n- b") {
+		t.Errorf("unexpected dependents result: %+v", result)
+	}
+
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"format":"dot"}`))
+	if !result.Success() || !strings.Contains(result.Output, `"a" -> "b";`) {
+		t.Errorf("unexpected DOT output: %+v", result)
+	}
+
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"action":"dependents","target":"missing"}`))
+	if result.Success() {
+		t.Error("expected failure for unknown target")
+	}
+
+	if err := tool.Validate(json.RawMessage(`{"action":"dependencies"}`)); err == nil {
+		t.Error("expected validation error for missing target")
+	}
+}
@@ -0,0 +1,335 @@
+// Build and Lint Tools - compiler/linter output as compact diagnostics.
+//
+// run_build and run_lint invoke a configurable command (go build and
+// golangci-lint by default), parse its output into file/line/severity/message
+// records, and return a short summary grouped by file instead of the raw
+// compiler output.
+//
+// Information Hiding:
+// - Diagnostic line formats (Go, golangci-lint, gcc/clang, tsc, mypy, eslint unix) hidden
+// - Command fallback when the linter is not installed hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// defaultMaxDiagnostics caps how many diagnostics a summary lists.
+const defaultMaxDiagnostics = 50
+
+// maxRawOutput caps the raw output shown when no diagnostics were parsed.
+const maxRawOutput = 2000
+
+// Diagnostic is one compiler or linter finding.
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Rule     string `json:"rule,omitempty"` // Linter or error code, e.g. "errcheck", "TS2304"
+}
+
+var (
+	// file:line[:col]: message - Go, golangci-lint, gcc/clang, mypy, ruff, eslint unix
+	diagColon = regexp.MustCompile(`^(?:\./)?([^\s:][^:]*\.[A-Za-z0-9]+):(\d+)(?::(\d+))?:\s*(.+)$`)
+	// file(line,col): error TS1234: message - tsc
+	diagParen = regexp.MustCompile(`^([^\s(][^(]*\.[A-Za-z0-9]+)\((\d+),(\d+)\):\s*(.+)$`)
+	// Leading "error:"/"warning[E123]:" severity marker
+	diagSeverity = regexp.MustCompile(`^(?i)(fatal error|error|warning|warn|note|info|hint)(?:\[([^\]]+)\]|\s+([A-Z]+\d+))?:\s*`)
+	// Trailing "(linter)" or "[rule]" as printed by golangci-lint, mypy and eslint
+	diagRule = regexp.MustCompile(`\s+(?:\(([\w-]+)\)|\[([\w./-]+)\])$`)
+)
+
+// ParseDiagnostics extracts diagnostics from compiler or linter output.
+// Lines without an explicit severity get defaultSeverity. Lines that don't
+// look like diagnostics (package headers, summaries) are ignored.
+func ParseDiagnostics(output, defaultSeverity string) []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[Diagnostic]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		var d Diagnostic
+		var rest string
+		if m := diagColon.FindStringSubmatch(line); m != nil {
+			d.File = m[1]
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			rest = m[4]
+		} else if m := diagParen.FindStringSubmatch(line); m != nil {
+			d.File = m[1]
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			rest = m[4]
+		} else {
+			continue
+		}
+
+		d.Severity = defaultSeverity
+		if m := diagSeverity.FindStringSubmatch(rest); m != nil {
+			d.Severity = normalizeSeverity(m[1])
+			d.Rule = m[2] + m[3]
+			rest = rest[len(m[0]):]
+		}
+		if d.Rule == "" {
+			if m := diagRule.FindStringSubmatch(rest); m != nil {
+				d.Rule = m[1] + m[2]
+				rest = rest[:len(rest)-len(m[0])]
+			}
+		}
+		d.Message = strings.TrimSpace(rest)
+		if d.Message == "" || seen[d] {
+			continue
+		}
+		seen[d] = true
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+func normalizeSeverity(s string) string {
+	switch strings.ToLower(s) {
+	case "fatal error", "error":
+		return SeverityError
+	case "warning", "warn":
+		return SeverityWarning
+	default:
+		return SeverityNote
+	}
+}
+
+// SummarizeDiagnostics renders diagnostics grouped by file, listing at most
+// max entries. Errors are listed before warnings and notes.
+func SummarizeDiagnostics(diags []Diagnostic, max int) string {
+	counts := make(map[string]int)
+	files := make(map[string]bool)
+	for _, d := range diags {
+		counts[d.Severity]++
+		files[d.File] = true
+	}
+
+	sorted := append([]Diagnostic(nil), diags...)
+	rank := map[string]int{SeverityError: 0, SeverityWarning: 1, SeverityNote: 2}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if rank[sorted[i].Severity] != rank[sorted[j].Severity] {
+			return rank[sorted[i].Severity] < rank[sorted[j].Severity]
+		}
+		if sorted[i].File != sorted[j].File {
+			return sorted[i].File < sorted[j].File
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d errors, %d warnings, %d notes in %d files\n",
+		counts[SeverityError], counts[SeverityWarning], counts[SeverityNote], len(files)))
+	for i, d := range sorted {
+		if max > 0 && i == max {
+			sb.WriteString(fmt.Sprintf("... and %d more\n", len(sorted)-max))
+			break
+		}
+		loc := fmt.Sprintf("%s:%d", d.File, d.Line)
+		if d.Column > 0 {
+			loc += fmt.Sprintf(":%d", d.Column)
+		}
+		rule := ""
+		if d.Rule != "" {
+			rule = " [" + d.Rule + "]"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s: %s%s\n", loc, d.Severity, d.Message, rule))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// DiagnosticsTool runs a build or lint command and reports its diagnostics.
+type DiagnosticsTool struct {
+	BaseTool
+	name            string
+	description     string
+	command         []string
+	fallback        []string // Used when command's executable is not installed
+	defaultSeverity string
+	timeoutSecs     uint64
+	maxDiagnostics  int
+}
+
+// NewBuildTool creates the run_build tool (default: go build ./...).
+func NewBuildTool(timeoutSecs uint64) *DiagnosticsTool {
+	return &DiagnosticsTool{
+		name:            "run_build",
+		description:     "Build the project (default: go build ./...) and return compile errors as file:line diagnostics. Use after editing code to check it compiles.",
+		command:         []string{"go", "build", "./..."},
+		defaultSeverity: SeverityError,
+		timeoutSecs:     timeoutSecs,
+		maxDiagnostics:  defaultMaxDiagnostics,
+	}
+}
+
+// NewLintTool creates the run_lint tool (default: golangci-lint run ./...,
+// falling back to go vet ./... when golangci-lint is not installed).
+func NewLintTool(timeoutSecs uint64) *DiagnosticsTool {
+	return &DiagnosticsTool{
+		name:            "run_lint",
+		description:     "Lint the project (default: golangci-lint run, or go vet if unavailable) and return findings as file:line diagnostics.",
+		command:         []string{"golangci-lint", "run", "./..."},
+		fallback:        []string{"go", "vet", "./..."},
+		defaultSeverity: SeverityWarning,
+		timeoutSecs:     timeoutSecs,
+		maxDiagnostics:  defaultMaxDiagnostics,
+	}
+}
+
+// WithCommand replaces the command (e.g. "npx", "tsc", "--noEmit") and
+// drops any fallback.
+func (t *DiagnosticsTool) WithCommand(command ...string) *DiagnosticsTool {
+	t.command = command
+	t.fallback = nil
+	return t
+}
+
+// WithMaxDiagnostics sets how many diagnostics a summary lists.
+func (t *DiagnosticsTool) WithMaxDiagnostics(max int) *DiagnosticsTool {
+	t.maxDiagnostics = max
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *DiagnosticsTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        t.name,
+		Description: t.description,
+		Parameters: []ToolParameter{
+			{Name: "path", ParamType: "string", Description: "Directory to run in (default: current directory)", Required: false},
+			{Name: "targets", ParamType: "array", Description: "Packages or files to check, replacing the default ./... (e.g. ['./storage/...'])", Required: false, Items: map[string]interface{}{"type": "string"}},
+			{Name: "format", ParamType: "string", Description: "text (default) or json for diagnostic records", Required: false},
+		},
+	}
+}
+
+type diagnosticsArgs struct {
+	Path    string   `json:"path"`
+	Targets []string `json:"targets"`
+	Format  string   `json:"format"`
+}
+
+// Validate validates the tool arguments.
+func (t *DiagnosticsTool) Validate(args json.RawMessage) error {
+	var a diagnosticsArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Format != "" && a.Format != "text" && a.Format != "json" {
+		return fmt.Errorf("format must be text or json, got %q", a.Format)
+	}
+	for _, target := range a.Targets {
+		if strings.HasPrefix(target, "-") {
+			return fmt.Errorf("targets cannot be flags: %q", target)
+		}
+	}
+	return nil
+}
+
+// Execute runs the command and summarizes its diagnostics.
+func (t *DiagnosticsTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a diagnosticsArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+	if len(t.command) == 0 {
+		return FailureResultf("%s: no command configured", t.name), nil
+	}
+
+	command := t.command
+	if _, err := exec.LookPath(command[0]); err != nil && len(t.fallback) > 0 {
+		command = t.fallback
+	}
+	command = withTargets(command, a.Targets)
+
+	timeout := time.Duration(t.timeoutSecs) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = a.Path
+	output, err := cmd.CombinedOutput()
+	commandLine := strings.Join(command, " ")
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return FailureResultf("%s timed out after %d seconds", commandLine, t.timeoutSecs), nil
+	}
+	exitCode := 0
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return FailureResult(fmt.Errorf("failed to execute %s: %w", commandLine, err)), nil
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	diags := ParseDiagnostics(string(output), t.defaultSeverity)
+	if a.Format == "json" {
+		if diags == nil {
+			diags = []Diagnostic{}
+		}
+		out, err := json.MarshalIndent(map[string]interface{}{
+			"command":     commandLine,
+			"exit_code":   exitCode,
+			"diagnostics": diags,
+		}, "", "  ")
+		if err != nil {
+			return FailureResult(fmt.Errorf("failed to encode diagnostics: %w", err)), nil
+		}
+		if exitCode != 0 {
+			return ToolResult{Output: string(out), Error: fmt.Errorf("%s failed with exit code %d", commandLine, exitCode)}, nil
+		}
+		return SuccessResult(string(out)), nil
+	}
+
+	if exitCode == 0 && len(diags) == 0 {
+		return SuccessResult(fmt.Sprintf("%s: ok, no diagnostics", commandLine)), nil
+	}
+	if len(diags) == 0 {
+		// Failed without parseable diagnostics (e.g. missing module): show the tail
+		raw := strings.TrimSpace(string(output))
+		if len(raw) > maxRawOutput {
+			raw = "..." + raw[len(raw)-maxRawOutput:]
+		}
+		return FailureResultf("%s failed with exit code %d\noutput: %s", commandLine, exitCode, raw), nil
+	}
+
+	summary := fmt.Sprintf("%s: %s", commandLine, SummarizeDiagnostics(diags, t.maxDiagnostics))
+	if exitCode != 0 {
+		return FailureResultf("%s", summary), nil
+	}
+	return SuccessResult(summary), nil
+}
+
+// withTargets replaces the command's package pattern arguments with targets.
+func withTargets(command, targets []string) []string {
+	if len(targets) == 0 {
+		return command
+	}
+	var result []string
+	for _, arg := range command {
+		if arg != "./..." && arg != "." {
+			result = append(result, arg)
+		}
+	}
+	return append(result, targets...)
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	output := strings.Join([]string{
+		"# github.com/example/app/storage",
+		"storage/store.go:12:5: undefined: foo",
+		"./cli/run.go:40:2: declared and not used: x",
+		"tools/http.go:88:13: Error return value of `resp.Body.Close` is not checked (errcheck)",
+		"src/app.ts(3,7): error TS2322: Type 'string' is not assignable to type 'number'.",
+		"app/models.py:10: error: Incompatible return value type  [return-value]",
+		"main.c:4:10: warning: unused variable 'y' [-Wunused-variable]",
+		"storage/store.go:12:5: undefined: foo",
+		"1 issues:",
+	}, "\n")
+
+	diags := ParseDiagnostics(output, SeverityError)
+	if len(diags) != 6 {
+		t.Fatalf("expected 6 diagnostics, got %d: %+v", len(diags), diags)
+	}
+
+	want := []Diagnostic{
+		{File: "storage/store.go", Line: 12, Column: 5, Severity: SeverityError, Message: "undefined: foo"},
+		{File: "cli/run.go", Line: 40, Column: 2, Severity: SeverityError, Message: "declared and not used: x"},
+		{File: "tools/http.go", Line: 88, Column: 13, Severity: SeverityError, Message: "Error return value of `resp.Body.Close` is not checked", Rule: "errcheck"},
+		{File: "src/app.ts", Line: 3, Column: 7, Severity: SeverityError, Message: "Type 'string' is not assignable to type 'number'.", Rule: "TS2322"},
+		{File: "app/models.py", Line: 10, Severity: SeverityError, Message: "Incompatible return value type", Rule: "return-value"},
+		{File: "main.c", Line: 4, Column: 10, Severity: SeverityWarning, Message: "unused variable 'y'", Rule: "-Wunused-variable"},
+	}
+	for i, w := range want {
+		if diags[i] != w {
+			t.Errorf("diagnostic %d:\n got %+v\nwant %+v", i, diags[i], w)
+		}
+	}
+
+	summary := SummarizeDiagnostics(diags, 2)
+	if !strings.HasPrefix(summary, "5 errors, 1 warnings, 0 notes in 6 files") {
+		t.Errorf("unexpected summary header: %s", summary)
+	}
+	if !strings.Contains(summary, "... and 4 more") {
+		t.Errorf("expected truncation in summary: %s", summary)
+	}
+}
+
+func TestBuildToolReportsCompileErrors(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/broken\n\ngo 1.21\n")
+	write("main.go", "package main\n\nfunc main() {\n\tundefinedCall()\n}\n")
+
+	tool := NewBuildTool(120)
+	result, _ := tool.Execute(context.Background(), json.RawMessage(`{"path":"`+filepath.ToSlash(dir)+`"}`))
+	if result.Success() {
+		t.Fatalf("expected build failure, got %q", result.Output)
+	}
+	if msg := result.Error.Error(); !strings.Contains(msg, "main.go:4:2 error: undefined: undefinedCall") {
+		t.Errorf("unexpected diagnostics: %s", msg)
+	}
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	result, _ = tool.Execute(context.Background(), json.RawMessage(`{"path":"`+filepath.ToSlash(dir)+`","format":"json"}`))
+	if !result.Success() || !strings.Contains(result.Output, `"exit_code": 0`) {
+		t.Errorf("expected clean build, got %+v", result)
+	}
+}
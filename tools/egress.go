@@ -0,0 +1,195 @@
+// Network Egress Policy.
+//
+// An EgressPolicy limits where network tools may connect and how much they
+// may download: domain allow/deny lists, private address blocking and a
+// response size cap. Violations are EgressErrors, which name the rule that
+// was broken and match ErrToolDenied, so the model can tell a policy
+// refusal from a failing server.
+//
+// Information Hiding:
+// - Which address ranges count as private
+// - Dial-time address checks (so DNS can't point an allowed name inside)
+// - Policy file format
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Egress policy rules reported in EgressError.Rule.
+const (
+	EgressRuleScheme          = "scheme"
+	EgressRuleDomainDenied    = "domain_denied"
+	EgressRuleDomainNotListed = "domain_not_allowed"
+	EgressRulePrivateAddress  = "private_address"
+	EgressRuleResponseSize    = "max_response_bytes"
+)
+
+// EgressPolicy restricts network access by tools. The zero value allows
+// everything; a nil policy does too.
+type EgressPolicy struct {
+	// AllowDomains, if set, are the only hosts reachable (subdomains
+	// included).
+	AllowDomains []string `json:"allow_domains,omitempty"`
+	// DenyDomains are never reachable; deny wins over allow.
+	DenyDomains []string `json:"deny_domains,omitempty"`
+	// BlockPrivateIPs refuses loopback, private, link-local (including
+	// cloud metadata endpoints) and other non-public addresses.
+	BlockPrivateIPs bool `json:"block_private_ips,omitempty"`
+	// MaxResponseBytes caps response bodies (0 = unlimited).
+	MaxResponseBytes int64 `json:"max_response_bytes,omitempty"`
+}
+
+// EgressError is a policy violation.
+type EgressError struct {
+	Rule   string // One of the EgressRule constants
+	Target string // Host, address or URL that broke the rule
+	Detail string
+}
+
+func (e *EgressError) Error() string {
+	return fmt.Sprintf("egress policy violation [%s] %s: %s", e.Rule, e.Target, e.Detail)
+}
+
+// Is makes EgressError match ErrToolDenied.
+func (e *EgressError) Is(target error) bool { return target == ErrToolDenied }
+
+// LoadEgressPolicy loads a policy from a JSON file:
+//
+//	{"allow_domains": ["api.github.com"], "deny_domains": ["evil.example"],
+//	 "block_private_ips": true, "max_response_bytes": 10485760}
+func LoadEgressPolicy(path string) (*EgressPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read egress policy file: %w", err)
+	}
+
+	var policy EgressPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse egress policy file: %w", err)
+	}
+	if policy.MaxResponseBytes < 0 {
+		return nil, fmt.Errorf("egress policy: max_response_bytes must not be negative")
+	}
+	return &policy, nil
+}
+
+// CheckURL returns an EgressError if rawURL may not be requested. Host
+// names are checked against the domain lists; literal addresses and
+// "localhost" against BlockPrivateIPs. Resolved addresses are checked when
+// connecting (see DialControl).
+func (p *EgressPolicy) CheckURL(rawURL string) error {
+	if p == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL '%s': %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return &EgressError{Rule: EgressRuleScheme, Target: rawURL, Detail: "only http and https are allowed"}
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if hostMatches(host, p.DenyDomains) {
+		return &EgressError{Rule: EgressRuleDomainDenied, Target: host, Detail: "domain is on the deny list"}
+	}
+	if len(p.AllowDomains) > 0 && !hostMatches(host, p.AllowDomains) {
+		return &EgressError{Rule: EgressRuleDomainNotListed, Target: host, Detail: "domain is not on the allow list"}
+	}
+	if p.BlockPrivateIPs {
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return &EgressError{Rule: EgressRulePrivateAddress, Target: host, Detail: "loopback hosts are blocked"}
+		}
+		if addr, err := netip.ParseAddr(host); err == nil && isPrivateAddr(addr) {
+			return &EgressError{Rule: EgressRulePrivateAddress, Target: host, Detail: "private addresses are blocked"}
+		}
+	}
+	return nil
+}
+
+// CheckResponseSize returns an EgressError if a body of n bytes is over
+// MaxResponseBytes.
+func (p *EgressPolicy) CheckResponseSize(target string, n int64) error {
+	if p == nil || p.MaxResponseBytes == 0 || n <= p.MaxResponseBytes {
+		return nil
+	}
+	return &EgressError{
+		Rule:   EgressRuleResponseSize,
+		Target: target,
+		Detail: fmt.Sprintf("response is larger than the %d byte limit", p.MaxResponseBytes),
+	}
+}
+
+// DialControl is a net.Dialer Control function that refuses connections to
+// private addresses when BlockPrivateIPs is set. It runs after name
+// resolution, so a public name resolving to an internal address is caught.
+func (p *EgressPolicy) DialControl(network, address string, _ syscall.RawConn) error {
+	if p == nil || !p.BlockPrivateIPs {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil
+	}
+	if isPrivateAddr(addr) {
+		return &EgressError{Rule: EgressRulePrivateAddress, Target: addr.String(), Detail: "connections to private addresses are blocked"}
+	}
+	return nil
+}
+
+// Transport returns an HTTP transport that applies DialControl to every
+// connection. Proxies from the environment are not used, since a proxy
+// would make the connection for us and bypass the address check.
+func (p *EgressPolicy) Transport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   p.DialControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return transport
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// netip doesn't classify as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPrivateAddr reports whether addr is not a public unicast address.
+func isPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr)
+}
+
+// hostMatches reports whether host equals or is a subdomain of one of
+// domains.
+func hostMatches(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
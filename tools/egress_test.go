@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEgressPolicyCheckURL(t *testing.T) {
+	policy := &EgressPolicy{
+		AllowDomains:    []string{"example.com", "127.0.0.1"},
+		DenyDomains:     []string{"internal.example.com"},
+		BlockPrivateIPs: true,
+	}
+
+	tests := map[string]string{
+		"https://api.example.com/v1":      "",
+		"https://internal.example.com/":   EgressRuleDomainDenied,
+		"https://x.internal.example.com/": EgressRuleDomainDenied,
+		"https://example.org/":            EgressRuleDomainNotListed,
+		"file:///etc/passwd":              EgressRuleScheme,
+		"http://127.0.0.1:8080/":          EgressRulePrivateAddress,
+	}
+	for rawURL, rule := range tests {
+		err := policy.CheckURL(rawURL)
+		var egressErr *EgressError
+		switch {
+		case rule == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", rawURL, err)
+		case rule != "" && (!errors.As(err, &egressErr) || egressErr.Rule != rule):
+			t.Errorf("%s: expected rule %s, got %v", rawURL, rule, err)
+		}
+	}
+
+	open := &EgressPolicy{BlockPrivateIPs: true}
+	for _, rawURL := range []string{"http://localhost/", "http://[::1]/", "http://169.254.169.254/latest/meta-data", "http://100.64.1.1/"} {
+		if err := open.CheckURL(rawURL); !errors.Is(err, ErrToolDenied) {
+			t.Errorf("%s should be blocked as private, got %v", rawURL, err)
+		}
+	}
+
+	var none *EgressPolicy
+	if err := none.CheckURL("http://127.0.0.1/"); err != nil {
+		t.Errorf("a nil policy should allow everything, got %v", err)
+	}
+}
+
+func TestEgressPolicyDialControl(t *testing.T) {
+	policy := &EgressPolicy{BlockPrivateIPs: true}
+	if err := policy.DialControl("tcp", "10.1.2.3:443", nil); err == nil {
+		t.Error("dialing a private address should be refused")
+	}
+	if err := policy.DialControl("tcp", "93.184.215.14:443", nil); err != nil {
+		t.Errorf("dialing a public address should be allowed, got %v", err)
+	}
+}
+
+func TestHTTPToolEgressPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://blocked.example/", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback
+	result := runHTTP(t, NewHTTPTool(5).WithEgressPolicy(&EgressPolicy{BlockPrivateIPs: true}), httpArgs{URL: server.URL})
+	var egressErr *EgressError
+	if !errors.As(result.Error, &egressErr) || egressErr.Rule != EgressRulePrivateAddress {
+		t.Errorf("expected a private address violation, got %+v", result)
+	}
+
+	tool := NewHTTPTool(5).WithEgressPolicy(&EgressPolicy{DenyDomains: []string{"blocked.example"}, MaxResponseBytes: 50})
+	result = runHTTP(t, tool, httpArgs{URL: server.URL})
+	if !errors.As(result.Error, &egressErr) || egressErr.Rule != EgressRuleResponseSize {
+		t.Errorf("expected a response size violation, got %+v", result)
+	}
+	result = runHTTP(t, tool, httpArgs{URL: server.URL + "/redirect"})
+	if !errors.As(result.Error, &egressErr) || egressErr.Rule != EgressRuleDomainDenied {
+		t.Errorf("expected the redirect to be denied, got %+v", result)
+	}
+}
+
+func TestLoadEgressPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "egress.json")
+	if err := os.WriteFile(path, []byte(`{"deny_domains": ["evil.example"], "block_private_ips": true, "max_response_bytes": 1024}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadEgressPolicy(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !policy.BlockPrivateIPs || policy.MaxResponseBytes != 1024 || len(policy.DenyDomains) != 1 {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+}
@@ -10,6 +10,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -30,12 +31,28 @@ func NewDefaultExecutor() *Executor {
 	return &Executor{config: DefaultToolConfig()}
 }
 
-// Execute runs a tool with retry logic.
+// Execute runs a tool with retry logic. Mutating calls are recorded in
+// the audit log, if one is configured, file changes are passed to
+// FileChanges, and PII in results is masked before they are returned.
 func (e *Executor) Execute(ctx context.Context, tool Tool, args json.RawMessage) (ToolResult, error) {
+	result, err := e.execute(ctx, tool, args)
+	e.config.Audit.Record(ctx, tool.Metadata().Name, args, result, err)
+	return result, err
+}
+
+// execute applies the run's policies and runs the tool with retries.
+func (e *Executor) execute(ctx context.Context, tool Tool, args json.RawMessage) (ToolResult, error) {
 	var lastErr error
 	toolName := tool.Metadata().Name
 	maxRetries := e.config.Retries()
 
+	if !e.config.Filter.Allows(toolName) {
+		return DeniedResultf("%s is disabled for this run", toolName), nil
+	}
+	if err := e.config.Injection.CheckCall(toolName, args); err != nil {
+		return DeniedResultf("%v", err), nil
+	}
+
 	for attempt := uint32(0); attempt < maxRetries; attempt++ {
 		if attempt > 0 {
 			// Exponential backoff
@@ -47,19 +64,20 @@ func (e *Executor) Execute(ctx context.Context, tool Tool, args json.RawMessage)
 			}
 		}
 
-		result, err := tool.Execute(ctx, args)
+		result, err := tool.Execute(withFileChanges(ctx, e.config.FileChanges), args)
 		if err != nil {
 			lastErr = err
 			continue
 		}
 
 		if result.Success() {
-			return result, nil
+			// Mask before screening, which may send the output to a model
+			return e.ingest(ctx, toolName, e.config.PII.MaskResult(result)), nil
 		}
 
 		// Check if we should retry this failure
 		if !e.shouldRetry(result) {
-			return result, nil
+			return e.config.PII.MaskResult(result), nil
 		}
 
 		lastErr = result.Error
@@ -70,7 +88,19 @@ func (e *Executor) Execute(ctx context.Context, tool Tool, args json.RawMessage)
 	if lastErr != nil {
 		errMsg = lastErr.Error()
 	}
-	return FailureResultf("tool '%s' failed after %d attempts: %s", toolName, maxRetries, errMsg), nil
+	return e.config.PII.MaskResult(FailureResultf("tool '%s' failed after %d attempts: %s", toolName, maxRetries, errMsg)), nil
+}
+
+// ingest screens and fences the output of tools that return external
+// content (see InjectionGuard and FenceContent).
+func (e *Executor) ingest(ctx context.Context, toolName string, result ToolResult) ToolResult {
+	source, ok := IngestedSource(toolName)
+	if !ok || result.Output == "" {
+		return result
+	}
+	output := e.config.Injection.Screen(ctx, toolName, result.Output)
+	result.Output = FenceContent(source, toolName, output)
+	return result
 }
 
 // calculateBackoff returns the backoff duration for the given attempt.
@@ -92,6 +122,9 @@ func (e *Executor) shouldRetry(result ToolResult) bool {
 	if result.Error == nil {
 		return true
 	}
+	if errors.Is(result.Error, ErrToolDenied) {
+		return false
+	}
 
 	errLower := strings.ToLower(result.Error.Error())
 
@@ -0,0 +1,203 @@
+// External Tools - tools implemented as executables.
+//
+// Teams can ship proprietary tools without forking the repo or running an
+// MCP server: any executable in a tools directory becomes a tool. Loading
+// runs each executable, so callers only load a directory the user enabled.
+// The executable speaks JSON over stdio, one process per request:
+//
+//	<exe> metadata          stdout: {"name", "description", "parameters", "validate"}
+//	<exe> validate < args   exit 0 if valid; otherwise stdout {"error"} or stderr
+//	<exe> execute  < args   stdout: {"output", "error"}
+//
+// Parameters use the ToolParameter JSON shape ("name", "param_type",
+// "description", "required"). validate is only invoked when metadata sets
+// "validate": true. A non-zero exit from execute is a tool failure with
+// stderr as the error.
+//
+// Information Hiding:
+// - Process invocation, timeouts and protocol decoding hidden
+// - Executable discovery hidden
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultExternalToolsDir is the conventional directory for external tool
+// executables.
+const DefaultExternalToolsDir = ".ariadne/tools.d"
+
+// externalMetadata is the response to the metadata command.
+type externalMetadata struct {
+	ToolMetadata
+	Validate bool `json:"validate"`
+}
+
+// externalResponse is the response to the validate and execute commands.
+type externalResponse struct {
+	Output string `json:"output"`
+	Error  string `json:"error"`
+}
+
+// ExternalTool runs an executable that implements the tool protocol.
+type ExternalTool struct {
+	path        string
+	metadata    ToolMetadata
+	validate    bool
+	timeoutSecs uint64
+}
+
+// LoadExternalTools loads every executable file in dir. A missing dir
+// yields no tools. Executables that fail to describe themselves are
+// reported together in the returned error; the rest are still returned.
+func LoadExternalTools(ctx context.Context, dir string, timeoutSecs uint64) ([]*ExternalTool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read external tools directory: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var loaded []*ExternalTool
+	var errs []string
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		tool, err := LoadExternalTool(ctx, path, timeoutSecs)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if other, ok := seen[tool.metadata.Name]; ok {
+			errs = append(errs, fmt.Sprintf("%s: tool %q already defined by %s", path, tool.metadata.Name, other))
+			continue
+		}
+		seen[tool.metadata.Name] = path
+		loaded = append(loaded, tool)
+	}
+	if len(errs) > 0 {
+		return loaded, fmt.Errorf("failed to load external tools:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return loaded, nil
+}
+
+// LoadExternalTool asks an executable for its metadata.
+func LoadExternalTool(ctx context.Context, path string, timeoutSecs uint64) (*ExternalTool, error) {
+	tool := &ExternalTool{path: path, timeoutSecs: timeoutSecs}
+	stdout, err := tool.run(ctx, "metadata", nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var meta externalMetadata
+	if err := json.Unmarshal(stdout, &meta); err != nil {
+		return nil, fmt.Errorf("%s: invalid metadata: %w", path, err)
+	}
+	if meta.Name == "" {
+		return nil, fmt.Errorf("%s: metadata has no name", path)
+	}
+	for _, p := range meta.Parameters {
+		if p.Name == "" {
+			return nil, fmt.Errorf("%s: parameter with no name", path)
+		}
+	}
+	tool.metadata = meta.ToolMetadata
+	tool.validate = meta.Validate
+	return tool, nil
+}
+
+// Path returns the executable backing the tool.
+func (t *ExternalTool) Path() string {
+	return t.path
+}
+
+// Metadata returns the metadata reported by the executable.
+func (t *ExternalTool) Metadata() ToolMetadata {
+	return t.metadata
+}
+
+// Validate checks required parameters, then asks the executable if it
+// opted in to validation.
+func (t *ExternalTool) Validate(args json.RawMessage) error {
+	var a map[string]json.RawMessage
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	for _, p := range t.metadata.Parameters {
+		if _, ok := a[p.Name]; p.Required && !ok {
+			return fmt.Errorf("missing required parameter: %s", p.Name)
+		}
+	}
+	if !t.validate {
+		return nil
+	}
+	stdout, err := t.run(context.Background(), "validate", args)
+	if err != nil {
+		return err
+	}
+	var resp externalResponse
+	if json.Unmarshal(stdout, &resp) == nil && resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// Execute runs the executable with the arguments on stdin.
+func (t *ExternalTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	stdout, err := t.run(ctx, "execute", args)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	var resp externalResponse
+	if err := json.Unmarshal(stdout, &resp); err != nil {
+		return FailureResult(fmt.Errorf("%s returned invalid response: %w", t.metadata.Name, err)), nil
+	}
+	if resp.Error != "" {
+		return ToolResult{Output: resp.Output, Error: fmt.Errorf("%s", resp.Error)}, nil
+	}
+	return SuccessResult(resp.Output), nil
+}
+
+// run invokes one protocol command. A non-zero exit is an error carrying
+// the response's error field, or stderr if there is none.
+func (t *ExternalTool) run(ctx context.Context, command string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.timeoutSecs)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, t.path, command)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s %s timed out after %d seconds", filepath.Base(t.path), command, t.timeoutSecs)
+	}
+	if err != nil {
+		var resp externalResponse
+		if json.Unmarshal(stdout.Bytes(), &resp) == nil && resp.Error != "" {
+			return nil, fmt.Errorf("%s", resp.Error)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s %s failed: %s", filepath.Base(t.path), command, msg)
+		}
+		return nil, fmt.Errorf("%s %s failed: %w", filepath.Base(t.path), command, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const externalGreet = `#!/bin/sh
+case "$1" in
+metadata)
+  echo '{"name":"greet","description":"Greet someone","parameters":[{"name":"who","param_type":"string","description":"Name","required":true}],"validate":true}'
+  ;;
+validate)
+  if grep -q '"who":""' ; then echo '{"error":"who cannot be empty"}'; exit 1; fi
+  ;;
+execute)
+  input=$(cat)
+  case "$input" in
+  *nobody*) echo '{"error":"nobody to greet"}' ;;
+  *) echo "{\"output\":\"hello from $(basename "$0")\"}" ;;
+  esac
+  ;;
+esac
+`
+
+func TestExternalTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not supported")
+	}
+	dir := t.TempDir()
+	write := func(name, content string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("greet", externalGreet, 0755)
+	write("README.md", "not executable", 0644)
+	write("broken", "#!/bin/sh\necho not json\n", 0755)
+
+	loaded, err := LoadExternalTools(context.Background(), dir, 10)
+	if err == nil || !strings.Contains(err.Error(), "broken: invalid metadata") {
+		t.Errorf("expected metadata error for broken tool, got %v", err)
+	}
+	if len(loaded) != 1 {
+		t.Fatalf("expected 1 tool, got %d", len(loaded))
+	}
+	tool := loaded[0]
+	if meta := tool.Metadata(); meta.Name != "greet" || len(meta.Parameters) != 1 || !meta.Parameters[0].Required {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+
+	if err := tool.Validate(json.RawMessage(`{}`)); err == nil || !strings.Contains(err.Error(), "missing required parameter: who") {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+	if err := tool.Validate(json.RawMessage(`{"who":""}`)); err == nil || err.Error() != "who cannot be empty" {
+		t.Errorf("expected validation error from executable, got %v", err)
+	}
+	if err := tool.Validate(json.RawMessage(`{"who":"ada"}`)); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+
+	result, _ := tool.Execute(context.Background(), json.RawMessage(`{"who":"ada"}`))
+	if !result.Success() || result.Output != "hello from greet" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	result, _ = tool.Execute(context.Background(), json.RawMessage(`{"who":"nobody"}`))
+	if result.Success() || result.Error.Error() != "nobody to greet" {
+		t.Errorf("expected tool error, got %+v", result)
+	}
+
+	if tools, err := LoadExternalTools(context.Background(), filepath.Join(dir, "missing"), 10); err != nil || len(tools) != 0 {
+		t.Errorf("missing dir should yield no tools, got %v, %v", tools, err)
+	}
+}
@@ -0,0 +1,70 @@
+// File Change Reporting.
+//
+// write_file, append_file, edit_file and format_code report each change
+// they make to a file: its content before and after. An Executor whose
+// ToolConfig sets FileChanges passes the changes to it, so a CLI can show
+// the user a diff of every agent modification as it happens. Files are
+// only read for their previous content when changes are being reported.
+//
+// Information Hiding:
+// - Observer carried in the context from Executor to tools
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// FileChange is one change a tool made to a file.
+type FileChange struct {
+	Tool    string
+	Path    string
+	Before  string
+	After   string
+	Created bool // The file didn't exist before
+}
+
+// Diff renders the change as a unified diff with 3 lines of context.
+func (c FileChange) Diff() string {
+	oldName := c.Path
+	if c.Created {
+		oldName = "/dev/null"
+	}
+	return unifiedDiff(oldName, c.Path, c.Before, c.After)
+}
+
+// fileChangesKey is the context key holding the FileChanges observer.
+type fileChangesKey struct{}
+
+// withFileChanges returns a context whose file tools report to fn.
+func withFileChanges(ctx context.Context, fn func(FileChange)) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, fileChangesKey{}, fn)
+}
+
+// fileBefore reads path's content ahead of a change and reports whether
+// the file exists. Nothing is read unless changes are being reported.
+func fileBefore(ctx context.Context, path string) (string, bool) {
+	if ctx.Value(fileChangesKey{}) == nil {
+		return "", true
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false
+	}
+	return string(data), true
+}
+
+// reportFileChange passes change to the context's observer, if any,
+// unless the content is unchanged.
+func reportFileChange(ctx context.Context, change FileChange) {
+	fn, ok := ctx.Value(fileChangesKey{}).(func(FileChange))
+	if ok && (change.Before != change.After || change.Created) {
+		fn(change)
+	}
+}
@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecutorReportsFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	var changes []FileChange
+	executor := NewExecutor(ToolConfig{FileChanges: func(c FileChange) { changes = append(changes, c) }})
+	ctx := context.Background()
+
+	run := func(tool Tool, args any) {
+		t.Helper()
+		raw, _ := json.Marshal(args)
+		if result, err := executor.Execute(ctx, tool, raw); err != nil || !result.Success() {
+			t.Fatalf("%s failed: %+v, err %v", tool.Metadata().Name, result, err)
+		}
+	}
+	run(NewWriteFileTool(1024), writeFileArgs{Path: path, Content: "one\ntwo\n"})
+	run(NewEditFileTool(1024), editFileArgs{Path: path, Search: "two", Replace: "TWO"})
+	run(NewAppendFileTool(1024), appendFileArgs{Path: path, Content: "three\n"})
+	// Rewriting the same content is not a change
+	run(NewWriteFileTool(1024), writeFileArgs{Path: path, Content: "one\nTWO\nthree\n"})
+
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+	if c := changes[0]; !c.Created || c.Tool != "write_file" || !strings.HasPrefix(c.Diff(), "--- /dev/null\n+++ "+path+"\n") {
+		t.Errorf("unexpected create: %+v\n%s", c, c.Diff())
+	}
+	if d := changes[1].Diff(); !strings.Contains(d, "-two\n+TWO\n") {
+		t.Errorf("unexpected edit diff:\n%s", d)
+	}
+	if c := changes[2]; c.Created || c.After != "one\nTWO\nthree\n" || !strings.HasSuffix(c.Diff(), "+three\n") {
+		t.Errorf("unexpected append: %+v\n%s", c, c.Diff())
+	}
+}
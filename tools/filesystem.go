@@ -1,4 +1,4 @@
-// Filesystem Tools - Read, Write, Edit, Append operations.
+// Filesystem Tools - Read, Write, Edit, Append, Stat operations.
 //
 // Information Hiding:
 // - File I/O implementation details hidden
@@ -8,11 +8,19 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/richinex/ariadne/model"
 )
@@ -25,6 +33,7 @@ type ReadFileTool struct {
 	maxSizeBytes int64
 	contentStore model.ContentStore
 	fileContext  *StoredFileContext
+	artifacts    *ArtifactDir // When set, files the run wrote there are read instead
 }
 
 // NewReadFileTool creates a new read file tool.
@@ -52,6 +61,13 @@ func (t *ReadFileTool) WithFileContext(ctx *StoredFileContext) *ReadFileTool {
 	return t
 }
 
+// WithArtifactDir reads the run's artifact copy of a file when it has one,
+// so the agent sees what it wrote. A nil dir reads the requested paths.
+func (t *ReadFileTool) WithArtifactDir(dir *ArtifactDir) *ReadFileTool {
+	t.artifacts = dir
+	return t
+}
+
 // Metadata returns the tool metadata.
 func (t *ReadFileTool) Metadata() ToolMetadata {
 	return ToolMetadata{
@@ -93,11 +109,15 @@ func (t *ReadFileTool) Execute(ctx context.Context, args json.RawMessage) (ToolR
 	}
 
 	if !pathAllowed(a.Path, t.allowedPaths) {
-		return FailureResultf("access to path '%s' is not allowed", a.Path), nil
+		return DeniedResultf("access to path '%s' is not allowed", a.Path), nil
+	}
+	source := a.Path
+	if t.artifacts != nil {
+		source = t.artifacts.Locate(a.Path)
 	}
 
 	// Check file exists
-	info, err := os.Stat(a.Path)
+	info, err := os.Stat(source)
 	if os.IsNotExist(err) {
 		return FailureResultf("file does not exist: %s", a.Path), nil
 	}
@@ -111,7 +131,7 @@ func (t *ReadFileTool) Execute(ctx context.Context, args json.RawMessage) (ToolR
 	}
 
 	// Read file
-	content, err := os.ReadFile(a.Path)
+	content, err := os.ReadFile(source)
 	if err != nil {
 		return FailureResult(fmt.Errorf("failed to read file: %w", err)), nil
 	}
@@ -132,9 +152,14 @@ func (t *ReadFileTool) Execute(ctx context.Context, args json.RawMessage) (ToolR
 
 		// Return ONLY metadata - no content
 		// Agent can use get_lines without specifying key (uses last stored)
+		// Source files include their outline so the agent can jump to line ranges
+		outline := ""
+		if stored.Outline {
+			outline = "\n" + stored.Preview
+		}
 		return SuccessResult(fmt.Sprintf(
-			"[File stored: %d bytes, %d lines]\nUse get_lines to retrieve content (key is automatic).",
-			stored.Bytes, stored.Lines,
+			"[File stored: %d bytes, %d lines]%s\nUse get_lines to retrieve content (key is automatic).",
+			stored.Bytes, stored.Lines, outline,
 		)), nil
 	}
 
@@ -146,6 +171,8 @@ type WriteFileTool struct {
 	BaseTool
 	allowedPaths []string
 	maxSizeBytes int64
+	artifacts    *ArtifactDir // When set, writes are redirected into the run's artifact directory
+	edits        *EditTracker
 }
 
 // NewWriteFileTool creates a new write file tool.
@@ -161,6 +188,19 @@ func (t *WriteFileTool) WithAllowedPaths(paths []string) *WriteFileTool {
 	return t
 }
 
+// WithArtifactDir redirects writes into a run's artifact directory.
+// A nil dir writes to the requested paths as usual.
+func (t *WriteFileTool) WithArtifactDir(dir *ArtifactDir) *WriteFileTool {
+	t.artifacts = dir
+	return t
+}
+
+// WithEditTracker records written files for format_code.
+func (t *WriteFileTool) WithEditTracker(edits *EditTracker) *WriteFileTool {
+	t.edits = edits
+	return t
+}
+
 // Metadata returns the tool metadata.
 func (t *WriteFileTool) Metadata() ToolMetadata {
 	return ToolMetadata{
@@ -205,22 +245,28 @@ func (t *WriteFileTool) Execute(ctx context.Context, args json.RawMessage) (Tool
 		return FailureResultf("content too large: %d bytes (max: %d bytes)", len(a.Content), t.maxSizeBytes), nil
 	}
 
-	if !pathAllowedForWrite(a.Path, t.allowedPaths) {
-		return FailureResultf("access to path '%s' is not allowed", a.Path), nil
+	target := a.Path
+	if t.artifacts != nil {
+		target = t.artifacts.Resolve(a.Path)
+	} else if !pathAllowedForWrite(a.Path, t.allowedPaths) {
+		return DeniedResultf("access to path '%s' is not allowed", a.Path), nil
 	}
 
 	// Create parent directory if needed
-	dir := parentDir(a.Path)
+	dir := parentDir(target)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return FailureResult(fmt.Errorf("failed to create directory: %w", err)), nil
 	}
 
 	// Write file
-	if err := os.WriteFile(a.Path, []byte(a.Content), 0644); err != nil {
+	before, existed := fileBefore(ctx, target)
+	if err := os.WriteFile(target, []byte(a.Content), 0644); err != nil {
 		return FailureResult(fmt.Errorf("failed to write file: %w", err)), nil
 	}
+	t.edits.Add(target)
+	reportFileChange(ctx, FileChange{Tool: "write_file", Path: target, Before: before, After: a.Content, Created: !existed})
 
-	return SuccessResult(fmt.Sprintf("Successfully wrote %d bytes to %s", len(a.Content), a.Path)), nil
+	return SuccessResult(fmt.Sprintf("Successfully wrote %d bytes to %s", len(a.Content), target)), nil
 }
 
 // parentDir returns the parent directory of a path.
@@ -242,6 +288,8 @@ type AppendFileTool struct {
 	BaseTool
 	allowedPaths []string
 	maxSizeBytes int64
+	artifacts    *ArtifactDir // When set, appends change a copy in the run's artifact directory
+	edits        *EditTracker
 }
 
 // NewAppendFileTool creates a new append file tool.
@@ -257,6 +305,20 @@ func (t *AppendFileTool) WithAllowedPaths(paths []string) *AppendFileTool {
 	return t
 }
 
+// WithArtifactDir redirects appends into a run's artifact directory,
+// appending to a copy of the original file if there is one. A nil dir
+// appends to the requested paths as usual.
+func (t *AppendFileTool) WithArtifactDir(dir *ArtifactDir) *AppendFileTool {
+	t.artifacts = dir
+	return t
+}
+
+// WithEditTracker records appended files for format_code.
+func (t *AppendFileTool) WithEditTracker(edits *EditTracker) *AppendFileTool {
+	t.edits = edits
+	return t
+}
+
 // Metadata returns the tool metadata.
 func (t *AppendFileTool) Metadata() ToolMetadata {
 	return ToolMetadata{
@@ -301,18 +363,26 @@ func (t *AppendFileTool) Execute(ctx context.Context, args json.RawMessage) (Too
 		return FailureResultf("content too large: %d bytes (max: %d bytes)", len(a.Content), t.maxSizeBytes), nil
 	}
 
-	if !pathAllowedForWrite(a.Path, t.allowedPaths) {
-		return FailureResultf("access to path '%s' is not allowed", a.Path), nil
+	target := a.Path
+	if t.artifacts != nil {
+		// Append to a copy of the original, not to an empty artifact
+		var err error
+		if target, err = t.artifacts.CopyOnWrite(a.Path); err != nil {
+			return FailureResult(err), nil
+		}
+	} else if !pathAllowedForWrite(a.Path, t.allowedPaths) {
+		return DeniedResultf("access to path '%s' is not allowed", a.Path), nil
 	}
 
 	// Create parent directory if needed
-	dir := parentDir(a.Path)
+	dir := parentDir(target)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return FailureResult(fmt.Errorf("failed to create directory: %w", err)), nil
 	}
 
 	// Open file for appending (create if not exists)
-	f, err := os.OpenFile(a.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	before, existed := fileBefore(ctx, target)
+	f, err := os.OpenFile(target, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return FailureResult(fmt.Errorf("failed to open file: %w", err)), nil
 	}
@@ -321,8 +391,10 @@ func (t *AppendFileTool) Execute(ctx context.Context, args json.RawMessage) (Too
 	if _, err := f.WriteString(a.Content); err != nil {
 		return FailureResult(fmt.Errorf("failed to write to file: %w", err)), nil
 	}
+	t.edits.Add(target)
+	reportFileChange(ctx, FileChange{Tool: "append_file", Path: target, Before: before, After: before + a.Content, Created: !existed})
 
-	return SuccessResult(fmt.Sprintf("Successfully appended %d bytes to %s", len(a.Content), a.Path)), nil
+	return SuccessResult(fmt.Sprintf("Successfully appended %d bytes to %s", len(a.Content), target)), nil
 }
 
 // EditFileTool performs search/replace operations on files.
@@ -330,6 +402,8 @@ type EditFileTool struct {
 	BaseTool
 	allowedPaths []string
 	maxSizeBytes int64
+	artifacts    *ArtifactDir // When set, edits change a copy in the run's artifact directory
+	edits        *EditTracker
 }
 
 // NewEditFileTool creates a new edit file tool.
@@ -345,6 +419,19 @@ func (t *EditFileTool) WithAllowedPaths(paths []string) *EditFileTool {
 	return t
 }
 
+// WithArtifactDir edits copies of files in a run's artifact directory,
+// leaving the originals untouched. A nil dir edits files in place.
+func (t *EditFileTool) WithArtifactDir(dir *ArtifactDir) *EditFileTool {
+	t.artifacts = dir
+	return t
+}
+
+// WithEditTracker records edited files for format_code.
+func (t *EditFileTool) WithEditTracker(edits *EditTracker) *EditFileTool {
+	t.edits = edits
+	return t
+}
+
 // Metadata returns the tool metadata.
 func (t *EditFileTool) Metadata() ToolMetadata {
 	return ToolMetadata{
@@ -395,17 +482,23 @@ func (t *EditFileTool) Execute(ctx context.Context, args json.RawMessage) (ToolR
 		return FailureResultf("search string cannot be empty"), nil
 	}
 
-	if !pathAllowedForWrite(a.Path, t.allowedPaths) {
-		return FailureResultf("access to path '%s' is not allowed", a.Path), nil
+	target := a.Path
+	if t.artifacts != nil {
+		var err error
+		if target, err = t.artifacts.CopyOnWrite(a.Path); err != nil {
+			return FailureResult(err), nil
+		}
+	} else if !pathAllowedForWrite(a.Path, t.allowedPaths) {
+		return DeniedResultf("access to path '%s' is not allowed", a.Path), nil
 	}
 
 	// Check file exists
-	if _, err := os.Stat(a.Path); os.IsNotExist(err) {
+	if _, err := os.Stat(target); os.IsNotExist(err) {
 		return FailureResultf("file does not exist: %s", a.Path), nil
 	}
 
 	// Read file
-	content, err := os.ReadFile(a.Path)
+	content, err := os.ReadFile(target)
 	if err != nil {
 		return FailureResult(fmt.Errorf("failed to read file: %w", err)), nil
 	}
@@ -439,14 +532,140 @@ func (t *EditFileTool) Execute(ctx context.Context, args json.RawMessage) (ToolR
 	}
 
 	// Write file
-	if err := os.WriteFile(a.Path, []byte(updated), 0644); err != nil {
+	if err := os.WriteFile(target, []byte(updated), 0644); err != nil {
 		return FailureResult(fmt.Errorf("failed to write file: %w", err)), nil
 	}
+	t.edits.Add(target)
+	reportFileChange(ctx, FileChange{Tool: "edit_file", Path: target, Before: contentStr, After: updated})
 
 	replacedCount := 1
 	if replaceAll {
 		replacedCount = occurrences
 	}
 
-	return SuccessResult(fmt.Sprintf("Replaced %d occurrence(s) in %s", replacedCount, a.Path)), nil
+	return SuccessResult(fmt.Sprintf("Replaced %d occurrence(s) in %s", replacedCount, target)), nil
+}
+
+// StatFileTool reports file metadata and a content hash without storing content.
+// Lets agents decide whether a file is worth reading and detect changes between steps.
+type StatFileTool struct {
+	BaseTool
+	allowedPaths []string
+}
+
+// NewStatFileTool creates a new stat file tool.
+func NewStatFileTool() *StatFileTool {
+	return &StatFileTool{}
+}
+
+// WithAllowedPaths sets the allowed path prefixes.
+func (t *StatFileTool) WithAllowedPaths(paths []string) *StatFileTool {
+	t.allowedPaths = paths
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *StatFileTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "stat_file",
+		Description: "Get file metadata without reading it into context: size, modification time, permissions, mime type, line count, and SHA-256 hash. Use to decide whether a file is worth reading, or compare hashes to detect changes.",
+		Parameters: []ToolParameter{
+			{Name: "path", ParamType: "string", Description: "Path to the file", Required: true},
+		},
+	}
+}
+
+type statFileArgs struct {
+	Path string `json:"path"`
+}
+
+// Validate validates the arguments.
+func (t *StatFileTool) Validate(args json.RawMessage) error {
+	var a statFileArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return nil
+}
+
+// Execute stats the file and hashes its content in a single streaming pass.
+func (t *StatFileTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a statFileArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+
+	if a.Path == "" {
+		return FailureResultf("path cannot be empty"), nil
+	}
+
+	if !pathAllowed(a.Path, t.allowedPaths) {
+		return DeniedResultf("access to path '%s' is not allowed", a.Path), nil
+	}
+
+	info, err := os.Stat(a.Path)
+	if os.IsNotExist(err) {
+		return FailureResultf("file does not exist: %s", a.Path), nil
+	}
+	if err != nil {
+		return FailureResult(fmt.Errorf("failed to read file metadata: %w", err)), nil
+	}
+	if info.IsDir() {
+		return FailureResultf("path is a directory: %s", a.Path), nil
+	}
+
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return FailureResult(fmt.Errorf("failed to open file: %w", err)), nil
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	var head []byte
+	lines := 0
+	lastByte := byte('\n')
+	buf := make([]byte, 64*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return FailureResult(err), nil
+		}
+		n, err := f.Read(buf)
+		if n > 0 {
+			chunk := buf[:n]
+			hasher.Write(chunk)
+			lines += bytes.Count(chunk, []byte{'\n'})
+			lastByte = chunk[n-1]
+			if len(head) < 512 {
+				head = append(head, chunk[:min(n, 512-len(head))]...)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return FailureResult(fmt.Errorf("failed to read file: %w", err)), nil
+		}
+	}
+	// Count a final line without a trailing newline
+	if lastByte != '\n' {
+		lines++
+	}
+
+	return SuccessResult(fmt.Sprintf(
+		"path: %s\nsize: %d bytes\nmodified: %s\npermissions: %s\nmime_type: %s\nlines: %d\nsha256: %s",
+		a.Path, info.Size(), info.ModTime().Format(time.RFC3339), info.Mode().Perm(),
+		detectMimeType(a.Path, head), lines, hex.EncodeToString(hasher.Sum(nil)),
+	)), nil
+}
+
+// detectMimeType prefers the extension's registered type and falls back to
+// sniffing the first bytes of content.
+func detectMimeType(path string, head []byte) string {
+	if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+		return byExt
+	}
+	return http.DetectContentType(head)
 }
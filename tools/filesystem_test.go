@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatFileTool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewStatFileTool()
+	args, _ := json.Marshal(statFileArgs{Path: path})
+	result, err := tool.Execute(context.Background(), args)
+	if err != nil || !result.Success() {
+		t.Fatalf("stat failed: %+v, err %v", result, err)
+	}
+
+	for _, want := range []string{
+		"size: 13 bytes",
+		"permissions: -rw-r-----",
+		"mime_type: text/plain",
+		"lines: 3",
+		"sha256: 058053d87c818d699cde0f00d670bca0e1c6ad857caa9758ea6a556d7c64fcee",
+	} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected %q in output:\n%s", want, result.Output)
+		}
+	}
+
+	// Hash changes when content changes
+	if err := os.WriteFile(path, []byte("one\ntwo\nfour"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	changed, _ := tool.Execute(context.Background(), args)
+	if hashLine(changed.Output) == hashLine(result.Output) {
+		t.Error("expected hash to change with content")
+	}
+}
+
+func TestStatFileToolErrors(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewStatFileTool().WithAllowedPaths([]string{dir})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+		denied  bool
+	}{
+		{"missing", filepath.Join(dir, "missing.txt"), "does not exist", false},
+		{"directory", dir, "is a directory", false},
+		{"outside allowed paths", filepath.Join(os.TempDir(), "other.txt"), "not allowed", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, _ := json.Marshal(statFileArgs{Path: tt.path})
+			result, _ := tool.Execute(context.Background(), args)
+			if result.Success() || !strings.Contains(result.Error.Error(), tt.wantErr) {
+				t.Errorf("expected failure containing %q, got %+v", tt.wantErr, result)
+			}
+			if errors.Is(result.Error, ErrToolDenied) != tt.denied {
+				t.Errorf("errors.Is(ErrToolDenied) = %v, want %v", !tt.denied, tt.denied)
+			}
+		})
+	}
+}
+
+func hashLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "sha256: ") {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+// Tool Allowlist and Denylist.
+//
+// A run can restrict the tools offered to its agents and sub-agents, so a
+// security-sensitive run can exclude shell or network access without code
+// changes. Filtered tools are never offered, and the executor denies calls
+// to them in case a model names one anyway.
+//
+// Information Hiding:
+// - Precedence of allow and deny lists
+// - Name validation against the known tools
+
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ToolFilter decides which tools a run may use. A nil filter allows every
+// tool.
+type ToolFilter struct {
+	allow map[string]bool // Empty = every tool not denied
+	deny  map[string]bool
+}
+
+// NewToolFilter creates a filter from tool names. An empty allow list
+// allows every tool; deny wins over allow. It returns nil when both lists
+// are empty.
+func NewToolFilter(allow, deny []string) *ToolFilter {
+	allowSet, denySet := nameSet(allow), nameSet(deny)
+	if len(allowSet) == 0 && len(denySet) == 0 {
+		return nil
+	}
+	return &ToolFilter{allow: allowSet, deny: denySet}
+}
+
+// nameSet returns the trimmed, non-empty names as a set.
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// Allows reports whether the tool named name may be used.
+func (f *ToolFilter) Allows(name string) bool {
+	if f == nil {
+		return true
+	}
+	if f.deny[name] {
+		return false
+	}
+	return len(f.allow) == 0 || f.allow[name]
+}
+
+// Apply returns the tools in list that the filter allows.
+func (f *ToolFilter) Apply(list []Tool) []Tool {
+	if f == nil {
+		return list
+	}
+	allowed := make([]Tool, 0, len(list))
+	for _, tool := range list {
+		if f.Allows(tool.Metadata().Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// Validate returns an error naming any filtered tool not in known, so a
+// typo doesn't silently leave a tool enabled.
+func (f *ToolFilter) Validate(known []string) error {
+	if f == nil {
+		return nil
+	}
+	knownSet := nameSet(known)
+	var unknown []string
+	for _, set := range []map[string]bool{f.allow, f.deny} {
+		for name := range set {
+			if !knownSet[name] {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	available := make([]string, 0, len(knownSet))
+	for name := range knownSet {
+		available = append(available, name)
+	}
+	sort.Strings(available)
+	return fmt.Errorf("unknown tools: %s (available: %s)", strings.Join(unknown, ", "), strings.Join(available, ", "))
+}
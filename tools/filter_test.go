@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToolFilter(t *testing.T) {
+	if NewToolFilter(nil, []string{" "}) != nil {
+		t.Error("empty lists should give a nil filter")
+	}
+	var none *ToolFilter
+	if !none.Allows("execute_shell") {
+		t.Error("a nil filter should allow every tool")
+	}
+
+	filter := NewToolFilter([]string{"read_file", "execute_shell"}, []string{"execute_shell"})
+	if !filter.Allows("read_file") || filter.Allows("execute_shell") || filter.Allows("http_request") {
+		t.Error("only allowed tools that are not denied should pass")
+	}
+
+	list := []Tool{cannedTool{name: "read_file"}, cannedTool{name: "http_request"}}
+	if allowed := filter.Apply(list); len(allowed) != 1 || allowed[0].Metadata().Name != "read_file" {
+		t.Errorf("Apply should keep only read_file, got %d tools", len(allowed))
+	}
+
+	err := NewToolFilter(nil, []string{"shell", "read_file"}).Validate([]string{"read_file", "execute_shell"})
+	if err == nil || !strings.Contains(err.Error(), "unknown tools: shell") || !strings.Contains(err.Error(), "execute_shell") {
+		t.Errorf("expected an unknown tool error listing the available tools, got %v", err)
+	}
+}
+
+func TestExecutorDeniesFilteredTools(t *testing.T) {
+	calls := 0
+	executor := NewExecutor(ToolConfig{Filter: NewToolFilter(nil, []string{"execute_shell"})})
+
+	result, _ := executor.Execute(context.Background(), cannedTool{name: "execute_shell", calls: &calls}, json.RawMessage(`{}`))
+	if result.Success() || calls != 0 {
+		t.Errorf("a denied tool should not run: %+v", result)
+	}
+}
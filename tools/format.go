@@ -0,0 +1,431 @@
+// Code Formatting Tool.
+//
+// format_code runs the project's formatter over files the agent edited
+// (or explicit paths) and returns a diff of what changed, so agent-written
+// patches pass CI formatting checks. Go uses goimports when installed and
+// go/format otherwise; prettier runs for web files in projects that carry a
+// prettier config. Other formatters can be registered per extension.
+// With an artifact directory, formatting changes the run's copies of files
+// (copying originals in first), never the originals.
+//
+// Information Hiding:
+// - Formatter selection and invocation hidden
+// - Edited-file tracking hidden behind EditTracker
+// - Line diff algorithm hidden
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDiffCells bounds the line diff table; larger changes are reported
+// as a line count instead of a diff.
+const maxDiffCells = 4_000_000
+
+// prettierConfigs mark a project as using prettier.
+var prettierConfigs = []string{
+	".prettierrc", ".prettierrc.json", ".prettierrc.yaml", ".prettierrc.yml",
+	".prettierrc.js", ".prettierrc.cjs", ".prettierrc.mjs", ".prettierrc.toml",
+	"prettier.config.js", "prettier.config.cjs", "prettier.config.mjs",
+}
+
+// prettierExtensions are formatted with prettier when a config is found.
+var prettierExtensions = []string{
+	".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts",
+	".json", ".css", ".scss", ".less", ".html", ".vue", ".md", ".yaml", ".yml",
+}
+
+// EditTracker records files written or edited during a run.
+type EditTracker struct {
+	mu    sync.Mutex
+	files map[string]bool
+}
+
+// NewEditTracker creates an empty tracker.
+func NewEditTracker() *EditTracker {
+	return &EditTracker{files: make(map[string]bool)}
+}
+
+// Add records an edited file. Safe to call on a nil tracker.
+func (e *EditTracker) Add(path string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.files[filepath.Clean(path)] = true
+}
+
+// Files returns the edited files, sorted.
+func (e *EditTracker) Files() []string {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	files := make([]string, 0, len(e.files))
+	for f := range e.files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// FormatTool formats source files and reports the changes as a diff.
+type FormatTool struct {
+	BaseTool
+	timeoutSecs  uint64
+	allowedPaths []string
+	artifacts    *ArtifactDir // When set, formatting changes copies in the run's artifact directory
+	edits        *EditTracker
+	formatters   map[string][]string // Extension -> command reading stdin, writing stdout
+}
+
+// NewFormatTool creates a format tool with the default Go and prettier formatters.
+func NewFormatTool(timeoutSecs uint64) *FormatTool {
+	return &FormatTool{
+		timeoutSecs: timeoutSecs,
+		formatters:  make(map[string][]string),
+	}
+}
+
+// WithEditTracker formats the tracked files when no paths are given.
+func (t *FormatTool) WithEditTracker(edits *EditTracker) *FormatTool {
+	t.edits = edits
+	return t
+}
+
+// WithArtifactDir formats copies of files in a run's artifact directory,
+// leaving the originals untouched. A nil dir formats files in place.
+func (t *FormatTool) WithArtifactDir(dir *ArtifactDir) *FormatTool {
+	t.artifacts = dir
+	return t
+}
+
+// WithAllowedPaths sets the allowed path prefixes.
+func (t *FormatTool) WithAllowedPaths(paths []string) *FormatTool {
+	t.allowedPaths = paths
+	return t
+}
+
+// WithFormatter registers a formatter command for a file extension (e.g.
+// ".py", "ruff", "format", "-"), overriding the default. The command reads
+// the file on stdin and writes the formatted file to stdout; "{path}" in
+// an argument is replaced with the file path.
+func (t *FormatTool) WithFormatter(ext string, command ...string) *FormatTool {
+	t.formatters[strings.ToLower(ext)] = command
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *FormatTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "format_code",
+		Description: "Format source files (gofmt/goimports for Go, prettier for JS/TS/etc. when the project configures it) and return a diff of the changes. With no paths, formats every file written or edited in this run. Run before finishing a code change.",
+		Parameters: []ToolParameter{
+			{Name: "paths", ParamType: "array", Description: "Files to format (default: files edited in this run)", Required: false, Items: map[string]interface{}{"type": "string"}},
+			{Name: "check", ParamType: "boolean", Description: "Only report the diff, don't modify files (default false)", Required: false},
+		},
+	}
+}
+
+type formatArgs struct {
+	Paths []string `json:"paths"`
+	Check bool     `json:"check"`
+}
+
+// Execute formats the files.
+func (t *FormatTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a formatArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+
+	paths := a.Paths
+	if len(paths) == 0 {
+		paths = t.edits.Files()
+	}
+	if len(paths) == 0 {
+		return SuccessResult("No files to format: none edited in this run and no paths given"), nil
+	}
+
+	var diffs []string
+	var skipped, failed []string
+	changed := 0
+	for _, path := range paths {
+		if t.artifacts != nil {
+			path = t.artifacts.Locate(path)
+		} else if !pathAllowedForWrite(path, t.allowedPaths) {
+			failed = append(failed, fmt.Sprintf("%s: access not allowed", path))
+			continue
+		}
+		original, err := os.ReadFile(path)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+
+		formatted, ok, err := t.format(ctx, path, original)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		if !ok {
+			skipped = append(skipped, path)
+			continue
+		}
+		if bytes.Equal(original, formatted) {
+			continue
+		}
+
+		changed++
+		diffs = append(diffs, unifiedDiff(path, path+" (formatted)", string(original), string(formatted)))
+		if !a.Check {
+			if t.artifacts != nil {
+				target, err := t.artifacts.CopyOnWrite(path)
+				if err != nil {
+					failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+					continue
+				}
+				path = target
+			}
+			if err := os.WriteFile(path, formatted, 0644); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: failed to write: %v", path, err))
+				continue
+			}
+			reportFileChange(ctx, FileChange{Tool: "format_code", Path: path, Before: string(original), After: string(formatted)})
+		}
+	}
+
+	var sb strings.Builder
+	verb := "Formatted"
+	if a.Check {
+		verb = "Would reformat"
+	}
+	sb.WriteString(fmt.Sprintf("%s %d of %d files", verb, changed, len(paths)))
+	if len(skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("; no formatter for: %s", strings.Join(skipped, ", ")))
+	}
+	sb.WriteString("\n")
+	for _, d := range diffs {
+		sb.WriteString("\n" + d)
+	}
+	if len(failed) > 0 {
+		return FailureResultf("%s\nerrors:\n%s", strings.TrimRight(sb.String(), "\n"), strings.Join(failed, "\n")), nil
+	}
+	return SuccessResult(strings.TrimRight(sb.String(), "\n")), nil
+}
+
+// format returns the formatted content, or false if no formatter applies.
+func (t *FormatTool) format(ctx context.Context, path string, content []byte) ([]byte, bool, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if command, ok := t.formatters[ext]; ok {
+		out, err := t.run(ctx, command, path, content)
+		return out, err == nil, err
+	}
+
+	if ext == ".go" {
+		if _, err := exec.LookPath("goimports"); err == nil {
+			out, err := t.run(ctx, []string{"goimports", "-srcdir", filepath.Dir(path)}, path, content)
+			return out, err == nil, err
+		}
+		out, err := format.Source(content)
+		if err != nil {
+			return nil, false, fmt.Errorf("gofmt: %w", err)
+		}
+		return out, true, nil
+	}
+
+	for _, e := range prettierExtensions {
+		if e == ext && hasPrettierConfig(path) {
+			command := []string{"npx", "--no-install", "prettier", "--stdin-filepath", "{path}"}
+			if _, err := exec.LookPath("prettier"); err == nil {
+				command = []string{"prettier", "--stdin-filepath", "{path}"}
+			}
+			out, err := t.run(ctx, command, path, content)
+			return out, err == nil, err
+		}
+	}
+	return nil, false, nil
+}
+
+// run pipes content through a formatter command.
+func (t *FormatTool) run(ctx context.Context, command []string, path string, content []byte) ([]byte, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("empty formatter command")
+	}
+	args := make([]string, len(command)-1)
+	for i, arg := range command[1:] {
+		args[i] = strings.ReplaceAll(arg, "{path}", path)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.timeoutSecs)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	cmd.Stdin = bytes.NewReader(content)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s timed out after %d seconds", command[0], t.timeoutSecs)
+		}
+		return nil, fmt.Errorf("%s failed: %v: %s", command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// hasPrettierConfig reports whether a prettier config exists in the file's
+// directory or any parent, including a "prettier" key in package.json.
+func hasPrettierConfig(path string) bool {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	for {
+		for _, name := range prettierConfigs {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return true
+			}
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+			var pkg map[string]json.RawMessage
+			if json.Unmarshal(data, &pkg) == nil {
+				if _, ok := pkg["prettier"]; ok {
+					return true
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// unifiedDiff renders a unified diff with 3 lines of context, labelling
+// the two sides oldName and newName.
+func unifiedDiff(oldName, newName, before, after string) string {
+	a := splitLinesKeepEnds(before)
+	b := splitLinesKeepEnds(after)
+
+	// Trim the common prefix and suffix so the table only covers the changes
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	header := fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName)
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		return header + fmt.Sprintf("@@ %d lines changed (diff too large to show) @@\n", len(midA)+len(midB))
+	}
+
+	// Longest common subsequence table over the middle section
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		kind byte // ' ', '-', '+'
+		text string
+		a, b int // 1-based line numbers in before/after
+	}
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{' ', a[i], i + 1, i + 1})
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			ops = append(ops, op{' ', midA[i], prefix + i + 1, prefix + j + 1})
+			i++
+			j++
+		case i < len(midA) && (j == len(midB) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', midA[i], prefix + i + 1, prefix + j + 1})
+			i++
+		default:
+			ops = append(ops, op{'+', midB[j], prefix + i + 1, prefix + j + 1})
+			j++
+		}
+	}
+	for k := 0; k < suffix; k++ {
+		ops = append(ops, op{' ', a[len(a)-suffix+k], len(a) - suffix + k + 1, len(b) - suffix + k + 1})
+	}
+
+	// Group changes into hunks with context
+	const diffContext = 3
+	var sb strings.Builder
+	sb.WriteString(header)
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		from := max(start-diffContext, 0)
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k
+			} else if k-end > 2*diffContext {
+				break
+			}
+		}
+		to := min(end+diffContext+1, len(ops))
+
+		countA, countB := 0, 0
+		for _, o := range ops[from:to] {
+			if o.kind != '+' {
+				countA++
+			}
+			if o.kind != '-' {
+				countB++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", ops[from].a, countA, ops[from].b, countB))
+		for _, o := range ops[from:to] {
+			line := strings.TrimSuffix(o.text, "\n")
+			sb.WriteString(string(o.kind) + line + "\n")
+		}
+		start = to
+	}
+	return sb.String()
+}
+
+// splitLinesKeepEnds splits text into lines that keep their newline.
+func splitLinesKeepEnds(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
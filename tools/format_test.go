@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatToolFormatsEditedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	edits := NewEditTracker()
+
+	write := NewWriteFileTool(1024).WithEditTracker(edits)
+	args, _ := json.Marshal(writeFileArgs{Path: path, Content: "package main\n\nfunc main() {\nx:=1\n_ = x\n}\n"})
+	if result, _ := write.Execute(context.Background(), args); !result.Success() {
+		t.Fatalf("write failed: %v", result.Error)
+	}
+
+	tool := NewFormatTool(30).WithEditTracker(edits)
+	result, _ := tool.Execute(context.Background(), json.RawMessage(`{"check":true}`))
+	if !result.Success() {
+		t.Fatalf("format failed: %v", result.Error)
+	}
+	if !strings.Contains(result.Output, "Would reformat 1 of 1 files") || !strings.Contains(result.Output, "-x:=1\n-_ = x\n+\tx := 1\n") {
+		t.Errorf("unexpected check output:\n%s", result.Output)
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "x:=1") {
+		t.Error("check mode must not modify the file")
+	}
+
+	result, _ = tool.Execute(context.Background(), json.RawMessage(`{}`))
+	if !result.Success() || !strings.Contains(result.Output, "Formatted 1 of 1 files") {
+		t.Fatalf("unexpected format output: %+v", result)
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "\tx := 1\n") {
+		t.Errorf("file not formatted:\n%s", content)
+	}
+
+	result, _ = tool.Execute(context.Background(), json.RawMessage(`{}`))
+	if !result.Success() || !strings.HasPrefix(result.Output, "Formatted 0 of 1 files") {
+		t.Errorf("expected no changes on second pass: %+v", result)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	diff := unifiedDiff("x.txt", "x.txt (formatted)", before, after)
+	want := "--- x.txt\n+++ x.txt (formatted)\n" +
+		"@@ -1,6 +1,6 @@\n a\n b\n-c\n+C\n d\n e\n f\n" +
+		"@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n"
+	if diff != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", diff, want)
+	}
+}
@@ -0,0 +1,149 @@
+// Gitignore matching for file discovery tools.
+//
+// Supports the common .gitignore syntax: comments, negation (!),
+// directory-only patterns (trailing /), anchored patterns (containing /),
+// and ** wildcards. Nested .gitignore files apply to their own subtree.
+//
+// Information Hiding:
+// - Lazy loading of nested .gitignore files hidden
+// - Rule precedence hidden
+
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreRule is one parsed line of a .gitignore file.
+type gitignoreRule struct {
+	base     string // Directory of the .gitignore, relative to the root ("" for root)
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// gitignoreMatcher answers whether paths under root are ignored.
+// Not safe for concurrent use; create one per search.
+type gitignoreMatcher struct {
+	root   string
+	rules  []gitignoreRule
+	loaded map[string]bool // Relative dirs whose .gitignore has been read
+}
+
+func newGitignoreMatcher(root string) *gitignoreMatcher {
+	return &gitignoreMatcher{root: root, loaded: make(map[string]bool)}
+}
+
+// Ignored reports whether rel (slash or OS separated, relative to root) is
+// ignored, either directly or because one of its parent directories is.
+func (m *gitignoreMatcher) Ignored(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == "" {
+		return false
+	}
+
+	m.load("")
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if m.match(dir, true) {
+			return true
+		}
+		m.load(dir)
+	}
+	return m.match(rel, isDir)
+}
+
+// match applies the loaded rules to a single path. The last matching rule wins.
+func (m *gitignoreMatcher) match(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, rule.base+"/")
+		}
+		if rule.matches(sub) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (r gitignoreRule) matches(sub string) bool {
+	if !r.anchored {
+		return matchPattern(r.pattern, path.Base(sub))
+	}
+	if strings.Contains(r.pattern, "**") {
+		return matchGlobPattern(sub, r.pattern)
+	}
+	return matchPattern(r.pattern, sub)
+}
+
+// load reads the .gitignore in a relative directory once.
+func (m *gitignoreMatcher) load(dir string) {
+	if m.loaded[dir] {
+		return
+	}
+	m.loaded[dir] = true
+
+	f, err := os.Open(filepath.Join(m.root, filepath.FromSlash(dir), ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseGitignoreLine(dir, scanner.Text()); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// parseGitignoreLine parses one .gitignore line. Returns false for blanks and comments.
+func parseGitignoreLine(base, line string) (gitignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return gitignoreRule{}, false
+	}
+
+	rule := gitignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // Escaped leading # or !
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if strings.HasPrefix(line, "**/") {
+		// "**/name" matches name at any depth, like an unanchored pattern
+		rest := strings.TrimPrefix(line, "**/")
+		if !strings.Contains(rest, "/") {
+			rule.anchored = false
+			line = rest
+		}
+	}
+	if line == "" {
+		return gitignoreRule{}, false
+	}
+	rule.pattern = line
+	return rule, true
+}
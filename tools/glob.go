@@ -2,6 +2,8 @@
 //
 // Returns file paths matching a glob pattern without reading content.
 // Designed for the RLM pattern where discovery and content loading are separate.
+// Respects .gitignore, supports exclusions, sorting, per-file metadata, and
+// paging so large repositories are never truncated silently.
 
 package tools
 
@@ -12,7 +14,9 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -20,6 +24,16 @@ const (
 	DefaultGlobMaxResults = 100
 	// AbsoluteGlobMaxResults is the hard limit to prevent excessive memory.
 	AbsoluteGlobMaxResults = 1000
+	// GlobScanLimit caps how many matching files one search collects.
+	// Searches hitting it report so rather than truncating silently.
+	GlobScanLimit = 100000
+)
+
+// Glob sort orders.
+const (
+	GlobSortName  = "name"  // Alphabetical (default)
+	GlobSortMtime = "mtime" // Most recently modified first
+	GlobSortSize  = "size"  // Largest first
 )
 
 // GlobTool finds files matching glob patterns.
@@ -40,20 +54,30 @@ func NewGlobTool(maxResults int) *GlobTool {
 func (t *GlobTool) Metadata() ToolMetadata {
 	return ToolMetadata{
 		Name:        "glob",
-		Description: "Find files matching a glob pattern. Returns file paths only (no content). Hidden directories (starting with .) are skipped. Use for discovery, then read_file to load content.",
+		Description: "Find files matching a glob pattern. Returns file paths only (no content). Hidden directories (starting with .) and .gitignore'd files are skipped. Results are paged: if more files match, the output gives a page_token for the next page. Use for discovery, then read_file to load content.",
 		Parameters: []ToolParameter{
 			{Name: "pattern", ParamType: "string", Description: "Glob pattern (e.g., '**/*.go', 'src/**/*.ts', '*.yaml')", Required: true},
 			{Name: "path", ParamType: "string", Description: "Base directory to search from (default: current directory)", Required: false},
-			{Name: "max_results", ParamType: "integer", Description: fmt.Sprintf("Maximum files to return (default: %d)", DefaultGlobMaxResults), Required: false},
+			{Name: "max_results", ParamType: "integer", Description: fmt.Sprintf("Maximum files per page (default: %d)", DefaultGlobMaxResults), Required: false},
+			{Name: "exclude", ParamType: "array", Description: "Glob patterns to exclude (e.g., ['**/*_test.go', 'vendor/**'])", Required: false, Items: map[string]interface{}{"type": "string"}},
+			{Name: "sort", ParamType: "string", Description: "Sort order: 'name' (default), 'mtime' (newest first), or 'size' (largest first)", Required: false},
+			{Name: "metadata", ParamType: "boolean", Description: "Include size, modification time, and language for each file (default: false)", Required: false},
+			{Name: "respect_gitignore", ParamType: "boolean", Description: "Skip files ignored by .gitignore (default: true)", Required: false},
+			{Name: "page_token", ParamType: "string", Description: "Token from a previous call to fetch the next page", Required: false},
 		},
 	}
 }
 
 // GlobArgs are the arguments for the glob tool.
 type GlobArgs struct {
-	Pattern    string `json:"pattern"`
-	Path       string `json:"path"`
-	MaxResults *int   `json:"max_results"`
+	Pattern          string   `json:"pattern"`
+	Path             string   `json:"path"`
+	MaxResults       *int     `json:"max_results"`
+	Exclude          []string `json:"exclude"`
+	Sort             string   `json:"sort"`
+	Metadata         bool     `json:"metadata"`
+	RespectGitignore *bool    `json:"respect_gitignore"`
+	PageToken        string   `json:"page_token"`
 }
 
 // Validate validates the arguments.
@@ -65,6 +89,14 @@ func (t *GlobTool) Validate(args json.RawMessage) error {
 	if strings.TrimSpace(globArgs.Pattern) == "" {
 		return fmt.Errorf("pattern is required")
 	}
+	switch globArgs.Sort {
+	case "", GlobSortName, GlobSortMtime, GlobSortSize:
+	default:
+		return fmt.Errorf("invalid sort '%s': use name, mtime, or size", globArgs.Sort)
+	}
+	if _, err := parsePageToken(globArgs.PageToken); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -89,41 +121,91 @@ func (t *GlobTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 		maxResults = t.maxResults
 	}
 
-	matches, err := t.findMatches(ctx, basePath, globArgs.Pattern, maxResults)
+	offset, err := parsePageToken(globArgs.PageToken)
+	if err != nil {
+		return FailureResultf("%v", err), nil
+	}
+
+	filter := globFilter{exclude: globArgs.Exclude}
+	if globArgs.RespectGitignore == nil || *globArgs.RespectGitignore {
+		filter.gitignore = true
+	}
+
+	matches, complete, err := t.findMatches(ctx, basePath, globArgs.Pattern, filter)
 	if err != nil {
 		return FailureResultf("%v", err), nil
 	}
+	sortGlobMatches(matches, globArgs.Sort)
+
+	return t.formatResult(globArgs, basePath, matches, complete, offset, maxResults), nil
+}
+
+// globMatch is a matched file with its stat metadata.
+type globMatch struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// globFilter holds exclusion settings for one search.
+type globFilter struct {
+	exclude   []string
+	gitignore bool
+	matcher   *gitignoreMatcher
+}
 
-	return t.formatResult(globArgs.Pattern, basePath, matches, maxResults), nil
+// skip reports whether a path is excluded or gitignored.
+func (f *globFilter) skip(relPath string, isDir bool) bool {
+	if f.matcher != nil && f.matcher.Ignored(relPath, isDir) {
+		return true
+	}
+	if isDir {
+		return false
+	}
+	for _, pattern := range f.exclude {
+		if matchGlobPattern(relPath, pattern) {
+			return true
+		}
+		if !strings.Contains(pattern, "/") && matchPattern(pattern, filepath.Base(relPath)) {
+			return true
+		}
+	}
+	return false
 }
 
 // findMatches finds files matching the pattern in basePath.
-func (t *GlobTool) findMatches(ctx context.Context, basePath, pattern string, maxResults int) ([]string, error) {
+// Returns false if the search stopped at GlobScanLimit.
+func (t *GlobTool) findMatches(ctx context.Context, basePath, pattern string, filter globFilter) ([]globMatch, bool, error) {
 	absBase, err := filepath.Abs(basePath)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base path: %w", err)
+		return nil, false, fmt.Errorf("invalid base path: %w", err)
 	}
 
 	dirInfo, err := os.Stat(absBase)
 	if err != nil {
-		return nil, fmt.Errorf("path not found: %s", basePath)
+		return nil, false, fmt.Errorf("path not found: %s", basePath)
 	}
 	if !dirInfo.IsDir() {
-		return nil, fmt.Errorf("path is not a directory: %s", basePath)
+		return nil, false, fmt.Errorf("path is not a directory: %s", basePath)
+	}
+
+	if filter.gitignore {
+		filter.matcher = newGitignoreMatcher(absBase)
 	}
 
 	// Normalize pattern: strip leading "./" as it's redundant
 	pattern = strings.TrimPrefix(pattern, "./")
 
 	if strings.Contains(pattern, "**") {
-		return t.findMatchesRecursive(ctx, absBase, pattern, maxResults)
+		return t.findMatchesRecursive(ctx, absBase, pattern, &filter)
 	}
-	return t.findMatchesSimple(absBase, pattern, maxResults)
+	return t.findMatchesSimple(absBase, pattern, &filter)
 }
 
 // findMatchesRecursive handles patterns with ** using WalkDir.
-func (t *GlobTool) findMatchesRecursive(ctx context.Context, absBase, pattern string, maxResults int) ([]string, error) {
-	var matches []string
+func (t *GlobTool) findMatchesRecursive(ctx context.Context, absBase, pattern string, filter *globFilter) ([]globMatch, bool, error) {
+	var matches []globMatch
+	complete := true
 
 	err := filepath.WalkDir(absBase, func(path string, entry os.DirEntry, err error) error {
 		// Check for cancellation
@@ -141,82 +223,167 @@ func (t *GlobTool) findMatchesRecursive(ctx context.Context, absBase, pattern st
 			return nil
 		}
 
+		relPath, err := filepath.Rel(absBase, path)
+		if err != nil {
+			return nil
+		}
+
 		if entry.IsDir() {
 			// Skip hidden directories
 			if strings.HasPrefix(entry.Name(), ".") && entry.Name() != "." {
 				return filepath.SkipDir
 			}
+			if relPath != "." && filter.skip(relPath, true) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		relPath, err := filepath.Rel(absBase, path)
-		if err != nil {
+		if !matchGlobPattern(relPath, pattern) || filter.skip(relPath, false) {
 			return nil
 		}
 
-		if matchGlobPattern(relPath, pattern) {
-			matches = append(matches, relPath)
-			if len(matches) >= maxResults {
-				return filepath.SkipAll
-			}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		matches = append(matches, globMatch{path: relPath, size: info.Size(), modTime: info.ModTime()})
+		if len(matches) >= GlobScanLimit {
+			complete = false
+			return filepath.SkipAll
 		}
 		return nil
 	})
 	if err != nil && err != filepath.SkipAll {
-		return matches, err
+		return matches, complete, err
 	}
 
-	sort.Strings(matches)
-	return matches, nil
+	return matches, complete, nil
 }
 
 // findMatchesSimple handles patterns without ** using filepath.Glob.
-func (t *GlobTool) findMatchesSimple(absBase, pattern string, maxResults int) ([]string, error) {
+func (t *GlobTool) findMatchesSimple(absBase, pattern string, filter *globFilter) ([]globMatch, bool, error) {
 	fullPattern := filepath.Join(absBase, pattern)
 	globMatches, err := filepath.Glob(fullPattern)
 	if err != nil {
-		return nil, fmt.Errorf("invalid glob pattern: %w", err)
+		return nil, false, fmt.Errorf("invalid glob pattern: %w", err)
 	}
 
-	var matches []string
+	var matches []globMatch
 	for _, m := range globMatches {
 		fileInfo, err := os.Stat(m)
 		if err != nil || fileInfo.IsDir() {
 			continue
 		}
 		relPath, err := filepath.Rel(absBase, m)
-		if err != nil {
+		if err != nil || filter.skip(relPath, false) {
 			continue
 		}
-		matches = append(matches, relPath)
-		if len(matches) >= maxResults {
-			break
+		matches = append(matches, globMatch{path: relPath, size: fileInfo.Size(), modTime: fileInfo.ModTime()})
+		if len(matches) >= GlobScanLimit {
+			return matches, false, nil
 		}
 	}
 
-	sort.Strings(matches)
-	return matches, nil
+	return matches, true, nil
+}
+
+// sortGlobMatches orders matches; ties fall back to path order.
+func sortGlobMatches(matches []globMatch, order string) {
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch order {
+		case GlobSortMtime:
+			if !a.modTime.Equal(b.modTime) {
+				return a.modTime.After(b.modTime)
+			}
+		case GlobSortSize:
+			if a.size != b.size {
+				return a.size > b.size
+			}
+		}
+		return a.path < b.path
+	})
+}
+
+// parsePageToken decodes a page token into a result offset.
+func parsePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(token)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid page_token '%s'", token)
+	}
+	return offset, nil
 }
 
-// formatResult formats the matches into a ToolResult.
-func (t *GlobTool) formatResult(pattern, basePath string, matches []string, maxResults int) ToolResult {
+// formatResult formats one page of matches into a ToolResult.
+func (t *GlobTool) formatResult(args GlobArgs, basePath string, matches []globMatch, complete bool, offset, maxResults int) ToolResult {
 	if len(matches) == 0 {
-		return SuccessResult(fmt.Sprintf("No files found matching pattern '%s' in %s", pattern, basePath))
+		return SuccessResult(fmt.Sprintf("No files found matching pattern '%s' in %s", args.Pattern, basePath))
+	}
+	if offset >= len(matches) {
+		return SuccessResult(fmt.Sprintf("No more files: page_token %d is past the last of %d matches", offset, len(matches)))
 	}
 
+	end := offset + maxResults
+	if end > len(matches) {
+		end = len(matches)
+	}
+	page := matches[offset:end]
+
 	var result strings.Builder
-	fmt.Fprintf(&result, "Found %d files matching '%s':\n", len(matches), pattern)
-	for _, m := range matches {
-		fmt.Fprintln(&result, m)
+	if offset == 0 && end == len(matches) {
+		fmt.Fprintf(&result, "Found %d files matching '%s':\n", len(matches), args.Pattern)
+	} else {
+		fmt.Fprintf(&result, "Found %d files matching '%s' (showing %d-%d):\n", len(matches), args.Pattern, offset+1, end)
+	}
+	for _, m := range page {
+		if args.Metadata {
+			fmt.Fprintf(&result, "%s\t%d bytes\t%s\t%s\n", m.path, m.size, m.modTime.Format(time.RFC3339), guessLanguage(m.path))
+		} else {
+			fmt.Fprintln(&result, m.path)
+		}
 	}
 
-	if len(matches) >= maxResults {
-		fmt.Fprintf(&result, "\n(limited to %d results)", maxResults)
+	if end < len(matches) {
+		fmt.Fprintf(&result, "\n(%d more files: call again with page_token \"%d\")", len(matches)-end, end)
+	}
+	if !complete {
+		fmt.Fprintf(&result, "\n(search stopped after %d matches; narrow the pattern or add exclusions)", GlobScanLimit)
 	}
 
 	return SuccessResult(result.String())
 }
 
+// languageByExt maps file extensions to language names for metadata output.
+var languageByExt = map[string]string{
+	".go": "Go", ".py": "Python", ".js": "JavaScript", ".jsx": "JavaScript",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".rs": "Rust", ".java": "Java",
+	".kt": "Kotlin", ".c": "C", ".h": "C", ".cc": "C++", ".cpp": "C++",
+	".hpp": "C++", ".cs": "C#", ".rb": "Ruby", ".php": "PHP", ".swift": "Swift",
+	".scala": "Scala", ".sh": "Shell", ".bash": "Shell", ".sql": "SQL",
+	".html": "HTML", ".css": "CSS", ".md": "Markdown", ".json": "JSON",
+	".yaml": "YAML", ".yml": "YAML", ".toml": "TOML", ".xml": "XML",
+	".proto": "Protobuf", ".tf": "Terraform",
+}
+
+// guessLanguage returns a language name from the file name, or "unknown".
+func guessLanguage(path string) string {
+	base := filepath.Base(path)
+	switch base {
+	case "Dockerfile":
+		return "Dockerfile"
+	case "Makefile":
+		return "Makefile"
+	}
+	if lang, ok := languageByExt[strings.ToLower(filepath.Ext(base))]; ok {
+		return lang
+	}
+	return "unknown"
+}
+
 // matchGlobPattern matches a path against a glob pattern with ** support.
 func matchGlobPattern(path, pattern string) bool {
 	// Normalize separators
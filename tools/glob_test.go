@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeGlobFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runGlob(t *testing.T, args GlobArgs) string {
+	t.Helper()
+	raw, _ := json.Marshal(args)
+	result, err := NewGlobTool(0).Execute(context.Background(), raw)
+	if err != nil || !result.Success() {
+		t.Fatalf("glob failed: %+v, err %v", result, err)
+	}
+	return result.Output
+}
+
+func TestGlobRespectsGitignore(t *testing.T) {
+	dir := writeGlobFixture(t, map[string]string{
+		".gitignore":        "build/\n*.gen.go\n!keep.gen.go\n",
+		"main.go":           "",
+		"util.gen.go":       "",
+		"keep.gen.go":       "",
+		"build/out.go":      "",
+		"pkg/.gitignore":    "local.go\n",
+		"pkg/local.go":      "",
+		"pkg/shared.go":     "",
+		"pkg/sub/deeper.go": "",
+	})
+
+	output := runGlob(t, GlobArgs{Pattern: "**/*.go", Path: dir})
+	for _, want := range []string{"main.go", "keep.gen.go", "pkg/shared.go", "pkg/sub/deeper.go"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %s in output:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"util.gen.go", "build/out.go", "pkg/local.go"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("expected %s to be ignored:\n%s", unwanted, output)
+		}
+	}
+
+	off := false
+	output = runGlob(t, GlobArgs{Pattern: "**/*.go", Path: dir, RespectGitignore: &off})
+	if !strings.Contains(output, "build/out.go") {
+		t.Errorf("expected ignored files when respect_gitignore is false:\n%s", output)
+	}
+
+	output = runGlob(t, GlobArgs{Pattern: "build/*.go", Path: dir})
+	if strings.Contains(output, "out.go") {
+		t.Errorf("expected simple glob to respect gitignored directories:\n%s", output)
+	}
+}
+
+func TestGlobExcludeAndSort(t *testing.T) {
+	dir := writeGlobFixture(t, map[string]string{
+		"small.go":      "a",
+		"large.go":      strings.Repeat("a", 100),
+		"large_test.go": strings.Repeat("a", 200),
+	})
+	old := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(filepath.Join(dir, "large.go"), old, old)
+
+	output := runGlob(t, GlobArgs{Pattern: "*.go", Path: dir, Exclude: []string{"*_test.go"}, Sort: GlobSortSize})
+	if strings.Contains(output, "large_test.go") {
+		t.Errorf("expected test file excluded:\n%s", output)
+	}
+	if strings.Index(output, "large.go") > strings.Index(output, "small.go") {
+		t.Errorf("expected largest file first:\n%s", output)
+	}
+
+	output = runGlob(t, GlobArgs{Pattern: "*.go", Path: dir, Sort: GlobSortMtime, Metadata: true})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if !strings.HasPrefix(lines[len(lines)-1], "large.go\t100 bytes") {
+		t.Errorf("expected oldest file last with metadata, got %q", lines[len(lines)-1])
+	}
+	if !strings.Contains(output, "\tGo") {
+		t.Errorf("expected language in metadata:\n%s", output)
+	}
+}
+
+func TestGlobPagination(t *testing.T) {
+	files := make(map[string]string)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"} {
+		files[name] = ""
+	}
+	dir := writeGlobFixture(t, files)
+
+	limit := 2
+	output := runGlob(t, GlobArgs{Pattern: "*.txt", Path: dir, MaxResults: &limit})
+	if !strings.Contains(output, "showing 1-2") || !strings.Contains(output, `page_token "2"`) {
+		t.Fatalf("expected first page with next token:\n%s", output)
+	}
+
+	output = runGlob(t, GlobArgs{Pattern: "*.txt", Path: dir, MaxResults: &limit, PageToken: "4"})
+	if !strings.Contains(output, "e.txt") || strings.Contains(output, "page_token") {
+		t.Errorf("expected last page without next token:\n%s", output)
+	}
+}
+
+func TestGlobValidateSort(t *testing.T) {
+	err := NewGlobTool(0).Validate(json.RawMessage(`{"pattern": "*.go", "sort": "random"}`))
+	if err == nil {
+		t.Error("expected invalid sort to fail validation")
+	}
+}
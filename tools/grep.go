@@ -0,0 +1,281 @@
+// Native grep tool.
+//
+// grep_files searches file contents with Go regular expressions, so agents
+// can search without ripgrep installed. Files are selected with the same
+// glob matching and .gitignore handling as the glob tool. Output uses
+// ripgrep's path:line:text layout and is bounded by max_results.
+//
+// With a ContentStore, every file that matched is stored, so the agent can
+// follow up with search_stored/get_lines instead of re-reading it.
+//
+// Information Hiding:
+// - File selection, binary detection and line scanning hidden
+// - Auto-storing of matched files hidden
+
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/model"
+)
+
+const (
+	// DefaultGrepMaxResults is the default maximum matching lines returned.
+	DefaultGrepMaxResults = 200
+	// maxGrepLineLength truncates long lines (minified files) in output.
+	maxGrepLineLength = 500
+)
+
+// GrepTool searches file contents with regular expressions.
+type GrepTool struct {
+	maxSizeBytes int64
+	maxResults   int
+	contentStore model.ContentStore
+	fileContext  *StoredFileContext
+}
+
+// NewGrepTool creates a grep tool. Files larger than maxSizeBytes are skipped.
+func NewGrepTool(maxSizeBytes int64) *GrepTool {
+	return &GrepTool{maxSizeBytes: maxSizeBytes, maxResults: DefaultGrepMaxResults}
+}
+
+// WithMaxResults sets the default maximum matching lines.
+func (t *GrepTool) WithMaxResults(max int) *GrepTool {
+	if max > 0 {
+		t.maxResults = max
+	}
+	return t
+}
+
+// WithContentStore stores files with matches for DSA search.
+func (t *GrepTool) WithContentStore(store model.ContentStore) *GrepTool {
+	t.contentStore = store
+	return t
+}
+
+// WithFileContext tracks stored files so get_lines can default to them.
+func (t *GrepTool) WithFileContext(ctx *StoredFileContext) *GrepTool {
+	t.fileContext = ctx
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *GrepTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "grep_files",
+		Description: "Search file contents with a regular expression (Go RE2 syntax). Returns path:line:text matches. Hidden directories and .gitignore'd files are skipped. Files with matches are stored for search_stored/get_lines.",
+		Parameters: []ToolParameter{
+			{Name: "pattern", ParamType: "string", Description: "Regular expression to search for", Required: true},
+			{Name: "path", ParamType: "string", Description: "File or directory to search (default: current directory)", Required: false},
+			{Name: "glob", ParamType: "string", Description: "Glob pattern selecting files (default: '**/*')", Required: false},
+			{Name: "case_sensitive", ParamType: "boolean", Description: "Case sensitive search (default: true)", Required: false},
+			{Name: "fixed_strings", ParamType: "boolean", Description: "Treat pattern as a literal string", Required: false},
+			{Name: "context", ParamType: "integer", Description: "Lines of context around matches", Required: false},
+			{Name: "max_results", ParamType: "integer", Description: fmt.Sprintf("Maximum matching lines (default: %d)", DefaultGrepMaxResults), Required: false},
+			{Name: "respect_gitignore", ParamType: "boolean", Description: "Skip files ignored by .gitignore (default: true)", Required: false},
+		},
+	}
+}
+
+type grepArgs struct {
+	Pattern          string `json:"pattern"`
+	Path             string `json:"path"`
+	Glob             string `json:"glob"`
+	CaseSensitive    *bool  `json:"case_sensitive"`
+	FixedStrings     bool   `json:"fixed_strings"`
+	Context          int    `json:"context"`
+	MaxResults       int    `json:"max_results"`
+	RespectGitignore *bool  `json:"respect_gitignore"`
+}
+
+// compile builds the search expression from the arguments.
+func (a grepArgs) compile() (*regexp.Regexp, error) {
+	expr := a.Pattern
+	if a.FixedStrings {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if a.CaseSensitive != nil && !*a.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// Validate validates the arguments.
+func (t *GrepTool) Validate(args json.RawMessage) error {
+	var a grepArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Pattern == "" {
+		return fmt.Errorf("pattern cannot be empty")
+	}
+	if a.Context < 0 {
+		return fmt.Errorf("context cannot be negative")
+	}
+	_, err := a.compile()
+	return err
+}
+
+// Execute searches the selected files.
+func (t *GrepTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a grepArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+	re, err := a.compile()
+	if err != nil {
+		return FailureResult(err), nil
+	}
+
+	searchPath := a.Path
+	if searchPath == "" {
+		searchPath = "."
+	}
+	files, complete, err := t.selectFiles(ctx, searchPath, a)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+
+	maxResults := t.maxResults
+	if a.MaxResults > 0 {
+		maxResults = a.MaxResults
+	}
+
+	var out strings.Builder
+	var stored []string
+	matches, matchedFiles := 0, 0
+	for _, path := range files {
+		if ctx.Err() != nil {
+			return FailureResult(fmt.Errorf("search cancelled: %w", ctx.Err())), nil
+		}
+		content, ok := readTextFile(path, t.maxSizeBytes)
+		if !ok {
+			continue
+		}
+		n := grepContent(&out, path, content, re, a.Context, maxResults-matches)
+		if n == 0 {
+			continue
+		}
+		matches += n
+		matchedFiles++
+		if t.storeFile(ctx, path, content) {
+			stored = append(stored, path)
+		}
+		if matches >= maxResults {
+			break
+		}
+	}
+
+	if matches == 0 {
+		return SuccessResult(fmt.Sprintf("No matches for %q in %d files", a.Pattern, len(files))), nil
+	}
+
+	fmt.Fprintf(&out, "\n[%d matches in %d files]", matches, matchedFiles)
+	if matches >= maxResults {
+		out.WriteString(" Stopped at max_results; narrow the pattern or glob for more.")
+	}
+	if !complete {
+		fmt.Fprintf(&out, " Only the first %d files were searched.", GlobScanLimit)
+	}
+	if len(stored) > 0 {
+		fmt.Fprintf(&out, "\nStored %d files for search_stored/get_lines: %s", len(stored), strings.Join(stored, ", "))
+	}
+	return SuccessResult(out.String()), nil
+}
+
+// selectFiles returns the files to search: the path itself if it is a file,
+// otherwise the files under it matching the glob.
+func (t *GrepTool) selectFiles(ctx context.Context, searchPath string, a grepArgs) ([]string, bool, error) {
+	info, err := os.Stat(searchPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("path not found: %s", searchPath)
+	}
+	if !info.IsDir() {
+		return []string{searchPath}, true, nil
+	}
+
+	pattern := a.Glob
+	if pattern == "" {
+		pattern = "**/*"
+	}
+	filter := globFilter{gitignore: a.RespectGitignore == nil || *a.RespectGitignore}
+	found, complete, err := (&GlobTool{}).findMatches(ctx, searchPath, pattern, filter)
+	if err != nil {
+		return nil, false, err
+	}
+	sortGlobMatches(found, GlobSortName)
+
+	files := make([]string, len(found))
+	for i, m := range found {
+		files[i] = filepath.Join(searchPath, m.path)
+	}
+	return files, complete, nil
+}
+
+// storeFile stores a matched file for DSA search. Returns false without a store.
+func (t *GrepTool) storeFile(ctx context.Context, path, content string) bool {
+	if t.contentStore == nil {
+		return false
+	}
+	if _, err := t.contentStore.StoreContent(ctx, model.FileKey(path), content); err != nil {
+		return false
+	}
+	if t.fileContext != nil {
+		t.fileContext.Add(path)
+	}
+	return true
+}
+
+// grepContent writes up to limit matching lines of one file, with context,
+// in ripgrep's format: "path:N:text" for matches, "path-N-text" for context
+// and "--" between separated groups. Returns the number of matching lines.
+func grepContent(out *strings.Builder, path, content string, re *regexp.Regexp, context, limit int) int {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	matches := 0
+	lastPrinted := -1
+	for i := 0; i < len(lines) && matches < limit; i++ {
+		if !re.MatchString(lines[i]) {
+			continue
+		}
+		matches++
+		start := max(i-context, lastPrinted+1)
+		if lastPrinted >= 0 && start > lastPrinted+1 {
+			out.WriteString("--\n")
+		}
+		for j := start; j < i; j++ {
+			writeGrepLine(out, path, j+1, '-', lines[j])
+		}
+		writeGrepLine(out, path, i+1, ':', lines[i])
+		lastPrinted = i
+
+		// Trailing context stops early at the next match, which prints itself
+		for j := i + 1; j <= i+context && j < len(lines) && !re.MatchString(lines[j]); j++ {
+			writeGrepLine(out, path, j+1, '-', lines[j])
+			lastPrinted = j
+		}
+	}
+	return matches
+}
+
+func writeGrepLine(out *strings.Builder, path string, lineNo int, sep byte, line string) {
+	fmt.Fprintf(out, "%s%c%d%c%s\n", path, sep, lineNo, sep, text.Truncate(line, maxGrepLineLength))
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/model"
+)
+
+// recordingStore is a ContentStore that remembers stored keys.
+type recordingStore struct {
+	paths []string
+}
+
+func (s *recordingStore) StoreContent(ctx context.Context, key model.ContentKey, content string) (model.StoredContent, error) {
+	s.paths = append(s.paths, key.Path)
+	return model.StoredContent{Lines: strings.Count(content, "\n") + 1, Bytes: len(content)}, nil
+}
+
+func runGrep(t *testing.T, tool *GrepTool, args grepArgs) string {
+	t.Helper()
+	raw, _ := json.Marshal(args)
+	if err := tool.Validate(raw); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	result, err := tool.Execute(context.Background(), raw)
+	if err != nil || !result.Success() {
+		t.Fatalf("grep failed: %+v, err %v", result, err)
+	}
+	return result.Output
+}
+
+func TestGrepFiles(t *testing.T) {
+	dir := writeGlobFixture(t, map[string]string{
+		".gitignore":     "gen/\n",
+		"main.go":        "package main\n\n// TODO: tidy\nfunc main() {}\n",
+		"pkg/util.go":    "package pkg\n// todo lower\n",
+		"gen/skip.go":    "// TODO generated\n",
+		"notes.txt":      "TODO in text\n",
+		"data/blob.bin":  "TODO\x00binary",
+		".hidden/x.go":   "// TODO hidden\n",
+		"pkg/nomatch.go": "package pkg\n",
+	})
+
+	store := &recordingStore{}
+	tool := NewGrepTool(DefaultMaxFileSize).WithContentStore(store)
+	output := runGrep(t, tool, grepArgs{Pattern: "TODO", Path: dir, Glob: "**/*.go"})
+
+	mainPath := filepath.Join(dir, "main.go")
+	if !strings.Contains(output, mainPath+":3:// TODO: tidy") {
+		t.Errorf("expected main.go match, got:\n%s", output)
+	}
+	for _, unwanted := range []string{"util.go", "skip.go", "notes.txt", "blob.bin", "hidden"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("did not expect %s in output:\n%s", unwanted, output)
+		}
+	}
+	if len(store.paths) != 1 || store.paths[0] != mainPath {
+		t.Errorf("expected only main.go stored, got %v", store.paths)
+	}
+
+	insensitive := false
+	output = runGrep(t, NewGrepTool(DefaultMaxFileSize), grepArgs{Pattern: "todo", Path: dir, CaseSensitive: &insensitive})
+	if !strings.Contains(output, "[3 matches in 3 files]") {
+		t.Errorf("expected case-insensitive matches in main.go, util.go and notes.txt, got:\n%s", output)
+	}
+}
+
+func TestGrepFilesContextAndLimit(t *testing.T) {
+	dir := writeGlobFixture(t, map[string]string{
+		"a.txt": "one\nhit 1\ntwo\nthree\nfour\nhit 2\nhit 3\nfive\n",
+	})
+	path := filepath.Join(dir, "a.txt")
+
+	output := runGrep(t, NewGrepTool(DefaultMaxFileSize), grepArgs{Pattern: "hit", Path: path, Context: 1})
+	want := strings.Join([]string{
+		path + "-1-one",
+		path + ":2:hit 1",
+		path + "-3-two",
+		"--",
+		path + "-5-four",
+		path + ":6:hit 2",
+		path + ":7:hit 3",
+		path + "-8-five",
+	}, "\n")
+	if !strings.HasPrefix(output, want+"\n") {
+		t.Errorf("unexpected context output:\n%s\nwant prefix:\n%s", output, want)
+	}
+
+	output = runGrep(t, NewGrepTool(DefaultMaxFileSize), grepArgs{Pattern: "hit", Path: path, MaxResults: 2})
+	if strings.Contains(output, "hit 3") || !strings.Contains(output, "Stopped at max_results") {
+		t.Errorf("expected output bounded at 2 matches, got:\n%s", output)
+	}
+
+	output = runGrep(t, NewGrepTool(DefaultMaxFileSize), grepArgs{Pattern: "a.c", Path: path, FixedStrings: true})
+	if !strings.HasPrefix(output, "No matches") {
+		t.Errorf("expected literal pattern to miss, got:\n%s", output)
+	}
+}
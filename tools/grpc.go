@@ -0,0 +1,449 @@
+// gRPC Tool - Reflection-based RPC calls.
+//
+// Lists services and methods via server reflection and invokes unary
+// RPCs with JSON-encoded requests, so agents can query gRPC services
+// without generated client code.
+//
+// Information Hiding:
+// - Reflection protocol and descriptor resolution hidden
+// - Dynamic message encoding hidden
+// - Connection management hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// reflectionPrefix prefixes the reflection services, hidden from listings.
+const reflectionPrefix = "grpc.reflection."
+
+// GRPCTool calls gRPC services discovered through server reflection.
+type GRPCTool struct {
+	BaseTool
+	timeoutSecs     uint64
+	allowedServices []string
+	creds           credentials.TransportCredentials
+}
+
+// NewGRPCTool creates a gRPC tool using plaintext connections.
+func NewGRPCTool(timeoutSecs uint64) *GRPCTool {
+	return &GRPCTool{
+		timeoutSecs: timeoutSecs,
+		creds:       insecure.NewCredentials(),
+	}
+}
+
+// WithAllowedServices restricts listing and calls to these fully-qualified
+// service names (e.g. "orders.v1.OrderService"). Empty allows all services.
+func (t *GRPCTool) WithAllowedServices(services []string) *GRPCTool {
+	t.allowedServices = services
+	return t
+}
+
+// WithTransportCredentials sets connection credentials (e.g. TLS).
+func (t *GRPCTool) WithTransportCredentials(creds credentials.TransportCredentials) *GRPCTool {
+	t.creds = creds
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *GRPCTool) Metadata() ToolMetadata {
+	description := "Call gRPC services using server reflection. Use action 'list' to see services and methods, 'describe' to see request/response fields, then 'call' to invoke a unary method with a JSON request."
+	if len(t.allowedServices) > 0 {
+		description += fmt.Sprintf(" Allowed services: %s.", strings.Join(t.allowedServices, ", "))
+	}
+	return ToolMetadata{
+		Name:        "grpc",
+		Description: description,
+		Parameters: []ToolParameter{
+			{Name: "target", ParamType: "string", Description: "Server address (host:port)", Required: true},
+			{Name: "action", ParamType: "string", Description: "'list', 'describe', or 'call' (default: list)", Required: false},
+			{Name: "service", ParamType: "string", Description: "Fully-qualified service name (for describe and call)", Required: false},
+			{Name: "method", ParamType: "string", Description: "Method name (for call; optional for describe)", Required: false},
+			{Name: "request", ParamType: "object", Description: "Request message as JSON (for call; default: {})", Required: false},
+		},
+	}
+}
+
+type grpcArgs struct {
+	Target  string          `json:"target"`
+	Action  string          `json:"action"`
+	Service string          `json:"service"`
+	Method  string          `json:"method"`
+	Request json.RawMessage `json:"request"`
+}
+
+// Validate validates the arguments.
+func (t *GRPCTool) Validate(args json.RawMessage) error {
+	var a grpcArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Target == "" {
+		return fmt.Errorf("target cannot be empty")
+	}
+	switch a.Action {
+	case "", "list":
+	case "describe":
+		if a.Service == "" {
+			return fmt.Errorf("service is required for describe")
+		}
+	case "call":
+		if a.Service == "" || a.Method == "" {
+			return fmt.Errorf("service and method are required for call")
+		}
+	default:
+		return fmt.Errorf("invalid action '%s': use list, describe, or call", a.Action)
+	}
+	return nil
+}
+
+// Execute runs the requested action.
+func (t *GRPCTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a grpcArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	if a.Service != "" && !t.serviceAllowed(a.Service) {
+		return DeniedResultf("access to service '%s' is not allowed", a.Service), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.timeoutSecs)*time.Second)
+	defer cancel()
+
+	conn, err := grpc.NewClient(a.Target, grpc.WithTransportCredentials(t.creds))
+	if err != nil {
+		return FailureResult(fmt.Errorf("failed to connect to %s: %w", a.Target, err)), nil
+	}
+	defer conn.Close()
+
+	resolver, err := newReflectionResolver(ctx, conn)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	defer resolver.close()
+
+	switch a.Action {
+	case "describe":
+		return t.describe(resolver, a.Service, a.Method)
+	case "call":
+		return t.call(ctx, conn, resolver, a)
+	default:
+		return t.list(resolver)
+	}
+}
+
+// list returns allowed services and their methods.
+func (t *GRPCTool) list(resolver *reflectionResolver) (ToolResult, error) {
+	services, err := resolver.listServices()
+	if err != nil {
+		return FailureResult(err), nil
+	}
+
+	var sb strings.Builder
+	count := 0
+	for _, name := range services {
+		if strings.HasPrefix(name, reflectionPrefix) || !t.serviceAllowed(name) {
+			continue
+		}
+		count++
+		sd, err := resolver.service(name)
+		if err != nil {
+			fmt.Fprintf(&sb, "%s (methods unavailable: %v)\n", name, err)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s\n", name)
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			fmt.Fprintf(&sb, "  %s\n", methodSignature(methods.Get(i)))
+		}
+	}
+
+	if count == 0 {
+		return SuccessResult("No accessible services found"), nil
+	}
+	return SuccessResult(fmt.Sprintf("Found %d services:\n%s", count, sb.String())), nil
+}
+
+// describe returns method signatures with request and response fields.
+func (t *GRPCTool) describe(resolver *reflectionResolver, service, method string) (ToolResult, error) {
+	sd, err := resolver.service(service)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+
+	var sb strings.Builder
+	methods := sd.Methods()
+	found := false
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		if method != "" && string(md.Name()) != method {
+			continue
+		}
+		found = true
+		fmt.Fprintf(&sb, "%s\n", methodSignature(md))
+		fmt.Fprintf(&sb, "  request %s\n", describeMessage(md.Input()))
+		fmt.Fprintf(&sb, "  response %s\n", describeMessage(md.Output()))
+	}
+	if !found {
+		return FailureResultf("method '%s' not found in %s", method, service), nil
+	}
+	return SuccessResult(sb.String()), nil
+}
+
+// call invokes a unary method with a JSON request.
+func (t *GRPCTool) call(ctx context.Context, conn *grpc.ClientConn, resolver *reflectionResolver, a grpcArgs) (ToolResult, error) {
+	sd, err := resolver.service(a.Service)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	md := sd.Methods().ByName(protoreflect.Name(a.Method))
+	if md == nil {
+		return FailureResultf("method '%s' not found in %s", a.Method, a.Service), nil
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return FailureResultf("method '%s' is streaming; only unary RPCs are supported", a.Method), nil
+	}
+
+	// Models sometimes send the request as a JSON-encoded string
+	body := []byte(a.Request)
+	var encoded string
+	if err := json.Unmarshal(a.Request, &encoded); err == nil {
+		body = []byte(encoded)
+	}
+
+	request := dynamicpb.NewMessage(md.Input())
+	if len(body) > 0 && string(body) != "null" {
+		if err := protojson.Unmarshal(body, request); err != nil {
+			return FailureResultf("invalid request for %s: %v", md.Input().FullName(), err), nil
+		}
+	}
+	response := dynamicpb.NewMessage(md.Output())
+
+	fullMethod := fmt.Sprintf("/%s/%s", a.Service, a.Method)
+	if err := conn.Invoke(ctx, fullMethod, request, response); err != nil {
+		st := status.Convert(err)
+		return FailureResultf("rpc %s failed: %s: %s", fullMethod, st.Code(), st.Message()), nil
+	}
+
+	output, err := protojson.MarshalOptions{Multiline: true}.Marshal(response)
+	if err != nil {
+		return FailureResult(fmt.Errorf("failed to encode response: %w", err)), nil
+	}
+	return SuccessResult(string(output)), nil
+}
+
+func (t *GRPCTool) serviceAllowed(service string) bool {
+	if len(t.allowedServices) == 0 {
+		return true
+	}
+	for _, allowed := range t.allowedServices {
+		if service == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// methodSignature formats a method like a proto rpc declaration.
+func methodSignature(md protoreflect.MethodDescriptor) string {
+	in, out := string(md.Input().FullName()), string(md.Output().FullName())
+	if md.IsStreamingClient() {
+		in = "stream " + in
+	}
+	if md.IsStreamingServer() {
+		out = "stream " + out
+	}
+	return fmt.Sprintf("rpc %s(%s) returns (%s)", md.Name(), in, out)
+}
+
+// describeMessage lists a message's top-level fields and their types.
+func describeMessage(msg protoreflect.MessageDescriptor) string {
+	fields := msg.Fields()
+	parts := make([]string, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		typ := fd.Kind().String()
+		switch fd.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			typ = string(fd.Message().FullName())
+		case protoreflect.EnumKind:
+			typ = string(fd.Enum().FullName())
+		}
+		if fd.IsList() {
+			typ = "repeated " + typ
+		}
+		if fd.IsMap() {
+			typ = fmt.Sprintf("map<%s, %s>", fd.MapKey().Kind(), fd.MapValue().Kind())
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", fd.JSONName(), typ))
+	}
+	return fmt.Sprintf("%s { %s }", msg.FullName(), strings.Join(parts, ", "))
+}
+
+// reflectionResolver fetches and links file descriptors over one reflection stream.
+type reflectionResolver struct {
+	stream reflectpb.ServerReflection_ServerReflectionInfoClient
+	protos map[string]*descriptorpb.FileDescriptorProto // By file name
+}
+
+func newReflectionResolver(ctx context.Context, conn *grpc.ClientConn) (*reflectionResolver, error) {
+	stream, err := reflectpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection unavailable: %w", err)
+	}
+	return &reflectionResolver{
+		stream: stream,
+		protos: make(map[string]*descriptorpb.FileDescriptorProto),
+	}, nil
+}
+
+func (r *reflectionResolver) close() {
+	_ = r.stream.CloseSend()
+}
+
+func (r *reflectionResolver) send(req *reflectpb.ServerReflectionRequest) (*reflectpb.ServerReflectionResponse, error) {
+	if err := r.stream.Send(req); err != nil {
+		return nil, fmt.Errorf("reflection request failed: %w", err)
+	}
+	resp, err := r.stream.Recv()
+	if err == io.EOF {
+		return nil, fmt.Errorf("reflection stream closed by server")
+	}
+	if err != nil {
+		st := status.Convert(err)
+		return nil, fmt.Errorf("reflection request failed: %s: %s", st.Code(), st.Message())
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("reflection error: %s", errResp.GetErrorMessage())
+	}
+	return resp, nil
+}
+
+// listServices returns all service names the server exposes, sorted.
+func (r *reflectionResolver) listServices() ([]string, error) {
+	resp, err := r.send(&reflectpb.ServerReflectionRequest{
+		MessageRequest: &reflectpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.GetName())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// service resolves a service descriptor with all its dependencies.
+func (r *reflectionResolver) service(name string) (protoreflect.ServiceDescriptor, error) {
+	resp, err := r.send(&reflectpb.ServerReflectionRequest{
+		MessageRequest: &reflectpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service '%s' not found: %w", name, err)
+	}
+	if err := r.addFiles(resp.GetFileDescriptorResponse().GetFileDescriptorProto()); err != nil {
+		return nil, err
+	}
+	if err := r.fetchMissingDependencies(); err != nil {
+		return nil, err
+	}
+
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: r.fileList()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build descriptors: %w", err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("service '%s' not found: %w", name, err)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a service", name)
+	}
+	return sd, nil
+}
+
+func (r *reflectionResolver) addFiles(raw [][]byte) error {
+	for _, b := range raw {
+		fd := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fd); err != nil {
+			return fmt.Errorf("invalid file descriptor: %w", err)
+		}
+		r.protos[fd.GetName()] = fd
+	}
+	return nil
+}
+
+// fetchMissingDependencies requests imports the server did not send. Well-known
+// types the server does not expose fall back to the local registry.
+func (r *reflectionResolver) fetchMissingDependencies() error {
+	for {
+		var missing []string
+		for _, fd := range r.protos {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := r.protos[dep]; !ok {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		for _, dep := range missing {
+			if _, ok := r.protos[dep]; ok {
+				continue
+			}
+			resp, err := r.send(&reflectpb.ServerReflectionRequest{
+				MessageRequest: &reflectpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err == nil {
+				if err := r.addFiles(resp.GetFileDescriptorResponse().GetFileDescriptorProto()); err != nil {
+					return err
+				}
+			}
+			if _, ok := r.protos[dep]; ok {
+				continue
+			}
+			local, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err != nil {
+				return fmt.Errorf("dependency '%s' not available from server", dep)
+			}
+			r.protos[dep] = protodesc.ToFileDescriptorProto(local)
+		}
+	}
+}
+
+func (r *reflectionResolver) fileList() []*descriptorpb.FileDescriptorProto {
+	files := make([]*descriptorpb.FileDescriptorProto, 0, len(r.protos))
+	for _, fd := range r.protos {
+		files = append(files, fd)
+	}
+	return files
+}
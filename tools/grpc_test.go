@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const healthService = "grpc.health.v1.Health"
+
+func startReflectionServer(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func runGRPC(t *testing.T, tool *GRPCTool, args grpcArgs) ToolResult {
+	t.Helper()
+	raw, _ := json.Marshal(args)
+	result, err := tool.Execute(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestGRPCToolListAndDescribe(t *testing.T) {
+	target := startReflectionServer(t)
+	tool := NewGRPCTool(5)
+
+	result := runGRPC(t, tool, grpcArgs{Target: target, Action: "list"})
+	if !result.Success() || !strings.Contains(result.Output, "rpc Check(grpc.health.v1.HealthCheckRequest)") {
+		t.Fatalf("expected health service listed, got %+v", result)
+	}
+	if strings.Contains(result.Output, "grpc.reflection") {
+		t.Errorf("expected reflection service hidden:\n%s", result.Output)
+	}
+
+	result = runGRPC(t, tool, grpcArgs{Target: target, Action: "describe", Service: healthService, Method: "Check"})
+	if !result.Success() || !strings.Contains(result.Output, "service: string") {
+		t.Errorf("expected request fields, got %+v", result)
+	}
+}
+
+func TestGRPCToolCall(t *testing.T) {
+	target := startReflectionServer(t)
+	tool := NewGRPCTool(5)
+
+	result := runGRPC(t, tool, grpcArgs{
+		Target:  target,
+		Action:  "call",
+		Service: healthService,
+		Method:  "Check",
+		Request: json.RawMessage(`{"service": "orders"}`),
+	})
+	if !result.Success() || !strings.Contains(result.Output, "NOT_SERVING") {
+		t.Errorf("expected NOT_SERVING status, got %+v", result)
+	}
+
+	result = runGRPC(t, tool, grpcArgs{Target: target, Action: "call", Service: healthService, Method: "Watch"})
+	if result.Success() || !strings.Contains(result.Error.Error(), "only unary") {
+		t.Errorf("expected streaming method to be rejected, got %+v", result)
+	}
+
+	result = runGRPC(t, tool, grpcArgs{
+		Target:  target,
+		Action:  "call",
+		Service: healthService,
+		Method:  "Check",
+		Request: json.RawMessage(`{"service": "unknown"}`),
+	})
+	if result.Success() || !strings.Contains(result.Error.Error(), "NotFound") {
+		t.Errorf("expected NotFound status, got %+v", result)
+	}
+}
+
+func TestGRPCToolAllowlist(t *testing.T) {
+	target := startReflectionServer(t)
+	tool := NewGRPCTool(5).WithAllowedServices([]string{"orders.v1.OrderService"})
+
+	result := runGRPC(t, tool, grpcArgs{Target: target, Action: "call", Service: healthService, Method: "Check"})
+	if result.Success() || !strings.Contains(result.Error.Error(), "not allowed") {
+		t.Errorf("expected disallowed service to be rejected, got %+v", result)
+	}
+
+	result = runGRPC(t, tool, grpcArgs{Target: target})
+	if !strings.Contains(result.Output, "No accessible services") {
+		t.Errorf("expected disallowed services hidden from listing, got %+v", result)
+	}
+}
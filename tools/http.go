@@ -4,26 +4,161 @@
 // - HTTP client implementation details hidden
 // - Request/response handling abstracted
 // - Error handling and retries hidden
+// - Auth secrets resolved from the environment, never shown to the LLM
+// - Large bodies spilled to ResultStore with a parsed summary
+// - Egress policy enforcement (per request, redirect and connection)
 
 package tools
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+const (
+	// DefaultHTTPMaxRedirects is the default redirect limit.
+	DefaultHTTPMaxRedirects = 10
+	// DefaultHTTPSpillBytes is the body size above which responses are stored.
+	DefaultHTTPSpillBytes = 4 * 1024
+	// httpRetryBackoff is the base delay between retries (doubled each attempt).
+	httpRetryBackoff = 500 * time.Millisecond
+)
+
+// Auth profile types.
+const (
+	HTTPAuthBearer = "bearer"
+	HTTPAuthBasic  = "basic"
+	HTTPAuthHeader = "header"
 )
 
+// HTTPAuthProfile is a named set of credentials for http_request.
+// Secrets are read from environment variables at request time, so the
+// profile file holds no secrets and the LLM only ever sees the profile name.
+type HTTPAuthProfile struct {
+	Type        string   `json:"type"`                   // bearer, basic, or header
+	TokenEnv    string   `json:"token_env,omitempty"`    // Bearer token or header value
+	UsernameEnv string   `json:"username_env,omitempty"` // Basic auth username
+	PasswordEnv string   `json:"password_env,omitempty"` // Basic auth password
+	Header      string   `json:"header,omitempty"`       // Header name for type "header" (e.g. X-API-Key)
+	Domains     []string `json:"domains,omitempty"`      // Restrict the profile to these domains
+}
+
+// HTTPProfilesConfig is the auth profile file format:
+//
+//	{
+//	  "httpProfiles": {
+//	    "github": {"type": "bearer", "token_env": "GITHUB_TOKEN", "domains": ["api.github.com"]}
+//	  }
+//	}
+type HTTPProfilesConfig struct {
+	Profiles map[string]HTTPAuthProfile `json:"httpProfiles"`
+}
+
+// LoadHTTPProfiles loads auth profiles from a JSON file.
+func LoadHTTPProfiles(path string) (map[string]HTTPAuthProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP profiles file: %w", err)
+	}
+
+	var config HTTPProfilesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse HTTP profiles file: %w", err)
+	}
+
+	for name, profile := range config.Profiles {
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("HTTP profile '%s': %w", name, err)
+		}
+	}
+	return config.Profiles, nil
+}
+
+func (p HTTPAuthProfile) validate() error {
+	switch p.Type {
+	case HTTPAuthBearer:
+		if p.TokenEnv == "" {
+			return fmt.Errorf("bearer profile requires token_env")
+		}
+	case HTTPAuthBasic:
+		if p.UsernameEnv == "" || p.PasswordEnv == "" {
+			return fmt.Errorf("basic profile requires username_env and password_env")
+		}
+	case HTTPAuthHeader:
+		if p.Header == "" || p.TokenEnv == "" {
+			return fmt.Errorf("header profile requires header and token_env")
+		}
+	default:
+		return fmt.Errorf("unknown type '%s': use bearer, basic, or header", p.Type)
+	}
+	return nil
+}
+
+// apply adds credentials to the request. Errors name the variable, never its value.
+func (p HTTPAuthProfile) apply(req *http.Request) error {
+	lookup := func(env string) (string, error) {
+		val := os.Getenv(env)
+		if val == "" {
+			return "", fmt.Errorf("%s environment variable not set", env)
+		}
+		return val, nil
+	}
+
+	switch p.Type {
+	case HTTPAuthBearer:
+		token, err := lookup(p.TokenEnv)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case HTTPAuthBasic:
+		user, err := lookup(p.UsernameEnv)
+		if err != nil {
+			return err
+		}
+		pass, err := lookup(p.PasswordEnv)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(user, pass)
+	case HTTPAuthHeader:
+		val, err := lookup(p.TokenEnv)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(p.Header, val)
+	}
+	return nil
+}
+
 // HTTPTool makes HTTP requests.
 type HTTPTool struct {
 	BaseTool
 	client         *http.Client
 	timeoutSecs    uint64
 	allowedDomains []string
+	profiles       map[string]HTTPAuthProfile
+	egress         *EgressPolicy
+	maxRetries     int
+	maxRedirects   int
+	spillBytes     int
+	store          *storage.ResultStore
+	sessionID      string
+	fileContext    *StoredFileContext
+	spills         atomic.Int64
 }
 
 // NewHTTPTool creates a new HTTP tool with the given timeout.
@@ -32,7 +167,9 @@ func NewHTTPTool(timeoutSecs uint64) *HTTPTool {
 		client: &http.Client{
 			Timeout: time.Duration(timeoutSecs) * time.Second,
 		},
-		timeoutSecs: timeoutSecs,
+		timeoutSecs:  timeoutSecs,
+		maxRedirects: DefaultHTTPMaxRedirects,
+		spillBytes:   DefaultHTTPSpillBytes,
 	}
 }
 
@@ -42,23 +179,91 @@ func (t *HTTPTool) WithAllowedDomains(domains []string) *HTTPTool {
 	return t
 }
 
+// WithEgressPolicy enforces policy on every request, redirect and
+// connection. Nil removes the policy.
+func (t *HTTPTool) WithEgressPolicy(policy *EgressPolicy) *HTTPTool {
+	t.egress = policy
+	t.client.Transport = nil
+	if policy != nil && policy.BlockPrivateIPs {
+		t.client.Transport = policy.Transport()
+	}
+	return t
+}
+
+// WithAuthProfiles sets the named auth profiles requests may use.
+func (t *HTTPTool) WithAuthProfiles(profiles map[string]HTTPAuthProfile) *HTTPTool {
+	t.profiles = profiles
+	return t
+}
+
+// WithRetries retries network errors, 429, and 5xx responses up to n times
+// with exponential backoff. Only GET requests are retried; a POST may have
+// taken effect before it failed, so it is retried only when the request
+// sets retry.
+func (t *HTTPTool) WithRetries(n int) *HTTPTool {
+	t.maxRetries = n
+	return t
+}
+
+// WithMaxRedirects sets how many redirects are followed. Zero disables redirects.
+func (t *HTTPTool) WithMaxRedirects(n int) *HTTPTool {
+	t.maxRedirects = n
+	return t
+}
+
+// WithResultStore stores response bodies larger than spillBytes and returns
+// a parsed summary instead. Non-positive spillBytes uses DefaultHTTPSpillBytes.
+func (t *HTTPTool) WithResultStore(store *storage.ResultStore, sessionID string, fileContext *StoredFileContext, spillBytes int) *HTTPTool {
+	t.store = store
+	t.sessionID = sessionID
+	t.fileContext = fileContext
+	if spillBytes > 0 {
+		t.spillBytes = spillBytes
+	}
+	return t
+}
+
 // Metadata returns the tool metadata.
 func (t *HTTPTool) Metadata() ToolMetadata {
+	params := []ToolParameter{
+		{Name: "url", ParamType: "string", Description: "The URL to request", Required: true},
+		{Name: "method", ParamType: "string", Description: "HTTP method (GET or POST)", Required: false},
+		{Name: "body", ParamType: "string", Description: "Request body for POST requests", Required: false},
+		{Name: "follow_redirects", ParamType: "boolean", Description: "Follow redirects (default: true)", Required: false},
+		{Name: "timeout_secs", ParamType: "integer", Description: fmt.Sprintf("Request timeout in seconds (max: %d)", t.timeoutSecs), Required: false},
+	}
+	if t.maxRetries > 0 {
+		params = append(params, ToolParameter{
+			Name:        "retry",
+			ParamType:   "boolean",
+			Description: "Retry a POST on network errors, 429 and 5xx; only set it when repeating the request is safe (GET is always retried)",
+			Required:    false,
+		})
+	}
+	if len(t.profiles) > 0 {
+		params = append(params, ToolParameter{
+			Name:        "auth_profile",
+			ParamType:   "string",
+			Description: fmt.Sprintf("Named credentials to authenticate with: %s", strings.Join(t.profileNames(), ", ")),
+			Required:    false,
+		})
+	}
+
 	return ToolMetadata{
 		Name:        "http_request",
 		Description: "Make HTTP GET or POST requests to fetch data from URLs",
-		Parameters: []ToolParameter{
-			{Name: "url", ParamType: "string", Description: "The URL to request", Required: true},
-			{Name: "method", ParamType: "string", Description: "HTTP method (GET or POST)", Required: false},
-			{Name: "body", ParamType: "string", Description: "Request body for POST requests", Required: false},
-		},
+		Parameters:  params,
 	}
 }
 
 type httpArgs struct {
-	URL    string `json:"url"`
-	Method string `json:"method"`
-	Body   string `json:"body"`
+	URL             string `json:"url"`
+	Method          string `json:"method"`
+	Body            string `json:"body"`
+	AuthProfile     string `json:"auth_profile"`
+	FollowRedirects *bool  `json:"follow_redirects"`
+	TimeoutSecs     uint64 `json:"timeout_secs"`
+	Retry           bool   `json:"retry"`
 }
 
 // Validate validates the arguments.
@@ -70,6 +275,11 @@ func (t *HTTPTool) Validate(args json.RawMessage) error {
 	if a.URL == "" {
 		return fmt.Errorf("URL cannot be empty")
 	}
+	if a.AuthProfile != "" {
+		if _, ok := t.profiles[a.AuthProfile]; !ok {
+			return fmt.Errorf("unknown auth_profile '%s'", a.AuthProfile)
+		}
+	}
 	return nil
 }
 
@@ -85,7 +295,10 @@ func (t *HTTPTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 	}
 
 	if !t.isDomainAllowed(a.URL) {
-		return FailureResultf("access to domain in '%s' is not allowed", a.URL), nil
+		return DeniedResultf("access to domain in '%s' is not allowed", a.URL), nil
+	}
+	if err := t.egress.CheckURL(a.URL); err != nil {
+		return FailureResult(err), nil
 	}
 
 	method := strings.ToUpper(a.Method)
@@ -97,41 +310,246 @@ func (t *HTTPTool) Execute(ctx context.Context, args json.RawMessage) (ToolResul
 		return FailureResultf("only GET and POST methods are supported"), nil
 	}
 
-	var req *http.Request
+	var profile *HTTPAuthProfile
+	if a.AuthProfile != "" {
+		p, ok := t.profiles[a.AuthProfile]
+		if !ok {
+			return FailureResultf("unknown auth_profile '%s'", a.AuthProfile), nil
+		}
+		if len(p.Domains) > 0 && !domainMatches(a.URL, p.Domains) {
+			return DeniedResultf("auth_profile '%s' is not allowed for '%s'", a.AuthProfile, a.URL), nil
+		}
+		profile = &p
+	}
+
+	timeoutSecs := t.timeoutSecs
+	if a.TimeoutSecs > 0 && a.TimeoutSecs < timeoutSecs {
+		timeoutSecs = a.TimeoutSecs
+	}
+	client := t.requestClient(timeoutSecs, a.FollowRedirects == nil || *a.FollowRedirects, profile)
+
+	// Repeating a POST may repeat its effect, so it needs the caller's consent
+	maxRetries := t.maxRetries
+	if method != "GET" && !a.Retry {
+		maxRetries = 0
+	}
+
+	var resp *http.Response
+	var body []byte
+	var transient bool
 	var err error
+	for attempt := 0; ; attempt++ {
+		resp, body, transient, err = t.do(ctx, client, method, a, profile)
+		if attempt >= maxRetries || !transient || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(httpRetryBackoff << attempt):
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded || isTimeout(err) {
+			return FailureResultf("request timed out after %d seconds", timeoutSecs), nil
+		}
+		return FailureResult(err), nil
+	}
 
+	output := t.formatBody(ctx, a.URL, resp, body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return SuccessResult(fmt.Sprintf("Status: %s\n\n%s", resp.Status, output)), nil
+	}
+
+	// Redirect not followed (disabled or limit reached)
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		return SuccessResult(fmt.Sprintf("Status: %s\nLocation: %s\n\n%s", resp.Status, resp.Header.Get("Location"), output)), nil
+	}
+
+	return FailureResultf("HTTP error: %s\n\n%s", resp.Status, output), nil
+}
+
+// do sends one request attempt and reads the whole body.
+// transient is true for failures worth retrying: network errors, 429, and 5xx.
+func (t *HTTPTool) do(ctx context.Context, client *http.Client, method string, a httpArgs, profile *HTTPAuthProfile) (resp *http.Response, body []byte, transient bool, err error) {
+	var reqBody io.Reader
 	if method == "POST" {
-		req, err = http.NewRequestWithContext(ctx, method, a.URL, strings.NewReader(a.Body))
-	} else {
-		req, err = http.NewRequestWithContext(ctx, method, a.URL, nil)
+		reqBody = strings.NewReader(a.Body)
 	}
 
+	req, err := http.NewRequestWithContext(ctx, method, a.URL, reqBody)
 	if err != nil {
-		return FailureResult(fmt.Errorf("failed to create request: %w", err)), nil
+		return nil, nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if profile != nil {
+		if err := profile.apply(req); err != nil {
+			return nil, nil, false, fmt.Errorf("auth_profile '%s': %w", a.AuthProfile, err)
+		}
 	}
 
-	resp, err := t.client.Do(req)
+	resp, err = client.Do(req)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return FailureResultf("request timed out after %d seconds", t.timeoutSecs), nil
+		// Policy refusals (redirects, dials) are final and reported as is
+		var egressErr *EgressError
+		if errors.As(err, &egressErr) {
+			return nil, nil, false, egressErr
 		}
-		return FailureResult(fmt.Errorf("request failed: %w", err)), nil
+		return nil, nil, true, fmt.Errorf("request failed: %w", err)
 	}
 	if resp == nil {
-		return FailureResult(fmt.Errorf("nil response received")), nil
+		return nil, nil, false, fmt.Errorf("nil response received")
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	if err := t.egress.CheckResponseSize(a.URL, resp.ContentLength); err != nil {
+		return resp, nil, false, err
+	}
+	reader := io.Reader(resp.Body)
+	if t.egress != nil && t.egress.MaxResponseBytes > 0 {
+		reader = io.LimitReader(resp.Body, t.egress.MaxResponseBytes+1)
+	}
+	body, err = io.ReadAll(reader)
 	if err != nil {
-		return FailureResult(fmt.Errorf("failed to read response body: %w", err)), nil
+		return resp, nil, true, fmt.Errorf("failed to read response body: %w", err)
 	}
+	if err := t.egress.CheckResponseSize(a.URL, int64(len(body))); err != nil {
+		return resp, nil, false, err
+	}
+	transient = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp, body, transient, nil
+}
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		return SuccessResult(fmt.Sprintf("Status: %s\n\n%s", resp.Status, string(body))), nil
+// requestClient returns a client with per-request timeout and redirect policy.
+// Credentials are dropped on redirects that leave the profile's domains.
+func (t *HTTPTool) requestClient(timeoutSecs uint64, followRedirects bool, profile *HTTPAuthProfile) *http.Client {
+	maxRedirects := t.maxRedirects
+	if !followRedirects {
+		maxRedirects = 0
 	}
 
-	return FailureResultf("HTTP error: %s\n\n%s", resp.Status, string(body)), nil
+	return &http.Client{
+		Transport: t.client.Transport,
+		Timeout:   time.Duration(timeoutSecs) * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return http.ErrUseLastResponse
+			}
+			if !t.isDomainAllowed(req.URL.String()) {
+				return fmt.Errorf("redirect to '%s' is not allowed", req.URL.Host)
+			}
+			if err := t.egress.CheckURL(req.URL.String()); err != nil {
+				return err
+			}
+			if profile != nil && req.URL.Host != via[0].URL.Host &&
+				(len(profile.Domains) == 0 || !domainMatches(req.URL.String(), profile.Domains)) {
+				req.Header.Del("Authorization")
+				if profile.Header != "" {
+					req.Header.Del(profile.Header)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// isTimeout reports whether a request failed by exceeding the client timeout.
+func isTimeout(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && urlErr.Timeout()
+}
+
+// formatBody returns the body, or a stored reference with a parsed summary
+// when the body is large and a ResultStore is configured.
+func (t *HTTPTool) formatBody(ctx context.Context, rawURL string, resp *http.Response, body []byte) string {
+	if t.store == nil || len(body) <= t.spillBytes {
+		return string(body)
+	}
+
+	n := t.spills.Add(1)
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+	key := storage.ResultKey{
+		SessionID: t.sessionID,
+		Key:       fmt.Sprintf("http/%s/%d", host, n),
+	}
+
+	meta, err := t.store.Store(ctx, key, string(body), storage.DefaultStoreOptions())
+	if err != nil {
+		return string(body)
+	}
+	if t.fileContext != nil {
+		t.fileContext.Add(key.Key)
+	}
+
+	return fmt.Sprintf("[Response stored - %d bytes, %d lines]\nKey: %s (use get_lines/search_stored to access)\nContent-Type: %s\n%s",
+		meta.ByteSize, meta.LineCount, key.Key, resp.Header.Get("Content-Type"),
+		summarizeBody(resp.Header.Get("Content-Type"), body, meta.Summary))
+}
+
+var (
+	htmlTitleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlLinkRe  = regexp.MustCompile(`(?i)<a\s[^>]*href=`)
+	htmlTagRe   = regexp.MustCompile(`(?s)<script.*?</script>|<style.*?</style>|<[^>]+>`)
+)
+
+// summarizeBody describes a JSON or HTML body's structure; other content
+// gets the stored preview.
+func summarizeBody(contentType string, body []byte, preview string) string {
+	var v interface{}
+	if strings.Contains(contentType, "json") || json.Valid(body) {
+		if err := json.Unmarshal(body, &v); err == nil {
+			switch val := v.(type) {
+			case map[string]interface{}:
+				keys := make([]string, 0, len(val))
+				for k := range val {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				return fmt.Sprintf("Summary: JSON object with %d keys: %s", len(keys), strings.Join(keys, ", "))
+			case []interface{}:
+				summary := fmt.Sprintf("Summary: JSON array with %d items", len(val))
+				if len(val) > 0 {
+					if first, ok := val[0].(map[string]interface{}); ok {
+						keys := make([]string, 0, len(first))
+						for k := range first {
+							keys = append(keys, k)
+						}
+						sort.Strings(keys)
+						summary += fmt.Sprintf("; item keys: %s", strings.Join(keys, ", "))
+					}
+				}
+				return summary
+			}
+		}
+	}
+
+	if strings.Contains(contentType, "html") {
+		title := ""
+		if m := htmlTitleRe.FindSubmatch(body); m != nil {
+			title = strings.TrimSpace(string(m[1]))
+		}
+		text := []rune(strings.Join(strings.Fields(htmlTagRe.ReplaceAllString(string(body), " ")), " "))
+		if len(text) > 300 {
+			text = append(text[:300], []rune("...")...)
+		}
+		return fmt.Sprintf("Summary: HTML page %q with %d links\nText: %s",
+			title, len(htmlLinkRe.FindAllIndex(body, -1)), string(text))
+	}
+
+	return "Preview:\n" + preview
+}
+
+// profileNames returns the configured profile names, sorted.
+func (t *HTTPTool) profileNames() []string {
+	names := make([]string, 0, len(t.profiles))
+	for name := range t.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // isDomainAllowed checks if the URL's domain is in the allowlist.
@@ -140,14 +558,18 @@ func (t *HTTPTool) isDomainAllowed(urlStr string) bool {
 	if len(t.allowedDomains) == 0 {
 		return true
 	}
+	return domainMatches(urlStr, t.allowedDomains)
+}
 
+// domainMatches reports whether the URL's host equals or is a subdomain of one of domains.
+func domainMatches(urlStr string, domains []string) bool {
 	u, err := url.Parse(urlStr)
 	if err != nil {
 		return false
 	}
 
 	host := u.Hostname()
-	for _, domain := range t.allowedDomains {
+	for _, domain := range domains {
 		// Exact match or subdomain match
 		if host == domain || strings.HasSuffix(host, "."+domain) {
 			return true
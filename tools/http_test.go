@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func runHTTP(t *testing.T, tool *HTTPTool, args httpArgs) ToolResult {
+	t.Helper()
+	raw, _ := json.Marshal(args)
+	result, err := tool.Execute(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestHTTPToolAuthProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_HTTP_TOKEN", "s3cret")
+	tool := NewHTTPTool(5).WithAuthProfiles(map[string]HTTPAuthProfile{
+		"api":   {Type: HTTPAuthBearer, TokenEnv: "TEST_HTTP_TOKEN"},
+		"other": {Type: HTTPAuthBearer, TokenEnv: "TEST_HTTP_TOKEN", Domains: []string{"example.com"}},
+	})
+
+	if desc := tool.Metadata().Parameters; !strings.Contains(desc[len(desc)-1].Description, "api, other") {
+		t.Errorf("expected profile names in auth_profile description, got %q", desc[len(desc)-1].Description)
+	}
+
+	result := runHTTP(t, tool, httpArgs{URL: server.URL, AuthProfile: "api"})
+	if !result.Success() || strings.Contains(result.Output, "s3cret") {
+		t.Errorf("expected authenticated success without leaking token, got %+v", result)
+	}
+
+	result = runHTTP(t, tool, httpArgs{URL: server.URL, AuthProfile: "other"})
+	if result.Success() || !strings.Contains(result.Error.Error(), "not allowed") {
+		t.Errorf("expected domain-restricted profile to be rejected, got %+v", result)
+	}
+}
+
+func TestHTTPToolRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("recovered"))
+	}))
+	defer server.Close()
+
+	result := runHTTP(t, NewHTTPTool(5).WithRetries(2), httpArgs{URL: server.URL})
+	if !result.Success() || !strings.Contains(result.Output, "recovered") {
+		t.Errorf("expected success after retry, got %+v", result)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls.Load())
+	}
+}
+
+func TestHTTPToolRetriesPostOnlyWhenAsked(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	tool := NewHTTPTool(5).WithRetries(1)
+
+	result := runHTTP(t, tool, httpArgs{URL: server.URL, Method: "POST", Body: "charge"})
+	if result.Success() || calls.Load() != 1 {
+		t.Errorf("a POST should not be retried on 503: %d attempts, %+v", calls.Load(), result)
+	}
+
+	calls.Store(0)
+	runHTTP(t, tool, httpArgs{URL: server.URL, Method: "POST", Body: "charge", Retry: true})
+	if calls.Load() != 2 {
+		t.Errorf("expected a POST with retry set to be retried, got %d attempts", calls.Load())
+	}
+}
+
+func TestHTTPToolRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("new page"))
+	}))
+	defer server.Close()
+
+	noFollow := false
+	result := runHTTP(t, NewHTTPTool(5), httpArgs{URL: server.URL + "/old", FollowRedirects: &noFollow})
+	if !result.Success() || !strings.Contains(result.Output, "Location: /new") {
+		t.Errorf("expected unfollowed redirect, got %+v", result)
+	}
+
+	result = runHTTP(t, NewHTTPTool(5), httpArgs{URL: server.URL + "/old"})
+	if !strings.Contains(result.Output, "new page") {
+		t.Errorf("expected redirect to be followed, got %+v", result)
+	}
+}
+
+func TestHTTPToolSpillsLargeJSON(t *testing.T) {
+	items := make([]map[string]int, 500)
+	for i := range items {
+		items[i] = map[string]int{"id": i, "value": i * 2}
+	}
+	payload, _ := json.Marshal(items)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(payload)
+	}))
+	defer server.Close()
+
+	store := storage.NewInMemoryResultStore()
+	tool := NewHTTPTool(5).WithResultStore(store, "session", nil, 1024)
+
+	result := runHTTP(t, tool, httpArgs{URL: server.URL})
+	if !result.Success() {
+		t.Fatalf("unexpected failure: %+v", result)
+	}
+	if !strings.Contains(result.Output, "JSON array with 500 items; item keys: id, value") {
+		t.Errorf("expected parsed JSON summary, got:\n%s", result.Output)
+	}
+	if len(result.Output) > 1024 {
+		t.Errorf("expected compact output, got %d bytes", len(result.Output))
+	}
+}
+
+func TestLoadHTTPProfiles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	_ = os.WriteFile(good, []byte(`{"httpProfiles": {"gh": {"type": "bearer", "token_env": "GITHUB_TOKEN"}}}`), 0644)
+	profiles, err := LoadHTTPProfiles(good)
+	if err != nil || profiles["gh"].TokenEnv != "GITHUB_TOKEN" {
+		t.Errorf("unexpected result: %+v, err %v", profiles, err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	_ = os.WriteFile(bad, []byte(`{"httpProfiles": {"x": {"type": "header", "token_env": "KEY"}}}`), 0644)
+	if _, err := LoadHTTPProfiles(bad); err == nil {
+		t.Error("expected header profile without header name to fail")
+	}
+}
@@ -0,0 +1,194 @@
+// Incremental Indexing.
+//
+// Keeps a ResultStore session in sync with files on disk. Each file's
+// content hash is compared with the stored hash, so only new or changed
+// files are re-stored and files gone from disk are removed. Repeated
+// ingestion of an unchanged repo does no store writes.
+//
+// Information Hiding:
+// - File walking, gitignore and binary filtering hidden
+// - Hash comparison against stored metadata hidden
+// - Batching of store writes hidden
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// binarySniffBytes is how much of a file is checked for NUL bytes.
+const binarySniffBytes = 8000
+
+// Changed files are written to the store in batches of up to this many
+// files or bytes.
+const (
+	indexBatchFiles = 256
+	indexBatchBytes = 16 << 20
+)
+
+// IndexSummary reports what an indexing pass changed.
+type IndexSummary struct {
+	Added     []string // Keys stored for the first time
+	Updated   []string // Keys whose content changed
+	Removed   []string // Keys whose files no longer exist
+	Unchanged int
+	Skipped   int // Binary, oversized, or unreadable files
+	Bytes     int // Bytes of content written to the store
+}
+
+// Files returns the number of files now indexed.
+func (s IndexSummary) Files() int {
+	return len(s.Added) + len(s.Updated) + s.Unchanged
+}
+
+// String renders a one-line summary.
+func (s IndexSummary) String() string {
+	return fmt.Sprintf("%d files: %d added, %d updated, %d removed, %d unchanged, %d skipped",
+		s.Files(), len(s.Added), len(s.Updated), len(s.Removed), s.Unchanged, s.Skipped)
+}
+
+// IndexPaths syncs sessionID with the files under paths. Directories are
+// walked recursively, honoring .gitignore; binary files and files over
+// maxFileSize are skipped. Keys are slash-separated file paths.
+// The session should be dedicated to this index: any key not found
+// during the walk is removed.
+func IndexPaths(ctx context.Context, store storage.ResultStoreInterface, sessionID string, paths []string, maxFileSize int64) (IndexSummary, error) {
+	var summary IndexSummary
+	if len(paths) == 0 {
+		return summary, fmt.Errorf("no paths to index")
+	}
+
+	seen := make(map[string]bool)
+	var batch []storage.StoreRequest
+	var batchBytes int
+	isNew := make(map[string]bool)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := store.StoreBatch(ctx, batch); err != nil {
+			return fmt.Errorf("failed to store %d files: %w", len(batch), err)
+		}
+		for _, req := range batch {
+			if isNew[req.Key.Key] {
+				summary.Added = append(summary.Added, req.Key.Key)
+			} else {
+				summary.Updated = append(summary.Updated, req.Key.Key)
+			}
+			summary.Bytes += len(req.Content)
+		}
+		batch, batchBytes = batch[:0], 0
+		return nil
+	}
+	index := func(path string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		content, ok := readTextFile(path, maxFileSize)
+		if !ok {
+			summary.Skipped++
+			return nil
+		}
+
+		key := storage.ResultKey{SessionID: sessionID, Key: filepath.ToSlash(filepath.Clean(path))}
+		seen[key.Key] = true
+
+		existing, err := store.GetMetadata(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to read metadata for %s: %w", key.Key, err)
+		}
+		if existing != nil && existing.ContentHash == storage.ContentHash(content) {
+			summary.Unchanged++
+			return nil
+		}
+
+		isNew[key.Key] = existing == nil
+		batch = append(batch, storage.StoreRequest{Key: key, Content: content, Options: storage.DefaultStoreOptions()})
+		batchBytes += len(content)
+		if len(batch) >= indexBatchFiles || batchBytes >= indexBatchBytes {
+			return flush()
+		}
+		return nil
+	}
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return summary, fmt.Errorf("cannot index %s: %w", root, err)
+		}
+		if !info.IsDir() {
+			if err := index(root); err != nil {
+				return summary, err
+			}
+			continue
+		}
+
+		ignore := newGitignoreMatcher(root)
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				summary.Skipped++
+				return nil
+			}
+			rel, _ := filepath.Rel(root, path)
+			if d.IsDir() {
+				if path != root && (d.Name() == ".git" || ignore.Ignored(rel, true)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || ignore.Ignored(rel, false) {
+				return nil
+			}
+			return index(path)
+		})
+		if err != nil {
+			return summary, err
+		}
+	}
+
+	if err := flush(); err != nil {
+		return summary, err
+	}
+
+	stored, err := store.List(ctx, sessionID, storage.QueryOptions{})
+	if err != nil {
+		return summary, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	for _, meta := range stored {
+		if seen[meta.Key.Key] || meta.Pinned {
+			continue
+		}
+		if err := store.Delete(ctx, meta.Key); err != nil {
+			return summary, fmt.Errorf("failed to remove %s: %w", meta.Key.Key, err)
+		}
+		summary.Removed = append(summary.Removed, meta.Key.Key)
+	}
+	return summary, nil
+}
+
+// readTextFile reads a file if it is text and within maxSize.
+func readTextFile(path string, maxSize int64) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || (maxSize > 0 && info.Size() > maxSize) {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	sniff := content
+	if len(sniff) > binarySniffBytes {
+		sniff = sniff[:binarySniffBytes]
+	}
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		return "", false
+	}
+	return string(content), true
+}
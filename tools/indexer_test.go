@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestIndexPathsIncremental(t *testing.T) {
+	root := t.TempDir()
+	a := filepath.Join(root, "a.go")
+	b := filepath.Join(root, "b.go")
+	_ = os.WriteFile(a, []byte("package a"), 0644)
+	_ = os.WriteFile(b, []byte("package b"), 0644)
+
+	store := storage.NewInMemoryResultStore()
+	ctx := context.Background()
+
+	summary, err := IndexPaths(ctx, store, "index", []string{root}, 0)
+	if err != nil {
+		t.Fatalf("IndexPaths failed: %v", err)
+	}
+	if len(summary.Added) != 2 || summary.Unchanged != 0 {
+		t.Fatalf("expected 2 added on first pass, got %s", summary)
+	}
+
+	// Second pass over an unchanged tree writes nothing
+	summary, _ = IndexPaths(ctx, store, "index", []string{root}, 0)
+	if summary.Unchanged != 2 || len(summary.Added)+len(summary.Updated)+len(summary.Removed) != 0 || summary.Bytes != 0 {
+		t.Fatalf("expected everything unchanged, got %s", summary)
+	}
+
+	_ = os.WriteFile(a, []byte("package a // changed"), 0644)
+	_ = os.Remove(b)
+	_ = os.WriteFile(filepath.Join(root, "c.go"), []byte("package c"), 0644)
+
+	summary, _ = IndexPaths(ctx, store, "index", []string{root}, 0)
+	if len(summary.Added) != 1 || len(summary.Updated) != 1 || len(summary.Removed) != 1 || summary.Unchanged != 0 {
+		t.Fatalf("expected 1 added, 1 updated, 1 removed, got %s", summary)
+	}
+	if summary.Removed[0] != filepath.ToSlash(b) {
+		t.Errorf("expected %s removed, got %v", b, summary.Removed)
+	}
+
+	result, _ := store.Get(ctx, storage.ResultKey{SessionID: "index", Key: filepath.ToSlash(a)})
+	if result == nil || result.Content != "package a // changed" {
+		t.Errorf("expected updated content, got %v", result)
+	}
+}
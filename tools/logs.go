@@ -0,0 +1,552 @@
+// Log Analysis Tools - tail, filter, histogram.
+//
+// Raw log output (kubectl logs, cat of a large file) overwhelms context.
+// These tools read log files from disk and return only what matters:
+// the last N matching lines, or a time-bucketed count of errors. The
+// raw slice is stored in ResultStore so get_lines/search_stored can dig in.
+//
+// Information Hiding:
+// - Backward chunked reading hidden
+// - Level and timestamp detection hidden
+// - Bucketing and rendering hidden
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+const (
+	// DefaultTailLines is the default number of lines tail_log returns.
+	DefaultTailLines = 100
+	// MaxTailLines caps how many lines one tail_log call collects.
+	MaxTailLines = 10000
+	// logPreviewLines is how many lines are shown inline when the slice is stored.
+	logPreviewLines = 20
+	// logReadChunk is the block size for reading files backwards.
+	logReadChunk = 64 * 1024
+)
+
+// logLevels orders severities; filters include the given level and above.
+var logLevels = map[string]int{
+	"trace": 0, "debug": 1, "info": 2, "notice": 2,
+	"warn": 3, "warning": 3,
+	"error": 4, "err": 4,
+	"fatal": 5, "critical": 5, "crit": 5, "panic": 5,
+}
+
+var (
+	levelWordRe = regexp.MustCompile(`(?i)\b(trace|debug|info|notice|warn|warning|error|err|fatal|critical|crit|panic)\b`)
+	levelKeyRe  = regexp.MustCompile(`(?i)"?(?:level|severity|lvl)"?\s*[:=]\s*"?([a-z]+)`)
+	klogRe      = regexp.MustCompile(`^([IWEF])\d{4} `)
+	isoTimeRe   = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+	syslogRe    = regexp.MustCompile(`\b[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}\b`)
+)
+
+// klogLevels maps klog/glog line prefixes to severities.
+var klogLevels = map[string]string{"I": "info", "W": "warn", "E": "error", "F": "fatal"}
+
+// detectLevel returns the severity of a log line, or -1 if none is found.
+// Explicit level fields win over bare words in the message.
+func detectLevel(line string) int {
+	if m := levelKeyRe.FindStringSubmatch(line); m != nil {
+		if sev, ok := logLevels[strings.ToLower(m[1])]; ok {
+			return sev
+		}
+	}
+	if m := klogRe.FindStringSubmatch(line); m != nil {
+		return logLevels[klogLevels[m[1]]]
+	}
+	if m := levelWordRe.FindString(line); m != "" {
+		return logLevels[strings.ToLower(m)]
+	}
+	return -1
+}
+
+// detectTime parses the first timestamp in a log line.
+func detectTime(line string) (time.Time, bool) {
+	if m := isoTimeRe.FindString(line); m != "" {
+		m = strings.Replace(strings.Replace(m, " ", "T", 1), ",", ".", 1)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700", "2006-01-02T15:04:05.999999999"} {
+			if ts, err := time.Parse(layout, m); err == nil {
+				return ts, true
+			}
+		}
+	}
+	if m := syslogRe.FindString(line); m != "" {
+		if ts, err := time.Parse(time.Stamp, m); err == nil {
+			return ts.AddDate(time.Now().Year(), 0, 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// logFilter selects lines by minimum level and regex.
+type logFilter struct {
+	minLevel int // -1 = no level filter
+	pattern  *regexp.Regexp
+}
+
+func newLogFilter(level, pattern string) (logFilter, error) {
+	f := logFilter{minLevel: -1}
+	if level != "" {
+		sev, ok := logLevels[strings.ToLower(level)]
+		if !ok {
+			return f, fmt.Errorf("unknown level '%s': use debug, info, warn, error, or fatal", level)
+		}
+		f.minLevel = sev
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return f, fmt.Errorf("invalid pattern: %w", err)
+		}
+		f.pattern = re
+	}
+	return f, nil
+}
+
+func (f logFilter) match(line string) bool {
+	if f.minLevel >= 0 && detectLevel(line) < f.minLevel {
+		return false
+	}
+	return f.pattern == nil || f.pattern.MatchString(line)
+}
+
+func (f logFilter) active() bool {
+	return f.minLevel >= 0 || f.pattern != nil
+}
+
+// logSource holds the shared path policy and ResultStore wiring for log tools.
+type logSource struct {
+	allowedPaths []string
+	store        *storage.ResultStore
+	sessionID    string
+	fileContext  *StoredFileContext
+	slices       atomic.Int64
+}
+
+// open validates the path and opens the file.
+func (s *logSource) open(path string) (*os.File, os.FileInfo, error) {
+	if path == "" {
+		return nil, nil, fmt.Errorf("path cannot be empty")
+	}
+	if !pathAllowed(path, s.allowedPaths) {
+		return nil, nil, fmt.Errorf("access to path '%s' is not allowed", path)
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("file does not exist: %s", path)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to read file metadata: %w", err)
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, nil, fmt.Errorf("path is a directory: %s", path)
+	}
+	return f, info, nil
+}
+
+// storeSlice stores lines and returns the key, or "" without a store.
+func (s *logSource) storeSlice(ctx context.Context, path string, lines []string) string {
+	if s.store == nil || len(lines) == 0 {
+		return ""
+	}
+	key := storage.ResultKey{
+		SessionID: s.sessionID,
+		Key:       fmt.Sprintf("logs/%s/%d", filepath.Base(path), s.slices.Add(1)),
+	}
+	if _, err := s.store.Store(ctx, key, strings.Join(lines, "\n"), storage.DefaultStoreOptions()); err != nil {
+		return ""
+	}
+	if s.fileContext != nil {
+		s.fileContext.Add(key.Key)
+	}
+	return key.Key
+}
+
+// TailLogTool returns the last N lines of a log file, optionally filtered.
+type TailLogTool struct {
+	BaseTool
+	logSource
+	maxLines int
+}
+
+// NewTailLogTool creates a tail tool. maxLines <= 0 uses MaxTailLines.
+func NewTailLogTool(maxLines int) *TailLogTool {
+	if maxLines <= 0 || maxLines > MaxTailLines {
+		maxLines = MaxTailLines
+	}
+	return &TailLogTool{maxLines: maxLines}
+}
+
+// WithAllowedPaths sets the allowed path prefixes.
+func (t *TailLogTool) WithAllowedPaths(paths []string) *TailLogTool {
+	t.allowedPaths = paths
+	return t
+}
+
+// WithResultStore stores the selected lines so only a preview enters the conversation.
+func (t *TailLogTool) WithResultStore(store *storage.ResultStore, sessionID string, fileContext *StoredFileContext) *TailLogTool {
+	t.store = store
+	t.sessionID = sessionID
+	t.fileContext = fileContext
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *TailLogTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "tail_log",
+		Description: "Get the last N lines of a log file without loading the whole file, optionally keeping only lines at or above a level and/or matching a regex. For command output (e.g. kubectl logs), redirect it to a file first.",
+		Parameters: []ToolParameter{
+			{Name: "path", ParamType: "string", Description: "Path to the log file", Required: true},
+			{Name: "lines", ParamType: "integer", Description: fmt.Sprintf("Number of lines to return (default: %d)", DefaultTailLines), Required: false},
+			{Name: "level", ParamType: "string", Description: "Minimum level: debug, info, warn, error, or fatal", Required: false},
+			{Name: "pattern", ParamType: "string", Description: "Regex lines must match", Required: false},
+		},
+	}
+}
+
+type tailLogArgs struct {
+	Path    string `json:"path"`
+	Lines   int    `json:"lines"`
+	Level   string `json:"level"`
+	Pattern string `json:"pattern"`
+}
+
+// Validate validates the arguments.
+func (t *TailLogTool) Validate(args json.RawMessage) error {
+	var a tailLogArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	_, err := newLogFilter(a.Level, a.Pattern)
+	return err
+}
+
+// Execute reads the file backwards until enough matching lines are found.
+func (t *TailLogTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a tailLogArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+	filter, err := newLogFilter(a.Level, a.Pattern)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	n := a.Lines
+	if n <= 0 {
+		n = DefaultTailLines
+	}
+	if n > t.maxLines {
+		n = t.maxLines
+	}
+
+	f, info, err := t.open(a.Path)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	defer f.Close()
+
+	lines, scanned, err := tailLines(ctx, f, info.Size(), n, filter)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	if len(lines) == 0 {
+		return SuccessResult(fmt.Sprintf("No matching lines in %s (%d lines scanned)", a.Path, scanned)), nil
+	}
+
+	header := fmt.Sprintf("Last %d lines of %s", len(lines), a.Path)
+	if filter.active() {
+		header = fmt.Sprintf("Last %d matching lines of %s (%d lines scanned)", len(lines), a.Path, scanned)
+	}
+
+	key := t.storeSlice(ctx, a.Path, lines)
+	if key == "" || len(lines) <= logPreviewLines {
+		return SuccessResult(header + ":\n" + strings.Join(lines, "\n")), nil
+	}
+
+	preview := lines[len(lines)-logPreviewLines:]
+	return SuccessResult(fmt.Sprintf("%s\nStored as: %s (use get_lines/search_stored to access)\nLevels: %s\nLast %d lines:\n%s",
+		header, key, levelCounts(lines), len(preview), strings.Join(preview, "\n"))), nil
+}
+
+// tailLines reads a file backwards in chunks and returns the last n lines
+// passing the filter, in file order, and how many lines were scanned.
+func tailLines(ctx context.Context, r io.ReaderAt, size int64, n int, filter logFilter) ([]string, int, error) {
+	var matched []string
+	var partial []byte // Incomplete line at the start of the previous chunk
+	scanned := 0
+	offset := size
+
+	take := func(line []byte) bool {
+		scanned++
+		s := strings.TrimRight(string(line), "\r")
+		if filter.match(s) {
+			matched = append(matched, s)
+		}
+		return len(matched) >= n
+	}
+
+	// A trailing newline does not start an extra empty line
+	trimmedTrailing := false
+	for offset > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, scanned, err
+		}
+		chunkSize := int64(logReadChunk)
+		if offset < chunkSize {
+			chunkSize = offset
+		}
+		offset -= chunkSize
+
+		buf := make([]byte, chunkSize, int(chunkSize)+len(partial))
+		if _, err := r.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, scanned, fmt.Errorf("failed to read file: %w", err)
+		}
+		buf = append(buf, partial...)
+		if !trimmedTrailing {
+			buf = bytes.TrimSuffix(buf, []byte{'\n'})
+			trimmedTrailing = true
+		}
+
+		for {
+			i := bytes.LastIndexByte(buf, '\n')
+			if i < 0 {
+				break
+			}
+			if take(buf[i+1:]) {
+				return reverseLines(matched), scanned, nil
+			}
+			buf = buf[:i]
+		}
+		partial = buf
+	}
+	if len(partial) > 0 {
+		take(partial)
+	}
+	return reverseLines(matched), scanned, nil
+}
+
+func reverseLines(lines []string) []string {
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// levelCounts summarizes the levels present in lines.
+func levelCounts(lines []string) string {
+	names := []string{"debug", "info", "warn", "error", "fatal"}
+	counts := make(map[int]int)
+	for _, line := range lines {
+		counts[detectLevel(line)]++
+	}
+	var parts []string
+	for _, name := range names {
+		if c := counts[logLevels[name]]; c > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", name, c))
+		}
+	}
+	if c := counts[-1]; c > 0 {
+		parts = append(parts, fmt.Sprintf("unknown=%d", c))
+	}
+	return strings.Join(parts, " ")
+}
+
+// LogHistogramTool counts matching log lines per time bucket.
+type LogHistogramTool struct {
+	BaseTool
+	logSource
+}
+
+// NewLogHistogramTool creates a histogram tool.
+func NewLogHistogramTool() *LogHistogramTool {
+	return &LogHistogramTool{}
+}
+
+// WithAllowedPaths sets the allowed path prefixes.
+func (t *LogHistogramTool) WithAllowedPaths(paths []string) *LogHistogramTool {
+	t.allowedPaths = paths
+	return t
+}
+
+// WithResultStore stores the matching lines alongside the histogram.
+func (t *LogHistogramTool) WithResultStore(store *storage.ResultStore, sessionID string, fileContext *StoredFileContext) *LogHistogramTool {
+	t.store = store
+	t.sessionID = sessionID
+	t.fileContext = fileContext
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *LogHistogramTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "log_histogram",
+		Description: "Count log lines per time bucket to see when errors spiked. Scans the whole file; by default counts lines at error level or above.",
+		Parameters: []ToolParameter{
+			{Name: "path", ParamType: "string", Description: "Path to the log file", Required: true},
+			{Name: "bucket", ParamType: "string", Description: "Bucket size as a duration (e.g. '1m', '5m', '1h'; default: '1m')", Required: false},
+			{Name: "level", ParamType: "string", Description: "Minimum level to count (default: error; use 'trace' to count all lines)", Required: false},
+			{Name: "pattern", ParamType: "string", Description: "Regex lines must match", Required: false},
+		},
+	}
+}
+
+type logHistogramArgs struct {
+	Path    string `json:"path"`
+	Bucket  string `json:"bucket"`
+	Level   string `json:"level"`
+	Pattern string `json:"pattern"`
+}
+
+func (a logHistogramArgs) parse() (time.Duration, logFilter, error) {
+	bucket := time.Minute
+	if a.Bucket != "" {
+		d, err := time.ParseDuration(a.Bucket)
+		if err != nil || d <= 0 {
+			return 0, logFilter{}, fmt.Errorf("invalid bucket '%s': use a duration like 1m or 1h", a.Bucket)
+		}
+		bucket = d
+	}
+	level := a.Level
+	if level == "" {
+		level = "error"
+	}
+	filter, err := newLogFilter(level, a.Pattern)
+	return bucket, filter, err
+}
+
+// Validate validates the arguments.
+func (t *LogHistogramTool) Validate(args json.RawMessage) error {
+	var a logHistogramArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	_, _, err := a.parse()
+	return err
+}
+
+// Execute scans the file and renders the histogram.
+func (t *LogHistogramTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	var a logHistogramArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+	bucket, filter, err := a.parse()
+	if err != nil {
+		return FailureResult(err), nil
+	}
+
+	f, _, err := t.open(a.Path)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	defer f.Close()
+
+	counts := make(map[time.Time]int)
+	var matched []string
+	total, untimed := 0, 0
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if total%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return FailureResult(err), nil
+			}
+		}
+		total++
+		line := scanner.Text()
+		if !filter.match(line) {
+			continue
+		}
+		matched = append(matched, line)
+		ts, ok := detectTime(line)
+		if !ok {
+			untimed++
+			continue
+		}
+		counts[ts.Truncate(bucket)]++
+	}
+	if err := scanner.Err(); err != nil {
+		return FailureResult(fmt.Errorf("failed to read file: %w", err)), nil
+	}
+
+	if len(matched) == 0 {
+		return SuccessResult(fmt.Sprintf("No matching lines in %s (%d lines scanned)", a.Path, total)), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d matching lines of %d in %s, per %s:\n", len(matched), total, a.Path, bucket)
+	sb.WriteString(renderHistogram(counts, bucket))
+	if untimed > 0 {
+		fmt.Fprintf(&sb, "(%d matching lines had no recognizable timestamp)\n", untimed)
+	}
+	if key := t.storeSlice(ctx, a.Path, matched); key != "" {
+		fmt.Fprintf(&sb, "Matching lines stored as: %s (use get_lines/search_stored to access)\n", key)
+	}
+	return SuccessResult(sb.String()), nil
+}
+
+// renderHistogram draws one bar per bucket, including empty buckets between
+// the first and last so gaps are visible. Long ranges show only non-empty buckets.
+func renderHistogram(counts map[time.Time]int, bucket time.Duration) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	keys := make([]time.Time, 0, len(counts))
+	maxCount := 0
+	for k, c := range counts {
+		keys = append(keys, k)
+		if c > maxCount {
+			maxCount = c
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+
+	const maxBuckets = 200
+	const barWidth = 40
+	first, last := keys[0], keys[len(keys)-1]
+	if int(last.Sub(first)/bucket) < maxBuckets {
+		keys = keys[:0]
+		for ts := first; !ts.After(last); ts = ts.Add(bucket) {
+			keys = append(keys, ts)
+		}
+	}
+
+	var sb strings.Builder
+	for _, ts := range keys {
+		c := counts[ts]
+		bar := strings.Repeat("#", (c*barWidth+maxCount-1)/maxCount)
+		fmt.Fprintf(&sb, "%s %6d %s\n", ts.Format(time.RFC3339), c, bar)
+	}
+	return sb.String()
+}
@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func writeLog(t *testing.T, lines []string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDetectLevel(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"2024-01-02T15:04:05Z ERROR connection refused", "error"},
+		{`{"level":"warn","msg":"slow query"}`, "warn"},
+		{"level=info msg=\"error budget ok\"", "info"},
+		{"E0102 15:04:05.123456   1 main.go:10] boom", "error"},
+		{"plain text line", ""},
+	}
+	for _, tt := range tests {
+		want := -1
+		if tt.want != "" {
+			want = logLevels[tt.want]
+		}
+		if got := detectLevel(tt.line); got != want {
+			t.Errorf("detectLevel(%q) = %d, want %d", tt.line, got, want)
+		}
+	}
+}
+
+func TestTailLogFiltersAcrossChunks(t *testing.T) {
+	// Enough lines to span several backward read chunks
+	var lines []string
+	for i := 0; i < 20000; i++ {
+		level := "INFO"
+		if i%1000 == 0 {
+			level = "ERROR"
+		}
+		lines = append(lines, fmt.Sprintf("2024-01-02T15:04:05Z %s request %d", level, i))
+	}
+	path := writeLog(t, lines)
+
+	args, _ := json.Marshal(tailLogArgs{Path: path, Lines: 3, Level: "error"})
+	result, err := NewTailLogTool(0).Execute(context.Background(), args)
+	if err != nil || !result.Success() {
+		t.Fatalf("tail failed: %+v, err %v", result, err)
+	}
+	want := "ERROR request 17000\n2024-01-02T15:04:05Z ERROR request 18000\n2024-01-02T15:04:05Z ERROR request 19000"
+	if !strings.HasSuffix(result.Output, want) {
+		t.Errorf("expected last 3 errors in order, got:\n%s", result.Output)
+	}
+
+	args, _ = json.Marshal(tailLogArgs{Path: path, Lines: 2})
+	result, _ = NewTailLogTool(0).Execute(context.Background(), args)
+	if !strings.HasSuffix(result.Output, "request 19998\n2024-01-02T15:04:05Z INFO request 19999") {
+		t.Errorf("expected last 2 lines, got:\n%s", result.Output)
+	}
+}
+
+func TestTailLogStoresSlice(t *testing.T) {
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	path := writeLog(t, lines)
+
+	store := storage.NewInMemoryResultStore()
+	tool := NewTailLogTool(0).WithResultStore(store, "session", nil)
+	args, _ := json.Marshal(tailLogArgs{Path: path, Lines: 40})
+	result, _ := tool.Execute(context.Background(), args)
+
+	if !strings.Contains(result.Output, "Stored as: logs/app.log/1") {
+		t.Fatalf("expected stored slice reference, got:\n%s", result.Output)
+	}
+	stored, err := store.Get(context.Background(), storage.ResultKey{SessionID: "session", Key: "logs/app.log/1"})
+	if err != nil || !strings.HasPrefix(stored.Content, "line 10\n") {
+		t.Errorf("expected stored slice to start at line 10, got err %v", err)
+	}
+}
+
+func TestLogHistogram(t *testing.T) {
+	path := writeLog(t, []string{
+		"2024-01-02T15:00:10Z ERROR a",
+		"2024-01-02T15:00:50Z ERROR b",
+		"2024-01-02T15:01:30Z INFO c",
+		"2024-01-02T15:03:05Z ERROR d",
+		"ERROR without timestamp",
+	})
+
+	args, _ := json.Marshal(logHistogramArgs{Path: path})
+	result, err := NewLogHistogramTool().Execute(context.Background(), args)
+	if err != nil || !result.Success() {
+		t.Fatalf("histogram failed: %+v, err %v", result, err)
+	}
+
+	for _, want := range []string{
+		"4 matching lines of 5",
+		"2024-01-02T15:00:00Z      2 ",
+		"2024-01-02T15:01:00Z      0 ",
+		"2024-01-02T15:03:00Z      1 ",
+		"1 matching lines had no recognizable timestamp",
+	} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected %q in output:\n%s", want, result.Output)
+		}
+	}
+
+	if err := NewLogHistogramTool().Validate(json.RawMessage(`{"path": "x", "bucket": "soon"}`)); err == nil {
+		t.Error("expected invalid bucket to fail validation")
+	}
+}
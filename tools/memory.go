@@ -0,0 +1,237 @@
+// Memory Tools - let agents persist and recall facts deliberately.
+//
+// store_memory saves a fact to the session's memory store; recall_memory
+// retrieves memories by keyword and type. Both are scoped to one session,
+// so agents never see memories from other sessions.
+//
+// Information Hiding:
+// - Keyword matching and result formatting hidden
+// - Session scoping hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+const (
+	// DefaultRecallLimit is the default number of memories recalled.
+	DefaultRecallLimit = 10
+	// recallScanLimit bounds how many memories a recall ranks and scans.
+	recallScanLimit = 500
+	// maxMemoryLength bounds a stored memory.
+	maxMemoryLength = 2000
+)
+
+// StoreMemoryTool saves facts to a session's memory store.
+type StoreMemoryTool struct {
+	store     storage.MemoryStorage
+	sessionID string
+	agentID   string
+}
+
+// NewStoreMemoryTool creates a tool storing memories for sessionID.
+func NewStoreMemoryTool(store storage.MemoryStorage, sessionID string) *StoreMemoryTool {
+	return &StoreMemoryTool{store: store, sessionID: sessionID}
+}
+
+// WithAgent records agentID as the author of stored memories.
+func (t *StoreMemoryTool) WithAgent(agentID string) *StoreMemoryTool {
+	t.agentID = agentID
+	return t
+}
+
+// Metadata returns the tool metadata.
+func (t *StoreMemoryTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "store_memory",
+		Description: "Save a fact to long-term memory for this session (decisions, findings, user preferences). Recall it later with recall_memory, even in a future run.",
+		Parameters: []ToolParameter{
+			{Name: "content", ParamType: "string", Description: fmt.Sprintf("The fact to remember, self-contained (max %d chars)", maxMemoryLength), Required: true},
+			{Name: "type", ParamType: "string", Description: "Memory type: fact (default) or episodic", Required: false},
+			{Name: "importance", ParamType: "number", Description: fmt.Sprintf("How much this matters, 0 to 1 (default: %.1f); important memories are recalled first and pruned last", storage.DefaultMemoryImportance), Required: false},
+		},
+	}
+}
+
+type storeMemoryArgs struct {
+	Content    string   `json:"content"`
+	Type       string   `json:"type"`
+	Importance *float64 `json:"importance"`
+}
+
+// memoryType parses the type argument for store_memory.
+func (a storeMemoryArgs) memoryType() (storage.MemoryType, error) {
+	if a.Type == "" {
+		return storage.MemoryFact, nil
+	}
+	memType, err := storage.ParseMemoryType(a.Type)
+	if err != nil {
+		return "", err
+	}
+	if memType != storage.MemoryFact && memType != storage.MemoryEpisodic {
+		return "", fmt.Errorf("type must be fact or episodic, got %s", a.Type)
+	}
+	return memType, nil
+}
+
+// Validate validates the arguments.
+func (t *StoreMemoryTool) Validate(args json.RawMessage) error {
+	var a storeMemoryArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(a.Content) == "" {
+		return fmt.Errorf("content cannot be empty")
+	}
+	if len(a.Content) > maxMemoryLength {
+		return fmt.Errorf("content exceeds %d characters; store a shorter summary", maxMemoryLength)
+	}
+	if a.Importance != nil && (*a.Importance < 0 || *a.Importance > 1) {
+		return fmt.Errorf("importance must be between 0 and 1")
+	}
+	_, err := a.memoryType()
+	return err
+}
+
+// Execute stores the memory.
+func (t *StoreMemoryTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if t.store == nil {
+		return FailureResultf("no memory store available"), nil
+	}
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	var a storeMemoryArgs
+	_ = json.Unmarshal(args, &a) // Validated above
+	memType, _ := a.memoryType()
+
+	entry := storage.NewMemoryEntry(t.sessionID, memType, strings.TrimSpace(a.Content))
+	if t.agentID != "" {
+		entry = entry.WithAgent(t.agentID)
+	}
+	if a.Importance != nil {
+		entry = entry.WithImportance(*a.Importance)
+	}
+	if err := t.store.StoreMemory(ctx, entry); err != nil {
+		return FailureResult(fmt.Errorf("failed to store memory: %w", err)), nil
+	}
+	return SuccessResult(fmt.Sprintf("Stored %s memory %s", memType, entry.ID)), nil
+}
+
+// RecallMemoryTool retrieves memories from a session's memory store.
+type RecallMemoryTool struct {
+	store     storage.MemoryStorage
+	sessionID string
+}
+
+// NewRecallMemoryTool creates a tool recalling memories for sessionID.
+func NewRecallMemoryTool(store storage.MemoryStorage, sessionID string) *RecallMemoryTool {
+	return &RecallMemoryTool{store: store, sessionID: sessionID}
+}
+
+// Metadata returns the tool metadata.
+func (t *RecallMemoryTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "recall_memory",
+		Description: "Recall memories saved in this session, most relevant first (importance, recency and use). Filter by keywords (all must match, case-insensitive) and type.",
+		Parameters: []ToolParameter{
+			{Name: "query", ParamType: "string", Description: "Keywords to match (empty: most recent memories)", Required: false},
+			{Name: "type", ParamType: "string", Description: "Memory type: fact, episodic, orchestration or conversation (default: all)", Required: false},
+			{Name: "limit", ParamType: "integer", Description: fmt.Sprintf("Maximum memories (default: %d)", DefaultRecallLimit), Required: false},
+		},
+	}
+}
+
+type recallMemoryArgs struct {
+	Query string `json:"query"`
+	Type  string `json:"type"`
+	Limit int    `json:"limit"`
+}
+
+// Validate validates the arguments.
+func (t *RecallMemoryTool) Validate(args json.RawMessage) error {
+	var a recallMemoryArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a.Type != "" {
+		if _, err := storage.ParseMemoryType(a.Type); err != nil {
+			return err
+		}
+	}
+	if a.Limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+	return nil
+}
+
+// Execute queries the memory store.
+func (t *RecallMemoryTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if t.store == nil {
+		return FailureResultf("no memory store available"), nil
+	}
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	var a recallMemoryArgs
+	_ = json.Unmarshal(args, &a) // Validated above
+
+	limit := DefaultRecallLimit
+	if a.Limit > 0 {
+		limit = a.Limit
+	}
+	var memType *storage.MemoryType
+	if a.Type != "" {
+		parsed, _ := storage.ParseMemoryType(a.Type)
+		memType = &parsed
+	}
+	terms := strings.Fields(strings.ToLower(a.Query))
+
+	// Scan well past limit: keywords filter and ranking reorders
+	entries, err := t.store.QueryMemories(ctx, t.sessionID, memType, recallScanLimit)
+	if err != nil {
+		return FailureResult(fmt.Errorf("failed to query memories: %w", err)), nil
+	}
+
+	storage.RankMemories(entries, time.Now(), storage.DefaultMemoryHalfLife)
+
+	var out strings.Builder
+	found := 0
+	for _, entry := range entries {
+		if !matchesAllTerms(entry.Content, terms) {
+			continue
+		}
+		created := time.Unix(entry.CreatedAt, 0).Format("2006-01-02 15:04")
+		fmt.Fprintf(&out, "[%s %s] %s\n", entry.Type, created, entry.Content)
+		_, _ = t.store.GetMemory(ctx, entry.ID) // Best-effort access tracking; recalled memories rank higher
+		found++
+		if found == limit {
+			break
+		}
+	}
+	if found == 0 {
+		if len(terms) > 0 {
+			return SuccessResult(fmt.Sprintf("No memories match %q", a.Query)), nil
+		}
+		return SuccessResult("No memories stored in this session yet"), nil
+	}
+	return SuccessResult(strings.TrimSuffix(out.String(), "\n")), nil
+}
+
+// matchesAllTerms reports whether content contains every lower-cased term.
+func matchesAllTerms(content string, terms []string) bool {
+	lower := strings.ToLower(content)
+	for _, term := range terms {
+		if !strings.Contains(lower, term) {
+			return false
+		}
+	}
+	return true
+}
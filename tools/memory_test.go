@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestMemoryToolsStoreAndRecall(t *testing.T) {
+	store, err := storage.NewSqliteInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	remember := NewStoreMemoryTool(store, "s1").WithAgent("tester")
+	recall := NewRecallMemoryTool(store, "s1")
+
+	for _, args := range []string{
+		`{"content": "The API uses port 8443"}`,
+		`{"content": "Deploys go through the staging cluster first"}`,
+		`{"content": "Ran the port scan", "type": "episodic"}`,
+	} {
+		result, err := remember.Execute(ctx, []byte(args))
+		if err != nil || !result.Success() {
+			t.Fatalf("store_memory %s failed: %v %v", args, err, result.Error)
+		}
+	}
+
+	result, _ := recall.Execute(ctx, []byte(`{"query": "PORT"}`))
+	if !result.Success() || strings.Count(result.Output, "\n") != 1 {
+		t.Fatalf("expected 2 port memories, got %q", result.Output)
+	}
+
+	result, _ = recall.Execute(ctx, []byte(`{"query": "port", "type": "fact"}`))
+	if !strings.Contains(result.Output, "8443") || strings.Contains(result.Output, "scan") {
+		t.Errorf("type filter not applied: %q", result.Output)
+	}
+
+	// Recalled twice, so it now outranks the others
+	result, _ = recall.Execute(ctx, []byte(`{"limit": 1}`))
+	if !strings.Contains(result.Output, "8443") || strings.Contains(result.Output, "\n") {
+		t.Errorf("expected only the most-used memory, got %q", result.Output)
+	}
+
+	// Importance outweighs use
+	if result, _ := remember.Execute(ctx, []byte(`{"content": "Never deploy on Fridays", "importance": 1}`)); !result.Success() {
+		t.Fatalf("store_memory with importance failed: %v", result.Error)
+	}
+	result, _ = recall.Execute(ctx, []byte(`{"limit": 1}`))
+	if !strings.Contains(result.Output, "Fridays") {
+		t.Errorf("expected the important memory first, got %q", result.Output)
+	}
+
+	// Other sessions see nothing
+	other := NewRecallMemoryTool(store, "s2")
+	result, _ = other.Execute(ctx, []byte(`{"query": "port"}`))
+	if !strings.Contains(result.Output, "No memories match") {
+		t.Errorf("memories leaked across sessions: %q", result.Output)
+	}
+}
+
+func TestMemoryToolsValidation(t *testing.T) {
+	remember := NewStoreMemoryTool(nil, "s1")
+	recall := NewRecallMemoryTool(nil, "s1")
+
+	for _, args := range []string{`{}`, `{"content": "  "}`, `{"content": "x", "type": "orchestration"}`, `{"content": "x", "importance": 1.5}`, `{"content": "` + strings.Repeat("x", maxMemoryLength+1) + `"}`} {
+		if err := remember.Validate([]byte(args)); err == nil {
+			t.Errorf("store_memory accepted %.40s", args)
+		}
+	}
+	if err := recall.Validate([]byte(`{"type": "dreams"}`)); err == nil {
+		t.Error("recall_memory accepted unknown type")
+	}
+	if result, _ := recall.Execute(context.Background(), []byte(`{}`)); result.Success() {
+		t.Error("recall_memory without a store should fail")
+	}
+}
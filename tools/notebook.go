@@ -0,0 +1,236 @@
+// Notebook - scratch notes for the root agent.
+//
+// The notes tool lets an agent jot intermediate conclusions by topic
+// instead of restating them in every reply. Notes live in the ResultStore
+// under the run's session ("notes/<topic>"), so they are also reachable
+// with get_lines and search_stored, and Digest renders a compact summary
+// the loop puts in the system prompt before each call.
+//
+// Information Hiding:
+// - Note storage layout (one line per note, one key per topic) hidden
+// - Digest size limits hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/storage"
+)
+
+const (
+	// notesPrefix is the store key prefix of notebook topics.
+	notesPrefix = "notes/"
+	// defaultNoteTopic is used when append or read names no topic.
+	defaultNoteTopic = "general"
+	// maxNoteLength bounds one note.
+	maxNoteLength = 1000
+	// Digest limits: latest notes per topic, chars per note, total bytes
+	digestNotesPerTopic = 3
+	digestNoteChars     = 160
+	digestMaxBytes      = 2000
+)
+
+// Notebook stores notes by topic for one session. Safe for concurrent use.
+type Notebook struct {
+	mu        sync.Mutex // Serializes read-modify-write of a topic
+	store     *storage.ResultStore
+	sessionID string
+}
+
+// NewNotebook creates a notebook stored in store under sessionID.
+func NewNotebook(store *storage.ResultStore, sessionID string) *Notebook {
+	return &Notebook{store: store, sessionID: sessionID}
+}
+
+// Append adds a note to topic and returns the topic's note count.
+func (n *Notebook) Append(ctx context.Context, topic, note string) (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	existing, err := n.Read(ctx, topic)
+	if err != nil {
+		return 0, err
+	}
+	// One note per line keeps get_lines and the digest simple
+	line := "- " + strings.Join(strings.Fields(note), " ")
+	content := line
+	if existing != "" {
+		content = existing + "\n" + line
+	}
+	if _, err := n.store.Store(ctx, n.key(topic), content, storage.DefaultStoreOptions()); err != nil {
+		return 0, fmt.Errorf("failed to store note: %w", err)
+	}
+	return strings.Count(content, "\n") + 1, nil
+}
+
+// Read returns the notes of topic, one per line ("" if none).
+func (n *Notebook) Read(ctx context.Context, topic string) (string, error) {
+	result, err := n.store.Get(ctx, n.key(topic))
+	if err != nil {
+		return "", fmt.Errorf("failed to read notes: %w", err)
+	}
+	if result == nil {
+		return "", nil
+	}
+	return result.Content, nil
+}
+
+// Topics returns the topics with notes, sorted.
+func (n *Notebook) Topics(ctx context.Context) ([]string, error) {
+	metas, err := n.store.GetByPrefix(ctx, n.sessionID, notesPrefix, storage.QueryOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	topics := make([]string, 0, len(metas))
+	for _, meta := range metas {
+		topics = append(topics, strings.TrimPrefix(meta.Key.Key, notesPrefix))
+	}
+	sort.Strings(topics)
+	return topics, nil
+}
+
+// Digest renders the latest notes of each topic for a system prompt, or ""
+// if the notebook is empty.
+func (n *Notebook) Digest(ctx context.Context) string {
+	topics, err := n.Topics(ctx)
+	if err != nil || len(topics) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("YOUR NOTES (latest per topic; read the full topic with the notes tool):\n")
+	for _, topic := range topics {
+		content, err := n.Read(ctx, topic)
+		if err != nil || content == "" {
+			continue
+		}
+		lines := strings.Split(content, "\n")
+		shown := lines[max(0, len(lines)-digestNotesPerTopic):]
+		entry := fmt.Sprintf("[%s] (%d notes)\n", topic, len(lines))
+		for _, line := range shown {
+			entry += truncateNote(line) + "\n"
+		}
+		if sb.Len()+len(entry) > digestMaxBytes {
+			sb.WriteString("... more topics: use notes with action=list\n")
+			break
+		}
+		sb.WriteString(entry)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (n *Notebook) key(topic string) storage.ResultKey {
+	if topic == "" {
+		topic = defaultNoteTopic
+	}
+	return storage.ResultKey{SessionID: n.sessionID, Key: notesPrefix + topic}
+}
+
+func truncateNote(line string) string {
+	if len(line) <= digestNoteChars {
+		return line
+	}
+	return text.Head(line, digestNoteChars-3) + "..."
+}
+
+// NotesTool exposes a Notebook to an agent.
+type NotesTool struct {
+	BaseTool
+	notebook *Notebook
+}
+
+// NewNotesTool creates the notes tool.
+func NewNotesTool(notebook *Notebook) *NotesTool {
+	return &NotesTool{notebook: notebook}
+}
+
+func (t *NotesTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "notes",
+		Description: "Scratch notebook for this run. Append intermediate conclusions by topic instead of repeating them; the latest notes are shown to you in the system prompt. Actions: append, read, list.",
+		Parameters: []ToolParameter{
+			{Name: "action", ParamType: "string", Description: "append, read or list", Required: true},
+			{Name: "topic", ParamType: "string", Description: fmt.Sprintf("Topic name (default: %s)", defaultNoteTopic), Required: false},
+			{Name: "text", ParamType: "string", Description: fmt.Sprintf("The note to append (max %d chars)", maxNoteLength), Required: false},
+		},
+	}
+}
+
+type notesArgs struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+	Text   string `json:"text"`
+}
+
+func (t *NotesTool) Validate(args json.RawMessage) error {
+	var a notesArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	switch a.Action {
+	case "append":
+		if strings.TrimSpace(a.Text) == "" {
+			return fmt.Errorf("text is required for append")
+		}
+		if len(a.Text) > maxNoteLength {
+			return fmt.Errorf("note too long: %d chars (max %d)", len(a.Text), maxNoteLength)
+		}
+	case "read", "list":
+	default:
+		return fmt.Errorf("action must be append, read or list, got %q", a.Action)
+	}
+	if strings.ContainsAny(a.Topic, "/\n") {
+		return fmt.Errorf("topic cannot contain '/' or newlines")
+	}
+	return nil
+}
+
+func (t *NotesTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if t.notebook == nil || t.notebook.store == nil {
+		return FailureResultf("no result store available"), nil
+	}
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	var a notesArgs
+	_ = json.Unmarshal(args, &a) // Validated above
+
+	topic := a.Topic
+	if topic == "" {
+		topic = defaultNoteTopic
+	}
+
+	switch a.Action {
+	case "append":
+		count, err := t.notebook.Append(ctx, topic, a.Text)
+		if err != nil {
+			return FailureResult(err), nil
+		}
+		return SuccessResult(fmt.Sprintf("Noted under %s (%d notes)", topic, count)), nil
+	case "read":
+		content, err := t.notebook.Read(ctx, topic)
+		if err != nil {
+			return FailureResult(err), nil
+		}
+		if content == "" {
+			return SuccessResult(fmt.Sprintf("No notes under %s", topic)), nil
+		}
+		return SuccessResult(fmt.Sprintf("Notes under %s:\n%s", topic, content)), nil
+	default:
+		topics, err := t.notebook.Topics(ctx)
+		if err != nil {
+			return FailureResult(err), nil
+		}
+		if len(topics) == 0 {
+			return SuccessResult("No notes yet"), nil
+		}
+		return SuccessResult("Topics: " + strings.Join(topics, ", ")), nil
+	}
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestNotesToolAppendReadList(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	defer store.Close()
+	notebook := NewNotebook(store, "run")
+	tool := NewNotesTool(notebook)
+
+	for _, args := range []string{
+		`{"action":"append","text":"config is loaded in main.go"}`,
+		`{"action":"append","topic":"bugs","text":"nil map write\nin cache.go"}`,
+		`{"action":"append","topic":"bugs","text":"race in the pool"}`,
+	} {
+		if res, _ := tool.Execute(ctx, json.RawMessage(args)); !res.Success() {
+			t.Fatalf("append %s failed: %+v", args, res)
+		}
+	}
+
+	res, _ := tool.Execute(ctx, json.RawMessage(`{"action":"read","topic":"bugs"}`))
+	if !strings.Contains(res.Output, "- nil map write in cache.go\n- race in the pool") {
+		t.Errorf("unexpected notes: %q", res.Output)
+	}
+	res, _ = tool.Execute(ctx, json.RawMessage(`{"action":"list"}`))
+	if res.Output != "Topics: bugs, general" {
+		t.Errorf("unexpected topics: %q", res.Output)
+	}
+	if res, _ := tool.Execute(ctx, json.RawMessage(`{"action":"append"}`)); res.Success() {
+		t.Error("append without text should fail")
+	}
+
+	digest := notebook.Digest(ctx)
+	if !strings.Contains(digest, "[bugs] (2 notes)") || !strings.Contains(digest, "- config is loaded in main.go") {
+		t.Errorf("unexpected digest:\n%s", digest)
+	}
+	if NewNotebook(store, "other").Digest(ctx) != "" {
+		t.Error("notes should be scoped to their session")
+	}
+}
@@ -0,0 +1,135 @@
+// Observation Budget - Bounded Tool Output in Conversations.
+//
+// Tool outputs are appended to the conversation after every call. A single
+// large output (a big file, a verbose command) can crowd out everything
+// else. ObservationBudget caps each observation; overflow goes to the
+// ResultStore and the conversation gets a reference plus preview instead.
+// Output of retrieval tools (get_lines, search_stored, list_stored) is
+// paged instead: the first page is shown and next_page returns the rest.
+//
+// Information Hiding:
+// - Overflow key generation hidden
+// - Truncation fallback hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/richinex/ariadne/internal/text"
+	"github.com/richinex/ariadne/storage"
+)
+
+// DefaultMaxObservationBytes is the default per-observation byte cap.
+const DefaultMaxObservationBytes = 8 * 1024
+
+// ObservationBudget caps the size of tool observations.
+// Safe for concurrent use.
+type ObservationBudget struct {
+	maxBytes    int
+	store       *storage.ResultStore
+	sessionID   string
+	fileContext *StoredFileContext
+	pager       *pager
+	overflows   atomic.Int64
+	saved       atomic.Int64 // Bytes kept out of the conversation
+}
+
+// NewObservationBudget creates a budget capping observations at maxBytes.
+// Non-positive values fall back to DefaultMaxObservationBytes.
+func NewObservationBudget(maxBytes int) *ObservationBudget {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxObservationBytes
+	}
+	return &ObservationBudget{maxBytes: maxBytes, pager: newPager(maxBytes)}
+}
+
+// WithResultStore stores oversized observations so they stay reachable via
+// get_lines and search_stored. Without a store, observations are truncated.
+func (b *ObservationBudget) WithResultStore(store *storage.ResultStore, sessionID string, fileContext *StoredFileContext) *ObservationBudget {
+	b.store = store
+	b.sessionID = sessionID
+	b.fileContext = fileContext
+	return b
+}
+
+// MaxBytes returns the per-observation byte cap.
+func (b *ObservationBudget) MaxBytes() int {
+	return b.maxBytes
+}
+
+// BytesSaved returns how many bytes of tool output were kept out of the
+// conversation by storing or truncating it.
+func (b *ObservationBudget) BytesSaved() int64 {
+	return b.saved.Load()
+}
+
+// observationRef is a reference to an oversized observation in ResultStore.
+type observationRef struct {
+	Key       string `json:"result_key"`
+	Hash      string `json:"content_hash"`
+	LineCount int    `json:"line_count"`
+	ByteSize  int    `json:"byte_size"`
+}
+
+// Apply returns output unchanged if it fits the budget. Otherwise output
+// of retrieval tools is paged, and other output is stored and a compact
+// reference with preview is returned.
+func (b *ObservationBudget) Apply(ctx context.Context, toolName, output string) string {
+	if len(output) <= b.maxBytes {
+		return output
+	}
+
+	if pagedTools[toolName] {
+		return b.keep(output, b.pager.paginate(toolName, output))
+	}
+
+	if b.store == nil {
+		return b.keep(output, b.truncate(output))
+	}
+
+	n := b.overflows.Add(1)
+	key := storage.ResultKey{
+		SessionID: b.sessionID,
+		Key:       fmt.Sprintf("observations/%s/%d", toolName, n),
+	}
+
+	meta, err := b.store.Store(ctx, key, output, storage.DefaultStoreOptions())
+	if err != nil {
+		return b.keep(output, b.truncate(output))
+	}
+	if b.fileContext != nil {
+		b.fileContext.Add(key.Key)
+	}
+
+	refJSON, err := json.Marshal(observationRef{
+		Key:       key.Key,
+		Hash:      meta.ContentHash,
+		LineCount: meta.LineCount,
+		ByteSize:  meta.ByteSize,
+	})
+	if err != nil {
+		refJSON = []byte(fmt.Sprintf(`{"result_key": %q}`, key.Key))
+	}
+
+	return b.keep(output, fmt.Sprintf("[Large output stored - %d bytes, %d lines]\nKey: %s (use get_lines/search_stored to access)\nReference: %s\nPreview:\n%s",
+		meta.ByteSize, meta.LineCount, key.Key, string(refJSON), meta.Summary))
+}
+
+// keep records the bytes saved by replacing output with kept.
+func (b *ObservationBudget) keep(output, kept string) string {
+	if saved := len(output) - len(kept); saved > 0 {
+		b.saved.Add(int64(saved))
+	}
+	return kept
+}
+
+// truncate keeps the head and tail of output within the budget.
+func (b *ObservationBudget) truncate(output string) string {
+	half := b.maxBytes / 2
+	return fmt.Sprintf("%s\n\n... [%d bytes truncated] ...\n\n%s",
+		text.Head(output, half), len(output)-b.maxBytes, text.Tail(output, half))
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestObservationBudgetPassthrough(t *testing.T) {
+	budget := NewObservationBudget(100)
+	output := strings.Repeat("a", 100)
+	if got := budget.Apply(context.Background(), "read_file", output); got != output {
+		t.Errorf("expected output within budget to pass through unchanged")
+	}
+}
+
+func TestObservationBudgetOverflowToStore(t *testing.T) {
+	store := storage.NewInMemoryResultStore()
+	fileContext := NewStoredFileContext()
+	budget := NewObservationBudget(64).WithResultStore(store, "session", fileContext)
+
+	output := strings.Repeat("line of tool output\n", 50)
+	got := budget.Apply(context.Background(), "shell", output)
+
+	if len(got) >= len(output) {
+		t.Errorf("expected compact reference, got %d bytes", len(got))
+	}
+	if saved := budget.BytesSaved(); saved != int64(len(output)-len(got)) {
+		t.Errorf("BytesSaved = %d, want %d", saved, len(output)-len(got))
+	}
+	if !strings.Contains(got, "Key: observations/shell/1") {
+		t.Fatalf("expected overflow key in reference, got %q", got)
+	}
+
+	stored, err := store.Get(context.Background(), storage.ResultKey{SessionID: "session", Key: "observations/shell/1"})
+	if err != nil {
+		t.Fatalf("expected overflow to be stored: %v", err)
+	}
+	if stored.Content != output {
+		t.Error("expected stored content to match full output")
+	}
+	if fileContext.Last() != "observations/shell/1" {
+		t.Error("expected overflow key in stored file context")
+	}
+
+	// Second overflow gets a distinct key
+	got = budget.Apply(context.Background(), "shell", output)
+	if !strings.Contains(got, "observations/shell/2") {
+		t.Errorf("expected second overflow key, got %q", got)
+	}
+}
+
+func TestObservationBudgetTruncatesWithoutStore(t *testing.T) {
+	budget := NewObservationBudget(20)
+	output := "HEAD" + strings.Repeat("x", 100) + "TAIL"
+
+	got := budget.Apply(context.Background(), "shell", output)
+	if !strings.HasPrefix(got, "HEAD") || !strings.HasSuffix(got, "TAIL") {
+		t.Errorf("expected head and tail kept, got %q", got)
+	}
+	if !strings.Contains(got, "bytes truncated") {
+		t.Errorf("expected truncation marker, got %q", got)
+	}
+}
+
+func TestObservationBudgetPagesRetrievalOutput(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	budget := NewObservationBudget(512).WithResultStore(store, "session", nil)
+	nextPage := NewNextPageTool(budget)
+
+	var sb strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&sb, "line %03d\n", i)
+	}
+	output := sb.String()
+
+	page := budget.Apply(ctx, "get_lines", output)
+	var got strings.Builder
+	for n := 1; ; n++ {
+		if len(page) > budget.MaxBytes() {
+			t.Fatalf("page %d is %d bytes, over the %d byte budget", n, len(page), budget.MaxBytes())
+		}
+		body, footer, ok := strings.Cut(page, "\n[Page ")
+		if !ok {
+			t.Fatalf("page %d has no footer: %q", n, page)
+		}
+		if !strings.HasSuffix(body, "\n") {
+			t.Errorf("page %d not cut at a line boundary: %q", n, body)
+		}
+		got.WriteString(body)
+		if strings.Contains(footer, "end of output") {
+			break
+		}
+		_, token, _ := strings.Cut(footer, `token "`)
+		token, _, _ = strings.Cut(token, `"`)
+		result, err := nextPage.Execute(ctx, json.RawMessage(fmt.Sprintf(`{"token": %q}`, token)))
+		if err != nil || !result.Success() {
+			t.Fatalf("next_page(%q) = %+v, %v", token, result, err)
+		}
+		page = result.Output
+	}
+	if got.String() != output {
+		t.Error("pages do not add up to the full output")
+	}
+
+	// Paged output is not stored again
+	if metas, _ := store.List(ctx, "session", storage.QueryOptions{}); len(metas) != 0 {
+		t.Errorf("expected nothing stored, got %d results", len(metas))
+	}
+
+	result, _ := nextPage.Execute(ctx, json.RawMessage(`{"token": "get_lines-9/2"}`))
+	if result.Success() || !strings.Contains(result.Error.Error(), "expired") {
+		t.Errorf("expected unknown token to fail, got %+v", result)
+	}
+}
@@ -0,0 +1,190 @@
+// Paged Observations.
+//
+// Some tool output has to be read in the conversation: get_lines of a range
+// that turned out too big, or a long list of search matches. Storing it in
+// the ResultStore would only hand the agent another key to read with
+// get_lines. Instead ObservationBudget shows the first page with a
+// continuation token, and the agent reads the rest with next_page.
+//
+// Information Hiding:
+// - Page splitting at line boundaries hidden
+// - Token format and eviction of old outputs hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/richinex/ariadne/internal/text"
+)
+
+// pagedTools return content from the store, so their oversized output is
+// paged rather than stored again.
+var pagedTools = map[string]bool{
+	"get_lines":     true,
+	"search_stored": true,
+	"list_stored":   true,
+}
+
+// maxPagedOutputs is how many paged outputs are kept for next_page; older
+// ones are dropped.
+const maxPagedOutputs = 16
+
+// pageFooterBytes is reserved in each page for the continuation footer, so
+// a page with its footer stays within the budget.
+const pageFooterBytes = 128
+
+// pager splits outputs into pages and serves them by token.
+type pager struct {
+	mu        sync.Mutex
+	pageBytes int
+	outputs   map[string][]string // Output ID -> pages
+	order     []string            // Output IDs, oldest first
+	count     int
+}
+
+func newPager(maxBytes int) *pager {
+	return &pager{
+		pageBytes: max(maxBytes-pageFooterBytes, maxBytes/2),
+		outputs:   make(map[string][]string),
+	}
+}
+
+// paginate keeps output's pages and returns the first.
+func (p *pager) paginate(toolName, output string) string {
+	pages := splitPages(output, p.pageBytes)
+
+	p.mu.Lock()
+	p.count++
+	id := fmt.Sprintf("%s-%d", toolName, p.count)
+	p.outputs[id] = pages
+	p.order = append(p.order, id)
+	if len(p.order) > maxPagedOutputs {
+		delete(p.outputs, p.order[0])
+		p.order = p.order[1:]
+	}
+	p.mu.Unlock()
+
+	return renderPage(id, pages, 1)
+}
+
+// page returns the page token points to.
+func (p *pager) page(token string) (string, error) {
+	id, n, ok := splitNextPageToken(token)
+	if !ok {
+		return "", fmt.Errorf("invalid page token %q", token)
+	}
+	p.mu.Lock()
+	pages, found := p.outputs[id]
+	p.mu.Unlock()
+	if !found {
+		return "", fmt.Errorf("page token %q has expired; call the original tool again", token)
+	}
+	if n > len(pages) {
+		return "", fmt.Errorf("page %d of %q does not exist (%d pages)", n, id, len(pages))
+	}
+	return renderPage(id, pages, n), nil
+}
+
+// renderPage returns page n (1-indexed) with a footer saying how to get the
+// next one.
+func renderPage(id string, pages []string, n int) string {
+	if n == len(pages) {
+		return fmt.Sprintf("%s\n[Page %d of %d, end of output]", pages[n-1], n, len(pages))
+	}
+	return fmt.Sprintf("%s\n[Page %d of %d. Call next_page with token %q for more]",
+		pages[n-1], n, len(pages), nextPageToken(id, n+1))
+}
+
+func nextPageToken(id string, n int) string {
+	return id + "/" + strconv.Itoa(n)
+}
+
+func splitNextPageToken(token string) (string, int, bool) {
+	i := strings.LastIndex(token, "/")
+	if i <= 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(token[i+1:])
+	if err != nil || n < 1 {
+		return "", 0, false
+	}
+	return token[:i], n, true
+}
+
+// splitPages cuts s into pages of at most size bytes, at a line boundary
+// when one falls in the second half of the page.
+func splitPages(s string, size int) []string {
+	var pages []string
+	for len(s) > size {
+		page := text.Head(s, size)
+		if i := strings.LastIndex(page, "\n"); i >= size/2 {
+			page = page[:i+1]
+		}
+		if page == "" {
+			page = s[:size] // A rune wider than the page; cut through it
+		}
+		pages = append(pages, page)
+		s = s[len(page):]
+	}
+	return append(pages, s)
+}
+
+// NextPageTool returns further pages of output that was too large for one
+// observation.
+type NextPageTool struct {
+	BaseTool
+	budget *ObservationBudget
+}
+
+// NewNextPageTool creates a tool reading pages kept by budget.
+func NewNextPageTool(budget *ObservationBudget) *NextPageTool {
+	return &NextPageTool{budget: budget}
+}
+
+func (t *NextPageTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "next_page",
+		Description: "Get the next page of a tool result that was too large to show at once. Pass the token from the end of the previous page.",
+		Parameters: []ToolParameter{
+			{Name: "token", ParamType: "string", Description: "Continuation token from the previous page (e.g. \"get_lines-1/2\")", Required: true},
+		},
+	}
+}
+
+type nextPageArgs struct {
+	Token string `json:"token"`
+}
+
+func (t *NextPageTool) Validate(args json.RawMessage) error {
+	var a nextPageArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(a.Token) == "" {
+		return fmt.Errorf("token cannot be empty")
+	}
+	return nil
+}
+
+func (t *NextPageTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if t.budget == nil {
+		return FailureResultf("no paged output available"), nil
+	}
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	var a nextPageArgs
+	_ = json.Unmarshal(args, &a) // Validated above
+
+	page, err := t.budget.pager.page(strings.TrimSpace(a.Token))
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	return SuccessResult(page), nil
+}
@@ -0,0 +1,204 @@
+// PII Masking of Tool Output.
+//
+// Regulated environments can't send personal data to an external model.
+// A PIIScanner replaces emails, phone numbers, payment card numbers and
+// any configured patterns in tool output with placeholders before the
+// output reaches a prompt. The same value always gets the same
+// placeholder within a run ([EMAIL_1], [EMAIL_2], ...), so the model can
+// still tell values apart. Counts of what was masked are kept for the
+// run's report; the values themselves are not.
+//
+// Information Hiding:
+// - Built-in patterns and their validation (Luhn, digit counts)
+// - Placeholder numbering
+// - Masking of error messages without losing their error class
+
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Built-in PII kinds.
+const (
+	PIIEmail      = "EMAIL"
+	PIIPhone      = "PHONE"
+	PIICreditCard = "CREDIT_CARD"
+)
+
+// piiPattern finds one kind of PII. valid, if set, rejects matches that
+// only look like it.
+type piiPattern struct {
+	kind  string
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// builtinPIIPatterns run in order; card numbers come before phone numbers
+// so a card isn't masked as a phone.
+var builtinPIIPatterns = []piiPattern{
+	{kind: PIIEmail, re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{kind: PIICreditCard, re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhnValid},
+	{kind: PIIPhone, re: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?)?\d{2,4}[ .-]\d{3,4}(?:[ .-]\d{2,4}){0,2}\b`), valid: phoneValid},
+}
+
+// piiKindPattern is the form of a custom pattern's name.
+var piiKindPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// PIIScanner masks PII in text. Safe for concurrent use; share one scanner
+// between a run's executors so placeholders stay consistent.
+type PIIScanner struct {
+	patterns []piiPattern
+
+	mu     sync.Mutex
+	tokens map[string]string // Kind + value -> placeholder
+	next   map[string]int    // Kind -> last placeholder number
+	counts map[string]int    // Kind -> occurrences masked
+}
+
+// NewPIIScanner creates a scanner with the built-in patterns plus custom
+// ones given as "NAME=REGEX" (e.g. `EMPLOYEE_ID=E\d{6}`). Names are
+// upper case and become the placeholder prefix.
+func NewPIIScanner(custom []string) (*PIIScanner, error) {
+	patterns := append([]piiPattern(nil), builtinPIIPatterns...)
+	for _, spec := range custom {
+		name, expr, ok := strings.Cut(spec, "=")
+		if !ok || !piiKindPattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid PII pattern %q: use NAME=REGEX with an upper-case NAME", spec)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PII pattern %s: %w", name, err)
+		}
+		patterns = append(patterns, piiPattern{kind: name, re: re})
+	}
+	return &PIIScanner{
+		patterns: patterns,
+		tokens:   make(map[string]string),
+		next:     make(map[string]int),
+		counts:   make(map[string]int),
+	}, nil
+}
+
+// Mask returns text with PII replaced by placeholders. Safe on nil.
+func (s *PIIScanner) Mask(text string) string {
+	if s == nil || text == "" {
+		return text
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.patterns {
+		text = p.re.ReplaceAllStringFunc(text, func(match string) string {
+			if p.valid != nil && !p.valid(match) {
+				return match
+			}
+			s.counts[p.kind]++
+			key := p.kind + "\x00" + match
+			token, ok := s.tokens[key]
+			if !ok {
+				s.next[p.kind]++
+				token = fmt.Sprintf("[%s_%d]", p.kind, s.next[p.kind])
+				s.tokens[key] = token
+			}
+			return token
+		})
+	}
+	return text
+}
+
+// MaskResult masks a tool result's output and error message. The masked
+// error still matches the original with errors.Is and errors.As.
+func (s *PIIScanner) MaskResult(result ToolResult) ToolResult {
+	if s == nil {
+		return result
+	}
+	result.Output = s.Mask(result.Output)
+	if result.Error != nil {
+		if msg := s.Mask(result.Error.Error()); msg != result.Error.Error() {
+			result.Error = &maskedError{msg: msg, err: result.Error}
+		}
+	}
+	return result
+}
+
+// Report returns how many occurrences of each kind were masked, or nil if
+// none were.
+func (s *PIIScanner) Report() map[string]int {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.counts) == 0 {
+		return nil
+	}
+	report := make(map[string]int, len(s.counts))
+	for kind, n := range s.counts {
+		report[kind] = n
+	}
+	return report
+}
+
+// FormatPIIReport describes a Report as "2 EMAIL, 1 PHONE", by kind.
+func FormatPIIReport(report map[string]int) string {
+	kinds := make([]string, 0, len(report))
+	for kind := range report {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		parts[i] = fmt.Sprintf("%d %s", report[kind], kind)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// maskedError replaces an error's message while keeping its class.
+type maskedError struct {
+	msg string
+	err error
+}
+
+func (e *maskedError) Error() string { return e.msg }
+func (e *maskedError) Unwrap() error { return e.err }
+
+// luhnValid reports whether the digits in s pass the Luhn checksum used
+// by payment card numbers.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// phoneValid accepts 10 to 15 digits, or 7 or more with an international
+// prefix, so dates, versions and short codes aren't masked.
+func phoneValid(s string) bool {
+	digits := 0
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	if strings.HasPrefix(s, "+") {
+		return digits >= 7 && digits <= 15
+	}
+	return digits >= 10 && digits <= 15
+}
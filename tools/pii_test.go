@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPIIScannerMasksBuiltinKinds(t *testing.T) {
+	scanner, err := NewPIIScanner(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := "Contact jane.doe@example.com or +1 415-555-0132. Card 4111 1111 1111 1111. CC jane.doe@example.com"
+	got := scanner.Mask(input)
+	want := "Contact [EMAIL_1] or [PHONE_1]. Card [CREDIT_CARD_1]. CC [EMAIL_1]"
+	if got != want {
+		t.Errorf("Mask() = %q, want %q", got, want)
+	}
+
+	report := scanner.Report()
+	if report[PIIEmail] != 2 || report[PIIPhone] != 1 || report[PIICreditCard] != 1 {
+		t.Errorf("unexpected report: %v", report)
+	}
+	if s := FormatPIIReport(report); s != "1 CREDIT_CARD, 2 EMAIL, 1 PHONE" {
+		t.Errorf("FormatPIIReport() = %q", s)
+	}
+}
+
+func TestPIIScannerLeavesLookalikes(t *testing.T) {
+	scanner, _ := NewPIIScanner(nil)
+
+	// Dates, versions, short numbers and digit runs failing the Luhn check
+	for _, text := range []string{
+		"released 2024-01-15",
+		"go 1.24.3",
+		"port 8080 and pid 12345",
+		"order 1234 5678 9012 3456",
+	} {
+		if got := scanner.Mask(text); got != text {
+			t.Errorf("Mask(%q) = %q, want unchanged", text, got)
+		}
+	}
+	if report := scanner.Report(); report != nil {
+		t.Errorf("expected empty report, got %v", report)
+	}
+}
+
+func TestPIIScannerCustomPatterns(t *testing.T) {
+	scanner, err := NewPIIScanner([]string{`EMPLOYEE_ID=E\d{6}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scanner.Mask("owner E123456, reviewer E654321"); got != "owner [EMPLOYEE_ID_1], reviewer [EMPLOYEE_ID_2]" {
+		t.Errorf("Mask() = %q", got)
+	}
+
+	for _, spec := range []string{"no-equals", "lower=x", "BAD=("} {
+		if _, err := NewPIIScanner([]string{spec}); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestExecutorMasksPII(t *testing.T) {
+	scanner, _ := NewPIIScanner(nil)
+	executor := NewExecutor(ToolConfig{PII: scanner})
+
+	result, err := executor.Execute(context.Background(), cannedTool{name: "read_file", output: "author: bob@example.org"}, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(result.Output, "bob@example.org") || !strings.Contains(result.Output, "[EMAIL_1]") {
+		t.Errorf("expected masked, fenced output, got %q", result.Output)
+	}
+
+	denied := scanner.MaskResult(DeniedResultf("cannot mail bob@example.org"))
+	if denied.Error.Error() != "cannot mail [EMAIL_1]" {
+		t.Errorf("expected masked error, got %q", denied.Error)
+	}
+	if !errors.Is(denied.Error, ErrToolDenied) {
+		t.Error("masked error should still match ErrToolDenied")
+	}
+}
+
+func TestNilPIIScanner(t *testing.T) {
+	var scanner *PIIScanner
+	if got := scanner.Mask("a@b.co"); got != "a@b.co" {
+		t.Errorf("nil scanner changed text: %q", got)
+	}
+	if scanner.Report() != nil {
+		t.Error("nil scanner should report nothing")
+	}
+}
@@ -41,6 +41,19 @@ func (r *Registry) Register(tool Tool) error {
 	return nil
 }
 
+// Unregister removes a tool from the registry.
+// Returns false if no tool with that name was registered.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[name]; !exists {
+		return false
+	}
+	delete(r.tools, name)
+	return true
+}
+
 // Get returns a tool by name.
 func (r *Registry) Get(name string) (Tool, bool) {
 	r.mu.RLock()
@@ -115,6 +128,7 @@ func (r *Registry) Description() string {
 const (
 	DefaultToolTimeout  = 30            // seconds
 	DefaultMaxFileSize = 1024 * 1024    // 1MB
+	DefaultBuildTimeout = 300           // seconds, for run_build/run_lint
 )
 
 // WithDefaults creates a registry with common default tools.
@@ -125,11 +139,18 @@ func WithDefaults() (*Registry, error) {
 	tools := []Tool{
 		NewBashTool(DefaultToolTimeout),
 		NewShellTool(DefaultToolTimeout),
+		NewBuildTool(DefaultBuildTimeout),
+		NewLintTool(DefaultBuildTimeout),
 		NewReadFileTool(DefaultMaxFileSize),
 		NewWriteFileTool(DefaultMaxFileSize),
 		NewEditFileTool(DefaultMaxFileSize),
+		NewFormatTool(DefaultToolTimeout),
 		NewAppendFileTool(DefaultMaxFileSize),
+		NewStatFileTool(),
+		NewTailLogTool(0),
+		NewLogHistogramTool(),
 		NewHTTPTool(DefaultToolTimeout),
+		NewGrepTool(DefaultMaxFileSize),
 		NewRipgrepTool(DefaultToolTimeout),
 	}
 
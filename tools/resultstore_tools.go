@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/richinex/ariadne/internal/text"
 	"github.com/richinex/ariadne/storage"
 )
 
@@ -66,14 +67,17 @@ type SearchStoredTool struct {
 	store       *storage.ResultStore
 	sessionID   string
 	fileContext *StoredFileContext
+	cache       *readCache
 }
 
 // NewSearchStoredTool creates a tool for searching stored content.
+// Repeated searches are answered from a cache until the store changes.
 func NewSearchStoredTool(store *storage.ResultStore, sessionID string, fileContext *StoredFileContext) *SearchStoredTool {
 	return &SearchStoredTool{
 		store:       store,
 		sessionID:   sessionID,
 		fileContext: fileContext,
+		cache:       newReadCache(),
 	}
 }
 
@@ -123,22 +127,29 @@ func (t *SearchStoredTool) Execute(ctx context.Context, args json.RawMessage) (T
 		limit = *a.Limit
 	}
 
+	cacheKey := fmt.Sprintf("%d:%s", limit, a.Pattern)
+	cached, ok, generation := t.cache.get(t.store, cacheKey)
+	if ok {
+		return SuccessResult(cachedNote + cached), nil
+	}
+
 	matches, err := t.store.Search(ctx, t.sessionID, a.Pattern, limit)
 	if err != nil {
 		return FailureResult(fmt.Errorf("search failed: %w", err)), nil
 	}
 
-	if len(matches) == 0 {
-		return SuccessResult(fmt.Sprintf("No matches found for pattern: %s", a.Pattern)), nil
-	}
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Found %d matches for '%s':\n\n", len(matches), a.Pattern))
-	for i, m := range matches {
-		sb.WriteString(fmt.Sprintf("[%d] %s (line %d):\n  %s\n\n", i+1, m.Key.Key, m.Line, m.Context))
+	output := fmt.Sprintf("No matches found for pattern: %s", a.Pattern)
+	if len(matches) > 0 {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Found %d matches for '%s':\n\n", len(matches), a.Pattern))
+		for i, m := range matches {
+			sb.WriteString(fmt.Sprintf("[%d] %s (line %d):\n  %s\n\n", i+1, m.Key.Key, m.Line, m.Context))
+		}
+		output = sb.String()
 	}
 
-	return SuccessResult(sb.String()), nil
+	t.cache.put(generation, t.store, cacheKey, output)
+	return SuccessResult(output), nil
 }
 
 // GetLinesTool retrieves specific line ranges from stored content.
@@ -147,14 +158,17 @@ type GetLinesTool struct {
 	store       *storage.ResultStore
 	sessionID   string
 	fileContext *StoredFileContext
+	cache       *readCache
 }
 
 // NewGetLinesTool creates a tool for getting line ranges.
+// Repeated reads are answered from a cache until the store changes.
 func NewGetLinesTool(store *storage.ResultStore, sessionID string, fileContext *StoredFileContext) *GetLinesTool {
 	return &GetLinesTool{
 		store:       store,
 		sessionID:   sessionID,
 		fileContext: fileContext,
+		cache:       newReadCache(),
 	}
 }
 
@@ -214,16 +228,24 @@ func (t *GetLinesTool) Execute(ctx context.Context, args json.RawMessage) (ToolR
 		Key:       fileKey,
 	}
 
+	cacheKey := fmt.Sprintf("%d-%d:%s", a.Start, a.End, fileKey)
+	cached, ok, generation := t.cache.get(t.store, cacheKey)
+	if ok {
+		return SuccessResult(cachedNote + cached), nil
+	}
+
 	lines, err := t.store.GetLines(ctx, key, storage.LineRange{Start: a.Start, End: a.End})
 	if err != nil {
 		return FailureResult(fmt.Errorf("failed to get lines: %w", err)), nil
 	}
 
-	if lines == "" {
-		return SuccessResult(fmt.Sprintf("No content found for key: %s (lines %d-%d)", fileKey, a.Start, a.End)), nil
+	output := fmt.Sprintf("No content found for key: %s (lines %d-%d)", fileKey, a.Start, a.End)
+	if lines != "" {
+		output = fmt.Sprintf("Lines %d-%d of %s:\n\n%s", a.Start, a.End, fileKey, lines)
 	}
 
-	return SuccessResult(fmt.Sprintf("Lines %d-%d of %s:\n\n%s", a.Start, a.End, fileKey, lines)), nil
+	t.cache.put(generation, t.store, cacheKey, output)
+	return SuccessResult(output), nil
 }
 
 // ListStoredTool lists stored results with optional prefix filter.
@@ -246,15 +268,21 @@ func NewListStoredTool(store *storage.ResultStore, sessionID string, fileContext
 func (t *ListStoredTool) Metadata() ToolMetadata {
 	return ToolMetadata{
 		Name:        "list_stored",
-		Description: "List all stored content in this session. Use prefix to filter (e.g., 'src/' for all files in src). Uses Trie for O(m+k) prefix lookup.",
+		Description: "List all stored content in this session with its tags (kind, language, size). Use prefix to filter (e.g., 'src/' for all files in src), and kind, language or size to pick relevant content without fetching it. Uses Trie for O(m+k) prefix lookup.",
 		Parameters: []ToolParameter{
 			{Name: "prefix", ParamType: "string", Description: "Optional prefix filter (e.g., 'src/', 'file:')", Required: false},
+			{Name: "kind", ParamType: "string", Description: "Optional kind filter: code, log, config, doc, data or text", Required: false},
+			{Name: "language", ParamType: "string", Description: "Optional language filter (e.g., 'go', 'python', 'yaml')", Required: false},
+			{Name: "size", ParamType: "string", Description: "Optional size filter: small (<4KB), medium (<64KB), large (<1MB) or huge", Required: false},
 		},
 	}
 }
 
 type listStoredArgs struct {
-	Prefix string `json:"prefix"`
+	Prefix   string `json:"prefix"`
+	Kind     string `json:"kind"`
+	Language string `json:"language"`
+	Size     string `json:"size"`
 }
 
 func (t *ListStoredTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
@@ -270,41 +298,122 @@ func (t *ListStoredTool) Execute(ctx context.Context, args json.RawMessage) (Too
 	var results []storage.ResultMetadata
 	var err error
 
+	filter := storage.ResultTags{Kind: a.Kind, Language: a.Language, Size: a.Size}
 	if a.Prefix != "" {
-		results, err = t.store.GetByPrefix(ctx, t.sessionID, a.Prefix)
+		results, err = t.store.GetByPrefix(ctx, t.sessionID, a.Prefix, storage.QueryOptions{Tags: filter})
 	} else {
-		results, err = t.store.List(ctx, t.sessionID, storage.QueryOptions{Limit: 100})
+		results, err = t.store.List(ctx, t.sessionID, storage.QueryOptions{Limit: 100, Tags: filter})
 	}
 
 	if err != nil {
 		return FailureResult(fmt.Errorf("failed to list stored content: %w", err)), nil
 	}
 
+	var scope []string
+	if a.Prefix != "" {
+		scope = append(scope, fmt.Sprintf("prefix '%s'", a.Prefix))
+	}
+	if tags := filter.String(); tags != "" {
+		scope = append(scope, "tags "+tags)
+	}
+
 	if len(results) == 0 {
-		if a.Prefix != "" {
-			return SuccessResult(fmt.Sprintf("No stored content found with prefix: %s", a.Prefix)), nil
+		if len(scope) > 0 {
+			return SuccessResult(fmt.Sprintf("No stored content found with %s", strings.Join(scope, " and "))), nil
 		}
 		return SuccessResult("No stored content in this session"), nil
 	}
 
 	var sb strings.Builder
-	if a.Prefix != "" {
-		sb.WriteString(fmt.Sprintf("Stored content with prefix '%s' (%d items):\n\n", a.Prefix, len(results)))
+	if len(scope) > 0 {
+		sb.WriteString(fmt.Sprintf("Stored content with %s (%d items):\n\n", strings.Join(scope, " and "), len(results)))
 	} else {
 		sb.WriteString(fmt.Sprintf("All stored content (%d items):\n\n", len(results)))
 	}
 
 	for _, meta := range results {
-		sb.WriteString(fmt.Sprintf("- %s (%d lines, %d bytes)\n", meta.Key.Key, meta.LineCount, meta.ByteSize))
+		tags := meta.Tags.String()
+		if meta.Pinned {
+			tags += ", pinned"
+		}
+		sb.WriteString(fmt.Sprintf("- %s (%d lines, %d bytes) [%s]\n", meta.Key.Key, meta.LineCount, meta.ByteSize, tags))
 		if meta.Summary != "" {
 			// Show first line of summary
-			firstLine := strings.Split(meta.Summary, "\n")[0]
-			if len(firstLine) > 60 {
-				firstLine = firstLine[:60] + "..."
-			}
+			firstLine := text.Truncate(strings.Split(meta.Summary, "\n")[0], 60)
 			sb.WriteString(fmt.Sprintf("  Preview: %s\n", firstLine))
 		}
 	}
 
 	return SuccessResult(sb.String()), nil
 }
+
+// PinStoredTool pins and unpins stored results. Pinned results survive the
+// run's session cleanup and can't be deleted until unpinned.
+type PinStoredTool struct {
+	BaseTool
+	store     *storage.ResultStore
+	sessionID string
+}
+
+// NewPinStoredTool creates a tool for pinning stored content.
+func NewPinStoredTool(store *storage.ResultStore, sessionID string) *PinStoredTool {
+	return &PinStoredTool{
+		store:     store,
+		sessionID: sessionID,
+	}
+}
+
+func (t *PinStoredTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "pin_stored",
+		Description: "Pin stored content (e.g. the task spec or key findings) so it is kept when the session is cleaned up and can't be deleted, or unpin it again.",
+		Parameters: []ToolParameter{
+			{Name: "key", ParamType: "string", Description: "The storage key (see list_stored)", Required: true},
+			{Name: "action", ParamType: "string", Description: "pin or unpin (default: pin)", Required: false},
+		},
+	}
+}
+
+type pinStoredArgs struct {
+	Key    string `json:"key"`
+	Action string `json:"action"`
+}
+
+func (t *PinStoredTool) Validate(args json.RawMessage) error {
+	var a pinStoredArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(a.Key) == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	switch a.Action {
+	case "", "pin", "unpin":
+	default:
+		return fmt.Errorf("action must be pin or unpin, got %q", a.Action)
+	}
+	return nil
+}
+
+func (t *PinStoredTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if t.store == nil {
+		return FailureResultf("no result store available"), nil
+	}
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	var a pinStoredArgs
+	_ = json.Unmarshal(args, &a) // Validated above
+
+	key := storage.ResultKey{SessionID: t.sessionID, Key: a.Key}
+	if a.Action == "unpin" {
+		if err := t.store.Unpin(ctx, key); err != nil {
+			return FailureResult(err), nil
+		}
+		return SuccessResult(fmt.Sprintf("Unpinned %s", a.Key)), nil
+	}
+	if err := t.store.Pin(ctx, key); err != nil {
+		return FailureResult(err), nil
+	}
+	return SuccessResult(fmt.Sprintf("Pinned %s; it will be kept after this run", a.Key)), nil
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestPinStoredTool(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	defer store.Close()
+	_, _ = store.Store(ctx, storage.ResultKey{SessionID: "run", Key: "findings.md"}, "# Findings", storage.DefaultStoreOptions())
+	tool := NewPinStoredTool(store, "run")
+
+	if res, _ := tool.Execute(ctx, json.RawMessage(`{"key":"findings.md"}`)); !res.Success() {
+		t.Fatalf("pin failed: %+v", res)
+	}
+	res, _ := NewListStoredTool(store, "run", nil).Execute(ctx, json.RawMessage(`{}`))
+	if !strings.Contains(res.Output, "findings.md (1 lines, 10 bytes) [doc, markdown, small, pinned]") {
+		t.Errorf("list_stored should show the pin: %q", res.Output)
+	}
+
+	if res, _ := tool.Execute(ctx, json.RawMessage(`{"key":"findings.md","action":"unpin"}`)); !res.Success() {
+		t.Fatalf("unpin failed: %+v", res)
+	}
+	if meta, _ := store.GetMetadata(ctx, storage.ResultKey{SessionID: "run", Key: "findings.md"}); meta.Pinned {
+		t.Error("key still pinned after unpin")
+	}
+	if res, _ := tool.Execute(ctx, json.RawMessage(`{"key":"nope.md"}`)); res.Success() {
+		t.Error("pinning a missing key should fail")
+	}
+	if res, _ := tool.Execute(ctx, json.RawMessage(`{"key":"findings.md","action":"lock"}`)); res.Success() {
+		t.Error("unknown action should fail")
+	}
+}
@@ -0,0 +1,72 @@
+// Per-run memoization of DSA reads.
+//
+// Agents often repeat a search_stored or get_lines call after a failed line
+// of reasoning. search_stored and get_lines remember their results for the
+// life of the tool (one run) and answer repeats instantly, marked as cached
+// so the agent notices it is going in circles.
+//
+// Information Hiding:
+// - Cache keys (tool arguments after defaults are resolved) hidden
+// - Invalidation when the store changes hidden
+
+package tools
+
+import (
+	"sync"
+
+	"github.com/richinex/ariadne/storage"
+)
+
+// maxCachedReads bounds a read cache; it is cleared when full.
+const maxCachedReads = 256
+
+// cachedNote prefixes observations served from the cache.
+const cachedNote = "(cached: identical call earlier in this run, same result)\n"
+
+// readCache memoizes successful read outputs by key. Entries are dropped
+// once the store has been written to since they were cached.
+type readCache struct {
+	mu         sync.Mutex
+	entries    map[string]string
+	generation uint64
+}
+
+func newReadCache() *readCache {
+	return &readCache{entries: make(map[string]string)}
+}
+
+// get returns the cached output for key and the store generation it was
+// checked against; pass that generation to put.
+func (c *readCache) get(store *storage.ResultStore, key string) (string, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sync(store.Generation())
+	output, ok := c.entries[key]
+	if ok {
+		store.CountCachedRead()
+	}
+	return output, ok, c.generation
+}
+
+// put caches output for key, unless the store changed after generation
+// (the output may then be stale).
+func (c *readCache) put(generation uint64, store *storage.ResultStore, key, output string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if store.Generation() != generation {
+		return
+	}
+	c.sync(generation)
+	if len(c.entries) >= maxCachedReads {
+		clear(c.entries)
+	}
+	c.entries[key] = output
+}
+
+// sync clears the cache if the store generation moved. Callers hold c.mu.
+func (c *readCache) sync(generation uint64) {
+	if generation != c.generation {
+		clear(c.entries)
+		c.generation = generation
+	}
+}
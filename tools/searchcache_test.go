@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/storage"
+)
+
+func TestStoredReadsAreCachedUntilStoreChanges(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	defer store.Close()
+	content := store.SessionContent("run")
+	if _, err := content.StoreContent(ctx, model.FileKey("a.go"), "func alpha() {}\n"); err != nil {
+		t.Fatalf("StoreContent failed: %v", err)
+	}
+
+	search := NewSearchStoredTool(store, "run", nil)
+	args := json.RawMessage(`{"pattern":"func"}`)
+	first, _ := search.Execute(ctx, args)
+	second, _ := search.Execute(ctx, args)
+	if strings.HasPrefix(first.Output, cachedNote) || second.Output != cachedNote+first.Output {
+		t.Fatalf("repeat search should be served from cache:\nfirst: %q\nsecond: %q", first.Output, second.Output)
+	}
+
+	if _, err := content.StoreContent(ctx, model.FileKey("b.go"), "func beta() {}\n"); err != nil {
+		t.Fatalf("StoreContent failed: %v", err)
+	}
+	third, _ := search.Execute(ctx, args)
+	if strings.HasPrefix(third.Output, cachedNote) || !strings.Contains(third.Output, "b.go") {
+		t.Errorf("a store write should invalidate the cache, got %q", third.Output)
+	}
+
+	lines := NewGetLinesTool(store, "run", nil)
+	lineArgs := json.RawMessage(`{"key":"file:a.go","start":1,"end":1}`)
+	lines.Execute(ctx, lineArgs)
+	if again, _ := lines.Execute(ctx, lineArgs); !strings.HasPrefix(again.Output, cachedNote) {
+		t.Errorf("repeat get_lines should be served from cache, got %q", again.Output)
+	}
+
+	usage := store.Usage()
+	if usage.FilesStored != 2 || usage.Searches != 2 || usage.GetLinesCalls != 1 || usage.CacheHits != 2 {
+		t.Errorf("store usage = %+v, want 2 stored, 2 searches, 1 get_lines call, 2 cache hits", usage)
+	}
+}
@@ -81,7 +81,7 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (ToolResu
 
 	// Check command allowlist
 	if !t.isCommandAllowed(a.Command) {
-		return FailureResultf("command '%s' is not in the allowed list", a.Command), nil
+		return DeniedResultf("command '%s' is not in the allowed list", a.Command), nil
 	}
 
 	// Create timeout context
@@ -14,15 +14,24 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	jsonutil "github.com/richinex/ariadne/internal/json"
+	"github.com/richinex/ariadne/internal/text"
 	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/storage"
 )
 
+// spawnStoredListLimit caps how many inherited stored items are listed in
+// a sub-agent's prompt; the rest are reachable with list_stored.
+const spawnStoredListLimit = 20
+
 // SpawnMetrics tracks RLM execution statistics.
 type SpawnMetrics struct {
 	LLMCalls      atomic.Int64 // Total LLM API calls
@@ -30,6 +39,107 @@ type SpawnMetrics struct {
 	SubAgents     atomic.Int64 // Total sub-agents spawned
 	MaxDepthUsed  atomic.Int64 // Deepest recursion level reached
 	TotalDuration atomic.Int64 // Total execution time (nanoseconds)
+
+	// Token usage, by depth (0 = root) and by subtree of the root
+	// (top-level spawn ID, known only with a SpawnControl)
+	tokensMu  sync.Mutex
+	byDepth   map[int]llm.TokenUsage
+	bySubtree map[string]llm.TokenUsage
+}
+
+// AddTokens records one LLM call's usage at depth, in subtree ("" if
+// unknown). Nil usage (provider didn't report it) is ignored.
+func (m *SpawnMetrics) AddTokens(depth int, subtree string, usage *llm.TokenUsage) {
+	if usage == nil {
+		return
+	}
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	if m.byDepth == nil {
+		m.byDepth = make(map[int]llm.TokenUsage)
+		m.bySubtree = make(map[string]llm.TokenUsage)
+	}
+	m.byDepth[depth] = addUsage(m.byDepth[depth], *usage)
+	if subtree != "" {
+		m.bySubtree[subtree] = addUsage(m.bySubtree[subtree], *usage)
+	}
+}
+
+// Tokens returns total token usage across all depths.
+func (m *SpawnMetrics) Tokens() llm.TokenUsage {
+	var total llm.TokenUsage
+	for _, u := range m.TokensByDepth() {
+		total = addUsage(total, u)
+	}
+	return total
+}
+
+// TokensByDepth returns token usage per depth (0 = root).
+func (m *SpawnMetrics) TokensByDepth() map[int]llm.TokenUsage {
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	out := make(map[int]llm.TokenUsage, len(m.byDepth))
+	for d, u := range m.byDepth {
+		out[d] = u
+	}
+	return out
+}
+
+// TokensBySubtree returns token usage per subtree of the root, keyed by
+// the top-level spawn ID ("1", "2", ...).
+func (m *SpawnMetrics) TokensBySubtree() map[string]llm.TokenUsage {
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	out := make(map[string]llm.TokenUsage, len(m.bySubtree))
+	for id, u := range m.bySubtree {
+		out[id] = u
+	}
+	return out
+}
+
+// TokenBreakdown renders usage per depth and per subtree, one per line.
+// Returns "" when no usage was recorded.
+func (m *SpawnMetrics) TokenBreakdown() string {
+	byDepth := m.TokensByDepth()
+	if len(byDepth) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	depths := make([]int, 0, len(byDepth))
+	for d := range byDepth {
+		depths = append(depths, d)
+	}
+	sort.Ints(depths)
+	for _, d := range depths {
+		label := fmt.Sprintf("depth %d", d)
+		if d == 0 {
+			label = "root"
+		}
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", label+":", formatUsage(byDepth[d])))
+	}
+
+	bySubtree := m.TokensBySubtree()
+	ids := make([]string, 0, len(bySubtree))
+	for id := range bySubtree {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return treeLess(ids[i], ids[j]) })
+	for _, id := range ids {
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", "subtree "+id+":", formatUsage(bySubtree[id])))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func addUsage(a, b llm.TokenUsage) llm.TokenUsage {
+	a.Add(&b)
+	return a
+}
+
+func formatUsage(u llm.TokenUsage) string {
+	if u.CachedPromptTokens > 0 {
+		return fmt.Sprintf("%d tokens (%d prompt, %d of them cached, %d completion)", u.TotalTokens, u.PromptTokens, u.CachedPromptTokens, u.CompletionTokens)
+	}
+	return fmt.Sprintf("%d tokens (%d prompt, %d completion)", u.TotalTokens, u.PromptTokens, u.CompletionTokens)
 }
 
 // Add adds another metrics instance to this one.
@@ -37,6 +147,23 @@ func (m *SpawnMetrics) Add(other *SpawnMetrics) {
 	m.LLMCalls.Add(other.LLMCalls.Load())
 	m.ToolCalls.Add(other.ToolCalls.Load())
 	m.SubAgents.Add(other.SubAgents.Load())
+	for d, u := range other.TokensByDepth() {
+		m.AddTokens(d, "", &u)
+	}
+	other.tokensMu.Lock()
+	subtrees := make(map[string]llm.TokenUsage, len(other.bySubtree))
+	for id, u := range other.bySubtree {
+		subtrees[id] = u
+	}
+	other.tokensMu.Unlock()
+	m.tokensMu.Lock()
+	for id, u := range subtrees {
+		if m.bySubtree == nil {
+			m.bySubtree = make(map[string]llm.TokenUsage)
+		}
+		m.bySubtree[id] = addUsage(m.bySubtree[id], u)
+	}
+	m.tokensMu.Unlock()
 	// Update max depth if other is deeper
 	for {
 		current := m.MaxDepthUsed.Load()
@@ -54,11 +181,12 @@ func (m *SpawnMetrics) Add(other *SpawnMetrics) {
 func (m *SpawnMetrics) String() string {
 	duration := time.Duration(m.TotalDuration.Load())
 	return fmt.Sprintf(
-		"LLM calls: %d | Tool calls: %d | Sub-agents: %d | Max depth: %d | Duration: %s",
+		"LLM calls: %d | Tool calls: %d | Sub-agents: %d | Max depth: %d | Tokens: %d | Duration: %s",
 		m.LLMCalls.Load(),
 		m.ToolCalls.Load(),
 		m.SubAgents.Load(),
 		m.MaxDepthUsed.Load(),
+		m.Tokens().TotalTokens,
 		duration.Round(time.Millisecond),
 	)
 }
@@ -87,6 +215,24 @@ type SpawnConfig struct {
 	MaxDepth     int           // Maximum recursion depth (default: 5)
 	MaxIterations int           // Max iterations per agent (default: 10)
 	Timeout      time.Duration // Timeout per agent (default: 2 min)
+
+	// SubagentProvider runs sub-agents on a different (typically cheaper)
+	// model than the root. Nil uses the root provider.
+	SubagentProvider llm.Provider
+	// DepthProviders picks a provider by sub-agent depth (1 = spawned by
+	// the root). A sub-agent uses the entry for the deepest level at or
+	// above its own, so {2: cheapest} runs depth 2 and below on cheapest.
+	// Takes precedence over SubagentProvider.
+	DepthProviders map[int]llm.Provider
+	// Tune sets depth, iteration and timeout limits per spawn from the
+	// task's estimated complexity (e.g. AdaptiveSpawnTuner). Nil uses the
+	// limits above for every spawn.
+	Tune SpawnTuner
+	// StoreWrite lets sub-agents' tools store, pin and delete in the
+	// parent's result store. By default sub-agents get a read-only view
+	// (see storage.WithReadOnly): they can search and read stored content
+	// but can't clobber the parent's keys.
+	StoreWrite bool
 }
 
 // DefaultSpawnConfig returns default spawn configuration.
@@ -101,13 +247,21 @@ func DefaultSpawnConfig() SpawnConfig {
 // SpawnAgentTool enables recursive sub-agent spawning.
 // Implements the RLM pattern where any agent can spawn sub-agents.
 type SpawnAgentTool struct {
-	provider         llm.Provider
-	subagentProvider llm.Provider // Optional: different provider for sub-agents (cost optimization)
+	provider         llm.Provider // Root provider
 	config           SpawnConfig
 	toolConfig       ToolConfig
 	depth            int  // Current recursion depth
 	verbose          bool // Print debug output
 	metrics          *SpawnMetrics // Metrics tracking
+	observations     *ObservationBudget // Caps tool output size (nil = truncate at toolConfig limit)
+
+	// Parent's stored content, listed for sub-agents so they don't re-read it
+	store       *storage.ResultStore
+	sessionID   string
+	fileContext *StoredFileContext
+
+	control *SpawnControl // Optional: lets running subtrees be cancelled by ID
+	id      string        // Tree ID of the sub-agent owning this tool ("" = root)
 
 	// Tools available to spawned agents (includes this tool for recursion)
 	availableTools []Tool
@@ -130,13 +284,71 @@ func (t *SpawnAgentTool) WithTools(tools []Tool) *SpawnAgentTool {
 	return t
 }
 
-// WithSubagentProvider sets a different provider for sub-agents.
-// If set, depth > 0 agents will use this provider instead of the root provider.
+// WithSubagentProvider sets a different provider for sub-agents
+// (SpawnConfig.SubagentProvider).
 // Accepts nil safely (no-op) to enable clean fluent chains.
 func (t *SpawnAgentTool) WithSubagentProvider(provider llm.Provider) *SpawnAgentTool {
 	if provider != nil {
-		t.subagentProvider = provider
+		t.config.SubagentProvider = provider
+	}
+	return t
+}
+
+// WithDepthProvider runs sub-agents at depth and below on provider
+// (SpawnConfig.DepthProviders). Accepts nil safely (no-op).
+func (t *SpawnAgentTool) WithDepthProvider(depth int, provider llm.Provider) *SpawnAgentTool {
+	if provider == nil {
+		return t
+	}
+	providers := make(map[int]llm.Provider, len(t.config.DepthProviders)+1)
+	for d, p := range t.config.DepthProviders {
+		providers[d] = p
+	}
+	providers[depth] = provider
+	t.config.DepthProviders = providers
+	return t
+}
+
+// providerFor picks the provider for a sub-agent at depth.
+func (t *SpawnAgentTool) providerFor(depth int) llm.Provider {
+	best := 0
+	var provider llm.Provider
+	for d, p := range t.config.DepthProviders {
+		if d <= depth && d > best && p != nil {
+			best, provider = d, p
+		}
+	}
+	if provider != nil {
+		return provider
+	}
+	if t.config.SubagentProvider != nil {
+		return t.config.SubagentProvider
 	}
+	return t.provider
+}
+
+// WithObservationBudget caps tool observations in sub-agent conversations.
+// Share the parent's budget so overflow lands in the same ResultStore session.
+func (t *SpawnAgentTool) WithObservationBudget(budget *ObservationBudget) *SpawnAgentTool {
+	t.observations = budget
+	return t
+}
+
+// WithResultStore tells sub-agents what the parent has already stored in
+// sessionID, most recently used files first, so they can go straight to
+// search_stored/get_lines. The DSA tools in WithTools should share the
+// same session and file context.
+func (t *SpawnAgentTool) WithResultStore(store *storage.ResultStore, sessionID string, fileContext *StoredFileContext) *SpawnAgentTool {
+	t.store = store
+	t.sessionID = sessionID
+	t.fileContext = fileContext
+	return t
+}
+
+// WithControl registers every sub-agent in control, so a running subtree
+// can be listed and cancelled by ID while the rest of the run continues.
+func (t *SpawnAgentTool) WithControl(control *SpawnControl) *SpawnAgentTool {
+	t.control = control
 	return t
 }
 
@@ -148,20 +360,18 @@ func (t *SpawnAgentTool) Verbose(v bool) *SpawnAgentTool {
 
 // atDepth creates a child spawn tool at deeper recursion level.
 func (t *SpawnAgentTool) atDepth(depth int) *SpawnAgentTool {
-	// Use subagent provider for depth > 0 if configured (cost optimization)
-	providerToUse := t.provider
-	if depth > 0 && t.subagentProvider != nil {
-		providerToUse = t.subagentProvider
-	}
-
 	child := &SpawnAgentTool{
-		provider:         providerToUse,
-		subagentProvider: t.subagentProvider,
+		provider:         t.provider,
 		config:           t.config,
 		toolConfig:       t.toolConfig,
 		depth:            depth,
 		verbose:          t.verbose,
 		metrics:          t.metrics,
+		observations:     t.observations,
+		store:            t.store,
+		sessionID:        t.sessionID,
+		fileContext:      t.fileContext,
+		control:          t.control,
 		availableTools:   t.availableTools,
 	}
 	return child
@@ -171,7 +381,8 @@ func (t *SpawnAgentTool) Metadata() ToolMetadata {
 	return ToolMetadata{
 		Name: "spawn",
 		Description: `Spawn a sub-agent to handle a specific task. The sub-agent executes independently,
-returns only the answer (not raw content), and terminates. Use this for:
+returns only the answer (not raw content), and terminates. The result is JSON: answer, confidence
+(0-1), references to stored keys/lines backing it, and follow_ups. Use this for:
 - Reading and summarizing specific documents/sections
 - Performing searches across files
 - Any subtask that would otherwise bloat your context
@@ -209,32 +420,71 @@ func (t *SpawnAgentTool) Execute(ctx context.Context, args json.RawMessage) (Too
 	var a spawnArgs
 	_ = json.Unmarshal(args, &a) // validated above
 
+	result, err := t.spawn(ctx, a)
+	if err != nil {
+		return FailureResult(err), nil
+	}
+	return SuccessResult(result.String()), nil
+}
+
+// spawn runs one sub-agent on a validated task.
+func (t *SpawnAgentTool) spawn(ctx context.Context, a spawnArgs) (result SubAgentResult, err error) {
 	// Check recursion depth
 	if t.depth >= t.config.MaxDepth {
-		return FailureResultf("maximum recursion depth (%d) reached", t.config.MaxDepth), nil
+		return SubAgentResult{}, fmt.Errorf("maximum recursion depth (%d) reached", t.config.MaxDepth)
 	}
 
-	// Create timeout context for this agent
-	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
-	defer cancel()
-
 	// Build the sub-agent prompt
 	prompt := a.Task
 	if a.Context != "" {
 		prompt = fmt.Sprintf("%s\n\nContext:\n%s", a.Task, a.Context)
 	}
 
+	// Size this spawn's limits to its task
+	limits := t.config
+	if t.config.Tune != nil {
+		limits = t.config.Tune(EstimateComplexity(prompt), t.depth+1, t.config)
+		limits.MaxDepth = min(limits.MaxDepth, t.config.MaxDepth)
+	}
+
+	// Register with run control so this subtree can be cancelled on its own
+	var id string
+	if t.control != nil {
+		var done func(error)
+		id, ctx, done = t.control.start(ctx, t.id, t.depth+1, prompt)
+		defer func() { done(err) }()
+		if t.verbose {
+			fmt.Printf("  [sub:%d] spawned %s: %s\n", t.depth+1, id, shortTask(prompt))
+		}
+	}
+
+	// Create timeout context for this agent
+	ctx, cancel := context.WithTimeout(ctx, limits.Timeout)
+	defer cancel()
+
 	// Run the sub-agent
-	result, err := t.runSubAgent(ctx, prompt)
+	result, err = t.runSubAgent(ctx, prompt, limits, id)
 	if err != nil {
-		return FailureResult(fmt.Errorf("sub-agent failed: %w", err)), nil
+		if cause := context.Cause(ctx); errors.Is(cause, ErrSubtreeCancelled) {
+			err = cause // Say why, rather than a bare "context canceled"
+		}
+		return SubAgentResult{}, fmt.Errorf("sub-agent failed: %w", err)
 	}
+	return result, nil
+}
 
-	return SuccessResult(result), nil
+// subAgentName names a sub-agent in the audit log by its tree ID, or its
+// depth when spawned without run control.
+func subAgentName(id string, depth int) string {
+	if id == "" {
+		return fmt.Sprintf("sub-agent (depth %d)", depth)
+	}
+	return "sub-agent " + id
 }
 
-// runSubAgent creates and executes a sub-agent.
-func (t *SpawnAgentTool) runSubAgent(ctx context.Context, task string) (string, error) {
+// runSubAgent creates and executes a sub-agent within limits. id is its
+// tree ID under run control ("" without one).
+func (t *SpawnAgentTool) runSubAgent(ctx context.Context, task string, limits SpawnConfig, id string) (SubAgentResult, error) {
 	// Track metrics
 	if t.metrics != nil {
 		t.metrics.SubAgents.Add(1)
@@ -251,9 +501,17 @@ func (t *SpawnAgentTool) runSubAgent(ctx context.Context, task string) (string,
 		}
 	}
 
-	// Build tool set for sub-agent (includes spawn tool at deeper depth)
+	observations := t.observations
+	if observations == nil {
+		observations = NewObservationBudget(t.toolConfig.ObservationLimit())
+	}
+
+	// Build tool set for sub-agent (includes spawn tool at deeper depth and
+	// next_page for output its observation budget paged)
 	childSpawn := t.atDepth(t.depth + 1)
-	tools := append([]Tool{childSpawn}, t.availableTools...)
+	childSpawn.config.MaxDepth = limits.MaxDepth // Caps the whole subtree
+	childSpawn.id = id
+	tools := t.toolConfig.Filter.Apply(append([]Tool{childSpawn, NewNextPageTool(observations)}, t.availableTools...))
 
 	// Build tool map for lookup
 	toolMap := make(map[string]Tool)
@@ -268,17 +526,15 @@ IMPORTANT: You MUST use tools to complete tasks. Do not just respond with text.
 
 Available tools: %s
 
-AUTOMATIC STORAGE:
-- ripgrep auto-stores files with matches (use DSA tools after)
-- read_file also stores files for DSA search
-- Use search_stored/get_lines/list_stored to query stored content
+%s
 
 Instructions:
 1. Use the appropriate tool to complete your task
 2. After getting results, summarize the answer concisely
-3. Return a clear, direct answer (not raw data)
+3. Return a clear, direct answer (not raw data) in the format below
 
-Example: If asked "what does file.go do?", use read_file to read it, then explain its purpose.`, t.depth+1, t.config.MaxDepth, toolNames(tools))
+Example: If asked "what does file.go do?", use read_file to read it, then explain its purpose.`, t.depth+1, limits.MaxDepth, toolNames(tools), t.storageNote())
+	systemPrompt += subAgentContract + IngestedContentRule + t.inheritedContent(ctx)
 
 	messages := []llm.ChatMessage{
 		{Role: "system", Content: systemPrompt},
@@ -287,52 +543,86 @@ Example: If asked "what does file.go do?", use read_file to read it, then explai
 
 	// Create executor for tool calls
 	executor := NewExecutor(t.toolConfig)
+	stall := NewStallDetector()
+
+	// The sub-agent runs one level below this tool
+	provider := t.providerFor(t.depth + 1)
+
+	// Token usage of this sub-agent's own calls, for metrics and the trace
+	subtree, _, _ := strings.Cut(id, ".")
+	var used llm.TokenUsage
+	if t.verbose {
+		defer func() {
+			fmt.Printf("  [sub:%d] finished %s: %s\n", t.depth+1, id, formatUsage(used))
+		}()
+	}
 
 	// Run ReAct loop
-	for i := 0; i < t.config.MaxIterations; i++ {
+	repairs := 0
+	for i := 0; i < limits.MaxIterations; i++ {
 		if ctx.Err() != nil {
-			return "", ctx.Err()
+			return SubAgentResult{}, ctx.Err()
 		}
 
 		if t.verbose {
 			fmt.Printf("  [sub:%d:%d] Processing...\n", t.depth+1, i)
 		}
 
+		// At the wrap-up threshold, ask for the answer instead of more calls
+		remaining := limits.MaxIterations - i
+		forced := t.toolConfig.WrapUp.ForcesAnswer(remaining)
+		if forced {
+			messages = withWrapUpNote(messages, t.toolConfig.WrapUp.SynthesisPrompt())
+		}
+
 		// Call LLM
-		response, err := t.provider.ChatWithTools(ctx, messages, convertToLLMTools(tools))
+		response, err := llm.ChatWithTools(ctx, provider, messages, convertToLLMTools(tools))
 		if t.metrics != nil {
 			t.metrics.LLMCalls.Add(1)
+			t.metrics.AddTokens(t.depth+1, subtree, response.Usage)
 		}
 		if err != nil {
-			return "", fmt.Errorf("LLM call failed: %w", err)
+			return SubAgentResult{}, fmt.Errorf("LLM call failed: %w", err)
+		}
+		if response.Usage != nil {
+			used = addUsage(used, *response.Usage)
 		}
 
 		if t.verbose && response.Content != "" {
-			content := response.Content
-			if len(content) > 100 {
-				content = content[:100] + "..."
-			}
+			content := text.Truncate(response.Content, 100)
 			fmt.Printf("  [sub:%d:%d] %s\n", t.depth+1, i, content)
 		}
 
-		// Check if there are tool calls
-		if len(response.ToolCalls) == 0 {
-			// No tool calls - this is the final answer
+		// Check if there are tool calls (a synthesis step's are not run)
+		if len(response.ToolCalls) == 0 || forced {
+			// No tool calls - this is the final answer. Empty replies were
+			// retried by ChatWithTools, so only a synthesis step that made
+			// tool calls instead of answering gets here without content.
 			if response.Content == "" {
 				if t.verbose {
-					fmt.Printf("  [sub:%d:%d] Empty response, no tool calls\n", t.depth+1, i)
+					fmt.Printf("  [sub:%d:%d] No answer on the synthesis step\n", t.depth+1, i)
 				}
-				return "(sub-agent returned empty response)", nil
+				return freeTextResult("(sub-agent returned empty response)"), nil
+			}
+			result, err := t.parseSubAgentResult(ctx, response.Content)
+			if err == nil {
+				return result, nil
 			}
-			return response.Content, nil
+			if repairs == maxSubAgentRepairs || i+1 == limits.MaxIterations || forced {
+				return freeTextResult(response.Content), nil
+			}
+			// Ask for the answer in the agreed format before giving up on it
+			repairs++
+			messages = append(messages,
+				llm.ChatMessage{Role: "assistant", Content: response.Content},
+				llm.ChatMessage{Role: "user", Content: jsonutil.RepairPrompt(err)},
+			)
+			continue
 		}
 
 		if t.verbose {
 			for _, tc := range response.ToolCalls {
-				args := string(tc.Arguments)
-				if len(args) > 100 {
-					args = args[:100] + "..."
-				}
+				args := text.Truncate(string(tc.Arguments), 100)
 				fmt.Printf("  [sub:%d:%d] Calling: %s(%s)\n", t.depth+1, i, tc.Name, args)
 			}
 		}
@@ -356,10 +646,18 @@ Example: If asked "what does file.go do?", use read_file to read it, then explai
 				continue
 			}
 
-			result, err := executor.Execute(ctx, tool, tc.Arguments)
+			toolCtx := WithAgent(ctx, subAgentName(id, t.depth+1))
+			if !t.config.StoreWrite && tool != Tool(childSpawn) {
+				// The child's own sub-agents are restricted in turn
+				toolCtx = storage.WithReadOnly(toolCtx)
+			}
+			result, err := executor.Execute(toolCtx, tool, tc.Arguments)
 			if t.metrics != nil {
 				t.metrics.ToolCalls.Add(1)
 			}
+			if err == nil {
+				err = result.Error // A failed call, e.g. a write denied by the read-only store
+			}
 			if err != nil {
 				messages = append(messages, llm.ChatMessage{
 					Role:       "tool",
@@ -373,15 +671,85 @@ Example: If asked "what does file.go do?", use read_file to read it, then explai
 			if output == "" {
 				output = "(empty result)"
 			}
+			output = observations.Apply(ctx, tc.Name, output)
 			messages = append(messages, llm.ChatMessage{
 				Role:       "tool",
 				Content:    output,
 				ToolCallID: tc.ID,
 			})
 		}
+
+		// Stop or nudge the sub-agent if it keeps repeating itself
+		if err := stall.CheckToolRound(response.ToolCalls, messages); err != nil {
+			return SubAgentResult{}, err
+		}
+		if warning := t.toolConfig.WrapUp.Warning(remaining); warning != "" {
+			messages = withWrapUpNote(messages, warning)
+		}
+	}
+
+	return SubAgentResult{}, fmt.Errorf("sub-agent reached max iterations without completing")
+}
+
+// withWrapUpNote returns a copy of messages with note appended to the last
+// message, so it reaches the model without a turn of its own.
+func withWrapUpNote(messages []llm.ChatMessage, note string) []llm.ChatMessage {
+	messages = append([]llm.ChatMessage(nil), messages...)
+	messages[len(messages)-1].Content += "\n\n" + note
+	return messages
+}
+
+// storageNote tells a sub-agent how it can use the result store.
+func (t *SpawnAgentTool) storageNote() string {
+	if t.config.StoreWrite {
+		return `AUTOMATIC STORAGE:
+- ripgrep and grep_files auto-store files with matches (use DSA tools after)
+- read_file also stores files for DSA search
+- Use search_stored/get_lines/list_stored to query stored content`
+	}
+	return `STORED CONTENT (read-only):
+- Use search_stored/get_lines/list_stored to query what the parent stored
+- You can't store, pin or delete stored content; read_file returns file content directly`
+}
+
+// inheritedContent lists the content already stored in the parent's
+// session for the sub-agent's prompt. Returns "" when there is none.
+func (t *SpawnAgentTool) inheritedContent(ctx context.Context) string {
+	if t.store == nil {
+		return ""
+	}
+	stored, err := t.store.List(ctx, t.sessionID, storage.QueryOptions{})
+	if err != nil || len(stored) == 0 {
+		return ""
+	}
+
+	// Files the parent tracked most recently first, then by last access
+	recent := make(map[string]int)
+	if t.fileContext != nil {
+		for i, key := range t.fileContext.List() {
+			recent[key] = i + 1
+		}
 	}
+	sort.SliceStable(stored, func(i, j int) bool {
+		ri, rj := recent[stored[i].Key.Key], recent[stored[j].Key.Key]
+		if (ri > 0) != (rj > 0) {
+			return ri > 0
+		}
+		if ri > 0 {
+			return ri < rj
+		}
+		return stored[i].AccessedAt.After(stored[j].AccessedAt)
+	})
 
-	return "", fmt.Errorf("sub-agent reached max iterations without completing")
+	lines := make([]string, 0, min(len(stored), spawnStoredListLimit)+1)
+	for i, meta := range stored {
+		if i == spawnStoredListLimit {
+			lines = append(lines, fmt.Sprintf("- ... and %d more (use list_stored)", len(stored)-spawnStoredListLimit))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("- %s (%d lines)", meta.Key.Key, meta.LineCount))
+	}
+	return "\n\nALREADY STORED by the parent (use search_stored/get_lines with these keys; do not re-read them):\n" + strings.Join(lines, "\n")
 }
 
 // toolNames returns comma-separated list of tool names.
@@ -445,7 +813,8 @@ func (t *ParallelSpawnTool) Metadata() ToolMetadata {
 		Name: "parallel_spawn",
 		Description: `Spawn multiple sub-agents in parallel. Each sub-agent executes independently and
 returns its answer. Use this when you have multiple independent tasks that can run concurrently.
-Returns a JSON object mapping task indices to their results.`,
+Returns a JSON object mapping task indices ("task_0", ...) to their results (answer, confidence,
+references, follow_ups) or errors.`,
 		Parameters: []ToolParameter{
 			{Name: "tasks", ParamType: "array", Description: "Array of task objects, each with 'task' and optional 'context' fields", Required: true, Items: map[string]interface{}{"type": "object"}},
 		},
@@ -482,7 +851,7 @@ func (t *ParallelSpawnTool) Execute(ctx context.Context, args json.RawMessage) (
 
 	type taskResult struct {
 		index  int
-		result string
+		result SubAgentResult
 		err    error
 	}
 
@@ -498,27 +867,15 @@ func (t *ParallelSpawnTool) Execute(ctx context.Context, args json.RawMessage) (
 				return
 			}
 
-			taskArgs, err := json.Marshal(taskArg)
-			if err != nil {
-				results <- taskResult{index: idx, err: fmt.Errorf("failed to marshal task: %w", err)}
-				return
-			}
-
-			res, err := t.spawnTool.Execute(ctx, taskArgs)
-			if err != nil {
-				results <- taskResult{index: idx, err: err}
-			} else if !res.Success() {
-				results <- taskResult{index: idx, err: fmt.Errorf("%s", res.Output)}
-			} else {
-				results <- taskResult{index: idx, result: res.Output}
-			}
+			res, err := t.spawnTool.spawn(ctx, taskArg)
+			results <- taskResult{index: idx, result: res, err: err}
 		}(i, task)
 	}
 
 	// Collect exactly len(a.Tasks) results
 	type TaskOutput struct {
-		Result string `json:"result,omitempty"`
-		Error  string `json:"error,omitempty"`
+		Result *SubAgentResult `json:"result,omitempty"`
+		Error  string          `json:"error,omitempty"`
 	}
 	output := make(map[string]TaskOutput)
 
@@ -535,7 +892,7 @@ func (t *ParallelSpawnTool) Execute(ctx context.Context, args json.RawMessage) (
 			if r.err != nil {
 				output[key] = TaskOutput{Error: r.err.Error()}
 			} else {
-				output[key] = TaskOutput{Result: r.result}
+				output[key] = TaskOutput{Result: &r.result}
 			}
 		}
 	}
@@ -3,9 +3,13 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/storage"
 	"go.uber.org/goleak"
 )
 
@@ -237,6 +241,132 @@ func TestParallelSpawnCancellation(t *testing.T) {
 	}
 }
 
+// promptCapture answers immediately and remembers the system prompt.
+type promptCapture struct {
+	system string
+}
+
+func (p *promptCapture) Name() string  { return "capture" }
+func (p *promptCapture) Model() string { return "capture" }
+
+func (p *promptCapture) Chat(ctx context.Context, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	p.system = messages[0].Content
+	return llm.LLMResponse{Content: "done"}, nil
+}
+
+func (p *promptCapture) ChatWithFormat(ctx context.Context, messages []llm.ChatMessage, format *llm.ResponseFormat) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *promptCapture) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	return p.Chat(ctx, messages)
+}
+
+func (p *promptCapture) StreamChat(ctx context.Context, messages []llm.ChatMessage, chunks chan<- string) (*llm.TokenUsage, error) {
+	return nil, nil
+}
+
+func TestSpawnInheritsParentStoredContent(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	defer store.Close()
+	files := NewStoredFileContext()
+	for _, path := range []string{"a.go", "b.go"} {
+		if _, err := store.SessionContent("run").StoreContent(ctx, model.FileKey(path), "package x\n"); err != nil {
+			t.Fatalf("StoreContent failed: %v", err)
+		}
+		files.Add(path)
+	}
+
+	provider := &promptCapture{}
+	tool := NewSpawnAgentTool(provider, DefaultSpawnConfig(), ToolConfig{}).
+		WithResultStore(store, "run", files)
+	result, err := tool.Execute(ctx, json.RawMessage(`{"task":"summarize b.go"}`))
+	if err != nil || !result.Success() {
+		t.Fatalf("spawn failed: %+v, %v", result, err)
+	}
+
+	_, listed, ok := strings.Cut(provider.system, "ALREADY STORED")
+	if !ok {
+		t.Fatalf("sub-agent prompt does not list stored content:\n%s", provider.system)
+	}
+	if b, a := strings.Index(listed, "- b.go ("), strings.Index(listed, "- a.go"); b < 0 || a < b {
+		t.Errorf("expected most recent file first:\n%s", listed)
+	}
+}
+
+func TestSpawnProviderByDepth(t *testing.T) {
+	root, cheap, cheapest := &promptCapture{}, &promptCapture{}, &promptCapture{}
+	tool := NewSpawnAgentTool(root, DefaultSpawnConfig(), ToolConfig{})
+	if tool.providerFor(1) != root {
+		t.Error("without overrides sub-agents should use the root provider")
+	}
+
+	tool = tool.WithSubagentProvider(cheap).WithDepthProvider(3, cheapest)
+	for depth, want := range map[int]llm.Provider{1: cheap, 2: cheap, 3: cheapest, 4: cheapest} {
+		if got := tool.atDepth(depth - 1).providerFor(depth); got != want {
+			t.Errorf("depth %d: got provider %p, want %p", depth, got, want)
+		}
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"task":"t"}`)); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if root.system != "" || cheap.system == "" {
+		t.Error("direct children should run on the sub-agent provider")
+	}
+}
+
+// usageProvider answers immediately and reports fixed token usage.
+type usageProvider struct {
+	promptCapture
+}
+
+func (p *usageProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	return llm.LLMResponse{
+		Content: `{"answer": "done", "confidence": 1}`,
+		Usage:   &llm.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func TestSpawnMetricsTokens(t *testing.T) {
+	metrics := ResetMetrics()
+	metrics.AddTokens(0, "", &llm.TokenUsage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120})
+
+	tool := NewSpawnAgentTool(&usageProvider{}, DefaultSpawnConfig(), ToolConfig{}).WithControl(NewSpawnControl())
+	for range 2 {
+		if _, err := tool.Execute(context.Background(), json.RawMessage(`{"task":"t"}`)); err != nil {
+			t.Fatalf("Execute returned error: %v", err)
+		}
+	}
+
+	if total := metrics.Tokens(); total.TotalTokens != 150 || total.PromptTokens != 120 {
+		t.Errorf("total usage = %+v, want 150 tokens (120 prompt)", total)
+	}
+	if depth1 := metrics.TokensByDepth()[1]; depth1.TotalTokens != 30 {
+		t.Errorf("depth 1 usage = %+v, want 30 tokens", depth1)
+	}
+	subtrees := metrics.TokensBySubtree()
+	if len(subtrees) != 2 || subtrees["1"].TotalTokens != 15 || subtrees["2"].TotalTokens != 15 {
+		t.Errorf("unexpected subtree usage: %+v", subtrees)
+	}
+	if !strings.Contains(metrics.String(), "Tokens: 150") {
+		t.Errorf("String() missing tokens, got: %s", metrics.String())
+	}
+	breakdown := metrics.TokenBreakdown()
+	for _, want := range []string{"root:", "depth 1:", "30 tokens (20 prompt, 10 completion)", "subtree 2:"} {
+		if !strings.Contains(breakdown, want) {
+			t.Errorf("breakdown missing %q:\n%s", want, breakdown)
+		}
+	}
+
+	merged := &SpawnMetrics{}
+	merged.Add(metrics)
+	if merged.Tokens().TotalTokens != 150 || merged.TokensBySubtree()["1"].TotalTokens != 15 {
+		t.Errorf("Add should merge token usage: %+v", merged.TokensByDepth())
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -249,3 +379,52 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// pinProvider has the sub-agent pin a key, then answers with the result of
+// that call.
+type pinProvider struct {
+	promptCapture
+	observed string
+}
+
+func (p *pinProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	last := messages[len(messages)-1]
+	if last.Role != "tool" {
+		p.system = messages[0].Content
+		return llm.LLMResponse{ToolCalls: []llm.ToolCall{
+			{ID: "1", Name: "pin_stored", Arguments: json.RawMessage(`{"key":"spec.md"}`)},
+		}}, nil
+	}
+	p.observed = last.Content
+	return llm.LLMResponse{Content: `{"answer": "done", "confidence": 1}`}, nil
+}
+
+func TestSpawnStoreIsReadOnlyByDefault(t *testing.T) {
+	ctx := context.Background()
+	for _, storeWrite := range []bool{false, true} {
+		store := storage.NewInMemoryResultStore()
+		key := storage.ResultKey{SessionID: "run", Key: "spec.md"}
+		if _, err := store.Store(ctx, key, "the task", storage.DefaultStoreOptions()); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+
+		config := DefaultSpawnConfig()
+		config.StoreWrite = storeWrite
+		provider := &pinProvider{}
+		tool := NewSpawnAgentTool(provider, config, ToolConfig{}).
+			WithTools([]Tool{NewPinStoredTool(store, "run")}).
+			WithResultStore(store, "run", nil)
+		if _, err := tool.Execute(ctx, json.RawMessage(`{"task":"pin the spec"}`)); err != nil {
+			t.Fatalf("Execute returned error: %v", err)
+		}
+
+		meta, _ := store.GetMetadata(ctx, key)
+		if meta.Pinned != storeWrite {
+			t.Errorf("StoreWrite=%v: pinned = %v (sub-agent saw %q)", storeWrite, meta.Pinned, provider.observed)
+		}
+		if !storeWrite && (!strings.Contains(provider.observed, "read-only") || !strings.Contains(provider.system, "STORED CONTENT (read-only)")) {
+			t.Errorf("read-only sub-agent should be told so; saw %q", provider.observed)
+		}
+		store.Close()
+	}
+}
@@ -0,0 +1,152 @@
+// Run control for spawn trees.
+//
+// Every sub-agent started through a SpawnAgentTool with a SpawnControl
+// gets a tree ID: "1", "2" for children of the root, "1.1", "1.2" for
+// theirs, and so on. Cancelling an ID stops that sub-agent and everything
+// below it; its parent sees a failed spawn and the rest of the run goes on.
+//
+// Finished sub-agents are kept, with how long they ran and how they ended,
+// so the whole tree can be reported after the run.
+//
+// Information Hiding:
+// - ID allocation per parent hidden
+// - Context wiring (subtrees inherit their parent's cancellation) hidden
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/richinex/ariadne/internal/text"
+)
+
+// ErrSubtreeCancelled is the cause given to a cancelled spawn subtree.
+var ErrSubtreeCancelled = errors.New("spawn subtree cancelled")
+
+// SpawnInfo describes a running sub-agent.
+type SpawnInfo struct {
+	ID      string
+	Depth   int
+	Task    string // First line of the task, shortened
+	Started time.Time
+}
+
+// SpawnRecord describes a sub-agent that has finished.
+type SpawnRecord struct {
+	SpawnInfo
+	Duration time.Duration
+	Err      string // Empty if it answered
+}
+
+// SpawnControl tracks running sub-agents so a subtree can be stopped by
+// ID. Safe for concurrent use.
+type SpawnControl struct {
+	mu       sync.Mutex
+	running  map[string]*runningSpawn
+	finished []SpawnRecord
+	children map[string]int // Parent ID -> children started so far
+}
+
+type runningSpawn struct {
+	info   SpawnInfo
+	cancel context.CancelCauseFunc
+}
+
+// NewSpawnControl creates an empty control.
+func NewSpawnControl() *SpawnControl {
+	return &SpawnControl{
+		running:  make(map[string]*runningSpawn),
+		children: make(map[string]int),
+	}
+}
+
+// start registers a sub-agent under parentID ("" for the root) and returns
+// its ID, a context that Cancel stops, and a function to call with its
+// error, if any, when it ends.
+func (c *SpawnControl) start(ctx context.Context, parentID string, depth int, task string) (string, context.Context, func(error)) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	c.mu.Lock()
+	c.children[parentID]++
+	id := strconv.Itoa(c.children[parentID])
+	if parentID != "" {
+		id = parentID + "." + id
+	}
+	info := SpawnInfo{ID: id, Depth: depth, Task: shortTask(task), Started: time.Now()}
+	c.running[id] = &runningSpawn{info: info, cancel: cancel}
+	c.mu.Unlock()
+
+	return id, ctx, func(err error) {
+		record := SpawnRecord{SpawnInfo: info, Duration: time.Since(info.Started)}
+		if err != nil {
+			record.Err = err.Error()
+		}
+		c.mu.Lock()
+		delete(c.running, id)
+		c.finished = append(c.finished, record)
+		c.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Running lists the sub-agents still running, in tree order.
+func (c *SpawnControl) Running() []SpawnInfo {
+	c.mu.Lock()
+	infos := make([]SpawnInfo, 0, len(c.running))
+	for _, r := range c.running {
+		infos = append(infos, r.info)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return treeLess(infos[i].ID, infos[j].ID) })
+	return infos
+}
+
+// Finished lists the sub-agents that have ended, in tree order.
+func (c *SpawnControl) Finished() []SpawnRecord {
+	c.mu.Lock()
+	records := append([]SpawnRecord(nil), c.finished...)
+	c.mu.Unlock()
+
+	sort.Slice(records, func(i, j int) bool { return treeLess(records[i].ID, records[j].ID) })
+	return records
+}
+
+// Cancel stops the sub-agent with the given ID and its whole subtree.
+// Returns an error if no such sub-agent is running.
+func (c *SpawnControl) Cancel(id string) error {
+	c.mu.Lock()
+	r, ok := c.running[id]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no running sub-agent %q", id)
+	}
+	r.cancel(fmt.Errorf("%w: %s", ErrSubtreeCancelled, id))
+	return nil
+}
+
+// treeLess orders IDs like "1.2" < "1.10" < "2".
+func treeLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// shortTask returns the first line of task, capped for listings.
+func shortTask(task string) string {
+	line, _, _ := strings.Cut(text.NormalizeNewlines(task), "\n")
+	return text.Clip(line, 80)
+}
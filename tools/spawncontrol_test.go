@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/richinex/ariadne/llm"
+)
+
+// blockingProvider waits for its context to end.
+type blockingProvider struct {
+	promptCapture
+}
+
+func (p *blockingProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	<-ctx.Done()
+	return llm.LLMResponse{}, ctx.Err()
+}
+
+func TestSpawnControlIDs(t *testing.T) {
+	control := NewSpawnControl()
+	ctx := context.Background()
+
+	first, parentCtx, endFirst := control.start(ctx, "", 1, "first task\nmore detail")
+	second, _, endSecond := control.start(ctx, "", 1, "second")
+	child, childCtx, endChild := control.start(parentCtx, first, 2, "child")
+	defer endSecond(nil)
+	defer endChild(nil)
+	if first != "1" || second != "2" || child != "1.1" {
+		t.Fatalf("unexpected IDs: %s, %s, %s", first, second, child)
+	}
+
+	running := control.Running()
+	if len(running) != 3 || running[1].ID != "1.1" || running[0].Task != "first task" {
+		t.Fatalf("unexpected running list: %+v", running)
+	}
+
+	if err := control.Cancel("1"); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if childCtx.Err() == nil {
+		t.Error("cancelling a sub-agent should cancel its subtree")
+	}
+	endFirst(ErrSubtreeCancelled)
+	if err := control.Cancel("1"); err == nil {
+		t.Error("expected an error for a finished sub-agent")
+	}
+	if finished := control.Finished(); len(finished) != 1 || finished[0].ID != "1" || finished[0].Err != ErrSubtreeCancelled.Error() {
+		t.Errorf("unexpected finished list: %+v", finished)
+	}
+}
+
+func TestSpawnControlCancelsOneSubtree(t *testing.T) {
+	control := NewSpawnControl()
+	tool := NewSpawnAgentTool(&blockingProvider{}, DefaultSpawnConfig(), ToolConfig{}).WithControl(control)
+
+	results := make(chan ToolResult, 1)
+	go func() {
+		res, _ := tool.Execute(context.Background(), json.RawMessage(`{"task":"loop forever"}`))
+		results <- res
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(control.Running()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("sub-agent never registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := control.Cancel("1"); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	res := <-results
+	if res.Success() || res.Error == nil || !strings.Contains(res.Error.Error(), "spawn subtree cancelled: 1") {
+		t.Errorf("expected a cancelled spawn, got %+v", res)
+	}
+	if len(control.Running()) != 0 {
+		t.Error("finished sub-agents should be unregistered")
+	}
+}
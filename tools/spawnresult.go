@@ -0,0 +1,97 @@
+// Structured sub-agent answers.
+//
+// Sub-agents finish with a SubAgentResult instead of free text: the
+// answer, how confident they are, which stored content backs it, and what
+// is worth following up. spawn and parallel_spawn return it as JSON, so
+// the parent can weigh and cross-check answers rather than re-deriving
+// them from prose.
+//
+// Information Hiding:
+// - Contract wording in the sub-agent prompt hidden
+// - Validation against the result store and free-text fallback hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	jsonutil "github.com/richinex/ariadne/internal/json"
+	"github.com/richinex/ariadne/storage"
+)
+
+// maxSubAgentRepairs is how many times a sub-agent is asked to fix a final
+// answer that breaks the contract before it is taken as free text.
+const maxSubAgentRepairs = 1
+
+// SubAgentResult is what a spawned sub-agent returns to its parent.
+type SubAgentResult struct {
+	Answer     string              `json:"answer"`
+	Confidence float64             `json:"confidence"` // 0 (guess) to 1 (verified)
+	References []SubAgentReference `json:"references,omitempty"`
+	FollowUps  []string            `json:"follow_ups,omitempty"`
+	// Unstructured marks an answer the sub-agent gave as free text;
+	// Confidence is then unknown.
+	Unstructured bool `json:"unstructured,omitempty"`
+}
+
+// SubAgentReference points at stored content backing an answer.
+type SubAgentReference struct {
+	Key       string `json:"key"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+}
+
+// subAgentContract is appended to the sub-agent system prompt.
+const subAgentContract = `
+
+FINAL ANSWER FORMAT:
+When you are done, reply WITHOUT tool calls and with JSON only:
+{"answer": "direct answer, not raw data", "confidence": 0.0 to 1.0, "references": [{"key": "stored key", "start_line": 10, "end_line": 20}], "follow_ups": ["what is worth checking next"]}
+- confidence: 1.0 only for what you verified in tool output; lower for inference
+- references: stored keys (and line ranges) your answer relies on; [] if none
+- follow_ups: open questions for the parent; [] if none`
+
+// parseSubAgentResult checks a final reply against the contract. With a
+// store, references must name stored keys.
+func (t *SpawnAgentTool) parseSubAgentResult(ctx context.Context, content string) (SubAgentResult, error) {
+	return jsonutil.DecodeValidated(content, func(r SubAgentResult) error {
+		if r.Answer == "" {
+			return fmt.Errorf("answer is required")
+		}
+		if r.Confidence < 0 || r.Confidence > 1 {
+			return fmt.Errorf("confidence must be between 0 and 1, got %v", r.Confidence)
+		}
+		for _, ref := range r.References {
+			if ref.Key == "" {
+				return fmt.Errorf("every reference needs a key")
+			}
+			if ref.EndLine < ref.StartLine {
+				return fmt.Errorf("reference %s: end_line is before start_line", ref.Key)
+			}
+			if t.store == nil {
+				continue
+			}
+			meta, err := t.store.GetMetadata(ctx, storage.ResultKey{SessionID: t.sessionID, Key: ref.Key})
+			if err == nil && meta == nil {
+				return fmt.Errorf("reference %q is not a stored key (check with list_stored)", ref.Key)
+			}
+		}
+		return nil
+	})
+}
+
+// freeTextResult wraps an answer that doesn't follow the contract.
+func freeTextResult(content string) SubAgentResult {
+	return SubAgentResult{Answer: content, Unstructured: true}
+}
+
+// String renders the result as the JSON returned to the parent.
+func (r SubAgentResult) String() string {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return r.Answer
+	}
+	return string(data)
+}
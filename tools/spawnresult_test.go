@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/storage"
+)
+
+// replyProvider answers with its replies in turn, repeating the last.
+type replyProvider struct {
+	promptCapture
+	mu      sync.Mutex
+	replies []string
+	calls   int
+	last    []llm.ChatMessage
+}
+
+func (p *replyProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reply := p.replies[min(p.calls, len(p.replies)-1)]
+	p.calls++
+	p.last = messages
+	return llm.LLMResponse{Content: reply}, nil
+}
+
+func TestSpawnReturnsStructuredResult(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryResultStore()
+	defer store.Close()
+	if _, err := store.SessionContent("run").StoreContent(ctx, model.FileKey("auth.go"), "package auth\n"); err != nil {
+		t.Fatalf("StoreContent failed: %v", err)
+	}
+
+	provider := &replyProvider{replies: []string{
+		`{"answer": "tokens are checked in middleware", "confidence": 0.9, "references": [{"key": "made_up.go"}]}`,
+		`{"answer": "tokens are checked in middleware", "confidence": 0.9, "references": [{"key": "auth.go", "start_line": 1, "end_line": 1}], "follow_ups": ["check refresh"]}`,
+	}}
+	tool := NewSpawnAgentTool(provider, DefaultSpawnConfig(), ToolConfig{}).
+		WithResultStore(store, "run", nil)
+
+	res, err := tool.Execute(ctx, json.RawMessage(`{"task":"where is auth checked?"}`))
+	if err != nil || !res.Success() {
+		t.Fatalf("spawn failed: %+v, %v", res, err)
+	}
+	var result SubAgentResult
+	if err := json.Unmarshal([]byte(res.Output), &result); err != nil {
+		t.Fatalf("output is not a SubAgentResult: %v\n%s", err, res.Output)
+	}
+	if result.Confidence != 0.9 || len(result.References) != 1 || result.References[0].Key != "auth.go" || result.FollowUps[0] != "check refresh" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if provider.calls != 2 || !strings.Contains(provider.last[len(provider.last)-1].Content, `"made_up.go" is not a stored key`) {
+		t.Errorf("expected one repair for the unknown reference, got %d calls", provider.calls)
+	}
+}
+
+func TestSpawnFallsBackToFreeText(t *testing.T) {
+	provider := &replyProvider{replies: []string{"It is in middleware."}}
+	tool := NewSpawnAgentTool(provider, DefaultSpawnConfig(), ToolConfig{})
+
+	res, _ := tool.Execute(context.Background(), json.RawMessage(`{"task":"where?"}`))
+	var result SubAgentResult
+	if err := json.Unmarshal([]byte(res.Output), &result); err != nil {
+		t.Fatalf("output is not a SubAgentResult: %v", err)
+	}
+	if !result.Unstructured || result.Answer != "It is in middleware." || provider.calls != 1+maxSubAgentRepairs {
+		t.Errorf("unexpected fallback: %+v after %d calls", result, provider.calls)
+	}
+
+	parallel := NewParallelSpawnTool(tool)
+	res, _ = parallel.Execute(context.Background(), json.RawMessage(`{"tasks":[{"task":"a"},{"task":"b"}]}`))
+	if !strings.Contains(res.Output, `"answer": "It is in middleware."`) {
+		t.Errorf("parallel_spawn should return structured results:\n%s", res.Output)
+	}
+}
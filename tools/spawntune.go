@@ -0,0 +1,93 @@
+// Adaptive spawn limits.
+//
+// One global depth, iteration and timeout setting is too tight for big
+// sub-tasks and too loose for small ones. A SpawnTuner sets them per spawn
+// from a cheap pre-flight estimate of the task, so a one-file lookup can't
+// grow a tree of sub-agents and a sweep over many files isn't cut off early.
+//
+// Information Hiding:
+// - Token and file-reference heuristics hidden
+// - Size thresholds hidden
+
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	complexityBytesPerToken = 4
+	smallTaskTokens         = 150
+	largeTaskTokens         = 2000
+	largeTaskFiles          = 5
+)
+
+// TaskComplexity is a pre-flight estimate of a spawned task's size.
+type TaskComplexity struct {
+	Tokens int // Approximate tokens in the task and its context
+	Files  int // Distinct file paths referenced
+}
+
+// EstimateComplexity sizes a task from its text alone: no LLM call.
+func EstimateComplexity(task string) TaskComplexity {
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(task) {
+		word = strings.Trim(word, "\"'`,;:()[]{}")
+		if looksLikePath(word) {
+			seen[word] = true
+		}
+	}
+	return TaskComplexity{
+		Tokens: len(task) / complexityBytesPerToken,
+		Files:  len(seen),
+	}
+}
+
+// looksLikePath reports whether word names a file or directory: it has a
+// slash (and is not a URL), or a name of two or more characters and a
+// short extension.
+func looksLikePath(word string) bool {
+	word = strings.TrimSuffix(word, ".")
+	if strings.Contains(word, "://") {
+		return false // URL
+	}
+	if strings.Contains(word, "/") && len(word) > 1 {
+		return true
+	}
+	ext := filepath.Ext(word)
+	name := strings.TrimSuffix(word, ext)
+	if len(ext) < 2 || len(ext) > 6 || len(name) < 2 {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// SpawnTuner returns the limits for one sub-agent at depth (1 = spawned
+// by the root) given its task's complexity. MaxDepth may only shrink the
+// base: it caps the sub-agent's whole subtree.
+type SpawnTuner func(c TaskComplexity, depth int, base SpawnConfig) SpawnConfig
+
+// AdaptiveSpawnTuner is the default SpawnTuner. Small tasks get half the
+// iterations and time and may not spawn further; large ones (long, or
+// referencing many files) get twice the iterations and time.
+func AdaptiveSpawnTuner(c TaskComplexity, depth int, base SpawnConfig) SpawnConfig {
+	tuned := base
+	switch {
+	case c.Tokens < smallTaskTokens && c.Files <= 1:
+		tuned.MaxDepth = min(base.MaxDepth, depth)
+		tuned.MaxIterations = min(base.MaxIterations, max(3, base.MaxIterations/2))
+		tuned.Timeout = min(base.Timeout, max(30*time.Second, base.Timeout/2))
+	case c.Tokens > largeTaskTokens || c.Files >= largeTaskFiles:
+		tuned.MaxIterations = base.MaxIterations * 2
+		tuned.Timeout = base.Timeout * 2
+	}
+	return tuned
+}
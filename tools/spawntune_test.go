@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateComplexity(t *testing.T) {
+	c := EstimateComplexity("Compare cli/runner.go with tools/spawn.go and main.go (see main.go), e.g. their error handling.")
+	if c.Files != 3 {
+		t.Errorf("Files = %d, want 3", c.Files)
+	}
+	if c.Tokens == 0 {
+		t.Error("Tokens should be estimated")
+	}
+	if c := EstimateComplexity("Fetch https://example.com and summarize it."); c.Files != 0 {
+		t.Errorf("URLs and sentence ends are not files: %d", c.Files)
+	}
+}
+
+func TestAdaptiveSpawnTuner(t *testing.T) {
+	base := SpawnConfig{MaxDepth: 4, MaxIterations: 10, Timeout: 2 * time.Minute}
+
+	small := AdaptiveSpawnTuner(TaskComplexity{Tokens: 20, Files: 1}, 2, base)
+	if small.MaxDepth != 2 || small.MaxIterations != 5 || small.Timeout != time.Minute {
+		t.Errorf("small task limits: %+v", small)
+	}
+
+	large := AdaptiveSpawnTuner(TaskComplexity{Tokens: 100, Files: 8}, 1, base)
+	if large.MaxDepth != 4 || large.MaxIterations != 20 || large.Timeout != 4*time.Minute {
+		t.Errorf("large task limits: %+v", large)
+	}
+
+	if medium := AdaptiveSpawnTuner(TaskComplexity{Tokens: 500, Files: 2}, 1, base); medium.MaxIterations != base.MaxIterations || medium.MaxDepth != base.MaxDepth {
+		t.Errorf("medium task should keep the base limits: %+v", medium)
+	}
+}
+
+func TestSpawnAppliesTunedLimits(t *testing.T) {
+	provider := &promptCapture{}
+	config := DefaultSpawnConfig()
+	config.Tune = func(c TaskComplexity, depth int, base SpawnConfig) SpawnConfig {
+		base.MaxDepth = 99 // May not grow past the configured depth
+		return base
+	}
+	tool := NewSpawnAgentTool(provider, config, ToolConfig{})
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"task":"look"}`)); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(provider.system, "(depth 1/5)") {
+		t.Errorf("tuned depth should be capped at the configured maximum:\n%s", provider.system)
+	}
+}
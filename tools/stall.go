@@ -0,0 +1,143 @@
+// Stall Detection for ReAct Loops.
+//
+// Agents sometimes repeat the same action and keep getting the same
+// observation until they run out of iterations. StallDetector spots this
+// early so the loop can nudge the model or stop. Only the same call getting
+// the same result counts: different arguments that happen to return the
+// same output (say, "no matches" for several patterns) are exploration, and
+// the same call returning new output (polling a job) is progress.
+//
+// Information Hiding:
+// - Action and observation fingerprinting hidden
+// - Repeat counting hidden
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/richinex/ariadne/llm"
+)
+
+// Default stall thresholds (consecutive repeats).
+const (
+	DefaultStallWarnAfter  = 3
+	DefaultStallAbortAfter = 5
+)
+
+// StallVerdict is the detector's assessment after an observation.
+type StallVerdict int
+
+const (
+	StallNone  StallVerdict = iota // Loop is making progress
+	StallWarn                      // Inject a corrective message
+	StallAbort                     // Stop the loop with a StallError
+)
+
+// StallError reports that a ReAct loop stopped making progress.
+type StallError struct {
+	Tool    string // Tool being repeated
+	Repeats int    // Consecutive repeats observed
+	Reason  string // "identical tool calls"
+}
+
+// Error implements the error interface.
+func (e *StallError) Error() string {
+	return fmt.Sprintf("agent stalled: %d %s to '%s'", e.Repeats, e.Reason, e.Tool)
+}
+
+// StallDetector tracks consecutive identical tool calls with unchanged
+// observations in a single ReAct loop. Not safe for concurrent use.
+type StallDetector struct {
+	warnAfter  int
+	abortAfter int
+
+	lastAction      string
+	lastObservation uint64
+	repeats         int
+	lastTool        string
+}
+
+// NewStallDetector creates a detector with default thresholds.
+func NewStallDetector() *StallDetector {
+	return &StallDetector{
+		warnAfter:  DefaultStallWarnAfter,
+		abortAfter: DefaultStallAbortAfter,
+	}
+}
+
+// WithThresholds sets how many consecutive repeats trigger a warning and an abort.
+// Non-positive values keep the current setting.
+func (d *StallDetector) WithThresholds(warnAfter, abortAfter int) *StallDetector {
+	if warnAfter > 0 {
+		d.warnAfter = warnAfter
+	}
+	if abortAfter > 0 {
+		d.abortAfter = abortAfter
+	}
+	return d
+}
+
+// Observe records a tool call and its observation and returns a verdict.
+func (d *StallDetector) Observe(tool string, args json.RawMessage, observation string) StallVerdict {
+	action := tool + ":" + hashArgs(args)
+	obs := xxhash.Sum64String(observation)
+	if action == d.lastAction && obs == d.lastObservation {
+		d.repeats++
+	} else {
+		d.lastAction = action
+		d.lastObservation = obs
+		d.repeats = 1
+	}
+	d.lastTool = tool
+
+	switch {
+	case d.repeats >= d.abortAfter:
+		return StallAbort
+	case d.repeats >= d.warnAfter:
+		return StallWarn
+	default:
+		return StallNone
+	}
+}
+
+// Err returns a StallError describing the current stall.
+func (d *StallDetector) Err() *StallError {
+	return &StallError{Tool: d.lastTool, Repeats: d.repeats, Reason: "identical tool calls"}
+}
+
+// CorrectiveMessage returns guidance to inject when the loop is stalling.
+func (d *StallDetector) CorrectiveMessage() string {
+	return fmt.Sprintf(
+		"NOTICE: You have called '%s' with identical arguments %d times in a row and are getting the same result. "+
+			"Repeating it will not help. Try a different tool or different arguments, or give your final answer with what you have.",
+		d.lastTool, d.repeats)
+}
+
+// CheckToolRound observes one round of native tool calls. messages must end
+// with the tool messages answering calls, one per call and in order. On a
+// warning the corrective message is appended to the last tool message; on
+// abort a *StallError is returned.
+func (d *StallDetector) CheckToolRound(calls []llm.ToolCall, messages []llm.ChatMessage) error {
+	if len(calls) == 0 || len(messages) < len(calls) {
+		return nil
+	}
+	results := messages[len(messages)-len(calls):]
+
+	verdict := StallNone
+	for i, tc := range calls {
+		if v := d.Observe(tc.Name, tc.Arguments, results[i].Content); v > verdict {
+			verdict = v
+		}
+	}
+
+	switch verdict {
+	case StallAbort:
+		return d.Err()
+	case StallWarn:
+		results[len(results)-1].Content += "\n\n" + d.CorrectiveMessage()
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+)
+
+func TestStallDetectorRepeatedCalls(t *testing.T) {
+	d := NewStallDetector()
+	args := json.RawMessage(`{"path": "a.go"}`)
+
+	want := []StallVerdict{StallNone, StallNone, StallWarn, StallWarn, StallAbort}
+	for i, w := range want {
+		// Whitespace differences must not hide identical calls
+		callArgs := args
+		if i%2 == 1 {
+			callArgs = json.RawMessage(`{ "path":"a.go" }`)
+		}
+		if got := d.Observe("read_file", callArgs, "same output"); got != w {
+			t.Fatalf("call %d: expected verdict %v, got %v", i+1, w, got)
+		}
+	}
+
+	err := d.Err()
+	if err.Tool != "read_file" || err.Repeats != 5 {
+		t.Errorf("unexpected stall error: %+v", err)
+	}
+}
+
+func TestStallDetectorProgressResets(t *testing.T) {
+	d := NewStallDetector().WithThresholds(2, 3)
+
+	d.Observe("glob", json.RawMessage(`{"pattern": "*.go"}`), "a.go")
+	if got := d.Observe("glob", json.RawMessage(`{"pattern": "*.go"}`), "a.go"); got != StallWarn {
+		t.Fatalf("expected warning, got %v", got)
+	}
+	if got := d.Observe("read_file", json.RawMessage(`{"path": "a.go"}`), "package main"); got != StallNone {
+		t.Errorf("expected progress to reset detector, got %v", got)
+	}
+}
+
+func TestStallDetectorSameOutputDifferentArgs(t *testing.T) {
+	d := NewStallDetector().WithThresholds(2, 3)
+
+	// Exploring different patterns that all come up empty is not a stall
+	for _, pattern := range []string{"*.rs", "*.py", "*.c", "*.java"} {
+		args := json.RawMessage(`{"pattern": "` + pattern + `"}`)
+		if got := d.Observe("glob", args, "no matches"); got != StallNone {
+			t.Fatalf("pattern %s: expected no stall, got %v", pattern, got)
+		}
+	}
+}
+
+func TestStallDetectorChangingOutputIsProgress(t *testing.T) {
+	d := NewStallDetector().WithThresholds(2, 3)
+	args := json.RawMessage(`{"job": "build"}`)
+
+	// Polling the same call is fine while its output keeps changing
+	for _, status := range []string{"queued", "running", "running 50%", "done"} {
+		if got := d.Observe("job_status", args, status); got != StallNone {
+			t.Fatalf("status %q: expected no stall, got %v", status, got)
+		}
+	}
+	if got := d.Observe("job_status", args, "done"); got != StallWarn {
+		t.Errorf("expected warning once the output stops changing, got %v", got)
+	}
+}
+
+func TestStallDetectorCheckToolRound(t *testing.T) {
+	d := NewStallDetector().WithThresholds(2, 3)
+	calls := []llm.ToolCall{{ID: "1", Name: "glob", Arguments: json.RawMessage(`{}`)}}
+
+	round := func() ([]llm.ChatMessage, error) {
+		messages := []llm.ChatMessage{
+			{Role: "assistant", ToolCalls: calls},
+			{Role: "tool", Content: "a.go", ToolCallID: "1"},
+		}
+		return messages, d.CheckToolRound(calls, messages)
+	}
+
+	if _, err := round(); err != nil {
+		t.Fatalf("unexpected error on first round: %v", err)
+	}
+	messages, err := round()
+	if err != nil {
+		t.Fatalf("unexpected error on second round: %v", err)
+	}
+	if !strings.Contains(messages[1].Content, "NOTICE") {
+		t.Errorf("expected corrective message appended, got %q", messages[1].Content)
+	}
+
+	_, err = round()
+	var stallErr *StallError
+	if !errors.As(err, &stallErr) {
+		t.Fatalf("expected StallError, got %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+// Script Tools - composite tools defined in Starlark.
+//
+// Users add tools without writing Go by dropping Starlark files into
+// .ariadne/tools/. Each file declares the tool and a run(args) function
+// that calls existing tools and transforms their results:
+//
+//	description = "Count TODO comments under a path"
+//	params = {"path": "Directory to scan"}
+//	required = ["path"]
+//
+//	def run(args):
+//	    out = call("ripgrep", pattern = "TODO", path = args["path"])
+//	    return "%d TODOs" % len(out.splitlines())
+//
+// The tool name is the file's base name unless the file sets name.
+// Scripts get call(tool, **args) (fails the script on tool errors),
+// try_call(tool, **args) (returns {"ok", "output", "error"}), and the
+// json module. With an Executor, calls go through it like the agent's
+// own, so they are filtered, audited and PII-masked.
+//
+// Information Hiding:
+// - Starlark interpreter setup, step limits and cancellation hidden
+// - Conversion between JSON arguments and Starlark values hidden
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// DefaultScriptToolsDir is where Starlark tool files are loaded from.
+const DefaultScriptToolsDir = ".ariadne/tools"
+
+// maxScriptSteps bounds how much computation one script run may do.
+const maxScriptSteps = 10_000_000
+
+// scriptFileOptions allows while loops and top-level control flow.
+var scriptFileOptions = &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+
+// ScriptTool is a tool implemented by a Starlark file.
+type ScriptTool struct {
+	path     string
+	metadata ToolMetadata
+	required []string
+	run      starlark.Callable
+	host     *scriptHost
+}
+
+// LoadScriptTools loads every *.star file in dir. Scripts may call any
+// tool in available. A missing dir yields no tools. Files that fail to
+// load are reported together in the returned error; the tools that did
+// load are still returned.
+func LoadScriptTools(dir string, available []Tool) ([]*ScriptTool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.star"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script tools: %w", err)
+	}
+	sort.Strings(paths)
+
+	callable := make(map[string]Tool, len(available))
+	for _, tool := range available {
+		callable[tool.Metadata().Name] = tool
+	}
+
+	var loaded []*ScriptTool
+	var errs []string
+	for _, path := range paths {
+		tool, err := LoadScriptTool(path, callable)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if _, exists := callable[tool.metadata.Name]; exists {
+			errs = append(errs, fmt.Sprintf("%s: tool %q already exists", path, tool.metadata.Name))
+			continue
+		}
+		callable[tool.metadata.Name] = tool // Later scripts may call earlier ones
+		loaded = append(loaded, tool)
+	}
+	if len(errs) > 0 {
+		return loaded, fmt.Errorf("failed to load script tools:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return loaded, nil
+}
+
+// LoadScriptTool loads one Starlark tool file.
+func LoadScriptTool(path string, tools map[string]Tool) (*ScriptTool, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	tool := &ScriptTool{path: path}
+	tool.host = &scriptHost{tools: tools, self: tool}
+	thread := &starlark.Thread{Name: path}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	globals, err := starlark.ExecFileOptions(scriptFileOptions, thread, path, src, tool.host.predeclared())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	run, ok := globals["run"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s: missing run(args) function", path)
+	}
+	tool.run = run
+
+	name := strings.TrimSuffix(filepath.Base(path), ".star")
+	if err := scriptString(globals, "name", &name); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	description := "Script tool " + name
+	if err := scriptString(globals, "description", &description); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	required := make(map[string]bool)
+	if v, ok := globals["required"]; ok {
+		iter, ok := v.(starlark.Iterable)
+		if !ok {
+			return nil, fmt.Errorf("%s: required must be a list of parameter names", path)
+		}
+		it := iter.Iterate()
+		var item starlark.Value
+		for it.Next(&item) {
+			s, ok := starlark.AsString(item)
+			if !ok {
+				it.Done()
+				return nil, fmt.Errorf("%s: required must be a list of parameter names", path)
+			}
+			required[s] = true
+			tool.required = append(tool.required, s)
+		}
+		it.Done()
+	}
+
+	var params []ToolParameter
+	if v, ok := globals["params"]; ok {
+		dict, ok := v.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("%s: params must be a dict of name to description", path)
+		}
+		for _, item := range dict.Items() {
+			pname, ok1 := starlark.AsString(item[0])
+			desc, ok2 := starlark.AsString(item[1])
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("%s: params must be a dict of name to description", path)
+			}
+			params = append(params, ToolParameter{Name: pname, ParamType: "string", Description: desc, Required: required[pname]})
+		}
+	}
+
+	tool.metadata = ToolMetadata{Name: name, Description: description, Parameters: params}
+	return tool, nil
+}
+
+// scriptString reads an optional string global.
+func scriptString(globals starlark.StringDict, key string, dst *string) error {
+	v, ok := globals[key]
+	if !ok {
+		return nil
+	}
+	s, ok := starlark.AsString(v)
+	if !ok || s == "" {
+		return fmt.Errorf("%s must be a non-empty string", key)
+	}
+	*dst = s
+	return nil
+}
+
+// WithExecutor runs the tools the script calls through executor.
+func (t *ScriptTool) WithExecutor(executor *Executor) *ScriptTool {
+	t.host.executor = executor
+	return t
+}
+
+// Path returns the script file the tool was loaded from.
+func (t *ScriptTool) Path() string {
+	return t.path
+}
+
+// Metadata returns the tool metadata declared by the script.
+func (t *ScriptTool) Metadata() ToolMetadata {
+	return t.metadata
+}
+
+// Validate checks that required parameters are present.
+func (t *ScriptTool) Validate(args json.RawMessage) error {
+	var a map[string]interface{}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	for _, name := range t.required {
+		if _, ok := a[name]; !ok {
+			return fmt.Errorf("missing required parameter: %s", name)
+		}
+	}
+	return nil
+}
+
+// scriptContextKey holds the Execute context on the Starlark thread.
+const scriptContextKey = "ariadne.context"
+
+// Execute runs the script's run(args) function.
+func (t *ScriptTool) Execute(ctx context.Context, args json.RawMessage) (ToolResult, error) {
+	if err := t.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+
+	thread, stop := newScriptThread(ctx, t.metadata.Name)
+	defer stop()
+
+	decode := starlarkjson.Module.Members["decode"]
+	argValue, err := starlark.Call(thread, decode, starlark.Tuple{starlark.String(args)}, nil)
+	if err != nil {
+		return FailureResult(fmt.Errorf("invalid arguments: %w", err)), nil
+	}
+
+	result, err := starlark.Call(thread, t.run, starlark.Tuple{argValue}, nil)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return FailureResultf("%s failed: %s", t.metadata.Name, evalErr.Backtrace()), nil
+		}
+		return FailureResult(fmt.Errorf("%s failed: %w", t.metadata.Name, err)), nil
+	}
+
+	switch v := result.(type) {
+	case starlark.NoneType:
+		return SuccessResult(""), nil
+	case starlark.String:
+		return SuccessResult(string(v)), nil
+	}
+	encode := starlarkjson.Module.Members["encode"]
+	encoded, err := starlark.Call(thread, encode, starlark.Tuple{result}, nil)
+	if err != nil {
+		return SuccessResult(result.String()), nil
+	}
+	s, _ := starlark.AsString(encoded)
+	return SuccessResult(s), nil
+}
+
+// newScriptThread returns a step-limited thread that is cancelled with
+// ctx. Call stop when the code is done.
+func newScriptThread(ctx context.Context, name string) (thread *starlark.Thread, stop func()) {
+	thread = &starlark.Thread{Name: name}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	thread.SetLocal(scriptContextKey, ctx)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+	return thread, func() { close(done) }
+}
+
+// scriptHost gives Starlark code call(), try_call() and json. It is shared
+// by script tools and run_starlark.
+type scriptHost struct {
+	tools    map[string]Tool // Tools callable from Starlark
+	self     Tool            // The tool running the code, which may not call itself
+	executor *Executor       // Runs calls with the run's policies; nil calls tools directly
+}
+
+// predeclared returns the globals available to Starlark code.
+func (h *scriptHost) predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"call":     starlark.NewBuiltin("call", h.builtinCall),
+		"try_call": starlark.NewBuiltin("try_call", h.builtinTryCall),
+		"json":     starlarkjson.Module,
+	}
+}
+
+// builtinCall implements call(tool, **args): returns output or fails.
+func (h *scriptHost) builtinCall(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	result, err := h.invoke(thread, b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success() {
+		return nil, fmt.Errorf("%s: %v", b.Name(), result.Error)
+	}
+	return starlark.String(result.Output), nil
+}
+
+// builtinTryCall implements try_call(tool, **args): returns a result dict.
+func (h *scriptHost) builtinTryCall(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	result, err := h.invoke(thread, b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	dict := starlark.NewDict(3)
+	errText := ""
+	if !result.Success() {
+		errText = result.Error.Error()
+	}
+	_ = dict.SetKey(starlark.String("ok"), starlark.Bool(result.Success()))
+	_ = dict.SetKey(starlark.String("output"), starlark.String(result.Output))
+	_ = dict.SetKey(starlark.String("error"), starlark.String(errText))
+	return dict, nil
+}
+
+// invoke runs a host tool with keyword arguments converted to JSON.
+func (h *scriptHost) invoke(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (ToolResult, error) {
+	if len(args) != 1 {
+		return ToolResult{}, fmt.Errorf("%s: expected tool name and keyword arguments", b.Name())
+	}
+	name, ok := starlark.AsString(args[0])
+	if !ok {
+		return ToolResult{}, fmt.Errorf("%s: tool name must be a string", b.Name())
+	}
+	tool, ok := h.tools[name]
+	if !ok || tool == h.self {
+		return ToolResult{}, fmt.Errorf("%s: unknown tool %q", b.Name(), name)
+	}
+
+	dict := starlark.NewDict(len(kwargs))
+	for _, kv := range kwargs {
+		_ = dict.SetKey(kv[0], kv[1])
+	}
+	encoded, err := starlark.Call(thread, starlarkjson.Module.Members["encode"], starlark.Tuple{dict}, nil)
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	raw, _ := starlark.AsString(encoded)
+
+	return h.execute(thread, tool, json.RawMessage(raw))
+}
+
+// execute runs a host tool on the thread's context, through the executor
+// if there is one.
+func (h *scriptHost) execute(thread *starlark.Thread, tool Tool, args json.RawMessage) (ToolResult, error) {
+	ctx, _ := thread.Local(scriptContextKey).(context.Context)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := tool.Validate(args); err != nil {
+		return FailureResult(err), nil
+	}
+	if h.executor != nil {
+		return h.executor.Execute(ctx, tool, args)
+	}
+	return tool.Execute(ctx, args)
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeScript(t *testing.T, dir, name, src string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScriptToolComposesTools(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "shout.star", `
+description = "Ask a sub-agent and shout the answer"
+params = {"question": "What to ask"}
+required = ["question"]
+
+def run(args):
+    answer = call("spawn", task = args["question"])
+    return answer.upper() + "!"
+`)
+	writeScript(t, dir, "survey.star", `
+name = "survey"
+
+def run(args):
+    results = []
+    for q in args.get("questions", []):
+        r = try_call("spawn", task = q)
+        results.append({"q": q, "ok": r["ok"], "answer": r["output"] or r["error"]})
+    return results
+`)
+
+	scripts, err := LoadScriptTools(dir, []Tool{echoTool{name: "spawn"}})
+	if err != nil {
+		t.Fatalf("LoadScriptTools failed: %v", err)
+	}
+	if len(scripts) != 2 {
+		t.Fatalf("expected 2 scripts, got %d", len(scripts))
+	}
+
+	shout := scripts[0]
+	meta := shout.Metadata()
+	if meta.Name != "shout" || len(meta.Parameters) != 1 || !meta.Parameters[0].Required {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	result, _ := shout.Execute(context.Background(), json.RawMessage(`{"question":"why"}`))
+	if !result.Success() || result.Output != "ANSWER TO WHY!" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if err := shout.Validate(json.RawMessage(`{}`)); err == nil {
+		t.Error("expected missing required parameter error")
+	}
+
+	result, _ = scripts[1].Execute(context.Background(), json.RawMessage(`{"questions":["a","fail"]}`))
+	want := `[{"answer":"answer to a","ok":true,"q":"a"},{"answer":"task failed","ok":false,"q":"fail"}]`
+	if !result.Success() || result.Output != want {
+		t.Errorf("got %+v, want %s", result, want)
+	}
+}
+
+func TestScriptToolErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "norun.star", `description = "missing run"`)
+	writeScript(t, dir, "spawn.star", `def run(args): return "shadow"`)
+	writeScript(t, dir, "bad.star", `
+def run(args):
+    return call("spawn", task = "fail")
+`)
+
+	scripts, err := LoadScriptTools(dir, []Tool{echoTool{name: "spawn"}})
+	if err == nil || !strings.Contains(err.Error(), "missing run(args)") || !strings.Contains(err.Error(), `tool "spawn" already exists`) {
+		t.Errorf("expected load errors, got %v", err)
+	}
+	if len(scripts) != 1 {
+		t.Fatalf("expected the valid script to load, got %d", len(scripts))
+	}
+
+	result, _ := scripts[0].Execute(context.Background(), json.RawMessage(`{}`))
+	if result.Success() || !strings.Contains(result.Error.Error(), "task failed") {
+		t.Errorf("expected tool failure to fail the script, got %+v", result)
+	}
+
+	if scripts, err := LoadScriptTools(filepath.Join(dir, "missing"), nil); err != nil || len(scripts) != 0 {
+		t.Errorf("missing dir should yield no tools, got %v, %v", scripts, err)
+	}
+}
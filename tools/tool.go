@@ -10,6 +10,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -85,6 +86,24 @@ func FailureResultf(format string, args ...interface{}) ToolResult {
 	return ToolResult{Error: fmt.Errorf(format, args...)}
 }
 
+// ErrToolDenied marks failures caused by a tool's access policy (allowed
+// paths, commands, domains), as opposed to the operation itself failing.
+var ErrToolDenied = errors.New("tool call denied")
+
+// deniedError keeps the policy message while matching ErrToolDenied.
+type deniedError struct {
+	msg string
+}
+
+func (e *deniedError) Error() string        { return e.msg }
+func (e *deniedError) Is(target error) bool { return target == ErrToolDenied }
+
+// DeniedResultf creates a failed tool result for a policy denial.
+// The error matches ErrToolDenied with errors.Is.
+func DeniedResultf(format string, args ...interface{}) ToolResult {
+	return ToolResult{Error: &deniedError{msg: fmt.Sprintf(format, args...)}}
+}
+
 // Tool is the interface that all tools must implement.
 //
 // Information Hiding: Tool implementations hide their internal execution logic,
@@ -111,9 +130,29 @@ func (BaseTool) Validate(args json.RawMessage) error {
 // ToolConfig holds tool execution configuration.
 // The zero value is safe: timeout defaults to 30s, retries to 3, and sandboxing is enabled.
 type ToolConfig struct {
-	TimeoutSecs uint64
-	MaxRetries  uint32
-	NoSandbox   bool // Default false = sandboxed (safe by default)
+	TimeoutSecs         uint64
+	MaxRetries          uint32
+	NoSandbox           bool // Default false = sandboxed (safe by default)
+	MaxObservationBytes int  // Per-observation cap before overflow to ResultStore
+	// Injection screens ingested content and blocks tool calls copied from
+	// it (nil = fencing only; see InjectionGuard).
+	Injection *InjectionGuard
+	// Filter restricts which tools may run (nil = all; see ToolFilter).
+	Filter *ToolFilter
+	// Egress restricts network tools' destinations and response sizes
+	// (nil = unrestricted; see EgressPolicy).
+	Egress *EgressPolicy
+	// Audit records mutating tool calls (nil = no audit log).
+	Audit *AuditLogger
+	// PII masks personal data in tool results (nil = no masking; see
+	// PIIScanner).
+	PII *PIIScanner
+	// FileChanges receives each change file tools make (nil = not
+	// reported; see FileChange).
+	FileChanges func(FileChange)
+	// WrapUp decides how agent loops wrap up near their iteration limit
+	// (zero value = warn only; see WrapUpPolicy).
+	WrapUp WrapUpPolicy
 }
 
 // Timeout returns the configured timeout, defaulting to 30 seconds if zero.
@@ -140,6 +179,15 @@ func (c *ToolConfig) Sandboxed() bool {
 	return !c.NoSandbox
 }
 
+// ObservationLimit returns the per-observation byte cap, defaulting to
+// DefaultMaxObservationBytes if zero.
+func (c *ToolConfig) ObservationLimit() int {
+	if c == nil || c.MaxObservationBytes <= 0 {
+		return DefaultMaxObservationBytes
+	}
+	return c.MaxObservationBytes
+}
+
 // DefaultToolConfig returns the default tool configuration.
 // Note: The zero value of ToolConfig is also safe and provides the same defaults.
 func DefaultToolConfig() ToolConfig {
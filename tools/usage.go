@@ -0,0 +1,122 @@
+// Tool Usage Tracking.
+//
+// Records per-run tool invocation statistics and flags agents that keep
+// calling the same tool with identical arguments. The run's result store
+// usage is saved with them.
+//
+// Information Hiding:
+// - Argument normalization and hashing hidden
+// - Repeat counting hidden
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/richinex/ariadne/model"
+	"github.com/richinex/ariadne/storage"
+)
+
+// RepeatWarnThreshold is the number of identical calls after which a
+// possible loop is reported.
+const RepeatWarnThreshold = 3
+
+// UsageTracker records tool invocations for a single run.
+// Safe for concurrent use.
+type UsageTracker struct {
+	mu      sync.Mutex
+	runID   string
+	records []storage.ToolCallRecord
+	seen    map[string]int  // tool name + args hash -> call count
+	dsa     *model.DSAUsage // Result store usage, saved with the calls
+}
+
+// NewUsageTracker creates a tracker for the given run.
+func NewUsageTracker(runID string) *UsageTracker {
+	return &UsageTracker{
+		runID: runID,
+		seen:  make(map[string]int),
+	}
+}
+
+// Record logs a tool call and returns how many times this exact call
+// (same tool, same arguments) has been made in the run, including this one.
+func (t *UsageTracker) Record(name string, args json.RawMessage, result ToolResult, err error, duration time.Duration) int {
+	hash := hashArgs(args)
+	record := storage.ToolCallRecord{
+		RunID:      t.runID,
+		ToolName:   name,
+		ArgsHash:   hash,
+		InputSize:  len(args),
+		OutputSize: len(result.Output),
+		DurationMs: uint64(duration.Milliseconds()),
+		Success:    err == nil && result.Success(),
+		CreatedAt:  time.Now().Unix(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.records = append(t.records, record)
+	key := name + ":" + hash
+	t.seen[key]++
+	return t.seen[key]
+}
+
+// Records returns a copy of all recorded calls.
+func (t *UsageTracker) Records() []storage.ToolCallRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]storage.ToolCallRecord, len(t.records))
+	copy(result, t.records)
+	return result
+}
+
+// SetDSAUsage sets the run's result store usage, saved by Save.
+func (t *UsageTracker) SetDSAUsage(usage model.DSAUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dsa = &usage
+}
+
+// Save persists all recorded calls and the result store usage, if set.
+func (t *UsageTracker) Save(ctx context.Context, store storage.ToolStatsStorage) error {
+	if store == nil {
+		return nil
+	}
+	if err := store.RecordToolCalls(ctx, t.Records()); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	dsa := t.dsa
+	t.mu.Unlock()
+	if dsa == nil {
+		return nil
+	}
+	return store.RecordDSAUsage(ctx, t.runID, *dsa)
+}
+
+// RepeatWarning returns a loop warning for a call made count times, or empty
+// if count is below RepeatWarnThreshold.
+func RepeatWarning(name string, count int) string {
+	if count < RepeatWarnThreshold {
+		return ""
+	}
+	return fmt.Sprintf("Warning: '%s' called %d times with identical arguments (possible loop)", name, count)
+}
+
+// hashArgs hashes arguments after compacting whitespace so formatting
+// differences do not hide identical calls.
+func hashArgs(args json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, args); err != nil {
+		buf.Reset()
+		buf.Write(args)
+	}
+	return fmt.Sprintf("%016x", xxhash.Sum64(buf.Bytes()))
+}
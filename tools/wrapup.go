@@ -0,0 +1,86 @@
+// Wrap-Up Policy for ReAct Loops.
+//
+// Runs that reach their iteration limit end with ErrMaxIterations and, at
+// best, a partial result. WrapUpPolicy tells the model when the limit is
+// near, and can turn the step at the threshold into a synthesis step: the
+// model must answer from what it has found, and tool calls it makes anyway
+// are not run.
+//
+// Information Hiding:
+// - Threshold arithmetic hidden
+// - Instruction formatting hidden
+
+package tools
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Wrap-up defaults.
+const (
+	// DefaultWrapUpThreshold is how many iterations, counting the current
+	// one, remain when wrap-up starts.
+	DefaultWrapUpThreshold = 2
+	// DefaultWrapUpInstruction warns the model; %d is the iterations left.
+	DefaultWrapUpInstruction = "WARNING: Only %d iterations remaining!"
+)
+
+// synthesisNote asks for the answer on a synthesis step.
+const synthesisNote = "FINAL STEP: Do not call any more tools. Give your final answer now, based on what you have found so far, and say what is left unverified."
+
+// WrapUpPolicy decides how a loop wraps up near its iteration limit. The
+// zero value warns at DefaultWrapUpThreshold with DefaultWrapUpInstruction
+// and never forces an answer.
+type WrapUpPolicy struct {
+	// Threshold is how many iterations, counting the current one, remain
+	// when wrap-up starts (0 = DefaultWrapUpThreshold, negative = never).
+	Threshold int
+	// Instruction is added to observations from the threshold on, or leads
+	// the synthesis step. A %d is replaced by the iterations left (empty =
+	// DefaultWrapUpInstruction, or no lead for the synthesis step).
+	Instruction string
+	// Synthesize makes the step at the threshold answer-only: tool calls
+	// are not run, and whatever the model says is the run's answer.
+	Synthesize bool
+}
+
+func (p WrapUpPolicy) threshold() int {
+	if p.Threshold == 0 {
+		return DefaultWrapUpThreshold
+	}
+	return p.Threshold
+}
+
+func (p WrapUpPolicy) instruction(left int) string {
+	instruction := p.Instruction
+	if instruction == "" {
+		instruction = DefaultWrapUpInstruction
+	}
+	return strings.ReplaceAll(instruction, "%d", strconv.Itoa(left))
+}
+
+// Warning returns the text to append to the observation of a step taken
+// with remaining iterations left (counting that step), or "" if none is
+// due. Synthesizing policies don't warn: the next step answers.
+func (p WrapUpPolicy) Warning(remaining int) string {
+	if p.Synthesize || remaining > p.threshold() {
+		return ""
+	}
+	return p.instruction(remaining - 1)
+}
+
+// ForcesAnswer reports whether the step taken with remaining iterations
+// left (counting it) must answer without tools.
+func (p WrapUpPolicy) ForcesAnswer(remaining int) bool {
+	return p.Synthesize && remaining <= p.threshold()
+}
+
+// SynthesisPrompt returns the message that asks for the final answer on a
+// step ForcesAnswer reported.
+func (p WrapUpPolicy) SynthesisPrompt() string {
+	if p.Instruction == "" {
+		return synthesisNote
+	}
+	return p.instruction(0) + "\n\n" + synthesisNote
+}
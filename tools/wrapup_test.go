@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richinex/ariadne/llm"
+)
+
+func TestWrapUpPolicyWarning(t *testing.T) {
+	var policy WrapUpPolicy
+	if got := policy.Warning(3); got != "" {
+		t.Errorf("Warning(3) = %q, want none above the default threshold", got)
+	}
+	if got := policy.Warning(2); got != "WARNING: Only 1 iterations remaining!" {
+		t.Errorf("Warning(2) = %q", got)
+	}
+	if policy.ForcesAnswer(1) {
+		t.Error("the zero policy should never force an answer")
+	}
+
+	custom := WrapUpPolicy{Threshold: 4, Instruction: "Wrap up: %d steps left, 100% done?"}
+	if got := custom.Warning(4); got != "Wrap up: 3 steps left, 100% done?" {
+		t.Errorf("custom Warning(4) = %q", got)
+	}
+	if got := (WrapUpPolicy{Threshold: -1}).Warning(1); got != "" {
+		t.Errorf("negative threshold should never warn, got %q", got)
+	}
+}
+
+func TestWrapUpPolicySynthesize(t *testing.T) {
+	policy := WrapUpPolicy{Threshold: 2, Synthesize: true}
+	if policy.ForcesAnswer(3) || !policy.ForcesAnswer(2) {
+		t.Error("expected the answer to be forced from the threshold on")
+	}
+	if got := policy.Warning(2); got != "" {
+		t.Errorf("synthesizing policy should not warn, got %q", got)
+	}
+	if got := policy.SynthesisPrompt(); got != synthesisNote {
+		t.Errorf("SynthesisPrompt() = %q", got)
+	}
+	policy.Instruction = "Time is up."
+	if got := policy.SynthesisPrompt(); !strings.HasPrefix(got, "Time is up.\n\n") {
+		t.Errorf("SynthesisPrompt() should lead with the instruction, got %q", got)
+	}
+}
+
+// busyProvider keeps calling tools, adding an answer once told to stop.
+type busyProvider struct {
+	promptCapture
+	calls int
+}
+
+func (p *busyProvider) ChatWithTools(ctx context.Context, messages []llm.ChatMessage, tools []llm.ToolDefinition) (llm.LLMResponse, error) {
+	p.calls++
+	resp := llm.LLMResponse{ToolCalls: []llm.ToolCall{
+		{ID: "1", Name: "look", Arguments: json.RawMessage(`{}`)},
+	}}
+	if strings.Contains(messages[len(messages)-1].Content, "FINAL STEP") {
+		resp.Content = `{"answer": "synthesized", "confidence": 0.5}`
+	}
+	return resp, nil
+}
+
+func TestSpawnSynthesizesAtWrapUpThreshold(t *testing.T) {
+	config := DefaultSpawnConfig()
+	config.MaxIterations = 4
+	provider := &busyProvider{}
+	tool := NewSpawnAgentTool(provider, config, ToolConfig{WrapUp: WrapUpPolicy{Synthesize: true}})
+
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"task":"look around"}`))
+	if err != nil || !result.Success() {
+		t.Fatalf("Execute = %+v, %v", result, err)
+	}
+	if !strings.Contains(result.Output, "synthesized") {
+		t.Errorf("expected the synthesis step's answer, got %q", result.Output)
+	}
+	if provider.calls != 3 {
+		t.Errorf("LLM calls = %d, want 3 (the third at the threshold)", provider.calls)
+	}
+}